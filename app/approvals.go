@@ -0,0 +1,592 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/domain"
+	"financial-ledger/events"
+	"financial-ledger/shared"
+	"financial-ledger/store"
+)
+
+// approvalDirectoryProjectionName is the name the approval directory
+// registers under with the projection runner.
+const approvalDirectoryProjectionName = "approval-directory"
+
+// DefaultApprovalTTL is how long an approval waits for its approvers before
+// it expires.
+const DefaultApprovalTTL = 72 * time.Hour
+
+var ErrApprovalRequired = errors.New("approval required")
+
+// ApprovalRequiredError is returned by TransferMoneyContext and
+// CreateAccountContext when the command was held for approval instead of
+// being executed.
+type ApprovalRequiredError struct {
+	Approval domain.Approval
+}
+
+func (e *ApprovalRequiredError) Error() string {
+	return fmt.Sprintf("%s: %s is held as approval %s (%s)", ErrApprovalRequired, e.Approval.Summary, e.Approval.ID, e.Approval.Status)
+}
+
+func (e *ApprovalRequiredError) Unwrap() error {
+	return ErrApprovalRequired
+}
+
+// ApprovalPolicy decides which commands need a second user's approval
+// (maker-checker). Account closures and reversals always do; transfers do when
+// they are larger than the threshold for their currency.
+type ApprovalPolicy struct {
+	// TransferThresholds holds, per currency, the largest transfer that needs
+	// no approval. Transfers in other currencies never need one.
+	TransferThresholds map[shared.Currency]decimal.Decimal
+
+	// RequiredApprovals is how many users other than the requester must
+	// approve. It defaults to 1.
+	RequiredApprovals int
+
+	// TTL defaults to DefaultApprovalTTL.
+	TTL time.Duration
+}
+
+func (p ApprovalPolicy) withDefaults() ApprovalPolicy {
+	if p.RequiredApprovals < 1 {
+		p.RequiredApprovals = 1
+	}
+	if p.TTL <= 0 {
+		p.TTL = DefaultApprovalTTL
+	}
+	return p
+}
+
+// WithApprovalPolicy replaces the default policy, under which transfers never
+// need approval.
+func WithApprovalPolicy(p ApprovalPolicy) ServiceOption {
+	return func(s *AccountService) {
+		s.approvalPolicy = p.withDefaults()
+	}
+}
+
+func (s *AccountService) transferNeedsApproval(cmd TransferMoneyCommand) bool {
+	threshold, ok := s.approvalPolicy.TransferThresholds[cmd.Currency]
+	return ok && cmd.Amount.GreaterThan(threshold)
+}
+
+// approvalForKey returns the approval a request of kind carrying
+// idempotencyKey opened, if there is one. Approval IDs are derived from the
+// key, so a retry finds the approval the first attempt created, whatever its
+// status by now.
+func (s *AccountService) approvalForKey(ctx context.Context, kind domain.ApprovalKind, idempotencyKey string) (domain.Approval, bool, error) {
+	if idempotencyKey == "" {
+		return domain.Approval{}, false, nil
+	}
+	approval, err := s.loadApproval(ctx, approvalIDForKey(kind, idempotencyKey))
+	if errors.Is(err, domain.ErrApprovalNotFound) {
+		return domain.Approval{}, false, nil
+	}
+	if err != nil {
+		return domain.Approval{}, false, err
+	}
+	return *approval, true, nil
+}
+
+func approvalIDForKey(kind domain.ApprovalKind, idempotencyKey string) string {
+	return uuid.NewSHA1(idempotencyNamespace, []byte(string(kind)+"\x00"+idempotencyKey)).String()
+}
+
+// requestApproval opens an approval of cmd requested by meta.Actor, in a
+// stream of its own. target names what the command acts on; a second request
+// of the same kind for a target that already has a pending approval is
+// refused with domain.ErrApprovalPending. Transfers have no target. When the
+// command carries an idempotency key, a retry returns the approval the first
+// attempt created.
+func (s *AccountService) requestApproval(ctx context.Context, kind domain.ApprovalKind, target, summary, idempotencyKey string, meta events.Metadata, cmd any, accountIDs ...string) (domain.Approval, error) {
+	if existing, ok, err := s.approvalForKey(ctx, kind, idempotencyKey); err != nil || ok {
+		return existing, err
+	}
+	id := uuid.NewString()
+	if idempotencyKey != "" {
+		id = approvalIDForKey(kind, idempotencyKey)
+	}
+	command, err := json.Marshal(cmd)
+	if err != nil {
+		return domain.Approval{}, fmt.Errorf("failed to encode %s for approval: %w", kind, err)
+	}
+
+	if target != "" {
+		unlock := s.keyLocks.lock("approval-target\x00" + string(kind) + "\x00" + target)
+		defer unlock()
+		if err := s.checkNoPendingApproval(ctx, kind, target); err != nil {
+			return domain.Approval{}, err
+		}
+	}
+
+	p := s.approvalPolicy
+	approval := domain.NewApproval(id)
+	if err := approval.HandleRequest(kind, target, accountIDs, summary, command, meta.Actor, p.RequiredApprovals, time.Now().UTC(), p.TTL); err != nil {
+		return domain.Approval{}, err
+	}
+	err = s.eventStore.SaveEventsContext(ctx, domain.ApprovalStreamID(id), 0, stampEvents(approval.GetUncommitedChanges(), commandMetadata(meta), nil))
+	if errors.Is(err, store.ErrOptimisticLock) && idempotencyKey != "" {
+		// A concurrent retry opened it first.
+		existing, loadErr := s.loadApproval(ctx, id)
+		if loadErr != nil {
+			return domain.Approval{}, loadErr
+		}
+		return *existing, nil
+	}
+	if err != nil {
+		return domain.Approval{}, fmt.Errorf("failed to save approval for %s: %w", summary, err)
+	}
+	log.Printf("Approval %s opened for %s requested by %s (expires %s)", id, summary, meta.Actor, approval.ExpiresAt.Format(time.RFC3339))
+	return *approval, nil
+}
+
+// checkNoPendingApproval refuses a request of kind for target while another is
+// pending.
+func (s *AccountService) checkNoPendingApproval(ctx context.Context, kind domain.ApprovalKind, target string) error {
+	pending, err := s.listApprovals(ctx, domain.ApprovalPending)
+	if err != nil {
+		return fmt.Errorf("failed to check for pending approvals of %s %s: %w", kind, target, err)
+	}
+	now := time.Now().UTC()
+	for _, a := range pending {
+		if a.Kind == kind && a.Target == target && now.Before(a.ExpiresAt) {
+			return fmt.Errorf("%w: %s is held as approval %s", domain.ErrApprovalPending, a.Summary, a.ID)
+		}
+	}
+	return nil
+}
+
+// RequestAccountClosure holds the closure of an account for approval. The
+// account must exist and be open; its balances are checked again when the
+// closure is executed.
+func (s *AccountService) RequestAccountClosure(ctx context.Context, cmd CloseAccountCommand) (domain.Approval, error) {
+	if existing, ok, err := s.approvalForKey(ctx, domain.ApprovalKindCloseAccount, cmd.IdempotencyKey); err != nil || ok {
+		return existing, err
+	}
+	account, err := s.loadAccount(ctx, cmd.AccountID)
+	if err != nil {
+		return domain.Approval{}, fmt.Errorf("failed to load account %s for closure: %w", cmd.AccountID, err)
+	}
+	if account.Closed {
+		return domain.Approval{}, fmt.Errorf("%w: %s", domain.ErrAccountClosed, cmd.AccountID)
+	}
+	return s.requestApproval(ctx, domain.ApprovalKindCloseAccount, cmd.AccountID, "closure of account "+cmd.AccountID, cmd.IdempotencyKey, cmd.Metadata, cmd, cmd.AccountID)
+}
+
+// RequestReversal holds the reversal of a deposit or withdrawal for approval.
+func (s *AccountService) RequestReversal(ctx context.Context, cmd ReverseTransactionCommand) (domain.Approval, error) {
+	if existing, ok, err := s.approvalForKey(ctx, domain.ApprovalKindReversal, cmd.IdempotencyKey); err != nil || ok {
+		return existing, err
+	}
+	original, err := s.findAccountEvent(ctx, cmd.AccountID, cmd.EventID)
+	if err != nil {
+		return domain.Approval{}, err
+	}
+	var summary string
+	switch e := original.(type) {
+	case events.DepositMadeEvent:
+		summary = fmt.Sprintf("reversal of deposit of %s %s to %s", e.Amount.String(), e.Currency, cmd.AccountID)
+	case events.WithdrawalMadeEvent:
+		summary = fmt.Sprintf("reversal of withdrawal of %s %s from %s", e.Amount.String(), e.Currency, cmd.AccountID)
+	default:
+		return domain.Approval{}, domain.NewDomainError("only deposits and withdrawals can be reversed; event %s is %s", cmd.EventID, original.GetBase().Type)
+	}
+	return s.requestApproval(ctx, domain.ApprovalKindReversal, cmd.EventID, summary, cmd.IdempotencyKey, cmd.Metadata, cmd, cmd.AccountID)
+}
+
+// loadApproval rebuilds an approval from its stream. Approvals are short, so
+// they are not snapshotted.
+func (s *AccountService) loadApproval(ctx context.Context, id string) (*domain.Approval, error) {
+	history, err := s.eventStore.GetEventsAfterVersionContext(ctx, domain.ApprovalStreamID(id), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load events of approval %s: %w", id, err)
+	}
+	approval := domain.NewApproval(id)
+	if err := approval.ApplyEvents(history); err != nil {
+		return nil, err
+	}
+	if approval.Version == 0 {
+		return nil, fmt.Errorf("%w: %s", domain.ErrApprovalNotFound, id)
+	}
+	return approval, nil
+}
+
+// GetApproval returns one approval.
+func (s *AccountService) GetApproval(ctx context.Context, id string) (domain.Approval, error) {
+	approval, err := s.loadApproval(ctx, id)
+	if err != nil {
+		return domain.Approval{}, err
+	}
+	return *approval, nil
+}
+
+// ListApprovals returns matching approvals, oldest first, from the approval
+// directory. Pending approvals past their expiry are expired first.
+func (s *AccountService) ListApprovals(ctx context.Context, query ListApprovalsQuery) ([]domain.Approval, error) {
+	if _, err := s.ExpireApprovals(ctx); err != nil {
+		return nil, err
+	}
+	all, err := s.listApprovals(ctx, query.Status)
+	if err != nil {
+		return nil, err
+	}
+	var out []domain.Approval
+	for _, a := range all {
+		if query.AccountID == "" || slices.Contains(a.AccountIDs, query.AccountID) {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+// listApprovals returns the approvals with status, or every approval if it is
+// empty, after catching the approval directory up.
+func (s *AccountService) listApprovals(ctx context.Context, status domain.ApprovalStatus) ([]domain.Approval, error) {
+	if s.projections == nil {
+		return nil, fmt.Errorf("cannot list approvals: %w", ErrGlobalLogUnsupported)
+	}
+	if err := s.projections.CatchUp(ctx, approvalDirectoryProjectionName); err != nil {
+		return nil, fmt.Errorf("failed to update approval directory: %w", err)
+	}
+	return s.approvals.list(status), nil
+}
+
+// ExpireApprovals expires every pending approval past its expiry and reports
+// how many it expired.
+func (s *AccountService) ExpireApprovals(ctx context.Context) (int, error) {
+	pending, err := s.listApprovals(ctx, domain.ApprovalPending)
+	if err != nil {
+		return 0, err
+	}
+	expired := 0
+	now := time.Now().UTC()
+	for _, a := range pending {
+		if now.Before(a.ExpiresAt) {
+			continue
+		}
+		_, err := s.updateApproval(ctx, a.ID, events.Metadata{}, func(a *domain.Approval) error {
+			ok, err := a.HandleExpire(now)
+			if ok {
+				expired++
+				log.Printf("Approval %s (%s) expired unapproved", a.ID, a.Summary)
+			}
+			return err
+		})
+		if err != nil {
+			return expired, err
+		}
+	}
+	return expired, nil
+}
+
+// Approve adds Metadata.Actor's approval. Once an approval has all it needs,
+// its command is executed through the service, and the last approval is saved
+// together with the outcome, so an approval is never stored as approved but
+// not executed. If they cannot be saved, the approval stays pending and
+// approving it again executes the command again: approved commands run under
+// their own idempotency key, or one derived from the approval, so that
+// replays the first execution. The executed command keeps its requester's
+// metadata, with the approval's ID as CorrelationID unless it already had
+// one. An error from the command is returned along with the failed approval.
+func (s *AccountService) Approve(ctx context.Context, cmd ApproveCommand) (domain.Approval, error) {
+	var executed bool
+	var execErr error
+	approval, err := s.updateApproval(ctx, cmd.ApprovalID, cmd.Metadata, func(a *domain.Approval) error {
+		complete, err := a.HandleApprove(cmd.Metadata.Actor, time.Now().UTC())
+		if err != nil || !complete {
+			return err
+		}
+		log.Printf("Approval %s approved by %s; executing", a.ID, cmd.Metadata.Actor)
+		execErr = s.executeApproved(ctx, *a)
+		executed = true
+		return a.HandleOutcome(execErr, time.Now().UTC())
+	})
+	switch {
+	case err != nil && executed:
+		log.Printf("Warning: Approval %s was executed but its outcome could not be saved: %v. Approving it again records it.", cmd.ApprovalID, err)
+		return approval, err
+	case err != nil:
+		return approval, err
+	case execErr != nil:
+		log.Printf("Warning: Approved %s (approval %s) failed: %v", approval.Summary, approval.ID, execErr)
+		return approval, fmt.Errorf("approval %s was approved but executing it failed: %w", approval.ID, execErr)
+	}
+	return approval, nil
+}
+
+// RejectApproval turns an approval down, recording who did so and why.
+func (s *AccountService) RejectApproval(ctx context.Context, cmd RejectApprovalCommand) (domain.Approval, error) {
+	approval, err := s.updateApproval(ctx, cmd.ApprovalID, cmd.Metadata, func(a *domain.Approval) error {
+		return a.HandleReject(cmd.Metadata.Actor, cmd.Reason, time.Now().UTC())
+	})
+	if err != nil {
+		return approval, err
+	}
+	log.Printf("Approval %s (%s) rejected by %s: %s", approval.ID, approval.Summary, cmd.Metadata.Actor, cmd.Reason)
+	return approval, nil
+}
+
+// updateApproval applies change to an approval under its lock and saves the
+// events it produced, stamped with meta. They are saved even when change
+// fails, since an approval found to be expired records its expiry.
+func (s *AccountService) updateApproval(ctx context.Context, id string, meta events.Metadata, change func(*domain.Approval) error) (domain.Approval, error) {
+	unlock := s.keyLocks.lock("approval\x00" + id)
+	defer unlock()
+
+	approval, err := s.loadApproval(ctx, id)
+	if err != nil {
+		return domain.Approval{}, err
+	}
+	initialVersion := approval.Version
+	changeErr := change(approval)
+	changes := approval.GetUncommitedChanges()
+	if len(changes) == 0 {
+		return *approval, changeErr
+	}
+	err = s.eventStore.SaveEventsContext(context.WithoutCancel(ctx), domain.ApprovalStreamID(id), initialVersion, stampEvents(changes, commandMetadata(meta), nil))
+	if err != nil {
+		return *approval, fmt.Errorf("failed to save approval %s: %w", id, err)
+	}
+	return *approval, changeErr
+}
+
+// executeApproved runs an approved command. A command without an idempotency
+// key is given one derived from the approval, so that running it twice has
+// the effect of running it once.
+func (s *AccountService) executeApproved(ctx context.Context, a domain.Approval) error {
+	switch a.Kind {
+	case domain.ApprovalKindTransfer:
+		var cmd TransferMoneyCommand
+		if err := json.Unmarshal(a.Command, &cmd); err != nil {
+			return fmt.Errorf("failed to decode approved transfer: %w", err)
+		}
+		cmd.IdempotencyKey = approvedIdempotencyKey(cmd.IdempotencyKey, a)
+		cmd.Metadata = approvedMetadata(cmd.Metadata, a)
+		return s.transferMoney(ctx, cmd)
+	case domain.ApprovalKindCreateAccount:
+		var held heldAccountCreation
+		if err := json.Unmarshal(a.Command, &held); err != nil {
+			return fmt.Errorf("failed to decode approved account creation: %w", err)
+		}
+		held.Command.IdempotencyKey = approvedIdempotencyKey(held.Command.IdempotencyKey, a)
+		held.Command.Metadata = approvedMetadata(held.Command.Metadata, a)
+		_, err := s.createAccount(ctx, held.Command, held.Details)
+		return err
+	case domain.ApprovalKindCreateCustomer:
+		var held heldCustomerCreation
+		if err := json.Unmarshal(a.Command, &held); err != nil {
+			return fmt.Errorf("failed to decode approved customer registration: %w", err)
+		}
+		held.Command.IdempotencyKey = approvedIdempotencyKey(held.Command.IdempotencyKey, a)
+		held.Command.Metadata = approvedMetadata(held.Command.Metadata, a)
+		_, err := s.createCustomer(ctx, held.Command, &held.Details)
+		return err
+	case domain.ApprovalKindCloseAccount:
+		var cmd CloseAccountCommand
+		if err := json.Unmarshal(a.Command, &cmd); err != nil {
+			return fmt.Errorf("failed to decode approved closure: %w", err)
+		}
+		cmd.IdempotencyKey = approvedIdempotencyKey(cmd.IdempotencyKey, a)
+		cmd.Metadata = approvedMetadata(cmd.Metadata, a)
+		return s.closeAccount(ctx, cmd)
+	case domain.ApprovalKindReversal:
+		var cmd ReverseTransactionCommand
+		if err := json.Unmarshal(a.Command, &cmd); err != nil {
+			return fmt.Errorf("failed to decode approved reversal: %w", err)
+		}
+		cmd.IdempotencyKey = approvedIdempotencyKey(cmd.IdempotencyKey, a)
+		cmd.Metadata = approvedMetadata(cmd.Metadata, a)
+		return s.reverseTransaction(ctx, cmd)
+	}
+	return fmt.Errorf("approval %s holds a %s, which cannot be executed", a.ID, a.Kind)
+}
+
+func approvedIdempotencyKey(key string, a domain.Approval) string {
+	if key != "" {
+		return key
+	}
+	return domain.ApprovalStreamID(a.ID)
+}
+
+func approvedMetadata(meta events.Metadata, a domain.Approval) events.Metadata {
+	if meta.CorrelationID == "" {
+		meta.CorrelationID = a.ID
+	}
+	return meta
+}
+
+func (s *AccountService) closeAccount(ctx context.Context, cmd CloseAccountCommand) error {
+	idem, replay, err := s.beginIdempotent(ctx, cmd.IdempotencyKey, cmd, cmd.AccountID)
+	if err != nil {
+		return err
+	}
+	if replay != nil {
+		return nil
+	}
+	defer idem.done()
+
+	meta := commandMetadata(cmd.Metadata)
+	err = s.retryOnConflict(ctx, "account closure", func(attempt int) error {
+		account, err := s.loadAccount(ctx, cmd.AccountID)
+		if err != nil {
+			return fmt.Errorf("failed to load account %s for closure: %w", cmd.AccountID, err)
+		}
+		initialVersion := account.Version
+
+		if err := account.HandleClose(); err != nil {
+			return fmt.Errorf("close command failed for account %s: %w", cmd.AccountID, err)
+		}
+		err = s.eventStore.SaveEventsContext(ctx, cmd.AccountID, initialVersion, stampEvents(account.GetUncommitedChanges(), meta, idem))
+		if err != nil {
+			return fmt.Errorf("failed to save closure events for account %s: %w", cmd.AccountID, err)
+		}
+		s.completeIdempotent(ctx, idem, cmd.AccountID)
+		log.Printf("Account %s closed. New Version: %d", cmd.AccountID, account.Version)
+		s.saveSnapshotIfNeeded(ctx, account)
+		return nil
+	})
+	s.notifyRejected(ctx, "closure", cmd.AccountID, meta, err)
+	return err
+}
+
+func (s *AccountService) reverseTransaction(ctx context.Context, cmd ReverseTransactionCommand) error {
+	idem, replay, err := s.beginIdempotent(ctx, cmd.IdempotencyKey, cmd, cmd.AccountID)
+	if err != nil {
+		return err
+	}
+	if replay != nil {
+		return nil
+	}
+	defer idem.done()
+
+	meta := commandMetadata(cmd.Metadata)
+	err = s.retryOnConflict(ctx, "reversal", func(attempt int) error {
+		account, err := s.loadAccount(ctx, cmd.AccountID)
+		if err != nil {
+			return fmt.Errorf("failed to load account %s for reversal: %w", cmd.AccountID, err)
+		}
+		initialVersion := account.Version
+
+		original, err := s.findAccountEvent(ctx, cmd.AccountID, cmd.EventID)
+		if err != nil {
+			return err
+		}
+		if err := account.HandleReverse(original); err != nil {
+			return fmt.Errorf("reversal command failed for account %s: %w", cmd.AccountID, err)
+		}
+		err = s.eventStore.SaveEventsContext(ctx, cmd.AccountID, initialVersion, stampEvents(account.GetUncommitedChanges(), causedBy(meta, original), idem))
+		if err != nil {
+			return fmt.Errorf("failed to save reversal events for account %s: %w", cmd.AccountID, err)
+		}
+		s.completeIdempotent(ctx, idem, cmd.AccountID)
+		log.Printf("Event %s on account %s reversed. New Version: %d", cmd.EventID, cmd.AccountID, account.Version)
+		s.saveSnapshotIfNeeded(ctx, account)
+		return nil
+	})
+	s.notifyRejected(ctx, "reversal", cmd.AccountID, meta, err)
+	return err
+}
+
+// findAccountEvent returns the event with ID eventID from accountID's stream.
+func (s *AccountService) findAccountEvent(ctx context.Context, accountID, eventID string) (events.Event, error) {
+	id, err := uuid.Parse(eventID)
+	if err != nil {
+		return nil, domain.NewDomainError("invalid event ID %q: %v", eventID, err)
+	}
+	history, err := s.eventStore.GetEventsContext(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read events of account %s: %w", accountID, err)
+	}
+	if len(history) == 0 {
+		return nil, fmt.Errorf("%w: %s", domain.ErrAccountNotFound, accountID)
+	}
+	for _, event := range history {
+		if event.GetBase().EventID == id {
+			return event, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s on account %s", ErrEventNotFound, eventID, accountID)
+}
+
+// approvalDirectory is a projection of every approval, in request order, for
+// ListApprovals and the check for pending approvals. It folds events through
+// domain.Approval and skips every other stream.
+type approvalDirectory struct {
+	sync.RWMutex
+	approvals map[string]*domain.Approval
+	order     []string // approval stream IDs in request order
+}
+
+func newApprovalDirectory() *approvalDirectory {
+	return &approvalDirectory{approvals: make(map[string]*domain.Approval)}
+}
+
+func (d *approvalDirectory) Name() string {
+	return approvalDirectoryProjectionName
+}
+
+func (d *approvalDirectory) Reset(ctx context.Context) error {
+	d.Lock()
+	defer d.Unlock()
+	d.approvals = make(map[string]*domain.Approval)
+	d.order = nil
+	return nil
+}
+
+func (d *approvalDirectory) Apply(ctx context.Context, event events.Event) error {
+	base := event.GetBase()
+	if !domain.IsApprovalStream(base.AggregateID) {
+		return nil
+	}
+	d.Lock()
+	defer d.Unlock()
+
+	approval, ok := d.approvals[base.AggregateID]
+	if !ok {
+		requested, isRequest := event.(events.ApprovalRequestedEvent)
+		if !isRequest {
+			return fmt.Errorf("approval directory: %s event at position %d for unknown approval stream %s", base.Type, base.Position, base.AggregateID)
+		}
+		approval = domain.NewApproval(requested.ApprovalID)
+		d.approvals[base.AggregateID] = approval
+		d.order = append(d.order, base.AggregateID)
+	}
+	if base.Version <= approval.Version {
+		return nil
+	}
+	if err := approval.ApplyEvent(event); err != nil {
+		return fmt.Errorf("approval directory: %w", err)
+	}
+	return nil
+}
+
+// list returns a copy of every approval with status, or of every approval if
+// status is empty, oldest first.
+func (d *approvalDirectory) list(status domain.ApprovalStatus) []domain.Approval {
+	d.RLock()
+	defer d.RUnlock()
+	var out []domain.Approval
+	for _, id := range d.order {
+		a := d.approvals[id]
+		if status == "" || a.Status == status {
+			cp := *a
+			cp.AccountIDs = slices.Clone(a.AccountIDs)
+			cp.Approvals = slices.Clone(a.Approvals)
+			out = append(out, cp)
+		}
+	}
+	return out
+}
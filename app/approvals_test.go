@@ -0,0 +1,325 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/app"
+	"financial-ledger/domain"
+	"financial-ledger/events"
+	"financial-ledger/shared"
+	"financial-ledger/store"
+)
+
+// outcomeLosingEventStore fails the first `losses` saves of an approval's
+// outcome, as if the process had stopped right after executing the command.
+type outcomeLosingEventStore struct {
+	*store.InMemoryEventStore
+	mu     sync.Mutex
+	losses int
+}
+
+func (s *outcomeLosingEventStore) SaveEventsContext(ctx context.Context, aggregateID string, expectedVersion int, evts []events.Event) error {
+	s.mu.Lock()
+	lose := s.losses > 0 && domain.IsApprovalStream(aggregateID) && evts[len(evts)-1].GetBase().Type == events.ApprovalExecutedType
+	if lose {
+		s.losses--
+	}
+	s.mu.Unlock()
+	if lose {
+		return errors.New("simulated crash")
+	}
+	return s.InMemoryEventStore.SaveEventsContext(ctx, aggregateID, expectedVersion, evts)
+}
+
+func TestAccountService_Approvals(t *testing.T) {
+	ctx := context.Background()
+	newServiceOn := func(t *testing.T, es store.EventStore, policy app.ApprovalPolicy) *app.AccountService {
+		t.Helper()
+		service := app.NewAccountService(es, store.NewInMemorySnapshotStore(), app.WithApprovalPolicy(policy))
+		for _, id := range []string{"ap-a", "ap-b"} {
+			if _, err := service.CreateAccount(app.CreateAccountCommand{AccountID: id, InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: dec("50000")}}); err != nil {
+				t.Fatalf("CreateAccount(%s) failed: %v", id, err)
+			}
+		}
+		return service
+	}
+	newService := func(t *testing.T, policy app.ApprovalPolicy) *app.AccountService {
+		t.Helper()
+		return newServiceOn(t, store.NewInMemoryEventStore(), policy)
+	}
+	thresholds := app.ApprovalPolicy{TransferThresholds: map[shared.Currency]decimal.Decimal{shared.USD: dec("10000")}}
+	alice := events.Metadata{Actor: "alice"}
+	balance := func(t *testing.T, service *app.AccountService, id string) decimal.Decimal {
+		t.Helper()
+		balances, err := service.GetCurrentBalance(app.GetBalanceQuery{AccountID: id})
+		if err != nil {
+			t.Fatalf("GetCurrentBalance(%s) failed: %v", id, err)
+		}
+		return balances[shared.USD]
+	}
+
+	t.Run("TransferUnderThresholdRunsStraightAway", func(t *testing.T) {
+		service := newService(t, thresholds)
+		err := service.TransferMoney(app.TransferMoneyCommand{SourceAccountID: "ap-a", TargetAccountID: "ap-b", Amount: dec("10000"), Currency: shared.USD, Metadata: alice})
+		if err != nil {
+			t.Fatalf("TransferMoney failed: %v", err)
+		}
+		if got := balance(t, service, "ap-b"); !got.Equal(dec("60000")) {
+			t.Errorf("expected 60000 on ap-b, got %s", got)
+		}
+	})
+
+	t.Run("LargeTransferWaitsForASecondUser", func(t *testing.T) {
+		service := newService(t, thresholds)
+		err := service.TransferMoney(app.TransferMoneyCommand{SourceAccountID: "ap-a", TargetAccountID: "ap-b", Amount: dec("20000"), Currency: shared.USD, Metadata: alice})
+		var held *app.ApprovalRequiredError
+		if !errors.As(err, &held) || !errors.Is(err, app.ErrApprovalRequired) {
+			t.Fatalf("expected ApprovalRequiredError, got %v", err)
+		}
+		if held.Approval.RequestedBy != "alice" || held.Approval.Status != domain.ApprovalPending {
+			t.Errorf("unexpected approval %+v", held.Approval)
+		}
+		if got := balance(t, service, "ap-a"); !got.Equal(dec("50000")) {
+			t.Fatalf("held transfer moved money: ap-a has %s", got)
+		}
+
+		if _, err := service.Approve(ctx, app.ApproveCommand{ApprovalID: held.Approval.ID, Metadata: alice}); err == nil {
+			t.Fatal("expected the requester's own approval to be refused")
+		}
+		approval, err := service.Approve(ctx, app.ApproveCommand{ApprovalID: held.Approval.ID, Metadata: events.Metadata{Actor: "bob"}})
+		if err != nil {
+			t.Fatalf("Approve failed: %v", err)
+		}
+		if approval.Status != domain.ApprovalExecuted {
+			t.Errorf("expected executed, got %s", approval.Status)
+		}
+		if got := balance(t, service, "ap-b"); !got.Equal(dec("70000")) {
+			t.Errorf("expected 70000 on ap-b, got %s", got)
+		}
+
+		history, _ := service.GetTransactionHistory(app.GetHistoryQuery{AccountID: "ap-b"})
+		meta := history[len(history)-1].GetBase().Metadata
+		if meta.Actor != "alice" || meta.CorrelationID != approval.ID {
+			t.Errorf("expected the requester as actor and the approval as correlation, got %+v", meta)
+		}
+	})
+
+	t.Run("RetryReturnsTheSameApproval", func(t *testing.T) {
+		service := newService(t, thresholds)
+		cmd := app.TransferMoneyCommand{SourceAccountID: "ap-a", TargetAccountID: "ap-b", Amount: dec("20000"), Currency: shared.USD, IdempotencyKey: "k-1", Metadata: alice}
+		var first, second *app.ApprovalRequiredError
+		errors.As(service.TransferMoney(cmd), &first)
+		errors.As(service.TransferMoney(cmd), &second)
+		if first == nil || second == nil || first.Approval.ID != second.Approval.ID {
+			t.Fatalf("expected both attempts to return one approval, got %v and %v", first, second)
+		}
+		pending, _ := service.ListApprovals(ctx, app.ListApprovalsQuery{Status: domain.ApprovalPending})
+		if len(pending) != 1 {
+			t.Errorf("expected 1 pending approval, got %d", len(pending))
+		}
+	})
+
+	t.Run("RejectRecordsWhy", func(t *testing.T) {
+		service := newService(t, thresholds)
+		var held *app.ApprovalRequiredError
+		errors.As(service.TransferMoney(app.TransferMoneyCommand{SourceAccountID: "ap-a", TargetAccountID: "ap-b", Amount: dec("20000"), Currency: shared.USD, Metadata: alice}), &held)
+
+		approval, err := service.RejectApproval(ctx, app.RejectApprovalCommand{ApprovalID: held.Approval.ID, Reason: "duplicate payment", Metadata: events.Metadata{Actor: "bob"}})
+		if err != nil {
+			t.Fatalf("RejectApproval failed: %v", err)
+		}
+		if approval.Status != domain.ApprovalRejected || approval.Reason != "duplicate payment" || approval.DecidedBy != "bob" {
+			t.Errorf("unexpected approval %+v", approval)
+		}
+		if _, err := service.Approve(ctx, app.ApproveCommand{ApprovalID: held.Approval.ID, Metadata: events.Metadata{Actor: "carol"}}); err == nil {
+			t.Error("expected a rejected approval to refuse approvals")
+		}
+		if got := balance(t, service, "ap-a"); !got.Equal(dec("50000")) {
+			t.Errorf("rejected transfer moved money: ap-a has %s", got)
+		}
+	})
+
+	t.Run("UnapprovedRequestsExpire", func(t *testing.T) {
+		service := newService(t, app.ApprovalPolicy{TTL: time.Millisecond})
+		approval, err := service.RequestAccountClosure(ctx, app.CloseAccountCommand{AccountID: "ap-a", Metadata: alice})
+		if err != nil {
+			t.Fatalf("RequestAccountClosure failed: %v", err)
+		}
+		time.Sleep(2 * time.Millisecond)
+
+		expired, err := service.ListApprovals(ctx, app.ListApprovalsQuery{Status: domain.ApprovalExpired})
+		if err != nil || len(expired) != 1 || expired[0].ID != approval.ID || expired[0].Reason == "" {
+			t.Fatalf("expected the closure to have expired with a reason, got %+v (err: %v)", expired, err)
+		}
+		if _, err := service.Approve(ctx, app.ApproveCommand{ApprovalID: approval.ID, Metadata: events.Metadata{Actor: "bob"}}); err == nil {
+			t.Error("expected an expired approval to refuse approvals")
+		}
+	})
+
+	t.Run("CloseAccount", func(t *testing.T) {
+		service := newService(t, app.ApprovalPolicy{})
+		approval, err := service.RequestAccountClosure(ctx, app.CloseAccountCommand{AccountID: "ap-a", Metadata: alice})
+		if err != nil {
+			t.Fatalf("RequestAccountClosure failed: %v", err)
+		}
+
+		// The account still holds funds, so the approved closure fails.
+		failed, err := service.Approve(ctx, app.ApproveCommand{ApprovalID: approval.ID, Metadata: events.Metadata{Actor: "bob"}})
+		if err == nil || failed.Status != domain.ApprovalFailed || failed.Reason == "" {
+			t.Fatalf("expected the closure to fail with a reason, got %+v (err: %v)", failed, err)
+		}
+
+		_ = service.Withdraw(app.WithdrawMoneyCommand{AccountID: "ap-a", Amount: dec("50000"), Currency: shared.USD})
+		approval, _ = service.RequestAccountClosure(ctx, app.CloseAccountCommand{AccountID: "ap-a", Metadata: alice})
+		if _, err := service.Approve(ctx, app.ApproveCommand{ApprovalID: approval.ID, Metadata: events.Metadata{Actor: "bob"}}); err != nil {
+			t.Fatalf("Approve failed: %v", err)
+		}
+		err = service.Deposit(app.DepositMoneyCommand{AccountID: "ap-a", Amount: dec("1"), Currency: shared.USD})
+		if !errors.Is(err, domain.ErrAccountClosed) {
+			t.Errorf("expected deposits to a closed account to fail, got %v", err)
+		}
+		err = service.TransferMoney(app.TransferMoneyCommand{SourceAccountID: "ap-b", TargetAccountID: "ap-a", Amount: dec("1"), Currency: shared.USD})
+		if !errors.Is(err, domain.ErrAccountClosed) {
+			t.Errorf("expected transfers to a closed account to fail, got %v", err)
+		}
+		if got := balance(t, service, "ap-b"); !got.Equal(dec("50000")) {
+			t.Errorf("refused transfer debited ap-b: %s", got)
+		}
+		page, _ := service.ListAccounts(ctx, app.ListAccountsQuery{Status: app.AccountStatusClosed})
+		if page == nil || len(page.Accounts) != 1 || page.Accounts[0].AccountID != "ap-a" {
+			t.Errorf("expected ap-a listed as closed, got %+v", page)
+		}
+	})
+
+	t.Run("ReverseDeposit", func(t *testing.T) {
+		service := newService(t, app.ApprovalPolicy{})
+		from := pause()
+		_ = service.Deposit(app.DepositMoneyCommand{AccountID: "ap-a", Amount: dec("250"), Currency: shared.USD})
+		history, _ := service.GetTransactionHistory(app.GetHistoryQuery{AccountID: "ap-a"})
+		deposit := history[len(history)-1].GetBase().EventID.String()
+
+		if _, err := service.RequestReversal(ctx, app.ReverseTransactionCommand{AccountID: "ap-a", EventID: history[0].GetBase().EventID.String(), Metadata: alice}); err == nil {
+			t.Error("expected reversing an account opening to be refused")
+		}
+		approval, err := service.RequestReversal(ctx, app.ReverseTransactionCommand{AccountID: "ap-a", EventID: deposit, Metadata: alice})
+		if err != nil {
+			t.Fatalf("RequestReversal failed: %v", err)
+		}
+		if _, err := service.Approve(ctx, app.ApproveCommand{ApprovalID: approval.ID, Metadata: events.Metadata{Actor: "bob"}}); err != nil {
+			t.Fatalf("Approve failed: %v", err)
+		}
+		if got := balance(t, service, "ap-a"); !got.Equal(dec("50000")) {
+			t.Errorf("expected the deposit to be undone, got %s", got)
+		}
+
+		statement, err := service.GenerateStatement(ctx, app.GenerateStatementQuery{AccountID: "ap-a", From: from, To: pause()})
+		if err != nil {
+			t.Fatalf("GenerateStatement failed: %v", err)
+		}
+		lines := statement.Currencies[0].Lines
+		if len(lines) != 2 || !lines[1].Amount.Equal(dec("-250")) {
+			t.Errorf("expected the deposit and its reversal on the statement, got %+v", lines)
+		}
+
+		again, _ := service.RequestReversal(ctx, app.ReverseTransactionCommand{AccountID: "ap-a", EventID: deposit, Metadata: alice})
+		if _, err := service.Approve(ctx, app.ApproveCommand{ApprovalID: again.ID, Metadata: events.Metadata{Actor: "bob"}}); err == nil {
+			t.Error("expected a second reversal of the same deposit to fail")
+		}
+	})
+
+	t.Run("EventTrail", func(t *testing.T) {
+		es := store.NewInMemoryEventStore()
+		service := newServiceOn(t, es, thresholds)
+		var held *app.ApprovalRequiredError
+		errors.As(service.TransferMoney(app.TransferMoneyCommand{SourceAccountID: "ap-a", TargetAccountID: "ap-b", Amount: dec("20000"), Currency: shared.USD, Metadata: alice}), &held)
+		if _, err := service.Approve(ctx, app.ApproveCommand{ApprovalID: held.Approval.ID, Metadata: events.Metadata{Actor: "bob"}}); err != nil {
+			t.Fatalf("Approve failed: %v", err)
+		}
+
+		stream, err := es.GetEvents(domain.ApprovalStreamID(held.Approval.ID))
+		if err != nil {
+			t.Fatalf("GetEvents failed: %v", err)
+		}
+		var types []events.EventType
+		for _, e := range stream {
+			types = append(types, e.GetBase().Type)
+		}
+		want := []events.EventType{events.ApprovalRequestedType, events.ApprovalGrantedType, events.ApprovalExecutedType}
+		if !slices.Equal(types, want) || stream[0].GetBase().Metadata.Actor != "alice" || stream[1].GetBase().Metadata.Actor != "bob" {
+			t.Errorf("expected %v by alice then bob, got %v", want, stream)
+		}
+		if page, _ := service.ListAccounts(ctx, app.ListAccountsQuery{}); page == nil || page.Total != 2 {
+			t.Errorf("expected approval streams to stay out of the account directory, got %+v", page)
+		}
+	})
+
+	t.Run("LostOutcomeIsRecordedOnRetry", func(t *testing.T) {
+		es := &outcomeLosingEventStore{InMemoryEventStore: store.NewInMemoryEventStore(), losses: 1}
+		service := newServiceOn(t, es, thresholds)
+		var held *app.ApprovalRequiredError
+		errors.As(service.TransferMoney(app.TransferMoneyCommand{SourceAccountID: "ap-a", TargetAccountID: "ap-b", Amount: dec("20000"), Currency: shared.USD, Metadata: alice}), &held)
+		bob := app.ApproveCommand{ApprovalID: held.Approval.ID, Metadata: events.Metadata{Actor: "bob"}}
+
+		if _, err := service.Approve(ctx, bob); err == nil {
+			t.Fatal("expected the lost outcome to be reported")
+		}
+		if approval, _ := service.GetApproval(ctx, held.Approval.ID); approval.Status != domain.ApprovalPending || len(approval.Approvals) != 0 {
+			t.Fatalf("expected the approval to stay pending without the lost approval, got %+v", approval)
+		}
+		approval, err := service.Approve(ctx, bob)
+		if err != nil || approval.Status != domain.ApprovalExecuted {
+			t.Fatalf("expected approving again to record the outcome, got %+v (err: %v)", approval, err)
+		}
+		if got := balance(t, service, "ap-b"); !got.Equal(dec("70000")) {
+			t.Errorf("expected the transfer to be made once, ap-b has %s", got)
+		}
+	})
+
+	t.Run("ClosureRetryReturnsTheSameApproval", func(t *testing.T) {
+		service := newService(t, app.ApprovalPolicy{})
+		cmd := app.CloseAccountCommand{AccountID: "ap-a", IdempotencyKey: "close-1", Metadata: alice}
+		first, err := service.RequestAccountClosure(ctx, cmd)
+		if err != nil {
+			t.Fatalf("RequestAccountClosure failed: %v", err)
+		}
+		second, err := service.RequestAccountClosure(ctx, cmd)
+		if err != nil || second.ID != first.ID {
+			t.Fatalf("expected the retry to return approval %s, got %+v (err: %v)", first.ID, second, err)
+		}
+		pending, _ := service.ListApprovals(ctx, app.ListApprovalsQuery{Status: domain.ApprovalPending})
+		if len(pending) != 1 {
+			t.Errorf("expected 1 pending approval, got %d", len(pending))
+		}
+	})
+
+	t.Run("SecondPendingRequestRefused", func(t *testing.T) {
+		service := newService(t, app.ApprovalPolicy{})
+		if _, err := service.RequestAccountClosure(ctx, app.CloseAccountCommand{AccountID: "ap-a", Metadata: alice}); err != nil {
+			t.Fatalf("RequestAccountClosure failed: %v", err)
+		}
+		_, err := service.RequestAccountClosure(ctx, app.CloseAccountCommand{AccountID: "ap-a", Metadata: alice})
+		if !errors.Is(err, domain.ErrApprovalPending) {
+			t.Errorf("expected ErrApprovalPending for a second closure, got %v", err)
+		}
+		if _, err := service.RequestAccountClosure(ctx, app.CloseAccountCommand{AccountID: "ap-b", Metadata: alice}); err != nil {
+			t.Errorf("expected the closure of another account to be held, got %v", err)
+		}
+
+		_ = service.Deposit(app.DepositMoneyCommand{AccountID: "ap-b", Amount: dec("5"), Currency: shared.USD})
+		history, _ := service.GetTransactionHistory(app.GetHistoryQuery{AccountID: "ap-b"})
+		reversal := app.ReverseTransactionCommand{AccountID: "ap-b", EventID: history[len(history)-1].GetBase().EventID.String(), Metadata: alice}
+		if _, err := service.RequestReversal(ctx, reversal); err != nil {
+			t.Fatalf("RequestReversal failed: %v", err)
+		}
+		if _, err := service.RequestReversal(ctx, reversal); !errors.Is(err, domain.ErrApprovalPending) {
+			t.Errorf("expected ErrApprovalPending for a second reversal, got %v", err)
+		}
+	})
+}
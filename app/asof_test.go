@@ -0,0 +1,105 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/app"
+	"financial-ledger/domain"
+	"financial-ledger/shared"
+)
+
+func TestAccountService_GetBalanceAsOf(t *testing.T) {
+	service, _, snapshotStore := setup()
+	ctx := context.Background()
+	id := "acc-asof-1"
+
+	beforeCreate := time.Now().UTC()
+	time.Sleep(time.Millisecond)
+	_, err := service.CreateAccount(app.CreateAccountCommand{AccountID: id, InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: dec("0")}})
+	if err != nil {
+		t.Fatalf("CreateAccount failed: %v", err)
+	}
+
+	// Versions 2..250 are deposits of 1, so the balance after version v is v-1.
+	// Snapshots are taken at versions 100 and 200.
+	var midpoint time.Time
+	for v := 2; v <= 250; v++ {
+		if err := service.Deposit(app.DepositMoneyCommand{AccountID: id, Amount: dec("1"), Currency: shared.USD}); err != nil {
+			t.Fatalf("Deposit for version %d failed: %v", v, err)
+		}
+		if v == 150 {
+			time.Sleep(time.Millisecond)
+			midpoint = time.Now().UTC()
+			time.Sleep(time.Millisecond)
+		}
+	}
+	if snap, _, _ := snapshotStore.GetLatestSnapshot(id); snap == nil || snap.Version != 200 {
+		t.Fatalf("expected latest snapshot at version 200, got %+v", snap)
+	}
+
+	balanceAt := func(t *testing.T, q app.GetBalanceQuery) decimal.Decimal {
+		t.Helper()
+		q.AccountID = id
+		balances, err := service.GetCurrentBalanceContext(ctx, q)
+		if err != nil {
+			t.Fatalf("GetCurrentBalance failed: %v", err)
+		}
+		return balances[shared.USD]
+	}
+
+	t.Run("ByVersion", func(t *testing.T) {
+		for _, v := range []int{1, 99, 100, 101, 150, 200, 249, 250} {
+			want := decimal.NewFromInt(int64(v - 1))
+			if got := balanceAt(t, app.GetBalanceQuery{AsOfVersion: v}); !got.Equal(want) {
+				t.Errorf("balance as of version %d: expected %s, got %s", v, want, got)
+			}
+		}
+	})
+
+	t.Run("ByTime", func(t *testing.T) {
+		if got := balanceAt(t, app.GetBalanceQuery{AsOf: &midpoint}); !got.Equal(dec("149")) {
+			t.Errorf("balance at midpoint: expected 149, got %s", got)
+		}
+		now := time.Now().UTC()
+		if got := balanceAt(t, app.GetBalanceQuery{AsOf: &now}); !got.Equal(dec("249")) {
+			t.Errorf("balance now: expected 249, got %s", got)
+		}
+	})
+
+	t.Run("BeforeAccountExisted", func(t *testing.T) {
+		_, err := service.GetCurrentBalanceContext(ctx, app.GetBalanceQuery{AccountID: id, AsOf: &beforeCreate})
+		if !errors.Is(err, domain.ErrAccountNotFound) {
+			t.Errorf("expected ErrAccountNotFound, got %v", err)
+		}
+	})
+
+	t.Run("VersionOutOfRange", func(t *testing.T) {
+		_, err := service.GetCurrentBalanceContext(ctx, app.GetBalanceQuery{AccountID: id, AsOfVersion: 251})
+		if !errors.Is(err, app.ErrAsOfOutOfRange) {
+			t.Errorf("expected ErrAsOfOutOfRange, got %v", err)
+		}
+	})
+
+	t.Run("VersionAndTime", func(t *testing.T) {
+		_, err := service.GetCurrentBalanceContext(ctx, app.GetBalanceQuery{AccountID: id, AsOfVersion: 10, AsOf: &midpoint})
+		if err == nil {
+			t.Errorf("expected error when both as-of version and time are set")
+		}
+	})
+
+	t.Run("CurrencyFilter", func(t *testing.T) {
+		usd := shared.USD
+		balances, err := service.GetCurrentBalanceContext(ctx, app.GetBalanceQuery{AccountID: id, Currency: &usd, AsOfVersion: 42})
+		if err != nil {
+			t.Fatalf("GetCurrentBalance failed: %v", err)
+		}
+		if len(balances) != 1 || !balances[shared.USD].Equal(dec("41")) {
+			t.Errorf("expected USD 41, got %v", balances)
+		}
+	})
+}
@@ -0,0 +1,237 @@
+package app
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"financial-ledger/events"
+	"financial-ledger/merkle"
+	"financial-ledger/store"
+)
+
+var (
+	ErrGlobalLogUnsupported = errors.New("event store does not expose a global log")
+	ErrEventNotFound        = errors.New("event not found")
+	ErrEventNotCheckpointed = errors.New("event is not covered by a checkpoint yet")
+)
+
+// Signer signs ledger records; see store.Signer.
+type Signer = store.Signer
+
+// WithCheckpointStore replaces the default in-memory checkpoint store.
+func WithCheckpointStore(cs store.CheckpointStore) ServiceOption {
+	return func(s *AccountService) {
+		if cs != nil {
+			s.checkpointStore = cs
+		}
+	}
+}
+
+// WithCheckpointSigner signs every checkpoint created by the service.
+func WithCheckpointSigner(signer Signer) ServiceOption {
+	return func(s *AccountService) {
+		s.checkpointSigner = signer
+	}
+}
+
+// WithVerificationKeys makes VerifyCheckpoints require every checkpoint to be
+// signed by one of the keys in keyring.
+func WithVerificationKeys(keyring *store.Keyring) ServiceOption {
+	return func(s *AccountService) {
+		s.verificationKeys = keyring
+	}
+}
+
+// EventProof is the answer to an inclusion-proof request: the proof itself and
+// the checkpoint whose root it verifies against.
+type EventProof struct {
+	Proof      merkle.InclusionProof `json:"proof"`
+	Checkpoint store.Checkpoint      `json:"checkpoint"`
+}
+
+func (s *AccountService) globalLog() (store.GlobalLog, error) {
+	gl, ok := s.eventStore.(store.GlobalLog)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrGlobalLogUnsupported, s.eventStore)
+	}
+	return gl, nil
+}
+
+// CreateCheckpoint computes the Merkle root over the whole global log and
+// persists it as a new checkpoint. If no events were committed since the latest
+// checkpoint, that checkpoint is returned unchanged.
+func (s *AccountService) CreateCheckpoint(ctx context.Context) (store.Checkpoint, error) {
+	gl, err := s.globalLog()
+	if err != nil {
+		return store.Checkpoint{}, err
+	}
+
+	all, err := gl.ReadAll(ctx, 0, 0)
+	if err != nil {
+		return store.Checkpoint{}, fmt.Errorf("failed to read global log for checkpoint: %w", err)
+	}
+
+	latest, found, err := s.checkpointStore.GetLatestCheckpoint(ctx)
+	if err != nil {
+		return store.Checkpoint{}, fmt.Errorf("failed to read latest checkpoint: %w", err)
+	}
+	if found && latest.TreeSize == int64(len(all)) {
+		return latest, nil
+	}
+	if len(all) == 0 {
+		return store.Checkpoint{}, errors.New("cannot checkpoint an empty ledger")
+	}
+
+	checkpoint := store.Checkpoint{
+		TreeSize:  int64(len(all)),
+		RootHash:  hex.EncodeToString(merkle.Root(leafHashes(all))),
+		Timestamp: time.Now().UTC(),
+	}
+	if s.checkpointSigner != nil {
+		sig, err := s.checkpointSigner.Sign(checkpoint.SigningPayload())
+		if err != nil {
+			return store.Checkpoint{}, fmt.Errorf("failed to sign checkpoint at tree size %d: %w", checkpoint.TreeSize, err)
+		}
+		checkpoint.KeyID = s.checkpointSigner.KeyID()
+		checkpoint.Signature = hex.EncodeToString(sig)
+	}
+
+	if err := s.checkpointStore.SaveCheckpoint(ctx, checkpoint); err != nil {
+		return store.Checkpoint{}, fmt.Errorf("failed to save checkpoint at tree size %d: %w", checkpoint.TreeSize, err)
+	}
+	log.Printf("Checkpoint created at tree size %d, root %s", checkpoint.TreeSize, checkpoint.RootHash)
+	return checkpoint, nil
+}
+
+// RunCheckpoints creates a checkpoint every interval until ctx is cancelled.
+func (s *AccountService) RunCheckpoints(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.CreateCheckpoint(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("Warning: Periodic checkpoint failed: %v", err)
+			}
+		}
+	}
+}
+
+// GetInclusionProof returns a proof that the event with eventID is part of the
+// tree summarised by the latest checkpoint.
+func (s *AccountService) GetInclusionProof(ctx context.Context, eventID uuid.UUID) (*EventProof, error) {
+	gl, err := s.globalLog()
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoint, found, err := s.checkpointStore.GetLatestCheckpoint(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read latest checkpoint: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: no checkpoint has been created", ErrEventNotCheckpointed)
+	}
+
+	covered, err := gl.ReadAll(ctx, 0, int(checkpoint.TreeSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read global log for proof: %w", err)
+	}
+
+	index := -1
+	for i, event := range covered {
+		if event.GetBase().EventID == eventID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		later, err := gl.ReadAll(ctx, checkpoint.TreeSize, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read global log for proof: %w", err)
+		}
+		for _, event := range later {
+			if event.GetBase().EventID == eventID {
+				return nil, fmt.Errorf("%w: event %s is at position %d, latest checkpoint covers %d", ErrEventNotCheckpointed, eventID, event.GetBase().Position, checkpoint.TreeSize)
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", ErrEventNotFound, eventID)
+	}
+
+	leaves := leafHashes(covered)
+	path, err := merkle.AuditPath(leaves, index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build audit path for event %s: %w", eventID, err)
+	}
+	if root := hex.EncodeToString(merkle.Root(leaves)); root != checkpoint.RootHash {
+		return nil, fmt.Errorf("global log no longer matches checkpoint at tree size %d (root %s, recomputed %s)", checkpoint.TreeSize, checkpoint.RootHash, root)
+	}
+
+	proof := merkle.InclusionProof{
+		EventID:   eventID.String(),
+		EventHash: covered[index].GetBase().Hash,
+		LeafIndex: int64(index),
+		TreeSize:  checkpoint.TreeSize,
+		RootHash:  checkpoint.RootHash,
+		AuditPath: make([]string, len(path)),
+	}
+	for i, h := range path {
+		proof.AuditPath[i] = hex.EncodeToString(h)
+	}
+	return &EventProof{Proof: proof, Checkpoint: checkpoint}, nil
+}
+
+// VerifyCheckpoints recomputes the root of every stored checkpoint from the
+// current global log and returns an error for the first one that differs. When
+// verification keys are configured, each checkpoint signature is checked too.
+func (s *AccountService) VerifyCheckpoints(ctx context.Context) (int, error) {
+	gl, err := s.globalLog()
+	if err != nil {
+		return 0, err
+	}
+	checkpoints, err := s.checkpointStore.ListCheckpoints(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+	all, err := gl.ReadAll(ctx, 0, 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read global log: %w", err)
+	}
+	leaves := leafHashes(all)
+
+	for _, cp := range checkpoints {
+		if cp.TreeSize > int64(len(leaves)) {
+			return 0, fmt.Errorf("checkpoint at tree size %d exceeds global log of %d events", cp.TreeSize, len(leaves))
+		}
+		if root := hex.EncodeToString(merkle.Root(leaves[:cp.TreeSize])); root != cp.RootHash {
+			return 0, fmt.Errorf("checkpoint at tree size %d has root %s, but the log now hashes to %s", cp.TreeSize, cp.RootHash, root)
+		}
+		if s.verificationKeys != nil {
+			if cp.Signature == "" {
+				return 0, fmt.Errorf("checkpoint at tree size %d is not signed", cp.TreeSize)
+			}
+			if err := s.verificationKeys.Verify(cp.KeyID, cp.SigningPayload(), cp.Signature); err != nil {
+				return 0, fmt.Errorf("checkpoint at tree size %d: %w", cp.TreeSize, err)
+			}
+		}
+	}
+	return len(checkpoints), nil
+}
+
+// leafHashes turns events into Merkle leaves; each leaf commits to the event's
+// stream-chain hash, which in turn covers its full content.
+func leafHashes(evts []events.Event) [][]byte {
+	leaves := make([][]byte, len(evts))
+	for i, event := range evts {
+		leaves[i] = merkle.LeafHash([]byte(event.GetBase().Hash))
+	}
+	return leaves
+}
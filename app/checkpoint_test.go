@@ -0,0 +1,152 @@
+package app_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/app"
+	"financial-ledger/shared"
+	"financial-ledger/store"
+)
+
+type stubSigner struct{}
+
+func (stubSigner) KeyID() string                       { return "stub-1" }
+func (stubSigner) Sign(message []byte) ([]byte, error) { return []byte("sig"), nil }
+
+func TestAccountService_Checkpoints(t *testing.T) {
+	ctx := context.Background()
+	eventStore := store.NewInMemoryEventStore()
+	service := app.NewAccountService(eventStore, store.NewInMemorySnapshotStore(), app.WithCheckpointSigner(stubSigner{}))
+
+	_, _ = service.CreateAccount(app.CreateAccountCommand{AccountID: "acc-cp-1", InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: dec("100")}})
+	for i := 0; i < 4; i++ {
+		_ = service.Deposit(app.DepositMoneyCommand{AccountID: "acc-cp-1", Amount: dec("1"), Currency: shared.USD})
+	}
+
+	t.Run("NoCheckpointYet", func(t *testing.T) {
+		evts, _ := eventStore.GetEvents("acc-cp-1")
+		_, err := service.GetInclusionProof(ctx, evts[0].GetBase().EventID)
+		if !errors.Is(err, app.ErrEventNotCheckpointed) {
+			t.Errorf("expected ErrEventNotCheckpointed, got %v", err)
+		}
+	})
+
+	checkpoint, err := service.CreateCheckpoint(ctx)
+	if err != nil {
+		t.Fatalf("CreateCheckpoint failed: %v", err)
+	}
+
+	t.Run("CheckpointSigned", func(t *testing.T) {
+		if checkpoint.TreeSize != 5 {
+			t.Errorf("expected tree size 5, got %d", checkpoint.TreeSize)
+		}
+		if checkpoint.KeyID != "stub-1" || checkpoint.Signature == "" {
+			t.Errorf("expected checkpoint to be signed, got %+v", checkpoint)
+		}
+	})
+
+	t.Run("ProofVerifiesAgainstRoot", func(t *testing.T) {
+		evts, _ := eventStore.GetEvents("acc-cp-1")
+		for _, event := range evts {
+			proof, err := service.GetInclusionProof(ctx, event.GetBase().EventID)
+			if err != nil {
+				t.Fatalf("GetInclusionProof failed: %v", err)
+			}
+			if err := proof.Proof.Verify(checkpoint.RootHash); err != nil {
+				t.Errorf("proof for version %d failed to verify: %v", event.GetBase().Version, err)
+			}
+		}
+	})
+
+	t.Run("EventAfterCheckpoint", func(t *testing.T) {
+		_ = service.Deposit(app.DepositMoneyCommand{AccountID: "acc-cp-1", Amount: dec("1"), Currency: shared.USD})
+		evts, _ := eventStore.GetEvents("acc-cp-1")
+		_, err := service.GetInclusionProof(ctx, evts[len(evts)-1].GetBase().EventID)
+		if !errors.Is(err, app.ErrEventNotCheckpointed) {
+			t.Errorf("expected ErrEventNotCheckpointed, got %v", err)
+		}
+	})
+
+	t.Run("UnknownEvent", func(t *testing.T) {
+		_, err := service.GetInclusionProof(ctx, uuid.New())
+		if !errors.Is(err, app.ErrEventNotFound) {
+			t.Errorf("expected ErrEventNotFound, got %v", err)
+		}
+	})
+
+	t.Run("CheckpointsStillMatchLog", func(t *testing.T) {
+		if _, err := service.CreateCheckpoint(ctx); err != nil {
+			t.Fatalf("second CreateCheckpoint failed: %v", err)
+		}
+		n, err := service.VerifyCheckpoints(ctx)
+		if err != nil || n != 2 {
+			t.Errorf("expected 2 verified checkpoints, got %d (err: %v)", n, err)
+		}
+	})
+}
+
+func TestAccountService_VerifyCheckpointSignatures(t *testing.T) {
+	ctx := context.Background()
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	signer := store.NewEd25519Signer(priv)
+	trusted := store.NewKeyring(signer.PublicKey())
+
+	newService := func(es store.EventStore, cs store.CheckpointStore, keyring *store.Keyring) *app.AccountService {
+		return app.NewAccountService(es, store.NewInMemorySnapshotStore(),
+			app.WithCheckpointStore(cs), app.WithCheckpointSigner(signer), app.WithVerificationKeys(keyring))
+	}
+
+	eventStore := store.NewInMemoryEventStore(store.WithEventSigner(signer))
+	service := newService(eventStore, store.NewInMemoryCheckpointStore(), trusted)
+	_, _ = service.CreateAccount(app.CreateAccountCommand{AccountID: "acc-sig-1"})
+	checkpoint, err := service.CreateCheckpoint(ctx)
+	if err != nil {
+		t.Fatalf("CreateCheckpoint failed: %v", err)
+	}
+
+	t.Run("TrustedKey", func(t *testing.T) {
+		if n, err := service.VerifyCheckpoints(ctx); err != nil || n != 1 {
+			t.Errorf("expected 1 verified checkpoint, got %d (err: %v)", n, err)
+		}
+	})
+
+	t.Run("UntrustedKey", func(t *testing.T) {
+		_, otherPriv, _ := ed25519.GenerateKey(rand.Reader)
+		cs := store.NewInMemoryCheckpointStore()
+		_ = cs.SaveCheckpoint(ctx, checkpoint)
+		verifier := newService(eventStore, cs, store.NewKeyring(otherPriv.Public().(ed25519.PublicKey)))
+		if _, err := verifier.VerifyCheckpoints(ctx); !errors.Is(err, store.ErrUnknownSigningKey) {
+			t.Errorf("expected ErrUnknownSigningKey, got %v", err)
+		}
+	})
+
+	t.Run("TamperedCheckpoint", func(t *testing.T) {
+		tampered := checkpoint
+		tampered.Timestamp = tampered.Timestamp.Add(time.Hour)
+		cs := store.NewInMemoryCheckpointStore()
+		_ = cs.SaveCheckpoint(ctx, tampered)
+		verifier := newService(eventStore, cs, trusted)
+		if _, err := verifier.VerifyCheckpoints(ctx); !errors.Is(err, store.ErrInvalidSignature) {
+			t.Errorf("expected ErrInvalidSignature, got %v", err)
+		}
+	})
+
+	t.Run("UnsignedCheckpoint", func(t *testing.T) {
+		unsigned := checkpoint
+		unsigned.KeyID, unsigned.Signature = "", ""
+		cs := store.NewInMemoryCheckpointStore()
+		_ = cs.SaveCheckpoint(ctx, unsigned)
+		verifier := newService(eventStore, cs, trusted)
+		if _, err := verifier.VerifyCheckpoints(ctx); err == nil {
+			t.Errorf("expected error for unsigned checkpoint")
+		}
+	})
+}
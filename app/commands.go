@@ -1,29 +1,113 @@
 package app
 
 import (
+	"time"
+
 	"github.com/shopspring/decimal"
 
+	"financial-ledger/domain"
+	"financial-ledger/events"
 	"financial-ledger/shared"
+	"financial-ledger/store"
 )
 
 // --- Command Struct Definitions ---
 // Commands represent the intent to perform an action or change state in the system.
+// Commands that open, change or move money between accounts and customers take
+// an optional IdempotencyKey: a retry carrying the same key and payload returns
+// the original result instead of executing the command again. So do closure
+// and reversal requests, whose retries return the approval the first attempt
+// opened. Approval decisions, alert dispositions, webhook subscriptions and
+// ForgetSubject take no key and are not deduplicated.
+// Metadata is copied onto every event the command produces; a CorrelationID is
+// generated when the caller leaves it empty.
+
+// PersonalDetails is personal data about an account holder. The ledger stores
+// it only encrypted under the data subject's key, so ForgetSubject can erase it.
+// Identifiers are document or registration numbers, such as a passport
+// number, which sanctions screening checks along with OwnerName.
+type PersonalDetails struct {
+	OwnerName   string
+	Address     string
+	Notes       string
+	Identifiers []string
+}
 
+// CreateAccountCommand optionally carries the holder's Details. SubjectID names
+// the data subject they belong to and defaults to the account ID. Holders are
+// the IDs of the customers who own the account, more than one for a joint
+// account; each must already be registered.
 type CreateAccountCommand struct {
 	AccountID       string
 	InitialBalances map[shared.Currency]decimal.Decimal
+	SubjectID       string
+	Details         *PersonalDetails
+	Holders         []string
+	IdempotencyKey  string
+	Metadata        events.Metadata
+}
+
+type UpdateAccountDetailsCommand struct {
+	AccountID      string
+	SubjectID      string
+	Details        PersonalDetails
+	IdempotencyKey string
+	Metadata       events.Metadata
+}
+
+// CustomerDetails is personal data about a customer, stored only encrypted
+// under the customer's own subject key. Identifiers are document or
+// registration numbers, as in PersonalDetails.
+type CustomerDetails struct {
+	Name        string
+	Identifiers []string
+	Email       string
+	Phone       string
+	Address     string
+}
+
+// CreateCustomerCommand registers a customer. CustomerID is generated if
+// empty, from IdempotencyKey when that is set, and KYCTier defaults to
+// domain.KYCTierNone.
+type CreateCustomerCommand struct {
+	CustomerID     string
+	Details        CustomerDetails
+	KYCTier        domain.KYCTier
+	IdempotencyKey string
+	Metadata       events.Metadata
+}
+
+// UpdateCustomerCommand replaces the customer's details if Details is set,
+// and its KYC tier if KYCTier is set.
+type UpdateCustomerCommand struct {
+	CustomerID     string
+	Details        *CustomerDetails
+	KYCTier        domain.KYCTier
+	IdempotencyKey string
+	Metadata       events.Metadata
+}
+
+type ForgetSubjectCommand struct {
+	SubjectID string
+	Metadata  events.Metadata
 }
 
 type DepositMoneyCommand struct {
-	AccountID string
-	Amount    decimal.Decimal
-	Currency  shared.Currency
+	AccountID       string
+	Amount          decimal.Decimal
+	Currency        shared.Currency
+	ExpectedVersion int // 0 skips the check; see ErrVersionMismatch
+	IdempotencyKey  string
+	Metadata        events.Metadata
 }
 
 type WithdrawMoneyCommand struct {
-	AccountID string
-	Amount    decimal.Decimal
-	Currency  shared.Currency
+	AccountID       string
+	Amount          decimal.Decimal
+	Currency        shared.Currency
+	ExpectedVersion int // 0 skips the check; see ErrVersionMismatch
+	IdempotencyKey  string
+	Metadata        events.Metadata
 }
 
 type TransferMoneyCommand struct {
@@ -31,20 +115,129 @@ type TransferMoneyCommand struct {
 	TargetAccountID string
 	Amount          decimal.Decimal
 	Currency        shared.Currency
+	ExpectedVersion int // of the source account; 0 skips the check
+	IdempotencyKey  string
+	Metadata        events.Metadata
 }
 
 type ConvertCurrencyCommand struct {
-	AccountID    string
-	FromAmount   decimal.Decimal
-	FromCurrency shared.Currency
-	ToCurrency   shared.Currency
+	AccountID       string
+	FromAmount      decimal.Decimal
+	FromCurrency    shared.Currency
+	ToCurrency      shared.Currency
+	ExpectedVersion int // 0 skips the check; see ErrVersionMismatch
+	IdempotencyKey  string
+	Metadata        events.Metadata
+}
+
+// CloseAccountCommand asks to close an account. Closing needs a second user's
+// approval, and every balance must be zero by the time it is approved.
+type CloseAccountCommand struct {
+	AccountID      string
+	IdempotencyKey string
+	Metadata       events.Metadata
+}
+
+// ReverseTransactionCommand asks to reverse the deposit or withdrawal EventID
+// on AccountID. Like closing, it needs a second user's approval.
+type ReverseTransactionCommand struct {
+	AccountID      string
+	EventID        string
+	IdempotencyKey string
+	Metadata       events.Metadata
+}
+
+// ApproveCommand approves an approval on behalf of Metadata.Actor, who must
+// not be the user who requested it.
+type ApproveCommand struct {
+	ApprovalID string
+	Metadata   events.Metadata
+}
+
+// RejectApprovalCommand turns an approval down for Reason.
+type RejectApprovalCommand struct {
+	ApprovalID string
+	Reason     string
+	Metadata   events.Metadata
+}
+
+// DispositionAlertCommand records Metadata.Actor's review of a monitoring
+// alert: store.AlertEscalated, store.AlertCleared or store.AlertReported,
+// with a Note explaining it.
+type DispositionAlertCommand struct {
+	AlertID  string
+	Status   store.AlertStatus
+	Note     string
+	Metadata events.Metadata
+}
+
+// AddWebhookCommand subscribes URL to the given event types, which may include
+// webhook.CommandRejectedType. No types means every type.
+type AddWebhookCommand struct {
+	URL        string
+	EventTypes []string
 }
 
 // --- Query Structures (Input for Read Operations) ---
 
+// ListApprovalsQuery selects approvals by status and by an account they touch.
+// Unset filters match every approval.
+type ListApprovalsQuery struct {
+	Status    domain.ApprovalStatus
+	AccountID string
+}
+
+// ListAlertsQuery selects monitoring alerts by status, account and rule.
+// Unset filters match every alert.
+type ListAlertsQuery struct {
+	Status    store.AlertStatus
+	AccountID string
+	RuleID    string
+}
+
+// GetBalanceQuery asks for current balances, or for balances as they stood
+// after AsOfVersion or at time AsOf. At most one of the two may be set.
 type GetBalanceQuery struct {
+	AccountID   string
+	Currency    *shared.Currency
+	AsOfVersion int
+	AsOf        *time.Time
+}
+
+type GetAccountDetailsQuery struct {
 	AccountID string
-	Currency  *shared.Currency
+}
+
+// GetCustomerAccountsQuery asks for a customer's accounts and their
+// consolidated balances, with the total converted to ReportingCurrency.
+type GetCustomerAccountsQuery struct {
+	CustomerID        string
+	ReportingCurrency shared.Currency
+}
+
+// ListAccountsQuery selects accounts from the directory. Unset filters match
+// every account. MinBalance, MaxBalance and sorting by balance refer to the
+// balance in Currency, which must then be set. HolderID selects the accounts
+// a customer holds, alone or jointly. Limit 0 means no limit.
+type ListAccountsQuery struct {
+	Prefix     string
+	IDs        []string
+	HolderID   string
+	Currency   *shared.Currency
+	MinBalance *decimal.Decimal
+	MaxBalance *decimal.Decimal
+	Status     AccountStatus
+	SortBy     AccountSortField
+	Descending bool
+	Offset     int
+	Limit      int
+}
+
+// GenerateStatementQuery asks for a statement of the period [From, To).
+type GenerateStatementQuery struct {
+	AccountID string
+	From      time.Time
+	To        time.Time
 }
 
 type GetHistoryQuery struct {
@@ -52,3 +245,22 @@ type GetHistoryQuery struct {
 	Limit     int
 	Skip      int
 }
+
+// SearchHistoryQuery selects events from an account's history. Unset filters
+// match everything; From is inclusive and To exclusive. Amount and currency
+// filters apply to the legs of an event that touch this account, so events
+// without money movement (such as details updates) never match them. Cursor is
+// the NextCursor of a previous page and must be used with the same ordering.
+type SearchHistoryQuery struct {
+	AccountID    string
+	Types        []events.EventType
+	Currency     *shared.Currency
+	From         *time.Time
+	To           *time.Time
+	MinAmount    *decimal.Decimal
+	MaxAmount    *decimal.Decimal
+	Counterparty string
+	Descending   bool
+	Limit        int
+	Cursor       string
+}
@@ -0,0 +1,387 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/domain"
+	"financial-ledger/events"
+	"financial-ledger/screening"
+	"financial-ledger/shared"
+	"financial-ledger/store"
+)
+
+// customerDirectoryProjectionName is the name the customer directory
+// registers under with the projection runner.
+const customerDirectoryProjectionName = "customer-directory"
+
+// CustomerProfile is the view of a customer with its details decrypted.
+// Redacted is set, and every detail holds RedactedValue, once the customer
+// has been forgotten.
+type CustomerProfile struct {
+	CustomerID string
+	CustomerDetails
+	KYCTier   domain.KYCTier
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Redacted  bool
+}
+
+// CustomerAccounts is a customer's accounts with their balances consolidated
+// per currency. A joint account counts in full for each of its holders. Total
+// is the sum of Balances converted to ReportingCurrency at the current rates,
+// unrounded; Rates holds the rate used for each currency.
+type CustomerAccounts struct {
+	CustomerID        string
+	Accounts          []AccountSummary
+	Balances          map[shared.Currency]decimal.Decimal
+	ReportingCurrency shared.Currency
+	Rates             map[shared.Currency]decimal.Decimal
+	Total             decimal.Decimal
+}
+
+// heldCustomerCreation is a customer registration held for approval by
+// sanctions screening, with the details sealed as in heldAccountCreation.
+type heldCustomerCreation struct {
+	Command CreateCustomerCommand
+	Details events.SealedPersonalData
+}
+
+// CreateCustomer registers a customer, with its details sealed under the
+// customer ID as data subject. When sanctions screening is on, the customer
+// is screened first, as account holders are.
+func (s *AccountService) CreateCustomer(ctx context.Context, cmd CreateCustomerCommand) (string, error) {
+	if cmd.CustomerID == "" {
+		if cmd.IdempotencyKey != "" {
+			cmd.CustomerID = idForKey(cmd.IdempotencyKey)
+		} else {
+			cmd.CustomerID = uuid.NewString()
+		}
+		log.Printf("No CustomerID provided, generated new ID: %s", cmd.CustomerID)
+	}
+	if cmd.Details.Name == "" {
+		return "", fmt.Errorf("customer %s must have a name", cmd.CustomerID)
+	}
+	if cmd.KYCTier != "" {
+		tier, err := domain.ParseKYCTier(string(cmd.KYCTier))
+		if err != nil {
+			return "", err
+		}
+		cmd.KYCTier = tier
+	}
+	done, err := s.alreadyProcessed(ctx, cmd.IdempotencyKey, cmd, domain.CustomerStreamID(cmd.CustomerID))
+	if err != nil {
+		return "", err
+	}
+	if done {
+		return s.createCustomer(ctx, cmd, nil)
+	}
+	_, err = s.loadCustomer(ctx, cmd.CustomerID)
+	if err == nil {
+		return "", fmt.Errorf("%w: %s", domain.ErrCustomerExists, cmd.CustomerID)
+	}
+	if !errors.Is(err, domain.ErrCustomerNotFound) {
+		return "", fmt.Errorf("failed to check for existing customer %s: %w", cmd.CustomerID, err)
+	}
+
+	subject := screening.Subject{Name: cmd.Details.Name, Identifiers: cmd.Details.Identifiers}
+	about := store.ScreeningDecision{Operation: "customer registration", CustomerID: cmd.CustomerID}
+	err = s.screen(ctx, about, cmd.CustomerID, subject, cmd.IdempotencyKey, cmd.Metadata, func(note string) (domain.Approval, error) {
+		sealed, err := s.sealDetails(ctx, cmd.CustomerID, cmd.Details)
+		if err != nil {
+			return domain.Approval{}, err
+		}
+		held := heldCustomerCreation{Command: cmd, Details: sealed}
+		held.Command.Details = CustomerDetails{}
+		return s.requestApproval(ctx, domain.ApprovalKindCreateCustomer, cmd.CustomerID, "registration of customer "+cmd.CustomerID+" ("+note+")", cmd.IdempotencyKey, cmd.Metadata, held)
+	})
+	if err != nil {
+		return "", err
+	}
+	return s.createCustomer(ctx, cmd, nil)
+}
+
+// createCustomer registers the customer, with details if they were sealed
+// already and otherwise with cmd.Details.
+func (s *AccountService) createCustomer(ctx context.Context, cmd CreateCustomerCommand, details *events.SealedPersonalData) (string, error) {
+	streamID := domain.CustomerStreamID(cmd.CustomerID)
+	idem, replay, err := s.beginIdempotent(ctx, cmd.IdempotencyKey, cmd, streamID)
+	if err != nil {
+		return "", err
+	}
+	if replay != nil {
+		return cmd.CustomerID, nil
+	}
+	defer idem.done()
+
+	meta := commandMetadata(cmd.Metadata)
+
+	existing, err := s.loadCustomer(ctx, cmd.CustomerID)
+	if err != nil && !errors.Is(err, domain.ErrCustomerNotFound) {
+		return "", fmt.Errorf("failed to check for existing customer %s: %w", cmd.CustomerID, err)
+	}
+	if existing != nil {
+		return "", fmt.Errorf("%w: %s", domain.ErrCustomerExists, cmd.CustomerID)
+	}
+
+	if details == nil {
+		sealed, err := s.sealDetails(ctx, cmd.CustomerID, cmd.Details)
+		if err != nil {
+			return "", err
+		}
+		details = &sealed
+	}
+
+	customer := domain.NewCustomer(cmd.CustomerID)
+	if err := customer.HandleRegister(*details, cmd.KYCTier); err != nil {
+		return "", fmt.Errorf("customer registration failed validation: %w", err)
+	}
+	err = s.eventStore.SaveEventsContext(ctx, streamID, 0, stampEvents(customer.GetUncommitedChanges(), meta, idem))
+	if err != nil {
+		return "", fmt.Errorf("failed to save registration of customer %s: %w", cmd.CustomerID, err)
+	}
+	s.completeIdempotent(ctx, idem, streamID)
+
+	log.Printf("Customer %s registered with KYC tier %s (actor: %q)", customer.ID, customer.KYCTier, cmd.Metadata.Actor)
+	return customer.ID, nil
+}
+
+// UpdateCustomer replaces a customer's details or KYC tier.
+func (s *AccountService) UpdateCustomer(ctx context.Context, cmd UpdateCustomerCommand) error {
+	if cmd.Details != nil && cmd.Details.Name == "" {
+		return fmt.Errorf("customer %s must have a name", cmd.CustomerID)
+	}
+	streamID := domain.CustomerStreamID(cmd.CustomerID)
+	idem, replay, err := s.beginIdempotent(ctx, cmd.IdempotencyKey, cmd, streamID)
+	if err != nil {
+		return err
+	}
+	if replay != nil {
+		return nil
+	}
+	defer idem.done()
+
+	meta := commandMetadata(cmd.Metadata)
+
+	return s.retryOnConflict(ctx, "customer update", func(attempt int) error {
+		customer, err := s.loadCustomer(ctx, cmd.CustomerID)
+		if err != nil {
+			return fmt.Errorf("failed to load customer %s: %w", cmd.CustomerID, err)
+		}
+
+		initialVersion := customer.Version
+		var sealed *events.SealedPersonalData
+		if cmd.Details != nil {
+			details, err := s.sealDetails(ctx, customer.ID, *cmd.Details)
+			if err != nil {
+				return err
+			}
+			sealed = &details
+		}
+		if err := customer.HandleUpdate(sealed, cmd.KYCTier); err != nil {
+			return fmt.Errorf("update failed for customer %s: %w", cmd.CustomerID, err)
+		}
+
+		err = s.eventStore.SaveEventsContext(ctx, streamID, initialVersion, stampEvents(customer.GetUncommitedChanges(), meta, idem))
+		if err != nil {
+			return fmt.Errorf("failed to save update of customer %s: %w", cmd.CustomerID, err)
+		}
+		s.completeIdempotent(ctx, idem, streamID)
+
+		log.Printf("Customer %s updated, KYC tier %s (actor: %q)", customer.ID, customer.KYCTier, cmd.Metadata.Actor)
+		return nil
+	})
+}
+
+// loadCustomer rebuilds a customer from its stream. Customers are small and
+// rarely change, so they are not snapshotted.
+func (s *AccountService) loadCustomer(ctx context.Context, customerID string) (*domain.Customer, error) {
+	history, err := s.eventStore.GetEventsAfterVersionContext(ctx, domain.CustomerStreamID(customerID), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load events of customer %s: %w", customerID, err)
+	}
+	customer := domain.NewCustomer(customerID)
+	if err := customer.ApplyEvents(history); err != nil {
+		return nil, err
+	}
+	if customer.Version == 0 {
+		return nil, fmt.Errorf("%w: %s", domain.ErrCustomerNotFound, customerID)
+	}
+	return customer, nil
+}
+
+// GetCustomer returns a customer's profile, redacted if the customer has been
+// forgotten.
+func (s *AccountService) GetCustomer(ctx context.Context, customerID string) (*CustomerProfile, error) {
+	customer, err := s.loadCustomer(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load customer %s: %w", customerID, err)
+	}
+	return s.customerProfile(ctx, *customer)
+}
+
+// ListCustomers returns every customer's profile, oldest first, from the
+// customer directory.
+func (s *AccountService) ListCustomers(ctx context.Context) ([]CustomerProfile, error) {
+	if s.projections == nil {
+		return nil, fmt.Errorf("cannot list customers: %w", ErrGlobalLogUnsupported)
+	}
+	if err := s.projections.CatchUp(ctx, customerDirectoryProjectionName); err != nil {
+		return nil, fmt.Errorf("failed to update customer directory: %w", err)
+	}
+	customers := s.customers.list()
+	profiles := make([]CustomerProfile, 0, len(customers))
+	for _, c := range customers {
+		profile, err := s.customerProfile(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, *profile)
+	}
+	return profiles, nil
+}
+
+func (s *AccountService) customerProfile(ctx context.Context, c domain.Customer) (*CustomerProfile, error) {
+	profile := &CustomerProfile{CustomerID: c.ID, KYCTier: c.KYCTier, CreatedAt: c.CreatedAt, UpdatedAt: c.UpdatedAt}
+	details, redacted, err := s.revealCustomerDetails(ctx, c.Details)
+	if err != nil {
+		return nil, err
+	}
+	profile.CustomerDetails, profile.Redacted = details, redacted
+	return profile, nil
+}
+
+// revealCustomerDetails decrypts a customer's details like RevealPersonalData.
+func (s *AccountService) revealCustomerDetails(ctx context.Context, sealed events.SealedPersonalData) (details CustomerDetails, redacted bool, err error) {
+	plaintext, err := s.subjectKeys.Open(ctx, sealed)
+	if errors.Is(err, store.ErrSubjectForgotten) {
+		return CustomerDetails{Name: RedactedValue, Email: RedactedValue, Phone: RedactedValue, Address: RedactedValue}, true, nil
+	}
+	if err != nil {
+		return CustomerDetails{}, false, fmt.Errorf("failed to decrypt details of customer %s: %w", sealed.SubjectID, err)
+	}
+	if err := json.Unmarshal(plaintext, &details); err != nil {
+		return CustomerDetails{}, false, fmt.Errorf("failed to decode details of customer %s: %w", sealed.SubjectID, err)
+	}
+	return details, false, nil
+}
+
+// checkHolders checks that every holder of a new account is a registered
+// customer.
+func (s *AccountService) checkHolders(ctx context.Context, holders []string) error {
+	for _, id := range holders {
+		if _, err := s.loadCustomer(ctx, id); err != nil {
+			return fmt.Errorf("cannot open account for holder %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// GetCustomerAccounts returns the accounts a customer holds, alone or
+// jointly, and their consolidated balances. The total is converted to the
+// query's reporting currency with the service's exchange rates.
+func (s *AccountService) GetCustomerAccounts(ctx context.Context, query GetCustomerAccountsQuery) (*CustomerAccounts, error) {
+	if query.ReportingCurrency == "" {
+		return nil, errors.New("customer accounts query needs a reporting currency")
+	}
+	if _, err := s.loadCustomer(ctx, query.CustomerID); err != nil {
+		return nil, fmt.Errorf("failed to load customer %s: %w", query.CustomerID, err)
+	}
+	page, err := s.ListAccounts(ctx, ListAccountsQuery{HolderID: query.CustomerID})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CustomerAccounts{
+		CustomerID:        query.CustomerID,
+		Accounts:          page.Accounts,
+		Balances:          make(map[shared.Currency]decimal.Decimal),
+		ReportingCurrency: query.ReportingCurrency,
+		Rates:             make(map[shared.Currency]decimal.Decimal),
+	}
+	for _, a := range page.Accounts {
+		for currency, amount := range a.Balances {
+			result.Balances[currency] = result.Balances[currency].Add(amount)
+		}
+	}
+	for currency, amount := range result.Balances {
+		rate, err := s.getExchangeRate(currency, query.ReportingCurrency)
+		if err != nil {
+			return nil, fmt.Errorf("cannot report balances of customer %s in %s: %w", query.CustomerID, query.ReportingCurrency, err)
+		}
+		result.Rates[currency] = rate
+		result.Total = result.Total.Add(amount.Mul(rate))
+	}
+	return result, nil
+}
+
+// customerDirectory is a projection of every customer, in registration order,
+// for ListCustomers. It folds events through domain.Customer and skips account
+// streams.
+type customerDirectory struct {
+	sync.RWMutex
+	customers map[string]*domain.Customer
+	order     []string // customer IDs in registration order
+}
+
+func newCustomerDirectory() *customerDirectory {
+	return &customerDirectory{customers: make(map[string]*domain.Customer)}
+}
+
+func (d *customerDirectory) Name() string {
+	return customerDirectoryProjectionName
+}
+
+func (d *customerDirectory) Reset(ctx context.Context) error {
+	d.Lock()
+	defer d.Unlock()
+	d.customers = make(map[string]*domain.Customer)
+	d.order = nil
+	return nil
+}
+
+func (d *customerDirectory) Apply(ctx context.Context, event events.Event) error {
+	base := event.GetBase()
+	if !domain.IsCustomerStream(base.AggregateID) {
+		return nil
+	}
+	d.Lock()
+	defer d.Unlock()
+
+	customer, ok := d.customers[base.AggregateID]
+	if !ok {
+		registered, isRegistration := event.(events.CustomerRegisteredEvent)
+		if !isRegistration {
+			return fmt.Errorf("customer directory: %s event at position %d for unknown customer stream %s", base.Type, base.Position, base.AggregateID)
+		}
+		customer = domain.NewCustomer(registered.CustomerID)
+		d.customers[base.AggregateID] = customer
+		d.order = append(d.order, base.AggregateID)
+	}
+	if base.Version <= customer.Version {
+		return nil
+	}
+	if err := customer.ApplyEvent(event); err != nil {
+		return fmt.Errorf("customer directory: %w", err)
+	}
+	return nil
+}
+
+// list returns a copy of every customer, oldest first.
+func (d *customerDirectory) list() []domain.Customer {
+	d.RLock()
+	defer d.RUnlock()
+	out := make([]domain.Customer, 0, len(d.order))
+	for _, id := range d.order {
+		out = append(out, *d.customers[id])
+	}
+	return out
+}
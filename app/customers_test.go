@@ -0,0 +1,185 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/app"
+	"financial-ledger/domain"
+	"financial-ledger/events"
+	"financial-ledger/shared"
+	"financial-ledger/store"
+)
+
+func TestAccountService_Customers(t *testing.T) {
+	ctx := context.Background()
+	es := store.NewInMemoryEventStore()
+	service := app.NewAccountService(es, store.NewInMemorySnapshotStore())
+
+	for _, cmd := range []app.CreateCustomerCommand{
+		{CustomerID: "cust-1", Details: app.CustomerDetails{Name: "Ada Lovelace", Email: "ada@example.com", Identifiers: []string{"P1234"}}, KYCTier: "Standard"},
+		{CustomerID: "cust-2", Details: app.CustomerDetails{Name: "Charles Babbage", Phone: "+44 20 7946 0000"}},
+	} {
+		if _, err := service.CreateCustomer(ctx, cmd); err != nil {
+			t.Fatalf("CreateCustomer %s failed: %v", cmd.CustomerID, err)
+		}
+	}
+	open := func(id string, currency shared.Currency, amount string, holders ...string) {
+		t.Helper()
+		_, err := service.CreateAccount(app.CreateAccountCommand{AccountID: id, InitialBalances: map[shared.Currency]decimal.Decimal{currency: dec(amount)}, Holders: holders})
+		if err != nil {
+			t.Fatalf("CreateAccount %s failed: %v", id, err)
+		}
+	}
+	open("cu-1", shared.USD, "100", "cust-1")
+	open("cu-joint", shared.EUR, "50", "cust-1", "cust-2")
+	open("cu-2", shared.GBP, "10", "cust-2")
+	open("cu-none", shared.USD, "999")
+
+	t.Run("Register", func(t *testing.T) {
+		profile, err := service.GetCustomer(ctx, "cust-1")
+		if err != nil {
+			t.Fatalf("GetCustomer failed: %v", err)
+		}
+		if profile.Name != "Ada Lovelace" || profile.Email != "ada@example.com" || profile.KYCTier != domain.KYCTierStandard || profile.Redacted {
+			t.Errorf("unexpected profile %+v", profile)
+		}
+		if _, err := service.CreateCustomer(ctx, app.CreateCustomerCommand{CustomerID: "cust-1", Details: app.CustomerDetails{Name: "Other"}}); !errors.Is(err, domain.ErrCustomerExists) {
+			t.Errorf("expected ErrCustomerExists, got %v", err)
+		}
+		if _, err := service.CreateCustomer(ctx, app.CreateCustomerCommand{Details: app.CustomerDetails{Name: "X"}, KYCTier: "gold"}); err == nil {
+			t.Error("expected an unknown KYC tier to be refused")
+		}
+		if _, err := service.CreateCustomer(ctx, app.CreateCustomerCommand{CustomerID: "cust-3"}); err == nil {
+			t.Error("expected a customer without a name to be refused")
+		}
+		all, _ := service.ListCustomers(ctx)
+		if len(all) != 2 || all[1].Name != "Charles Babbage" {
+			t.Errorf("expected both customers, oldest first, got %+v", all)
+		}
+	})
+
+	t.Run("EventSourced", func(t *testing.T) {
+		stream, err := es.GetEventsAfterVersionContext(ctx, domain.CustomerStreamID("cust-1"), 0)
+		if err != nil || len(stream) != 1 {
+			t.Fatalf("expected the registration in the customer's stream, got %d events, %v", len(stream), err)
+		}
+		registered, ok := stream[0].(events.CustomerRegisteredEvent)
+		if !ok || registered.Hash == "" || registered.Metadata.CorrelationID == "" {
+			t.Fatalf("expected a hash-chained CustomerRegistered event, got %+v", stream[0])
+		}
+		if registered.Details.SubjectID != "cust-1" || strings.Contains(string(registered.Details.Data), "Ada") {
+			t.Errorf("expected details sealed under the customer, got %+v", registered.Details)
+		}
+		// The account projections skip customer streams.
+		page, err := service.ListAccounts(ctx, app.ListAccountsQuery{})
+		if err != nil || page.Total != 4 {
+			t.Errorf("expected only the 4 accounts in the directory, got %+v, %v", page, err)
+		}
+	})
+
+	t.Run("UnknownHolder", func(t *testing.T) {
+		_, err := service.CreateAccount(app.CreateAccountCommand{AccountID: "cu-x", Holders: []string{"cust-1", "cust-9"}})
+		if !errors.Is(err, domain.ErrCustomerNotFound) {
+			t.Errorf("expected ErrCustomerNotFound, got %v", err)
+		}
+		if _, err := service.GetCurrentBalance(app.GetBalanceQuery{AccountID: "cu-x"}); !errors.Is(err, domain.ErrAccountNotFound) {
+			t.Errorf("expected no account, got %v", err)
+		}
+	})
+
+	t.Run("ConsolidatedBalances", func(t *testing.T) {
+		got, err := service.GetCustomerAccounts(ctx, app.GetCustomerAccountsQuery{CustomerID: "cust-1", ReportingCurrency: shared.USD})
+		if err != nil {
+			t.Fatalf("GetCustomerAccounts failed: %v", err)
+		}
+		if len(got.Accounts) != 2 || got.Accounts[0].AccountID != "cu-1" || got.Accounts[1].AccountID != "cu-joint" {
+			t.Fatalf("expected cu-1 and the joint account, got %+v", got.Accounts)
+		}
+		if len(got.Accounts[1].Holders) != 2 {
+			t.Errorf("expected the joint account to list both holders, got %v", got.Accounts[1].Holders)
+		}
+		if !got.Balances[shared.USD].Equal(dec("100")) || !got.Balances[shared.EUR].Equal(dec("50")) {
+			t.Errorf("unexpected balances %v", got.Balances)
+		}
+		// 100 USD + 50 EUR at 1.08
+		if !got.Total.Equal(dec("154")) || !got.Rates[shared.EUR].Equal(dec("1.08")) {
+			t.Errorf("expected a total of 154 USD, got %s (rates %v)", got.Total, got.Rates)
+		}
+
+		got, err = service.GetCustomerAccounts(ctx, app.GetCustomerAccountsQuery{CustomerID: "cust-2", ReportingCurrency: shared.EUR})
+		if err != nil {
+			t.Fatalf("GetCustomerAccounts failed: %v", err)
+		}
+		// 50 EUR + 10 GBP at 1.15
+		if len(got.Accounts) != 2 || !got.Total.Equal(dec("61.5")) {
+			t.Errorf("expected a total of 61.5 EUR over 2 accounts, got %s over %+v", got.Total, got.Accounts)
+		}
+
+		if _, err := service.GetCustomerAccounts(ctx, app.GetCustomerAccountsQuery{CustomerID: "cust-9", ReportingCurrency: shared.USD}); !errors.Is(err, domain.ErrCustomerNotFound) {
+			t.Errorf("expected ErrCustomerNotFound, got %v", err)
+		}
+		if _, err := service.GetCustomerAccounts(ctx, app.GetCustomerAccountsQuery{CustomerID: "cust-1"}); err == nil {
+			t.Error("expected a reporting currency to be required")
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		err := service.UpdateCustomer(ctx, app.UpdateCustomerCommand{CustomerID: "cust-2", KYCTier: domain.KYCTierEnhanced})
+		if err != nil {
+			t.Fatalf("UpdateCustomer failed: %v", err)
+		}
+		err = service.UpdateCustomer(ctx, app.UpdateCustomerCommand{CustomerID: "cust-2", Details: &app.CustomerDetails{Name: "Charles Babbage", Email: "cb@example.com"}})
+		if err != nil {
+			t.Fatalf("UpdateCustomer failed: %v", err)
+		}
+		profile, _ := service.GetCustomer(ctx, "cust-2")
+		if profile.KYCTier != domain.KYCTierEnhanced || profile.Email != "cb@example.com" || profile.Phone != "" {
+			t.Errorf("unexpected profile %+v", profile)
+		}
+		if err := service.UpdateCustomer(ctx, app.UpdateCustomerCommand{CustomerID: "cust-9", KYCTier: domain.KYCTierNone}); !errors.Is(err, domain.ErrCustomerNotFound) {
+			t.Errorf("expected ErrCustomerNotFound, got %v", err)
+		}
+		if err := service.UpdateCustomer(ctx, app.UpdateCustomerCommand{CustomerID: "cust-2"}); err == nil {
+			t.Error("expected an empty update to be refused")
+		}
+	})
+
+	t.Run("Forget", func(t *testing.T) {
+		if _, err := service.ForgetSubject(ctx, app.ForgetSubjectCommand{SubjectID: "cust-1"}); err != nil {
+			t.Fatalf("ForgetSubject failed: %v", err)
+		}
+		profile, err := service.GetCustomer(ctx, "cust-1")
+		if err != nil || !profile.Redacted || profile.Name != app.RedactedValue || profile.KYCTier != domain.KYCTierStandard {
+			t.Errorf("expected a redacted profile, got %+v, %v", profile, err)
+		}
+		got, err := service.GetCustomerAccounts(ctx, app.GetCustomerAccountsQuery{CustomerID: "cust-1", ReportingCurrency: shared.USD})
+		if err != nil || len(got.Accounts) != 2 {
+			t.Errorf("expected a forgotten customer to keep its accounts, got %+v, %v", got, err)
+		}
+	})
+
+	t.Run("IdempotentRegistration", func(t *testing.T) {
+		cmd := app.CreateCustomerCommand{Details: app.CustomerDetails{Name: "Grace Hopper"}, IdempotencyKey: "cu-retry"}
+		first, err := service.CreateCustomer(ctx, cmd)
+		if err != nil {
+			t.Fatalf("CreateCustomer failed: %v", err)
+		}
+		second, err := service.CreateCustomer(ctx, cmd)
+		if err != nil || second != first {
+			t.Errorf("expected the retry to return customer %s, got %q, %v", first, second, err)
+		}
+		all, _ := service.ListCustomers(ctx)
+		if len(all) != 3 {
+			t.Errorf("expected the retry to register nobody else, got %+v", all)
+		}
+		cmd.KYCTier = domain.KYCTierEnhanced
+		if _, err := service.CreateCustomer(ctx, cmd); !errors.Is(err, app.ErrIdempotencyConflict) {
+			t.Errorf("expected ErrIdempotencyConflict for another payload, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,250 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/domain"
+	"financial-ledger/events"
+	"financial-ledger/shared"
+)
+
+// DefaultDormancyPeriod is how long an account can go without events before the
+// directory reports it as dormant.
+const DefaultDormancyPeriod = 365 * 24 * time.Hour
+
+// directoryProjectionName is the name the account directory registers under
+// with the projection runner.
+const directoryProjectionName = "account-directory"
+
+// AccountStatus is derived from an account's activity, except that a closed
+// account is always AccountStatusClosed.
+type AccountStatus string
+
+const (
+	AccountStatusActive  AccountStatus = "active"
+	AccountStatusDormant AccountStatus = "dormant"
+	AccountStatusClosed  AccountStatus = "closed"
+)
+
+// AccountSortField names the order in which ListAccounts returns accounts.
+type AccountSortField string
+
+const (
+	SortByID           AccountSortField = "id"
+	SortByCreated      AccountSortField = "created"
+	SortByLastActivity AccountSortField = "activity"
+	SortByBalance      AccountSortField = "balance"
+)
+
+// AccountSummary is the directory's view of one account.
+type AccountSummary struct {
+	AccountID    string
+	SubjectID    string
+	Holders      []string
+	Balances     map[shared.Currency]decimal.Decimal
+	Version      int
+	Status       AccountStatus
+	CreatedAt    time.Time
+	LastActivity time.Time
+}
+
+// AccountPage is one page of a directory listing. Total counts every account
+// that matched, across all pages.
+type AccountPage struct {
+	Accounts []AccountSummary
+	Total    int
+}
+
+// WithDormancyPeriod overrides DefaultDormancyPeriod.
+func WithDormancyPeriod(d time.Duration) ServiceOption {
+	return func(s *AccountService) {
+		if d > 0 {
+			s.dormancyPeriod = d
+		}
+	}
+}
+
+// accountDirectory is a projection of every account. It folds events into
+// per-account aggregates, so balances follow exactly the same rules as the
+// write side.
+type accountDirectory struct {
+	sync.RWMutex
+	accounts map[string]*directoryEntry
+}
+
+type directoryEntry struct {
+	account      *domain.Account
+	createdAt    time.Time
+	lastActivity time.Time
+}
+
+func newAccountDirectory() *accountDirectory {
+	return &accountDirectory{accounts: make(map[string]*directoryEntry)}
+}
+
+func (d *accountDirectory) Name() string {
+	return directoryProjectionName
+}
+
+func (d *accountDirectory) Reset(ctx context.Context) error {
+	d.Lock()
+	defer d.Unlock()
+	d.accounts = make(map[string]*directoryEntry)
+	return nil
+}
+
+func (d *accountDirectory) Apply(ctx context.Context, event events.Event) error {
+	base := event.GetBase()
+	if !domain.IsAccountStream(base.AggregateID) {
+		return nil
+	}
+	d.Lock()
+	defer d.Unlock()
+
+	entry, ok := d.accounts[base.AggregateID]
+	if !ok {
+		if _, created := event.(events.AccountCreatedEvent); !created {
+			return fmt.Errorf("directory: %s event at position %d for unknown account %s", base.Type, base.Position, base.AggregateID)
+		}
+		entry = &directoryEntry{account: domain.NewAccount(base.AggregateID), createdAt: base.Timestamp}
+		d.accounts[base.AggregateID] = entry
+	}
+	if base.Version <= entry.account.Version {
+		return nil
+	}
+	if err := entry.account.ApplyEvent(event); err != nil {
+		return fmt.Errorf("directory: %w", err)
+	}
+	entry.lastActivity = base.Timestamp
+	return nil
+}
+
+// ListAccounts searches the account directory.
+func (s *AccountService) ListAccounts(ctx context.Context, query ListAccountsQuery) (*AccountPage, error) {
+	if query.Currency == nil && (query.MinBalance != nil || query.MaxBalance != nil || query.SortBy == SortByBalance) {
+		return nil, errors.New("cannot list accounts: balance filters and sorting need a currency")
+	}
+	switch query.SortBy {
+	case "", SortByID, SortByCreated, SortByLastActivity, SortByBalance:
+	default:
+		return nil, fmt.Errorf("cannot list accounts: unknown sort field %q", query.SortBy)
+	}
+	switch query.Status {
+	case "", AccountStatusActive, AccountStatusDormant, AccountStatusClosed:
+	default:
+		return nil, fmt.Errorf("cannot list accounts: unknown status %q", query.Status)
+	}
+
+	if s.projections == nil {
+		return nil, fmt.Errorf("cannot list accounts: %w", ErrGlobalLogUnsupported)
+	}
+	if err := s.projections.CatchUp(ctx, directoryProjectionName); err != nil {
+		return nil, fmt.Errorf("failed to update account directory: %w", err)
+	}
+
+	s.directory.RLock()
+	defer s.directory.RUnlock()
+
+	now := time.Now().UTC()
+	var matched []AccountSummary
+	for _, entry := range s.directory.accounts {
+		summary := entry.summary(now, s.dormancyPeriod)
+		if query.matches(summary) {
+			matched = append(matched, summary)
+		}
+	}
+	sortAccounts(matched, query)
+
+	page := &AccountPage{Total: len(matched)}
+	start := min(max(query.Offset, 0), len(matched))
+	end := len(matched)
+	if query.Limit > 0 {
+		end = min(start+query.Limit, end)
+	}
+	page.Accounts = matched[start:end]
+	return page, nil
+}
+
+func (e *directoryEntry) summary(now time.Time, dormancy time.Duration) AccountSummary {
+	status := AccountStatusActive
+	switch {
+	case e.account.Closed:
+		status = AccountStatusClosed
+	case now.Sub(e.lastActivity) >= dormancy:
+		status = AccountStatusDormant
+	}
+	return AccountSummary{
+		AccountID:    e.account.ID,
+		SubjectID:    e.account.SubjectID,
+		Holders:      slices.Clone(e.account.Holders),
+		Balances:     copyBalances(e.account.Balances),
+		Version:      e.account.Version,
+		Status:       status,
+		CreatedAt:    e.createdAt,
+		LastActivity: e.lastActivity,
+	}
+}
+
+func (q ListAccountsQuery) matches(a AccountSummary) bool {
+	if q.Prefix != "" && !strings.HasPrefix(a.AccountID, q.Prefix) {
+		return false
+	}
+	if len(q.IDs) > 0 && !slices.Contains(q.IDs, a.AccountID) {
+		return false
+	}
+	if q.HolderID != "" && !slices.Contains(a.Holders, q.HolderID) {
+		return false
+	}
+	if q.Status != "" && a.Status != q.Status {
+		return false
+	}
+	if q.Currency == nil {
+		return true
+	}
+	balance, held := a.Balances[*q.Currency]
+	if !held || balance.IsZero() {
+		return false
+	}
+	if q.MinBalance != nil && balance.LessThan(*q.MinBalance) {
+		return false
+	}
+	if q.MaxBalance != nil && balance.GreaterThan(*q.MaxBalance) {
+		return false
+	}
+	return true
+}
+
+// sortAccounts orders accounts by the query's sort field, breaking ties by ID
+// so that paging is deterministic.
+func sortAccounts(accounts []AccountSummary, q ListAccountsQuery) {
+	compare := func(a, b AccountSummary) int {
+		switch q.SortBy {
+		case SortByCreated:
+			return a.CreatedAt.Compare(b.CreatedAt)
+		case SortByLastActivity:
+			return a.LastActivity.Compare(b.LastActivity)
+		case SortByBalance:
+			return a.Balances[*q.Currency].Cmp(b.Balances[*q.Currency])
+		}
+		return 0
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		c := compare(accounts[i], accounts[j])
+		if c == 0 {
+			c = strings.Compare(accounts[i].AccountID, accounts[j].AccountID)
+		}
+		if q.Descending {
+			return c > 0
+		}
+		return c < 0
+	})
+}
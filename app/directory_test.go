@@ -0,0 +1,133 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/app"
+	"financial-ledger/shared"
+	"financial-ledger/store"
+)
+
+func TestAccountService_ListAccounts(t *testing.T) {
+	service, _, _ := setup()
+	ctx := context.Background()
+
+	create := func(id string, balances map[shared.Currency]decimal.Decimal) {
+		t.Helper()
+		if _, err := service.CreateAccount(app.CreateAccountCommand{AccountID: id, InitialBalances: balances}); err != nil {
+			t.Fatalf("CreateAccount(%s) failed: %v", id, err)
+		}
+	}
+	create("cust-a", map[shared.Currency]decimal.Decimal{shared.USD: dec("100")})
+	create("cust-b", map[shared.Currency]decimal.Decimal{shared.USD: dec("50"), shared.EUR: dec("5")})
+	create("corp-c", map[shared.Currency]decimal.Decimal{shared.EUR: dec("1"), shared.USD: dec("0")})
+
+	ids := func(page *app.AccountPage) []string {
+		out := make([]string, 0, len(page.Accounts))
+		for _, a := range page.Accounts {
+			out = append(out, a.AccountID)
+		}
+		return out
+	}
+	list := func(t *testing.T, q app.ListAccountsQuery, want ...string) *app.AccountPage {
+		t.Helper()
+		page, err := service.ListAccounts(ctx, q)
+		if err != nil {
+			t.Fatalf("ListAccounts failed: %v", err)
+		}
+		got := ids(page)
+		if len(got) != len(want) {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("expected %v, got %v", want, got)
+			}
+		}
+		return page
+	}
+	usd, eur := shared.USD, shared.EUR
+	threshold := dec("60")
+
+	t.Run("AllSortedByID", func(t *testing.T) {
+		page := list(t, app.ListAccountsQuery{}, "corp-c", "cust-a", "cust-b")
+		if page.Total != 3 {
+			t.Errorf("expected total 3, got %d", page.Total)
+		}
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		list(t, app.ListAccountsQuery{Prefix: "cust-"}, "cust-a", "cust-b")
+		list(t, app.ListAccountsQuery{IDs: []string{"corp-c", "missing"}}, "corp-c")
+	})
+
+	t.Run("CurrencyHeld", func(t *testing.T) {
+		list(t, app.ListAccountsQuery{Currency: &usd}, "cust-a", "cust-b")
+		list(t, app.ListAccountsQuery{Currency: &eur}, "corp-c", "cust-b")
+		list(t, app.ListAccountsQuery{Currency: &usd, MinBalance: &threshold}, "cust-a")
+		list(t, app.ListAccountsQuery{Currency: &usd, MaxBalance: &threshold}, "cust-b")
+	})
+
+	t.Run("SortAndPage", func(t *testing.T) {
+		list(t, app.ListAccountsQuery{Currency: &eur, SortBy: app.SortByBalance, Descending: true}, "cust-b", "corp-c")
+		list(t, app.ListAccountsQuery{SortBy: app.SortByCreated}, "cust-a", "cust-b", "corp-c")
+		page := list(t, app.ListAccountsQuery{Offset: 1, Limit: 1}, "cust-a")
+		if page.Total != 3 {
+			t.Errorf("expected total 3 across pages, got %d", page.Total)
+		}
+	})
+
+	t.Run("FollowsLaterEvents", func(t *testing.T) {
+		if err := service.Withdraw(app.WithdrawMoneyCommand{AccountID: "cust-a", Amount: dec("100"), Currency: shared.USD}); err != nil {
+			t.Fatalf("Withdraw failed: %v", err)
+		}
+		if err := service.TransferMoney(app.TransferMoneyCommand{SourceAccountID: "cust-b", TargetAccountID: "corp-c", Amount: dec("50"), Currency: shared.USD}); err != nil {
+			t.Fatalf("TransferMoney failed: %v", err)
+		}
+		list(t, app.ListAccountsQuery{Currency: &usd}, "corp-c")
+		list(t, app.ListAccountsQuery{SortBy: app.SortByLastActivity, Descending: true}, "corp-c", "cust-b", "cust-a")
+	})
+
+	t.Run("InvalidQueries", func(t *testing.T) {
+		for name, q := range map[string]app.ListAccountsQuery{
+			"BalanceWithoutCurrency":  {MinBalance: &threshold},
+			"SortByBalanceNoCurrency": {SortBy: app.SortByBalance},
+			"UnknownSort":             {SortBy: "colour"},
+			"UnknownStatus":           {Status: "frozen"},
+		} {
+			if _, err := service.ListAccounts(ctx, q); err == nil {
+				t.Errorf("%s: expected an error", name)
+			}
+		}
+	})
+}
+
+func TestAccountService_ListAccountsDormancy(t *testing.T) {
+	service := app.NewAccountService(store.NewInMemoryEventStore(), store.NewInMemorySnapshotStore(),
+		app.WithDormancyPeriod(20*time.Millisecond))
+	ctx := context.Background()
+
+	for _, id := range []string{"quiet", "busy"} {
+		if _, err := service.CreateAccount(app.CreateAccountCommand{AccountID: id, InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: dec("1")}}); err != nil {
+			t.Fatalf("CreateAccount(%s) failed: %v", id, err)
+		}
+	}
+	time.Sleep(30 * time.Millisecond)
+	if err := service.Deposit(app.DepositMoneyCommand{AccountID: "busy", Amount: dec("1"), Currency: shared.USD}); err != nil {
+		t.Fatalf("Deposit failed: %v", err)
+	}
+
+	for status, want := range map[app.AccountStatus]string{app.AccountStatusDormant: "quiet", app.AccountStatusActive: "busy"} {
+		page, err := service.ListAccounts(ctx, app.ListAccountsQuery{Status: status})
+		if err != nil {
+			t.Fatalf("ListAccounts(%s) failed: %v", status, err)
+		}
+		if len(page.Accounts) != 1 || page.Accounts[0].AccountID != want {
+			t.Errorf("status %s: expected [%s], got %+v", status, want, page.Accounts)
+		}
+	}
+}
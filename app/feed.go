@@ -0,0 +1,176 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/domain"
+	"financial-ledger/events"
+	"financial-ledger/shared"
+	"financial-ledger/store"
+)
+
+// feedBatchSize is how many global log events FeedEvents reads per poll.
+const feedBatchSize = 500
+
+// FeedQuery selects the accounts a feed follows and where it starts. With
+// FromHead the feed starts at the current head of the global log; otherwise it
+// resumes after AfterEventID if that is set, or after AfterPosition.
+type FeedQuery struct {
+	AccountIDs    []string
+	FromHead      bool
+	AfterPosition int64
+	AfterEventID  uuid.UUID
+}
+
+// FeedItem is one committed event of a followed account, with that account's
+// balances right after it.
+type FeedItem struct {
+	Event         events.Event
+	BalancesAfter map[shared.Currency]decimal.Decimal
+}
+
+// Feed follows the global log for a set of accounts. It is not safe for
+// concurrent use.
+type Feed struct {
+	gl           store.GlobalLog
+	pollInterval time.Duration
+	accountIDs   []string
+	position     int64
+	accounts     map[string]*domain.Account
+}
+
+// OpenFeed resolves the query's starting point and rebuilds every followed
+// account as of that point. It fails with ErrAccountNotFound for an unknown
+// account and ErrEventNotFound if AfterEventID is not an event of one of them.
+func (s *AccountService) OpenFeed(ctx context.Context, query FeedQuery) (*Feed, error) {
+	if len(query.AccountIDs) == 0 {
+		return nil, errors.New("feed must follow at least one account")
+	}
+	gl, err := s.globalLog()
+	if err != nil {
+		return nil, err
+	}
+
+	position := query.AfterPosition
+	switch {
+	case query.FromHead:
+		if position, err = gl.Head(ctx); err != nil {
+			return nil, fmt.Errorf("failed to read head of the event log: %w", err)
+		}
+	case query.AfterEventID != uuid.Nil:
+		if position, err = s.positionOfEvent(ctx, query.AccountIDs, query.AfterEventID); err != nil {
+			return nil, err
+		}
+	}
+	accounts, err := s.accountsAtPosition(ctx, query.AccountIDs, position)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, id := range query.AccountIDs {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return &Feed{gl: gl, pollInterval: s.tailPollInterval, accountIDs: ids, position: position, accounts: accounts}, nil
+}
+
+// Position is the global log position of the last event the feed has read.
+func (f *Feed) Position() int64 {
+	return f.position
+}
+
+// Balances returns every followed account's balances as of Position.
+func (f *Feed) Balances() []AccountBalances {
+	out := make([]AccountBalances, 0, len(f.accountIDs))
+	for _, id := range f.accountIDs {
+		account := f.accounts[id]
+		out = append(out, AccountBalances{AccountID: id, Version: account.Version, Balances: copyBalances(account.Balances)})
+	}
+	return out
+}
+
+// Run calls fn with every event of the followed accounts committed after
+// Position, in global log order, and keeps following new commits until ctx is
+// done or fn returns an error.
+func (f *Feed) Run(ctx context.Context, fn func(FeedItem) error) error {
+	ticker := time.NewTicker(f.pollInterval)
+	defer ticker.Stop()
+	for {
+		batch, err := f.gl.ReadAll(ctx, f.position, feedBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to read event log after position %d: %w", f.position, err)
+		}
+		for _, event := range batch {
+			base := event.GetBase()
+			f.position = base.Position
+			account, followed := f.accounts[base.AggregateID]
+			if !followed {
+				continue
+			}
+			if err := account.ApplyEvent(event); err != nil {
+				return fmt.Errorf("failed to apply event %s to account %s: %w", base.EventID, base.AggregateID, err)
+			}
+			if err := fn(FeedItem{Event: event, BalancesAfter: copyBalances(account.Balances)}); err != nil {
+				return err
+			}
+		}
+		if len(batch) == feedBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// accountsAtPosition rebuilds each account from its events up to position.
+func (s *AccountService) accountsAtPosition(ctx context.Context, accountIDs []string, position int64) (map[string]*domain.Account, error) {
+	accounts := make(map[string]*domain.Account, len(accountIDs))
+	for _, id := range accountIDs {
+		history, err := s.eventStore.GetEventsContext(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load events of account %s: %w", id, err)
+		}
+		if len(history) == 0 {
+			return nil, fmt.Errorf("%w: %s", domain.ErrAccountNotFound, id)
+		}
+		account := domain.NewAccount(id)
+		upTo := slices.IndexFunc(history, func(e events.Event) bool { return e.GetBase().Position > position })
+		if upTo < 0 {
+			upTo = len(history)
+		}
+		if err := replayEvents(ctx, account, history[:upTo]); err != nil {
+			return nil, err
+		}
+		accounts[id] = account
+	}
+	return accounts, nil
+}
+
+// positionOfEvent finds the global log position of an event belonging to one
+// of accountIDs.
+func (s *AccountService) positionOfEvent(ctx context.Context, accountIDs []string, eventID uuid.UUID) (int64, error) {
+	for _, id := range accountIDs {
+		history, err := s.eventStore.GetEventsContext(ctx, id)
+		if err != nil {
+			return 0, fmt.Errorf("failed to load events of account %s: %w", id, err)
+		}
+		for _, event := range history {
+			if event.GetBase().EventID == eventID {
+				return event.GetBase().Position, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("%w: %s is not an event of the followed accounts", ErrEventNotFound, eventID)
+}
@@ -0,0 +1,122 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/app"
+	"financial-ledger/domain"
+	"financial-ledger/shared"
+	"financial-ledger/store"
+)
+
+func TestAccountService_Feed(t *testing.T) {
+	ctx := context.Background()
+	service := app.NewAccountService(store.NewInMemoryEventStore(), store.NewInMemorySnapshotStore(), app.WithTailPollInterval(time.Millisecond))
+	_, _ = service.CreateAccount(app.CreateAccountCommand{AccountID: "feed-a", InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: dec("100")}})
+	_, _ = service.CreateAccount(app.CreateAccountCommand{AccountID: "feed-b"})
+	_, _ = service.CreateAccount(app.CreateAccountCommand{AccountID: "feed-other"})
+	_ = service.Deposit(app.DepositMoneyCommand{AccountID: "feed-other", Amount: dec("1"), Currency: shared.USD})
+
+	// collect runs a feed until n items arrive.
+	collect := func(t *testing.T, feed *app.Feed, n int, during func()) []app.FeedItem {
+		t.Helper()
+		runCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		var items []app.FeedItem
+		enough := errors.New("enough")
+		if during != nil {
+			go during()
+		}
+		err := feed.Run(runCtx, func(item app.FeedItem) error {
+			items = append(items, item)
+			if len(items) == n {
+				return enough
+			}
+			return nil
+		})
+		if !errors.Is(err, enough) {
+			t.Fatalf("feed stopped after %d of %d items: %v", len(items), n, err)
+		}
+		return items
+	}
+
+	t.Run("FromHeadFollowsNewCommits", func(t *testing.T) {
+		feed, err := service.OpenFeed(ctx, app.FeedQuery{AccountIDs: []string{"feed-a", "feed-b", "feed-a"}, FromHead: true})
+		if err != nil {
+			t.Fatalf("OpenFeed failed: %v", err)
+		}
+		start := feed.Balances()
+		if len(start) != 2 || start[0].AccountID != "feed-a" || !start[0].Balances[shared.USD].Equal(dec("100")) {
+			t.Fatalf("unexpected starting balances: %+v", start)
+		}
+
+		items := collect(t, feed, 3, func() {
+			_ = service.Deposit(app.DepositMoneyCommand{AccountID: "feed-other", Amount: dec("1"), Currency: shared.USD})
+			_ = service.TransferMoney(app.TransferMoneyCommand{SourceAccountID: "feed-a", TargetAccountID: "feed-b", Amount: dec("30"), Currency: shared.USD})
+			_ = service.Withdraw(app.WithdrawMoneyCommand{AccountID: "feed-b", Amount: dec("5"), Currency: shared.USD})
+		})
+		want := []struct {
+			account string
+			balance string
+		}{{"feed-a", "70"}, {"feed-b", "30"}, {"feed-b", "25"}}
+		for i, w := range want {
+			base := items[i].Event.GetBase()
+			if base.AggregateID != w.account || !items[i].BalancesAfter[shared.USD].Equal(dec(w.balance)) {
+				t.Errorf("item %d: expected %s at %s USD, got %s at %v", i, w.account, w.balance, base.AggregateID, items[i].BalancesAfter)
+			}
+			if i > 0 && base.Position <= items[i-1].Event.GetBase().Position {
+				t.Errorf("items are not in global log order")
+			}
+		}
+		if feed.Position() != items[2].Event.GetBase().Position {
+			t.Errorf("expected the feed position to follow the last item, got %d", feed.Position())
+		}
+	})
+
+	t.Run("ResumeFromPosition", func(t *testing.T) {
+		first, _ := service.OpenFeed(ctx, app.FeedQuery{AccountIDs: []string{"feed-b"}})
+		all := collect(t, first, 3, nil)
+
+		resumed, err := service.OpenFeed(ctx, app.FeedQuery{AccountIDs: []string{"feed-b"}, AfterPosition: all[0].Event.GetBase().Position})
+		if err != nil {
+			t.Fatalf("OpenFeed failed: %v", err)
+		}
+		if got := resumed.Balances()[0]; got.Version != 1 {
+			t.Errorf("expected the account as of version 1, got %+v", got)
+		}
+		items := collect(t, resumed, 2, nil)
+		if items[0].Event.GetBase().EventID != all[1].Event.GetBase().EventID || !items[1].BalancesAfter[shared.USD].Equal(dec("25")) {
+			t.Errorf("resumed feed did not continue where the first left off")
+		}
+	})
+
+	t.Run("ResumeFromEventID", func(t *testing.T) {
+		history, _ := service.GetTransactionHistory(app.GetHistoryQuery{AccountID: "feed-a"})
+		feed, err := service.OpenFeed(ctx, app.FeedQuery{AccountIDs: []string{"feed-a"}, AfterEventID: history[0].GetBase().EventID})
+		if err != nil {
+			t.Fatalf("OpenFeed failed: %v", err)
+		}
+		items := collect(t, feed, 1, nil)
+		if items[0].Event.GetBase().Version != 2 {
+			t.Errorf("expected the event after the first, got version %d", items[0].Event.GetBase().Version)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		if _, err := service.OpenFeed(ctx, app.FeedQuery{AccountIDs: []string{"missing"}}); !errors.Is(err, domain.ErrAccountNotFound) {
+			t.Errorf("expected ErrAccountNotFound, got %v", err)
+		}
+		if _, err := service.OpenFeed(ctx, app.FeedQuery{AccountIDs: []string{"feed-a"}, AfterEventID: uuid.New()}); !errors.Is(err, app.ErrEventNotFound) {
+			t.Errorf("expected ErrEventNotFound, got %v", err)
+		}
+		if _, err := service.OpenFeed(ctx, app.FeedQuery{}); err == nil {
+			t.Error("expected a feed without accounts to be rejected")
+		}
+	})
+}
@@ -0,0 +1,209 @@
+package app
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/domain"
+	"financial-ledger/events"
+	"financial-ledger/shared"
+)
+
+var ErrInvalidCursor = errors.New("invalid history cursor")
+
+// HistoryItem is one event from an account's history together with the
+// account's balances right after it was applied.
+type HistoryItem struct {
+	Event         events.Event
+	BalancesAfter map[shared.Currency]decimal.Decimal
+}
+
+// HistoryPage is one page of search results. NextCursor is empty on the last page.
+type HistoryPage struct {
+	Items      []HistoryItem
+	NextCursor string
+}
+
+// historyLeg is an amount of money an event moved into or out of the account.
+type historyLeg struct {
+	Currency shared.Currency
+	Amount   decimal.Decimal
+}
+
+// SearchHistory returns the events of an account matching the query's filters.
+// Cursors are anchored on event versions rather than offsets, so pages stay
+// stable while new events are appended.
+func (s *AccountService) SearchHistory(ctx context.Context, query SearchHistoryQuery) (*HistoryPage, error) {
+	after, err := decodeHistoryCursor(query.Cursor, query.AccountID, query.Descending)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.eventStore.GetEventsContext(ctx, query.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event history for account %s: %w", query.AccountID, err)
+	}
+	if len(history) == 0 {
+		return nil, fmt.Errorf("%w: cannot search history: account %s not found", domain.ErrAccountNotFound, query.AccountID)
+	}
+
+	// Running balances need the whole stream in order, whatever is returned.
+	account := domain.NewAccount(query.AccountID)
+	var matched []HistoryItem
+	for _, event := range history {
+		if err := account.ApplyEvent(event); err != nil {
+			return nil, fmt.Errorf("failed to replay history of account %s: %w", query.AccountID, err)
+		}
+		if !query.matches(query.AccountID, event) {
+			continue
+		}
+		matched = append(matched, HistoryItem{Event: event, BalancesAfter: copyBalances(account.Balances)})
+	}
+
+	if query.Descending {
+		slices.Reverse(matched)
+	}
+	start := 0
+	if after > 0 {
+		for start < len(matched) && !pastCursor(matched[start].Event.GetBase().Version, after, query.Descending) {
+			start++
+		}
+	}
+	matched = matched[start:]
+
+	page := &HistoryPage{Items: matched}
+	if query.Limit > 0 && len(matched) > query.Limit {
+		page.Items = matched[:query.Limit]
+		last := page.Items[len(page.Items)-1].Event.GetBase().Version
+		page.NextCursor = encodeHistoryCursor(query.AccountID, last, query.Descending)
+	}
+	return page, nil
+}
+
+func (q SearchHistoryQuery) matches(accountID string, event events.Event) bool {
+	base := event.GetBase()
+	if len(q.Types) > 0 && !slices.Contains(q.Types, base.Type) {
+		return false
+	}
+	if q.From != nil && base.Timestamp.Before(*q.From) {
+		return false
+	}
+	if q.To != nil && !base.Timestamp.Before(*q.To) {
+		return false
+	}
+	if q.Counterparty != "" && counterpartyOf(accountID, event) != q.Counterparty {
+		return false
+	}
+	if q.Currency == nil && q.MinAmount == nil && q.MaxAmount == nil {
+		return true
+	}
+	for _, leg := range legsOf(accountID, event) {
+		if q.Currency != nil && leg.Currency != *q.Currency {
+			continue
+		}
+		if q.MinAmount != nil && leg.Amount.LessThan(*q.MinAmount) {
+			continue
+		}
+		if q.MaxAmount != nil && leg.Amount.GreaterThan(*q.MaxAmount) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// legsOf lists the money movements event made on accountID's side.
+func legsOf(accountID string, event events.Event) []historyLeg {
+	switch e := event.(type) {
+	case events.AccountCreatedEvent:
+		legs := make([]historyLeg, 0, len(e.InitialBalances))
+		for _, b := range e.InitialBalances {
+			legs = append(legs, historyLeg{Currency: b.Currency, Amount: b.Amount})
+		}
+		return legs
+	case events.DepositMadeEvent:
+		return []historyLeg{{Currency: e.Currency, Amount: e.Amount}}
+	case events.WithdrawalMadeEvent:
+		return []historyLeg{{Currency: e.Currency, Amount: e.Amount}}
+	case events.CurrencyConvertedEvent:
+		return []historyLeg{{Currency: e.FromCurrency, Amount: e.FromAmount}, {Currency: e.ToCurrency, Amount: e.ToAmount}}
+	case events.MoneyTransferredEvent:
+		if accountID == e.SourceAccountID {
+			return []historyLeg{{Currency: e.DebitedCurrency, Amount: e.DebitedAmount}}
+		}
+		return []historyLeg{{Currency: e.CreditedCurrency, Amount: e.CreditedAmount}}
+	case events.TransactionReversedEvent:
+		return []historyLeg{{Currency: e.Currency, Amount: e.Amount}}
+	}
+	return nil
+}
+
+// counterpartyOf returns the other account in a transfer, or "".
+func counterpartyOf(accountID string, event events.Event) string {
+	e, ok := event.(events.MoneyTransferredEvent)
+	if !ok {
+		return ""
+	}
+	if accountID == e.SourceAccountID {
+		return e.TargetAccountID
+	}
+	return e.SourceAccountID
+}
+
+func copyBalances(balances map[shared.Currency]decimal.Decimal) map[shared.Currency]decimal.Decimal {
+	out := make(map[shared.Currency]decimal.Decimal, len(balances))
+	for cur, amount := range balances {
+		out[cur] = amount
+	}
+	return out
+}
+
+func pastCursor(version, cursor int, descending bool) bool {
+	if descending {
+		return version < cursor
+	}
+	return version > cursor
+}
+
+// History cursors are opaque to callers. They name the account, the ordering
+// and the version of the last event returned.
+func encodeHistoryCursor(accountID string, version int, descending bool) string {
+	order := "asc"
+	if descending {
+		order = "desc"
+	}
+	raw := fmt.Sprintf("h1|%s|%s|%d", order, accountID, version)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeHistoryCursor(cursor, accountID string, descending bool) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 4 || parts[0] != "h1" {
+		return 0, fmt.Errorf("%w: malformed", ErrInvalidCursor)
+	}
+	version, err := strconv.Atoi(parts[3])
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("%w: malformed", ErrInvalidCursor)
+	}
+	if parts[2] != accountID {
+		return 0, fmt.Errorf("%w: cursor belongs to account %s", ErrInvalidCursor, parts[2])
+	}
+	if (parts[1] == "desc") != descending {
+		return 0, fmt.Errorf("%w: cursor was issued for %s order", ErrInvalidCursor, parts[1])
+	}
+	return version, nil
+}
@@ -0,0 +1,155 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/app"
+	"financial-ledger/domain"
+	"financial-ledger/events"
+	"financial-ledger/shared"
+)
+
+func TestAccountService_SearchHistory(t *testing.T) {
+	service, _, _ := setup()
+	ctx := context.Background()
+	id, other := "acc-hist-1", "acc-hist-2"
+
+	mustCreate := func(accountID string) {
+		t.Helper()
+		_, err := service.CreateAccount(app.CreateAccountCommand{AccountID: accountID, InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: dec("100")}})
+		if err != nil {
+			t.Fatalf("CreateAccount(%s) failed: %v", accountID, err)
+		}
+	}
+	mustCreate(id)
+	mustCreate(other)
+
+	// v2 deposit 50 USD, v3 withdraw 20 USD, v4 transfer 30 USD out, v5 deposit 5 EUR
+	if err := service.Deposit(app.DepositMoneyCommand{AccountID: id, Amount: dec("50"), Currency: shared.USD}); err != nil {
+		t.Fatalf("Deposit failed: %v", err)
+	}
+	if err := service.Withdraw(app.WithdrawMoneyCommand{AccountID: id, Amount: dec("20"), Currency: shared.USD}); err != nil {
+		t.Fatalf("Withdraw failed: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+	midpoint := time.Now().UTC()
+	time.Sleep(time.Millisecond)
+	if err := service.TransferMoney(app.TransferMoneyCommand{SourceAccountID: id, TargetAccountID: other, Amount: dec("30"), Currency: shared.USD}); err != nil {
+		t.Fatalf("TransferMoney failed: %v", err)
+	}
+	if err := service.Deposit(app.DepositMoneyCommand{AccountID: id, Amount: dec("5"), Currency: shared.EUR}); err != nil {
+		t.Fatalf("Deposit failed: %v", err)
+	}
+
+	versions := func(page *app.HistoryPage) []int {
+		out := make([]int, 0, len(page.Items))
+		for _, item := range page.Items {
+			out = append(out, item.Event.GetBase().Version)
+		}
+		return out
+	}
+	search := func(t *testing.T, q app.SearchHistoryQuery) *app.HistoryPage {
+		t.Helper()
+		q.AccountID = id
+		page, err := service.SearchHistory(ctx, q)
+		if err != nil {
+			t.Fatalf("SearchHistory failed: %v", err)
+		}
+		return page
+	}
+	expectVersions := func(t *testing.T, page *app.HistoryPage, want ...int) {
+		t.Helper()
+		got := versions(page)
+		if len(got) != len(want) {
+			t.Fatalf("expected versions %v, got %v", want, got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("expected versions %v, got %v", want, got)
+			}
+		}
+	}
+	eur := shared.EUR
+	low, high := dec("20"), dec("40")
+
+	t.Run("AllWithRunningBalances", func(t *testing.T) {
+		page := search(t, app.SearchHistoryQuery{})
+		expectVersions(t, page, 1, 2, 3, 4, 5)
+		wantUSD := []string{"100", "150", "130", "100", "100"}
+		for i, item := range page.Items {
+			if got := item.BalancesAfter[shared.USD]; !got.Equal(dec(wantUSD[i])) {
+				t.Errorf("v%d: expected USD balance %s, got %s", i+1, wantUSD[i], got)
+			}
+		}
+		if got := page.Items[4].BalancesAfter[shared.EUR]; !got.Equal(dec("5")) {
+			t.Errorf("v5: expected EUR balance 5, got %s", got)
+		}
+		if page.NextCursor != "" {
+			t.Errorf("expected no cursor on a complete page, got %q", page.NextCursor)
+		}
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		expectVersions(t, search(t, app.SearchHistoryQuery{Types: []events.EventType{events.DepositMadeType}}), 2, 5)
+		expectVersions(t, search(t, app.SearchHistoryQuery{Currency: &eur}), 5)
+		expectVersions(t, search(t, app.SearchHistoryQuery{MinAmount: &low, MaxAmount: &high}), 3, 4)
+		expectVersions(t, search(t, app.SearchHistoryQuery{Counterparty: other}), 4)
+		expectVersions(t, search(t, app.SearchHistoryQuery{From: &midpoint}), 4, 5)
+		expectVersions(t, search(t, app.SearchHistoryQuery{To: &midpoint}), 1, 2, 3)
+	})
+
+	t.Run("CounterpartySeesOtherSide", func(t *testing.T) {
+		page, err := service.SearchHistory(ctx, app.SearchHistoryQuery{AccountID: other, Counterparty: id})
+		if err != nil {
+			t.Fatalf("SearchHistory failed: %v", err)
+		}
+		if len(page.Items) != 1 || !page.Items[0].BalancesAfter[shared.USD].Equal(dec("130")) {
+			t.Errorf("expected one transfer leaving USD 130, got %+v", page.Items)
+		}
+	})
+
+	t.Run("CursorPagingIsStable", func(t *testing.T) {
+		first := search(t, app.SearchHistoryQuery{Descending: true, Limit: 2})
+		expectVersions(t, first, 5, 4)
+		if first.NextCursor == "" {
+			t.Fatalf("expected a next cursor")
+		}
+
+		// A new event must not shift the following page.
+		if err := service.Deposit(app.DepositMoneyCommand{AccountID: id, Amount: dec("1"), Currency: shared.USD}); err != nil {
+			t.Fatalf("Deposit failed: %v", err)
+		}
+		second := search(t, app.SearchHistoryQuery{Descending: true, Limit: 2, Cursor: first.NextCursor})
+		expectVersions(t, second, 3, 2)
+		third := search(t, app.SearchHistoryQuery{Descending: true, Limit: 2, Cursor: second.NextCursor})
+		expectVersions(t, third, 1)
+		if third.NextCursor != "" {
+			t.Errorf("expected no cursor on the last page, got %q", third.NextCursor)
+		}
+	})
+
+	t.Run("InvalidCursor", func(t *testing.T) {
+		asc := search(t, app.SearchHistoryQuery{Limit: 1})
+		for name, q := range map[string]app.SearchHistoryQuery{
+			"Garbage":      {AccountID: id, Cursor: "not-a-cursor!"},
+			"WrongOrder":   {AccountID: id, Cursor: asc.NextCursor, Descending: true},
+			"WrongAccount": {AccountID: other, Cursor: asc.NextCursor},
+		} {
+			if _, err := service.SearchHistory(ctx, q); !errors.Is(err, app.ErrInvalidCursor) {
+				t.Errorf("%s: expected ErrInvalidCursor, got %v", name, err)
+			}
+		}
+	})
+
+	t.Run("AccountNotFound", func(t *testing.T) {
+		_, err := service.SearchHistory(ctx, app.SearchHistoryQuery{AccountID: "missing"})
+		if !errors.Is(err, domain.ErrAccountNotFound) {
+			t.Errorf("expected ErrAccountNotFound, got %v", err)
+		}
+	})
+}
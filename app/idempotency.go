@@ -0,0 +1,297 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"financial-ledger/store"
+)
+
+// idempotencyNamespace seeds the deterministic account IDs generated for
+// CreateAccount commands that carry an idempotency key but no AccountID.
+var idempotencyNamespace = uuid.MustParse("6f1d6b3e-3c55-4c1a-9c8e-2f0b7f3c9a41")
+
+var ErrIdempotencyConflict = errors.New("idempotency key conflict")
+
+// IdempotencyConflictError is returned when an idempotency key is reused with a
+// payload that differs from the request it was first seen with.
+type IdempotencyConflictError struct {
+	Key         string
+	AggregateID string
+}
+
+func (e *IdempotencyConflictError) Error() string {
+	return fmt.Sprintf("%s: key %q was already used for a different request on account %s", ErrIdempotencyConflict, e.Key, e.AggregateID)
+}
+
+func (e *IdempotencyConflictError) Unwrap() error {
+	return ErrIdempotencyConflict
+}
+
+// idempotentRequest tracks a command carrying an idempotency key between the
+// initial lookup and the final record. A nil *idempotentRequest means the
+// command had no key and every method is a no-op.
+type idempotentRequest struct {
+	key         string
+	fingerprint string
+	release     func()
+}
+
+// beginIdempotent serialises requests sharing the same key and checks whether
+// the key was already used. If a matching record exists it is returned and the
+// caller should replay the original result instead of executing the command.
+// When the key store has no record the stored events are searched, so keys
+// survive a restart of a durable event store; aggregateID is the stream that
+// would hold the key's events.
+func (s *AccountService) beginIdempotent(ctx context.Context, key string, cmd interface{}, aggregateID string) (*idempotentRequest, *store.IdempotencyRecord, error) {
+	if key == "" {
+		return nil, nil, nil
+	}
+
+	fingerprint, err := commandFingerprint(cmd)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fingerprint command for idempotency key %q: %w", key, err)
+	}
+
+	req := &idempotentRequest{
+		key:         key,
+		fingerprint: fingerprint,
+		release:     s.keyLocks.lock(key),
+	}
+
+	record, found, err := s.idempotencyStore.GetRecord(ctx, key)
+	if err != nil {
+		req.done()
+		return nil, nil, fmt.Errorf("failed to look up idempotency key %q: %w", key, err)
+	}
+	if !found {
+		record, found, err = s.recoverIdempotencyRecord(ctx, key, aggregateID)
+		if err != nil {
+			req.done()
+			return nil, nil, err
+		}
+	}
+	if !found {
+		return req, nil, nil
+	}
+
+	if record.Fingerprint != fingerprint {
+		req.done()
+		return nil, nil, &IdempotencyConflictError{Key: key, AggregateID: record.AggregateID}
+	}
+
+	log.Printf("Idempotency key %q already processed for account %s; returning original result.", key, record.AggregateID)
+	req.done()
+	return nil, &record, nil
+}
+
+// alreadyProcessed reports whether a command with key has already run, so
+// that checks made before a command runs, such as sanctions screening, are
+// not repeated for a retry that will only replay the original result. A key
+// reused for another command is refused as in beginIdempotent.
+func (s *AccountService) alreadyProcessed(ctx context.Context, key string, cmd interface{}, aggregateID string) (bool, error) {
+	idem, replay, err := s.beginIdempotent(ctx, key, cmd, aggregateID)
+	idem.done()
+	return replay != nil, err
+}
+
+// recoverIdempotencyRecord rebuilds a missing record from the stored events and
+// caches it back into the key store. Keys are unique across the ledger, so with
+// a global log every stream is searched: a key reused against another account
+// must still be found to be refused. The log is scanned from where the last
+// scan stopped, caching every key met on the way, so each event is read once.
+// Without a global log only aggregateID's stream can be searched.
+func (s *AccountService) recoverIdempotencyRecord(ctx context.Context, key string, aggregateID string) (store.IdempotencyRecord, bool, error) {
+	gl, ok := s.eventStore.(store.GlobalLog)
+	if !ok {
+		return s.recoverIdempotencyRecordFromStream(ctx, key, aggregateID)
+	}
+
+	s.keyScan.Lock()
+	defer s.keyScan.Unlock()
+	logEvents, err := gl.ReadAll(ctx, s.keyScan.position, 0)
+	if err != nil {
+		return store.IdempotencyRecord{}, false, fmt.Errorf("failed to scan the global log for idempotency key %q: %w", key, err)
+	}
+	var (
+		match store.IdempotencyRecord
+		found bool
+		seen  = make(map[string]bool)
+	)
+	for _, event := range logEvents {
+		base := event.GetBase()
+		s.keyScan.position = base.Position
+		// The first event with a key belongs to the aggregate the command was
+		// for; a transfer's credit follows its debit.
+		if base.IdempotencyKey == "" || seen[base.IdempotencyKey] {
+			continue
+		}
+		seen[base.IdempotencyKey] = true
+		record := store.IdempotencyRecord{
+			Key:         base.IdempotencyKey,
+			Fingerprint: base.CommandFingerprint,
+			AggregateID: base.AggregateID,
+			Timestamp:   base.Timestamp,
+		}
+		if _, cached, err := s.idempotencyStore.GetRecord(ctx, record.Key); err == nil && !cached {
+			if err := s.idempotencyStore.SaveRecord(ctx, record); err != nil {
+				log.Printf("Warning: Failed to cache recovered idempotency record for key %q: %v", record.Key, err)
+			}
+		}
+		if record.Key == key {
+			match, found = record, true
+		}
+	}
+	return match, found, nil
+}
+
+// recoverIdempotencyRecordFromStream is recoverIdempotencyRecord for event
+// stores without a global log.
+func (s *AccountService) recoverIdempotencyRecordFromStream(ctx context.Context, key string, aggregateID string) (store.IdempotencyRecord, bool, error) {
+	if aggregateID == "" {
+		return store.IdempotencyRecord{}, false, nil
+	}
+
+	stream, err := s.eventStore.GetEventsContext(ctx, aggregateID)
+	if err != nil {
+		return store.IdempotencyRecord{}, false, fmt.Errorf("failed to scan events of %s for idempotency key %q: %w", aggregateID, key, err)
+	}
+
+	for _, event := range stream {
+		base := event.GetBase()
+		if base.IdempotencyKey != key {
+			continue
+		}
+		record := store.IdempotencyRecord{
+			Key:         key,
+			Fingerprint: base.CommandFingerprint,
+			AggregateID: aggregateID,
+			Timestamp:   base.Timestamp,
+		}
+		if err := s.idempotencyStore.SaveRecord(ctx, record); err != nil {
+			log.Printf("Warning: Failed to cache recovered idempotency record for key %q: %v", key, err)
+		}
+		return record, true, nil
+	}
+	return store.IdempotencyRecord{}, false, nil
+}
+
+// streamHasIdempotencyKey reports whether any event of aggregateID carries key.
+func (s *AccountService) streamHasIdempotencyKey(ctx context.Context, aggregateID, key string) (bool, error) {
+	stream, err := s.eventStore.GetEventsContext(ctx, aggregateID)
+	if err != nil {
+		return false, err
+	}
+	for _, event := range stream {
+		if event.GetBase().IdempotencyKey == key {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// completeIdempotent records the successful outcome for aggregateID under the request's key.
+func (s *AccountService) completeIdempotent(ctx context.Context, r *idempotentRequest, aggregateID string) {
+	if r == nil {
+		return
+	}
+	record := store.IdempotencyRecord{
+		Key:         r.key,
+		Fingerprint: r.fingerprint,
+		AggregateID: aggregateID,
+	}
+	if err := s.idempotencyStore.SaveRecord(ctx, record); err != nil {
+		// The key is already stored on the events, so it can still be recovered.
+		log.Printf("Warning: Failed to save idempotency record for key %q: %v", r.key, err)
+	}
+}
+
+func (r *idempotentRequest) done() {
+	if r == nil || r.release == nil {
+		return
+	}
+	r.release()
+	r.release = nil
+}
+
+// commandFingerprint hashes the JSON form of a command with its IdempotencyKey
+// and Metadata removed, so two submissions can be compared for an identical payload.
+// Personal details are left out too: the fingerprint is stored in plaintext on
+// the events and would otherwise let erased data be confirmed by guessing.
+func commandFingerprint(cmd interface{}) (string, error) {
+	raw, err := json.Marshal(cmd)
+	if err != nil {
+		return "", err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return "", err
+	}
+	// Neither the key itself nor the audit metadata is part of the request payload.
+	delete(fields, "IdempotencyKey")
+	delete(fields, "Metadata")
+	delete(fields, "Details")
+
+	canonical, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// idForKey derives a stable account or customer ID from an idempotency key so
+// that a retried creation without an explicit ID targets the same stream.
+func idForKey(key string) string {
+	return uuid.NewSHA1(idempotencyNamespace, []byte(key)).String()
+}
+
+// keyScan is how far recoverIdempotencyRecord has searched the global log.
+type keyScan struct {
+	sync.Mutex
+	position int64
+}
+
+// keyedMutex hands out one lock per idempotency key, dropping it once unused.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*refMutex
+}
+
+type refMutex struct {
+	sync.Mutex
+	refs int
+}
+
+func (k *keyedMutex) lock(key string) func() {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*refMutex)
+	}
+	m, ok := k.locks[key]
+	if !ok {
+		m = &refMutex{}
+		k.locks[key] = m
+	}
+	m.refs++
+	k.mu.Unlock()
+
+	m.Lock()
+	return func() {
+		m.Unlock()
+		k.mu.Lock()
+		m.refs--
+		if m.refs == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
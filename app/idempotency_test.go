@@ -0,0 +1,157 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/app"
+	"financial-ledger/events"
+	"financial-ledger/shared"
+	"financial-ledger/store"
+)
+
+// unavailableEventStore refuses every save to one aggregate while down is set.
+type unavailableEventStore struct {
+	*store.InMemoryEventStore
+	mu          sync.Mutex
+	aggregateID string
+	down        bool
+}
+
+func (s *unavailableEventStore) SaveEventsContext(ctx context.Context, aggregateID string, expectedVersion int, evts []events.Event) error {
+	s.mu.Lock()
+	down := s.down && aggregateID == s.aggregateID
+	s.mu.Unlock()
+	if down {
+		return errors.New("simulated outage")
+	}
+	return s.InMemoryEventStore.SaveEventsContext(ctx, aggregateID, expectedVersion, evts)
+}
+
+func TestAccountService_Idempotency(t *testing.T) {
+	service, eventStore, snapshotStore := setup()
+	id := "acc-idem-1"
+	_, _ = service.CreateAccount(app.CreateAccountCommand{AccountID: id, InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: dec("100")}})
+	_, _ = service.CreateAccount(app.CreateAccountCommand{AccountID: "acc-idem-2"})
+
+	t.Run("RetriedDepositAppliedOnce", func(t *testing.T) {
+		cmd := app.DepositMoneyCommand{AccountID: id, Amount: dec("25"), Currency: shared.USD, IdempotencyKey: "dep-1"}
+		for i := 0; i < 3; i++ {
+			if err := service.Deposit(cmd); err != nil {
+				t.Fatalf("Deposit attempt %d failed: %v", i+1, err)
+			}
+		}
+		balances, _ := service.GetCurrentBalance(app.GetBalanceQuery{AccountID: id})
+		if !balances[shared.USD].Equal(dec("125")) {
+			t.Errorf("expected balance 125 after retried deposit, got %s", balances[shared.USD])
+		}
+
+		evts, _ := eventStore.GetEvents(id)
+		if key := evts[len(evts)-1].GetBase().IdempotencyKey; key != "dep-1" {
+			t.Errorf("expected stored event to carry idempotency key 'dep-1', got %q", key)
+		}
+	})
+
+	t.Run("ConflictOnDifferentPayload", func(t *testing.T) {
+		cmd := app.DepositMoneyCommand{AccountID: id, Amount: dec("99"), Currency: shared.USD, IdempotencyKey: "dep-1"}
+		err := service.Deposit(cmd)
+		if !errors.Is(err, app.ErrIdempotencyConflict) {
+			t.Fatalf("expected ErrIdempotencyConflict, got %v", err)
+		}
+		var conflict *app.IdempotencyConflictError
+		if !errors.As(err, &conflict) || conflict.Key != "dep-1" {
+			t.Errorf("expected IdempotencyConflictError for key 'dep-1', got %#v", err)
+		}
+	})
+
+	t.Run("RetriedTransferAppliedOnce", func(t *testing.T) {
+		cmd := app.TransferMoneyCommand{SourceAccountID: id, TargetAccountID: "acc-idem-2", Amount: dec("10"), Currency: shared.USD, IdempotencyKey: "tx-1"}
+		for i := 0; i < 2; i++ {
+			if err := service.TransferMoney(cmd); err != nil {
+				t.Fatalf("TransferMoney attempt %d failed: %v", i+1, err)
+			}
+		}
+		target, _ := service.GetCurrentBalance(app.GetBalanceQuery{AccountID: "acc-idem-2"})
+		if !target[shared.USD].Equal(dec("10")) {
+			t.Errorf("expected target balance 10 after retried transfer, got %s", target[shared.USD])
+		}
+	})
+
+	t.Run("CreateWithoutIDReturnsOriginalID", func(t *testing.T) {
+		cmd := app.CreateAccountCommand{InitialBalances: map[shared.Currency]decimal.Decimal{shared.EUR: dec("5")}, IdempotencyKey: "create-1"}
+		first, err := service.CreateAccount(cmd)
+		if err != nil {
+			t.Fatalf("CreateAccount failed: %v", err)
+		}
+		second, err := service.CreateAccount(cmd)
+		if err != nil {
+			t.Fatalf("retried CreateAccount failed: %v", err)
+		}
+		if first != second {
+			t.Errorf("expected retried CreateAccount to return %s, got %s", first, second)
+		}
+	})
+
+	t.Run("KeysRecoveredFromEventsAfterRestart", func(t *testing.T) {
+		restarted := app.NewAccountService(eventStore, snapshotStore, app.WithIdempotencyStore(store.NewInMemoryIdempotencyStore()))
+
+		cmd := app.DepositMoneyCommand{AccountID: id, Amount: dec("25"), Currency: shared.USD, IdempotencyKey: "dep-1"}
+		if err := restarted.Deposit(cmd); err != nil {
+			t.Fatalf("retried Deposit after restart failed: %v", err)
+		}
+		balances, _ := restarted.GetCurrentBalance(app.GetBalanceQuery{AccountID: id})
+		if !balances[shared.USD].Equal(dec("115")) {
+			t.Errorf("expected balance 115 after restart retry, got %s", balances[shared.USD])
+		}
+
+		cmd.Amount = dec("1")
+		if err := restarted.Deposit(cmd); !errors.Is(err, app.ErrIdempotencyConflict) {
+			t.Errorf("expected ErrIdempotencyConflict after restart, got %v", err)
+		}
+	})
+	t.Run("KeyReusedOnAnotherAccountAfterRestart", func(t *testing.T) {
+		restarted := app.NewAccountService(eventStore, snapshotStore, app.WithIdempotencyStore(store.NewInMemoryIdempotencyStore()))
+
+		cmd := app.DepositMoneyCommand{AccountID: "acc-idem-2", Amount: dec("25"), Currency: shared.USD, IdempotencyKey: "dep-1"}
+		var conflict *app.IdempotencyConflictError
+		if err := restarted.Deposit(cmd); !errors.As(err, &conflict) || conflict.AggregateID != id {
+			t.Fatalf("expected a conflict naming %s, got %v", id, err)
+		}
+		balances, _ := restarted.GetCurrentBalance(app.GetBalanceQuery{AccountID: "acc-idem-2"})
+		if !balances[shared.USD].Equal(dec("10")) {
+			t.Errorf("conflicting deposit was applied: %s", balances[shared.USD])
+		}
+	})
+
+	t.Run("RetryCompletesUncreditedTransfer", func(t *testing.T) {
+		es := &unavailableEventStore{InMemoryEventStore: store.NewInMemoryEventStore(), aggregateID: "acc-idem-4"}
+		service := app.NewAccountService(es, store.NewInMemorySnapshotStore())
+		_, _ = service.CreateAccount(app.CreateAccountCommand{AccountID: "acc-idem-3", InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: dec("100")}})
+		_, _ = service.CreateAccount(app.CreateAccountCommand{AccountID: "acc-idem-4"})
+
+		cmd := app.TransferMoneyCommand{SourceAccountID: "acc-idem-3", TargetAccountID: "acc-idem-4", Amount: dec("30"), Currency: shared.USD, IdempotencyKey: "tx-2"}
+		es.down = true
+		if err := service.TransferMoney(cmd); err == nil {
+			t.Fatal("expected the credit to fail")
+		}
+		es.down = false
+		for i := 0; i < 2; i++ {
+			if err := service.TransferMoney(cmd); err != nil {
+				t.Fatalf("retry %d failed: %v", i+1, err)
+			}
+		}
+		source, _ := service.GetCurrentBalance(app.GetBalanceQuery{AccountID: "acc-idem-3"})
+		target, _ := service.GetCurrentBalance(app.GetBalanceQuery{AccountID: "acc-idem-4"})
+		if !source[shared.USD].Equal(dec("70")) || !target[shared.USD].Equal(dec("30")) {
+			t.Errorf("expected the transfer completed once, got source %s and target %s", source[shared.USD], target[shared.USD])
+		}
+		credit, _ := es.GetEvents("acc-idem-4")
+		if key := credit[len(credit)-1].GetBase().IdempotencyKey; key != "tx-2" {
+			t.Errorf("expected the completed credit to carry the key, got %q", key)
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/domain"
+	"financial-ledger/shared"
+)
+
+// LimitPolicy assigns transaction limits and velocity controls to accounts.
+// An account's limits are its entry in Accounts if it has one, otherwise
+// those of its tier (product) in AccountTiers, otherwise those of
+// DefaultTier. An account with none of these has no limits.
+type LimitPolicy struct {
+	Tiers        map[string]domain.Limits `json:"tiers,omitempty"`
+	DefaultTier  string                   `json:"defaultTier,omitempty"`
+	AccountTiers map[string]string        `json:"accountTiers,omitempty"`
+	Accounts     map[string]domain.Limits `json:"accounts,omitempty"`
+}
+
+// Validate rejects negative limits and references to tiers that are not
+// defined, which would otherwise leave the account without limits.
+func (p LimitPolicy) Validate() error {
+	if p.DefaultTier != "" {
+		if _, ok := p.Tiers[p.DefaultTier]; !ok {
+			return fmt.Errorf("default tier %q is not defined", p.DefaultTier)
+		}
+	}
+	for accountID, tier := range p.AccountTiers {
+		if _, ok := p.Tiers[tier]; !ok {
+			return fmt.Errorf("account %s is assigned to tier %q, which is not defined", accountID, tier)
+		}
+	}
+	for tier, limits := range p.Tiers {
+		if err := limits.Validate(); err != nil {
+			return fmt.Errorf("tier %q: %w", tier, err)
+		}
+	}
+	for accountID, limits := range p.Accounts {
+		if err := limits.Validate(); err != nil {
+			return fmt.Errorf("account %s: %w", accountID, err)
+		}
+	}
+	return nil
+}
+
+// LimitsFor returns the limits that apply to accountID.
+func (p LimitPolicy) LimitsFor(accountID string) domain.Limits {
+	if limits, ok := p.Accounts[accountID]; ok {
+		return limits
+	}
+	if tier, ok := p.AccountTiers[accountID]; ok {
+		return p.Tiers[tier]
+	}
+	return p.Tiers[p.DefaultTier]
+}
+
+// LoadLimitPolicy reads a LimitPolicy from a JSON file and validates it.
+func LoadLimitPolicy(path string) (LimitPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return LimitPolicy{}, fmt.Errorf("failed to read limits: %w", err)
+	}
+	var p LimitPolicy
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return LimitPolicy{}, fmt.Errorf("invalid limits %s: %w", path, err)
+	}
+	if err := p.Validate(); err != nil {
+		return LimitPolicy{}, fmt.Errorf("invalid limits %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// WithLimitPolicy sets the limits checked before withdrawals and transfers.
+// By default there are none.
+func WithLimitPolicy(p LimitPolicy) ServiceOption {
+	return func(s *AccountService) {
+		s.limitPolicy = p
+	}
+}
+
+// checkLimits returns a *domain.LimitExceededError if the outflow would
+// break one of the account's limits. Usage comes from the loaded aggregate,
+// so a retry after a concurrent write checks against the newer usage.
+func (s *AccountService) checkLimits(account *domain.Account, kind domain.OutflowKind, amount decimal.Decimal, currency shared.Currency) error {
+	limits := s.limitPolicy.LimitsFor(account.ID)
+	if limits.IsZero() {
+		return nil
+	}
+	if err := account.CheckLimits(limits, kind, amount, currency, time.Now()); err != nil {
+		log.Printf("Limit check refused %s from %s: %v", kind, account.ID, err)
+		return err
+	}
+	return nil
+}
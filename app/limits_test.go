@@ -0,0 +1,125 @@
+package app_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/app"
+	"financial-ledger/domain"
+	"financial-ledger/shared"
+	"financial-ledger/store"
+)
+
+func TestAccountService_Limits(t *testing.T) {
+	policy := app.LimitPolicy{
+		Tiers: map[string]domain.Limits{
+			"basic": {
+				Withdrawal: map[shared.Currency]domain.AmountLimits{shared.USD: {Daily: dec("300")}},
+				Transfer:   map[shared.Currency]domain.AmountLimits{shared.USD: {PerTransaction: dec("250")}},
+			},
+			"premium": {MaxOperationsPerHour: 2},
+		},
+		DefaultTier:  "basic",
+		AccountTiers: map[string]string{"lim-premium": "premium"},
+		Accounts:     map[string]domain.Limits{"lim-vip": {}},
+	}
+	service := app.NewAccountService(store.NewInMemoryEventStore(), store.NewInMemorySnapshotStore(), app.WithLimitPolicy(policy))
+	for _, id := range []string{"lim-basic", "lim-premium", "lim-vip"} {
+		if _, err := service.CreateAccount(app.CreateAccountCommand{AccountID: id, InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: dec("5000")}}); err != nil {
+			t.Fatalf("CreateAccount(%s) failed: %v", id, err)
+		}
+	}
+	withdraw := func(id, amount string) error {
+		return service.Withdraw(app.WithdrawMoneyCommand{AccountID: id, Amount: dec(amount), Currency: shared.USD})
+	}
+	limitError := func(t *testing.T, err error) *domain.LimitExceededError {
+		t.Helper()
+		var limitErr *domain.LimitExceededError
+		if !errors.As(err, &limitErr) || !errors.Is(err, domain.ErrLimitExceeded) {
+			t.Fatalf("expected a LimitExceededError, got %v", err)
+		}
+		return limitErr
+	}
+
+	t.Run("DailyWithdrawalLimit", func(t *testing.T) {
+		if err := withdraw("lim-basic", "200"); err != nil {
+			t.Fatalf("Withdraw failed: %v", err)
+		}
+		err := limitError(t, withdraw("lim-basic", "150"))
+		if err.Kind != domain.LimitDaily || !err.Remaining.Equal(dec("100")) {
+			t.Errorf("expected the daily limit with 100 headroom, got %+v", err)
+		}
+		if err := withdraw("lim-basic", "100"); err != nil {
+			t.Errorf("expected the remaining headroom to be usable, got %v", err)
+		}
+		balances, _ := service.GetCurrentBalance(app.GetBalanceQuery{AccountID: "lim-basic"})
+		if !balances[shared.USD].Equal(dec("4700")) {
+			t.Errorf("expected 4700, got %s", balances[shared.USD])
+		}
+	})
+
+	t.Run("PerTransactionTransferLimit", func(t *testing.T) {
+		err := service.TransferMoney(app.TransferMoneyCommand{SourceAccountID: "lim-basic", TargetAccountID: "lim-vip", Amount: dec("251"), Currency: shared.USD})
+		if limitErr := limitError(t, err); limitErr.Kind != domain.LimitPerTransaction || limitErr.Operation != domain.OutflowTransfer {
+			t.Errorf("expected the per-transaction transfer limit, got %+v", limitErr)
+		}
+		balances, _ := service.GetCurrentBalance(app.GetBalanceQuery{AccountID: "lim-vip"})
+		if !balances[shared.USD].Equal(dec("5000")) {
+			t.Errorf("refused transfer credited the target: %s", balances[shared.USD])
+		}
+	})
+
+	t.Run("TierOperationsPerHour", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			if err := withdraw("lim-premium", "1000"); err != nil {
+				t.Fatalf("Withdraw %d failed: %v", i+1, err)
+			}
+		}
+		err := service.TransferMoney(app.TransferMoneyCommand{SourceAccountID: "lim-premium", TargetAccountID: "lim-vip", Amount: dec("1"), Currency: shared.USD})
+		if limitErr := limitError(t, err); limitErr.Kind != domain.LimitOperationsPerHour {
+			t.Errorf("expected the operations limit, got %+v", limitErr)
+		}
+	})
+
+	t.Run("AccountOverrideHasNoLimits", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			if err := withdraw("lim-vip", "400"); err != nil {
+				t.Fatalf("Withdraw %d failed: %v", i+1, err)
+			}
+		}
+	})
+}
+
+func TestLoadLimitPolicy(t *testing.T) {
+	write := func(t *testing.T, contents string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "limits.json")
+		if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	policy, err := app.LoadLimitPolicy(write(t, `{"tiers": {"retail": {"withdrawal": {"USD": {"daily": "1000", "monthly": 5000}}, "maxOperationsPerHour": 20}}, "defaultTier": "retail"}`))
+	if err != nil {
+		t.Fatalf("LoadLimitPolicy failed: %v", err)
+	}
+	limits := policy.LimitsFor("any")
+	if limits.MaxOperationsPerHour != 20 || !limits.Withdrawal[shared.USD].Monthly.Equal(dec("5000")) {
+		t.Errorf("unexpected limits %+v", limits)
+	}
+
+	for name, contents := range map[string]string{
+		"UnknownTier":   `{"accountTiers": {"acc-1": "gold"}}`,
+		"UnknownField":  `{"tier": {}}`,
+		"NegativeLimit": `{"accounts": {"acc-1": {"transfer": {"USD": {"daily": "-1"}}}}}`,
+	} {
+		if _, err := app.LoadLimitPolicy(write(t, contents)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"github.com/google/uuid"
+
+	"financial-ledger/events"
+)
+
+// commandMetadata returns the metadata to stamp on a command's events,
+// generating a CorrelationID if the caller did not supply one.
+func commandMetadata(meta events.Metadata) events.Metadata {
+	if meta.CorrelationID == "" {
+		meta.CorrelationID = uuid.NewString()
+	}
+	return meta
+}
+
+// causedBy returns a copy of meta whose CausationID points at the given event.
+func causedBy(meta events.Metadata, cause events.Event) events.Metadata {
+	meta.CausationID = cause.GetBase().EventID.String()
+	return meta
+}
+
+// stampEvents copies request-level data onto every event about to be saved: the
+// metadata envelope and, if the command carried one, its idempotency key.
+func stampEvents(changes []events.Event, meta events.Metadata, idem *idempotentRequest) []events.Event {
+	stamped := make([]events.Event, len(changes))
+	for i, event := range changes {
+		stamped[i] = events.WithBase(event, func(base *events.BaseEvent) {
+			base.Metadata = meta
+			if idem != nil {
+				base.IdempotencyKey = idem.key
+				base.CommandFingerprint = idem.fingerprint
+			}
+		})
+	}
+	return stamped
+}
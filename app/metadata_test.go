@@ -0,0 +1,63 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/app"
+	"financial-ledger/events"
+	"financial-ledger/shared"
+)
+
+func TestAccountService_EventMetadata(t *testing.T) {
+	service, eventStore, _ := setup()
+	meta := events.Metadata{Actor: "teller-7", Channel: "branch", Reason: "customer request"}
+	_, _ = service.CreateAccount(app.CreateAccountCommand{AccountID: "acc-meta-1", InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: dec("100")}, Metadata: meta})
+	_, _ = service.CreateAccount(app.CreateAccountCommand{AccountID: "acc-meta-2"})
+
+	t.Run("StampedOnEvents", func(t *testing.T) {
+		err := service.Withdraw(app.WithdrawMoneyCommand{AccountID: "acc-meta-1", Amount: dec("5"), Currency: shared.USD, Metadata: meta})
+		if err != nil {
+			t.Fatalf("Withdraw failed: %v", err)
+		}
+		evts, _ := eventStore.GetEvents("acc-meta-1")
+		got := evts[len(evts)-1].GetBase().Metadata
+		if got.Actor != "teller-7" || got.Channel != "branch" || got.Reason != "customer request" {
+			t.Errorf("metadata not stamped on withdrawal event: %+v", got)
+		}
+		if got.CorrelationID == "" {
+			t.Errorf("expected a generated correlation ID")
+		}
+		if got.CorrelationID == evts[0].GetBase().Metadata.CorrelationID {
+			t.Errorf("expected separate commands to get distinct correlation IDs")
+		}
+	})
+
+	t.Run("TransferCreditCausedByDebit", func(t *testing.T) {
+		cmd := app.TransferMoneyCommand{
+			SourceAccountID: "acc-meta-1",
+			TargetAccountID: "acc-meta-2",
+			Amount:          dec("10"),
+			Currency:        shared.USD,
+			Metadata:        events.Metadata{Actor: "api-client", CorrelationID: "req-42"},
+		}
+		if err := service.TransferMoney(cmd); err != nil {
+			t.Fatalf("TransferMoney failed: %v", err)
+		}
+		sourceEvts, _ := eventStore.GetEvents("acc-meta-1")
+		targetEvts, _ := eventStore.GetEvents("acc-meta-2")
+		debit := sourceEvts[len(sourceEvts)-1].GetBase()
+		credit := targetEvts[len(targetEvts)-1].GetBase()
+
+		if debit.Metadata.CorrelationID != "req-42" || credit.Metadata.CorrelationID != "req-42" {
+			t.Errorf("expected both legs to carry correlation ID req-42, got %q and %q", debit.Metadata.CorrelationID, credit.Metadata.CorrelationID)
+		}
+		if debit.Metadata.CausationID != "" {
+			t.Errorf("expected debit to have no causation ID, got %q", debit.Metadata.CausationID)
+		}
+		if credit.Metadata.CausationID != debit.EventID.String() {
+			t.Errorf("expected credit causation ID %s, got %q", debit.EventID, credit.Metadata.CausationID)
+		}
+	})
+}
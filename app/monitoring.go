@@ -0,0 +1,72 @@
+package app
+
+import (
+	"context"
+	"log"
+	"slices"
+	"time"
+
+	"financial-ledger/monitoring"
+	"financial-ledger/store"
+)
+
+// WithMonitor replaces the default transaction monitor, which has no rules
+// and keeps its alerts in memory.
+func WithMonitor(m *monitoring.Monitor) ServiceOption {
+	return func(s *AccountService) {
+		if m != nil {
+			s.monitor = m
+		}
+	}
+}
+
+// catchUpMonitoring evaluates the monitoring rules against every event
+// committed since the last call.
+func (s *AccountService) catchUpMonitoring(ctx context.Context) error {
+	if s.projections == nil {
+		return ErrGlobalLogUnsupported
+	}
+	return s.projections.CatchUp(ctx, monitoring.ProjectionName)
+}
+
+// ListAlerts evaluates the monitoring rules against new events and returns
+// the matching alerts, oldest first.
+func (s *AccountService) ListAlerts(ctx context.Context, query ListAlertsQuery) ([]store.Alert, error) {
+	if err := s.catchUpMonitoring(ctx); err != nil {
+		return nil, err
+	}
+	alerts, err := s.monitor.Alerts(ctx, query.Status)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(alerts, func(a store.Alert) bool {
+		return (query.AccountID != "" && a.AccountID != query.AccountID) || (query.RuleID != "" && a.RuleID != query.RuleID)
+	}), nil
+}
+
+func (s *AccountService) GetAlert(ctx context.Context, id string) (store.Alert, error) {
+	return s.monitor.Alert(ctx, id)
+}
+
+// DispositionAlert records a review of an alert by cmd.Metadata.Actor.
+func (s *AccountService) DispositionAlert(ctx context.Context, cmd DispositionAlertCommand) (store.Alert, error) {
+	return s.monitor.Disposition(ctx, cmd.AlertID, cmd.Status, cmd.Metadata.Actor, cmd.Note)
+}
+
+// RunMonitoring evaluates the monitoring rules against new events every
+// interval until ctx is cancelled, so alerts are raised without waiting for
+// someone to list them.
+func (s *AccountService) RunMonitoring(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.catchUpMonitoring(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("ERROR: Transaction monitoring failed: %v", err)
+			}
+		}
+	}
+}
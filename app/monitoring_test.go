@@ -0,0 +1,71 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/app"
+	"financial-ledger/events"
+	"financial-ledger/monitoring"
+	"financial-ledger/shared"
+	"financial-ledger/store"
+)
+
+func TestAccountService_Monitoring(t *testing.T) {
+	ctx := context.Background()
+	rules := monitoring.Rules{Rules: []monitoring.Rule{
+		{ID: "round", Type: monitoring.RuleRoundAmount, MinAmount: dec("1000"), Multiple: dec("1000")},
+	}}
+	service := app.NewAccountService(store.NewInMemoryEventStore(), store.NewInMemorySnapshotStore(),
+		app.WithMonitor(monitoring.NewMonitor(store.NewInMemoryAlertStore(), rules)))
+	for _, id := range []string{"mon-1", "mon-2"} {
+		if _, err := service.CreateAccount(app.CreateAccountCommand{AccountID: id, InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: dec("10000")}}); err != nil {
+			t.Fatalf("CreateAccount(%s) failed: %v", id, err)
+		}
+	}
+	transfer := func(from, to, amount string) {
+		t.Helper()
+		if err := service.TransferMoney(app.TransferMoneyCommand{SourceAccountID: from, TargetAccountID: to, Amount: dec(amount), Currency: shared.USD}); err != nil {
+			t.Fatalf("TransferMoney failed: %v", err)
+		}
+	}
+	transfer("mon-1", "mon-2", "2000")
+	transfer("mon-1", "mon-2", "2500")
+	transfer("mon-2", "mon-1", "3000")
+
+	t.Run("ListCatchesUp", func(t *testing.T) {
+		alerts, err := service.ListAlerts(ctx, app.ListAlertsQuery{})
+		if err != nil {
+			t.Fatalf("ListAlerts failed: %v", err)
+		}
+		if len(alerts) != 2 || alerts[0].AccountID != "mon-1" || alerts[1].AccountID != "mon-2" {
+			t.Fatalf("expected an alert on each source account, got %+v", alerts)
+		}
+		filtered, _ := service.ListAlerts(ctx, app.ListAlertsQuery{AccountID: "mon-2", RuleID: "round"})
+		if len(filtered) != 1 || filtered[0].ID != alerts[1].ID {
+			t.Errorf("expected the mon-2 alert only, got %+v", filtered)
+		}
+	})
+
+	t.Run("Disposition", func(t *testing.T) {
+		alerts, _ := service.ListAlerts(ctx, app.ListAlertsQuery{AccountID: "mon-1"})
+		alert, err := service.DispositionAlert(ctx, app.DispositionAlertCommand{
+			AlertID:  alerts[0].ID,
+			Status:   store.AlertCleared,
+			Note:     "rent",
+			Metadata: events.Metadata{Actor: "carol"},
+		})
+		if err != nil {
+			t.Fatalf("DispositionAlert failed: %v", err)
+		}
+		if alert.Status != store.AlertCleared || alert.Dispositions[0].Actor != "carol" {
+			t.Errorf("unexpected alert %+v", alert)
+		}
+		open, _ := service.ListAlerts(ctx, app.ListAlertsQuery{Status: store.AlertOpen})
+		if len(open) != 1 || open[0].AccountID != "mon-2" {
+			t.Errorf("expected only the mon-2 alert open, got %+v", open)
+		}
+	})
+}
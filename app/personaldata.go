@@ -0,0 +1,149 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"financial-ledger/events"
+	"financial-ledger/store"
+)
+
+// RedactedValue replaces personal data whose subject has been forgotten.
+const RedactedValue = "[redacted]"
+
+// WithSubjectKeyStore replaces the default in-memory store of per-subject keys.
+func WithSubjectKeyStore(ks store.SubjectKeyStore) ServiceOption {
+	return func(s *AccountService) {
+		if ks != nil {
+			s.subjectKeys = ks
+		}
+	}
+}
+
+// AccountDetails is the personal-data view of an account. Redacted is set, and
+// every field holds RedactedValue, once the data subject has been forgotten.
+type AccountDetails struct {
+	AccountID string
+	SubjectID string
+	PersonalDetails
+	Redacted bool
+}
+
+func (s *AccountService) sealDetails(ctx context.Context, subjectID string, details any) (events.SealedPersonalData, error) {
+	plaintext, err := json.Marshal(details)
+	if err != nil {
+		return events.SealedPersonalData{}, fmt.Errorf("failed to serialise personal details: %w", err)
+	}
+	sealed, err := s.subjectKeys.Seal(ctx, subjectID, plaintext)
+	if err != nil {
+		return events.SealedPersonalData{}, fmt.Errorf("failed to encrypt personal details for subject %s: %w", subjectID, err)
+	}
+	return sealed, nil
+}
+
+// RevealPersonalData decrypts personal data for display. If the subject has been
+// forgotten, it returns RedactedValue in every field and redacted is true.
+func (s *AccountService) RevealPersonalData(ctx context.Context, sealed events.SealedPersonalData) (details PersonalDetails, redacted bool, err error) {
+	plaintext, err := s.subjectKeys.Open(ctx, sealed)
+	if errors.Is(err, store.ErrSubjectForgotten) {
+		return PersonalDetails{OwnerName: RedactedValue, Address: RedactedValue, Notes: RedactedValue}, true, nil
+	}
+	if err != nil {
+		return PersonalDetails{}, false, fmt.Errorf("failed to decrypt personal details for subject %s: %w", sealed.SubjectID, err)
+	}
+	if err := json.Unmarshal(plaintext, &details); err != nil {
+		return PersonalDetails{}, false, fmt.Errorf("failed to decode personal details for subject %s: %w", sealed.SubjectID, err)
+	}
+	return details, false, nil
+}
+
+// UpdateAccountDetails replaces the personal data held for an account. The
+// subject defaults to the account's current subject, or the account ID.
+func (s *AccountService) UpdateAccountDetails(ctx context.Context, cmd UpdateAccountDetailsCommand) error {
+	idem, replay, err := s.beginIdempotent(ctx, cmd.IdempotencyKey, cmd, cmd.AccountID)
+	if err != nil {
+		return err
+	}
+	if replay != nil {
+		return nil
+	}
+	defer idem.done()
+
+	meta := commandMetadata(cmd.Metadata)
+
+	return s.retryOnConflict(ctx, "details update", func(attempt int) error {
+		account, err := s.loadAccount(ctx, cmd.AccountID)
+		if err != nil {
+			return fmt.Errorf("failed to load account %s for details update: %w", cmd.AccountID, err)
+		}
+
+		initialVersion := account.Version
+
+		subjectID := cmd.SubjectID
+		if subjectID == "" {
+			subjectID = account.SubjectID
+		}
+		if subjectID == "" {
+			subjectID = account.ID
+		}
+		sealed, err := s.sealDetails(ctx, subjectID, cmd.Details)
+		if err != nil {
+			return err
+		}
+
+		err = account.HandleUpdateDetails(sealed)
+		if err != nil {
+			return fmt.Errorf("details update failed for account %s: %w", cmd.AccountID, err)
+		}
+
+		changes := account.GetUncommitedChanges()
+		err = s.eventStore.SaveEventsContext(ctx, cmd.AccountID, initialVersion, stampEvents(changes, meta, idem))
+		if err != nil {
+			return fmt.Errorf("failed to save details update for account %s: %w", cmd.AccountID, err)
+		}
+		s.completeIdempotent(ctx, idem, cmd.AccountID)
+
+		log.Printf("Personal details updated for account %s. New Version: %d", cmd.AccountID, account.Version)
+
+		s.saveSnapshotIfNeeded(ctx, account)
+		return nil
+	})
+}
+
+// GetAccountDetails returns the account's personal data, redacted if its
+// subject has been forgotten.
+func (s *AccountService) GetAccountDetails(ctx context.Context, query GetAccountDetailsQuery) (*AccountDetails, error) {
+	account, err := s.loadAccount(ctx, query.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load account %s for details query: %w", query.AccountID, err)
+	}
+
+	view := &AccountDetails{AccountID: account.ID, SubjectID: account.SubjectID}
+	if account.PersonalData == nil {
+		return view, nil
+	}
+	view.PersonalDetails, view.Redacted, err = s.RevealPersonalData(ctx, *account.PersonalData)
+	if err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// ForgetSubject erases a data subject's personal data by destroying the keys it
+// was encrypted under. Events are left untouched, so balances and the hash
+// chain are unaffected; every view of the data shows RedactedValue afterwards.
+func (s *AccountService) ForgetSubject(ctx context.Context, cmd ForgetSubjectCommand) (store.ShredRecord, error) {
+	if cmd.SubjectID == "" {
+		return store.ShredRecord{}, errors.New("subject ID cannot be empty")
+	}
+	record, err := s.subjectKeys.Shred(ctx, cmd.SubjectID)
+	if err != nil {
+		return store.ShredRecord{}, fmt.Errorf("failed to forget subject %s: %w", cmd.SubjectID, err)
+	}
+	log.Printf("Subject %s forgotten (actor: %q, reason: %q): %d keys destroyed",
+		cmd.SubjectID, cmd.Metadata.Actor, cmd.Metadata.Reason, record.KeysDestroyed)
+	return record, nil
+}
@@ -0,0 +1,102 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/app"
+	"financial-ledger/events"
+	"financial-ledger/shared"
+	"financial-ledger/store"
+)
+
+func TestAccountService_PersonalData(t *testing.T) {
+	ctx := context.Background()
+	service, eventStore, _ := setup()
+	accID := "acc-pii-1"
+
+	_, err := service.CreateAccount(app.CreateAccountCommand{
+		AccountID:       accID,
+		InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: dec("100")},
+		SubjectID:       "subj-1",
+		Details:         &app.PersonalDetails{OwnerName: "Jane Doe", Address: "1 Main St", Notes: "prefers email"},
+	})
+	if err != nil {
+		t.Fatalf("CreateAccount failed: %v", err)
+	}
+	for i := 0; i < app.SnapshotFrequency; i++ {
+		_ = service.Deposit(app.DepositMoneyCommand{AccountID: accID, Amount: dec("1"), Currency: shared.USD})
+	}
+
+	t.Run("StoredEncrypted", func(t *testing.T) {
+		stream, _ := eventStore.GetEvents(accID)
+		created := stream[0].(events.AccountCreatedEvent)
+		if created.PersonalData == nil || created.PersonalData.SubjectID != "subj-1" {
+			t.Fatalf("expected sealed personal data on creation event, got %+v", created.PersonalData)
+		}
+		if string(created.PersonalData.Data) == "Jane Doe" {
+			t.Errorf("personal data must not be stored in plaintext")
+		}
+	})
+
+	t.Run("ReadBack", func(t *testing.T) {
+		details, err := service.GetAccountDetails(ctx, app.GetAccountDetailsQuery{AccountID: accID})
+		if err != nil {
+			t.Fatalf("GetAccountDetails failed: %v", err)
+		}
+		if details.Redacted || details.OwnerName != "Jane Doe" || details.SubjectID != "subj-1" {
+			t.Errorf("unexpected details: %+v", details)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		err := service.UpdateAccountDetails(ctx, app.UpdateAccountDetailsCommand{
+			AccountID: accID,
+			Details:   app.PersonalDetails{OwnerName: "Jane Smith", Address: "2 High St"},
+		})
+		if err != nil {
+			t.Fatalf("UpdateAccountDetails failed: %v", err)
+		}
+		details, _ := service.GetAccountDetails(ctx, app.GetAccountDetailsQuery{AccountID: accID})
+		if details.OwnerName != "Jane Smith" || details.SubjectID != "subj-1" {
+			t.Errorf("unexpected details after update: %+v", details)
+		}
+	})
+
+	t.Run("ForgetSubject", func(t *testing.T) {
+		record, err := service.ForgetSubject(ctx, app.ForgetSubjectCommand{SubjectID: "subj-1"})
+		if err != nil || record.KeysDestroyed == 0 {
+			t.Fatalf("ForgetSubject failed: %+v, %v", record, err)
+		}
+
+		details, err := service.GetAccountDetails(ctx, app.GetAccountDetailsQuery{AccountID: accID})
+		if err != nil {
+			t.Fatalf("GetAccountDetails failed after erasure: %v", err)
+		}
+		if !details.Redacted || details.OwnerName != app.RedactedValue || details.Address != app.RedactedValue {
+			t.Errorf("expected redacted details, got %+v", details)
+		}
+
+		history, _ := service.GetTransactionHistory(app.GetHistoryQuery{AccountID: accID, Limit: 1})
+		created := history[0].(events.AccountCreatedEvent)
+		shown, redacted, err := service.RevealPersonalData(ctx, *created.PersonalData)
+		if err != nil || !redacted || shown.OwnerName != app.RedactedValue {
+			t.Errorf("expected history to show redacted values, got %+v (redacted %v, err %v)", shown, redacted, err)
+		}
+	})
+
+	t.Run("BalancesStillReplay", func(t *testing.T) {
+		balances, err := service.GetCurrentBalance(app.GetBalanceQuery{AccountID: accID})
+		if err != nil {
+			t.Fatalf("GetCurrentBalance failed: %v", err)
+		}
+		if want := dec("200"); !balances[shared.USD].Equal(want) {
+			t.Errorf("expected balance %s, got %s", want, balances[shared.USD])
+		}
+		if _, brk, err := store.VerifyChain(ctx, eventStore); err != nil || brk != nil {
+			t.Errorf("expected intact hash chain after erasure, got %v, %v", brk, err)
+		}
+	})
+}
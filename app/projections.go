@@ -0,0 +1,82 @@
+package app
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/projection"
+	"financial-ledger/shared"
+	"financial-ledger/store"
+)
+
+// WithProjectionCheckpointStore replaces the default in-memory store of
+// projection checkpoints.
+func WithProjectionCheckpointStore(cs store.ProjectionCheckpointStore) ServiceOption {
+	return func(s *AccountService) {
+		if cs != nil {
+			s.projectionCheckpoints = cs
+		}
+	}
+}
+
+// initProjections registers the built-in projections. Without a global log
+// there is nothing to project from, and reads fall back to replaying aggregates.
+func (s *AccountService) initProjections() {
+	gl, ok := s.eventStore.(store.GlobalLog)
+	if !ok {
+		log.Printf("Warning: Event store %T has no global log; projections are disabled.", s.eventStore)
+		return
+	}
+	s.projections = projection.NewRunner(gl, s.projectionCheckpoints)
+	s.balances = projection.NewBalanceProjection()
+	for _, p := range []projection.Projection{s.balances, s.directory, s.customers, s.approvals, s.webhooks, s.monitor} {
+		if err := s.projections.Register(context.Background(), p); err != nil {
+			log.Printf("ERROR: Failed to register projection %s: %v. Projections are disabled.", p.Name(), err)
+			s.projections = nil
+			return
+		}
+	}
+}
+
+// projectedBalances answers a balance query from the balance projection after
+// catching it up. ok is false if the projection cannot answer, in which case
+// the caller should load the aggregate instead.
+func (s *AccountService) projectedBalances(ctx context.Context, accountID string) (map[shared.Currency]decimal.Decimal, int, bool) {
+	if s.projections == nil {
+		return nil, 0, false
+	}
+	if err := s.projections.CatchUp(ctx, projection.BalanceProjectionName); err != nil {
+		log.Printf("Warning: Balance projection unavailable: %v. Falling back to replay for account %s.", err, accountID)
+		return nil, 0, false
+	}
+	return s.balances.Balances(accountID)
+}
+
+// ProjectionStatus reports the position and lag of every projection.
+func (s *AccountService) ProjectionStatus(ctx context.Context) ([]projection.Status, error) {
+	if s.projections == nil {
+		return nil, ErrGlobalLogUnsupported
+	}
+	return s.projections.Status(ctx)
+}
+
+// RebuildProjection discards the named projection and rebuilds it from the
+// start of the global log.
+func (s *AccountService) RebuildProjection(ctx context.Context, name string) error {
+	if s.projections == nil {
+		return ErrGlobalLogUnsupported
+	}
+	return s.projections.Rebuild(ctx, name)
+}
+
+// RunProjections keeps every projection caught up, polling each interval, until
+// ctx is cancelled. Reads catch up on demand, so this only bounds their lag.
+func (s *AccountService) RunProjections(ctx context.Context, interval time.Duration) {
+	if s.projections == nil {
+		return
+	}
+	s.projections.Run(ctx, interval)
+}
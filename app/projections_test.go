@@ -0,0 +1,110 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/app"
+	"financial-ledger/events"
+	"financial-ledger/projection"
+	"financial-ledger/shared"
+	"financial-ledger/store"
+)
+
+// countingEventStore counts per-stream reads, which is how aggregates are replayed.
+type countingEventStore struct {
+	*store.InMemoryEventStore
+	streamReads atomic.Int64
+}
+
+func (s *countingEventStore) GetEventsContext(ctx context.Context, aggregateID string) ([]events.Event, error) {
+	s.streamReads.Add(1)
+	return s.InMemoryEventStore.GetEventsContext(ctx, aggregateID)
+}
+
+func (s *countingEventStore) GetEventsAfterVersionContext(ctx context.Context, aggregateID string, version int) ([]events.Event, error) {
+	s.streamReads.Add(1)
+	return s.InMemoryEventStore.GetEventsAfterVersionContext(ctx, aggregateID, version)
+}
+
+func TestAccountService_BalanceProjection(t *testing.T) {
+	ctx := context.Background()
+	es := &countingEventStore{InMemoryEventStore: store.NewInMemoryEventStore()}
+	checkpoints := store.NewInMemoryProjectionCheckpointStore()
+	service := app.NewAccountService(es, store.NewInMemorySnapshotStore(), app.WithProjectionCheckpointStore(checkpoints))
+
+	id := "acc-proj-1"
+	if _, err := service.CreateAccount(app.CreateAccountCommand{AccountID: id, InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: dec("10")}}); err != nil {
+		t.Fatalf("CreateAccount failed: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := service.Deposit(app.DepositMoneyCommand{AccountID: id, Amount: dec("2"), Currency: shared.USD}); err != nil {
+			t.Fatalf("Deposit failed: %v", err)
+		}
+	}
+
+	t.Run("AnswersWithoutReplay", func(t *testing.T) {
+		before := es.streamReads.Load()
+		balances, err := service.GetCurrentBalanceContext(ctx, app.GetBalanceQuery{AccountID: id})
+		if err != nil {
+			t.Fatalf("GetCurrentBalance failed: %v", err)
+		}
+		if !balances[shared.USD].Equal(dec("20")) {
+			t.Errorf("expected USD 20, got %s", balances[shared.USD])
+		}
+		if reads := es.streamReads.Load() - before; reads != 0 {
+			t.Errorf("expected no stream reads, got %d", reads)
+		}
+	})
+
+	t.Run("ReadsOwnWrites", func(t *testing.T) {
+		if err := service.Withdraw(app.WithdrawMoneyCommand{AccountID: id, Amount: dec("5"), Currency: shared.USD}); err != nil {
+			t.Fatalf("Withdraw failed: %v", err)
+		}
+		usd := shared.USD
+		balances, err := service.GetCurrentBalanceContext(ctx, app.GetBalanceQuery{AccountID: id, Currency: &usd})
+		if err != nil {
+			t.Fatalf("GetCurrentBalance failed: %v", err)
+		}
+		if len(balances) != 1 || !balances[shared.USD].Equal(dec("15")) {
+			t.Errorf("expected USD 15, got %v", balances)
+		}
+	})
+
+	t.Run("UnknownAccount", func(t *testing.T) {
+		if _, err := service.GetCurrentBalanceContext(ctx, app.GetBalanceQuery{AccountID: "missing"}); err == nil {
+			t.Errorf("expected an error for an unknown account")
+		}
+	})
+
+	t.Run("StatusAndRebuild", func(t *testing.T) {
+		statuses, err := service.ProjectionStatus(ctx)
+		if err != nil {
+			t.Fatalf("ProjectionStatus failed: %v", err)
+		}
+		var balancesStatus *projection.Status
+		for i := range statuses {
+			if statuses[i].Name == projection.BalanceProjectionName {
+				balancesStatus = &statuses[i]
+			}
+		}
+		if balancesStatus == nil || balancesStatus.Head != 7 || balancesStatus.Lag != 0 {
+			t.Fatalf("unexpected balance projection status %+v", statuses)
+		}
+
+		if err := service.RebuildProjection(ctx, projection.BalanceProjectionName); err != nil {
+			t.Fatalf("RebuildProjection failed: %v", err)
+		}
+		balances, err := service.GetCurrentBalanceContext(ctx, app.GetBalanceQuery{AccountID: id})
+		if err != nil || !balances[shared.USD].Equal(dec("15")) {
+			t.Errorf("expected USD 15 after rebuild, got %v (err %v)", balances, err)
+		}
+		if err := service.RebuildProjection(ctx, "missing"); !errors.Is(err, projection.ErrUnknownProjection) {
+			t.Errorf("expected ErrUnknownProjection, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"time"
+
+	"financial-ledger/domain"
+	"financial-ledger/store"
+)
+
+// RetryPolicy controls how AccountService retries a command whose events were
+// rejected by the EventStore with store.ErrOptimisticLock. Each retry reloads the
+// aggregate and re-runs the domain handler; domain rejections are never retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values below 1 are treated as 1 (no retries).
+	MaxAttempts int
+	// BaseDelay is the backoff ceiling before the first retry; it doubles per
+	// attempt up to MaxDelay. The actual sleep is a random duration below it.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Observer, if set, is called once per operation with the number of attempts
+	// made and the final error (nil on success).
+	Observer func(operation string, attempts int, err error)
+}
+
+// DefaultRetryPolicy returns a policy suitable for contended accounts.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   5 * time.Millisecond,
+		MaxDelay:    100 * time.Millisecond,
+	}
+}
+
+// WithRetryPolicy enables retries on version conflicts. Without it every command
+// is attempted exactly once.
+func WithRetryPolicy(policy RetryPolicy) ServiceOption {
+	return func(s *AccountService) {
+		s.retryPolicy = policy
+	}
+}
+
+// ErrVersionMismatch is returned when a command's ExpectedVersion does not match
+// the account. Unlike store.ErrOptimisticLock it is never retried: the caller
+// asked for a change to a specific version and must re-read before trying again.
+var ErrVersionMismatch = errors.New("account version does not match expected version")
+
+func checkExpectedVersion(account *domain.Account, expected int) error {
+	if expected != 0 && account.Version != expected {
+		return fmt.Errorf("%w: account %s is at version %d, expected %d", ErrVersionMismatch, account.ID, account.Version, expected)
+	}
+	return nil
+}
+
+// RetryExhaustedError is returned when every attempt allowed by the RetryPolicy
+// failed with a version conflict. It unwraps to the last conflict error.
+type RetryExhaustedError struct {
+	Operation string
+	Attempts  int
+	Err       error
+}
+
+func (e *RetryExhaustedError) Error() string {
+	return fmt.Sprintf("%s failed after %d attempts: %v", e.Operation, e.Attempts, e.Err)
+}
+
+func (e *RetryExhaustedError) Unwrap() error {
+	return e.Err
+}
+
+// retryOnConflict runs fn until it succeeds, fails with anything other than
+// store.ErrOptimisticLock, or the policy's attempts are used up. fn receives the
+// 1-based attempt number and must reload any aggregate it mutates when attempt > 1.
+func (s *AccountService) retryOnConflict(ctx context.Context, operation string, fn func(attempt int) error) error {
+	maxAttempts := s.retryPolicy.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn(attempt)
+		if err == nil || !errors.Is(err, store.ErrOptimisticLock) {
+			s.reportAttempts(operation, attempt, err)
+			return err
+		}
+
+		if attempt >= maxAttempts {
+			if maxAttempts > 1 {
+				err = &RetryExhaustedError{Operation: operation, Attempts: attempt, Err: err}
+			}
+			s.reportAttempts(operation, attempt, err)
+			return err
+		}
+
+		delay := s.retryPolicy.backoff(attempt)
+		log.Printf("Version conflict during %s (attempt %d/%d): %v. Retrying in %s.", operation, attempt, maxAttempts, err, delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			err = fmt.Errorf("%s abandoned after %d attempts: %w", operation, attempt, ctx.Err())
+			s.reportAttempts(operation, attempt, err)
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+func (s *AccountService) reportAttempts(operation string, attempts int, err error) {
+	if attempts > 1 {
+		log.Printf("%s finished after %d attempts (error: %v)", operation, attempts, err)
+	}
+	if s.retryPolicy.Observer != nil {
+		s.retryPolicy.Observer(operation, attempts, err)
+	}
+}
+
+// backoff returns a jittered delay for the retry following the given attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	if p.BaseDelay <= 0 {
+		return 0
+	}
+	ceiling := p.BaseDelay << (attempt - 1)
+	if ceiling <= 0 || (p.MaxDelay > 0 && ceiling > p.MaxDelay) {
+		ceiling = p.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling + 1)
+}
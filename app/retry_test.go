@@ -0,0 +1,178 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/app"
+	"financial-ledger/domain"
+	"financial-ledger/events"
+	"financial-ledger/shared"
+	"financial-ledger/store"
+)
+
+// conflictingEventStore rejects the first `conflicts` saves with ErrOptimisticLock.
+type conflictingEventStore struct {
+	*store.InMemoryEventStore
+	mu        sync.Mutex
+	conflicts int
+}
+
+func (s *conflictingEventStore) SaveEventsContext(ctx context.Context, aggregateID string, expectedVersion int, evts []events.Event) error {
+	s.mu.Lock()
+	if s.conflicts > 0 {
+		s.conflicts--
+		s.mu.Unlock()
+		return fmt.Errorf("%w: simulated conflict", store.ErrOptimisticLock)
+	}
+	s.mu.Unlock()
+	return s.InMemoryEventStore.SaveEventsContext(ctx, aggregateID, expectedVersion, evts)
+}
+
+func TestAccountService_RetryOnConflict(t *testing.T) {
+	fastPolicy := func(attempts int, observed *[]int) app.RetryPolicy {
+		var mu sync.Mutex
+		return app.RetryPolicy{
+			MaxAttempts: attempts,
+			BaseDelay:   time.Microsecond,
+			MaxDelay:    time.Millisecond,
+			Observer: func(operation string, n int, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				*observed = append(*observed, n)
+			},
+		}
+	}
+
+	t.Run("ConcurrentDepositsAllSucceed", func(t *testing.T) {
+		var observed []int
+		service := app.NewAccountService(store.NewInMemoryEventStore(), store.NewInMemorySnapshotStore(), app.WithRetryPolicy(fastPolicy(100, &observed)))
+		id := "acc-retry-1"
+		_, _ = service.CreateAccount(app.CreateAccountCommand{AccountID: id, InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: dec("100")}})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := service.Deposit(app.DepositMoneyCommand{AccountID: id, Amount: dec("10"), Currency: shared.USD}); err != nil {
+					t.Errorf("Deposit failed despite retry policy: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		balances, _ := service.GetCurrentBalance(app.GetBalanceQuery{AccountID: id})
+		if !balances[shared.USD].Equal(dec("300")) {
+			t.Errorf("expected balance 300 after 20 concurrent deposits, got %s", balances[shared.USD])
+		}
+	})
+
+	t.Run("ReportsAttempts", func(t *testing.T) {
+		var observed []int
+		es := &conflictingEventStore{InMemoryEventStore: store.NewInMemoryEventStore()}
+		service := app.NewAccountService(es, store.NewInMemorySnapshotStore(), app.WithRetryPolicy(fastPolicy(5, &observed)))
+		id := "acc-retry-2"
+		_, _ = service.CreateAccount(app.CreateAccountCommand{AccountID: id})
+
+		es.conflicts = 2
+		if err := service.Deposit(app.DepositMoneyCommand{AccountID: id, Amount: dec("1"), Currency: shared.USD}); err != nil {
+			t.Fatalf("Deposit failed: %v", err)
+		}
+		if len(observed) != 1 || observed[0] != 3 {
+			t.Errorf("expected one report of 3 attempts, got %v", observed)
+		}
+	})
+
+	t.Run("ExhaustedReturnsTypedError", func(t *testing.T) {
+		var observed []int
+		es := &conflictingEventStore{InMemoryEventStore: store.NewInMemoryEventStore()}
+		service := app.NewAccountService(es, store.NewInMemorySnapshotStore(), app.WithRetryPolicy(fastPolicy(3, &observed)))
+		id := "acc-retry-3"
+		_, _ = service.CreateAccount(app.CreateAccountCommand{AccountID: id})
+
+		es.conflicts = 10
+		err := service.Deposit(app.DepositMoneyCommand{AccountID: id, Amount: dec("1"), Currency: shared.USD})
+		var exhausted *app.RetryExhaustedError
+		if !errors.As(err, &exhausted) || exhausted.Attempts != 3 {
+			t.Fatalf("expected RetryExhaustedError after 3 attempts, got %v", err)
+		}
+		if !errors.Is(err, store.ErrOptimisticLock) {
+			t.Errorf("expected error to unwrap to ErrOptimisticLock, got %v", err)
+		}
+	})
+
+	t.Run("DomainRejectionNotRetried", func(t *testing.T) {
+		var observed []int
+		service := app.NewAccountService(store.NewInMemoryEventStore(), store.NewInMemorySnapshotStore(), app.WithRetryPolicy(fastPolicy(5, &observed)))
+		id := "acc-retry-4"
+		_, _ = service.CreateAccount(app.CreateAccountCommand{AccountID: id})
+
+		err := service.Withdraw(app.WithdrawMoneyCommand{AccountID: id, Amount: dec("1"), Currency: shared.USD})
+		if !errors.Is(err, domain.ErrInsufficientFunds) {
+			t.Fatalf("expected ErrInsufficientFunds, got %v", err)
+		}
+		if len(observed) != 1 || observed[0] != 1 {
+			t.Errorf("expected a single attempt for a domain rejection, got %v", observed)
+		}
+	})
+}
+
+func TestAccountService_ExpectedVersion(t *testing.T) {
+	ctx := context.Background()
+	service := app.NewAccountService(store.NewInMemoryEventStore(), store.NewInMemorySnapshotStore())
+	id := "acc-expected-1"
+	_, _ = service.CreateAccount(app.CreateAccountCommand{AccountID: id, InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: dec("100")}})
+	_, _ = service.CreateAccount(app.CreateAccountCommand{AccountID: "acc-expected-2"})
+
+	t.Run("MatchingVersionSucceeds", func(t *testing.T) {
+		err := service.DepositContext(ctx, app.DepositMoneyCommand{AccountID: id, Amount: dec("10"), Currency: shared.USD, ExpectedVersion: 1})
+		if err != nil {
+			t.Fatalf("Deposit at the expected version failed: %v", err)
+		}
+		result, err := service.GetBalances(ctx, app.GetBalanceQuery{AccountID: id})
+		if err != nil {
+			t.Fatalf("GetBalances failed: %v", err)
+		}
+		if result.Version != 2 || !result.Balances[shared.USD].Equal(dec("110")) {
+			t.Errorf("Expected version 2 with 110 USD, got version %d with %v", result.Version, result.Balances)
+		}
+	})
+
+	t.Run("StaleVersionIsRejectedWithoutRetry", func(t *testing.T) {
+		commands := map[string]func() error{
+			"deposit": func() error {
+				return service.DepositContext(ctx, app.DepositMoneyCommand{AccountID: id, Amount: dec("1"), Currency: shared.USD, ExpectedVersion: 1})
+			},
+			"withdraw": func() error {
+				return service.WithdrawContext(ctx, app.WithdrawMoneyCommand{AccountID: id, Amount: dec("1"), Currency: shared.USD, ExpectedVersion: 1})
+			},
+			"convert": func() error {
+				return service.ConvertCurrencyContext(ctx, app.ConvertCurrencyCommand{AccountID: id, FromAmount: dec("1"), FromCurrency: shared.USD, ToCurrency: shared.EUR, ExpectedVersion: 1})
+			},
+			"transfer": func() error {
+				return service.TransferMoneyContext(ctx, app.TransferMoneyCommand{SourceAccountID: id, TargetAccountID: "acc-expected-2", Amount: dec("1"), Currency: shared.USD, ExpectedVersion: 1})
+			},
+		}
+		for name, run := range commands {
+			err := run()
+			if !errors.Is(err, app.ErrVersionMismatch) {
+				t.Errorf("%s: expected ErrVersionMismatch, got %v", name, err)
+			}
+			var exhausted *app.RetryExhaustedError
+			if errors.As(err, &exhausted) {
+				t.Errorf("%s: version mismatch should not be retried", name)
+			}
+		}
+		balance, _ := service.GetCurrentBalance(app.GetBalanceQuery{AccountID: id})
+		if !balance[shared.USD].Equal(dec("110")) {
+			t.Errorf("Rejected commands changed the balance: %v", balance)
+		}
+	})
+}
@@ -0,0 +1,229 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+
+	"financial-ledger/domain"
+	"financial-ledger/events"
+	"financial-ledger/screening"
+	"financial-ledger/store"
+)
+
+var (
+	ErrScreeningHit      = errors.New("sanctions screening hit")
+	ErrScreeningDisabled = errors.New("sanctions screening is not configured")
+)
+
+// ScreeningHitError is returned when an operation was refused because its
+// subject matched the sanctions list.
+type ScreeningHitError struct {
+	Decision store.ScreeningDecision
+}
+
+func (e *ScreeningHitError) Error() string {
+	return fmt.Sprintf("%s: %s blocked (decision %s)", ErrScreeningHit, describeHit(e.Decision), e.Decision.ID)
+}
+
+func (e *ScreeningHitError) Unwrap() error {
+	return ErrScreeningHit
+}
+
+// describeHit summarises a decision's best match by the list entry alone, so
+// that the screened subject's details stay out of logs, notes and errors.
+func describeHit(d store.ScreeningDecision) string {
+	best := d.Matches[0]
+	return fmt.Sprintf("matches list entry %s %q (score %.2f) on sanctions list version %d", best.EntryUID, best.EntryName, best.Score, d.ListVersion)
+}
+
+// ScreeningAction is what happens to an operation whose subject matches the
+// sanctions list.
+type ScreeningAction string
+
+const (
+	// ScreeningBlock refuses the operation with a *ScreeningHitError.
+	ScreeningBlock ScreeningAction = "block"
+	// ScreeningHold holds the operation for approval, as with large
+	// transfers, and returns an *ApprovalRequiredError.
+	ScreeningHold ScreeningAction = "hold"
+)
+
+// WithScreening screens every new customer, the holder of every new account,
+// and the holders of the target account of every transfer against sc's
+// sanctions list. A hit is blocked or held according to action.
+func WithScreening(sc *screening.Screener, action ScreeningAction) ServiceOption {
+	return func(s *AccountService) {
+		s.screener = sc
+		s.screeningAction = action
+	}
+}
+
+// WithScreeningStore replaces the default in-memory store of screening
+// decisions.
+func WithScreeningStore(ss store.ScreeningStore) ServiceOption {
+	return func(s *AccountService) {
+		if ss != nil {
+			s.screenings = ss
+		}
+	}
+}
+
+// Screener returns the sanctions screener, or nil if screening is off.
+func (s *AccountService) Screener() *screening.Screener {
+	return s.screener
+}
+
+// screen checks subject against the sanctions list before the operation
+// described by about, which names the operation and the account or customer
+// it concerns, and records the decision with the subject sealed under
+// subjectID's key. A clear subject returns nil. On a hit, a blocking policy
+// returns a *ScreeningHitError; a holding policy calls hold with a note on the
+// hit and returns an *ApprovalRequiredError for the approval it opens.
+//
+// A command with an idempotency key gets a decision ID derived from the key,
+// so a retry that is screened again, such as one still held for approval,
+// records no second decision.
+func (s *AccountService) screen(ctx context.Context, about store.ScreeningDecision, subjectID string, subject screening.Subject, idempotencyKey string, meta events.Metadata, hold func(note string) (domain.Approval, error)) error {
+	if s.screener == nil || (subject.Name == "" && len(subject.Identifiers) == 0) {
+		return nil
+	}
+	sealed, err := s.sealDetails(ctx, subjectID, subject)
+	if err != nil {
+		return err
+	}
+	decision := s.screener.Screen(subject)
+	decision.ID = uuid.NewString()
+	if idempotencyKey != "" {
+		decision.ID = uuid.NewSHA1(idempotencyNamespace, []byte("screening\x00"+about.Operation+"\x00"+idempotencyKey+"\x00"+subjectID)).String()
+	}
+	decision.Subject = &sealed
+	decision.Operation = about.Operation
+	decision.AccountID = about.AccountID
+	decision.CustomerID = about.CustomerID
+	decision.Actor = meta.Actor
+	target := "account " + about.AccountID
+	if about.CustomerID != "" {
+		target = "customer " + about.CustomerID
+	}
+
+	var result error
+	if len(decision.Matches) > 0 {
+		note := "sanctions screening: " + describeHit(decision)
+		if s.screeningAction == ScreeningHold {
+			approval, err := hold(note)
+			if err != nil {
+				return err
+			}
+			decision.Outcome = store.ScreeningHeld
+			decision.ApprovalID = approval.ID
+			result = &ApprovalRequiredError{Approval: approval}
+		} else {
+			decision.Outcome = store.ScreeningBlocked
+			result = &ScreeningHitError{Decision: decision}
+		}
+		log.Printf("Warning: %s for %s %s: %s", decision.Operation, target, decision.Outcome, note)
+	}
+
+	err = s.screenings.AddScreening(context.WithoutCancel(ctx), decision)
+	if err != nil && !errors.Is(err, store.ErrScreeningExists) {
+		return fmt.Errorf("failed to record screening of %s for %s: %w", decision.Operation, target, err)
+	}
+	return result
+}
+
+// screenTransferTarget screens the holder named in the personal data of cmd's
+// target account, and each customer holding it. Details whose subject has been
+// forgotten have nobody to screen; a missing target is left for the transfer
+// to report.
+func (s *AccountService) screenTransferTarget(ctx context.Context, cmd TransferMoneyCommand, summary string) error {
+	if s.screener == nil {
+		return nil
+	}
+	target, err := s.loadAccount(ctx, cmd.TargetAccountID)
+	if errors.Is(err, domain.ErrAccountNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load account %s for screening: %w", cmd.TargetAccountID, err)
+	}
+	type screened struct {
+		subjectID string
+		subject   screening.Subject
+	}
+	var subjects []screened
+	if target.PersonalData != nil {
+		details, redacted, err := s.RevealPersonalData(ctx, *target.PersonalData)
+		if err != nil {
+			return err
+		}
+		if !redacted {
+			subjects = append(subjects, screened{target.PersonalData.SubjectID, screening.Subject{Name: details.OwnerName, Identifiers: details.Identifiers}})
+		}
+	}
+	for _, id := range target.Holders {
+		customer, err := s.loadCustomer(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to load holder %s of account %s for screening: %w", id, cmd.TargetAccountID, err)
+		}
+		details, redacted, err := s.revealCustomerDetails(ctx, customer.Details)
+		if err != nil {
+			return err
+		}
+		if !redacted {
+			subjects = append(subjects, screened{id, screening.Subject{Name: details.Name, Identifiers: details.Identifiers}})
+		}
+	}
+
+	about := store.ScreeningDecision{Operation: "transfer", AccountID: cmd.TargetAccountID}
+	for _, sub := range subjects {
+		err := s.screen(ctx, about, sub.subjectID, sub.subject, cmd.IdempotencyKey, cmd.Metadata, func(note string) (domain.Approval, error) {
+			return s.requestApproval(ctx, domain.ApprovalKindTransfer, "", summary+" ("+note+")", cmd.IdempotencyKey, cmd.Metadata, cmd, cmd.SourceAccountID, cmd.TargetAccountID)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListScreenings returns the screening decisions about accountID, or every
+// decision if it is empty, oldest first.
+func (s *AccountService) ListScreenings(ctx context.Context, accountID string) ([]store.ScreeningDecision, error) {
+	return s.screenings.ListScreenings(ctx, accountID)
+}
+
+// ScreenName screens subject without any operation, for a manual check. The
+// decision is recorded like any other, but without the subject, who is no
+// data subject of the ledger and so could never be forgotten.
+func (s *AccountService) ScreenName(ctx context.Context, subject screening.Subject, meta events.Metadata) (store.ScreeningDecision, error) {
+	if s.screener == nil {
+		return store.ScreeningDecision{}, ErrScreeningDisabled
+	}
+	decision := s.screener.Screen(subject)
+	decision.ID = uuid.NewString()
+	decision.Operation = "manual check"
+	decision.Actor = meta.Actor
+	if len(decision.Matches) > 0 {
+		decision.Outcome = store.ScreeningFlagged
+	}
+	if err := s.screenings.AddScreening(context.WithoutCancel(ctx), decision); err != nil {
+		return decision, fmt.Errorf("failed to record screening: %w", err)
+	}
+	return decision, nil
+}
+
+// ReloadSanctionsList reads the sanctions list file again; see
+// screening.Screener.Reload.
+func (s *AccountService) ReloadSanctionsList(ctx context.Context) (screening.Version, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return screening.Version{}, false, err
+	}
+	if s.screener == nil {
+		return screening.Version{}, false, ErrScreeningDisabled
+	}
+	return s.screener.Reload()
+}
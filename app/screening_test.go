@@ -0,0 +1,223 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/app"
+	"financial-ledger/domain"
+	"financial-ledger/events"
+	"financial-ledger/screening"
+	"financial-ledger/shared"
+	"financial-ledger/store"
+)
+
+func TestAccountService_Screening(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "sdn.csv")
+	list := `2674,"HERNANDEZ PEREZ, Jose Antonio","individual","SDNTK",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,"Passport A1234567 (Mexico)."` + "\n"
+	if err := os.WriteFile(path, []byte(list), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	screener, err := screening.NewScreener(path)
+	if err != nil {
+		t.Fatalf("NewScreener failed: %v", err)
+	}
+	alice := events.Metadata{Actor: "alice"}
+	newService := func(t *testing.T, action app.ScreeningAction) *app.AccountService {
+		t.Helper()
+		service := app.NewAccountService(store.NewInMemoryEventStore(), store.NewInMemorySnapshotStore(), app.WithScreening(screener, action))
+		if _, err := service.CreateAccount(app.CreateAccountCommand{AccountID: "sc-src", InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: dec("1000")}}); err != nil {
+			t.Fatalf("CreateAccount failed: %v", err)
+		}
+		return service
+	}
+	sanctioned := &app.PersonalDetails{OwnerName: "José Antonio Hernández Pérez"}
+
+	t.Run("BlockedAccountCreation", func(t *testing.T) {
+		service := newService(t, app.ScreeningBlock)
+		_, err := service.CreateAccount(app.CreateAccountCommand{AccountID: "sc-1", Details: sanctioned, Metadata: alice})
+		var hit *app.ScreeningHitError
+		if !errors.As(err, &hit) || !errors.Is(err, app.ErrScreeningHit) {
+			t.Fatalf("expected a ScreeningHitError, got %v", err)
+		}
+		if strings.Contains(err.Error(), sanctioned.OwnerName) {
+			t.Errorf("error must not carry the holder's name: %v", err)
+		}
+		if _, err := service.GetCurrentBalance(app.GetBalanceQuery{AccountID: "sc-1"}); !errors.Is(err, domain.ErrAccountNotFound) {
+			t.Errorf("expected no account, got %v", err)
+		}
+		decisions, _ := service.ListScreenings(ctx, "sc-1")
+		if len(decisions) != 1 || decisions[0].Outcome != store.ScreeningBlocked || decisions[0].ListVersion != 1 || decisions[0].Actor != "alice" {
+			t.Errorf("expected a blocked decision against list version 1, got %+v", decisions)
+		}
+		if len(decisions) == 1 && (decisions[0].Subject == nil || decisions[0].Subject.SubjectID != "sc-1" || strings.Contains(string(decisions[0].Subject.Data), "Hern")) {
+			t.Errorf("expected the screened subject sealed under the account, got %+v", decisions[0].Subject)
+		}
+	})
+
+	t.Run("ClearAccountCreation", func(t *testing.T) {
+		service := newService(t, app.ScreeningBlock)
+		if _, err := service.CreateAccount(app.CreateAccountCommand{AccountID: "sc-2", Details: &app.PersonalDetails{OwnerName: "Jane Smith"}}); err != nil {
+			t.Fatalf("CreateAccount failed: %v", err)
+		}
+		decisions, _ := service.ListScreenings(ctx, "sc-2")
+		if len(decisions) != 1 || decisions[0].Outcome != store.ScreeningClear || decisions[0].Operation != "account creation" {
+			t.Errorf("expected a clear decision, got %+v", decisions)
+		}
+	})
+
+	t.Run("HeldAccountCreation", func(t *testing.T) {
+		service := newService(t, app.ScreeningHold)
+		_, err := service.CreateAccount(app.CreateAccountCommand{AccountID: "sc-3", Details: sanctioned, Metadata: alice})
+		var held *app.ApprovalRequiredError
+		if !errors.As(err, &held) || held.Approval.Kind != domain.ApprovalKindCreateAccount {
+			t.Fatalf("expected the creation to be held, got %v", err)
+		}
+		if !strings.Contains(held.Approval.Summary, "sanctions screening") {
+			t.Errorf("expected the summary to explain the hold, got %q", held.Approval.Summary)
+		}
+		if strings.Contains(string(held.Approval.Command), "Hern") {
+			t.Errorf("held command must not keep the holder's details in the clear: %s", held.Approval.Command)
+		}
+		decisions, _ := service.ListScreenings(ctx, "sc-3")
+		if len(decisions) != 1 || decisions[0].Outcome != store.ScreeningHeld || decisions[0].ApprovalID != held.Approval.ID {
+			t.Errorf("expected a held decision naming the approval, got %+v", decisions)
+		}
+
+		if _, err := service.Approve(ctx, app.ApproveCommand{ApprovalID: held.Approval.ID, Metadata: events.Metadata{Actor: "bob"}}); err != nil {
+			t.Fatalf("Approve failed: %v", err)
+		}
+		details, err := service.GetAccountDetails(ctx, app.GetAccountDetailsQuery{AccountID: "sc-3"})
+		if err != nil || details.OwnerName != sanctioned.OwnerName {
+			t.Errorf("expected the approved account with its holder, got %+v, %v", details, err)
+		}
+	})
+
+	t.Run("KeyedRetry", func(t *testing.T) {
+		service := newService(t, app.ScreeningHold)
+		cmd := app.CreateAccountCommand{Details: sanctioned, IdempotencyKey: "sc-retry", Metadata: alice}
+		var first, second *app.ApprovalRequiredError
+		if _, err := service.CreateAccount(cmd); !errors.As(err, &first) {
+			t.Fatalf("expected the creation to be held, got %v", err)
+		}
+		if _, err := service.CreateAccount(cmd); !errors.As(err, &second) || second.Approval.ID != first.Approval.ID {
+			t.Fatalf("expected the retry to return the same approval, got %v", err)
+		}
+		if _, err := service.Approve(ctx, app.ApproveCommand{ApprovalID: first.Approval.ID, Metadata: events.Metadata{Actor: "bob"}}); err != nil {
+			t.Fatalf("Approve failed: %v", err)
+		}
+		// Once the creation has run, a retry replays it without screening again.
+		accountID, err := service.CreateAccount(cmd)
+		if err != nil || accountID != first.Approval.AccountIDs[0] {
+			t.Fatalf("expected the retry to replay the approved creation, got %q, %v", accountID, err)
+		}
+		decisions, _ := service.ListScreenings(ctx, "")
+		if len(decisions) != 1 {
+			t.Errorf("expected retries to record no further decisions, got %+v", decisions)
+		}
+	})
+
+	t.Run("TransferToSanctionedHolder", func(t *testing.T) {
+		service := newService(t, app.ScreeningBlock)
+		// The holder is cleared at creation under another name, then changes it.
+		if _, err := service.CreateAccount(app.CreateAccountCommand{AccountID: "sc-4", Details: &app.PersonalDetails{OwnerName: "J. Smith"}}); err != nil {
+			t.Fatalf("CreateAccount failed: %v", err)
+		}
+		err := service.UpdateAccountDetails(ctx, app.UpdateAccountDetailsCommand{AccountID: "sc-4", Details: app.PersonalDetails{OwnerName: "Someone", Identifiers: []string{"A1234567"}}})
+		if err != nil {
+			t.Fatalf("UpdateAccountDetails failed: %v", err)
+		}
+		err = service.TransferMoney(app.TransferMoneyCommand{SourceAccountID: "sc-src", TargetAccountID: "sc-4", Amount: dec("10"), Currency: shared.USD})
+		if !errors.Is(err, app.ErrScreeningHit) {
+			t.Fatalf("expected the transfer to be blocked, got %v", err)
+		}
+		balances, _ := service.GetCurrentBalance(app.GetBalanceQuery{AccountID: "sc-src"})
+		if !balances[shared.USD].Equal(dec("1000")) {
+			t.Errorf("blocked transfer moved money: %s", balances[shared.USD])
+		}
+		decisions, _ := service.ListScreenings(ctx, "sc-4")
+		if len(decisions) != 2 || decisions[1].Operation != "transfer" || decisions[1].Matches[0].Matched != "Passport A1234567" {
+			t.Errorf("expected a blocked transfer decision, got %+v", decisions)
+		}
+
+		// Accounts without holder details have nobody to screen.
+		if err := service.TransferMoney(app.TransferMoneyCommand{SourceAccountID: "sc-4", TargetAccountID: "sc-src", Amount: dec("0.01"), Currency: shared.USD}); !errors.Is(err, domain.ErrInsufficientFunds) {
+			t.Errorf("expected the transfer to reach the domain, got %v", err)
+		}
+	})
+
+	t.Run("CustomerRegistration", func(t *testing.T) {
+		service := newService(t, app.ScreeningBlock)
+		_, err := service.CreateCustomer(ctx, app.CreateCustomerCommand{CustomerID: "sc-c1", Details: app.CustomerDetails{Name: sanctioned.OwnerName}, Metadata: alice})
+		if !errors.Is(err, app.ErrScreeningHit) {
+			t.Fatalf("expected the registration to be blocked, got %v", err)
+		}
+		if _, err := service.GetCustomer(ctx, "sc-c1"); !errors.Is(err, domain.ErrCustomerNotFound) {
+			t.Errorf("expected no customer, got %v", err)
+		}
+
+		service = newService(t, app.ScreeningHold)
+		_, err = service.CreateCustomer(ctx, app.CreateCustomerCommand{CustomerID: "sc-c1", Details: app.CustomerDetails{Name: sanctioned.OwnerName}, KYCTier: domain.KYCTierEnhanced, Metadata: alice})
+		var held *app.ApprovalRequiredError
+		if !errors.As(err, &held) || held.Approval.Kind != domain.ApprovalKindCreateCustomer || strings.Contains(string(held.Approval.Command), "Hern") {
+			t.Fatalf("expected the registration to be held without its details in the clear, got %v", err)
+		}
+		if _, err := service.Approve(ctx, app.ApproveCommand{ApprovalID: held.Approval.ID, Metadata: events.Metadata{Actor: "bob"}}); err != nil {
+			t.Fatalf("Approve failed: %v", err)
+		}
+		profile, err := service.GetCustomer(ctx, "sc-c1")
+		if err != nil || profile.Name != sanctioned.OwnerName || profile.KYCTier != domain.KYCTierEnhanced {
+			t.Errorf("expected the approved customer, got %+v, %v", profile, err)
+		}
+		decisions, _ := service.ListScreenings(ctx, "")
+		if len(decisions) != 1 || decisions[0].CustomerID != "sc-c1" || decisions[0].Outcome != store.ScreeningHeld {
+			t.Errorf("expected a held decision about the customer, got %+v", decisions)
+		}
+	})
+
+	t.Run("TransferToSanctionedCustomer", func(t *testing.T) {
+		service := newService(t, app.ScreeningBlock)
+		for _, id := range []string{"sc-c2", "sc-c3"} {
+			if _, err := service.CreateCustomer(ctx, app.CreateCustomerCommand{CustomerID: id, Details: app.CustomerDetails{Name: "Jane Smith"}}); err != nil {
+				t.Fatalf("CreateCustomer failed: %v", err)
+			}
+		}
+		if _, err := service.CreateAccount(app.CreateAccountCommand{AccountID: "sc-joint", Holders: []string{"sc-c2", "sc-c3"}}); err != nil {
+			t.Fatalf("CreateAccount failed: %v", err)
+		}
+		if err := service.TransferMoney(app.TransferMoneyCommand{SourceAccountID: "sc-src", TargetAccountID: "sc-joint", Amount: dec("10"), Currency: shared.USD}); err != nil {
+			t.Fatalf("expected a transfer to clear holders to pass, got %v", err)
+		}
+
+		// One joint holder later turns out to be listed.
+		err := service.UpdateCustomer(ctx, app.UpdateCustomerCommand{CustomerID: "sc-c3", Details: &app.CustomerDetails{Name: "Jane Smith", Identifiers: []string{"A1234567"}}})
+		if err != nil {
+			t.Fatalf("UpdateCustomer failed: %v", err)
+		}
+		err = service.TransferMoney(app.TransferMoneyCommand{SourceAccountID: "sc-src", TargetAccountID: "sc-joint", Amount: dec("10"), Currency: shared.USD})
+		if !errors.Is(err, app.ErrScreeningHit) {
+			t.Fatalf("expected the transfer to be blocked, got %v", err)
+		}
+		decisions, _ := service.ListScreenings(ctx, "sc-joint")
+		if len(decisions) != 4 || decisions[2].Outcome != store.ScreeningClear || decisions[3].Outcome != store.ScreeningBlocked {
+			t.Errorf("expected each holder screened on each transfer, got %+v", decisions)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		service := app.NewAccountService(store.NewInMemoryEventStore(), store.NewInMemorySnapshotStore())
+		if _, err := service.CreateAccount(app.CreateAccountCommand{AccountID: "sc-5", Details: sanctioned}); err != nil {
+			t.Errorf("expected no screening without a screener, got %v", err)
+		}
+		if _, err := service.ScreenName(ctx, screening.Subject{Name: "x"}, alice); !errors.Is(err, app.ErrScreeningDisabled) {
+			t.Errorf("expected ErrScreeningDisabled, got %v", err)
+		}
+	})
+}
@@ -1,39 +1,105 @@
 package app
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 
 	"financial-ledger/domain"
 	"financial-ledger/events"
+	"financial-ledger/monitoring"
+	"financial-ledger/projection"
+	"financial-ledger/screening"
 	"financial-ledger/shared"
 	"financial-ledger/store"
+	"financial-ledger/webhook"
 )
 
 const (
 	SnapshotFrequency = 100
+
+	// replayBatchSize is how many events loadAccount applies between checks for
+	// context cancellation.
+	replayBatchSize = 256
 )
 
 // AccountService acts as the application layer, orchestrating the interaction
 // between incoming commands/queries, the domain aggregates (Account), and the
 // persistence layers (EventStore, SnapshotStore).
 type AccountService struct {
-	eventStore    store.EventStore
-	snapshotStore store.SnapshotStore
+	eventStore       store.EventStore
+	snapshotStore    store.SnapshotStore
+	idempotencyStore store.IdempotencyStore
+	retryPolicy      RetryPolicy
+	checkpointStore  store.CheckpointStore
+	checkpointSigner Signer
+	verificationKeys *store.Keyring
+	subjectKeys      store.SubjectKeyStore
+	directory        *accountDirectory
+	dormancyPeriod   time.Duration
+	tailPollInterval time.Duration
+	webhooks         *webhook.Dispatcher
+	approvals        *approvalDirectory
+	approvalPolicy   ApprovalPolicy
+	limitPolicy      LimitPolicy
+	monitor          *monitoring.Monitor
+	screener         *screening.Screener
+	screeningAction  ScreeningAction
+	screenings       store.ScreeningStore
+	customers        *customerDirectory
+
+	projections           *projection.Runner
+	projectionCheckpoints store.ProjectionCheckpointStore
+	balances              *projection.BalanceProjection
+
+	keyLocks keyedMutex
+	keyScan  keyScan
 }
 
-func NewAccountService(es store.EventStore, ss store.SnapshotStore) *AccountService {
+// ServiceOption customises an AccountService at construction time.
+type ServiceOption func(*AccountService)
+
+// WithIdempotencyStore replaces the default in-memory idempotency key store.
+func WithIdempotencyStore(is store.IdempotencyStore) ServiceOption {
+	return func(s *AccountService) {
+		if is != nil {
+			s.idempotencyStore = is
+		}
+	}
+}
+
+func NewAccountService(es store.EventStore, ss store.SnapshotStore, opts ...ServiceOption) *AccountService {
 	if es == nil || ss == nil {
 		log.Fatal("FATAL: EventStore and SnapshotStore must not be nil")
 	}
-	return &AccountService{
-		eventStore:    es,
-		snapshotStore: ss,
-	}
+	s := &AccountService{
+		eventStore:       es,
+		snapshotStore:    ss,
+		idempotencyStore: store.NewInMemoryIdempotencyStore(),
+		checkpointStore:  store.NewInMemoryCheckpointStore(),
+		subjectKeys:      store.NewInMemorySubjectKeyStore(),
+		directory:        newAccountDirectory(),
+		dormancyPeriod:   DefaultDormancyPeriod,
+		tailPollInterval: DefaultTailPollInterval,
+		webhooks:         webhook.NewDispatcher(store.NewInMemoryWebhookStore()),
+		approvals:        newApprovalDirectory(),
+		approvalPolicy:   ApprovalPolicy{}.withDefaults(),
+		monitor:          monitoring.NewMonitor(store.NewInMemoryAlertStore(), monitoring.Rules{}),
+		screenings:       store.NewInMemoryScreeningStore(),
+		customers:        newCustomerDirectory(),
+
+		projectionCheckpoints: store.NewInMemoryProjectionCheckpointStore(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.initProjections()
+	return s
 }
 
 // --- Command Handlers ---
@@ -43,14 +109,84 @@ func NewAccountService(es store.EventStore, ss store.SnapshotStore) *AccountServ
 // 3. Persisting the resulting events (using EventStore).
 // 4. Optionally saving a snapshot (using SnapshotStore).
 
+// CreateAccount is equivalent to CreateAccountContext with context.Background().
 func (s *AccountService) CreateAccount(cmd CreateAccountCommand) (string, error) {
+	return s.CreateAccountContext(context.Background(), cmd)
+}
+
+// CreateAccountContext opens an account. Its holders must be registered
+// customers. When sanctions screening is on, the holder named in cmd.Details
+// is screened first; see WithScreening.
+func (s *AccountService) CreateAccountContext(ctx context.Context, cmd CreateAccountCommand) (string, error) {
+	if cmd.AccountID == "" {
+		if cmd.IdempotencyKey != "" {
+			cmd.AccountID = idForKey(cmd.IdempotencyKey)
+		} else {
+			cmd.AccountID = uuid.NewString()
+		}
+		log.Printf("No AccountID provided, generated new ID: %s", cmd.AccountID)
+	}
+	done, err := s.alreadyProcessed(ctx, cmd.IdempotencyKey, cmd, cmd.AccountID)
+	if err != nil {
+		return "", err
+	}
+	if done {
+		return s.createAccount(ctx, cmd, nil)
+	}
+	if err := s.checkHolders(ctx, cmd.Holders); err != nil {
+		return "", err
+	}
+	if cmd.Details != nil {
+		subject := screening.Subject{Name: cmd.Details.OwnerName, Identifiers: cmd.Details.Identifiers}
+		about := store.ScreeningDecision{Operation: "account creation", AccountID: cmd.AccountID}
+		err := s.screen(ctx, about, cmd.subject(), subject, cmd.IdempotencyKey, cmd.Metadata, func(note string) (domain.Approval, error) {
+			sealed, err := s.sealDetails(ctx, cmd.subject(), *cmd.Details)
+			if err != nil {
+				return domain.Approval{}, err
+			}
+			held := heldAccountCreation{Command: cmd, Details: &sealed}
+			held.Command.Details = nil
+			return s.requestApproval(ctx, domain.ApprovalKindCreateAccount, cmd.AccountID, "creation of account "+cmd.AccountID+" ("+note+")", cmd.IdempotencyKey, cmd.Metadata, held, cmd.AccountID)
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return s.createAccount(ctx, cmd, nil)
+}
+
+// heldAccountCreation is an account creation held for approval. The holder's
+// details are sealed under the data subject's key rather than kept in the
+// approval in the clear, so forgetting the subject still erases them.
+type heldAccountCreation struct {
+	Command CreateAccountCommand
+	Details *events.SealedPersonalData
+}
+
+// subject is the data subject the command's details belong to.
+func (cmd CreateAccountCommand) subject() string {
+	if cmd.SubjectID != "" {
+		return cmd.SubjectID
+	}
+	return cmd.AccountID
+}
+
+// createAccount opens the account, with details if they were sealed already
+// and otherwise with cmd.Details.
+func (s *AccountService) createAccount(ctx context.Context, cmd CreateAccountCommand, details *events.SealedPersonalData) (string, error) {
 	accountID := cmd.AccountID
-	if accountID == "" {
-		accountID = uuid.NewString()
-		log.Printf("No AccountID provided, generated new ID: %s", accountID)
+	idem, replay, err := s.beginIdempotent(ctx, cmd.IdempotencyKey, cmd, accountID)
+	if err != nil {
+		return "", err
+	}
+	if replay != nil {
+		return replay.AggregateID, nil
 	}
+	defer idem.done()
 
-	existingAccount, err := s.loadAccount(accountID)
+	meta := commandMetadata(cmd.Metadata)
+
+	existingAccount, err := s.loadAccount(ctx, accountID)
 	if err != nil && !errors.Is(err, domain.ErrAccountNotFound) {
 		return "", fmt.Errorf("failed to check for existing account %s: %w", accountID, err)
 	}
@@ -59,9 +195,17 @@ func (s *AccountService) CreateAccount(cmd CreateAccountCommand) (string, error)
 		return "", fmt.Errorf("%w: %s", domain.ErrAccountExists, accountID)
 	}
 
+	if details == nil && cmd.Details != nil {
+		sealed, err := s.sealDetails(ctx, cmd.subject(), *cmd.Details)
+		if err != nil {
+			return "", err
+		}
+		details = &sealed
+	}
+
 	account := domain.NewAccount(accountID)
 
-	err = account.HandleCreateAccount(accountID, cmd.InitialBalances)
+	err = account.HandleCreateAccountWithHolders(accountID, cmd.InitialBalances, details, cmd.Holders)
 	if err != nil {
 		return "", fmt.Errorf("account creation failed validation: %w", err)
 	}
@@ -72,136 +216,262 @@ func (s *AccountService) CreateAccount(cmd CreateAccountCommand) (string, error)
 		return "", errors.New("internal error: create account produced no events")
 	}
 
-	err = s.eventStore.SaveEvents(accountID, 0, changes)
+	err = s.eventStore.SaveEventsContext(ctx, accountID, 0, stampEvents(changes, meta, idem))
 	if err != nil {
 		return "", fmt.Errorf("failed to save creation events for account %s: %w", accountID, err)
 	}
+	s.completeIdempotent(ctx, idem, accountID)
 
 	log.Printf("Account %s created successfully. Version: %d", accountID, account.Version)
 
-	s.saveSnapshotIfNeeded(account)
+	s.saveSnapshotIfNeeded(ctx, account)
 
 	return accountID, nil
 }
 
+// Deposit is equivalent to DepositContext with context.Background().
 func (s *AccountService) Deposit(cmd DepositMoneyCommand) error {
-	account, err := s.loadAccount(cmd.AccountID)
-	if err != nil {
-		return fmt.Errorf("failed to load account %s for deposit: %w", cmd.AccountID, err)
-	}
-
-	initialVersion := account.Version
+	return s.DepositContext(context.Background(), cmd)
+}
 
-	err = account.HandleDeposit(cmd.Amount, cmd.Currency)
+func (s *AccountService) DepositContext(ctx context.Context, cmd DepositMoneyCommand) error {
+	idem, replay, err := s.beginIdempotent(ctx, cmd.IdempotencyKey, cmd, cmd.AccountID)
 	if err != nil {
-		return fmt.Errorf("deposit command failed for account %s: %w", cmd.AccountID, err)
+		return err
 	}
-
-	changes := account.GetUncommitedChanges()
-	if len(changes) == 0 {
-		log.Printf("Deposit command for %s resulted in no state change (no events generated).", cmd.AccountID)
+	if replay != nil {
 		return nil
 	}
+	defer idem.done()
 
-	err = s.eventStore.SaveEvents(cmd.AccountID, initialVersion, changes)
-	if err != nil {
-		return fmt.Errorf("failed to save deposit events for account %s: %w", cmd.AccountID, err)
-	}
+	meta := commandMetadata(cmd.Metadata)
+
+	err = s.retryOnConflict(ctx, "deposit", func(attempt int) error {
+		account, err := s.loadAccount(ctx, cmd.AccountID)
+		if err != nil {
+			return fmt.Errorf("failed to load account %s for deposit: %w", cmd.AccountID, err)
+		}
 
-	log.Printf("Deposit of %s %s successful for account %s. New Version: %d", cmd.Amount.String(), cmd.Currency, cmd.AccountID, account.Version)
+		initialVersion := account.Version
+		if err := checkExpectedVersion(account, cmd.ExpectedVersion); err != nil {
+			return err
+		}
 
-	s.saveSnapshotIfNeeded(account)
-	return nil
+		err = account.HandleDeposit(cmd.Amount, cmd.Currency)
+		if err != nil {
+			return fmt.Errorf("deposit command failed for account %s: %w", cmd.AccountID, err)
+		}
+
+		changes := account.GetUncommitedChanges()
+		if len(changes) == 0 {
+			log.Printf("Deposit command for %s resulted in no state change (no events generated).", cmd.AccountID)
+			return nil
+		}
+
+		err = s.eventStore.SaveEventsContext(ctx, cmd.AccountID, initialVersion, stampEvents(changes, meta, idem))
+		if err != nil {
+			return fmt.Errorf("failed to save deposit events for account %s: %w", cmd.AccountID, err)
+		}
+		s.completeIdempotent(ctx, idem, cmd.AccountID)
+
+		log.Printf("Deposit of %s %s successful for account %s. New Version: %d", cmd.Amount.String(), cmd.Currency, cmd.AccountID, account.Version)
+
+		s.saveSnapshotIfNeeded(ctx, account)
+		return nil
+	})
+	s.notifyRejected(ctx, "deposit", cmd.AccountID, meta, err)
+	return err
 }
 
+// Withdraw is equivalent to WithdrawContext with context.Background().
 func (s *AccountService) Withdraw(cmd WithdrawMoneyCommand) error {
-	account, err := s.loadAccount(cmd.AccountID)
+	return s.WithdrawContext(context.Background(), cmd)
+}
+
+func (s *AccountService) WithdrawContext(ctx context.Context, cmd WithdrawMoneyCommand) error {
+	idem, replay, err := s.beginIdempotent(ctx, cmd.IdempotencyKey, cmd, cmd.AccountID)
 	if err != nil {
-		return fmt.Errorf("failed to load account %s for withdrawal: %w", cmd.AccountID, err)
+		return err
+	}
+	if replay != nil {
+		return nil
 	}
+	defer idem.done()
 
-	initialVersion := account.Version
+	meta := commandMetadata(cmd.Metadata)
 
-	err = account.HandleWithdraw(cmd.Amount, cmd.Currency)
-	if err != nil {
-		if errors.Is(err, domain.ErrInsufficientFunds) {
-			log.Printf("Withdrawal failed for %s: %v", cmd.AccountID, err)
+	err = s.retryOnConflict(ctx, "withdrawal", func(attempt int) error {
+		account, err := s.loadAccount(ctx, cmd.AccountID)
+		if err != nil {
+			return fmt.Errorf("failed to load account %s for withdrawal: %w", cmd.AccountID, err)
+		}
+
+		initialVersion := account.Version
+		if err := checkExpectedVersion(account, cmd.ExpectedVersion); err != nil {
 			return err
 		}
-		return fmt.Errorf("withdrawal command failed for account %s: %w", cmd.AccountID, err)
-	}
 
-	changes := account.GetUncommitedChanges()
-	if len(changes) == 0 {
-		log.Printf("Withdraw command for %s resulted in no state change.", cmd.AccountID)
-		return nil
-	}
+		if err := s.checkLimits(account, domain.OutflowWithdrawal, cmd.Amount, cmd.Currency); err != nil {
+			return err
+		}
+		err = account.HandleWithdraw(cmd.Amount, cmd.Currency)
+		if err != nil {
+			if errors.Is(err, domain.ErrInsufficientFunds) {
+				log.Printf("Withdrawal failed for %s: %v", cmd.AccountID, err)
+				return err
+			}
+			return fmt.Errorf("withdrawal command failed for account %s: %w", cmd.AccountID, err)
+		}
 
-	err = s.eventStore.SaveEvents(cmd.AccountID, initialVersion, changes)
-	if err != nil {
-		return fmt.Errorf("failed to save withdrawal events for account %s: %w", cmd.AccountID, err)
-	}
+		changes := account.GetUncommitedChanges()
+		if len(changes) == 0 {
+			log.Printf("Withdraw command for %s resulted in no state change.", cmd.AccountID)
+			return nil
+		}
 
-	log.Printf("Withdrawal of %s %s successful for account %s. New Version: %d", cmd.Amount.String(), cmd.Currency, cmd.AccountID, account.Version)
-	s.saveSnapshotIfNeeded(account)
-	return nil
+		err = s.eventStore.SaveEventsContext(ctx, cmd.AccountID, initialVersion, stampEvents(changes, meta, idem))
+		if err != nil {
+			return fmt.Errorf("failed to save withdrawal events for account %s: %w", cmd.AccountID, err)
+		}
+		s.completeIdempotent(ctx, idem, cmd.AccountID)
+
+		log.Printf("Withdrawal of %s %s successful for account %s. New Version: %d", cmd.Amount.String(), cmd.Currency, cmd.AccountID, account.Version)
+		s.saveSnapshotIfNeeded(ctx, account)
+		return nil
+	})
+	s.notifyRejected(ctx, "withdrawal", cmd.AccountID, meta, err)
+	return err
 }
 
+// ConvertCurrency is equivalent to ConvertCurrencyContext with context.Background().
 func (s *AccountService) ConvertCurrency(cmd ConvertCurrencyCommand) error {
-	account, err := s.loadAccount(cmd.AccountID)
+	return s.ConvertCurrencyContext(context.Background(), cmd)
+}
+
+func (s *AccountService) ConvertCurrencyContext(ctx context.Context, cmd ConvertCurrencyCommand) error {
+	idem, replay, err := s.beginIdempotent(ctx, cmd.IdempotencyKey, cmd, cmd.AccountID)
 	if err != nil {
-		return fmt.Errorf("failed to load account %s for currency conversion: %w", cmd.AccountID, err)
+		return err
 	}
+	if replay != nil {
+		return nil
+	}
+	defer idem.done()
 
-	initialVersion := account.Version
+	meta := commandMetadata(cmd.Metadata)
 
-	rate, err := s.getExchangeRate(cmd.FromCurrency, cmd.ToCurrency)
-	if err != nil {
-		return fmt.Errorf("could not get exchange rate for %s -> %s: %w", cmd.FromCurrency, cmd.ToCurrency, err)
-	}
+	err = s.retryOnConflict(ctx, "currency conversion", func(attempt int) error {
+		account, err := s.loadAccount(ctx, cmd.AccountID)
+		if err != nil {
+			return fmt.Errorf("failed to load account %s for currency conversion: %w", cmd.AccountID, err)
+		}
 
-	err = account.HandleConvertCurrency(cmd.FromAmount, cmd.FromCurrency, cmd.ToCurrency, rate)
-	if err != nil {
-		if errors.Is(err, domain.ErrInsufficientFunds) {
-			log.Printf("Currency conversion failed for %s: %v", cmd.AccountID, err)
+		initialVersion := account.Version
+		if err := checkExpectedVersion(account, cmd.ExpectedVersion); err != nil {
 			return err
 		}
-		return fmt.Errorf("currency conversion command failed for account %s: %w", cmd.AccountID, err)
-	}
 
-	changes := account.GetUncommitedChanges()
-	if len(changes) == 0 {
-		log.Printf("ConvertCurrency command for %s resulted in no state change.", cmd.AccountID)
+		rate, err := s.getExchangeRate(cmd.FromCurrency, cmd.ToCurrency)
+		if err != nil {
+			return fmt.Errorf("could not get exchange rate for %s -> %s: %w", cmd.FromCurrency, cmd.ToCurrency, err)
+		}
+
+		err = account.HandleConvertCurrency(cmd.FromAmount, cmd.FromCurrency, cmd.ToCurrency, rate)
+		if err != nil {
+			if errors.Is(err, domain.ErrInsufficientFunds) {
+				log.Printf("Currency conversion failed for %s: %v", cmd.AccountID, err)
+				return err
+			}
+			return fmt.Errorf("currency conversion command failed for account %s: %w", cmd.AccountID, err)
+		}
+
+		changes := account.GetUncommitedChanges()
+		if len(changes) == 0 {
+			log.Printf("ConvertCurrency command for %s resulted in no state change.", cmd.AccountID)
+			return nil
+		}
+
+		err = s.eventStore.SaveEventsContext(ctx, cmd.AccountID, initialVersion, stampEvents(changes, meta, idem))
+		if err != nil {
+			return fmt.Errorf("failed to save conversion events for account %s: %w", cmd.AccountID, err)
+		}
+		s.completeIdempotent(ctx, idem, cmd.AccountID)
+
+		log.Printf("Conversion of %s %s -> %s successful for account %s. Rate: %s. New Version: %d",
+			cmd.FromAmount.String(), cmd.FromCurrency, cmd.ToCurrency, cmd.AccountID, rate.String(), account.Version)
+		s.saveSnapshotIfNeeded(ctx, account)
 		return nil
+	})
+	s.notifyRejected(ctx, "conversion", cmd.AccountID, meta, err)
+	return err
+}
+
+// TransferMoney is equivalent to TransferMoneyContext with context.Background().
+func (s *AccountService) TransferMoney(cmd TransferMoneyCommand) error {
+	return s.TransferMoneyContext(context.Background(), cmd)
+}
+
+// TransferMoneyContext moves money between accounts. A transfer above the
+// approval policy's threshold is not executed; it is held for approval and an
+// *ApprovalRequiredError is returned.
+func (s *AccountService) TransferMoneyContext(ctx context.Context, cmd TransferMoneyCommand) error {
+	done, err := s.alreadyProcessed(ctx, cmd.IdempotencyKey, cmd, cmd.SourceAccountID)
+	if err != nil {
+		return err
+	}
+	if done {
+		return s.transferMoney(ctx, cmd)
+	}
+	summary := fmt.Sprintf("transfer of %s %s from %s to %s", cmd.Amount.String(), cmd.Currency, cmd.SourceAccountID, cmd.TargetAccountID)
+	if err := s.screenTransferTarget(ctx, cmd, summary); err != nil {
+		return err
 	}
+	if s.transferNeedsApproval(cmd) {
+		approval, err := s.requestApproval(ctx, domain.ApprovalKindTransfer, "", summary, cmd.IdempotencyKey, cmd.Metadata, cmd, cmd.SourceAccountID, cmd.TargetAccountID)
+		if err != nil {
+			return err
+		}
+		return &ApprovalRequiredError{Approval: approval}
+	}
+	return s.transferMoney(ctx, cmd)
+}
 
-	err = s.eventStore.SaveEvents(cmd.AccountID, initialVersion, changes)
+func (s *AccountService) transferMoney(ctx context.Context, cmd TransferMoneyCommand) error {
+	idem, replay, err := s.beginIdempotent(ctx, cmd.IdempotencyKey, cmd, cmd.SourceAccountID)
 	if err != nil {
-		return fmt.Errorf("failed to save conversion events for account %s: %w", cmd.AccountID, err)
+		return err
+	}
+	if replay != nil {
+		// The debit was recorded; make sure the credit was too before reporting success.
+		return s.resumeTransfer(ctx, cmd, *replay)
 	}
+	defer idem.done()
 
-	log.Printf("Conversion of %s %s -> %s successful for account %s. Rate: %s. New Version: %d",
-		cmd.FromAmount.String(), cmd.FromCurrency, cmd.ToCurrency, cmd.AccountID, rate.String(), account.Version)
-	s.saveSnapshotIfNeeded(account)
-	return nil
-}
+	meta := commandMetadata(cmd.Metadata)
 
-func (s *AccountService) TransferMoney(cmd TransferMoneyCommand) error {
-	sourceAccount, err := s.loadAccount(cmd.SourceAccountID)
+	sourceAccount, err := s.loadAccount(ctx, cmd.SourceAccountID)
 	if err != nil {
-		return fmt.Errorf("failed to load source account %s for transfer: %w", cmd.SourceAccountID, err)
+		err = fmt.Errorf("failed to load source account %s for transfer: %w", cmd.SourceAccountID, err)
+		s.notifyRejected(ctx, "transfer", cmd.SourceAccountID, meta, err)
+		return err
 	}
-	initialSourceVersion := sourceAccount.Version
 
-	targetAccount, err := s.loadAccount(cmd.TargetAccountID)
+	// Confirm the target exists before debiting anything from the source.
+	targetAccount, err := s.loadAccount(ctx, cmd.TargetAccountID)
 	if err != nil {
 		if errors.Is(err, domain.ErrAccountNotFound) {
 			log.Printf("Transfer failed: Target account %s not found.", cmd.TargetAccountID)
-			return fmt.Errorf("target account %s not found for transfer: %w", cmd.TargetAccountID, err)
+			err = fmt.Errorf("target account %s not found for transfer: %w", cmd.TargetAccountID, err)
+			s.notifyRejected(ctx, "transfer", cmd.SourceAccountID, meta, err)
+			return err
 		}
 		return fmt.Errorf("failed to load target account %s for transfer: %w", cmd.TargetAccountID, err)
 	}
-	initialTargetVersion := targetAccount.Version
+	if targetAccount.Closed {
+		err = fmt.Errorf("%w: cannot transfer to account %s", domain.ErrAccountClosed, cmd.TargetAccountID)
+		s.notifyRejected(ctx, "transfer", cmd.SourceAccountID, meta, err)
+		return err
+	}
 
 	transferID := uuid.NewString()
 
@@ -211,57 +481,192 @@ func (s *AccountService) TransferMoney(cmd TransferMoneyCommand) error {
 	creditCurrency := cmd.Currency // For same-currency transfer
 	rate := decimal.NewFromInt(1)  // For same-currency transfer
 
-	err = sourceAccount.HandleInitiateTransfer(transferID, cmd.TargetAccountID, debitAmount, debitCurrency, creditAmount, creditCurrency, rate)
-	if err != nil {
-		log.Printf("Transfer failed (debit phase) for source %s: %v", cmd.SourceAccountID, err)
-		return fmt.Errorf("transfer command failed for source account %s: %w", cmd.SourceAccountID, err)
-	}
+	var debitEvent events.Event
+	err = s.retryOnConflict(ctx, "transfer debit", func(attempt int) error {
+		var err error
+		if attempt > 1 {
+			sourceAccount, err = s.loadAccount(ctx, cmd.SourceAccountID)
+			if err != nil {
+				return fmt.Errorf("failed to reload source account %s for transfer: %w", cmd.SourceAccountID, err)
+			}
+		}
+		initialSourceVersion := sourceAccount.Version
+		if err := checkExpectedVersion(sourceAccount, cmd.ExpectedVersion); err != nil {
+			return err
+		}
 
-	sourceChanges := sourceAccount.GetUncommitedChanges()
-	if len(sourceChanges) > 0 {
-		err = s.eventStore.SaveEvents(cmd.SourceAccountID, initialSourceVersion, sourceChanges)
+		if err := s.checkLimits(sourceAccount, domain.OutflowTransfer, debitAmount, debitCurrency); err != nil {
+			return err
+		}
+		err = sourceAccount.HandleInitiateTransfer(transferID, cmd.TargetAccountID, debitAmount, debitCurrency, creditAmount, creditCurrency, rate)
 		if err != nil {
-			log.Printf("CRITICAL ERROR: Failed to save transfer debit events for account %s (TransferID: %s): %v. State is inconsistent.", cmd.SourceAccountID, transferID, err)
-			return fmt.Errorf("failed to save transfer debit events for account %s (TransferID: %s): %w. System may be in an inconsistent state", cmd.SourceAccountID, transferID, err)
+			log.Printf("Transfer failed (debit phase) for source %s: %v", cmd.SourceAccountID, err)
+			return fmt.Errorf("transfer command failed for source account %s: %w", cmd.SourceAccountID, err)
+		}
+
+		sourceChanges := sourceAccount.GetUncommitedChanges()
+		if len(sourceChanges) == 0 {
+			log.Printf("Warning: HandleInitiateTransfer for source %s (TransferID: %s) resulted in no state change.", cmd.SourceAccountID, transferID)
+			return nil
 		}
+		debitEvents := stampEvents(sourceChanges, meta, idem)
+		err = s.eventStore.SaveEventsContext(ctx, cmd.SourceAccountID, initialSourceVersion, debitEvents)
+		if err != nil {
+			log.Printf("ERROR: Failed to save transfer debit events for account %s (TransferID: %s): %v. Nothing was debited.", cmd.SourceAccountID, transferID, err)
+			return fmt.Errorf("failed to save transfer debit events for account %s (TransferID: %s): %w", cmd.SourceAccountID, transferID, err)
+		}
+		debitEvent = debitEvents[len(debitEvents)-1]
 		log.Printf("Transfer (Debit) of %s %s from %s to %s successful (TransferID: %s). Source New Version: %d",
 			debitAmount.String(), debitCurrency, cmd.SourceAccountID, cmd.TargetAccountID, transferID, sourceAccount.Version)
-		s.saveSnapshotIfNeeded(sourceAccount)
-	} else {
-		log.Printf("Warning: HandleInitiateTransfer for source %s (TransferID: %s) resulted in no state change.", cmd.SourceAccountID, transferID)
+		s.saveSnapshotIfNeeded(ctx, sourceAccount)
+		return nil
+	})
+	if err != nil {
+		s.notifyRejected(ctx, "transfer", cmd.SourceAccountID, meta, err)
+		return err
 	}
 
-	err = targetAccount.HandleReceiveTransfer(transferID, cmd.SourceAccountID, cmd.TargetAccountID, debitAmount, debitCurrency, creditAmount, creditCurrency, rate)
-	if err != nil {
-		// Should implement a compensating action for source account if this fails.
-		log.Printf("CRITICAL ERROR: Transfer partially failed (TransferID: %s). Source %s debited, but crediting target %s failed: %v. Manual intervention may be required.", transferID, cmd.SourceAccountID, cmd.TargetAccountID, err)
-		return fmt.Errorf("transfer failed during credit to target account %s (TransferID: %s): %w. Source account %s was debited. Manual intervention likely required", cmd.TargetAccountID, transferID, err, cmd.SourceAccountID)
+	// The debit is committed from here on, so only the credit is retried on conflict.
+	creditMeta := meta
+	if debitEvent != nil {
+		creditMeta = causedBy(meta, debitEvent)
 	}
+	credit := events.MoneyTransferredEvent{
+		TransferID:       transferID,
+		SourceAccountID:  cmd.SourceAccountID,
+		TargetAccountID:  cmd.TargetAccountID,
+		DebitedAmount:    debitAmount,
+		DebitedCurrency:  debitCurrency,
+		CreditedAmount:   creditAmount,
+		CreditedCurrency: creditCurrency,
+		ExchangeRate:     rate,
+	}
+	if err := s.creditTransfer(ctx, targetAccount, credit, creditMeta, idem); err != nil {
+		return err
+	}
+
+	s.completeIdempotent(ctx, idem, cmd.SourceAccountID)
+	log.Printf("Transfer (TransferID: %s) from %s to %s completed successfully.", transferID, cmd.SourceAccountID, cmd.TargetAccountID)
+	return nil
+}
 
-	targetChanges := targetAccount.GetUncommitedChanges()
-	if len(targetChanges) > 0 {
-		err = s.eventStore.SaveEvents(cmd.TargetAccountID, initialTargetVersion, targetChanges)
+// creditTransfer records the credit half of a transfer whose debit, described
+// by debit, is committed. targetAccount may be nil, in which case it is loaded.
+func (s *AccountService) creditTransfer(ctx context.Context, targetAccount *domain.Account, debit events.MoneyTransferredEvent, creditMeta events.Metadata, idem *idempotentRequest) error {
+	transferID := debit.TransferID
+	return s.retryOnConflict(ctx, "transfer credit", func(attempt int) error {
+		var err error
+		if attempt > 1 || targetAccount == nil {
+			targetAccount, err = s.loadAccount(ctx, debit.TargetAccountID)
+			if err != nil {
+				return fmt.Errorf("failed to reload target account %s for transfer credit: %w", debit.TargetAccountID, err)
+			}
+		}
+		initialTargetVersion := targetAccount.Version
+
+		err = targetAccount.HandleReceiveTransfer(transferID, debit.SourceAccountID, debit.TargetAccountID, debit.DebitedAmount, debit.DebitedCurrency, debit.CreditedAmount, debit.CreditedCurrency, debit.ExchangeRate)
+		if err != nil {
+			// Should implement a compensating action for source account if this fails.
+			log.Printf("CRITICAL ERROR: Transfer partially failed (TransferID: %s). Source %s debited, but crediting target %s failed: %v. Manual intervention may be required.", transferID, debit.SourceAccountID, debit.TargetAccountID, err)
+			return fmt.Errorf("transfer failed during credit to target account %s (TransferID: %s): %w. Source account %s was debited. Manual intervention likely required", debit.TargetAccountID, transferID, err, debit.SourceAccountID)
+		}
+
+		targetChanges := targetAccount.GetUncommitedChanges()
+		if len(targetChanges) == 0 {
+			log.Printf("Warning: HandleReceiveTransfer for target %s (TransferID: %s) resulted in no state change.", debit.TargetAccountID, transferID)
+			return nil
+		}
+		err = s.eventStore.SaveEventsContext(ctx, debit.TargetAccountID, initialTargetVersion, stampEvents(targetChanges, creditMeta, idem))
 		if err != nil {
 			// Should implement a compensating action for source account.
-			log.Printf("CRITICAL ERROR: Failed to save transfer credit events for target account %s (TransferID: %s): %v. State is inconsistent.", cmd.TargetAccountID, transferID, err)
-			return fmt.Errorf("failed to save transfer credit events for target account %s (TransferID: %s): %w. System may be in an inconsistent state", cmd.TargetAccountID, transferID, err)
+			log.Printf("CRITICAL ERROR: Failed to save transfer credit events for target account %s (TransferID: %s): %v. State is inconsistent.", debit.TargetAccountID, transferID, err)
+			return fmt.Errorf("failed to save transfer credit events for target account %s (TransferID: %s): %w. System may be in an inconsistent state", debit.TargetAccountID, transferID, err)
 		}
 		log.Printf("Transfer (Credit) of %s %s to %s from %s successful (TransferID: %s). Target New Version: %d",
-			creditAmount.String(), creditCurrency, cmd.TargetAccountID, cmd.SourceAccountID, transferID, targetAccount.Version)
-		s.saveSnapshotIfNeeded(targetAccount)
-	} else {
-		log.Printf("Warning: HandleReceiveTransfer for target %s (TransferID: %s) resulted in no state change.", cmd.TargetAccountID, transferID)
+			debit.CreditedAmount.String(), debit.CreditedCurrency, debit.TargetAccountID, debit.SourceAccountID, transferID, targetAccount.Version)
+		s.saveSnapshotIfNeeded(ctx, targetAccount)
+		return nil
+	})
+}
+
+// resumeTransfer handles the retry of a transfer whose key was already used.
+// If the earlier attempt debited the source but failed to credit the target,
+// the credit is completed now from the recorded debit, so the retry finishes
+// the transfer rather than reporting a success that never happened.
+func (s *AccountService) resumeTransfer(ctx context.Context, cmd TransferMoneyCommand, replay store.IdempotencyRecord) error {
+	idem := &idempotentRequest{key: replay.Key, fingerprint: replay.Fingerprint, release: s.keyLocks.lock(replay.Key)}
+	defer idem.done()
+
+	source, err := s.eventStore.GetEventsContext(ctx, cmd.SourceAccountID)
+	if err != nil {
+		return fmt.Errorf("failed to find the debit of replayed transfer (key %q): %w", cmd.IdempotencyKey, err)
+	}
+	var debit events.Event
+	for _, event := range source {
+		if event.GetBase().IdempotencyKey == cmd.IdempotencyKey {
+			debit = event
+			break
+		}
+	}
+	transfer, ok := debit.(events.MoneyTransferredEvent)
+	if !ok {
+		// Nothing was debited, so there is nothing to credit.
+		return nil
+	}
+	credited, err := s.streamHasIdempotencyKey(ctx, transfer.TargetAccountID, cmd.IdempotencyKey)
+	if err != nil {
+		return fmt.Errorf("failed to verify credit for replayed transfer (key %q): %w", cmd.IdempotencyKey, err)
+	}
+	if credited {
+		return nil
 	}
 
-	log.Printf("Transfer (TransferID: %s) from %s to %s completed successfully.", transferID, cmd.SourceAccountID, cmd.TargetAccountID)
-	return nil
+	log.Printf("Warning: Transfer %s with idempotency key %q debited %s but never credited %s; completing the credit.", transfer.TransferID, cmd.IdempotencyKey, transfer.SourceAccountID, transfer.TargetAccountID)
+	return s.creditTransfer(ctx, nil, transfer, causedBy(commandMetadata(transfer.Metadata), debit), idem)
 }
 
 // --- Query Handlers ---
 // These methods retrieve information about accounts without changing state.
 
+// GetCurrentBalance is equivalent to GetCurrentBalanceContext with context.Background().
 func (s *AccountService) GetCurrentBalance(query GetBalanceQuery) (map[shared.Currency]decimal.Decimal, error) {
-	account, err := s.loadAccount(query.AccountID)
+	return s.GetCurrentBalanceContext(context.Background(), query)
+}
+
+func (s *AccountService) GetCurrentBalanceContext(ctx context.Context, query GetBalanceQuery) (map[shared.Currency]decimal.Decimal, error) {
+	result, err := s.GetBalances(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return result.Balances, nil
+}
+
+// AccountBalances is the answer to a balance query together with the account
+// version it reflects, for callers doing optimistic concurrency.
+type AccountBalances struct {
+	AccountID string
+	Version   int
+	Balances  map[shared.Currency]decimal.Decimal
+}
+
+// GetBalances answers a balance query like GetCurrentBalance, and also reports
+// the account version the balances were read at.
+func (s *AccountService) GetBalances(ctx context.Context, query GetBalanceQuery) (*AccountBalances, error) {
+	var account *domain.Account
+	var err error
+	switch {
+	case query.AsOfVersion == 0 && query.AsOf == nil:
+		if balances, version, ok := s.projectedBalances(ctx, query.AccountID); ok {
+			return &AccountBalances{AccountID: query.AccountID, Version: version, Balances: selectBalances(balances, query.Currency)}, nil
+		}
+		account, err = s.loadAccount(ctx, query.AccountID)
+	case query.AsOfVersion != 0 && query.AsOf != nil:
+		return nil, errors.New("cannot get balance: specify an as-of version or an as-of time, not both")
+	case query.AsOfVersion != 0:
+		account, err = s.loadAccountAtVersion(ctx, query.AccountID, query.AsOfVersion)
+	default:
+		account, err = s.loadAccountAtTime(ctx, query.AccountID, *query.AsOf)
+	}
 	if err != nil {
 		if errors.Is(err, domain.ErrAccountNotFound) {
 			return nil, fmt.Errorf("cannot get balance: %w", err)
@@ -269,27 +674,36 @@ func (s *AccountService) GetCurrentBalance(query GetBalanceQuery) (map[shared.Cu
 		return nil, fmt.Errorf("failed to load account %s for balance query: %w", query.AccountID, err)
 	}
 
+	return &AccountBalances{AccountID: account.ID, Version: account.Version, Balances: selectBalances(account.Balances, query.Currency)}, nil
+}
+
+// selectBalances copies balances, keeping only currency if it is set.
+func selectBalances(balances map[shared.Currency]decimal.Decimal, currency *shared.Currency) map[shared.Currency]decimal.Decimal {
 	balancesCopy := make(map[shared.Currency]decimal.Decimal)
 
-	if query.Currency != nil {
-		balance := account.Balances[*query.Currency]
-		balancesCopy[*query.Currency] = balance
+	if currency != nil {
+		balancesCopy[*currency] = balances[*currency]
 	} else {
-		for cur, bal := range account.Balances {
+		for cur, bal := range balances {
 			balancesCopy[cur] = bal
 		}
 	}
-	return balancesCopy, nil
+	return balancesCopy
 }
 
+// GetTransactionHistory is equivalent to GetTransactionHistoryContext with context.Background().
 func (s *AccountService) GetTransactionHistory(query GetHistoryQuery) ([]events.Event, error) {
-	history, err := s.eventStore.GetEvents(query.AccountID)
+	return s.GetTransactionHistoryContext(context.Background(), query)
+}
+
+func (s *AccountService) GetTransactionHistoryContext(ctx context.Context, query GetHistoryQuery) ([]events.Event, error) {
+	history, err := s.eventStore.GetEventsContext(ctx, query.AccountID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get event history for account %s: %w", query.AccountID, err)
 	}
 
 	if len(history) == 0 {
-		_, errLoad := s.loadAccount(query.AccountID)
+		_, errLoad := s.loadAccount(ctx, query.AccountID)
 		if errors.Is(errLoad, domain.ErrAccountNotFound) {
 			return nil, fmt.Errorf("%w: cannot get history: account %s not found", domain.ErrAccountNotFound, query.AccountID)
 		}
@@ -315,11 +729,11 @@ func (s *AccountService) GetTransactionHistory(query GetHistoryQuery) ([]events.
 
 // --- Aggregate Loading & Snapshotting Logic ---
 
-func (s *AccountService) loadAccount(accountID string) (*domain.Account, error) {
+func (s *AccountService) loadAccount(ctx context.Context, accountID string) (*domain.Account, error) {
 	var account *domain.Account
 	var snapshotVersion int = 0
 
-	snapshot, found, err := s.snapshotStore.GetLatestSnapshot(accountID)
+	snapshot, found, err := s.snapshotStore.GetLatestSnapshotContext(ctx, accountID)
 	if err != nil {
 		log.Printf("Warning: Error loading snapshot for account %s: %v. Attempting full event replay.", accountID, err)
 		found = false
@@ -327,7 +741,11 @@ func (s *AccountService) loadAccount(accountID string) (*domain.Account, error)
 
 	if found {
 		account, err = domain.ApplySnapshot(snapshot)
-		if err != nil {
+		if errors.Is(err, domain.ErrOutdatedSnapshot) {
+			log.Printf("Warning: %v. Rebuilding account %s from all events.", err, accountID)
+			account = domain.NewAccount(accountID)
+			snapshotVersion = 0
+		} else if err != nil {
 			log.Printf("ERROR: Failed to apply snapshot version %d for account %s: %v. Rebuilding from all events.", snapshot.Version, accountID, err)
 			account = domain.NewAccount(accountID)
 			snapshotVersion = 0
@@ -342,8 +760,11 @@ func (s *AccountService) loadAccount(accountID string) (*domain.Account, error)
 		}
 	}
 
-	eventsToApply, err := s.eventStore.GetEventsAfterVersion(accountID, snapshotVersion)
+	eventsToApply, err := s.eventStore.GetEventsAfterVersionContext(ctx, accountID, snapshotVersion)
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("loading account %s interrupted: %w", accountID, ctx.Err())
+		}
 		if snapshotVersion == 0 {
 			return nil, fmt.Errorf("failed to load initial events for account %s: %w", accountID, err)
 		}
@@ -352,9 +773,8 @@ func (s *AccountService) loadAccount(accountID string) (*domain.Account, error)
 
 	if len(eventsToApply) > 0 {
 		log.Printf("Applying %d events to account %s starting after version %d", len(eventsToApply), accountID, snapshotVersion)
-		err = account.ApplyEvents(eventsToApply)
-		if err != nil {
-			return nil, fmt.Errorf("critical error applying events to account %s after snapshot/initial load: %w", accountID, err)
+		if err := replayEvents(ctx, account, eventsToApply); err != nil {
+			return nil, err
 		}
 	}
 
@@ -367,7 +787,22 @@ func (s *AccountService) loadAccount(accountID string) (*domain.Account, error)
 	return account, nil
 }
 
-func (s *AccountService) saveSnapshotIfNeeded(account *domain.Account) {
+// replayEvents applies history to account in batches, so a cancelled caller
+// doesn't wait for a long history to finish.
+func replayEvents(ctx context.Context, account *domain.Account, history []events.Event) error {
+	for start := 0; start < len(history); start += replayBatchSize {
+		if ctx.Err() != nil {
+			return fmt.Errorf("replay of account %s interrupted at version %d: %w", account.ID, account.Version, ctx.Err())
+		}
+		end := min(start+replayBatchSize, len(history))
+		if err := account.ApplyEvents(history[start:end]); err != nil {
+			return fmt.Errorf("critical error applying events to account %s after snapshot/initial load: %w", account.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *AccountService) saveSnapshotIfNeeded(ctx context.Context, account *domain.Account) {
 	if account.Version%SnapshotFrequency == 0 && account.Version > 0 {
 		log.Printf("Snapshot condition met for account %s at version %d (Frequency: %d)", account.ID, account.Version, SnapshotFrequency)
 
@@ -377,7 +812,7 @@ func (s *AccountService) saveSnapshotIfNeeded(account *domain.Account) {
 			return
 		}
 
-		err = s.snapshotStore.SaveSnapshot(snapshot)
+		err = s.snapshotStore.SaveSnapshotContext(ctx, snapshot)
 		if err != nil {
 			log.Printf("ERROR: Failed to save snapshot for account %s at version %d: %v", account.ID, account.Version, err)
 		} else {
@@ -1,11 +1,13 @@
 package app_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"testing"
 
@@ -899,3 +901,81 @@ func TestAccountService_OptimisticLocking(t *testing.T) {
 
 	t.Logf("current balance  is %s", balances[shared.USD])
 }
+
+// TestAccountService_ContextCancellation verifies that cancelled contexts stop commands and queries.
+func TestAccountService_ContextCancellation(t *testing.T) {
+	service, eventStore, _ := setup()
+	id := "acc-ctx-1"
+	_, _ = service.CreateAccount(app.CreateAccountCommand{AccountID: id, InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: dec("100")}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	t.Run("DepositCancelled", func(t *testing.T) {
+		err := service.DepositContext(ctx, app.DepositMoneyCommand{AccountID: id, Amount: dec("10"), Currency: shared.USD})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+		evts, _ := eventStore.GetEvents(id)
+		if len(evts) != 1 {
+			t.Errorf("expected no new events after cancelled deposit, got %d events", len(evts))
+		}
+	})
+
+	t.Run("BalanceCancelled", func(t *testing.T) {
+		_, err := service.GetCurrentBalanceContext(ctx, app.GetBalanceQuery{AccountID: id})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("HistoryDeadlineExceeded", func(t *testing.T) {
+		expired, cancelExpired := context.WithTimeout(context.Background(), -time.Second)
+		defer cancelExpired()
+		_, err := service.GetTransactionHistoryContext(expired, app.GetHistoryQuery{AccountID: id})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected context.DeadlineExceeded, got %v", err)
+		}
+	})
+}
+
+func TestAccountService_EncryptedStores(t *testing.T) {
+	masterKey, _ := store.NewMasterKey([]byte("0123456789abcdef0123456789abcdef"))
+	enc := store.NewEncryptor(masterKey)
+	eventStore := store.NewInMemoryEventStore(store.WithPayloadEncryption(enc))
+	snapshotStore := store.NewInMemorySnapshotStore(store.WithSnapshotEncryption(enc))
+	service := app.NewAccountService(eventStore, snapshotStore)
+	accID := "acc-enc-1"
+
+	_, err := service.CreateAccount(app.CreateAccountCommand{AccountID: accID, InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: dec("10")}})
+	if err != nil {
+		t.Fatalf("CreateAccount failed: %v", err)
+	}
+	for i := 0; i < app.SnapshotFrequency+5; i++ {
+		if err := service.Deposit(app.DepositMoneyCommand{AccountID: accID, Amount: dec("1"), Currency: shared.USD}); err != nil {
+			t.Fatalf("Deposit %d failed: %v", i, err)
+		}
+	}
+
+	if _, found, _ := snapshotStore.GetLatestSnapshot(accID); !found {
+		t.Fatalf("expected a snapshot to be taken")
+	}
+	if _, plain := eventStore.GetStreamCopy(accID)[1].(events.DepositMadeEvent); plain {
+		t.Errorf("expected events to be encrypted at rest")
+	}
+
+	balances, err := service.GetCurrentBalance(app.GetBalanceQuery{AccountID: accID})
+	if err != nil {
+		t.Fatalf("GetCurrentBalance failed: %v", err)
+	}
+	if want := dec("115"); !balances[shared.USD].Equal(want) {
+		t.Errorf("expected balance %s, got %s", want, balances[shared.USD])
+	}
+	history, err := service.GetTransactionHistory(app.GetHistoryQuery{AccountID: accID, Limit: 1})
+	if err != nil || len(history) != 1 {
+		t.Fatalf("GetTransactionHistory failed: %v", err)
+	}
+	if created, ok := history[0].(events.AccountCreatedEvent); !ok || len(created.InitialBalances) != 1 {
+		t.Errorf("expected history to return decrypted events, got %#v", history[0])
+	}
+}
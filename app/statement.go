@@ -0,0 +1,188 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/domain"
+	"financial-ledger/events"
+	"financial-ledger/shared"
+)
+
+var ErrStatementUnbalanced = errors.New("statement does not reconcile")
+
+// Statement lists an account's activity over [From, To), per currency.
+type Statement struct {
+	AccountID   string
+	From        time.Time
+	To          time.Time
+	GeneratedAt time.Time
+	Currencies  []CurrencyStatement
+}
+
+// CurrencyStatement covers one currency. Opening plus the sum of the line
+// amounts always equals Closing; GenerateStatement checks this before returning.
+type CurrencyStatement struct {
+	Currency     shared.Currency
+	Opening      decimal.Decimal
+	Closing      decimal.Decimal
+	TotalCredits decimal.Decimal
+	TotalDebits  decimal.Decimal
+	Lines        []StatementLine
+}
+
+// StatementLine is one movement of money. Amount is positive for credits and
+// negative for debits; Balance is the running balance after it.
+type StatementLine struct {
+	Timestamp   time.Time
+	Version     int
+	EventID     string
+	Description string
+	Amount      decimal.Decimal
+	Balance     decimal.Decimal
+}
+
+// GenerateStatement builds a statement for events at or after From and before
+// To. The opening balances are the as-of balances just before From, and the
+// closing balances are checked against the as-of balances just before To.
+func (s *AccountService) GenerateStatement(ctx context.Context, query GenerateStatementQuery) (*Statement, error) {
+	if query.From.IsZero() || query.To.IsZero() || !query.From.Before(query.To) {
+		return nil, fmt.Errorf("cannot generate statement: the period must have a start before its end, got %s to %s",
+			query.From.Format(time.RFC3339), query.To.Format(time.RFC3339))
+	}
+
+	opening, err := s.balancesBefore(ctx, query.AccountID, query.From)
+	if err != nil {
+		return nil, err
+	}
+	expectedClosing, err := s.balancesBefore(ctx, query.AccountID, query.To)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := query.From, query.To
+	page, err := s.SearchHistory(ctx, SearchHistoryQuery{AccountID: query.AccountID, From: &from, To: &to})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history for statement of account %s: %w", query.AccountID, err)
+	}
+
+	byCurrency := make(map[shared.Currency]*CurrencyStatement)
+	section := func(cur shared.Currency) *CurrencyStatement {
+		cs, ok := byCurrency[cur]
+		if !ok {
+			cs = &CurrencyStatement{Currency: cur, Opening: opening[cur], Closing: opening[cur]}
+			byCurrency[cur] = cs
+		}
+		return cs
+	}
+	for cur := range opening {
+		section(cur)
+	}
+	for _, item := range page.Items {
+		base := item.Event.GetBase()
+		for _, m := range movementsOf(query.AccountID, item.Event) {
+			cs := section(m.Currency)
+			balance := item.BalancesAfter[m.Currency]
+			cs.Lines = append(cs.Lines, StatementLine{
+				Timestamp:   base.Timestamp,
+				Version:     base.Version,
+				EventID:     base.EventID.String(),
+				Description: m.Description,
+				Amount:      m.Amount,
+				Balance:     balance,
+			})
+			if m.Amount.IsNegative() {
+				cs.TotalDebits = cs.TotalDebits.Add(m.Amount.Neg())
+			} else {
+				cs.TotalCredits = cs.TotalCredits.Add(m.Amount)
+			}
+			cs.Closing = balance
+		}
+	}
+
+	statement := &Statement{AccountID: query.AccountID, From: query.From, To: query.To, GeneratedAt: time.Now().UTC()}
+	for _, cs := range byCurrency {
+		if err := cs.reconcile(expectedClosing[cs.Currency]); err != nil {
+			return nil, fmt.Errorf("statement for account %s: %w", query.AccountID, err)
+		}
+		statement.Currencies = append(statement.Currencies, *cs)
+	}
+	sort.Slice(statement.Currencies, func(i, j int) bool {
+		return statement.Currencies[i].Currency < statement.Currencies[j].Currency
+	})
+	return statement, nil
+}
+
+// reconcile checks that the lines add up from Opening to Closing, and that
+// Closing matches the balance obtained independently by replay.
+func (cs *CurrencyStatement) reconcile(expectedClosing decimal.Decimal) error {
+	running := cs.Opening
+	for _, line := range cs.Lines {
+		running = running.Add(line.Amount)
+		if !running.Equal(line.Balance) {
+			return fmt.Errorf("%w: %s running balance %s after version %d, but the account held %s",
+				ErrStatementUnbalanced, cs.Currency, running, line.Version, line.Balance)
+		}
+	}
+	if !running.Equal(cs.Closing) || !cs.Closing.Equal(expectedClosing) {
+		return fmt.Errorf("%w: %s opening %s plus movements gives %s, but the closing balance is %s",
+			ErrStatementUnbalanced, cs.Currency, cs.Opening, running, expectedClosing)
+	}
+	return nil
+}
+
+// balancesBefore returns the account's balances from every event strictly
+// before at; an account that did not exist yet has no balances.
+func (s *AccountService) balancesBefore(ctx context.Context, accountID string, at time.Time) (map[shared.Currency]decimal.Decimal, error) {
+	account, err := s.loadAccountAtTime(ctx, accountID, at.Add(-time.Nanosecond))
+	if errors.Is(err, domain.ErrAccountNotFound) {
+		if _, errExists := s.loadAccount(ctx, accountID); errExists != nil {
+			return nil, fmt.Errorf("cannot generate statement: %w", errExists)
+		}
+		return map[shared.Currency]decimal.Decimal{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute balances of account %s at %s: %w", accountID, at.Format(time.RFC3339), err)
+	}
+	return copyBalances(account.Balances), nil
+}
+
+// movement is one signed change to a balance, as it appears on a statement.
+type movement struct {
+	Currency    shared.Currency
+	Amount      decimal.Decimal
+	Description string
+}
+
+func movementsOf(accountID string, event events.Event) []movement {
+	switch e := event.(type) {
+	case events.AccountCreatedEvent:
+		out := make([]movement, 0, len(e.InitialBalances))
+		for _, b := range e.InitialBalances {
+			out = append(out, movement{b.Currency, b.Amount, "Account opened"})
+		}
+		return out
+	case events.DepositMadeEvent:
+		return []movement{{e.Currency, e.Amount, "Deposit"}}
+	case events.WithdrawalMadeEvent:
+		return []movement{{e.Currency, e.Amount.Neg(), "Withdrawal"}}
+	case events.CurrencyConvertedEvent:
+		return []movement{
+			{e.FromCurrency, e.FromAmount.Neg(), fmt.Sprintf("Converted to %s at %s", e.ToCurrency, e.ExchangeRate)},
+			{e.ToCurrency, e.ToAmount, fmt.Sprintf("Converted from %s at %s", e.FromCurrency, e.ExchangeRate)},
+		}
+	case events.MoneyTransferredEvent:
+		if accountID == e.SourceAccountID {
+			return []movement{{e.DebitedCurrency, e.DebitedAmount.Neg(), "Transfer to " + e.TargetAccountID}}
+		}
+		return []movement{{e.CreditedCurrency, e.CreditedAmount, "Transfer from " + e.SourceAccountID}}
+	case events.TransactionReversedEvent:
+		return []movement{{e.Currency, e.Delta(), fmt.Sprintf("Reversal of %s %s", e.ReversedType, e.ReversedEventID)}}
+	}
+	return nil
+}
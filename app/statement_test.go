@@ -0,0 +1,140 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/app"
+	"financial-ledger/domain"
+	"financial-ledger/shared"
+)
+
+// pause makes consecutive events fall on either side of the returned instant.
+func pause() time.Time {
+	time.Sleep(time.Millisecond)
+	t := time.Now().UTC()
+	time.Sleep(time.Millisecond)
+	return t
+}
+
+func TestAccountService_GenerateStatement(t *testing.T) {
+	service, _, _ := setup()
+	ctx := context.Background()
+	id, other := "acc-stmt-1", "acc-stmt-2"
+
+	beforeOpen := pause()
+	for _, acc := range []string{id, other} {
+		if _, err := service.CreateAccount(app.CreateAccountCommand{AccountID: acc, InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: dec("100")}}); err != nil {
+			t.Fatalf("CreateAccount(%s) failed: %v", acc, err)
+		}
+	}
+	if err := service.Deposit(app.DepositMoneyCommand{AccountID: id, Amount: dec("0.10"), Currency: shared.USD}); err != nil {
+		t.Fatalf("Deposit failed: %v", err)
+	}
+	periodStart := pause()
+	if err := service.Withdraw(app.WithdrawMoneyCommand{AccountID: id, Amount: dec("20.05"), Currency: shared.USD}); err != nil {
+		t.Fatalf("Withdraw failed: %v", err)
+	}
+	if err := service.ConvertCurrency(app.ConvertCurrencyCommand{AccountID: id, FromAmount: dec("10"), FromCurrency: shared.USD, ToCurrency: shared.EUR}); err != nil {
+		t.Fatalf("ConvertCurrency failed: %v", err)
+	}
+	if err := service.TransferMoney(app.TransferMoneyCommand{SourceAccountID: other, TargetAccountID: id, Amount: dec("7"), Currency: shared.USD}); err != nil {
+		t.Fatalf("TransferMoney failed: %v", err)
+	}
+	periodEnd := pause()
+	if err := service.Deposit(app.DepositMoneyCommand{AccountID: id, Amount: dec("1000"), Currency: shared.USD}); err != nil {
+		t.Fatalf("Deposit failed: %v", err)
+	}
+
+	section := func(t *testing.T, st *app.Statement, cur shared.Currency) app.CurrencyStatement {
+		t.Helper()
+		for _, cs := range st.Currencies {
+			if cs.Currency == cur {
+				return cs
+			}
+		}
+		t.Fatalf("statement has no %s section: %+v", cur, st.Currencies)
+		return app.CurrencyStatement{}
+	}
+
+	t.Run("Period", func(t *testing.T) {
+		st, err := service.GenerateStatement(ctx, app.GenerateStatementQuery{AccountID: id, From: periodStart, To: periodEnd})
+		if err != nil {
+			t.Fatalf("GenerateStatement failed: %v", err)
+		}
+		if len(st.Currencies) != 2 {
+			t.Fatalf("expected EUR and USD sections, got %+v", st.Currencies)
+		}
+
+		usd := section(t, st, shared.USD)
+		if !usd.Opening.Equal(dec("100.10")) || !usd.Closing.Equal(dec("77.05")) {
+			t.Errorf("USD opening/closing: expected 100.10/77.05, got %s/%s", usd.Opening, usd.Closing)
+		}
+		if !usd.TotalCredits.Equal(dec("7")) || !usd.TotalDebits.Equal(dec("30.05")) {
+			t.Errorf("USD totals: expected credits 7 and debits 30.05, got %s and %s", usd.TotalCredits, usd.TotalDebits)
+		}
+		wantLines := []struct{ desc, amount, balance string }{
+			{"Withdrawal", "-20.05", "80.05"},
+			{"Converted to EUR at 0.92", "-10", "70.05"},
+			{"Transfer from " + other, "7", "77.05"},
+		}
+		if len(usd.Lines) != len(wantLines) {
+			t.Fatalf("expected %d USD lines, got %+v", len(wantLines), usd.Lines)
+		}
+		for i, want := range wantLines {
+			line := usd.Lines[i]
+			if line.Description != want.desc || !line.Amount.Equal(dec(want.amount)) || !line.Balance.Equal(dec(want.balance)) {
+				t.Errorf("USD line %d: expected %+v, got %+v", i, want, line)
+			}
+		}
+
+		eur := section(t, st, shared.EUR)
+		if !eur.Opening.IsZero() || !eur.Closing.Equal(dec("9.2")) || len(eur.Lines) != 1 {
+			t.Errorf("unexpected EUR section %+v", eur)
+		}
+	})
+
+	t.Run("ReconcilesWithAsOfBalances", func(t *testing.T) {
+		st, err := service.GenerateStatement(ctx, app.GenerateStatementQuery{AccountID: id, From: beforeOpen, To: time.Now().UTC().Add(time.Second)})
+		if err != nil {
+			t.Fatalf("GenerateStatement failed: %v", err)
+		}
+		current, err := service.GetCurrentBalanceContext(ctx, app.GetBalanceQuery{AccountID: id})
+		if err != nil {
+			t.Fatalf("GetCurrentBalance failed: %v", err)
+		}
+		for _, cs := range st.Currencies {
+			if !cs.Opening.IsZero() {
+				t.Errorf("%s: expected zero opening before the account existed, got %s", cs.Currency, cs.Opening)
+			}
+			if !cs.Closing.Equal(current[cs.Currency]) {
+				t.Errorf("%s: closing %s does not match current balance %s", cs.Currency, cs.Closing, current[cs.Currency])
+			}
+		}
+	})
+
+	t.Run("QuietPeriod", func(t *testing.T) {
+		st, err := service.GenerateStatement(ctx, app.GenerateStatementQuery{AccountID: other, From: periodEnd, To: periodEnd.Add(time.Hour)})
+		if err != nil {
+			t.Fatalf("GenerateStatement failed: %v", err)
+		}
+		usd := section(t, st, shared.USD)
+		if len(usd.Lines) != 0 || !usd.Opening.Equal(dec("93")) || !usd.Closing.Equal(dec("93")) {
+			t.Errorf("expected an unchanged 93 USD, got %+v", usd)
+		}
+	})
+
+	t.Run("InvalidRequests", func(t *testing.T) {
+		if _, err := service.GenerateStatement(ctx, app.GenerateStatementQuery{AccountID: id, From: periodEnd, To: periodStart}); err == nil {
+			t.Errorf("expected an error for a reversed period")
+		}
+		_, err := service.GenerateStatement(ctx, app.GenerateStatementQuery{AccountID: "missing", From: periodStart, To: periodEnd})
+		if !errors.Is(err, domain.ErrAccountNotFound) {
+			t.Errorf("expected ErrAccountNotFound, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// StatementFormat selects how RenderStatement writes a statement.
+type StatementFormat string
+
+const (
+	StatementText StatementFormat = "text"
+	StatementCSV  StatementFormat = "csv"
+	StatementHTML StatementFormat = "html"
+)
+
+// RenderStatement writes st to w. Amounts are shown to two decimal places.
+func RenderStatement(w io.Writer, st *Statement, format StatementFormat) error {
+	switch format {
+	case StatementText, "":
+		return renderStatementText(w, st)
+	case StatementCSV:
+		return renderStatementCSV(w, st)
+	case StatementHTML:
+		return statementHTML.Execute(w, st)
+	}
+	return fmt.Errorf("unknown statement format %q (want text, csv or html)", format)
+}
+
+func statementPeriod(st *Statement) string {
+	return fmt.Sprintf("%s to %s", st.From.Format(time.RFC3339), st.To.Format(time.RFC3339))
+}
+
+func renderStatementText(w io.Writer, st *Statement) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Statement for account %s\n", st.AccountID)
+	fmt.Fprintf(tw, "Period: %s\n", statementPeriod(st))
+	if len(st.Currencies) == 0 {
+		fmt.Fprintln(tw, "\nNo balances or activity in this period.")
+	}
+	for _, cs := range st.Currencies {
+		fmt.Fprintf(tw, "\n%s\n", cs.Currency)
+		fmt.Fprintf(tw, "Date\tDescription\tAmount\tBalance\t\n")
+		fmt.Fprintf(tw, "\tOpening balance\t\t%s\t\n", cs.Opening.StringFixed(2))
+		for _, line := range cs.Lines {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t\n",
+				line.Timestamp.Format(time.RFC3339), line.Description, line.Amount.StringFixed(2), line.Balance.StringFixed(2))
+		}
+		fmt.Fprintf(tw, "\tClosing balance\t\t%s\t\n", cs.Closing.StringFixed(2))
+		fmt.Fprintf(tw, "\tTotal credits\t%s\t\t\n", cs.TotalCredits.StringFixed(2))
+		fmt.Fprintf(tw, "\tTotal debits\t%s\t\t\n", cs.TotalDebits.StringFixed(2))
+	}
+	return tw.Flush()
+}
+
+func renderStatementCSV(w io.Writer, st *Statement) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"account_id", "currency", "timestamp", "version", "event_id", "description", "amount", "balance"})
+	for _, cs := range st.Currencies {
+		cur := string(cs.Currency)
+		_ = cw.Write([]string{st.AccountID, cur, st.From.Format(time.RFC3339), "", "", "Opening balance", "", cs.Opening.StringFixed(2)})
+		for _, line := range cs.Lines {
+			_ = cw.Write([]string{st.AccountID, cur, line.Timestamp.Format(time.RFC3339Nano), strconv.Itoa(line.Version),
+				line.EventID, line.Description, line.Amount.StringFixed(2), line.Balance.StringFixed(2)})
+		}
+		_ = cw.Write([]string{st.AccountID, cur, st.To.Format(time.RFC3339), "", "", "Closing balance", "", cs.Closing.StringFixed(2)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+var statementHTML = template.Must(template.New("statement").Funcs(template.FuncMap{
+	"date":   func(t time.Time) string { return t.Format("2006-01-02 15:04:05 MST") },
+	"period": statementPeriod,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Statement {{.AccountID}}</title>
+<style>
+  body { font-family: sans-serif; margin: 2em; color: #000; }
+  table { border-collapse: collapse; width: 100%; margin-bottom: 2em; }
+  th, td { border-bottom: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+  td.num, th.num { text-align: right; font-variant-numeric: tabular-nums; }
+  tr.summary td { font-weight: bold; }
+  section { page-break-inside: avoid; }
+  @media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<h1>Account statement</h1>
+<p>Account <strong>{{.AccountID}}</strong><br>Period {{period .}}<br>Generated {{date .GeneratedAt}}</p>
+{{range .Currencies}}<section>
+<h2>{{.Currency}}</h2>
+<table>
+<thead><tr><th>Date</th><th>Description</th><th class="num">Amount</th><th class="num">Balance</th></tr></thead>
+<tbody>
+<tr class="summary"><td></td><td>Opening balance</td><td></td><td class="num">{{.Opening.StringFixed 2}}</td></tr>
+{{range .Lines}}<tr><td>{{date .Timestamp}}</td><td>{{.Description}}</td><td class="num">{{.Amount.StringFixed 2}}</td><td class="num">{{.Balance.StringFixed 2}}</td></tr>
+{{end}}<tr class="summary"><td></td><td>Closing balance</td><td></td><td class="num">{{.Closing.StringFixed 2}}</td></tr>
+</tbody>
+</table>
+<p>Total credits {{.TotalCredits.StringFixed 2}} &middot; Total debits {{.TotalDebits.StringFixed 2}}</p>
+</section>
+{{else}}<p>No balances or activity in this period.</p>
+{{end}}</body>
+</html>
+`))
@@ -0,0 +1,94 @@
+package app_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"financial-ledger/app"
+	"financial-ledger/shared"
+)
+
+func sampleStatement() *app.Statement {
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	return &app.Statement{
+		AccountID: "acc-<1>",
+		From:      from,
+		To:        from.AddDate(0, 1, 0),
+		Currencies: []app.CurrencyStatement{{
+			Currency:     shared.USD,
+			Opening:      dec("100"),
+			Closing:      dec("87.5"),
+			TotalCredits: dec("7.5"),
+			TotalDebits:  dec("20"),
+			Lines: []app.StatementLine{
+				{Timestamp: from.Add(time.Hour), Version: 4, Description: "Withdrawal", Amount: dec("-20"), Balance: dec("80")},
+				{Timestamp: from.Add(2 * time.Hour), Version: 5, Description: "Transfer from acc, \"2\"", Amount: dec("7.5"), Balance: dec("87.5")},
+			},
+		}},
+	}
+}
+
+func TestRenderStatement(t *testing.T) {
+	st := sampleStatement()
+
+	t.Run("Text", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := app.RenderStatement(&buf, st, app.StatementText); err != nil {
+			t.Fatalf("RenderStatement failed: %v", err)
+		}
+		out := buf.String()
+		for _, want := range []string{"Statement for account acc-<1>", "Opening balance", "100.00", "-20.00", "Closing balance", "87.50", "Total debits"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("text statement is missing %q:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := app.RenderStatement(&buf, st, app.StatementCSV); err != nil {
+			t.Fatalf("RenderStatement failed: %v", err)
+		}
+		records, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("statement is not valid CSV: %v", err)
+		}
+		if len(records) != 5 {
+			t.Fatalf("expected header, opening, 2 lines and closing, got %d records", len(records))
+		}
+		if records[1][5] != "Opening balance" || records[1][7] != "100.00" {
+			t.Errorf("unexpected opening row %v", records[1])
+		}
+		if records[3][5] != "Transfer from acc, \"2\"" || records[3][6] != "7.50" || records[3][3] != "5" {
+			t.Errorf("unexpected line row %v", records[3])
+		}
+		if records[4][5] != "Closing balance" || records[4][7] != "87.50" {
+			t.Errorf("unexpected closing row %v", records[4])
+		}
+	})
+
+	t.Run("HTML", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := app.RenderStatement(&buf, st, app.StatementHTML); err != nil {
+			t.Fatalf("RenderStatement failed: %v", err)
+		}
+		out := buf.String()
+		if !strings.Contains(out, "acc-&lt;1&gt;") || strings.Contains(out, "acc-<1>") {
+			t.Errorf("account ID was not escaped:\n%s", out)
+		}
+		for _, want := range []string{"<!DOCTYPE html>", "@media print", "Opening balance", "87.50"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("HTML statement is missing %q", want)
+			}
+		}
+	})
+
+	t.Run("UnknownFormat", func(t *testing.T) {
+		if err := app.RenderStatement(&bytes.Buffer{}, st, "pdf"); err == nil {
+			t.Errorf("expected an error for an unknown format")
+		}
+	})
+}
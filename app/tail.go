@@ -0,0 +1,52 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"financial-ledger/events"
+)
+
+// DefaultTailPollInterval is how often TailEvents and feeds check for new events.
+const DefaultTailPollInterval = 250 * time.Millisecond
+
+// WithTailPollInterval overrides DefaultTailPollInterval.
+func WithTailPollInterval(d time.Duration) ServiceOption {
+	return func(s *AccountService) {
+		if d > 0 {
+			s.tailPollInterval = d
+		}
+	}
+}
+
+// TailEvents calls fn with each event of an account after afterVersion, in
+// version order, and then with every new event as it is committed. It returns
+// when ctx is done or fn returns an error, and fails with ErrAccountNotFound
+// if the account does not exist.
+func (s *AccountService) TailEvents(ctx context.Context, accountID string, afterVersion int, fn func(events.Event) error) error {
+	if _, err := s.loadAccount(ctx, accountID); err != nil {
+		return fmt.Errorf("cannot tail account %s: %w", accountID, err)
+	}
+
+	ticker := time.NewTicker(s.tailPollInterval)
+	defer ticker.Stop()
+	for {
+		pending, err := s.eventStore.GetEventsAfterVersionContext(ctx, accountID, afterVersion)
+		if err != nil {
+			return fmt.Errorf("failed to read events of account %s after version %d: %w", accountID, afterVersion, err)
+		}
+		for _, event := range pending {
+			if err := fn(event); err != nil {
+				return err
+			}
+			afterVersion = event.GetBase().Version
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
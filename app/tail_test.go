@@ -0,0 +1,79 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/app"
+	"financial-ledger/domain"
+	"financial-ledger/events"
+	"financial-ledger/shared"
+	"financial-ledger/store"
+)
+
+func TestAccountService_TailEvents(t *testing.T) {
+	newService := func() *app.AccountService {
+		return app.NewAccountService(store.NewInMemoryEventStore(), store.NewInMemorySnapshotStore(), app.WithTailPollInterval(time.Millisecond))
+	}
+
+	t.Run("ReplaysThenFollows", func(t *testing.T) {
+		service := newService()
+		id := "acc-tail-1"
+		_, _ = service.CreateAccount(app.CreateAccountCommand{AccountID: id, InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: dec("100")}})
+		_ = service.Deposit(app.DepositMoneyCommand{AccountID: id, Amount: dec("1"), Currency: shared.USD})
+		_ = service.Deposit(app.DepositMoneyCommand{AccountID: id, Amount: dec("2"), Currency: shared.USD})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		received := make(chan events.Event, 10)
+		done := make(chan error, 1)
+		go func() {
+			done <- service.TailEvents(ctx, id, 1, func(e events.Event) error {
+				received <- e
+				return nil
+			})
+		}()
+
+		for _, want := range []int{2, 3} {
+			if got := (<-received).GetBase().Version; got != want {
+				t.Fatalf("expected version %d from the backlog, got %d", want, got)
+			}
+		}
+		_ = service.Withdraw(app.WithdrawMoneyCommand{AccountID: id, Amount: dec("5"), Currency: shared.USD})
+		select {
+		case e := <-received:
+			if e.GetBase().Version != 4 || e.GetBase().Type != events.WithdrawalMadeType {
+				t.Errorf("expected the new withdrawal at version 4, got %s at %d", e.GetBase().Type, e.GetBase().Version)
+			}
+		case <-ctx.Done():
+			t.Fatal("new event was not delivered")
+		}
+
+		cancel()
+		if err := <-done; !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled after cancellation, got %v", err)
+		}
+	})
+
+	t.Run("StopsOnCallbackError", func(t *testing.T) {
+		service := newService()
+		id := "acc-tail-2"
+		_, _ = service.CreateAccount(app.CreateAccountCommand{AccountID: id})
+		stop := errors.New("stop")
+		err := service.TailEvents(context.Background(), id, 0, func(events.Event) error { return stop })
+		if !errors.Is(err, stop) {
+			t.Errorf("expected the callback's error, got %v", err)
+		}
+	})
+
+	t.Run("UnknownAccount", func(t *testing.T) {
+		err := newService().TailEvents(context.Background(), "missing", 0, func(events.Event) error { return nil })
+		if !errors.Is(err, domain.ErrAccountNotFound) {
+			t.Errorf("expected ErrAccountNotFound, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"financial-ledger/domain"
+	"financial-ledger/events"
+	"financial-ledger/store"
+	"financial-ledger/webhook"
+)
+
+// WithWebhooks replaces the default webhook dispatcher, which keeps its
+// subscriptions and deliveries in memory.
+func WithWebhooks(d *webhook.Dispatcher) ServiceOption {
+	return func(s *AccountService) {
+		if d != nil {
+			s.webhooks = d
+		}
+	}
+}
+
+// CommandRejection is the data of a webhook.CommandRejectedType notification:
+// a command that a business rule refused, so no event was recorded.
+type CommandRejection struct {
+	Command       string    `json:"command"`
+	AccountID     string    `json:"accountId"`
+	Error         string    `json:"error"`
+	Actor         string    `json:"actor,omitempty"`
+	CorrelationID string    `json:"correlationId"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// notifyRejected tells webhook subscribers about a command that failed with a
+// domain error. Other failures (conflicts, storage errors, cancellation) are
+// not rejections and are not reported.
+func (s *AccountService) notifyRejected(ctx context.Context, command, accountID string, meta events.Metadata, cmdErr error) {
+	var domainErr *domain.DomainError
+	if cmdErr == nil || !errors.As(cmdErr, &domainErr) {
+		return
+	}
+	rejection := CommandRejection{
+		Command:       command,
+		AccountID:     accountID,
+		Error:         cmdErr.Error(),
+		Actor:         meta.Actor,
+		CorrelationID: meta.CorrelationID,
+		Timestamp:     time.Now().UTC(),
+	}
+	if err := s.webhooks.Notify(context.WithoutCancel(ctx), webhook.CommandRejectedType, uuid.NewString(), rejection); err != nil {
+		log.Printf("ERROR: Failed to enqueue rejection webhook for %s on account %s: %v", command, accountID, err)
+	}
+}
+
+// AddWebhook subscribes a URL to notifications. The returned subscription
+// holds the secret the receiver needs to verify signatures; it is not shown
+// again by ListWebhooks.
+func (s *AccountService) AddWebhook(ctx context.Context, cmd AddWebhookCommand) (store.WebhookSubscription, error) {
+	sub, err := s.webhooks.Subscribe(ctx, cmd.URL, cmd.EventTypes)
+	if err != nil {
+		return store.WebhookSubscription{}, err
+	}
+	log.Printf("Webhook %s added for %s (types: %v)", sub.ID, sub.URL, sub.EventTypes)
+	return sub, nil
+}
+
+// ListWebhooks returns every subscription with its secret blanked out.
+func (s *AccountService) ListWebhooks(ctx context.Context) ([]store.WebhookSubscription, error) {
+	subs, err := s.webhooks.Subscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+	return subs, nil
+}
+
+// RemoveWebhook deletes a subscription. Deliveries still queued for it are
+// dead-lettered on their next attempt.
+func (s *AccountService) RemoveWebhook(ctx context.Context, id string) error {
+	if err := s.webhooks.Unsubscribe(ctx, id); err != nil {
+		return err
+	}
+	log.Printf("Webhook %s removed", id)
+	return nil
+}
+
+// ListWebhookDeliveries returns deliveries with the given status, or all of
+// them if status is empty. Use store.DeliveryDead to inspect dead letters.
+func (s *AccountService) ListWebhookDeliveries(ctx context.Context, status store.WebhookDeliveryStatus) ([]store.WebhookDelivery, error) {
+	return s.webhooks.Deliveries(ctx, status)
+}
+
+// ReplayWebhookDelivery requeues a dead-lettered delivery.
+func (s *AccountService) ReplayWebhookDelivery(ctx context.Context, deliveryID string) error {
+	return s.webhooks.Replay(ctx, deliveryID)
+}
+
+// DeliverWebhooks enqueues notifications for events committed since the last
+// call and attempts every delivery that is due.
+func (s *AccountService) DeliverWebhooks(ctx context.Context) (webhook.DeliveryReport, error) {
+	if s.projections != nil {
+		if err := s.projections.CatchUp(ctx, webhook.ProjectionName); err != nil {
+			return webhook.DeliveryReport{}, err
+		}
+	}
+	return s.webhooks.DeliverDue(ctx)
+}
+
+// RunWebhooks calls DeliverWebhooks every interval until ctx is cancelled.
+func (s *AccountService) RunWebhooks(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.DeliverWebhooks(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("ERROR: Webhook delivery failed: %v", err)
+			}
+		}
+	}
+}
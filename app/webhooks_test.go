@@ -0,0 +1,113 @@
+package app_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/app"
+	"financial-ledger/events"
+	"financial-ledger/shared"
+	"financial-ledger/store"
+	"financial-ledger/webhook"
+)
+
+func TestAccountService_Webhooks(t *testing.T) {
+	ctx := context.Background()
+	service := app.NewAccountService(store.NewInMemoryEventStore(), store.NewInMemorySnapshotStore())
+
+	var (
+		mu       sync.Mutex
+		secret   string
+		received []webhook.Payload
+	)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		if err := webhook.Verify(secret, r.Header.Get(webhook.SignatureHeader), body, time.Now(), time.Minute); err != nil {
+			t.Errorf("receiver got a bad signature: %v", err)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var p webhook.Payload
+		json.Unmarshal(body, &p)
+		received = append(received, p)
+	}))
+	defer receiver.Close()
+
+	sub, err := service.AddWebhook(ctx, app.AddWebhookCommand{
+		URL:        receiver.URL,
+		EventTypes: []string{string(events.DepositMadeType), string(events.MoneyTransferredType), webhook.CommandRejectedType},
+	})
+	if err != nil {
+		t.Fatalf("AddWebhook failed: %v", err)
+	}
+	secret = sub.Secret
+
+	_, _ = service.CreateAccount(app.CreateAccountCommand{AccountID: "wh-a", InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: dec("100")}})
+	_, _ = service.CreateAccount(app.CreateAccountCommand{AccountID: "wh-b"})
+	_ = service.Deposit(app.DepositMoneyCommand{AccountID: "wh-a", Amount: dec("10"), Currency: shared.USD})
+	_ = service.TransferMoney(app.TransferMoneyCommand{SourceAccountID: "wh-a", TargetAccountID: "wh-b", Amount: dec("5"), Currency: shared.USD})
+	if err := service.Withdraw(app.WithdrawMoneyCommand{AccountID: "wh-a", Amount: dec("1000"), Currency: shared.USD, Metadata: events.Metadata{Actor: "teller-7"}}); err == nil {
+		t.Fatalf("expected the overdraft to be rejected")
+	}
+
+	report, err := service.DeliverWebhooks(ctx)
+	if err != nil {
+		t.Fatalf("DeliverWebhooks failed: %v", err)
+	}
+	// One deposit, two transfer legs and one rejection; account creations were not subscribed to.
+	if report.Delivered != 4 {
+		t.Fatalf("expected 4 deliveries, got %+v", report)
+	}
+
+	t.Run("Payloads", func(t *testing.T) {
+		mu.Lock()
+		defer mu.Unlock()
+		counts := map[string]int{}
+		var rejection app.CommandRejection
+		for _, p := range received {
+			counts[p.Type]++
+			if p.Type == webhook.CommandRejectedType {
+				json.Unmarshal(p.Data, &rejection)
+			}
+		}
+		if counts["DepositMade"] != 1 || counts["MoneyTransferred"] != 2 || counts[webhook.CommandRejectedType] != 1 {
+			t.Errorf("unexpected payload types %v", counts)
+		}
+		if rejection.Command != "withdrawal" || rejection.AccountID != "wh-a" || rejection.Actor != "teller-7" || rejection.CorrelationID == "" {
+			t.Errorf("unexpected rejection %+v", rejection)
+		}
+	})
+
+	t.Run("NothingRedelivered", func(t *testing.T) {
+		if report, _ := service.DeliverWebhooks(ctx); report.Delivered != 0 {
+			t.Errorf("expected no new deliveries, got %+v", report)
+		}
+	})
+
+	t.Run("ListHidesSecret", func(t *testing.T) {
+		subs, err := service.ListWebhooks(ctx)
+		if err != nil || len(subs) != 1 || subs[0].Secret != "" {
+			t.Errorf("expected one subscription without its secret, got %+v (err: %v)", subs, err)
+		}
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		if err := service.RemoveWebhook(ctx, sub.ID); err != nil {
+			t.Fatalf("RemoveWebhook failed: %v", err)
+		}
+		_ = service.Deposit(app.DepositMoneyCommand{AccountID: "wh-a", Amount: dec("1"), Currency: shared.USD})
+		if report, _ := service.DeliverWebhooks(ctx); report.Delivered != 0 {
+			t.Errorf("expected nothing delivered after removal, got %+v", report)
+		}
+	})
+}
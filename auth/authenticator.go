@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"financial-ledger/store"
+)
+
+// APIKey is a principal identified by an API key. Hash is HashAPIKey of the
+// key; the key itself is never stored.
+type APIKey struct {
+	ID       string   `json:"id"`
+	Hash     string   `json:"hash"`
+	Roles    []string `json:"roles"`
+	Accounts []string `json:"accounts,omitempty"`
+}
+
+// Authenticator turns a credential, either an API key or a JWT, into a
+// Principal.
+type Authenticator struct {
+	apiKeys  map[string]Principal // by key hash
+	jwt      *JWTVerifier
+	allowAll *Principal
+}
+
+// NewAuthenticator accepts the given API keys and, if jwt is not nil, JWTs it
+// verifies.
+func NewAuthenticator(keys []APIKey, jwt *JWTVerifier) (*Authenticator, error) {
+	a := &Authenticator{apiKeys: make(map[string]Principal, len(keys)), jwt: jwt}
+	for _, k := range keys {
+		if k.ID == "" {
+			return nil, errors.New("every API key needs an id")
+		}
+		if !isAPIKeyHash(k.Hash) {
+			return nil, fmt.Errorf("API key %s: hash must be \"sha256:<64 hex digits>\"", k.ID)
+		}
+		roles, err := ParseRoles(k.Roles)
+		if err != nil {
+			return nil, fmt.Errorf("API key %s: %w", k.ID, err)
+		}
+		if _, dup := a.apiKeys[k.Hash]; dup {
+			return nil, fmt.Errorf("API key %s: the same key is configured twice", k.ID)
+		}
+		a.apiKeys[k.Hash] = Principal{ID: k.ID, Roles: roles, Accounts: k.Accounts}
+	}
+	return a, nil
+}
+
+// AllowAll returns an Authenticator that accepts any credential, or none, as
+// p. It is for running without authentication, such as a local CLI or a
+// server started with authentication explicitly disabled.
+func AllowAll(p Principal) *Authenticator {
+	return &Authenticator{allowAll: &p}
+}
+
+// Authenticate returns the principal credential belongs to. Credentials with
+// two dots are treated as JWTs, anything else as an API key.
+func (a *Authenticator) Authenticate(ctx context.Context, credential string) (Principal, error) {
+	if a.allowAll != nil {
+		return *a.allowAll, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return Principal{}, err
+	}
+	if credential == "" {
+		return Principal{}, fmt.Errorf("%w: no credentials presented", ErrUnauthenticated)
+	}
+	if strings.Count(credential, ".") == 2 {
+		if a.jwt == nil {
+			return Principal{}, fmt.Errorf("%w: JWTs are not accepted", ErrUnauthenticated)
+		}
+		p, err := a.jwt.Verify(credential)
+		if err != nil {
+			return Principal{}, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+		}
+		return p, nil
+	}
+	p, ok := a.apiKeys[HashAPIKey(credential)]
+	if !ok {
+		return Principal{}, fmt.Errorf("%w: unknown API key", ErrUnauthenticated)
+	}
+	return p, nil
+}
+
+// Config is the JSON form of an Authenticator's settings. Key file paths are
+// relative to the configuration file.
+type Config struct {
+	APIKeys []APIKey   `json:"apiKeys"`
+	JWT     *JWTConfig `json:"jwt,omitempty"`
+}
+
+type JWTConfig struct {
+	Issuer   string `json:"issuer,omitempty"`
+	Audience string `json:"audience,omitempty"`
+	// HMACSecretFile holds the HS256 secret; whitespace around it is ignored.
+	HMACSecretFile string `json:"hmacSecretFile,omitempty"`
+	// PublicKeyFiles are PEM Ed25519 public keys trusted for EdDSA tokens.
+	PublicKeyFiles []string `json:"publicKeyFiles,omitempty"`
+}
+
+// LoadConfig reads a Config file and builds its Authenticator.
+func LoadConfig(path string) (*Authenticator, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read auth config: %w", err)
+	}
+	var cfg Config
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("invalid auth config %s: %w", path, err)
+	}
+
+	var verifier *JWTVerifier
+	if cfg.JWT != nil {
+		dir := filepath.Dir(path)
+		resolve := func(p string) string {
+			if filepath.IsAbs(p) {
+				return p
+			}
+			return filepath.Join(dir, p)
+		}
+		verifier = &JWTVerifier{Issuer: cfg.JWT.Issuer, Audience: cfg.JWT.Audience}
+		if cfg.JWT.HMACSecretFile != "" {
+			secret, err := os.ReadFile(resolve(cfg.JWT.HMACSecretFile))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read JWT secret: %w", err)
+			}
+			verifier.HMACSecret = []byte(strings.TrimSpace(string(secret)))
+		}
+		if len(cfg.JWT.PublicKeyFiles) > 0 {
+			paths := make([]string, len(cfg.JWT.PublicKeyFiles))
+			for i, p := range cfg.JWT.PublicKeyFiles {
+				paths[i] = resolve(p)
+			}
+			if verifier.Keys, err = store.LoadKeyring(paths...); err != nil {
+				return nil, err
+			}
+		}
+		if len(verifier.HMACSecret) == 0 && verifier.Keys == nil {
+			return nil, fmt.Errorf("auth config %s: jwt needs hmacSecretFile or publicKeyFiles", path)
+		}
+	}
+	return NewAuthenticator(cfg.APIKeys, verifier)
+}
@@ -0,0 +1,122 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"financial-ledger/auth"
+)
+
+func TestAuthenticator(t *testing.T) {
+	ctx := context.Background()
+	key, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("GenerateAPIKey failed: %v", err)
+	}
+	if !strings.HasPrefix(key, "lk_") || hash != auth.HashAPIKey(key) {
+		t.Fatalf("unexpected key %q with hash %q", key, hash)
+	}
+	secret := []byte("jwt-secret")
+	authn, err := auth.NewAuthenticator(
+		[]auth.APIKey{{ID: "teller-1", Hash: hash, Roles: []string{"teller"}, Accounts: []string{"acc-1"}}},
+		&auth.JWTVerifier{HMACSecret: secret})
+	if err != nil {
+		t.Fatalf("NewAuthenticator failed: %v", err)
+	}
+
+	t.Run("APIKey", func(t *testing.T) {
+		p, err := authn.Authenticate(ctx, key)
+		if err != nil || p.ID != "teller-1" || !p.Can(auth.PermMoveMoney) || p.Owns("acc-2") {
+			t.Errorf("unexpected principal %+v, %v", p, err)
+		}
+	})
+
+	t.Run("JWT", func(t *testing.T) {
+		token, _ := auth.SignHS256(secret, auth.Claims{Subject: "auditor-1", Roles: []string{"auditor"}, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+		p, err := authn.Authenticate(ctx, token)
+		if err != nil || p.ID != "auditor-1" || !p.Can(auth.PermAudit) {
+			t.Errorf("unexpected principal %+v, %v", p, err)
+		}
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		for name, credential := range map[string]string{
+			"Empty":      "",
+			"UnknownKey": "lk_unknown",
+			"BadJWT":     "a.b.c",
+		} {
+			if _, err := authn.Authenticate(ctx, credential); !errors.Is(err, auth.ErrUnauthenticated) {
+				t.Errorf("%s: expected ErrUnauthenticated, got %v", name, err)
+			}
+		}
+	})
+
+	t.Run("AllowAll", func(t *testing.T) {
+		p, err := auth.AllowAll(auth.Principal{ID: "local", Roles: []auth.Role{auth.RoleAdmin}}).Authenticate(ctx, "")
+		if err != nil || p.ID != "local" {
+			t.Errorf("expected the fixed principal, got %+v, %v", p, err)
+		}
+	})
+
+	t.Run("InvalidKeys", func(t *testing.T) {
+		tests := map[string][]auth.APIKey{
+			"MissingID":     {{Hash: hash, Roles: []string{"admin"}}},
+			"PlaintextHash": {{ID: "k", Hash: key, Roles: []string{"admin"}}},
+			"UnknownRole":   {{ID: "k", Hash: hash, Roles: []string{"owner"}}},
+			"Duplicate":     {{ID: "a", Hash: hash, Roles: []string{"admin"}}, {ID: "b", Hash: hash, Roles: []string{"admin"}}},
+		}
+		for name, keys := range tests {
+			if _, err := auth.NewAuthenticator(keys, nil); err == nil {
+				t.Errorf("%s: expected configuration to be rejected", name)
+			}
+		}
+	})
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	key, hash, _ := auth.GenerateAPIKey()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+	write("jwt.secret", "  from-file\n")
+
+	t.Run("Valid", func(t *testing.T) {
+		path := write("auth.json", `{
+			"apiKeys": [{"id": "ops", "hash": "`+hash+`", "roles": ["admin"]}],
+			"jwt": {"issuer": "idp", "hmacSecretFile": "jwt.secret"}
+		}`)
+		authn, err := auth.LoadConfig(path)
+		if err != nil {
+			t.Fatalf("LoadConfig failed: %v", err)
+		}
+		if p, err := authn.Authenticate(context.Background(), key); err != nil || p.ID != "ops" {
+			t.Errorf("expected the configured key to authenticate, got %+v, %v", p, err)
+		}
+		token, _ := auth.SignHS256([]byte("from-file"), auth.Claims{Subject: "svc", Issuer: "idp", Roles: []string{"readonly"}, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+		if p, err := authn.Authenticate(context.Background(), token); err != nil || p.ID != "svc" {
+			t.Errorf("expected a token signed with the configured secret, relative to the config, to authenticate, got %+v, %v", p, err)
+		}
+	})
+
+	t.Run("UnknownField", func(t *testing.T) {
+		if _, err := auth.LoadConfig(write("typo.json", `{"apiKey": []}`)); err == nil {
+			t.Error("expected a misspelt field to be rejected")
+		}
+	})
+
+	t.Run("JWTWithoutKeys", func(t *testing.T) {
+		if _, err := auth.LoadConfig(write("nokeys.json", `{"jwt": {"issuer": "idp"}}`)); err == nil {
+			t.Error("expected a jwt section without a secret or keys to be rejected")
+		}
+	})
+}
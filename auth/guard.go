@@ -0,0 +1,556 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/app"
+	"financial-ledger/domain"
+	"financial-ledger/events"
+	"financial-ledger/projection"
+	"financial-ledger/screening"
+	"financial-ledger/shared"
+	"financial-ledger/store"
+	"financial-ledger/webhook"
+)
+
+type GuardOption func(*Guard)
+
+// WithDenialStore replaces the default in-memory denial log.
+func WithDenialStore(ds store.DenialStore) GuardOption {
+	return func(g *Guard) {
+		if ds != nil {
+			g.denials = ds
+		}
+	}
+}
+
+// Guard checks the caller's permissions before each call to the account
+// service. The caller is the Principal in the call's context; without one,
+// every call fails with ErrUnauthenticated. Commands are recorded with the
+// principal's ID as their actor, whatever actor the request claimed.
+type Guard struct {
+	svc     *app.AccountService
+	authn   *Authenticator
+	denials store.DenialStore
+}
+
+func NewGuard(svc *app.AccountService, authn *Authenticator, opts ...GuardOption) *Guard {
+	g := &Guard{svc: svc, authn: authn, denials: store.NewInMemoryDenialStore()}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// Authenticate verifies credential and returns a context carrying its
+// principal for the calls that follow. Failures are recorded as denials.
+func (g *Guard) Authenticate(ctx context.Context, credential, channel string) (context.Context, error) {
+	p, err := g.authn.Authenticate(ctx, credential)
+	if err != nil {
+		g.record(WithPrincipal(ctx, Principal{}, channel), Principal{}, "authenticate", "", nil, err.Error())
+		return ctx, err
+	}
+	return WithPrincipal(ctx, p, channel), nil
+}
+
+// Authorize checks that the caller holds perm and owns accountIDs, recording
+// a denial if not. Operations that name no account are refused to principals
+// limited to a list of accounts. The Guard's own methods call it; adapters use
+// it directly for operations the service does not perform, such as key
+// rotation.
+func (g *Guard) Authorize(ctx context.Context, perm Permission, operation string, accountIDs ...string) (Principal, error) {
+	return g.authorize(ctx, perm, operation, "", accountIDs...)
+}
+
+func (g *Guard) authorize(ctx context.Context, perm Permission, operation, detail string, accountIDs ...string) (Principal, error) {
+	p, ok := PrincipalFrom(ctx)
+	if !ok {
+		reason := "no authenticated principal"
+		g.record(ctx, p, operation, detail, accountIDs, reason)
+		return p, fmt.Errorf("%w: %s", ErrUnauthenticated, reason)
+	}
+
+	var reason string
+	switch {
+	case !p.Can(perm):
+		reason = fmt.Sprintf("roles %v do not grant %s", p.roleNames(), perm)
+	case p.Scoped() && len(accountIDs) == 0:
+		reason = "operation spans the whole ledger but the principal is limited to its own accounts"
+	default:
+		for _, id := range accountIDs {
+			if !p.Owns(id) {
+				reason = fmt.Sprintf("account %q is not among the principal's accounts", id)
+				break
+			}
+		}
+	}
+	if reason == "" {
+		return p, nil
+	}
+	g.record(ctx, p, operation, detail, accountIDs, reason)
+	return p, fmt.Errorf("%w: %s may not %s: %s", ErrPermissionDenied, actorName(p), operation, reason)
+}
+
+func (g *Guard) record(ctx context.Context, p Principal, operation, detail string, accountIDs []string, reason string) {
+	denial := store.AccessDenial{
+		Timestamp:  time.Now().UTC(),
+		Actor:      p.ID,
+		Roles:      p.roleNames(),
+		Channel:    channelFrom(ctx),
+		Operation:  operation,
+		Detail:     detail,
+		AccountIDs: accountIDs,
+		Reason:     reason,
+	}
+	attempt := operation
+	if detail != "" {
+		attempt += " " + detail
+	}
+	if len(accountIDs) > 0 {
+		attempt += " on " + strings.Join(accountIDs, ", ")
+	}
+	log.Printf("Warning: Access denied to %s via %s: %s: %s", actorName(p), denial.Channel, attempt, reason)
+	if err := g.denials.RecordDenial(context.WithoutCancel(ctx), denial); err != nil {
+		log.Printf("ERROR: Failed to record access denial for %s: %v", actorName(p), err)
+	}
+}
+
+func actorName(p Principal) string {
+	if p.ID == "" {
+		return "anonymous caller"
+	}
+	return p.ID
+}
+
+// stamp records the principal as the actor of a command. A principal without
+// an ID (authentication disabled) keeps the actor the request supplied.
+func stamp(p Principal, meta events.Metadata) events.Metadata {
+	if p.ID != "" {
+		meta.Actor = p.ID
+	}
+	return meta
+}
+
+func money(amount decimal.Decimal, currency shared.Currency) string {
+	return amount.String() + " " + string(currency)
+}
+
+// --- Commands ---
+
+func (g *Guard) CreateAccount(ctx context.Context, cmd app.CreateAccountCommand) (string, error) {
+	p, err := g.authorize(ctx, PermManageAccounts, "CreateAccount", "", cmd.AccountID)
+	if err != nil {
+		return "", err
+	}
+	cmd.Metadata = stamp(p, cmd.Metadata)
+	return g.svc.CreateAccountContext(ctx, cmd)
+}
+
+func (g *Guard) UpdateAccountDetails(ctx context.Context, cmd app.UpdateAccountDetailsCommand) error {
+	p, err := g.authorize(ctx, PermManageAccounts, "UpdateAccountDetails", "", cmd.AccountID)
+	if err != nil {
+		return err
+	}
+	cmd.Metadata = stamp(p, cmd.Metadata)
+	return g.svc.UpdateAccountDetails(ctx, cmd)
+}
+
+func (g *Guard) ForgetSubject(ctx context.Context, cmd app.ForgetSubjectCommand) (store.ShredRecord, error) {
+	p, err := g.authorize(ctx, PermAdmin, "ForgetSubject", "subject "+cmd.SubjectID)
+	if err != nil {
+		return store.ShredRecord{}, err
+	}
+	cmd.Metadata = stamp(p, cmd.Metadata)
+	return g.svc.ForgetSubject(ctx, cmd)
+}
+
+func (g *Guard) Deposit(ctx context.Context, cmd app.DepositMoneyCommand) error {
+	p, err := g.authorize(ctx, PermMoveMoney, "Deposit", money(cmd.Amount, cmd.Currency), cmd.AccountID)
+	if err != nil {
+		return err
+	}
+	cmd.Metadata = stamp(p, cmd.Metadata)
+	return g.svc.DepositContext(ctx, cmd)
+}
+
+func (g *Guard) Withdraw(ctx context.Context, cmd app.WithdrawMoneyCommand) error {
+	p, err := g.authorize(ctx, PermMoveMoney, "Withdraw", money(cmd.Amount, cmd.Currency), cmd.AccountID)
+	if err != nil {
+		return err
+	}
+	cmd.Metadata = stamp(p, cmd.Metadata)
+	return g.svc.WithdrawContext(ctx, cmd)
+}
+
+func (g *Guard) ConvertCurrency(ctx context.Context, cmd app.ConvertCurrencyCommand) error {
+	detail := money(cmd.FromAmount, cmd.FromCurrency) + " to " + string(cmd.ToCurrency)
+	p, err := g.authorize(ctx, PermMoveMoney, "ConvertCurrency", detail, cmd.AccountID)
+	if err != nil {
+		return err
+	}
+	cmd.Metadata = stamp(p, cmd.Metadata)
+	return g.svc.ConvertCurrencyContext(ctx, cmd)
+}
+
+// TransferMoney requires ownership of the source account only: paying into
+// someone else's account is the point of a transfer.
+func (g *Guard) TransferMoney(ctx context.Context, cmd app.TransferMoneyCommand) error {
+	detail := money(cmd.Amount, cmd.Currency) + " to " + cmd.TargetAccountID
+	p, err := g.authorize(ctx, PermMoveMoney, "TransferMoney", detail, cmd.SourceAccountID)
+	if err != nil {
+		return err
+	}
+	cmd.Metadata = stamp(p, cmd.Metadata)
+	return g.svc.TransferMoneyContext(ctx, cmd)
+}
+
+// --- Customers ---
+
+// Customers span accounts, so principals limited to their own accounts cannot
+// use these operations.
+
+func (g *Guard) CreateCustomer(ctx context.Context, cmd app.CreateCustomerCommand) (string, error) {
+	p, err := g.authorize(ctx, PermManageAccounts, "CreateCustomer", "customer "+cmd.CustomerID)
+	if err != nil {
+		return "", err
+	}
+	cmd.Metadata = stamp(p, cmd.Metadata)
+	return g.svc.CreateCustomer(ctx, cmd)
+}
+
+func (g *Guard) UpdateCustomer(ctx context.Context, cmd app.UpdateCustomerCommand) error {
+	p, err := g.authorize(ctx, PermManageAccounts, "UpdateCustomer", "customer "+cmd.CustomerID)
+	if err != nil {
+		return err
+	}
+	cmd.Metadata = stamp(p, cmd.Metadata)
+	return g.svc.UpdateCustomer(ctx, cmd)
+}
+
+func (g *Guard) GetCustomer(ctx context.Context, customerID string) (*app.CustomerProfile, error) {
+	if _, err := g.authorize(ctx, PermReadPersonalData, "GetCustomer", "customer "+customerID); err != nil {
+		return nil, err
+	}
+	return g.svc.GetCustomer(ctx, customerID)
+}
+
+func (g *Guard) ListCustomers(ctx context.Context) ([]app.CustomerProfile, error) {
+	if _, err := g.authorize(ctx, PermReadPersonalData, "ListCustomers", ""); err != nil {
+		return nil, err
+	}
+	return g.svc.ListCustomers(ctx)
+}
+
+func (g *Guard) GetCustomerAccounts(ctx context.Context, query app.GetCustomerAccountsQuery) (*app.CustomerAccounts, error) {
+	if _, err := g.authorize(ctx, PermRead, "GetCustomerAccounts", "customer "+query.CustomerID); err != nil {
+		return nil, err
+	}
+	return g.svc.GetCustomerAccounts(ctx, query)
+}
+
+// --- Approvals ---
+
+func (g *Guard) RequestAccountClosure(ctx context.Context, cmd app.CloseAccountCommand) (domain.Approval, error) {
+	p, err := g.authorize(ctx, PermManageAccounts, "RequestAccountClosure", "", cmd.AccountID)
+	if err != nil {
+		return domain.Approval{}, err
+	}
+	cmd.Metadata = stamp(p, cmd.Metadata)
+	return g.svc.RequestAccountClosure(ctx, cmd)
+}
+
+func (g *Guard) RequestReversal(ctx context.Context, cmd app.ReverseTransactionCommand) (domain.Approval, error) {
+	p, err := g.authorize(ctx, PermMoveMoney, "RequestReversal", "event "+cmd.EventID, cmd.AccountID)
+	if err != nil {
+		return domain.Approval{}, err
+	}
+	cmd.Metadata = stamp(p, cmd.Metadata)
+	return g.svc.RequestReversal(ctx, cmd)
+}
+
+// ListApprovals shows principals limited to a list of accounts only the
+// approvals touching those accounts.
+func (g *Guard) ListApprovals(ctx context.Context, query app.ListApprovalsQuery) ([]domain.Approval, error) {
+	accounts := scopeOf(ctx)
+	if query.AccountID != "" {
+		accounts = []string{query.AccountID}
+	}
+	p, err := g.authorize(ctx, PermRead, "ListApprovals", "", accounts...)
+	if err != nil {
+		return nil, err
+	}
+	approvals, err := g.svc.ListApprovals(ctx, query)
+	if err != nil || !p.Scoped() {
+		return approvals, err
+	}
+	return slices.DeleteFunc(approvals, func(a domain.Approval) bool {
+		return !slices.ContainsFunc(a.AccountIDs, p.Owns)
+	}), nil
+}
+
+// Approve requires PermApprove on every account the approval touches. The
+// approver is the principal, so nobody can approve a request of their own
+// by claiming another actor.
+func (g *Guard) Approve(ctx context.Context, cmd app.ApproveCommand) (domain.Approval, error) {
+	p, err := g.authorize(ctx, PermApprove, "Approve", "approval "+cmd.ApprovalID, g.approvalAccounts(ctx, cmd.ApprovalID)...)
+	if err != nil {
+		return domain.Approval{}, err
+	}
+	cmd.Metadata = stamp(p, cmd.Metadata)
+	return g.svc.Approve(ctx, cmd)
+}
+
+func (g *Guard) RejectApproval(ctx context.Context, cmd app.RejectApprovalCommand) (domain.Approval, error) {
+	p, err := g.authorize(ctx, PermApprove, "RejectApproval", "approval "+cmd.ApprovalID, g.approvalAccounts(ctx, cmd.ApprovalID)...)
+	if err != nil {
+		return domain.Approval{}, err
+	}
+	cmd.Metadata = stamp(p, cmd.Metadata)
+	return g.svc.RejectApproval(ctx, cmd)
+}
+
+// approvalAccounts returns the accounts an approval touches, or none if it
+// cannot be found; the service then reports it missing.
+func (g *Guard) approvalAccounts(ctx context.Context, id string) []string {
+	approval, err := g.svc.GetApproval(ctx, id)
+	if err != nil {
+		return nil
+	}
+	return approval.AccountIDs
+}
+
+// --- Queries ---
+
+func (g *Guard) GetBalances(ctx context.Context, query app.GetBalanceQuery) (*app.AccountBalances, error) {
+	if _, err := g.authorize(ctx, PermRead, "GetBalances", "", query.AccountID); err != nil {
+		return nil, err
+	}
+	return g.svc.GetBalances(ctx, query)
+}
+
+func (g *Guard) GetCurrentBalance(ctx context.Context, query app.GetBalanceQuery) (map[shared.Currency]decimal.Decimal, error) {
+	if _, err := g.authorize(ctx, PermRead, "GetCurrentBalance", "", query.AccountID); err != nil {
+		return nil, err
+	}
+	return g.svc.GetCurrentBalanceContext(ctx, query)
+}
+
+func (g *Guard) SearchHistory(ctx context.Context, query app.SearchHistoryQuery) (*app.HistoryPage, error) {
+	if _, err := g.authorize(ctx, PermRead, "SearchHistory", "", query.AccountID); err != nil {
+		return nil, err
+	}
+	return g.svc.SearchHistory(ctx, query)
+}
+
+func (g *Guard) GenerateStatement(ctx context.Context, query app.GenerateStatementQuery) (*app.Statement, error) {
+	if _, err := g.authorize(ctx, PermRead, "GenerateStatement", "", query.AccountID); err != nil {
+		return nil, err
+	}
+	return g.svc.GenerateStatement(ctx, query)
+}
+
+func (g *Guard) TailEvents(ctx context.Context, accountID string, afterVersion int, fn func(events.Event) error) error {
+	if _, err := g.authorize(ctx, PermRead, "TailEvents", "", accountID); err != nil {
+		return err
+	}
+	return g.svc.TailEvents(ctx, accountID, afterVersion, fn)
+}
+
+func (g *Guard) OpenFeed(ctx context.Context, query app.FeedQuery) (*app.Feed, error) {
+	if _, err := g.authorize(ctx, PermRead, "OpenFeed", "", query.AccountIDs...); err != nil {
+		return nil, err
+	}
+	return g.svc.OpenFeed(ctx, query)
+}
+
+// ListAccounts confines principals limited to a list of accounts to those
+// accounts rather than refusing the query.
+func (g *Guard) ListAccounts(ctx context.Context, query app.ListAccountsQuery) (*app.AccountPage, error) {
+	p, err := g.authorize(ctx, PermRead, "ListAccounts", "", scopeOf(ctx)...)
+	if err != nil {
+		return nil, err
+	}
+	if p.Scoped() {
+		owned := p.Accounts
+		if len(query.IDs) > 0 {
+			owned = slices.DeleteFunc(slices.Clone(query.IDs), func(id string) bool { return !p.Owns(id) })
+		}
+		if len(owned) == 0 {
+			return &app.AccountPage{}, nil
+		}
+		query.IDs = owned
+	}
+	return g.svc.ListAccounts(ctx, query)
+}
+
+// scopeOf returns the accounts of the caller's scope, so that listing counts as an
+// operation on them rather than on the whole ledger.
+func scopeOf(ctx context.Context) []string {
+	p, _ := PrincipalFrom(ctx)
+	return p.Accounts
+}
+
+func (g *Guard) GetAccountDetails(ctx context.Context, query app.GetAccountDetailsQuery) (*app.AccountDetails, error) {
+	if _, err := g.authorize(ctx, PermReadPersonalData, "GetAccountDetails", "", query.AccountID); err != nil {
+		return nil, err
+	}
+	return g.svc.GetAccountDetails(ctx, query)
+}
+
+// RevealPersonalData decrypts personal data found in an event of accountID.
+func (g *Guard) RevealPersonalData(ctx context.Context, accountID string, sealed events.SealedPersonalData) (app.PersonalDetails, bool, error) {
+	if _, err := g.authorize(ctx, PermReadPersonalData, "RevealPersonalData", "subject "+sealed.SubjectID, accountID); err != nil {
+		return app.PersonalDetails{}, false, err
+	}
+	return g.svc.RevealPersonalData(ctx, sealed)
+}
+
+// --- Audit ---
+
+func (g *Guard) CreateCheckpoint(ctx context.Context) (store.Checkpoint, error) {
+	if _, err := g.authorize(ctx, PermAudit, "CreateCheckpoint", ""); err != nil {
+		return store.Checkpoint{}, err
+	}
+	return g.svc.CreateCheckpoint(ctx)
+}
+
+func (g *Guard) VerifyCheckpoints(ctx context.Context) (int, error) {
+	if _, err := g.authorize(ctx, PermAudit, "VerifyCheckpoints", ""); err != nil {
+		return 0, err
+	}
+	return g.svc.VerifyCheckpoints(ctx)
+}
+
+func (g *Guard) GetInclusionProof(ctx context.Context, eventID uuid.UUID) (*app.EventProof, error) {
+	if _, err := g.authorize(ctx, PermAudit, "GetInclusionProof", "event "+eventID.String()); err != nil {
+		return nil, err
+	}
+	return g.svc.GetInclusionProof(ctx, eventID)
+}
+
+func (g *Guard) ProjectionStatus(ctx context.Context) ([]projection.Status, error) {
+	if _, err := g.authorize(ctx, PermAudit, "ProjectionStatus", ""); err != nil {
+		return nil, err
+	}
+	return g.svc.ProjectionStatus(ctx)
+}
+
+// ListDenials returns the access denials recorded since the given time.
+func (g *Guard) ListDenials(ctx context.Context, since time.Time) ([]store.AccessDenial, error) {
+	if _, err := g.authorize(ctx, PermAudit, "ListDenials", ""); err != nil {
+		return nil, err
+	}
+	return g.denials.ListDenials(ctx, since)
+}
+
+// --- Monitoring and screening ---
+
+// Alerts and screening decisions cover the whole ledger, so principals
+// limited to a list of accounts cannot review them.
+
+func (g *Guard) ListAlerts(ctx context.Context, query app.ListAlertsQuery) ([]store.Alert, error) {
+	if _, err := g.authorize(ctx, PermReviewAlerts, "ListAlerts", string(query.Status)); err != nil {
+		return nil, err
+	}
+	return g.svc.ListAlerts(ctx, query)
+}
+
+func (g *Guard) GetAlert(ctx context.Context, id string) (store.Alert, error) {
+	if _, err := g.authorize(ctx, PermReviewAlerts, "GetAlert", id); err != nil {
+		return store.Alert{}, err
+	}
+	return g.svc.GetAlert(ctx, id)
+}
+
+// DispositionAlert records the disposition as made by the principal.
+func (g *Guard) DispositionAlert(ctx context.Context, cmd app.DispositionAlertCommand) (store.Alert, error) {
+	p, err := g.authorize(ctx, PermReviewAlerts, "DispositionAlert", string(cmd.Status)+" "+cmd.AlertID)
+	if err != nil {
+		return store.Alert{}, err
+	}
+	cmd.Metadata = stamp(p, cmd.Metadata)
+	return g.svc.DispositionAlert(ctx, cmd)
+}
+
+// ScreenName checks a name against the sanctions list, recording the
+// principal as the actor.
+func (g *Guard) ScreenName(ctx context.Context, subject screening.Subject, meta events.Metadata) (store.ScreeningDecision, error) {
+	p, err := g.authorize(ctx, PermReviewAlerts, "ScreenName", "")
+	if err != nil {
+		return store.ScreeningDecision{}, err
+	}
+	return g.svc.ScreenName(ctx, subject, stamp(p, meta))
+}
+
+func (g *Guard) ListScreenings(ctx context.Context, accountID string) ([]store.ScreeningDecision, error) {
+	if _, err := g.authorize(ctx, PermReviewAlerts, "ListScreenings", accountID); err != nil {
+		return nil, err
+	}
+	return g.svc.ListScreenings(ctx, accountID)
+}
+
+// --- Administration ---
+
+func (g *Guard) RebuildProjection(ctx context.Context, name string) error {
+	if _, err := g.authorize(ctx, PermAdmin, "RebuildProjection", name); err != nil {
+		return err
+	}
+	return g.svc.RebuildProjection(ctx, name)
+}
+
+func (g *Guard) AddWebhook(ctx context.Context, cmd app.AddWebhookCommand) (store.WebhookSubscription, error) {
+	if _, err := g.authorize(ctx, PermAdmin, "AddWebhook", cmd.URL); err != nil {
+		return store.WebhookSubscription{}, err
+	}
+	return g.svc.AddWebhook(ctx, cmd)
+}
+
+func (g *Guard) ListWebhooks(ctx context.Context) ([]store.WebhookSubscription, error) {
+	if _, err := g.authorize(ctx, PermAdmin, "ListWebhooks", ""); err != nil {
+		return nil, err
+	}
+	return g.svc.ListWebhooks(ctx)
+}
+
+func (g *Guard) RemoveWebhook(ctx context.Context, id string) error {
+	if _, err := g.authorize(ctx, PermAdmin, "RemoveWebhook", id); err != nil {
+		return err
+	}
+	return g.svc.RemoveWebhook(ctx, id)
+}
+
+func (g *Guard) ListWebhookDeliveries(ctx context.Context, status store.WebhookDeliveryStatus) ([]store.WebhookDelivery, error) {
+	if _, err := g.authorize(ctx, PermAdmin, "ListWebhookDeliveries", string(status)); err != nil {
+		return nil, err
+	}
+	return g.svc.ListWebhookDeliveries(ctx, status)
+}
+
+func (g *Guard) ReplayWebhookDelivery(ctx context.Context, deliveryID string) error {
+	if _, err := g.authorize(ctx, PermAdmin, "ReplayWebhookDelivery", deliveryID); err != nil {
+		return err
+	}
+	return g.svc.ReplayWebhookDelivery(ctx, deliveryID)
+}
+
+func (g *Guard) DeliverWebhooks(ctx context.Context) (webhook.DeliveryReport, error) {
+	if _, err := g.authorize(ctx, PermAdmin, "DeliverWebhooks", ""); err != nil {
+		return webhook.DeliveryReport{}, err
+	}
+	return g.svc.DeliverWebhooks(ctx)
+}
+
+func (g *Guard) ReloadSanctionsList(ctx context.Context) (screening.Version, bool, error) {
+	if _, err := g.authorize(ctx, PermAdmin, "ReloadSanctionsList", ""); err != nil {
+		return screening.Version{}, false, err
+	}
+	return g.svc.ReloadSanctionsList(ctx)
+}
@@ -0,0 +1,321 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/app"
+	"financial-ledger/auth"
+	"financial-ledger/domain"
+	"financial-ledger/events"
+	"financial-ledger/screening"
+	"financial-ledger/shared"
+	"financial-ledger/store"
+)
+
+type guardFixture struct {
+	guard   *auth.Guard
+	svc     *app.AccountService
+	denials *store.InMemoryDenialStore
+}
+
+func newGuardFixture(t *testing.T) *guardFixture {
+	t.Helper()
+	svc := app.NewAccountService(store.NewInMemoryEventStore(), store.NewInMemorySnapshotStore())
+	for _, id := range []string{"acc-1", "acc-2"} {
+		if _, err := svc.CreateAccount(app.CreateAccountCommand{AccountID: id, InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: decimal.NewFromInt(100)}}); err != nil {
+			t.Fatalf("failed to create %s: %v", id, err)
+		}
+	}
+	denials := store.NewInMemoryDenialStore()
+	return &guardFixture{guard: auth.NewGuard(svc, auth.AllowAll(auth.Principal{}), auth.WithDenialStore(denials)), svc: svc, denials: denials}
+}
+
+func as(id string, role auth.Role, accounts ...string) context.Context {
+	return auth.WithPrincipal(context.Background(), auth.Principal{ID: id, Roles: []auth.Role{role}, Accounts: accounts}, "test")
+}
+
+func usd(n int64) decimal.Decimal {
+	return decimal.NewFromInt(n)
+}
+
+func TestGuard_Authorization(t *testing.T) {
+	f := newGuardFixture(t)
+	teller := as("teller-1", auth.RoleTeller, "acc-1")
+	viewer := as("viewer", auth.RoleReadOnly)
+	auditor := as("auditor", auth.RoleAuditor)
+
+	t.Run("NoPrincipal", func(t *testing.T) {
+		_, err := f.guard.GetBalances(context.Background(), app.GetBalanceQuery{AccountID: "acc-1"})
+		if !errors.Is(err, auth.ErrUnauthenticated) {
+			t.Errorf("expected ErrUnauthenticated, got %v", err)
+		}
+	})
+
+	t.Run("TellerOnOwnAccount", func(t *testing.T) {
+		if err := f.guard.Deposit(teller, app.DepositMoneyCommand{AccountID: "acc-1", Amount: usd(5), Currency: shared.USD}); err != nil {
+			t.Fatalf("Deposit failed: %v", err)
+		}
+		if err := f.guard.TransferMoney(teller, app.TransferMoneyCommand{SourceAccountID: "acc-1", TargetAccountID: "acc-2", Amount: usd(5), Currency: shared.USD}); err != nil {
+			t.Fatalf("a transfer out of an owned account should be allowed: %v", err)
+		}
+	})
+
+	t.Run("Denied", func(t *testing.T) {
+		tests := []struct {
+			name string
+			call func() error
+		}{
+			{"TellerOtherAccount", func() error {
+				return f.guard.Withdraw(teller, app.WithdrawMoneyCommand{AccountID: "acc-2", Amount: usd(1), Currency: shared.USD})
+			}},
+			{"TellerTransferFromOtherAccount", func() error {
+				return f.guard.TransferMoney(teller, app.TransferMoneyCommand{SourceAccountID: "acc-2", TargetAccountID: "acc-1", Amount: usd(1), Currency: shared.USD})
+			}},
+			{"TellerLedgerWideAudit", func() error { _, err := f.guard.CreateCheckpoint(teller); return err }},
+			{"ReadOnlyDeposit", func() error {
+				return f.guard.Deposit(viewer, app.DepositMoneyCommand{AccountID: "acc-1", Amount: usd(1), Currency: shared.USD})
+			}},
+			{"ReadOnlyPersonalData", func() error {
+				_, err := f.guard.GetAccountDetails(viewer, app.GetAccountDetailsQuery{AccountID: "acc-1"})
+				return err
+			}},
+			{"AuditorOpensAccount", func() error {
+				_, err := f.guard.CreateAccount(auditor, app.CreateAccountCommand{AccountID: "acc-3"})
+				return err
+			}},
+			{"AuditorForgetsSubject", func() error {
+				_, err := f.guard.ForgetSubject(auditor, app.ForgetSubjectCommand{SubjectID: "acc-1"})
+				return err
+			}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if err := tt.call(); !errors.Is(err, auth.ErrPermissionDenied) {
+					t.Errorf("expected ErrPermissionDenied, got %v", err)
+				}
+			})
+		}
+		balances, _ := f.svc.GetBalances(context.Background(), app.GetBalanceQuery{AccountID: "acc-2"})
+		if !balances.Balances[shared.USD].Equal(usd(105)) {
+			t.Errorf("denied commands must not change balances, acc-2 has %v", balances.Balances)
+		}
+	})
+
+	t.Run("AuditorAudits", func(t *testing.T) {
+		if _, err := f.guard.CreateCheckpoint(auditor); err != nil {
+			t.Errorf("CreateCheckpoint failed: %v", err)
+		}
+		if _, err := f.guard.ListDenials(auditor, time.Time{}); err != nil {
+			t.Errorf("ListDenials failed: %v", err)
+		}
+	})
+}
+
+func TestGuard_ActorAndAudit(t *testing.T) {
+	f := newGuardFixture(t)
+	teller := as("teller-1", auth.RoleTeller, "acc-1")
+
+	t.Run("PrincipalIsRecordedAsActor", func(t *testing.T) {
+		err := f.guard.Deposit(teller, app.DepositMoneyCommand{AccountID: "acc-1", Amount: usd(1), Currency: shared.USD, Metadata: events.Metadata{Actor: "impostor"}})
+		if err != nil {
+			t.Fatalf("Deposit failed: %v", err)
+		}
+		page, err := f.svc.SearchHistory(context.Background(), app.SearchHistoryQuery{AccountID: "acc-1", Descending: true, Limit: 1})
+		if err != nil || len(page.Items) != 1 {
+			t.Fatalf("SearchHistory failed: %v", err)
+		}
+		if got := page.Items[0].Event.GetBase().Metadata.Actor; got != "teller-1" {
+			t.Errorf("expected actor teller-1, got %q", got)
+		}
+	})
+
+	t.Run("DenialIsRecorded", func(t *testing.T) {
+		before := time.Now().Add(-time.Second)
+		_ = f.guard.Withdraw(teller, app.WithdrawMoneyCommand{AccountID: "acc-2", Amount: usd(7), Currency: shared.USD})
+		denials, err := f.denials.ListDenials(context.Background(), before)
+		if err != nil || len(denials) != 1 {
+			t.Fatalf("expected one denial, got %+v, %v", denials, err)
+		}
+		d := denials[0]
+		if d.Actor != "teller-1" || d.Channel != "test" || d.Operation != "Withdraw" || d.Detail != "7 USD" ||
+			len(d.AccountIDs) != 1 || d.AccountIDs[0] != "acc-2" || len(d.Roles) != 1 || d.Roles[0] != "teller" || d.Reason == "" {
+			t.Errorf("unexpected denial record: %+v", d)
+		}
+	})
+
+	t.Run("FailedAuthenticationIsRecorded", func(t *testing.T) {
+		authn, _ := auth.NewAuthenticator(nil, nil)
+		denials := store.NewInMemoryDenialStore()
+		guard := auth.NewGuard(f.svc, authn, auth.WithDenialStore(denials))
+		if _, err := guard.Authenticate(context.Background(), "lk_wrong", "http"); !errors.Is(err, auth.ErrUnauthenticated) {
+			t.Fatalf("expected ErrUnauthenticated, got %v", err)
+		}
+		recorded, _ := denials.ListDenials(context.Background(), time.Time{})
+		if len(recorded) != 1 || recorded[0].Operation != "authenticate" || recorded[0].Channel != "http" {
+			t.Errorf("expected the failed authentication to be recorded, got %+v", recorded)
+		}
+	})
+}
+
+func TestGuard_ListAccountsIsScoped(t *testing.T) {
+	f := newGuardFixture(t)
+	teller := as("teller-1", auth.RoleTeller, "acc-1")
+
+	page, err := f.guard.ListAccounts(teller, app.ListAccountsQuery{})
+	if err != nil {
+		t.Fatalf("ListAccounts failed: %v", err)
+	}
+	if len(page.Accounts) != 1 || page.Accounts[0].AccountID != "acc-1" {
+		t.Errorf("expected only acc-1, got %+v", page.Accounts)
+	}
+
+	page, err = f.guard.ListAccounts(teller, app.ListAccountsQuery{IDs: []string{"acc-2"}})
+	if err != nil || len(page.Accounts) != 0 {
+		t.Errorf("asking for an account outside the scope should find nothing, got %+v, %v", page, err)
+	}
+
+	page, err = f.guard.ListAccounts(as("viewer", auth.RoleReadOnly), app.ListAccountsQuery{})
+	if err != nil || len(page.Accounts) != 2 {
+		t.Errorf("an unscoped reader should see every account, got %+v, %v", page, err)
+	}
+}
+
+func TestGuard_Approvals(t *testing.T) {
+	f := newGuardFixture(t)
+	teller := as("teller-1", auth.RoleTeller, "acc-1")
+
+	approval, err := f.guard.RequestAccountClosure(teller, app.CloseAccountCommand{AccountID: "acc-1"})
+	if err != nil {
+		t.Fatalf("RequestAccountClosure failed: %v", err)
+	}
+	if approval.RequestedBy != "teller-1" {
+		t.Errorf("expected the principal as requester, got %q", approval.RequestedBy)
+	}
+
+	t.Run("TellerCannotApprove", func(t *testing.T) {
+		_, err := f.guard.Approve(as("teller-2", auth.RoleTeller), app.ApproveCommand{ApprovalID: approval.ID})
+		if !errors.Is(err, auth.ErrPermissionDenied) {
+			t.Errorf("expected ErrPermissionDenied, got %v", err)
+		}
+	})
+
+	t.Run("RequesterCannotApproveWithAnotherActor", func(t *testing.T) {
+		supervisor := as("teller-1", auth.RoleSupervisor, "acc-1")
+		_, err := f.guard.Approve(supervisor, app.ApproveCommand{ApprovalID: approval.ID, Metadata: events.Metadata{Actor: "someone-else"}})
+		if err == nil {
+			t.Error("expected the requester's approval to be refused whatever actor it claims")
+		}
+	})
+
+	t.Run("ScopedListing", func(t *testing.T) {
+		approvals, err := f.guard.ListApprovals(as("teller-2", auth.RoleTeller, "acc-2"), app.ListApprovalsQuery{})
+		if err != nil || len(approvals) != 0 {
+			t.Errorf("expected no approvals outside the scope, got %+v, %v", approvals, err)
+		}
+		approvals, err = f.guard.ListApprovals(teller, app.ListApprovalsQuery{})
+		if err != nil || len(approvals) != 1 {
+			t.Errorf("expected the teller's own approval, got %+v, %v", approvals, err)
+		}
+	})
+
+	t.Run("SupervisorApproves", func(t *testing.T) {
+		// acc-1 still holds funds, so the closure is approved but fails.
+		decided, err := f.guard.Approve(as("super", auth.RoleSupervisor), app.ApproveCommand{ApprovalID: approval.ID})
+		if errors.Is(err, auth.ErrPermissionDenied) || decided.Status != domain.ApprovalFailed {
+			t.Errorf("expected the supervisor's approval to be recorded, got %+v, %v", decided, err)
+		}
+		if decided.DecidedBy != "super" {
+			t.Errorf("expected the principal as approver, got %q", decided.DecidedBy)
+		}
+	})
+}
+
+func TestGuard_Alerts(t *testing.T) {
+	f := newGuardFixture(t)
+
+	for name, ctx := range map[string]context.Context{
+		"Teller":           as("teller-1", auth.RoleTeller),
+		"Auditor":          as("auditor", auth.RoleAuditor),
+		"ScopedCompliance": as("analyst", auth.RoleCompliance, "acc-1"),
+	} {
+		if _, err := f.guard.ListAlerts(ctx, app.ListAlertsQuery{}); !errors.Is(err, auth.ErrPermissionDenied) {
+			t.Errorf("%s: expected ErrPermissionDenied, got %v", name, err)
+		}
+	}
+
+	compliance := as("analyst", auth.RoleCompliance)
+	if _, err := f.guard.ListAlerts(compliance, app.ListAlertsQuery{}); err != nil {
+		t.Errorf("expected compliance to list alerts, got %v", err)
+	}
+	_, err := f.guard.DispositionAlert(compliance, app.DispositionAlertCommand{AlertID: "missing", Status: store.AlertCleared, Note: "ok"})
+	if !errors.Is(err, store.ErrAlertNotFound) {
+		t.Errorf("expected ErrAlertNotFound, got %v", err)
+	}
+	if err := f.guard.Deposit(compliance, app.DepositMoneyCommand{AccountID: "acc-1", Amount: usd(5), Currency: shared.USD}); !errors.Is(err, auth.ErrPermissionDenied) {
+		t.Errorf("expected compliance not to move money, got %v", err)
+	}
+}
+
+func TestGuard_Screening(t *testing.T) {
+	f := newGuardFixture(t)
+	teller := as("teller-1", auth.RoleTeller)
+	if _, err := f.guard.ScreenName(teller, screening.Subject{Name: "Jane Smith"}, events.Metadata{}); !errors.Is(err, auth.ErrPermissionDenied) {
+		t.Errorf("expected a teller not to screen names, got %v", err)
+	}
+	if _, err := f.guard.ListScreenings(teller, "acc-1"); !errors.Is(err, auth.ErrPermissionDenied) {
+		t.Errorf("expected a teller not to list screenings, got %v", err)
+	}
+
+	compliance := as("analyst", auth.RoleCompliance)
+	if _, err := f.guard.ScreenName(compliance, screening.Subject{Name: "Jane Smith"}, events.Metadata{}); !errors.Is(err, app.ErrScreeningDisabled) {
+		t.Errorf("expected compliance to reach the service, got %v", err)
+	}
+	if _, err := f.guard.ListScreenings(compliance, ""); err != nil {
+		t.Errorf("expected compliance to list screenings, got %v", err)
+	}
+	if _, _, err := f.guard.ReloadSanctionsList(compliance); !errors.Is(err, auth.ErrPermissionDenied) {
+		t.Errorf("expected reloading the list to need admin, got %v", err)
+	}
+	if _, _, err := f.guard.ReloadSanctionsList(as("root", auth.RoleAdmin)); !errors.Is(err, app.ErrScreeningDisabled) {
+		t.Errorf("expected admin to reach the service, got %v", err)
+	}
+}
+
+func TestGuard_Customers(t *testing.T) {
+	f := newGuardFixture(t)
+	teller := as("teller-1", auth.RoleTeller)
+	if _, err := f.guard.CreateCustomer(teller, app.CreateCustomerCommand{CustomerID: "cust-1", Details: app.CustomerDetails{Name: "Ada Lovelace"}}); err != nil {
+		t.Fatalf("expected a teller to register customers, got %v", err)
+	}
+
+	for name, ctx := range map[string]context.Context{
+		"ReadOnly": as("viewer", auth.RoleReadOnly),
+		"Auditor":  as("auditor", auth.RoleAuditor),
+	} {
+		if _, err := f.guard.CreateCustomer(ctx, app.CreateCustomerCommand{Details: app.CustomerDetails{Name: "X"}}); !errors.Is(err, auth.ErrPermissionDenied) {
+			t.Errorf("%s: expected ErrPermissionDenied, got %v", name, err)
+		}
+		if _, err := f.guard.GetCustomer(ctx, "cust-1"); !errors.Is(err, auth.ErrPermissionDenied) {
+			t.Errorf("%s: expected personal data to be refused, got %v", name, err)
+		}
+		if _, err := f.guard.GetCustomerAccounts(ctx, app.GetCustomerAccountsQuery{CustomerID: "cust-1", ReportingCurrency: shared.USD}); err != nil {
+			t.Errorf("%s: expected to read the customer's accounts, got %v", name, err)
+		}
+	}
+
+	scoped := as("cust-1-login", auth.RoleTeller, "acc-1")
+	if _, err := f.guard.GetCustomerAccounts(scoped, app.GetCustomerAccountsQuery{CustomerID: "cust-1", ReportingCurrency: shared.USD}); !errors.Is(err, auth.ErrPermissionDenied) {
+		t.Errorf("expected a scoped principal to be refused, got %v", err)
+	}
+	if err := f.guard.UpdateCustomer(scoped, app.UpdateCustomerCommand{CustomerID: "cust-1", KYCTier: domain.KYCTierStandard}); !errors.Is(err, auth.ErrPermissionDenied) {
+		t.Errorf("expected a scoped principal to be refused, got %v", err)
+	}
+	if profile, err := f.guard.GetCustomer(teller, "cust-1"); err != nil || profile.Name != "Ada Lovelace" {
+		t.Errorf("expected a teller to read the customer, got %+v, %v", profile, err)
+	}
+}
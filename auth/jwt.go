@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"financial-ledger/store"
+)
+
+// DefaultJWTLeeway is the clock skew tolerated when checking exp and nbf.
+const DefaultJWTLeeway = time.Minute
+
+// Claims are the JWT claims the ledger reads. Subject becomes the principal
+// ID; Roles and Accounts map onto the Principal fields of the same name.
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss,omitempty"`
+	Audience  audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	Roles     []string `json:"roles"`
+	Accounts  []string `json:"accounts,omitempty"`
+}
+
+// audience accepts the single-string and array forms of "aud".
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return fmt.Errorf("aud must be a string or an array of strings")
+	}
+	*a = many
+	return nil
+}
+
+// JWTVerifier checks compact JWS tokens signed with HS256 under HMACSecret or
+// with EdDSA (Ed25519) under one of Keys. Tokens are verified locally; there is
+// no call to an identity provider. An empty Issuer or Audience is not checked.
+type JWTVerifier struct {
+	Issuer     string
+	Audience   string
+	HMACSecret []byte
+	Keys       *store.Keyring
+	Leeway     time.Duration
+	Now        func() time.Time
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// Verify checks token's signature and claims and returns its principal.
+func (v *JWTVerifier) Verify(token string) (Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Principal{}, errors.New("malformed JWT")
+	}
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Principal{}, fmt.Errorf("malformed JWT header: %w", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Principal{}, fmt.Errorf("malformed JWT signature: %w", err)
+	}
+	if err := v.checkSignature(header, []byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return Principal{}, err
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Principal{}, fmt.Errorf("malformed JWT claims: %w", err)
+	}
+	if err := v.checkClaims(claims); err != nil {
+		return Principal{}, err
+	}
+	roles, err := ParseRoles(claims.Roles)
+	if err != nil {
+		return Principal{}, fmt.Errorf("JWT for %s: %w", claims.Subject, err)
+	}
+	return Principal{ID: claims.Subject, Roles: roles, Accounts: claims.Accounts}, nil
+}
+
+func (v *JWTVerifier) checkSignature(header jwtHeader, signed, sig []byte) error {
+	switch header.Alg {
+	case "HS256":
+		if len(v.HMACSecret) == 0 {
+			return errors.New("HS256 tokens are not accepted")
+		}
+		if !hmac.Equal(sig, hs256(v.HMACSecret, signed)) {
+			return errors.New("JWT signature does not match")
+		}
+		return nil
+	case "EdDSA":
+		if v.Keys == nil {
+			return errors.New("EdDSA tokens are not accepted")
+		}
+		keyIDs := v.Keys.KeyIDs()
+		if header.Kid != "" {
+			keyIDs = []string{header.Kid}
+		}
+		for _, keyID := range keyIDs {
+			if v.Keys.Verify(keyID, signed, hex.EncodeToString(sig)) == nil {
+				return nil
+			}
+		}
+		return errors.New("JWT signature does not match any trusted key")
+	}
+	return fmt.Errorf("JWT algorithm %q is not accepted", header.Alg)
+}
+
+func (v *JWTVerifier) checkClaims(c Claims) error {
+	now := time.Now()
+	if v.Now != nil {
+		now = v.Now()
+	}
+	leeway := v.Leeway
+	if leeway == 0 {
+		leeway = DefaultJWTLeeway
+	}
+	switch {
+	case c.Subject == "":
+		return errors.New("JWT has no subject")
+	case c.ExpiresAt == 0:
+		return errors.New("JWT has no expiry")
+	case now.After(time.Unix(c.ExpiresAt, 0).Add(leeway)):
+		return errors.New("JWT has expired")
+	case c.NotBefore != 0 && now.Add(leeway).Before(time.Unix(c.NotBefore, 0)):
+		return errors.New("JWT is not valid yet")
+	case v.Issuer != "" && c.Issuer != v.Issuer:
+		return fmt.Errorf("JWT issuer %q is not trusted", c.Issuer)
+	case v.Audience != "" && !slices.Contains(c.Audience, v.Audience):
+		return fmt.Errorf("JWT is not intended for audience %q", v.Audience)
+	}
+	return nil
+}
+
+// SignHS256 issues a token for claims signed with secret. It is meant for
+// deployments that mint their own short-lived tokens, and for tests.
+func SignHS256(secret []byte, claims Claims) (string, error) {
+	header, _ := json.Marshal(jwtHeader{Alg: "HS256"})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT claims: %w", err)
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(hs256(secret, []byte(signed))), nil
+}
+
+// SignEdDSA issues a token for claims signed with an Ed25519 key, naming the
+// key in the kid header.
+func SignEdDSA(key ed25519.PrivateKey, claims Claims) (string, error) {
+	header, _ := json.Marshal(jwtHeader{Alg: "EdDSA", Kid: store.KeyIDFor(key.Public().(ed25519.PublicKey))})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT claims: %w", err)
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(ed25519.Sign(key, []byte(signed))), nil
+}
+
+func hs256(secret, signed []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write(signed)
+	return h.Sum(nil)
+}
+
+func decodeSegment(segment string, dst any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
@@ -0,0 +1,118 @@
+package auth_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"financial-ledger/auth"
+	"financial-ledger/store"
+)
+
+func TestJWTVerifier(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	secret := []byte("shared-secret")
+	verifier := &auth.JWTVerifier{Issuer: "idp", Audience: "ledger", HMACSecret: secret, Now: func() time.Time { return now }}
+	valid := func() auth.Claims {
+		return auth.Claims{
+			Subject:   "teller-1",
+			Issuer:    "idp",
+			Audience:  []string{"ledger", "other"},
+			ExpiresAt: now.Add(time.Hour).Unix(),
+			Roles:     []string{"teller"},
+			Accounts:  []string{"acc-1"},
+		}
+	}
+	sign := func(t *testing.T, c auth.Claims) string {
+		t.Helper()
+		token, err := auth.SignHS256(secret, c)
+		if err != nil {
+			t.Fatalf("SignHS256 failed: %v", err)
+		}
+		return token
+	}
+
+	t.Run("HS256", func(t *testing.T) {
+		p, err := verifier.Verify(sign(t, valid()))
+		if err != nil {
+			t.Fatalf("Verify failed: %v", err)
+		}
+		if p.ID != "teller-1" || len(p.Roles) != 1 || p.Roles[0] != auth.RoleTeller || !p.Owns("acc-1") || p.Owns("acc-2") {
+			t.Errorf("unexpected principal: %+v", p)
+		}
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			mutate func(*auth.Claims)
+			want   string
+		}{
+			{"Expired", func(c *auth.Claims) { c.ExpiresAt = now.Add(-2 * time.Minute).Unix() }, "expired"},
+			{"NoExpiry", func(c *auth.Claims) { c.ExpiresAt = 0 }, "no expiry"},
+			{"NotYetValid", func(c *auth.Claims) { c.NotBefore = now.Add(time.Hour).Unix() }, "not valid yet"},
+			{"WrongIssuer", func(c *auth.Claims) { c.Issuer = "elsewhere" }, "issuer"},
+			{"WrongAudience", func(c *auth.Claims) { c.Audience = []string{"other"} }, "audience"},
+			{"NoSubject", func(c *auth.Claims) { c.Subject = "" }, "subject"},
+			{"UnknownRole", func(c *auth.Claims) { c.Roles = []string{"root"} }, "unknown role"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				c := valid()
+				tt.mutate(&c)
+				if _, err := verifier.Verify(sign(t, c)); err == nil || !strings.Contains(err.Error(), tt.want) {
+					t.Errorf("expected an error mentioning %q, got %v", tt.want, err)
+				}
+			})
+		}
+	})
+
+	t.Run("ExpiryLeeway", func(t *testing.T) {
+		c := valid()
+		c.ExpiresAt = now.Add(-30 * time.Second).Unix()
+		if _, err := verifier.Verify(sign(t, c)); err != nil {
+			t.Errorf("a token expired within the leeway should be accepted, got %v", err)
+		}
+	})
+
+	t.Run("TamperedPayload", func(t *testing.T) {
+		token := sign(t, valid())
+		admin := valid()
+		admin.Roles = []string{"admin"}
+		forged := strings.Split(sign(t, admin), ".")[1]
+		parts := strings.Split(token, ".")
+		if _, err := verifier.Verify(parts[0] + "." + forged + "." + parts[2]); err == nil {
+			t.Error("a token whose claims were swapped should be rejected")
+		}
+	})
+
+	t.Run("WrongSecret", func(t *testing.T) {
+		token, _ := auth.SignHS256([]byte("other-secret"), valid())
+		if _, err := verifier.Verify(token); err == nil {
+			t.Error("a token signed with another secret should be rejected")
+		}
+	})
+
+	t.Run("EdDSA", func(t *testing.T) {
+		pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+		_, otherPriv, _ := ed25519.GenerateKey(rand.Reader)
+		v := &auth.JWTVerifier{Keys: store.NewKeyring(pub), Now: func() time.Time { return now }}
+
+		token, err := auth.SignEdDSA(priv, valid())
+		if err != nil {
+			t.Fatalf("SignEdDSA failed: %v", err)
+		}
+		if p, err := v.Verify(token); err != nil || p.ID != "teller-1" {
+			t.Errorf("expected teller-1, got %+v, %v", p, err)
+		}
+		untrusted, _ := auth.SignEdDSA(otherPriv, valid())
+		if _, err := v.Verify(untrusted); err == nil {
+			t.Error("a token signed by an untrusted key should be rejected")
+		}
+		if _, err := v.Verify(sign(t, valid())); err == nil {
+			t.Error("HS256 tokens should be rejected when no secret is configured")
+		}
+	})
+}
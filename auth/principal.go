@@ -0,0 +1,150 @@
+// Package auth authenticates callers and authorizes what they may do.
+//
+// A Principal is an authenticated caller: an API key holder or the subject of
+// a locally verified JWT. Its roles grant permissions, and an optional list of
+// accounts confines it to those accounts. The Guard enforces both in front of
+// app.AccountService; the HTTP and gRPC APIs and the CLI all go through it,
+// and every denial is recorded in a store.DenialStore.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrUnauthenticated  = errors.New("unauthenticated")
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+type Role string
+
+const (
+	RoleAdmin      Role = "admin"
+	RoleSupervisor Role = "supervisor"
+	RoleTeller     Role = "teller"
+	RoleAuditor    Role = "auditor"
+	RoleCompliance Role = "compliance"
+	RoleReadOnly   Role = "readonly"
+)
+
+// ParseRole accepts a role name in any case, and "read-only" for RoleReadOnly.
+func ParseRole(s string) (Role, error) {
+	role := Role(strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), "-", ""))
+	if _, ok := rolePermissions[role]; !ok {
+		return "", fmt.Errorf("unknown role %q: use admin, supervisor, teller, auditor, compliance or readonly", s)
+	}
+	return role, nil
+}
+
+// ParseRoles parses a list of role names.
+func ParseRoles(names []string) ([]Role, error) {
+	roles := make([]Role, 0, len(names))
+	for _, name := range names {
+		role, err := ParseRole(name)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+type Permission string
+
+const (
+	// PermRead covers balances, history, statements and event streams.
+	PermRead Permission = "read"
+	// PermReadPersonalData covers decrypted account holder details.
+	PermReadPersonalData Permission = "read_personal_data"
+	// PermManageAccounts covers opening accounts and updating their details.
+	PermManageAccounts Permission = "manage_accounts"
+	// PermMoveMoney covers deposits, withdrawals, conversions and transfers.
+	PermMoveMoney Permission = "move_money"
+	// PermApprove covers approving and rejecting commands held for a second
+	// user's approval.
+	PermApprove Permission = "approve"
+	// PermReviewAlerts covers listing transaction monitoring alerts and
+	// recording their dispositions, and sanctions screening checks and
+	// decisions.
+	PermReviewAlerts Permission = "review_alerts"
+	// PermAudit covers checkpoints, proofs, projection status and the denial log.
+	PermAudit Permission = "audit"
+	// PermAdmin covers erasure, key rotation, webhooks, the outbox, rebuilds
+	// and sanctions list reloads.
+	PermAdmin Permission = "admin"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin:      {PermRead, PermReadPersonalData, PermManageAccounts, PermMoveMoney, PermApprove, PermReviewAlerts, PermAudit, PermAdmin},
+	RoleSupervisor: {PermRead, PermReadPersonalData, PermManageAccounts, PermMoveMoney, PermApprove},
+	RoleTeller:     {PermRead, PermReadPersonalData, PermManageAccounts, PermMoveMoney},
+	RoleAuditor:    {PermRead, PermAudit},
+	RoleCompliance: {PermRead, PermReadPersonalData, PermReviewAlerts},
+	RoleReadOnly:   {PermRead},
+}
+
+// Principal is an authenticated caller. If Accounts is non-empty the principal
+// may act only on those accounts, and may not use operations that span the
+// whole ledger, whatever its roles.
+type Principal struct {
+	ID       string
+	Roles    []Role
+	Accounts []string
+}
+
+// Can reports whether any of the principal's roles grants perm.
+func (p Principal) Can(perm Permission) bool {
+	for _, role := range p.Roles {
+		if slices.Contains(rolePermissions[role], perm) {
+			return true
+		}
+	}
+	return false
+}
+
+// Scoped reports whether the principal is confined to a list of accounts.
+func (p Principal) Scoped() bool {
+	return len(p.Accounts) > 0
+}
+
+// Owns reports whether the principal may act on accountID.
+func (p Principal) Owns(accountID string) bool {
+	return !p.Scoped() || slices.Contains(p.Accounts, accountID)
+}
+
+func (p Principal) roleNames() []string {
+	names := make([]string, len(p.Roles))
+	for i, role := range p.Roles {
+		names[i] = string(role)
+	}
+	return names
+}
+
+type sessionKey struct{}
+
+type session struct {
+	principal Principal
+	channel   string
+}
+
+// WithPrincipal returns a context carrying p as the caller, reached through
+// channel (such as "http" or "cli"). Adapters that authenticate callers
+// themselves use it; the others use Guard.Authenticate.
+func WithPrincipal(ctx context.Context, p Principal, channel string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, session{principal: p, channel: channel})
+}
+
+// PrincipalFrom returns the caller stored in ctx.
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	s, ok := ctx.Value(sessionKey{}).(session)
+	return s.principal, ok
+}
+
+func channelFrom(ctx context.Context) string {
+	s, _ := ctx.Value(sessionKey{}).(session)
+	return s.channel
+}
@@ -0,0 +1,82 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+
+	"financial-ledger/auth"
+)
+
+func TestParseRole(t *testing.T) {
+	for input, want := range map[string]auth.Role{
+		"admin":     auth.RoleAdmin,
+		"Teller":    auth.RoleTeller,
+		" auditor ": auth.RoleAuditor,
+		"read-only": auth.RoleReadOnly,
+		"readonly":  auth.RoleReadOnly,
+	} {
+		got, err := auth.ParseRole(input)
+		if err != nil || got != want {
+			t.Errorf("ParseRole(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := auth.ParseRole("superuser"); err == nil {
+		t.Error("expected an unknown role to be rejected")
+	}
+}
+
+func TestPrincipal(t *testing.T) {
+	t.Run("RolesGrantPermissions", func(t *testing.T) {
+		tests := []struct {
+			role    auth.Role
+			allowed []auth.Permission
+			denied  []auth.Permission
+		}{
+			{auth.RoleAdmin, []auth.Permission{auth.PermMoveMoney, auth.PermAudit, auth.PermAdmin}, nil},
+			{auth.RoleTeller, []auth.Permission{auth.PermRead, auth.PermReadPersonalData, auth.PermManageAccounts, auth.PermMoveMoney}, []auth.Permission{auth.PermAudit, auth.PermAdmin}},
+			{auth.RoleAuditor, []auth.Permission{auth.PermRead, auth.PermAudit}, []auth.Permission{auth.PermMoveMoney, auth.PermReadPersonalData}},
+			{auth.RoleReadOnly, []auth.Permission{auth.PermRead}, []auth.Permission{auth.PermMoveMoney, auth.PermManageAccounts, auth.PermAudit}},
+		}
+		for _, tt := range tests {
+			p := auth.Principal{ID: "p", Roles: []auth.Role{tt.role}}
+			for _, perm := range tt.allowed {
+				if !p.Can(perm) {
+					t.Errorf("%s should grant %s", tt.role, perm)
+				}
+			}
+			for _, perm := range tt.denied {
+				if p.Can(perm) {
+					t.Errorf("%s should not grant %s", tt.role, perm)
+				}
+			}
+		}
+	})
+
+	t.Run("RolesCombine", func(t *testing.T) {
+		p := auth.Principal{Roles: []auth.Role{auth.RoleTeller, auth.RoleAuditor}}
+		if !p.Can(auth.PermMoveMoney) || !p.Can(auth.PermAudit) {
+			t.Error("a principal should hold the permissions of all its roles")
+		}
+	})
+
+	t.Run("AccountScope", func(t *testing.T) {
+		unscoped := auth.Principal{Roles: []auth.Role{auth.RoleTeller}}
+		if unscoped.Scoped() || !unscoped.Owns("anything") {
+			t.Error("a principal without accounts should act on every account")
+		}
+		scoped := auth.Principal{Roles: []auth.Role{auth.RoleTeller}, Accounts: []string{"acc-1"}}
+		if !scoped.Scoped() || !scoped.Owns("acc-1") || scoped.Owns("acc-10") {
+			t.Errorf("expected the principal to own exactly acc-1, got %+v", scoped)
+		}
+	})
+
+	t.Run("Context", func(t *testing.T) {
+		if _, ok := auth.PrincipalFrom(context.Background()); ok {
+			t.Error("a bare context should carry no principal")
+		}
+		ctx := auth.WithPrincipal(context.Background(), auth.Principal{ID: "teller-1"}, "http")
+		if p, ok := auth.PrincipalFrom(ctx); !ok || p.ID != "teller-1" {
+			t.Errorf("expected teller-1 from context, got %+v, %v", p, ok)
+		}
+	})
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"financial-ledger/app"
 	"financial-ledger/shared"
@@ -15,6 +16,29 @@ import (
 var (
 	accountID string
 	balances  []string
+	idemKey   string
+
+	// Personal data about the account holder, stored encrypted per subject
+	subjectID    string
+	ownerName    string
+	ownerAddress string
+	ownerNotes   string
+	ownerIDs     []string
+
+	// Customers who hold a new account
+	holderIDs []string
+
+	// Directory listing filters
+	listPrefix     string
+	listIDs        []string
+	listCurrency   string
+	listMinBalance string
+	listMaxBalance string
+	listStatus     string
+	listSortBy     string
+	listDescending bool
+	listOffset     int
+	listLimit      int
 )
 
 // accountCmd represents the account command group
@@ -31,7 +55,11 @@ var createCmd = &cobra.Command{
 	Long: `Creates a new financial account with an optional ID and initial balances.
 If --id is not provided, a new UUID will be generated.
 Initial balances can be set using the --balance flag multiple times,
-e.g., --balance USD:100.50 --balance EUR:50`,
+e.g., --balance USD:100.50 --balance EUR:50
+Personal data (--owner, --address, --notes, --identifier) is stored encrypted under the
+key of its data subject (--subject, defaulting to the account ID).
+--holder links the account to a registered customer; name several customers
+for a joint account.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Generate ID if not provided
 		if accountID == "" {
@@ -44,21 +72,26 @@ e.g., --balance USD:100.50 --balance EUR:50`,
 			parts := strings.SplitN(b, ":", 2)
 			if len(parts) != 2 {
 				exitWithError(fmt.Errorf("invalid balance format: %q. Use CURRENCY:AMOUNT (e.g., USD:100.50)", b))
+				return
 			}
 			currency := shared.Currency(strings.ToUpper(parts[0]))
 			// Basic validation - could be more robust
 			if currency != shared.USD && currency != shared.EUR && currency != shared.GBP {
 				exitWithError(fmt.Errorf("invalid currency code: %q. Supported: USD, EUR, GBP", currency))
+				return
 			}
 			amount, err := decimal.NewFromString(parts[1])
 			if err != nil {
 				exitWithError(fmt.Errorf("invalid amount format for %s: %q. %v", currency, parts[1], err))
+				return
 			}
 			if amount.IsNegative() {
 				exitWithError(fmt.Errorf("initial balance cannot be negative: %s %s", currency, amount))
+				return
 			}
 			if _, exists := initialBalancesMap[currency]; exists {
 				exitWithError(fmt.Errorf("duplicate initial balance provided for currency: %s", currency))
+				return
 			}
 			initialBalancesMap[currency] = amount
 		}
@@ -66,13 +99,24 @@ e.g., --balance USD:100.50 --balance EUR:50`,
 		createCmdInput := app.CreateAccountCommand{
 			AccountID:       accountID, // Pass the user-provided ID (or empty string)
 			InitialBalances: initialBalancesMap,
+			SubjectID:       subjectID,
+			Details:         personalDetailsFromFlags(),
+			Holders:         holderIDs,
+			IdempotencyKey:  idemKey,
+			Metadata:        cliMetadata(),
 		}
 
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
 		// The service now handles ID generation if cmd.AccountID is empty and returns the ID used
-		accountIDUsed, err := accountService.CreateAccount(createCmdInput)
+		accountIDUsed, err := ledger.CreateAccount(ctx, createCmdInput)
 		if err != nil {
 			// Check for specific domain errors if needed, e.g., account exists
 			exitWithError(fmt.Errorf("failed to create account: %w", err))
+			return
 		}
 
 		fmt.Printf("Account '%s' created successfully.\n", accountIDUsed)
@@ -87,6 +131,203 @@ e.g., --balance USD:100.50 --balance EUR:50`,
 	},
 }
 
+// updateDetailsCmd represents the account update-details command
+var updateDetailsCmd = &cobra.Command{
+	Use:   "update-details",
+	Short: "Replace the personal data held for an account",
+	Run: func(cmd *cobra.Command, args []string) {
+		details := personalDetailsFromFlags()
+		if details == nil {
+			exitWithError(fmt.Errorf("at least one of --owner, --address, --notes or --identifier is required"))
+			return
+		}
+
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		err = ledger.UpdateAccountDetails(ctx, app.UpdateAccountDetailsCommand{
+			AccountID:      accountID,
+			SubjectID:      subjectID,
+			Details:        *details,
+			IdempotencyKey: idemKey,
+			Metadata:       cliMetadata(),
+		})
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to update account details: %w", err))
+			return
+		}
+		fmt.Printf("Personal details updated for account '%s'.\n", accountID)
+	},
+}
+
+// showCmd represents the account show command
+var showCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the personal data held for an account",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		details, err := ledger.GetAccountDetails(ctx, app.GetAccountDetailsQuery{AccountID: accountID})
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to get account details: %w", err))
+			return
+		}
+
+		fmt.Printf("Account '%s'\n", details.AccountID)
+		if details.SubjectID == "" {
+			fmt.Println("  (No personal data on file)")
+			return
+		}
+		fmt.Printf("  Subject: %s\n", details.SubjectID)
+		fmt.Printf("  Owner:   %s\n", details.OwnerName)
+		fmt.Printf("  Address: %s\n", details.Address)
+		fmt.Printf("  Notes:   %s\n", details.Notes)
+		if len(details.Identifiers) > 0 {
+			fmt.Printf("  IDs:     %s\n", strings.Join(details.Identifiers, ", "))
+		}
+		if details.Redacted {
+			fmt.Println("  (Subject has been forgotten; personal data is no longer recoverable)")
+		}
+	},
+}
+
+// forgetCmd represents the account forget command
+var forgetCmd = &cobra.Command{
+	Use:   "forget",
+	Short: "Erase a data subject's personal data (right to erasure)",
+	Long: `Destroys the key that the subject's personal data is encrypted under. The
+data becomes unrecoverable everywhere, including in event history and backups,
+while balances and the audit trail are unaffected. This cannot be undone.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		record, err := ledger.ForgetSubject(ctx, app.ForgetSubjectCommand{
+			SubjectID: subjectID,
+			Metadata:  cliMetadata(),
+		})
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to forget subject: %w", err))
+			return
+		}
+		fmt.Printf("Subject '%s' forgotten at %s: %d keys destroyed.\n",
+			record.SubjectID, record.Timestamp.Format(time.RFC3339), record.KeysDestroyed)
+	},
+}
+
+// closeCmd represents the account close command
+var closeCmd = &cobra.Command{
+	Use:   "close",
+	Short: "Request the closure of an account",
+	Long: `Requests that an account be closed. The closure is held until a second user
+approves it with 'approval approve', and every balance must be zero by then. A
+closed account accepts no further transactions.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		approval, err := ledger.RequestAccountClosure(ctx, app.CloseAccountCommand{
+			AccountID:      accountID,
+			IdempotencyKey: idemKey,
+			Metadata:       cliMetadata(),
+		})
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to request account closure: %w", err))
+			return
+		}
+		fmt.Printf("Closure of account '%s' requested; it needs a second user's approval.\n", accountID)
+		printApproval(approval)
+	},
+}
+
+// listCmd represents the account list command
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List and search accounts",
+	Long: `Lists accounts from the account directory. Accounts can be searched by ID
+prefix or exact ID, filtered by a currency they hold (optionally within a balance
+range) and by status, and sorted by ID, creation time, last activity or balance.
+An account is dormant once it has had no activity for a year. Closed accounts
+have status closed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		query := app.ListAccountsQuery{
+			Prefix:     listPrefix,
+			IDs:        listIDs,
+			Status:     app.AccountStatus(listStatus),
+			SortBy:     app.AccountSortField(listSortBy),
+			Descending: listDescending,
+			Offset:     listOffset,
+			Limit:      listLimit,
+		}
+		if listCurrency != "" {
+			c := shared.Currency(strings.ToUpper(listCurrency))
+			if !isValidCurrency(c) {
+				exitWithError(fmt.Errorf("invalid currency code: %q. Supported: USD, EUR, GBP", c))
+				return
+			}
+			query.Currency = &c
+		}
+		for _, bound := range []struct {
+			flag, value string
+			dst         **decimal.Decimal
+		}{{"--min-balance", listMinBalance, &query.MinBalance}, {"--max-balance", listMaxBalance, &query.MaxBalance}} {
+			if bound.value == "" {
+				continue
+			}
+			amount, err := decimal.NewFromString(bound.value)
+			if err != nil {
+				exitWithError(fmt.Errorf("invalid %s %q: %w", bound.flag, bound.value, err))
+				return
+			}
+			*bound.dst = &amount
+		}
+
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		page, err := ledger.ListAccounts(ctx, query)
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to list accounts: %w", err))
+			return
+		}
+		if len(page.Accounts) == 0 {
+			fmt.Printf("No accounts found (%d matched).\n", page.Total)
+			return
+		}
+
+		first := min(max(listOffset, 0), page.Total) + 1
+		fmt.Printf("Accounts %d-%d of %d:\n", first, first+len(page.Accounts)-1, page.Total)
+		for _, a := range page.Accounts {
+			fmt.Printf("  %s  [%s]  v%d  last activity %s  %s",
+				a.AccountID, a.Status, a.Version, a.LastActivity.Format(time.RFC3339), formatBalances(a.Balances))
+			if len(a.Holders) > 0 {
+				fmt.Printf("  held by %s", strings.Join(a.Holders, ", "))
+			}
+			fmt.Println()
+		}
+	},
+}
+
+// personalDetailsFromFlags returns the personal data given on the command line,
+// or nil if none was given.
+func personalDetailsFromFlags() *app.PersonalDetails {
+	if ownerName == "" && ownerAddress == "" && ownerNotes == "" && len(ownerIDs) == 0 {
+		return nil
+	}
+	return &app.PersonalDetails{OwnerName: ownerName, Address: ownerAddress, Notes: ownerNotes, Identifiers: ownerIDs}
+}
+
 func init() {
 	// Add accountCmd to root command
 	rootCmd.AddCommand(accountCmd)
@@ -97,4 +338,47 @@ func init() {
 	// Define flags for createCmd
 	createCmd.Flags().StringVar(&accountID, "id", "", "Optional unique ID for the account (UUID generated if empty)")
 	createCmd.Flags().StringSliceVarP(&balances, "balance", "b", []string{}, "Initial balance(s) in CURRENCY:AMOUNT format (e.g., USD:100.50). Can be used multiple times.")
+	createCmd.Flags().StringVar(&idemKey, "idempotency-key", "", "Optional key that makes retries of this command safe")
+	createCmd.Flags().StringVar(&subjectID, "subject", "", "Data subject the personal data belongs to (defaults to the account ID)")
+	createCmd.Flags().StringVar(&ownerName, "owner", "", "Optional account holder name")
+	createCmd.Flags().StringVar(&ownerAddress, "address", "", "Optional account holder address")
+	createCmd.Flags().StringVar(&ownerNotes, "notes", "", "Optional notes about the account holder")
+	createCmd.Flags().StringSliceVar(&ownerIDs, "identifier", nil, "Optional passport, national ID or registration number of the holder. Can be used multiple times.")
+	createCmd.Flags().StringSliceVar(&holderIDs, "holder", nil, "ID of a customer who holds the account. Can be used multiple times for a joint account.")
+
+	// Add and define flags for the personal data commands
+	accountCmd.AddCommand(updateDetailsCmd)
+	updateDetailsCmd.Flags().StringVar(&accountID, "id", "", "Account ID (required)")
+	updateDetailsCmd.Flags().StringVar(&subjectID, "subject", "", "Data subject (defaults to the account's current subject)")
+	updateDetailsCmd.Flags().StringVar(&ownerName, "owner", "", "Account holder name")
+	updateDetailsCmd.Flags().StringVar(&ownerAddress, "address", "", "Account holder address")
+	updateDetailsCmd.Flags().StringVar(&ownerNotes, "notes", "", "Notes about the account holder")
+	updateDetailsCmd.Flags().StringSliceVar(&ownerIDs, "identifier", nil, "Passport, national ID or registration number of the holder. Can be used multiple times.")
+	updateDetailsCmd.Flags().StringVar(&idemKey, "idempotency-key", "", "Optional key that makes retries of this command safe")
+	updateDetailsCmd.MarkFlagRequired("id")
+
+	accountCmd.AddCommand(showCmd)
+	showCmd.Flags().StringVar(&accountID, "id", "", "Account ID (required)")
+	showCmd.MarkFlagRequired("id")
+
+	accountCmd.AddCommand(forgetCmd)
+	forgetCmd.Flags().StringVar(&subjectID, "subject", "", "Data subject to forget (required)")
+	forgetCmd.MarkFlagRequired("subject")
+
+	accountCmd.AddCommand(closeCmd)
+	closeCmd.Flags().StringVar(&accountID, "id", "", "Account ID (required)")
+	closeCmd.Flags().StringVar(&idemKey, "idempotency-key", "", "Optional key that makes retries of this command safe")
+	_ = closeCmd.MarkFlagRequired("id")
+
+	accountCmd.AddCommand(listCmd)
+	listCmd.Flags().StringVar(&listPrefix, "prefix", "", "Only accounts whose ID starts with this prefix")
+	listCmd.Flags().StringSliceVar(&listIDs, "id", nil, "Only these account IDs (repeatable)")
+	listCmd.Flags().StringVar(&listCurrency, "currency", "", "Only accounts holding a non-zero balance in this currency")
+	listCmd.Flags().StringVar(&listMinBalance, "min-balance", "", "Only accounts with at least this balance in --currency")
+	listCmd.Flags().StringVar(&listMaxBalance, "max-balance", "", "Only accounts with at most this balance in --currency")
+	listCmd.Flags().StringVar(&listStatus, "status", "", "Only accounts with this status (active, dormant, closed)")
+	listCmd.Flags().StringVar(&listSortBy, "sort", "id", "Sort by id, created, activity or balance (needs --currency)")
+	listCmd.Flags().BoolVar(&listDescending, "desc", false, "Sort in descending order")
+	listCmd.Flags().IntVar(&listOffset, "offset", 0, "Number of accounts to skip")
+	listCmd.Flags().IntVar(&listLimit, "limit", 0, "Maximum number of accounts to show (0 for no limit)")
 }
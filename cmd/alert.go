@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"financial-ledger/app"
+	"financial-ledger/store"
+)
+
+var (
+	alertID      string
+	alertStatus  string
+	alertAccount string
+	alertRule    string
+)
+
+// alertCmd represents the alert command group
+var alertCmd = &cobra.Command{
+	Use:   "alert",
+	Short: "Review transaction monitoring alerts",
+	Long: `The rules in the file named by LEDGER_MONITORING_RULES are evaluated against
+every committed event; an alert is raised whenever one matches. Alerts start
+open and are escalated for further investigation, cleared as not suspicious,
+or reported to the authorities. Cleared and reported alerts are closed.
+Every disposition needs a note, given with --reason.`,
+}
+
+// alertListCmd represents the alert list command
+var alertListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List alerts, by default the open ones",
+	Run: func(cmd *cobra.Command, args []string) {
+		status := store.AlertStatus(alertStatus)
+		switch status {
+		case store.AlertOpen, store.AlertEscalated, store.AlertCleared, store.AlertReported:
+		case "all":
+			status = ""
+		default:
+			exitWithError(fmt.Errorf("unknown status %q: use open, escalated, cleared, reported or all", alertStatus))
+			return
+		}
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		alerts, err := ledger.ListAlerts(ctx, app.ListAlertsQuery{Status: status, AccountID: alertAccount, RuleID: alertRule})
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to list alerts: %w", err))
+			return
+		}
+		if len(alerts) == 0 {
+			if !monitoringEnabled {
+				fmt.Println("No alerts: no monitoring rules are configured; set LEDGER_MONITORING_RULES.")
+				return
+			}
+			fmt.Println("No alerts.")
+			return
+		}
+		for _, a := range alerts {
+			printAlert(a, false)
+		}
+	},
+}
+
+// alertShowCmd represents the alert show command
+var alertShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show an alert with its triggering events and dispositions",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		alert, err := ledger.GetAlert(ctx, alertID)
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to get alert: %w", err))
+			return
+		}
+		printAlert(alert, true)
+	},
+}
+
+// newAlertDispositionCmd returns the alert subcommand that sets status.
+func newAlertDispositionCmd(use string, status store.AlertStatus, short string) *cobra.Command {
+	c := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Run: func(cmd *cobra.Command, args []string) {
+			if cliReason == "" {
+				exitWithError(fmt.Errorf("alert %s needs a note: pass --reason", use))
+				return
+			}
+			ctx, err := cliContext()
+			if err != nil {
+				exitWithError(err)
+				return
+			}
+			alert, err := ledger.DispositionAlert(ctx, app.DispositionAlertCommand{
+				AlertID:  alertID,
+				Status:   status,
+				Note:     cliReason,
+				Metadata: cliMetadata(),
+			})
+			if err != nil {
+				exitWithError(fmt.Errorf("failed to %s alert: %w", use, err))
+				return
+			}
+			fmt.Printf("Alert %s is now %s.\n", alert.ID, alert.Status)
+		},
+	}
+	c.Flags().StringVar(&alertID, "id", "", "Alert ID (required)")
+	c.MarkFlagRequired("id")
+	return c
+}
+
+func printAlert(a store.Alert, detailed bool) {
+	fmt.Printf("%s  %-9s %s on %s: %s\n", a.ID, a.Status, a.RuleID, a.AccountID, a.Description)
+	fmt.Printf("    Detected at %s\n", a.DetectedAt.Format(time.RFC3339))
+	if !detailed {
+		return
+	}
+	fmt.Printf("    Rule type: %s\n", a.RuleType)
+	fmt.Printf("    Events: %s\n", strings.Join(a.EventIDs, ", "))
+	for _, d := range a.Dispositions {
+		fmt.Printf("    %s  %s by %s: %s\n", d.At.Format(time.RFC3339), d.Status, d.Actor, d.Note)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(alertCmd)
+
+	alertCmd.AddCommand(alertListCmd)
+	alertListCmd.Flags().StringVar(&alertStatus, "status", "open", "Only alerts with this status: open, escalated, cleared, reported or all")
+	alertListCmd.Flags().StringVar(&alertAccount, "account", "", "Only alerts on this account")
+	alertListCmd.Flags().StringVar(&alertRule, "rule", "", "Only alerts raised by this rule")
+
+	alertCmd.AddCommand(alertShowCmd)
+	alertShowCmd.Flags().StringVar(&alertID, "id", "", "Alert ID (required)")
+	alertShowCmd.MarkFlagRequired("id")
+
+	alertCmd.AddCommand(newAlertDispositionCmd("escalate", store.AlertEscalated, "Escalate an alert for further investigation"))
+	alertCmd.AddCommand(newAlertDispositionCmd("clear", store.AlertCleared, "Close an alert as not suspicious"))
+	alertCmd.AddCommand(newAlertDispositionCmd("report", store.AlertReported, "Close an alert as reported to the authorities"))
+}
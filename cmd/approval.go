@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+
+	"financial-ledger/app"
+	"financial-ledger/domain"
+	"financial-ledger/shared"
+)
+
+var (
+	approvalID      string
+	approvalStatus  string
+	approvalAccount string
+)
+
+// approvalCmd represents the approval command group
+var approvalCmd = &cobra.Command{
+	Use:   "approval",
+	Short: "Review commands held for a second user's approval",
+	Long: `Account closures, reversals and transfers above the threshold set in
+LEDGER_APPROVAL_THRESHOLDS are not executed straight away. They are held as
+approvals until a user other than the requester approves them, at which point
+they are executed. Approvals that are neither approved nor rejected expire
+after three days.`,
+}
+
+// approvalListCmd represents the approval list command
+var approvalListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List approvals, by default the pending ones",
+	Run: func(cmd *cobra.Command, args []string) {
+		status := domain.ApprovalStatus(approvalStatus)
+		switch status {
+		case domain.ApprovalPending, domain.ApprovalExecuted,
+			domain.ApprovalFailed, domain.ApprovalRejected, domain.ApprovalExpired:
+		case "all":
+			status = ""
+		default:
+			exitWithError(fmt.Errorf("unknown status %q: use pending, executed, failed, rejected, expired or all", approvalStatus))
+			return
+		}
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		approvals, err := ledger.ListApprovals(ctx, app.ListApprovalsQuery{Status: status, AccountID: approvalAccount})
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to list approvals: %w", err))
+			return
+		}
+		if len(approvals) == 0 {
+			fmt.Println("No approvals.")
+			return
+		}
+		for _, a := range approvals {
+			printApproval(a)
+		}
+	},
+}
+
+// errApprovalWithoutAuth refuses approval decisions under --no-auth. The
+// requester and the approver would then be only what --actor says, so anyone
+// could approve their own request under another name.
+var errApprovalWithoutAuth = errors.New("approvals need authenticated users: set LEDGER_AUTH_CONFIG")
+
+// approvalApproveCmd represents the approval approve command
+var approvalApproveCmd = &cobra.Command{
+	Use:   "approve",
+	Short: "Approve a held command, executing it once it has every approval it needs",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !authConfigured {
+			exitWithError(errApprovalWithoutAuth)
+			return
+		}
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		approval, err := ledger.Approve(ctx, app.ApproveCommand{ApprovalID: approvalID, Metadata: cliMetadata()})
+		if err != nil {
+			if approval.ID != "" {
+				printApproval(approval)
+			}
+			exitWithError(fmt.Errorf("failed to approve: %w", err))
+			return
+		}
+		switch approval.Status {
+		case domain.ApprovalExecuted:
+			fmt.Printf("Approved and executed: %s.\n", approval.Summary)
+		default:
+			fmt.Printf("Approval recorded: %d of %d.\n", len(approval.Approvals), approval.RequiredApprovals)
+		}
+		printApproval(approval)
+	},
+}
+
+// approvalRejectCmd represents the approval reject command
+var approvalRejectCmd = &cobra.Command{
+	Use:   "reject",
+	Short: "Reject a held command; the reason is given with --reason",
+	Run: func(cmd *cobra.Command, args []string) {
+		if cliReason == "" {
+			exitWithError(errors.New("a rejection needs a reason: pass --reason"))
+			return
+		}
+		if !authConfigured {
+			exitWithError(errApprovalWithoutAuth)
+			return
+		}
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		approval, err := ledger.RejectApproval(ctx, app.RejectApprovalCommand{
+			ApprovalID: approvalID,
+			Reason:     cliReason,
+			Metadata:   cliMetadata(),
+		})
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to reject: %w", err))
+			return
+		}
+		fmt.Printf("Rejected: %s.\n", approval.Summary)
+	},
+}
+
+func printApproval(a domain.Approval) {
+	fmt.Printf("%s  %-8s %s\n", a.ID, a.Status, a.Summary)
+	fmt.Printf("    Requested by %s at %s", a.RequestedBy, a.RequestedAt.Format(time.RFC3339))
+	if a.Status == domain.ApprovalPending {
+		fmt.Printf(", expires %s", a.ExpiresAt.Format(time.RFC3339))
+	}
+	fmt.Println()
+	approvers := make([]string, len(a.Approvals))
+	for i, d := range a.Approvals {
+		approvers[i] = d.Actor
+	}
+	fmt.Printf("    Approvals: %d of %d", len(a.Approvals), a.RequiredApprovals)
+	if len(approvers) > 0 {
+		fmt.Printf(" (%s)", strings.Join(approvers, ", "))
+	}
+	fmt.Println()
+	switch {
+	case a.Status == domain.ApprovalRejected:
+		fmt.Printf("    Rejected by %s: %s\n", a.DecidedBy, a.Reason)
+	case a.Reason != "":
+		fmt.Printf("    Reason: %s\n", a.Reason)
+	}
+}
+
+// parseApprovalThresholds reads thresholds in the form USD=10000,EUR=8000.
+func parseApprovalThresholds(s string) (map[shared.Currency]decimal.Decimal, error) {
+	thresholds := make(map[shared.Currency]decimal.Decimal)
+	for _, entry := range strings.Split(s, ",") {
+		cur, amt, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid approval threshold %q: use CURRENCY=AMOUNT", entry)
+		}
+		currency := shared.Currency(strings.ToUpper(cur))
+		if !isValidCurrency(currency) {
+			return nil, fmt.Errorf("invalid currency code in approval threshold %q. Supported: USD, EUR, GBP", entry)
+		}
+		amount, err := decimal.NewFromString(amt)
+		if err != nil || amount.IsNegative() {
+			return nil, fmt.Errorf("invalid amount in approval threshold %q", entry)
+		}
+		thresholds[currency] = amount
+	}
+	return thresholds, nil
+}
+
+func init() {
+	rootCmd.AddCommand(approvalCmd)
+
+	approvalCmd.AddCommand(approvalListCmd)
+	approvalListCmd.Flags().StringVar(&approvalStatus, "status", "pending", "Only approvals with this status: pending, executed, failed, rejected, expired or all")
+	approvalListCmd.Flags().StringVar(&approvalAccount, "account", "", "Only approvals touching this account")
+
+	approvalCmd.AddCommand(approvalApproveCmd)
+	approvalApproveCmd.Flags().StringVar(&approvalID, "id", "", "Approval ID (required)")
+	approvalApproveCmd.MarkFlagRequired("id")
+
+	approvalCmd.AddCommand(approvalRejectCmd)
+	approvalRejectCmd.Flags().StringVar(&approvalID, "id", "", "Approval ID (required)")
+	approvalRejectCmd.MarkFlagRequired("id")
+}
@@ -0,0 +1,270 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"financial-ledger/auth"
+	"financial-ledger/merkle"
+	"financial-ledger/store"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+var (
+	proofEventID  string
+	proofFile     string
+	publishedRoot string
+	keyOut        string
+	denialsSince  string
+)
+
+// auditCmd represents the audit command group
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Audit the integrity of the ledger",
+	Long:  `Provides commands to prove that recorded history has not been edited.`,
+}
+
+// verifyCmd represents the audit verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify the hash chain of every stream and of the global log",
+	Long: `Walks every account stream and the global event log, recomputes each event's
+hash and its link to the previous event, and reports the first broken link.
+Every stored checkpoint root is then recomputed from the global log. When
+verification keys are configured, every event and checkpoint must also carry
+a valid signature from one of them.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		// The chain is read straight from the store, so check the caller may audit first.
+		if _, err := ledger.Authorize(ctx, auth.PermAudit, "VerifyChain"); err != nil {
+			exitWithError(err)
+			return
+		}
+		var opts []store.VerifyOption
+		if verificationKeys != nil {
+			opts = append(opts, store.WithSignatureKeys(verificationKeys))
+		}
+		report, brk, err := store.VerifyChain(ctx, eventStore, opts...)
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to verify ledger: %w", err))
+			return
+		}
+		if brk != nil {
+			exitWithError(fmt.Errorf("ledger integrity check FAILED: %s", brk))
+			return
+		}
+
+		checkpoints, err := ledger.VerifyCheckpoints(ctx)
+		if err != nil {
+			exitWithError(fmt.Errorf("ledger integrity check FAILED: %w", err))
+			return
+		}
+
+		fmt.Printf("Ledger integrity verified: %d streams, %d events, global head at position %d, %d checkpoints.\n",
+			report.Streams, report.Events, report.Head, checkpoints)
+		if verificationKeys != nil {
+			fmt.Printf("All %d events and %d checkpoints carry valid signatures from trusted keys.\n", report.Signed, checkpoints)
+		} else {
+			fmt.Println("Signatures were not checked: LEDGER_VERIFY_KEYS and LEDGER_SIGNING_KEY are unset.")
+		}
+	},
+}
+
+// keygenCmd represents the audit keygen command
+var keygenCmd = &cobra.Command{
+	Use:   "keygen",
+	Short: "Generate an Ed25519 key pair for signing the ledger",
+	Long: `Writes a new Ed25519 private key (PEM, mode 0600) and its public key next to it
+with a .pub suffix. Point LEDGER_SIGNING_KEY at the private key to sign events and
+checkpoints, and give the public key to auditors via LEDGER_VERIFY_KEYS.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		keyID, err := store.GenerateEd25519KeyFiles(keyOut, keyOut+".pub")
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to generate signing key: %w", err))
+			return
+		}
+		fmt.Printf("Signing key written to %s (public key %s.pub), key ID %s\n", keyOut, keyOut, keyID)
+	},
+}
+
+// checkpointCmd represents the audit checkpoint command
+var checkpointCmd = &cobra.Command{
+	Use:   "checkpoint",
+	Short: "Record a Merkle-root checkpoint over the global log",
+	Long: `Computes the Merkle root over every event committed so far and stores it as a
+checkpoint. Publish the root to let counterparties verify inclusion proofs.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		checkpoint, err := ledger.CreateCheckpoint(ctx)
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to create checkpoint: %w", err))
+			return
+		}
+
+		fmt.Printf("Checkpoint at tree size %d\n", checkpoint.TreeSize)
+		fmt.Printf("  Root:      %s\n", checkpoint.RootHash)
+		fmt.Printf("  Timestamp: %s\n", checkpoint.Timestamp.Format("2006-01-02 15:04:05 MST"))
+		if checkpoint.KeyID != "" {
+			fmt.Printf("  Key ID:    %s\n", checkpoint.KeyID)
+		}
+	},
+}
+
+// proofCmd represents the audit proof command
+var proofCmd = &cobra.Command{
+	Use:   "proof",
+	Short: "Print an inclusion proof for an event",
+	Long: `Prints, as JSON, a proof that the given event is included in the tree summarised
+by the latest checkpoint. The proof can be checked offline with 'audit verify-proof'.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		eventID, err := uuid.Parse(proofEventID)
+		if err != nil {
+			exitWithError(fmt.Errorf("invalid event ID '%s': %w", proofEventID, err))
+			return
+		}
+
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		proof, err := ledger.GetInclusionProof(ctx, eventID)
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to build inclusion proof: %w", err))
+			return
+		}
+
+		out, err := json.MarshalIndent(proof, "", "  ")
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to encode proof: %w", err))
+			return
+		}
+		fmt.Println(string(out))
+	},
+}
+
+// verifyProofCmd represents the audit verify-proof command
+var verifyProofCmd = &cobra.Command{
+	Use:   "verify-proof",
+	Short: "Verify an inclusion proof against a published root",
+	Long: `Checks a proof produced by 'audit proof' against a published checkpoint root.
+Only the proof file and the root are needed; the ledger itself is not consulted.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := os.ReadFile(proofFile)
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to read proof file: %w", err))
+			return
+		}
+
+		// Accept both the full 'audit proof' output and a bare inclusion proof.
+		var wrapped struct {
+			Proof *merkle.InclusionProof `json:"proof"`
+		}
+		var proof merkle.InclusionProof
+		if err := json.Unmarshal(data, &wrapped); err == nil && wrapped.Proof != nil {
+			proof = *wrapped.Proof
+		} else if err := json.Unmarshal(data, &proof); err != nil {
+			exitWithError(fmt.Errorf("failed to parse proof file: %w", err))
+			return
+		}
+
+		if err := proof.Verify(publishedRoot); err != nil {
+			exitWithError(fmt.Errorf("proof verification FAILED: %w", err))
+			return
+		}
+		fmt.Printf("Proof verified: event %s is leaf %d of %d under root %s.\n",
+			proof.EventID, proof.LeafIndex, proof.TreeSize, proof.RootHash)
+	},
+}
+
+// denialsCmd represents the audit denials command
+var denialsCmd = &cobra.Command{
+	Use:   "denials",
+	Short: "List attempts to do something the caller was not allowed to",
+	Long: `Lists recorded access denials: failed authentications and operations refused
+because of the caller's roles or accounts, with who tried, through which
+channel, and what they attempted.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var since time.Time
+		if denialsSince != "" {
+			d, err := time.ParseDuration(denialsSince)
+			if err != nil {
+				exitWithError(fmt.Errorf("invalid --since %q: %w", denialsSince, err))
+				return
+			}
+			since = time.Now().Add(-d)
+		}
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		denials, err := ledger.ListDenials(ctx, since)
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to list denials: %w", err))
+			return
+		}
+		if len(denials) == 0 {
+			fmt.Println("No access denials recorded.")
+			return
+		}
+		for _, d := range denials {
+			actor := d.Actor
+			if actor == "" {
+				actor = "(unauthenticated)"
+			}
+			fmt.Printf("%s  %s via %s: %s", d.Timestamp.Format(time.RFC3339), actor, d.Channel, d.Operation)
+			if d.Detail != "" {
+				fmt.Printf(" %s", d.Detail)
+			}
+			if len(d.AccountIDs) > 0 {
+				fmt.Printf(" on %s", strings.Join(d.AccountIDs, ", "))
+			}
+			fmt.Printf("\n    %s\n", d.Reason)
+		}
+	},
+}
+
+func init() {
+	// Add auditCmd to root command
+	rootCmd.AddCommand(auditCmd)
+
+	// Add subcommands to auditCmd
+	auditCmd.AddCommand(verifyCmd)
+	auditCmd.AddCommand(checkpointCmd)
+	auditCmd.AddCommand(proofCmd)
+	auditCmd.AddCommand(verifyProofCmd)
+	auditCmd.AddCommand(keygenCmd)
+	auditCmd.AddCommand(denialsCmd)
+
+	// Flags for proofCmd
+	proofCmd.Flags().StringVar(&proofEventID, "event-id", "", "ID of the event to prove (required)")
+	proofCmd.MarkFlagRequired("event-id")
+
+	// Flags for verifyProofCmd
+	verifyProofCmd.Flags().StringVar(&proofFile, "proof", "", "Path to a proof file produced by 'audit proof' (required)")
+	verifyProofCmd.Flags().StringVar(&publishedRoot, "root", "", "Published checkpoint root hash, hex encoded (required)")
+	verifyProofCmd.MarkFlagRequired("proof")
+	verifyProofCmd.MarkFlagRequired("root")
+
+	// Flags for keygenCmd
+	keygenCmd.Flags().StringVar(&keyOut, "out", "", "Path of the private key file to write (required)")
+	keygenCmd.MarkFlagRequired("out")
+
+	// Flags for denialsCmd
+	denialsCmd.Flags().StringVar(&denialsSince, "since", "", "Only denials within this long ago (e.g. 24h); empty for all")
+}
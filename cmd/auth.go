@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"financial-ledger/auth"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	authID         string
+	authRoles      []string
+	authAccounts   []string
+	authSecretFile string
+	authIssuer     string
+	authAudience   string
+	authTTL        time.Duration
+)
+
+// authCmd represents the auth command group
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage API keys and tokens",
+	Long: `Callers authenticate with an API key or a JWT. Keys and JWT settings live in
+the JSON file named by LEDGER_AUTH_CONFIG; the CLI itself authenticates with
+LEDGER_API_KEY or LEDGER_TOKEN. Roles are admin, supervisor, teller, auditor, compliance and readonly,
+and --accounts confines a principal to the listed accounts.`,
+}
+
+// authAPIKeyCmd represents the auth apikey command
+var authAPIKeyCmd = &cobra.Command{
+	Use:   "apikey",
+	Short: "Generate an API key and its configuration entry",
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := auth.ParseRoles(authRoles); err != nil {
+			exitWithError(err)
+			return
+		}
+		key, hash, err := auth.GenerateAPIKey()
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to generate API key: %w", err))
+			return
+		}
+		entry, err := json.MarshalIndent(auth.APIKey{ID: authID, Hash: hash, Roles: authRoles, Accounts: authAccounts}, "", "  ")
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to encode configuration entry: %w", err))
+			return
+		}
+		fmt.Printf("API key (shown once): %s\n", key)
+		fmt.Printf("Add to \"apiKeys\" in the auth configuration:\n%s\n", entry)
+	},
+}
+
+// authTokenCmd represents the auth token command
+var authTokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Issue an HS256 JWT",
+	Long: `Signs a JWT with the HS256 secret in --secret-file, for deployments that mint
+their own tokens. The ledger accepts it if the same secret is configured as
+the auth configuration's jwt.hmacSecretFile.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := auth.ParseRoles(authRoles); err != nil {
+			exitWithError(err)
+			return
+		}
+		if authTTL <= 0 {
+			exitWithError(errors.New("--ttl must be positive"))
+			return
+		}
+		secret, err := os.ReadFile(authSecretFile)
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to read JWT secret: %w", err))
+			return
+		}
+		now := time.Now()
+		claims := auth.Claims{
+			Subject:   authID,
+			Issuer:    authIssuer,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(authTTL).Unix(),
+			Roles:     authRoles,
+			Accounts:  authAccounts,
+		}
+		if authAudience != "" {
+			claims.Audience = []string{authAudience}
+		}
+		token, err := auth.SignHS256([]byte(strings.TrimSpace(string(secret))), claims)
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to sign token: %w", err))
+			return
+		}
+		fmt.Println(token)
+	},
+}
+
+// authWhoAmICmd represents the auth whoami command
+var authWhoAmICmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Show who the CLI is authenticated as",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		if !authConfigured {
+			fmt.Println("Authentication is disabled with --no-auth; acting as an administrator.")
+			return
+		}
+		p, _ := auth.PrincipalFrom(ctx)
+		fmt.Printf("Principal: %s\n", p.ID)
+		fmt.Printf("  Roles:    %v\n", p.Roles)
+		if p.Scoped() {
+			fmt.Printf("  Accounts: %s\n", strings.Join(p.Accounts, ", "))
+		} else {
+			fmt.Println("  Accounts: all")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(authCmd)
+	authCmd.AddCommand(authAPIKeyCmd)
+	authCmd.AddCommand(authTokenCmd)
+	authCmd.AddCommand(authWhoAmICmd)
+
+	for _, c := range []*cobra.Command{authAPIKeyCmd, authTokenCmd} {
+		c.Flags().StringVar(&authID, "id", "", "Principal ID recorded as the actor on its commands (required)")
+		c.Flags().StringSliceVar(&authRoles, "roles", nil, "Comma-separated roles: admin, supervisor, teller, auditor, compliance, readonly (required)")
+		c.Flags().StringSliceVar(&authAccounts, "accounts", nil, "Comma-separated accounts the principal is confined to; empty for all")
+		c.MarkFlagRequired("id")
+		c.MarkFlagRequired("roles")
+	}
+	authTokenCmd.Flags().StringVar(&authSecretFile, "secret-file", "", "File holding the HS256 secret (required)")
+	authTokenCmd.Flags().StringVar(&authIssuer, "issuer", "", "Optional iss claim")
+	authTokenCmd.Flags().StringVar(&authAudience, "audience", "", "Optional aud claim")
+	authTokenCmd.Flags().DurationVar(&authTTL, "ttl", time.Hour, "How long the token is valid")
+	authTokenCmd.MarkFlagRequired("secret-file")
+}
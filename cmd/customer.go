@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"financial-ledger/app"
+	"financial-ledger/domain"
+	"financial-ledger/shared"
+)
+
+var (
+	customerID          string
+	customerName        string
+	customerIdentifiers []string
+	customerEmail       string
+	customerPhone       string
+	customerAddress     string
+	customerKYCTier     string
+	customerCurrency    string
+	customerIdemKey     string
+)
+
+// customerCmd represents the customer command group
+var customerCmd = &cobra.Command{
+	Use:   "customer",
+	Short: "Manage customers and the accounts they hold",
+	Long: `A customer is an account holder with a name, identifiers, contact details and
+a KYC tier (none, simplified, standard or enhanced). Accounts are linked to
+their customers when they are opened with 'account create --holder'; an
+account opened for several customers is held jointly. A customer's details
+are stored encrypted under the customer ID as data subject, so
+'account forget --subject <customer-id>' erases them.`,
+}
+
+// customerCreateCmd represents the customer create command
+var customerCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Register a customer",
+	Run: func(cmd *cobra.Command, args []string) {
+		tier, err := kycTierFromFlag()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		id, err := ledger.CreateCustomer(ctx, app.CreateCustomerCommand{
+			CustomerID:     customerID,
+			Details:        customerDetailsFromFlags(),
+			KYCTier:        tier,
+			IdempotencyKey: customerIdemKey,
+			Metadata:       cliMetadata(),
+		})
+		var held *app.ApprovalRequiredError
+		if errors.As(err, &held) {
+			fmt.Println("Registration of the customer needs a second user's approval.")
+			printApproval(held.Approval)
+			return
+		}
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to register customer: %w", err))
+			return
+		}
+		fmt.Printf("Customer '%s' registered.\n", id)
+	},
+}
+
+// customerUpdateCmd represents the customer update command
+var customerUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update a customer's details or KYC tier",
+	Long: `Sets a customer's KYC tier with --kyc-tier, and replaces all of its details
+if any of --name, --identifier, --email, --phone or --address is given. The
+name is required whenever the details are replaced.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		tier, err := kycTierFromFlag()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		update := app.UpdateCustomerCommand{CustomerID: customerID, KYCTier: tier, IdempotencyKey: customerIdemKey, Metadata: cliMetadata()}
+		for _, flag := range []string{"name", "identifier", "email", "phone", "address"} {
+			if cmd.Flags().Changed(flag) {
+				details := customerDetailsFromFlags()
+				update.Details = &details
+				break
+			}
+		}
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		if err := ledger.UpdateCustomer(ctx, update); err != nil {
+			exitWithError(fmt.Errorf("failed to update customer: %w", err))
+			return
+		}
+		fmt.Printf("Customer '%s' updated.\n", customerID)
+	},
+}
+
+// customerShowCmd represents the customer show command
+var customerShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show a customer's details",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		profile, err := ledger.GetCustomer(ctx, customerID)
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to get customer: %w", err))
+			return
+		}
+		printCustomer(*profile)
+	},
+}
+
+// customerListCmd represents the customer list command
+var customerListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List customers, oldest first",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		profiles, err := ledger.ListCustomers(ctx)
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to list customers: %w", err))
+			return
+		}
+		if len(profiles) == 0 {
+			fmt.Println("No customers.")
+			return
+		}
+		for _, p := range profiles {
+			fmt.Printf("  %s  %s  [KYC %s]\n", p.CustomerID, p.Name, p.KYCTier)
+		}
+	},
+}
+
+// customerAccountsCmd represents the customer accounts command
+var customerAccountsCmd = &cobra.Command{
+	Use:   "accounts",
+	Short: "Show a customer's accounts and consolidated balances",
+	Long: `Lists the accounts a customer holds, alone or jointly, with the balances of
+all of them added up per currency and in total in the reporting currency
+(--currency, default USD) at the current exchange rates. Joint accounts count
+in full for each of their holders.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		currency := shared.Currency(strings.ToUpper(customerCurrency))
+		if !isValidCurrency(currency) {
+			exitWithError(fmt.Errorf("invalid currency code: %q. Supported: USD, EUR, GBP", currency))
+			return
+		}
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		result, err := ledger.GetCustomerAccounts(ctx, app.GetCustomerAccountsQuery{CustomerID: customerID, ReportingCurrency: currency})
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to get customer accounts: %w", err))
+			return
+		}
+		if len(result.Accounts) == 0 {
+			fmt.Printf("Customer '%s' holds no accounts.\n", result.CustomerID)
+			return
+		}
+		fmt.Printf("Accounts of customer '%s':\n", result.CustomerID)
+		for _, a := range result.Accounts {
+			fmt.Printf("  %s  [%s]  %s", a.AccountID, a.Status, formatBalances(a.Balances))
+			if len(a.Holders) > 1 {
+				fmt.Printf("  (joint: %s)", strings.Join(a.Holders, ", "))
+			}
+			fmt.Println()
+		}
+		fmt.Printf("Consolidated: %s\n", formatBalances(result.Balances))
+
+		currencies := make([]shared.Currency, 0, len(result.Rates))
+		for c := range result.Rates {
+			if c != result.ReportingCurrency {
+				currencies = append(currencies, c)
+			}
+		}
+		sort.Slice(currencies, func(i, j int) bool { return currencies[i] < currencies[j] })
+		rates := make([]string, 0, len(currencies))
+		for _, c := range currencies {
+			rates = append(rates, fmt.Sprintf("%s/%s %s", c, result.ReportingCurrency, result.Rates[c]))
+		}
+		fmt.Printf("Total:        %s %s", result.Total.StringFixed(2), result.ReportingCurrency)
+		if len(rates) > 0 {
+			fmt.Printf(" (at %s)", strings.Join(rates, ", "))
+		}
+		fmt.Println()
+	},
+}
+
+func printCustomer(p app.CustomerProfile) {
+	fmt.Printf("Customer '%s'\n", p.CustomerID)
+	fmt.Printf("  Name:     %s\n", p.Name)
+	if len(p.Identifiers) > 0 {
+		fmt.Printf("  IDs:      %s\n", strings.Join(p.Identifiers, ", "))
+	}
+	fmt.Printf("  Email:    %s\n", p.Email)
+	fmt.Printf("  Phone:    %s\n", p.Phone)
+	fmt.Printf("  Address:  %s\n", p.Address)
+	fmt.Printf("  KYC tier: %s\n", p.KYCTier)
+	fmt.Printf("  Created %s, updated %s\n", p.CreatedAt.Format(time.RFC3339), p.UpdatedAt.Format(time.RFC3339))
+	if p.Redacted {
+		fmt.Println("  (Customer has been forgotten; personal data is no longer recoverable)")
+	}
+}
+
+func customerDetailsFromFlags() app.CustomerDetails {
+	return app.CustomerDetails{
+		Name:        customerName,
+		Identifiers: customerIdentifiers,
+		Email:       customerEmail,
+		Phone:       customerPhone,
+		Address:     customerAddress,
+	}
+}
+
+// kycTierFromFlag parses --kyc-tier, which may be empty.
+func kycTierFromFlag() (domain.KYCTier, error) {
+	if customerKYCTier == "" {
+		return "", nil
+	}
+	return domain.ParseKYCTier(customerKYCTier)
+}
+
+func init() {
+	rootCmd.AddCommand(customerCmd)
+
+	customerCmd.AddCommand(customerCreateCmd)
+	customerCreateCmd.Flags().StringVar(&customerID, "id", "", "Optional unique ID for the customer (UUID generated if empty)")
+	customerCreateCmd.Flags().StringVar(&customerName, "name", "", "Customer name (required)")
+	customerCreateCmd.Flags().StringSliceVar(&customerIdentifiers, "identifier", nil, "Passport, national ID or registration number. Can be used multiple times.")
+	customerCreateCmd.Flags().StringVar(&customerEmail, "email", "", "Contact email address")
+	customerCreateCmd.Flags().StringVar(&customerPhone, "phone", "", "Contact phone number")
+	customerCreateCmd.Flags().StringVar(&customerAddress, "address", "", "Postal address")
+	customerCreateCmd.Flags().StringVar(&customerKYCTier, "kyc-tier", "", "KYC tier: none (default), simplified, standard or enhanced")
+	customerCreateCmd.Flags().StringVar(&customerIdemKey, "idempotency-key", "", "Optional key that makes retries of this command safe")
+	customerCreateCmd.MarkFlagRequired("name")
+
+	customerCmd.AddCommand(customerUpdateCmd)
+	customerUpdateCmd.Flags().StringVar(&customerID, "id", "", "Customer ID (required)")
+	customerUpdateCmd.Flags().StringVar(&customerName, "name", "", "Customer name")
+	customerUpdateCmd.Flags().StringSliceVar(&customerIdentifiers, "identifier", nil, "Passport, national ID or registration number. Can be used multiple times.")
+	customerUpdateCmd.Flags().StringVar(&customerEmail, "email", "", "Contact email address")
+	customerUpdateCmd.Flags().StringVar(&customerPhone, "phone", "", "Contact phone number")
+	customerUpdateCmd.Flags().StringVar(&customerAddress, "address", "", "Postal address")
+	customerUpdateCmd.Flags().StringVar(&customerKYCTier, "kyc-tier", "", "KYC tier: none, simplified, standard or enhanced")
+	customerUpdateCmd.Flags().StringVar(&customerIdemKey, "idempotency-key", "", "Optional key that makes retries of this command safe")
+	customerUpdateCmd.MarkFlagRequired("id")
+
+	customerCmd.AddCommand(customerShowCmd)
+	customerShowCmd.Flags().StringVar(&customerID, "id", "", "Customer ID (required)")
+	customerShowCmd.MarkFlagRequired("id")
+
+	customerCmd.AddCommand(customerListCmd)
+
+	customerCmd.AddCommand(customerAccountsCmd)
+	customerAccountsCmd.Flags().StringVar(&customerID, "id", "", "Customer ID (required)")
+	customerAccountsCmd.Flags().StringVar(&customerCurrency, "currency", "USD", "Reporting currency for the total")
+	customerAccountsCmd.MarkFlagRequired("id")
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"financial-ledger/auth"
+	"financial-ledger/store"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	masterKeyOut  string
+	newMasterPath string
+)
+
+// keysCmd represents the keys command group
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Manage encryption keys for data at rest",
+	Long: `Provides commands to create master keys and to rotate the keys protecting
+event payloads and snapshots. Encryption is enabled by pointing LEDGER_MASTER_KEY
+at a master key file.`,
+}
+
+// generateMasterCmd represents the keys generate-master command
+var generateMasterCmd = &cobra.Command{
+	Use:   "generate-master",
+	Short: "Generate a new master key file",
+	Run: func(cmd *cobra.Command, args []string) {
+		keyID, err := store.GenerateMasterKeyFile(masterKeyOut)
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to generate master key: %w", err))
+			return
+		}
+		fmt.Printf("Master key written to %s, key ID %s\n", masterKeyOut, keyID)
+	},
+}
+
+// rotateKeysCmd represents the keys rotate command
+var rotateKeysCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Rotate data keys and re-encrypt stored events and snapshots",
+	Long: `Starts a new data key for every account and re-encrypts all stored event
+payloads and snapshots under it. Data keys that no longer seal anything are
+then deleted. With --new-master, every data key is first
+re-wrapped under the new master key; update LEDGER_MASTER_KEY afterwards.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if encryptor == nil {
+			exitWithError(errors.New("encryption at rest is not enabled (set LEDGER_MASTER_KEY)"))
+			return
+		}
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		if _, err := ledger.Authorize(ctx, auth.PermAdmin, "RotateKeys"); err != nil {
+			exitWithError(err)
+			return
+		}
+
+		if newMasterPath != "" {
+			newMaster, err := store.LoadMasterKey(newMasterPath)
+			if err != nil {
+				exitWithError(err)
+				return
+			}
+			oldID := encryptor.MasterKeyID()
+			n, err := encryptor.RotateMasterKey(newMaster)
+			if err != nil {
+				exitWithError(fmt.Errorf("failed to rotate master key: %w", err))
+				return
+			}
+			fmt.Printf("Re-wrapped %d data keys: master key %s -> %s\n", n, oldID, newMaster.ID())
+		}
+
+		scopes, err := encryptor.RotateDataKeys()
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to rotate data keys: %w", err))
+			return
+		}
+		eventCount, err := eventStore.Reencrypt(ctx)
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to re-encrypt events: %w", err))
+			return
+		}
+		snapshotCount, err := snapshotStore.Reencrypt(ctx)
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to re-encrypt snapshots: %w", err))
+			return
+		}
+		inUse, err := eventStore.DataKeysInUse(ctx)
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to list data keys in use: %w", err))
+			return
+		}
+		snapshotKeys, err := snapshotStore.DataKeysInUse(ctx)
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to list data keys in use: %w", err))
+			return
+		}
+		for id := range snapshotKeys {
+			inUse[id] = true
+		}
+		retired, err := encryptor.RetireDataKeys(ctx, inUse)
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to retire old data keys: %w", err))
+			return
+		}
+		fmt.Printf("Rotated %d data keys; re-encrypted %d events and %d snapshots; retired %d old data keys.\n", scopes, eventCount, snapshotCount, retired)
+	},
+}
+
+func init() {
+	// Add keysCmd to root command
+	rootCmd.AddCommand(keysCmd)
+
+	// Add subcommands to keysCmd
+	keysCmd.AddCommand(generateMasterCmd)
+	keysCmd.AddCommand(rotateKeysCmd)
+
+	// Flags for generateMasterCmd
+	generateMasterCmd.Flags().StringVar(&masterKeyOut, "out", "", "Path of the master key file to write (required)")
+	generateMasterCmd.MarkFlagRequired("out")
+
+	// Flags for rotateKeysCmd
+	rotateKeysCmd.Flags().StringVar(&newMasterPath, "new-master", "", "Optional new master key file to re-wrap all data keys under")
+}
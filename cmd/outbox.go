@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"financial-ledger/auth"
+	"financial-ledger/outbox"
+)
+
+var outboxTarget string
+
+// outboxCmd represents the outbox command group
+var outboxCmd = &cobra.Command{
+	Use:   "outbox",
+	Short: "Publish committed events to other systems",
+	Long: `Every committed event is recorded in an outbox in the same commit as the
+event itself. The relay publishes pending events in order as JSON lines and
+removes them from the outbox once written. Delivery is at least once; each
+message's "id" is its event ID, which consumers use to drop duplicates.`,
+}
+
+// outboxStatusCmd represents the outbox status command
+var outboxStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show how many events are waiting to be published",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		if _, err := ledger.Authorize(ctx, auth.PermAdmin, "OutboxStatus"); err != nil {
+			exitWithError(err)
+			return
+		}
+		n, err := eventStore.OutboxSize(ctx)
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to read outbox: %w", err))
+			return
+		}
+		fmt.Printf("%d events pending publication.\n", n)
+	},
+}
+
+// outboxRelayCmd represents the outbox relay command
+var outboxRelayCmd = &cobra.Command{
+	Use:   "relay",
+	Short: "Publish every pending event now",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		if _, err := ledger.Authorize(ctx, auth.PermAdmin, "RelayOutbox"); err != nil {
+			exitWithError(err)
+			return
+		}
+		publisher, closePublisher, err := openPublisher(outboxTarget)
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		defer closePublisher()
+
+		n, err := outbox.NewRelay(eventStore, publisher).Drain(ctx)
+		if err != nil {
+			exitWithError(fmt.Errorf("outbox relay stopped after %d events: %w", n, err))
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Published %d events.\n", n)
+	},
+}
+
+// openPublisher returns a publisher for target: "-" is stdout, anything else a
+// file that messages are appended to.
+func openPublisher(target string) (outbox.Publisher, func() error, error) {
+	if target == "-" {
+		return outbox.NewWriterPublisher(os.Stdout), func() error { return nil }, nil
+	}
+	pub, err := outbox.OpenFilePublisher(target)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pub, pub.Close, nil
+}
+
+func init() {
+	rootCmd.AddCommand(outboxCmd)
+
+	outboxCmd.AddCommand(outboxStatusCmd)
+
+	outboxCmd.AddCommand(outboxRelayCmd)
+	outboxRelayCmd.Flags().StringVar(&outboxTarget, "to", "-", "File to append events to, or - for stdout")
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var projectionName string
+
+// projectionCmd represents the projection command group
+var projectionCmd = &cobra.Command{
+	Use:   "projection",
+	Short: "Inspect and rebuild read-model projections",
+	Long: `Projections are read models (balances, the account directory) built from the
+global event log. Each keeps a checkpoint of the last event it applied.`,
+}
+
+// projectionStatusCmd represents the projection status command
+var projectionStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show each projection's position and lag behind the event log",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		statuses, err := ledger.ProjectionStatus(ctx)
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to get projection status: %w", err))
+			return
+		}
+		for _, st := range statuses {
+			updated := "never"
+			if !st.UpdatedAt.IsZero() {
+				updated = st.UpdatedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%-20s position %d of %d, lag %d (updated %s)\n", st.Name, st.Position, st.Head, st.Lag, updated)
+			if st.LastError != "" {
+				fmt.Printf("%-20s last error: %s\n", "", st.LastError)
+			}
+		}
+	},
+}
+
+// projectionRebuildCmd represents the projection rebuild command
+var projectionRebuildCmd = &cobra.Command{
+	Use:   "rebuild",
+	Short: "Discard a projection and rebuild it from the start of the event log",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		if err := ledger.RebuildProjection(ctx, projectionName); err != nil {
+			exitWithError(fmt.Errorf("failed to rebuild projection %s: %w", projectionName, err))
+			return
+		}
+		fmt.Printf("Projection %s rebuilt.\n", projectionName)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(projectionCmd)
+
+	projectionCmd.AddCommand(projectionStatusCmd)
+
+	projectionCmd.AddCommand(projectionRebuildCmd)
+	projectionRebuildCmd.Flags().StringVar(&projectionName, "name", "", "Projection to rebuild (required)")
+	projectionRebuildCmd.MarkFlagRequired("name")
+}
@@ -1,15 +1,20 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 
 	"encoding/json"
 	"financial-ledger/app"
+	"financial-ledger/auth"
 	"financial-ledger/events"
 	"financial-ledger/shared" // Needed for event details potentially
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/spf13/cobra"
 )
 
@@ -17,8 +22,19 @@ import (
 var (
 	queryAccountID string
 	queryCurrency  string // Optional currency for balance query
+	queryAsOf      string // Optional version or RFC 3339 time for balance query
 	querySkip      int
 	queryLimit     int
+
+	// Filters for history queries
+	queryTypes        []string
+	queryFrom         string
+	queryTo           string
+	queryMinAmount    string
+	queryMaxAmount    string
+	queryCounterparty string
+	queryDescending   bool
+	queryCursor       string
 )
 
 // queryCmd represents the query command group
@@ -33,7 +49,9 @@ var balanceCmd = &cobra.Command{
 	Use:   "balance",
 	Short: "Get account balance(s)",
 	Long: `Retrieves the current balance for one or all currencies in a specified account.
-If --currency is omitted, all balances are shown.`,
+If --currency is omitted, all balances are shown. With --as-of, the balance is
+reconstructed as it stood after an event version (e.g. 42) or at a time
+(RFC 3339, e.g. 2024-05-01T12:00:00Z).`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if queryAccountID == "" {
 			exitWithError(fmt.Errorf("account ID (--id) is required"))
@@ -52,8 +70,26 @@ If --currency is omitted, all balances are shown.`,
 			AccountID: queryAccountID,
 			Currency:  targetCurrency,
 		}
+		asOfLabel := ""
+		if queryAsOf != "" {
+			if v, err := strconv.Atoi(queryAsOf); err == nil {
+				queryInput.AsOfVersion = v
+				asOfLabel = fmt.Sprintf(" as of version %d", v)
+			} else if at, err := time.Parse(time.RFC3339, queryAsOf); err == nil {
+				queryInput.AsOf = &at
+				asOfLabel = " as of " + at.Format(time.RFC3339)
+			} else {
+				exitWithError(fmt.Errorf("invalid --as-of %q: expected a version number or an RFC 3339 time", queryAsOf))
+				return
+			}
+		}
 
-		balances, err := accountService.GetCurrentBalance(queryInput)
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		balances, err := ledger.GetCurrentBalance(ctx, queryInput)
 		if err != nil {
 			// Handle account not found specifically
 			// if errors.Is(err, domain.ErrAccountNotFound) { ... }
@@ -63,7 +99,7 @@ If --currency is omitted, all balances are shown.`,
 		if len(balances) == 0 {
 			if targetCurrency != nil {
 				// If a specific currency was requested and not found, it means the balance is zero
-				fmt.Printf("Account '%s' Balance (%s): 0.00\n", queryAccountID, *targetCurrency)
+				fmt.Printf("Account '%s' Balance (%s)%s: 0.00\n", queryAccountID, *targetCurrency, asOfLabel)
 			} else {
 				// If all balances were requested and the map is empty, the account might exist but have zero balances
 				// (or potentially it doesn't exist, though the service call should have errored)
@@ -72,7 +108,7 @@ If --currency is omitted, all balances are shown.`,
 			return
 		}
 
-		fmt.Printf("Account '%s' Balances:\n", queryAccountID)
+		fmt.Printf("Account '%s' Balances%s:\n", queryAccountID, asOfLabel)
 		// Sort currencies for consistent output
 		currencies := make([]shared.Currency, 0, len(balances))
 		for cur := range balances {
@@ -92,57 +128,178 @@ If --currency is omitted, all balances are shown.`,
 var historyCmd = &cobra.Command{
 	Use:   "history",
 	Short: "Get account transaction history (events)",
-	Long:  `Retrieves the sequence of events (transaction history) for a specified account, with optional pagination.`,
+	Long: `Retrieves the sequence of events (transaction history) for a specified account.
+Events can be filtered by type, currency, time range, amount and transfer
+counterparty, and are shown with the account's balances after each one.
+When more events remain, a cursor is printed; pass it to --cursor for the next page.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if queryAccountID == "" {
 			exitWithError(fmt.Errorf("account ID (--id) is required"))
+			return
 		}
 
 		// Basic validation for pagination flags
 		if querySkip < 0 {
 			exitWithError(fmt.Errorf("skip value cannot be negative"))
+			return
 		}
 		// Allow limit 0 (meaning no limit, handled by service) or positive
 		if queryLimit < 0 {
 			exitWithError(fmt.Errorf("limit value cannot be negative"))
+			return
 		}
 
-		queryInput := app.GetHistoryQuery{
-			AccountID: queryAccountID,
-			Skip:      querySkip,
-			Limit:     queryLimit, // Service likely handles 0 as 'no limit' or a default
+		queryInput, err := historySearchFromFlags()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		// --skip predates cursors; honour it by dropping the head of the first page.
+		if querySkip > 0 && queryLimit > 0 {
+			queryInput.Limit = querySkip + queryLimit
 		}
 
-		history, err := accountService.GetTransactionHistory(queryInput)
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		page, err := ledger.SearchHistory(ctx, queryInput)
 		if err != nil {
-			// Handle account not found specifically
-			// if errors.Is(err, domain.ErrAccountNotFound) { ... }
 			exitWithError(fmt.Errorf("failed to get history: %w", err))
+			return
+		}
+		items := page.Items
+		if querySkip > 0 {
+			items = items[min(querySkip, len(items)):]
 		}
 
-		if len(history) == 0 {
-			fmt.Printf("No transaction history found for account '%s' (or account does not exist).\n", queryAccountID)
+		if len(items) == 0 {
+			fmt.Printf("No matching transaction history found for account '%s'.\n", queryAccountID)
 			return
 		}
 
 		fmt.Printf("Transaction History for Account '%s':\n", queryAccountID)
 		fmt.Println("--------------------------------------------------")
-		for i, event := range history {
+		for i, item := range items {
 			fmt.Printf("Event %d:\n", querySkip+i+1) // Adjust index based on skip
-			printEventDetails(event)
+			printEventDetails(ctx, item.Event)
+			printBalances("  Balances after:", item.BalancesAfter)
 			fmt.Println("--------------------------------------------------")
 		}
+		if page.NextCursor != "" {
+			fmt.Printf("More events available. Next page: --cursor %s\n", page.NextCursor)
+		}
 	},
 }
 
+// historySearchFromFlags builds a history search from the query flags.
+func historySearchFromFlags() (app.SearchHistoryQuery, error) {
+	q := app.SearchHistoryQuery{
+		AccountID:    queryAccountID,
+		Counterparty: queryCounterparty,
+		Descending:   queryDescending,
+		Limit:        queryLimit,
+		Cursor:       queryCursor,
+	}
+	for _, t := range queryTypes {
+		q.Types = append(q.Types, events.EventType(t))
+	}
+	if queryCurrency != "" {
+		c := shared.Currency(queryCurrency)
+		if !isValidCurrency(c) {
+			return q, fmt.Errorf("invalid currency code: %q. Supported: USD, EUR, GBP", c)
+		}
+		q.Currency = &c
+	}
+	for _, bound := range []struct {
+		flag, value string
+		dst         **time.Time
+	}{{"--from", queryFrom, &q.From}, {"--to", queryTo, &q.To}} {
+		if bound.value == "" {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, bound.value)
+		if err != nil {
+			return q, fmt.Errorf("invalid %s %q: expected an RFC 3339 time", bound.flag, bound.value)
+		}
+		*bound.dst = &at
+	}
+	for _, bound := range []struct {
+		flag, value string
+		dst         **decimal.Decimal
+	}{{"--min-amount", queryMinAmount, &q.MinAmount}, {"--max-amount", queryMaxAmount, &q.MaxAmount}} {
+		if bound.value == "" {
+			continue
+		}
+		amount, err := decimal.NewFromString(bound.value)
+		if err != nil {
+			return q, fmt.Errorf("invalid %s %q: %w", bound.flag, bound.value, err)
+		}
+		*bound.dst = &amount
+	}
+	return q, nil
+}
+
+// printBalances prints balances under a heading, sorted by currency.
+func printBalances(heading string, balances map[shared.Currency]decimal.Decimal) {
+	fmt.Printf("%s %s\n", heading, formatBalances(balances))
+}
+
+// formatBalances renders balances on one line, sorted by currency.
+func formatBalances(balances map[shared.Currency]decimal.Decimal) string {
+	currencies := make([]shared.Currency, 0, len(balances))
+	for cur := range balances {
+		currencies = append(currencies, cur)
+	}
+	sort.Slice(currencies, func(i, j int) bool { return currencies[i] < currencies[j] })
+
+	parts := make([]string, 0, len(currencies))
+	for _, cur := range currencies {
+		parts = append(parts, fmt.Sprintf("%s %s", cur, balances[cur].StringFixed(2)))
+	}
+	if len(parts) == 0 {
+		return "(none)"
+	}
+	return strings.Join(parts, ", ")
+}
+
 // printEventDetails formats and prints the details of a single event.
 // This function uses type assertions to print specific fields for known event types.
-func printEventDetails(event events.Event) {
+func printEventDetails(ctx context.Context, event events.Event) {
 	base := event.GetBase()
 	fmt.Printf("  Type:      %s\n", base.Type)
 	fmt.Printf("  EventID:   %s\n", base.EventID.String()) // Use .String() for UUID
 	fmt.Printf("  Version:   %d\n", base.Version)
 	fmt.Printf("  Timestamp: %s\n", base.Timestamp.Format(time.RFC3339))
+	if meta := base.Metadata; meta != (events.Metadata{}) {
+		fmt.Printf("  Actor:     %s (via %s)\n", meta.Actor, meta.Channel)
+		if meta.Reason != "" {
+			fmt.Printf("  Reason:    %s\n", meta.Reason)
+		}
+		fmt.Printf("  Correlation: %s\n", meta.CorrelationID)
+		if meta.CausationID != "" {
+			fmt.Printf("  Caused by: %s\n", meta.CausationID)
+		}
+	}
+
+	// Personal data is shown decrypted, or redacted once its subject is forgotten.
+	// Callers who may not read it are not asked to, so history stays usable
+	// without filling the denial log.
+	if sealed := personalDataOf(event); sealed != nil {
+		if p, _ := auth.PrincipalFrom(ctx); !p.Can(auth.PermReadPersonalData) {
+			fmt.Println("  Personal data: (withheld)")
+		} else if details, redacted, err := ledger.RevealPersonalData(ctx, base.AggregateID, *sealed); err != nil {
+			fmt.Printf("  Personal data: (unreadable: %v)\n", err)
+		} else {
+			fmt.Printf("  Owner:     %s\n", details.OwnerName)
+			fmt.Printf("  Address:   %s\n", details.Address)
+			fmt.Printf("  Notes:     %s\n", details.Notes)
+			if redacted {
+				fmt.Printf("  (Subject %s has been forgotten)\n", sealed.SubjectID)
+			}
+		}
+	}
 
 	// Use type assertion to get specific event details
 	switch e := event.(type) {
@@ -186,6 +343,17 @@ func printEventDetails(event events.Event) {
 	}
 }
 
+// personalDataOf returns the sealed personal data carried by event, if any.
+func personalDataOf(event events.Event) *events.SealedPersonalData {
+	switch e := event.(type) {
+	case events.AccountCreatedEvent:
+		return e.PersonalData
+	case events.AccountDetailsUpdatedEvent:
+		return &e.PersonalData
+	}
+	return nil
+}
+
 func init() {
 	// Add queryCmd to root command
 	rootCmd.AddCommand(queryCmd)
@@ -196,6 +364,7 @@ func init() {
 	// Define flags for balanceCmd
 	balanceCmd.Flags().StringVar(&queryAccountID, "id", "", "Account ID to query (required)")
 	balanceCmd.Flags().StringVar(&queryCurrency, "currency", "", "Optional currency code (USD, EUR, GBP) to get specific balance")
+	balanceCmd.Flags().StringVar(&queryAsOf, "as-of", "", "Optional event version or RFC 3339 time to get the balance as of")
 	_ = balanceCmd.MarkFlagRequired("id")
 
 	// Add historyCmd to queryCmd
@@ -205,5 +374,14 @@ func init() {
 	historyCmd.Flags().StringVar(&queryAccountID, "id", "", "Account ID to query (required)")
 	historyCmd.Flags().IntVar(&querySkip, "skip", 0, "Number of events to skip (for pagination)")
 	historyCmd.Flags().IntVar(&queryLimit, "limit", 0, "Maximum number of events to return (0 for no limit)")
+	historyCmd.Flags().StringSliceVar(&queryTypes, "type", nil, "Only events of these types (repeatable, e.g. DepositMade)")
+	historyCmd.Flags().StringVar(&queryCurrency, "currency", "", "Only events moving this currency")
+	historyCmd.Flags().StringVar(&queryFrom, "from", "", "Only events at or after this RFC 3339 time")
+	historyCmd.Flags().StringVar(&queryTo, "to", "", "Only events before this RFC 3339 time")
+	historyCmd.Flags().StringVar(&queryMinAmount, "min-amount", "", "Only events moving at least this amount")
+	historyCmd.Flags().StringVar(&queryMaxAmount, "max-amount", "", "Only events moving at most this amount")
+	historyCmd.Flags().StringVar(&queryCounterparty, "counterparty", "", "Only transfers to or from this account")
+	historyCmd.Flags().BoolVar(&queryDescending, "desc", false, "Newest events first")
+	historyCmd.Flags().StringVar(&queryCursor, "cursor", "", "Continue from the cursor printed by a previous page")
 	_ = historyCmd.MarkFlagRequired("id")
 }
@@ -2,19 +2,54 @@ package cmd
 
 import (
 	"bufio" // Added for REPL input
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings" // Added for REPL input processing
 
 	"financial-ledger/app"
+	"financial-ledger/auth"
+	"financial-ledger/events"
+	"financial-ledger/monitoring"
+	"financial-ledger/screening"
 	"financial-ledger/store"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var (
-	// Shared application service instance
+	// Shared application service instance and the event store behind it
 	accountService *app.AccountService
+	eventStore     *store.InMemoryEventStore
+	snapshotStore  *store.InMemorySnapshotStore
+
+	// Every command goes through ledger, which checks the caller's roles.
+	// authConfigured is false when LEDGER_AUTH_CONFIG is unset, in which case
+	// commands are refused unless --no-auth (cliNoAuth) is given; the CLI then
+	// acts as an administrator.
+	ledger         *auth.Guard
+	authConfigured bool
+	cliNoAuth      bool
+
+	// Envelope encryptor for events and snapshots; nil when LEDGER_MASTER_KEY is unset
+	encryptor *store.Encryptor
+
+	// Whether LEDGER_MONITORING_RULES names a rule file; 'serve' then
+	// evaluates the rules in the background
+	monitoringEnabled bool
+
+	// Sanctions screener loaded from LEDGER_SANCTIONS_LIST; nil when unset
+	screener *screening.Screener
+
+	// Public keys trusted by 'audit verify'; nil when signing is not configured
+	verificationKeys *store.Keyring
+
+	// Audit metadata recorded on every event produced by a CLI command
+	cliActor  string
+	cliReason string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -41,9 +76,89 @@ func Execute() {
 func init() {
 	// Initialize shared services here
 	// Using in-memory stores as per the original design
-	eventStore := store.NewInMemoryEventStore()
-	snapshotStore := store.NewInMemorySnapshotStore()
-	accountService = app.NewAccountService(eventStore, snapshotStore)
+	signer, keyring, err := loadSigningKeys()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	verificationKeys = keyring
+
+	// Events are published through the outbox by 'outbox relay' and 'serve --outbox'.
+	storeOpts := []store.InMemoryEventStoreOption{store.WithOutbox()}
+	var snapshotOpts []store.InMemorySnapshotStoreOption
+	if path := os.Getenv("LEDGER_MASTER_KEY"); path != "" {
+		masterKey, err := store.LoadMasterKey(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		// Data keys are kept next to the events and snapshots they seal, so
+		// they share their lifetime.
+		encryptor, err = store.LoadEncryptor(context.Background(), masterKey, store.NewInMemoryDataKeyStore())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		storeOpts = append(storeOpts, store.WithPayloadEncryption(encryptor))
+		snapshotOpts = append(snapshotOpts, store.WithSnapshotEncryption(encryptor))
+	}
+
+	serviceOpts := []app.ServiceOption{app.WithRetryPolicy(app.DefaultRetryPolicy())}
+	if signer != nil {
+		storeOpts = append(storeOpts, store.WithEventSigner(signer))
+		serviceOpts = append(serviceOpts, app.WithCheckpointSigner(signer))
+	}
+	if keyring != nil {
+		serviceOpts = append(serviceOpts, app.WithVerificationKeys(keyring))
+	}
+	if thresholds := os.Getenv("LEDGER_APPROVAL_THRESHOLDS"); thresholds != "" {
+		parsed, err := parseApprovalThresholds(thresholds)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		serviceOpts = append(serviceOpts, app.WithApprovalPolicy(app.ApprovalPolicy{TransferThresholds: parsed}))
+	}
+	if path := os.Getenv("LEDGER_LIMITS"); path != "" {
+		limits, err := app.LoadLimitPolicy(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		serviceOpts = append(serviceOpts, app.WithLimitPolicy(limits))
+	}
+	if path := os.Getenv("LEDGER_MONITORING_RULES"); path != "" {
+		rules, err := monitoring.LoadRules(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		serviceOpts = append(serviceOpts, app.WithMonitor(monitoring.NewMonitor(store.NewInMemoryAlertStore(), rules)))
+		monitoringEnabled = true
+	}
+
+	if path := os.Getenv("LEDGER_SANCTIONS_LIST"); path != "" {
+		opt, err := loadScreening(path, os.Getenv("LEDGER_SANCTIONS_THRESHOLD"), os.Getenv("LEDGER_SANCTIONS_ACTION"))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		serviceOpts = append(serviceOpts, opt)
+	}
+
+	eventStore = store.NewInMemoryEventStore(storeOpts...)
+	snapshotStore = store.NewInMemorySnapshotStore(snapshotOpts...)
+	accountService = app.NewAccountService(eventStore, snapshotStore, serviceOpts...)
+
+	authn := auth.AllowAll(auth.Principal{Roles: []auth.Role{auth.RoleAdmin}})
+	if path := os.Getenv("LEDGER_AUTH_CONFIG"); path != "" {
+		if authn, err = auth.LoadConfig(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		authConfigured = true
+	}
+	ledger = auth.NewGuard(accountService, authn)
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -54,6 +169,64 @@ func init() {
 	// e.g., rootCmd.AddCommand(transactionCmd)
 	// e.g., rootCmd.AddCommand(queryCmd)
 	rootCmd.AddCommand(replCmd) // Add the repl command
+
+	rootCmd.PersistentFlags().StringVar(&cliActor, "actor", os.Getenv("USER"), "Who is performing the operation (recorded in event metadata)")
+	rootCmd.PersistentFlags().StringVar(&cliReason, "reason", "", "Optional reason recorded in event metadata")
+	rootCmd.PersistentFlags().BoolVar(&cliNoAuth, "no-auth", false, "Run without LEDGER_AUTH_CONFIG, acting as an administrator")
+}
+
+// loadSigningKeys reads the signing key named by LEDGER_SIGNING_KEY and the
+// public keys listed in LEDGER_VERIFY_KEYS (a path list). The signer's own
+// public key is always trusted, so older keys only need listing after rotation.
+func loadSigningKeys() (store.Signer, *store.Keyring, error) {
+	signingKeyPath := os.Getenv("LEDGER_SIGNING_KEY")
+	verifyKeyPaths := filepath.SplitList(os.Getenv("LEDGER_VERIFY_KEYS"))
+	if signingKeyPath != "" {
+		verifyKeyPaths = append(verifyKeyPaths, signingKeyPath)
+	}
+	if len(verifyKeyPaths) == 0 {
+		return nil, nil, nil
+	}
+
+	keyring, err := store.LoadKeyring(verifyKeyPaths...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load verification keys: %w", err)
+	}
+	if signingKeyPath == "" {
+		return nil, keyring, nil
+	}
+	signer, err := store.LoadEd25519Signer(signingKeyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return signer, keyring, nil
+}
+
+// cliMetadata builds the event metadata for a command issued from this CLI.
+func cliMetadata() events.Metadata {
+	return events.Metadata{
+		Actor:   cliActor,
+		Channel: "cli",
+		Reason:  cliReason,
+	}
+}
+
+// cliContext authenticates the CLI user with the API key in LEDGER_API_KEY or
+// the JWT in LEDGER_TOKEN. Once authenticated, the principal's ID replaces
+// --actor in event metadata. Without LEDGER_AUTH_CONFIG it fails unless
+// --no-auth was given.
+func cliContext() (context.Context, error) {
+	if !authConfigured && !cliNoAuth {
+		return nil, errors.New("authentication is not configured, and commands no longer run unauthenticated by default: set LEDGER_AUTH_CONFIG with LEDGER_API_KEY or LEDGER_TOKEN, or pass --no-auth to act as an administrator (e.g. 'ledger-cli --no-auth account list')")
+	}
+	if authConfigured && cliNoAuth {
+		return nil, errors.New("--no-auth conflicts with LEDGER_AUTH_CONFIG")
+	}
+	credential := os.Getenv("LEDGER_API_KEY")
+	if credential == "" {
+		credential = os.Getenv("LEDGER_TOKEN")
+	}
+	return ledger.Authenticate(context.Background(), credential, "cli")
 }
 
 // Helper function to print errors and exit
@@ -76,6 +249,8 @@ var replCmd = &cobra.Command{
 		fmt.Println("Starting ledger CLI REPL. Type 'exit' or 'quit' to exit.")
 
 		reader := bufio.NewReader(os.Stdin)
+		// --no-auth given to 'repl' holds for the whole session.
+		noAuth := cliNoAuth
 
 		for {
 			fmt.Print("> ")
@@ -113,7 +288,9 @@ var replCmd = &cobra.Command{
 			// Execute the command. Errors will be printed by exitWithError.
 			// We don't need to check the return value here because exitWithError
 			// handles the error reporting.
+			cliNoAuth = noAuth
 			rootCmd.Execute()
+			resetFlags(rootCmd)
 
 			// Restore original os.Args
 			os.Args = originalArgs
@@ -122,3 +299,22 @@ var replCmd = &cobra.Command{
 		fmt.Println("Exiting REPL.")
 	},
 }
+
+// resetFlags restores every flag of cmd and its subcommands to its default, so
+// values given to one REPL command don't leak into the next.
+func resetFlags(cmd *cobra.Command) {
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if !f.Changed {
+			return
+		}
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			_ = slice.Replace(nil)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	})
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
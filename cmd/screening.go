@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"financial-ledger/app"
+	"financial-ledger/screening"
+	"financial-ledger/store"
+)
+
+var (
+	screenName        string
+	screenIdentifiers []string
+	screenAccount     string
+)
+
+// screeningCmd represents the screening command group
+var screeningCmd = &cobra.Command{
+	Use:   "screening",
+	Short: "Screen names against the sanctions list",
+	Long: `The sanctions list named by LEDGER_SANCTIONS_LIST, in OFAC's sdn.csv or sdn.xml
+format, is checked before an account with holder details is created and before
+money is transferred to an account with holder details. Names match fuzzily,
+at or above LEDGER_SANCTIONS_THRESHOLD (default 0.9); identifiers match
+exactly. LEDGER_SANCTIONS_ACTION decides what happens on a hit: "block"
+(the default) refuses the command, "hold" holds it for approval.
+
+Every screening is recorded with the version of the list it used.`,
+}
+
+// screeningCheckCmd represents the screening check command
+var screeningCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Screen a name and identifiers without any operation",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		decision, err := ledger.ScreenName(ctx, screening.Subject{Name: screenName, Identifiers: screenIdentifiers}, cliMetadata())
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to screen: %w", err))
+			return
+		}
+		subject := screenName
+		if len(screenIdentifiers) > 0 {
+			subject += " [" + strings.Join(screenIdentifiers, ", ") + "]"
+		}
+		fmt.Printf("Screened %s:\n", subject)
+		printScreening(decision)
+	},
+}
+
+// screeningDecisionsCmd represents the screening decisions command
+var screeningDecisionsCmd = &cobra.Command{
+	Use:   "decisions",
+	Short: "List recorded screening decisions, oldest first",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		decisions, err := ledger.ListScreenings(ctx, screenAccount)
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to list screening decisions: %w", err))
+			return
+		}
+		if len(decisions) == 0 {
+			fmt.Println("No screening decisions.")
+			return
+		}
+		for _, d := range decisions {
+			printScreening(d)
+		}
+	},
+}
+
+// screeningReloadCmd represents the screening reload command
+var screeningReloadCmd = &cobra.Command{
+	Use:   "reload",
+	Short: "Read the sanctions list file again",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		version, changed, err := ledger.ReloadSanctionsList(ctx)
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to reload sanctions list: %w", err))
+			return
+		}
+		if !changed {
+			fmt.Printf("Sanctions list unchanged; still version %s.\n", version)
+			return
+		}
+		fmt.Printf("Sanctions list loaded as version %s.\n", version)
+	},
+}
+
+func printScreening(d store.ScreeningDecision) {
+	fmt.Printf("%s  %-8s %s", d.ID, d.Outcome, d.Operation)
+	if d.AccountID != "" {
+		fmt.Printf(", account %s", d.AccountID)
+	}
+	if d.CustomerID != "" {
+		fmt.Printf(", customer %s", d.CustomerID)
+	}
+	fmt.Println()
+	fmt.Printf("    List version %d (sha256:%.12s), threshold %.2f, screened %s", d.ListVersion, d.ListDigest, d.Threshold, d.ScreenedAt.Format(time.RFC3339))
+	if d.Actor != "" {
+		fmt.Printf(" by %s", d.Actor)
+	}
+	fmt.Println()
+	if d.ApprovalID != "" {
+		fmt.Printf("    Held as approval %s\n", d.ApprovalID)
+	}
+	for _, m := range d.Matches {
+		fmt.Printf("    %.2f  %q (entry %s, %s", m.Score, m.Matched, m.EntryUID, m.EntryName)
+		if len(m.Programs) > 0 {
+			fmt.Printf("; %s", strings.Join(m.Programs, ", "))
+		}
+		fmt.Println(")")
+	}
+}
+
+// loadScreening loads the sanctions list at path with the threshold and
+// action given in the environment, either of which may be empty.
+func loadScreening(path, threshold, action string) (app.ServiceOption, error) {
+	var opts []screening.Option
+	if threshold != "" {
+		t, err := strconv.ParseFloat(threshold, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LEDGER_SANCTIONS_THRESHOLD %q: %w", threshold, err)
+		}
+		opts = append(opts, screening.WithThreshold(t))
+	}
+	a := app.ScreeningAction(strings.ToLower(action))
+	switch a {
+	case "":
+		a = app.ScreeningBlock
+	case app.ScreeningBlock, app.ScreeningHold:
+	default:
+		return nil, fmt.Errorf("invalid LEDGER_SANCTIONS_ACTION %q: use block or hold", action)
+	}
+	sc, err := screening.NewScreener(path, opts...)
+	if err != nil {
+		return nil, err
+	}
+	screener = sc
+	return app.WithScreening(sc, a), nil
+}
+
+func init() {
+	rootCmd.AddCommand(screeningCmd)
+
+	screeningCmd.AddCommand(screeningCheckCmd)
+	screeningCheckCmd.Flags().StringVar(&screenName, "name", "", "Name to screen (required)")
+	screeningCheckCmd.Flags().StringSliceVar(&screenIdentifiers, "identifier", nil, "Passport, national ID or registration number to screen. Can be used multiple times.")
+	screeningCheckCmd.MarkFlagRequired("name")
+
+	screeningCmd.AddCommand(screeningDecisionsCmd)
+	screeningDecisionsCmd.Flags().StringVar(&screenAccount, "account", "", "Only decisions about this account")
+
+	screeningCmd.AddCommand(screeningReloadCmd)
+}
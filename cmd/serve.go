@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"financial-ledger/grpcapi"
+	"financial-ledger/httpapi"
+	"financial-ledger/outbox"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+var (
+	serveAddr            string
+	serveGRPCAddr        string
+	serveShutdownTimeout time.Duration
+	serveWebhookInterval time.Duration
+	serveOutbox          string
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the ledger over HTTP and gRPC",
+	Long: `Starts an HTTP/JSON API over the ledger and, with --grpc-addr, a gRPC API.
+The OpenAPI description of the HTTP routes is served at /openapi.json; the
+gRPC service is defined in grpcapi/ledgerpb/ledger.proto. Webhooks are
+delivered, and the transaction monitoring rules in LEDGER_MONITORING_RULES
+evaluated, in the background while serving. The sanctions list is reloaded
+every minute, taking a new version whenever the file has changed. Servers stop gracefully on SIGINT
+or SIGTERM.
+
+Callers authenticate with the API keys and JWT settings in LEDGER_AUTH_CONFIG.
+Without it the server refuses to start unless --no-auth is given, in which
+case every caller is treated as an administrator.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if serveAddr == "" && serveGRPCAddr == "" {
+			exitWithError(errors.New("nothing to serve: set --addr, --grpc-addr or both"))
+			return
+		}
+		if !authConfigured && !cliNoAuth {
+			exitWithError(errors.New("refusing to serve without authentication: set LEDGER_AUTH_CONFIG, or pass --no-auth"))
+			return
+		}
+		if cliNoAuth {
+			if authConfigured {
+				exitWithError(errors.New("--no-auth conflicts with LEDGER_AUTH_CONFIG"))
+				return
+			}
+			log.Printf("Warning: Authentication is disabled; every caller can act as an administrator")
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		errCh := make(chan error, 2)
+
+		var httpServer *http.Server
+		if serveAddr != "" {
+			// Event streams never finish on their own; end them when shutdown begins.
+			streamCtx, endStreams := context.WithCancel(context.Background())
+			defer endStreams()
+			httpServer = &http.Server{
+				Addr:              serveAddr,
+				Handler:           httpapi.NewServer(ledger),
+				ReadHeaderTimeout: 10 * time.Second,
+				BaseContext:       func(net.Listener) context.Context { return streamCtx },
+			}
+			httpServer.RegisterOnShutdown(endStreams)
+			go func() {
+				log.Printf("HTTP API listening on %s", serveAddr)
+				if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+					errCh <- fmt.Errorf("HTTP server failed: %w", err)
+				}
+			}()
+		}
+
+		var grpcServer *grpc.Server
+		if serveGRPCAddr != "" {
+			lis, err := net.Listen("tcp", serveGRPCAddr)
+			if err != nil {
+				exitWithError(fmt.Errorf("failed to listen for gRPC on %s: %w", serveGRPCAddr, err))
+				return
+			}
+			ledgerServer := grpcapi.NewServer(ledger)
+			grpcServer = grpc.NewServer(ledgerServer.ServerOptions()...)
+			ledgerServer.Register(grpcServer)
+			go func() {
+				log.Printf("gRPC API listening on %s", serveGRPCAddr)
+				if err := grpcServer.Serve(lis); err != nil {
+					errCh <- fmt.Errorf("gRPC server failed: %w", err)
+				}
+			}()
+		}
+
+		// Background work runs as the server itself, not as any caller.
+		if serveWebhookInterval > 0 {
+			go accountService.RunWebhooks(ctx, serveWebhookInterval)
+		}
+		if monitoringEnabled {
+			go accountService.RunMonitoring(ctx, time.Second)
+		}
+		if screener != nil {
+			go screener.Watch(ctx, time.Minute)
+		}
+		if serveOutbox != "" {
+			publisher, closePublisher, err := openPublisher(serveOutbox)
+			if err != nil {
+				exitWithError(err)
+				return
+			}
+			defer closePublisher()
+			go outbox.NewRelay(eventStore, publisher).Run(ctx, time.Second)
+		}
+
+		select {
+		case err := <-errCh:
+			exitWithError(err)
+		case <-ctx.Done():
+		}
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+		defer cancel()
+		if grpcServer != nil {
+			stopped := make(chan struct{})
+			go func() {
+				grpcServer.GracefulStop()
+				close(stopped)
+			}()
+			select {
+			case <-stopped:
+			case <-shutdownCtx.Done():
+				// Tailing streams never finish on their own.
+				grpcServer.Stop()
+			}
+		}
+		if httpServer != nil {
+			if err := httpServer.Shutdown(shutdownCtx); err != nil {
+				exitWithError(fmt.Errorf("HTTP server did not shut down cleanly: %w", err))
+				return
+			}
+		}
+		log.Printf("Servers stopped")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", ":8080", "Address for the HTTP API; empty disables it")
+	serveCmd.Flags().StringVar(&serveGRPCAddr, "grpc-addr", "", "Address for the gRPC API; empty disables it")
+	serveCmd.Flags().DurationVar(&serveShutdownTimeout, "shutdown-timeout", 10*time.Second, "How long to wait for in-flight requests when stopping")
+	serveCmd.Flags().DurationVar(&serveWebhookInterval, "webhook-interval", time.Second, "How often to deliver webhooks; 0 disables delivery")
+	serveCmd.Flags().StringVar(&serveOutbox, "outbox", "", "Publish committed events to this file, or to stdout with -; empty disables publishing")
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"financial-ledger/app"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	statementAccountID string
+	statementFrom      string
+	statementTo        string
+	statementFormat    string
+	statementOut       string
+)
+
+// statementCmd represents the statement command
+var statementCmd = &cobra.Command{
+	Use:   "statement",
+	Short: "Produce an account statement for a period",
+	Long: `Produces a statement of an account's activity from --from (inclusive) to --to
+(exclusive): the opening balance, every transaction with its running balance,
+and the closing balance, per currency. Dates are RFC 3339 times or YYYY-MM-DD
+(midnight UTC). --to defaults to now. Formats are text, csv and html; the HTML
+version is laid out for printing.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		from, err := parseStatementTime(statementFrom)
+		if err != nil {
+			exitWithError(fmt.Errorf("invalid --from: %w", err))
+			return
+		}
+		to := time.Now().UTC()
+		if statementTo != "" {
+			if to, err = parseStatementTime(statementTo); err != nil {
+				exitWithError(fmt.Errorf("invalid --to: %w", err))
+				return
+			}
+		}
+
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		st, err := ledger.GenerateStatement(ctx, app.GenerateStatementQuery{
+			AccountID: statementAccountID,
+			From:      from,
+			To:        to,
+		})
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to generate statement: %w", err))
+			return
+		}
+
+		var w io.Writer = os.Stdout
+		if statementOut != "" {
+			f, err := os.Create(statementOut)
+			if err != nil {
+				exitWithError(fmt.Errorf("failed to create %s: %w", statementOut, err))
+				return
+			}
+			defer f.Close()
+			w = f
+		}
+		if err := app.RenderStatement(w, st, app.StatementFormat(statementFormat)); err != nil {
+			exitWithError(fmt.Errorf("failed to write statement: %w", err))
+			return
+		}
+		if statementOut != "" {
+			fmt.Printf("Statement written to %s\n", statementOut)
+		}
+	},
+}
+
+// parseStatementTime accepts an RFC 3339 time or a calendar date.
+func parseStatementTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither an RFC 3339 time nor a YYYY-MM-DD date", value)
+	}
+	return t, nil
+}
+
+func init() {
+	rootCmd.AddCommand(statementCmd)
+
+	statementCmd.Flags().StringVar(&statementAccountID, "id", "", "Account ID (required)")
+	statementCmd.Flags().StringVar(&statementFrom, "from", "", "Start of the period, inclusive (required)")
+	statementCmd.Flags().StringVar(&statementTo, "to", "", "End of the period, exclusive (defaults to now)")
+	statementCmd.Flags().StringVar(&statementFormat, "format", "text", "Output format: text, csv or html")
+	statementCmd.Flags().StringVar(&statementOut, "out", "", "Write the statement to this file instead of stdout")
+	statementCmd.MarkFlagRequired("id")
+	statementCmd.MarkFlagRequired("from")
+}
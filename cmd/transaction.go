@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -20,6 +21,8 @@ var (
 	txToCurrency   string
 	txFromID       string
 	txToID         string
+	txIdemKey      string
+	txEventID      string
 )
 
 // transactionCmd represents the transaction command group
@@ -38,36 +41,50 @@ var depositCmd = &cobra.Command{
 		// Validate required flags
 		if txAccountID == "" {
 			exitWithError(fmt.Errorf("account ID (--id) is required"))
+			return
 		}
 		if txCurrency == "" {
 			exitWithError(fmt.Errorf("currency (--currency) is required"))
+			return
 		}
 		if txAmountStr == "" {
 			exitWithError(fmt.Errorf("amount (--amount) is required"))
+			return
 		}
 
 		currency := shared.Currency(txCurrency)
 		if !isValidCurrency(currency) {
 			exitWithError(fmt.Errorf("invalid currency code: %q. Supported: USD, EUR, GBP", currency))
+			return
 		}
 
 		amount, err := decimal.NewFromString(txAmountStr)
 		if err != nil {
 			exitWithError(fmt.Errorf("invalid amount format: %q. %v", txAmountStr, err))
+			return
 		}
 		if amount.IsNegative() || amount.IsZero() {
 			exitWithError(fmt.Errorf("deposit amount must be positive: %s", amount))
+			return
 		}
 
 		depositCmdInput := app.DepositMoneyCommand{
-			AccountID: txAccountID,
-			Amount:    amount,
-			Currency:  currency,
+			AccountID:      txAccountID,
+			Amount:         amount,
+			Currency:       currency,
+			IdempotencyKey: txIdemKey,
+			Metadata:       cliMetadata(),
 		}
 
-		err = accountService.Deposit(depositCmdInput)
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		err = ledger.Deposit(ctx, depositCmdInput)
 		if err != nil {
 			exitWithError(fmt.Errorf("failed to deposit funds: %w", err))
+			return
 		}
 
 		fmt.Printf("Successfully deposited %s %s into account '%s'.\n", amount.StringFixed(2), currency, txAccountID)
@@ -83,34 +100,47 @@ var withdrawCmd = &cobra.Command{
 		// Validate required flags (reusing txAccountID, txCurrency, txAmountStr)
 		if txAccountID == "" {
 			exitWithError(fmt.Errorf("account ID (--id) is required"))
+			return
 		}
 		if txCurrency == "" {
 			exitWithError(fmt.Errorf("currency (--currency) is required"))
+			return
 		}
 		if txAmountStr == "" {
 			exitWithError(fmt.Errorf("amount (--amount) is required"))
+			return
 		}
 
 		currency := shared.Currency(txCurrency)
 		if !isValidCurrency(currency) {
 			exitWithError(fmt.Errorf("invalid currency code: %q. Supported: USD, EUR, GBP", currency))
+			return
 		}
 
 		amount, err := decimal.NewFromString(txAmountStr)
 		if err != nil {
 			exitWithError(fmt.Errorf("invalid amount format: %q. %v", txAmountStr, err))
+			return
 		}
 		if amount.IsNegative() || amount.IsZero() {
 			exitWithError(fmt.Errorf("withdrawal amount must be positive: %s", amount))
+			return
 		}
 
 		withdrawCmdInput := app.WithdrawMoneyCommand{
-			AccountID: txAccountID,
-			Amount:    amount,
-			Currency:  currency,
+			AccountID:      txAccountID,
+			Amount:         amount,
+			Currency:       currency,
+			IdempotencyKey: txIdemKey,
+			Metadata:       cliMetadata(),
 		}
 
-		err = accountService.Withdraw(withdrawCmdInput)
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		err = ledger.Withdraw(ctx, withdrawCmdInput)
 		if err != nil {
 			// Specific error handling for insufficient funds is good UX
 			// The service layer already logs this, but we inform the CLI user directly.
@@ -120,6 +150,7 @@ var withdrawCmd = &cobra.Command{
 			// }
 			// For other errors, wrap them:
 			exitWithError(fmt.Errorf("failed to withdraw funds: %w", err))
+			return
 		}
 
 		fmt.Printf("Successfully withdrew %s %s from account '%s'.\n", amount.StringFixed(2), currency, txAccountID)
@@ -135,49 +166,66 @@ var convertCmd = &cobra.Command{
 		// Validate required flags
 		if txAccountID == "" {
 			exitWithError(fmt.Errorf("account ID (--id) is required"))
+			return
 		}
 		if txFromCurrency == "" {
 			exitWithError(fmt.Errorf("source currency (--from) is required"))
+			return
 		}
 		if txToCurrency == "" {
 			exitWithError(fmt.Errorf("target currency (--to) is required"))
+			return
 		}
 		if txAmountStr == "" {
 			exitWithError(fmt.Errorf("amount (--amount) is required"))
+			return
 		}
 
 		fromCurrency := shared.Currency(txFromCurrency)
 		if !isValidCurrency(fromCurrency) {
 			exitWithError(fmt.Errorf("invalid source currency code: %q. Supported: USD, EUR, GBP", fromCurrency))
+			return
 		}
 		toCurrency := shared.Currency(txToCurrency)
 		if !isValidCurrency(toCurrency) {
 			exitWithError(fmt.Errorf("invalid target currency code: %q. Supported: USD, EUR, GBP", toCurrency))
+			return
 		}
 		if fromCurrency == toCurrency {
 			exitWithError(fmt.Errorf("source and target currencies cannot be the same"))
+			return
 		}
 
 		amount, err := decimal.NewFromString(txAmountStr)
 		if err != nil {
 			exitWithError(fmt.Errorf("invalid amount format: %q. %v", txAmountStr, err))
+			return
 		}
 		if amount.IsNegative() || amount.IsZero() {
 			exitWithError(fmt.Errorf("conversion amount must be positive: %s", amount))
+			return
 		}
 
 		convertCmdInput := app.ConvertCurrencyCommand{
-			AccountID:    txAccountID,
-			FromAmount:   amount,
-			FromCurrency: fromCurrency,
-			ToCurrency:   toCurrency,
+			AccountID:      txAccountID,
+			FromAmount:     amount,
+			FromCurrency:   fromCurrency,
+			ToCurrency:     toCurrency,
+			IdempotencyKey: txIdemKey,
+			Metadata:       cliMetadata(),
 		}
 
-		err = accountService.ConvertCurrency(convertCmdInput)
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		err = ledger.ConvertCurrency(ctx, convertCmdInput)
 		if err != nil {
 			// Handle insufficient funds specifically if desired
 			// if errors.Is(err, domain.ErrInsufficientFunds) { ... }
 			exitWithError(fmt.Errorf("failed to convert currency: %w", err))
+			return
 		}
 
 		// Note: The actual converted amount isn't directly returned by the service call.
@@ -199,31 +247,39 @@ A separate process would be needed to handle the corresponding credit.`,
 		// Validate required flags
 		if txFromID == "" {
 			exitWithError(fmt.Errorf("source account ID (--from-id) is required"))
+			return
 		}
 		if txToID == "" {
 			exitWithError(fmt.Errorf("target account ID (--to-id) is required"))
+			return
 		}
 		if txCurrency == "" {
 			exitWithError(fmt.Errorf("currency (--currency) is required"))
+			return
 		}
 		if txAmountStr == "" {
 			exitWithError(fmt.Errorf("amount (--amount) is required"))
+			return
 		}
 		if txFromID == txToID {
 			exitWithError(fmt.Errorf("source and target account IDs cannot be the same"))
+			return
 		}
 
 		currency := shared.Currency(txCurrency)
 		if !isValidCurrency(currency) {
 			exitWithError(fmt.Errorf("invalid currency code: %q. Supported: USD, EUR, GBP", currency))
+			return
 		}
 
 		amount, err := decimal.NewFromString(txAmountStr)
 		if err != nil {
 			exitWithError(fmt.Errorf("invalid amount format: %q. %v", txAmountStr, err))
+			return
 		}
 		if amount.IsNegative() || amount.IsZero() {
 			exitWithError(fmt.Errorf("transfer amount must be positive: %s", amount))
+			return
 		}
 
 		transferCmdInput := app.TransferMoneyCommand{
@@ -231,14 +287,29 @@ A separate process would be needed to handle the corresponding credit.`,
 			TargetAccountID: txToID,
 			Amount:          amount,
 			Currency:        currency,
+			IdempotencyKey:  txIdemKey,
+			Metadata:        cliMetadata(),
 		}
 
-		err = accountService.TransferMoney(transferCmdInput)
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		err = ledger.TransferMoney(ctx, transferCmdInput)
+		var held *app.ApprovalRequiredError
+		if errors.As(err, &held) {
+			fmt.Printf("Transfer of %s %s from account '%s' to account '%s' needs a second user's approval.\n",
+				amount.StringFixed(2), currency, txFromID, txToID)
+			printApproval(held.Approval)
+			return
+		}
 		if err != nil {
 			// Handle specific errors like insufficient funds or target account not found
 			// if errors.Is(err, domain.ErrInsufficientFunds) { ... }
 			// if errors.Is(err, domain.ErrAccountNotFound) { ... } // Check if target exists
 			exitWithError(fmt.Errorf("failed to initiate transfer: %w", err))
+			return
 		}
 
 		fmt.Printf("Successfully initiated transfer (debit) of %s %s from account '%s' to account '%s'.\n",
@@ -246,6 +317,34 @@ A separate process would be needed to handle the corresponding credit.`,
 	},
 }
 
+// reverseCmd represents the transaction reverse command
+var reverseCmd = &cobra.Command{
+	Use:   "reverse",
+	Short: "Request the reversal of a deposit or withdrawal",
+	Long: `Requests a compensating entry that undoes a deposit or withdrawal, identified
+by its event ID (see 'query history'). The original event stays in the history.
+The reversal is held until a second user approves it with 'approval approve'.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		approval, err := ledger.RequestReversal(ctx, app.ReverseTransactionCommand{
+			AccountID:      txAccountID,
+			EventID:        txEventID,
+			IdempotencyKey: txIdemKey,
+			Metadata:       cliMetadata(),
+		})
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to request reversal: %w", err))
+			return
+		}
+		fmt.Println("Reversal requested; it needs a second user's approval.")
+		printApproval(approval)
+	},
+}
+
 // Helper function for currency validation
 func isValidCurrency(c shared.Currency) bool {
 	cUpper := shared.Currency(strings.ToUpper(string(c)))
@@ -263,6 +362,7 @@ func init() {
 	depositCmd.Flags().StringVar(&txAccountID, "id", "", "Account ID to deposit into (required)")
 	depositCmd.Flags().StringVar(&txCurrency, "currency", "", "Currency code (USD, EUR, GBP) (required)")
 	depositCmd.Flags().StringVar(&txAmountStr, "amount", "", "Amount to deposit (required)")
+	depositCmd.Flags().StringVar(&txIdemKey, "idempotency-key", "", "Optional key that makes retries of this command safe")
 	_ = depositCmd.MarkFlagRequired("id") // Mark flags as required for better UX
 	_ = depositCmd.MarkFlagRequired("currency")
 	_ = depositCmd.MarkFlagRequired("amount")
@@ -277,6 +377,7 @@ func init() {
 	withdrawCmd.Flags().StringVar(&txAccountID, "id", "", "Account ID to withdraw from (required)")
 	withdrawCmd.Flags().StringVar(&txCurrency, "currency", "", "Currency code (USD, EUR, GBP) (required)")
 	withdrawCmd.Flags().StringVar(&txAmountStr, "amount", "", "Amount to withdraw (required)")
+	withdrawCmd.Flags().StringVar(&txIdemKey, "idempotency-key", "", "Optional key that makes retries of this command safe")
 	_ = withdrawCmd.MarkFlagRequired("id")
 	_ = withdrawCmd.MarkFlagRequired("currency")
 	_ = withdrawCmd.MarkFlagRequired("amount")
@@ -289,6 +390,7 @@ func init() {
 	convertCmd.Flags().StringVar(&txFromCurrency, "from", "", "Source currency code (USD, EUR, GBP) (required)")
 	convertCmd.Flags().StringVar(&txToCurrency, "to", "", "Target currency code (USD, EUR, GBP) (required)")
 	convertCmd.Flags().StringVar(&txAmountStr, "amount", "", "Amount in source currency to convert (required)")
+	convertCmd.Flags().StringVar(&txIdemKey, "idempotency-key", "", "Optional key that makes retries of this command safe")
 	_ = convertCmd.MarkFlagRequired("id")
 	_ = convertCmd.MarkFlagRequired("from")
 	_ = convertCmd.MarkFlagRequired("to")
@@ -302,9 +404,17 @@ func init() {
 	transferCmd.Flags().StringVar(&txToID, "to-id", "", "Target account ID (required)")
 	transferCmd.Flags().StringVar(&txCurrency, "currency", "", "Currency code (USD, EUR, GBP) (required)")
 	transferCmd.Flags().StringVar(&txAmountStr, "amount", "", "Amount to transfer (required)")
+	transferCmd.Flags().StringVar(&txIdemKey, "idempotency-key", "", "Optional key that makes retries of this command safe")
 	_ = transferCmd.MarkFlagRequired("from-id")
 	_ = transferCmd.MarkFlagRequired("to-id")
 	_ = transferCmd.MarkFlagRequired("currency")
 	_ = transferCmd.MarkFlagRequired("amount")
 
+	transactionCmd.AddCommand(reverseCmd)
+	reverseCmd.Flags().StringVar(&txAccountID, "id", "", "Account ID the transaction was made on (required)")
+	reverseCmd.Flags().StringVar(&txEventID, "event", "", "Event ID of the deposit or withdrawal to reverse (required)")
+	reverseCmd.Flags().StringVar(&txIdemKey, "idempotency-key", "", "Optional key that makes retries of this command safe")
+	_ = reverseCmd.MarkFlagRequired("id")
+	_ = reverseCmd.MarkFlagRequired("event")
+
 }
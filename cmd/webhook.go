@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"financial-ledger/app"
+	"financial-ledger/store"
+)
+
+var (
+	webhookURL      string
+	webhookTypes    []string
+	webhookID       string
+	webhookStatus   string
+	webhookDelivery string
+)
+
+// webhookCmd represents the webhook command group
+var webhookCmd = &cobra.Command{
+	Use:   "webhook",
+	Short: "Manage webhook subscriptions and deliveries",
+	Long: `Webhooks post signed JSON notifications of ledger events, and of commands
+rejected by business rules (type CommandRejected), to subscribed URLs.
+Receivers verify the X-Ledger-Signature header with the subscription secret.
+Failed deliveries are retried with exponential backoff and then dead-lettered.`,
+}
+
+// webhookAddCmd represents the webhook add command
+var webhookAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Subscribe a URL to notifications",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		sub, err := ledger.AddWebhook(ctx, app.AddWebhookCommand{URL: webhookURL, EventTypes: webhookTypes})
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to add webhook: %w", err))
+			return
+		}
+		fmt.Printf("Webhook %s added for %s\n", sub.ID, sub.URL)
+		fmt.Printf("Signing secret (shown once): %s\n", sub.Secret)
+	},
+}
+
+// webhookListCmd represents the webhook list command
+var webhookListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List webhook subscriptions",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		subs, err := ledger.ListWebhooks(ctx)
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to list webhooks: %w", err))
+			return
+		}
+		if len(subs) == 0 {
+			fmt.Println("No webhooks.")
+			return
+		}
+		for _, sub := range subs {
+			types := "all types"
+			if len(sub.EventTypes) > 0 {
+				types = strings.Join(sub.EventTypes, ", ")
+			}
+			fmt.Printf("%s  %s  (%s)\n", sub.ID, sub.URL, types)
+		}
+	},
+}
+
+// webhookRemoveCmd represents the webhook remove command
+var webhookRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Remove a webhook subscription",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		if err := ledger.RemoveWebhook(ctx, webhookID); err != nil {
+			exitWithError(fmt.Errorf("failed to remove webhook: %w", err))
+			return
+		}
+		fmt.Printf("Webhook %s removed.\n", webhookID)
+	},
+}
+
+// webhookDeliveriesCmd represents the webhook deliveries command
+var webhookDeliveriesCmd = &cobra.Command{
+	Use:   "deliveries",
+	Short: "List webhook deliveries, e.g. the dead letters with --status dead",
+	Run: func(cmd *cobra.Command, args []string) {
+		status := store.WebhookDeliveryStatus(webhookStatus)
+		switch status {
+		case "", store.DeliveryPending, store.DeliveryDelivered, store.DeliveryDead:
+		default:
+			exitWithError(fmt.Errorf("unknown status %q: use pending, delivered or dead", webhookStatus))
+			return
+		}
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		deliveries, err := ledger.ListWebhookDeliveries(ctx, status)
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to list webhook deliveries: %w", err))
+			return
+		}
+		if len(deliveries) == 0 {
+			fmt.Println("No deliveries.")
+			return
+		}
+		for _, d := range deliveries {
+			fmt.Printf("%s  %-10s %-18s attempts %d", d.ID, d.Status, d.Type, d.Attempts)
+			if d.Status == store.DeliveryPending && d.Attempts > 0 {
+				fmt.Printf(", next %s", d.NextAttemptAt.Format(time.RFC3339))
+			}
+			fmt.Println()
+			if d.LastError != "" {
+				fmt.Printf("    last error: %s\n", d.LastError)
+			}
+		}
+	},
+}
+
+// webhookReplayCmd represents the webhook replay command
+var webhookReplayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Requeue a dead-lettered delivery",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		if err := ledger.ReplayWebhookDelivery(ctx, webhookDelivery); err != nil {
+			exitWithError(fmt.Errorf("failed to replay delivery: %w", err))
+			return
+		}
+		fmt.Printf("Delivery %s requeued.\n", webhookDelivery)
+	},
+}
+
+// webhookDeliverCmd represents the webhook deliver command
+var webhookDeliverCmd = &cobra.Command{
+	Use:   "deliver",
+	Short: "Deliver due webhooks now",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, err := cliContext()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		report, err := ledger.DeliverWebhooks(ctx)
+		if err != nil {
+			exitWithError(fmt.Errorf("failed to deliver webhooks: %w", err))
+			return
+		}
+		fmt.Printf("Delivered %d, retrying %d, dead-lettered %d.\n", report.Delivered, report.Retrying, report.DeadLettered)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(webhookCmd)
+
+	webhookCmd.AddCommand(webhookAddCmd)
+	webhookAddCmd.Flags().StringVar(&webhookURL, "url", "", "URL to post notifications to (required)")
+	webhookAddCmd.Flags().StringSliceVar(&webhookTypes, "type", nil, "Event types to send, e.g. DepositMade,MoneyTransferred,CommandRejected (default all)")
+	webhookAddCmd.MarkFlagRequired("url")
+
+	webhookCmd.AddCommand(webhookListCmd)
+
+	webhookCmd.AddCommand(webhookRemoveCmd)
+	webhookRemoveCmd.Flags().StringVar(&webhookID, "id", "", "Webhook ID (required)")
+	webhookRemoveCmd.MarkFlagRequired("id")
+
+	webhookCmd.AddCommand(webhookDeliveriesCmd)
+	webhookDeliveriesCmd.Flags().StringVar(&webhookStatus, "status", "", "Only show deliveries with this status: pending, delivered or dead")
+
+	webhookCmd.AddCommand(webhookReplayCmd)
+	webhookReplayCmd.Flags().StringVar(&webhookDelivery, "id", "", "Delivery ID (required)")
+	webhookReplayCmd.MarkFlagRequired("id")
+
+	webhookCmd.AddCommand(webhookDeliverCmd)
+}
@@ -3,6 +3,9 @@ package domain
 import (
 	"fmt"
 	"log"
+	"slices"
+	"sort"
+	"strings"
 
 	"github.com/shopspring/decimal"
 
@@ -18,6 +21,24 @@ type Account struct {
 	Balances map[shared.Currency]decimal.Decimal `json:"balances"`
 	Version  int                                 `json:"version"`
 
+	// SubjectID identifies the data subject the account's personal data belongs
+	// to; PersonalData is the latest sealed copy of it. Neither affects balances.
+	SubjectID    string                     `json:"subjectId,omitempty"`
+	PersonalData *events.SealedPersonalData `json:"personalData,omitempty"`
+
+	// Holders are the IDs of the customers who own the account, fixed when it
+	// is opened.
+	Holders []string `json:"holders,omitempty"`
+
+	// Closed is set once the account is closed. ReversedEvents holds the IDs
+	// of deposits and withdrawals that have been reversed, so none is
+	// reversed twice.
+	Closed         bool            `json:"closed,omitempty"`
+	ReversedEvents map[string]bool `json:"reversedEvents,omitempty"`
+
+	// Outflows tracks recent withdrawals and outgoing transfers for CheckLimits.
+	Outflows *OutflowUsage `json:"outflows,omitempty"`
+
 	changes []events.Event
 }
 
@@ -51,12 +72,30 @@ func (a *Account) handleChange(event events.Event) error {
 // create and track domain events representing the change.
 
 func (a *Account) HandleCreateAccount(id string, initialBalances map[shared.Currency]decimal.Decimal) error {
+	return a.HandleCreateAccountWithDetails(id, initialBalances, nil)
+}
+
+// HandleCreateAccountWithDetails creates the account with optional personal
+// data, already sealed under the data subject's key.
+func (a *Account) HandleCreateAccountWithDetails(id string, initialBalances map[shared.Currency]decimal.Decimal, details *events.SealedPersonalData) error {
+	return a.HandleCreateAccountWithHolders(id, initialBalances, details, nil)
+}
+
+// HandleCreateAccountWithHolders creates the account owned by the customers
+// in holders, jointly if there are several.
+func (a *Account) HandleCreateAccountWithHolders(id string, initialBalances map[shared.Currency]decimal.Decimal, details *events.SealedPersonalData, holders []string) error {
 	if a.Version > 0 {
 		return fmt.Errorf("%w: account %s (current version %d)", ErrAccountExists, a.ID, a.Version)
 	}
 	if id == "" {
 		return NewDomainError("account ID cannot be empty")
 	}
+	if IsCustomerStream(id) {
+		return NewDomainError("account ID %s cannot begin with %q, which names customer streams", id, CustomerStreamPrefix)
+	}
+	if IsApprovalStream(id) {
+		return NewDomainError("account ID %s cannot begin with %q, which names approval streams", id, ApprovalStreamPrefix)
+	}
 
 	balanceEntries := make([]shared.Balance, 0, len(initialBalances))
 	for cur, amt := range initialBalances {
@@ -65,12 +104,45 @@ func (a *Account) HandleCreateAccount(id string, initialBalances map[shared.Curr
 		}
 		balanceEntries = append(balanceEntries, shared.Balance{Currency: cur, Amount: amt})
 	}
+	for i, holder := range holders {
+		if holder == "" {
+			return NewDomainError("account holder ID cannot be empty")
+		}
+		if slices.Contains(holders[:i], holder) {
+			return NewDomainError("customer %s is named as a holder of account %s more than once", holder, id)
+		}
+	}
 
 	event := events.AccountCreatedEvent{
 		BaseEvent:       events.NewBaseEvent(id, a.Version+1, events.AccountCreatedType),
 		InitialBalances: balanceEntries,
+		PersonalData:    details,
+		Holders:         slices.Clone(holders),
+	}
+
+	return a.handleChange(event)
+}
+
+// HandleUpdateDetails replaces the account's personal data. An account's data
+// subject cannot change once set.
+func (a *Account) HandleUpdateDetails(details events.SealedPersonalData) error {
+	if a.ID == "" || a.Version == 0 {
+		return NewDomainError("cannot update details of uninitialized account")
+	}
+	if a.Closed {
+		return fmt.Errorf("%w: cannot update details of account %s", ErrAccountClosed, a.ID)
+	}
+	if details.SubjectID == "" {
+		return NewDomainError("personal data must name a data subject")
+	}
+	if a.SubjectID != "" && a.SubjectID != details.SubjectID {
+		return NewDomainError("account %s belongs to data subject %s, not %s", a.ID, a.SubjectID, details.SubjectID)
 	}
 
+	event := events.AccountDetailsUpdatedEvent{
+		BaseEvent:    events.NewBaseEvent(a.ID, a.Version+1, events.AccountDetailsUpdatedType),
+		PersonalData: details,
+	}
 	return a.handleChange(event)
 }
 
@@ -78,6 +150,9 @@ func (a *Account) HandleDeposit(amount decimal.Decimal, currency shared.Currency
 	if a.ID == "" || a.Version == 0 {
 		return NewDomainError("cannot deposit to uninitialized account")
 	}
+	if a.Closed {
+		return fmt.Errorf("%w: cannot deposit to account %s", ErrAccountClosed, a.ID)
+	}
 
 	if !amount.IsPositive() {
 		return NewDomainError("deposit amount must be positive: %s", amount.String())
@@ -95,6 +170,9 @@ func (a *Account) HandleWithdraw(amount decimal.Decimal, currency shared.Currenc
 	if a.ID == "" || a.Version == 0 {
 		return NewDomainError("cannot withdraw from uninitialized account")
 	}
+	if a.Closed {
+		return fmt.Errorf("%w: cannot withdraw from account %s", ErrAccountClosed, a.ID)
+	}
 	if !amount.IsPositive() {
 		return NewDomainError("withdrawal amount must be positive: %s", amount.String())
 	}
@@ -121,6 +199,9 @@ func (a *Account) HandleConvertCurrency(fromAmount decimal.Decimal, fromCurrency
 	if a.ID == "" || a.Version == 0 {
 		return NewDomainError("cannot convert currency for uninitialized account")
 	}
+	if a.Closed {
+		return fmt.Errorf("%w: cannot convert currency for account %s", ErrAccountClosed, a.ID)
+	}
 	if !fromAmount.IsPositive() {
 		return NewDomainError("conversion amount must be positive: %s", fromAmount.String())
 	}
@@ -158,6 +239,9 @@ func (a *Account) HandleInitiateTransfer(transferID string, targetAccountID stri
 	if a.ID == "" || a.Version == 0 {
 		return NewDomainError("cannot transfer from uninitialized account")
 	}
+	if a.Closed {
+		return fmt.Errorf("%w: cannot transfer from account %s", ErrAccountClosed, a.ID)
+	}
 	if !debitAmount.IsPositive() {
 		return NewDomainError("transfer amount must be positive: %s", debitAmount.String())
 	}
@@ -215,6 +299,9 @@ func (a *Account) HandleReceiveTransfer(transferID string, originalSourceAccount
 	if a.ID == "" || a.Version == 0 {
 		return NewDomainError("cannot apply transfer credit to uninitialized account: %s", a.ID)
 	}
+	if a.Closed {
+		return fmt.Errorf("%w: cannot transfer to account %s", ErrAccountClosed, a.ID)
+	}
 	if a.ID != originalTargetAccountID {
 		log.Printf("Error: HandleReceiveTransfer called on account %s, but event's target is %s", a.ID, originalTargetAccountID)
 		return NewDomainError("mismatch: account %s is not the target %s of this transfer credit", a.ID, originalTargetAccountID)
@@ -237,6 +324,78 @@ func (a *Account) HandleReceiveTransfer(transferID string, originalSourceAccount
 	return a.handleChange(event)
 }
 
+// HandleClose closes the account. Every balance must be zero first.
+func (a *Account) HandleClose() error {
+	if a.ID == "" || a.Version == 0 {
+		return NewDomainError("cannot close uninitialized account")
+	}
+	if a.Closed {
+		return fmt.Errorf("%w: account %s", ErrAccountClosed, a.ID)
+	}
+	currencies := make([]string, 0, len(a.Balances))
+	for cur, amt := range a.Balances {
+		if !amt.IsZero() {
+			currencies = append(currencies, string(cur))
+		}
+	}
+	if len(currencies) > 0 {
+		sort.Strings(currencies)
+		return NewDomainError("cannot close account %s: it still holds funds in %s", a.ID, strings.Join(currencies, ", "))
+	}
+
+	event := events.AccountClosedEvent{
+		BaseEvent: events.NewBaseEvent(a.ID, a.Version+1, events.AccountClosedType),
+	}
+	return a.handleChange(event)
+}
+
+// HandleReverse posts a compensating entry for original, which must be a
+// deposit or withdrawal already applied to this account and not yet reversed.
+// Reversing a deposit needs the funds to still be there.
+func (a *Account) HandleReverse(original events.Event) error {
+	if a.ID == "" || a.Version == 0 {
+		return NewDomainError("cannot reverse a transaction on uninitialized account")
+	}
+	if a.Closed {
+		return fmt.Errorf("%w: cannot reverse a transaction on account %s", ErrAccountClosed, a.ID)
+	}
+	base := original.GetBase()
+	if base.AggregateID != a.ID || base.Version > a.Version {
+		return NewDomainError("event %s does not belong to account %s", base.EventID, a.ID)
+	}
+	if a.ReversedEvents[base.EventID.String()] {
+		return NewDomainError("event %s on account %s has already been reversed", base.EventID, a.ID)
+	}
+
+	var amount decimal.Decimal
+	var currency shared.Currency
+	switch e := original.(type) {
+	case events.DepositMadeEvent:
+		amount, currency = e.Amount, e.Currency
+		currentBalance := a.getBalance(currency)
+		sufficient, _ := NewMoney(currentBalance, currency).GreaterThanOrEqual(NewMoney(amount, currency))
+		if !sufficient {
+			return fmt.Errorf("%w: reversing deposit %s needs %s %s, available %s %s",
+				ErrInsufficientFunds, base.EventID, amount.String(), currency, currentBalance.String(), currency)
+		}
+	case events.WithdrawalMadeEvent:
+		amount, currency = e.Amount, e.Currency
+	default:
+		return NewDomainError("only deposits and withdrawals can be reversed; event %s is %s", base.EventID, base.Type)
+	}
+
+	originalTimestamp := base.Timestamp
+	event := events.TransactionReversedEvent{
+		BaseEvent:         events.NewBaseEvent(a.ID, a.Version+1, events.TransactionReversedType),
+		ReversedEventID:   base.EventID.String(),
+		ReversedType:      base.Type,
+		Amount:            amount,
+		Currency:          currency,
+		OriginalTimestamp: &originalTimestamp,
+	}
+	return a.handleChange(event)
+}
+
 func (a *Account) ApplyEvent(event events.Event) error {
 	base := event.GetBase()
 
@@ -252,6 +411,15 @@ func (a *Account) ApplyEvent(event events.Event) error {
 		for _, balance := range e.InitialBalances {
 			a.Balances[balance.Currency] = balance.Amount
 		}
+		if e.PersonalData != nil {
+			a.SubjectID = e.PersonalData.SubjectID
+			a.PersonalData = e.PersonalData
+		}
+		a.Holders = slices.Clone(e.Holders)
+	case events.AccountDetailsUpdatedEvent:
+		details := e.PersonalData
+		a.SubjectID = details.SubjectID
+		a.PersonalData = &details
 	case events.DepositMadeEvent:
 		currentBalance := a.getBalance(e.Currency)
 		a.Balances[e.Currency] = currentBalance.Add(e.Amount)
@@ -264,6 +432,7 @@ func (a *Account) ApplyEvent(event events.Event) error {
 			return fmt.Errorf("invariant violation: negative balance applying %T (v%d)", event, base.Version)
 		}
 		a.Balances[e.Currency] = newBalance
+		a.recordOutflow(OutflowWithdrawal, e.Amount, e.Currency, e.Timestamp)
 	case events.CurrencyConvertedEvent:
 		currentFrom := a.getBalance(e.FromCurrency)
 		newFrom := currentFrom.Sub(e.FromAmount)
@@ -286,6 +455,7 @@ func (a *Account) ApplyEvent(event events.Event) error {
 				return fmt.Errorf("invariant violation: negative balance applying debit of %T (v%d, TransferID: %s)", event, base.Version, e.TransferID)
 			}
 			a.Balances[e.DebitedCurrency] = newBalance
+			a.recordOutflow(OutflowTransfer, e.DebitedAmount, e.DebitedCurrency, e.Timestamp)
 		} else if a.ID == e.TargetAccountID {
 			currentBalance := a.getBalance(e.CreditedCurrency)
 			a.Balances[e.CreditedCurrency] = currentBalance.Add(e.CreditedAmount)
@@ -297,6 +467,24 @@ func (a *Account) ApplyEvent(event events.Event) error {
 				a.ID, e.EventID, e.TransferID, e.SourceAccountID, e.TargetAccountID, e.GetBase().AggregateID)
 			return fmt.Errorf("misconfigured or misrouted MoneyTransferredEvent (ID: %s, TransferID: %s) for account %s", e.EventID, e.TransferID, a.ID)
 		}
+	case events.AccountClosedEvent:
+		a.Closed = true
+	case events.TransactionReversedEvent:
+		currentBalance := a.getBalance(e.Currency)
+		newBalance := currentBalance.Add(e.Delta())
+		if newBalance.IsNegative() {
+			log.Printf("CRITICAL: Invariant Violation! Account %s balance for %s negative after applying %T (v%d) of event %s: %s + %s = %s",
+				a.ID, e.Currency, event, base.Version, e.ReversedEventID, currentBalance.String(), e.Delta().String(), newBalance.String())
+			return fmt.Errorf("invariant violation: negative balance applying %T (v%d)", event, base.Version)
+		}
+		a.Balances[e.Currency] = newBalance
+		if a.ReversedEvents == nil {
+			a.ReversedEvents = make(map[string]bool)
+		}
+		a.ReversedEvents[e.ReversedEventID] = true
+		if kind, ok := reversedOutflow(e.ReversedType); ok && e.OriginalTimestamp != nil {
+			a.Outflows.release(kind, e.Amount, e.Currency, *e.OriginalTimestamp)
+		}
 	default:
 		return fmt.Errorf("apply failed: unknown event type %T for account %s", event, a.ID)
 	}
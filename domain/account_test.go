@@ -174,6 +174,54 @@ func TestAccount_HandleDeposit(t *testing.T) {
 	})
 }
 
+func TestAccount_HandleUpdateDetails(t *testing.T) {
+	acc := domain.NewAccount("acc-1")
+	_ = acc.ApplyEvent(events.AccountCreatedEvent{
+		BaseEvent:    events.NewBaseEvent("acc-1", 1, events.AccountCreatedType),
+		PersonalData: &events.SealedPersonalData{SubjectID: "subj-1", KeyID: "sk:subj-1:1"},
+	})
+	acc.GetUncommitedChanges()
+
+	t.Run("SubjectFromCreation", func(t *testing.T) {
+		if acc.SubjectID != "subj-1" || acc.PersonalData == nil {
+			t.Errorf("expected subject subj-1 with personal data, got %q, %+v", acc.SubjectID, acc.PersonalData)
+		}
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		err := acc.HandleUpdateDetails(events.SealedPersonalData{SubjectID: "subj-1", KeyID: "sk:subj-1:2"})
+		if err != nil {
+			t.Fatalf("HandleUpdateDetails failed: %v", err)
+		}
+		event := assertEvent[events.AccountDetailsUpdatedEvent](t, acc.GetUncommitedChanges())
+		if event.Version != 2 || acc.Version != 2 {
+			t.Errorf("expected version 2, got event %d, account %d", event.Version, acc.Version)
+		}
+		if acc.PersonalData.KeyID != "sk:subj-1:2" {
+			t.Errorf("expected latest personal data to be held, got %+v", acc.PersonalData)
+		}
+	})
+
+	t.Run("FailOnDifferentSubject", func(t *testing.T) {
+		err := acc.HandleUpdateDetails(events.SealedPersonalData{SubjectID: "subj-2"})
+		var domainErr *domain.DomainError
+		if !errors.As(err, &domainErr) {
+			t.Errorf("expected DomainError, got %T: %v", err, err)
+		}
+		if len(acc.GetUncommitedChanges()) != 0 {
+			t.Errorf("should not have generated events on error")
+		}
+	})
+
+	t.Run("FailOnUninitializedAccount", func(t *testing.T) {
+		err := domain.NewAccount("acc-new").HandleUpdateDetails(events.SealedPersonalData{SubjectID: "subj-1"})
+		var domainErr *domain.DomainError
+		if !errors.As(err, &domainErr) {
+			t.Errorf("expected DomainError, got %T: %v", err, err)
+		}
+	})
+}
+
 func TestAccount_HandleWithdraw(t *testing.T) {
 	acc := domain.NewAccount("acc-1")
 	_ = acc.ApplyEvent(events.AccountCreatedEvent{ // Apply initial state
@@ -615,3 +663,152 @@ func TestAccount_GetUncommitedChanges(t *testing.T) {
 		t.Fatalf("expected 0 changes after GetUncommitedChanges called, got %d", len(changes2))
 	}
 }
+
+func TestAccount_HandleClose(t *testing.T) {
+	newAccount := func(t *testing.T, usd string) *domain.Account {
+		t.Helper()
+		acc := domain.NewAccount("acc-1")
+		if err := acc.HandleCreateAccount("acc-1", map[shared.Currency]decimal.Decimal{shared.USD: dec(usd)}); err != nil {
+			t.Fatalf("HandleCreateAccount failed: %v", err)
+		}
+		acc.GetUncommitedChanges()
+		return acc
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		acc := newAccount(t, "0")
+		if err := acc.HandleClose(); err != nil {
+			t.Fatalf("HandleClose failed: %v", err)
+		}
+		assertEvent[events.AccountClosedEvent](t, acc.GetUncommitedChanges())
+		if !acc.Closed {
+			t.Error("expected the account to be closed")
+		}
+	})
+
+	t.Run("FailWhileHoldingFunds", func(t *testing.T) {
+		acc := newAccount(t, "0.01")
+		err := acc.HandleClose()
+		var domainErr *domain.DomainError
+		if !errors.As(err, &domainErr) {
+			t.Fatalf("expected DomainError, got %T: %v", err, err)
+		}
+		if acc.Closed || len(acc.GetUncommitedChanges()) != 0 {
+			t.Error("should not have closed the account")
+		}
+	})
+
+	t.Run("ClosedAccountRejectsCommands", func(t *testing.T) {
+		acc := newAccount(t, "0")
+		_ = acc.HandleClose()
+		acc.GetUncommitedChanges()
+
+		checks := map[string]error{
+			"deposit":  acc.HandleDeposit(dec("1"), shared.USD),
+			"withdraw": acc.HandleWithdraw(dec("1"), shared.USD),
+			"transfer": acc.HandleReceiveTransfer("t-1", "acc-2", "acc-1", dec("1"), shared.USD, dec("1"), shared.USD, dec("1")),
+			"close":    acc.HandleClose(),
+		}
+		for name, err := range checks {
+			if !errors.Is(err, domain.ErrAccountClosed) {
+				t.Errorf("%s: expected ErrAccountClosed, got %v", name, err)
+			}
+		}
+		if len(acc.GetUncommitedChanges()) != 0 {
+			t.Error("should not have generated events on a closed account")
+		}
+	})
+}
+
+func TestAccount_HandleReverse(t *testing.T) {
+	setup := func(t *testing.T) (*domain.Account, events.Event, events.Event) {
+		t.Helper()
+		acc := domain.NewAccount("acc-1")
+		_ = acc.HandleCreateAccount("acc-1", map[shared.Currency]decimal.Decimal{shared.USD: dec("100")})
+		_ = acc.HandleDeposit(dec("30"), shared.USD)
+		_ = acc.HandleWithdraw(dec("20"), shared.USD)
+		changes := acc.GetUncommitedChanges()
+		return acc, changes[1], changes[2]
+	}
+
+	t.Run("ReverseDeposit", func(t *testing.T) {
+		acc, deposit, _ := setup(t)
+		if err := acc.HandleReverse(deposit); err != nil {
+			t.Fatalf("HandleReverse failed: %v", err)
+		}
+		event := assertEvent[events.TransactionReversedEvent](t, acc.GetUncommitedChanges())
+		if event.ReversedEventID != deposit.GetBase().EventID.String() || event.ReversedType != events.DepositMadeType {
+			t.Errorf("unexpected reversal %+v", event)
+		}
+		if !acc.Balances[shared.USD].Equal(dec("80")) {
+			t.Errorf("expected balance 80, got %s", acc.Balances[shared.USD])
+		}
+	})
+
+	t.Run("ReverseWithdrawal", func(t *testing.T) {
+		acc, _, withdrawal := setup(t)
+		if err := acc.HandleReverse(withdrawal); err != nil {
+			t.Fatalf("HandleReverse failed: %v", err)
+		}
+		if !acc.Balances[shared.USD].Equal(dec("130")) {
+			t.Errorf("expected balance 130, got %s", acc.Balances[shared.USD])
+		}
+	})
+
+	t.Run("FailOnSecondReversal", func(t *testing.T) {
+		acc, deposit, _ := setup(t)
+		_ = acc.HandleReverse(deposit)
+		acc.GetUncommitedChanges()
+		err := acc.HandleReverse(deposit)
+		var domainErr *domain.DomainError
+		if !errors.As(err, &domainErr) {
+			t.Errorf("expected DomainError, got %T: %v", err, err)
+		}
+	})
+
+	t.Run("FailWhenDepositWasSpent", func(t *testing.T) {
+		acc, deposit, _ := setup(t)
+		_ = acc.HandleWithdraw(dec("100"), shared.USD)
+		acc.GetUncommitedChanges()
+		if err := acc.HandleReverse(deposit); !errors.Is(err, domain.ErrInsufficientFunds) {
+			t.Errorf("expected ErrInsufficientFunds, got %v", err)
+		}
+	})
+
+	t.Run("FailOnOtherEvents", func(t *testing.T) {
+		acc, _, _ := setup(t)
+		created := events.AccountCreatedEvent{BaseEvent: events.NewBaseEvent("acc-1", 1, events.AccountCreatedType)}
+		foreign := events.DepositMadeEvent{BaseEvent: events.NewBaseEvent("acc-2", 2, events.DepositMadeType), Amount: dec("1"), Currency: shared.USD}
+		for _, original := range []events.Event{created, foreign} {
+			var domainErr *domain.DomainError
+			if err := acc.HandleReverse(original); !errors.As(err, &domainErr) {
+				t.Errorf("%T on %s: expected DomainError, got %v", original, original.GetBase().AggregateID, err)
+			}
+		}
+	})
+}
+
+func TestAccount_Holders(t *testing.T) {
+	acc := domain.NewAccount("acc-1")
+	if err := acc.HandleCreateAccountWithHolders("acc-1", nil, nil, []string{"cust-1", "cust-1"}); err == nil {
+		t.Error("expected a holder named twice to be refused")
+	}
+	if err := acc.HandleCreateAccountWithHolders("acc-1", nil, nil, []string{"cust-1", ""}); err == nil {
+		t.Error("expected an empty holder to be refused")
+	}
+
+	holders := []string{"cust-1", "cust-2"}
+	if err := acc.HandleCreateAccountWithHolders("acc-1", nil, nil, holders); err != nil {
+		t.Fatalf("HandleCreateAccountWithHolders failed: %v", err)
+	}
+	holders[0] = "cust-9"
+	created := assertEvent[events.AccountCreatedEvent](t, acc.GetUncommitedChanges())
+	if len(created.Holders) != 2 || created.Holders[0] != "cust-1" || acc.Holders[1] != "cust-2" {
+		t.Errorf("expected joint holders cust-1 and cust-2, got event %v, account %v", created.Holders, acc.Holders)
+	}
+
+	replayed := domain.NewAccount("acc-1")
+	if err := replayed.ApplyEvent(created); err != nil || len(replayed.Holders) != 2 {
+		t.Errorf("expected holders after replay, got %v, %v", replayed.Holders, err)
+	}
+}
@@ -0,0 +1,320 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"financial-ledger/events"
+)
+
+// ApprovalKind names the command an approval holds.
+type ApprovalKind string
+
+const (
+	ApprovalKindTransfer       ApprovalKind = "transfer"
+	ApprovalKindCreateAccount  ApprovalKind = "create-account"
+	ApprovalKindCreateCustomer ApprovalKind = "create-customer"
+	ApprovalKindCloseAccount   ApprovalKind = "close-account"
+	ApprovalKindReversal       ApprovalKind = "reversal"
+)
+
+type ApprovalStatus string
+
+const (
+	// ApprovalPending is waiting for approvers.
+	ApprovalPending ApprovalStatus = "pending"
+	// ApprovalExecuted was approved and its command succeeded.
+	ApprovalExecuted ApprovalStatus = "executed"
+	// ApprovalFailed was approved but its command was refused when executed.
+	ApprovalFailed   ApprovalStatus = "failed"
+	ApprovalRejected ApprovalStatus = "rejected"
+	ApprovalExpired  ApprovalStatus = "expired"
+)
+
+// Decided reports whether the approval can no longer be approved or rejected.
+func (s ApprovalStatus) Decided() bool {
+	return s != ApprovalPending
+}
+
+// ApprovalStreamPrefix begins the ID of every approval's event stream, which
+// keeps approvals apart from accounts in one event store.
+const ApprovalStreamPrefix = "approval:"
+
+// ApprovalStreamID is the ID of the event stream of the approval approvalID.
+func ApprovalStreamID(approvalID string) string {
+	return ApprovalStreamPrefix + approvalID
+}
+
+// IsApprovalStream reports whether aggregateID is an approval's stream.
+func IsApprovalStream(aggregateID string) bool {
+	return strings.HasPrefix(aggregateID, ApprovalStreamPrefix)
+}
+
+// IsAccountStream reports whether aggregateID is an account's stream, rather
+// than a customer's or an approval's.
+func IsAccountStream(aggregateID string) bool {
+	return !IsCustomerStream(aggregateID) && !IsApprovalStream(aggregateID)
+}
+
+// ApprovalDecision is one approver's sign-off.
+type ApprovalDecision struct {
+	Actor string    `json:"actor"`
+	At    time.Time `json:"at"`
+}
+
+// Approval is a command held back until RequiredApprovals users other than
+// its requester have approved it (maker-checker), an aggregate with its own
+// event stream. Command is the proposed command, encoded by the application
+// layer; AccountIDs are the accounts it touches, and Target names what it
+// acts on, so that one action is not held twice. Once decided, Reason
+// records why it was rejected, expired or failed.
+type Approval struct {
+	ID                string             `json:"id"`
+	Kind              ApprovalKind       `json:"kind"`
+	Target            string             `json:"target,omitempty"`
+	AccountIDs        []string           `json:"accountIds"`
+	Summary           string             `json:"summary"`
+	Command           json.RawMessage    `json:"command"`
+	RequestedBy       string             `json:"requestedBy"`
+	RequestedAt       time.Time          `json:"requestedAt"`
+	ExpiresAt         time.Time          `json:"expiresAt"`
+	RequiredApprovals int                `json:"requiredApprovals"`
+	Approvals         []ApprovalDecision `json:"approvals,omitempty"`
+	Status            ApprovalStatus     `json:"status"`
+	DecidedBy         string             `json:"decidedBy,omitempty"`
+	DecidedAt         time.Time          `json:"decidedAt"`
+	Reason            string             `json:"reason,omitempty"`
+	Version           int                `json:"version"`
+
+	changes []events.Event
+}
+
+func NewApproval(id string) *Approval {
+	return &Approval{ID: id, changes: make([]events.Event, 0)}
+}
+
+func (a *Approval) GetUncommitedChanges() []events.Event {
+	unCommittedChanges := a.changes
+	a.changes = make([]events.Event, 0)
+	return unCommittedChanges
+}
+
+func (a *Approval) handleChange(event events.Event) error {
+	if err := a.ApplyEvent(event); err != nil {
+		log.Printf("ERROR: Internal Apply failed for event %T on approval %s: %v", event, a.ID, err)
+		return fmt.Errorf("internal error applying event %T: %w", event, err)
+	}
+	a.changes = append(a.changes, event)
+	return nil
+}
+
+// newEvent returns the base of the approval's next event, timestamped now.
+func (a *Approval) newEvent(eventType events.EventType, now time.Time) events.BaseEvent {
+	base := events.NewBaseEvent(ApprovalStreamID(a.ID), a.Version+1, eventType)
+	base.Timestamp = now
+	return base
+}
+
+// HandleRequest opens the approval, requested by requestedBy at now and
+// expiring after ttl. The requester must be named so that they can be kept
+// from approving their own request.
+func (a *Approval) HandleRequest(kind ApprovalKind, target string, accountIDs []string, summary string, command json.RawMessage, requestedBy string, requiredApprovals int, now time.Time, ttl time.Duration) error {
+	if a.Version > 0 {
+		return fmt.Errorf("%w: %s", ErrApprovalExists, a.ID)
+	}
+	if a.ID == "" {
+		return NewDomainError("approval ID cannot be empty")
+	}
+	if requestedBy == "" {
+		return NewDomainError("a %s needing approval must name its requester", kind)
+	}
+	if requiredApprovals < 1 {
+		return NewDomainError("an approval needs at least one approver, got %d", requiredApprovals)
+	}
+	if ttl <= 0 {
+		return NewDomainError("approval lifetime must be positive, got %s", ttl)
+	}
+
+	event := events.ApprovalRequestedEvent{
+		BaseEvent:         a.newEvent(events.ApprovalRequestedType, now),
+		ApprovalID:        a.ID,
+		Kind:              string(kind),
+		Target:            target,
+		AccountIDs:        slices.Clone(accountIDs),
+		Summary:           summary,
+		Command:           command,
+		RequestedBy:       requestedBy,
+		RequiredApprovals: requiredApprovals,
+		ExpiresAt:         now.Add(ttl),
+	}
+	return a.handleChange(event)
+}
+
+// HandleApprove records actor's approval at now. It reports whether the
+// approval now has every approval it needs, in which case the command should
+// be executed and its outcome recorded with HandleOutcome before the changes
+// are saved, so that the last approval is never stored without the outcome.
+// The requester cannot approve their own request, and nobody can approve
+// twice. An approval past its expiry is expired instead, and an error
+// returned.
+func (a *Approval) HandleApprove(actor string, now time.Time) (bool, error) {
+	if err := a.checkPending(now); err != nil {
+		return false, err
+	}
+	if actor == "" {
+		return false, NewDomainError("approval %s: the approver must be named", a.ID)
+	}
+	if actor == a.RequestedBy {
+		return false, NewDomainError("approval %s: %s requested it and cannot also approve it", a.ID, actor)
+	}
+	for _, d := range a.Approvals {
+		if d.Actor == actor {
+			return false, NewDomainError("approval %s: %s has already approved it", a.ID, actor)
+		}
+	}
+
+	event := events.ApprovalGrantedEvent{
+		BaseEvent:  a.newEvent(events.ApprovalGrantedType, now),
+		ApprovalID: a.ID,
+		Approver:   actor,
+	}
+	if err := a.handleChange(event); err != nil {
+		return false, err
+	}
+	return a.approved(), nil
+}
+
+// HandleOutcome records the result of executing the approved command.
+func (a *Approval) HandleOutcome(err error, now time.Time) error {
+	if a.Status.Decided() || !a.approved() {
+		return NewDomainError("approval %s is %s with %d of %d approvals and cannot have been executed", a.ID, a.Status, len(a.Approvals), a.RequiredApprovals)
+	}
+	event := events.ApprovalExecutedEvent{
+		BaseEvent:  a.newEvent(events.ApprovalExecutedType, now),
+		ApprovalID: a.ID,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	return a.handleChange(event)
+}
+
+// HandleReject turns the request down. A reason is required.
+func (a *Approval) HandleReject(actor, reason string, now time.Time) error {
+	if err := a.checkPending(now); err != nil {
+		return err
+	}
+	if actor == "" {
+		return NewDomainError("approval %s: whoever rejects it must be named", a.ID)
+	}
+	if reason == "" {
+		return NewDomainError("approval %s: a rejection needs a reason", a.ID)
+	}
+	event := events.ApprovalRejectedEvent{
+		BaseEvent:  a.newEvent(events.ApprovalRejectedType, now),
+		ApprovalID: a.ID,
+		RejectedBy: actor,
+		Reason:     reason,
+	}
+	return a.handleChange(event)
+}
+
+// HandleExpire expires a pending approval whose expiry has passed at now, and
+// reports whether it did.
+func (a *Approval) HandleExpire(now time.Time) (bool, error) {
+	if a.Status != ApprovalPending || a.approved() || now.Before(a.ExpiresAt) {
+		return false, nil
+	}
+	event := events.ApprovalExpiredEvent{
+		BaseEvent:  a.newEvent(events.ApprovalExpiredType, now),
+		ApprovalID: a.ID,
+		Reason:     fmt.Sprintf("not approved before %s", a.ExpiresAt.Format(time.RFC3339)),
+	}
+	if err := a.handleChange(event); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// approved reports whether the approval has every approval it needs.
+func (a *Approval) approved() bool {
+	return len(a.Approvals) >= a.RequiredApprovals
+}
+
+func (a *Approval) checkPending(now time.Time) error {
+	expired, err := a.HandleExpire(now)
+	if err != nil {
+		return err
+	}
+	if expired {
+		return NewDomainError("approval %s expired at %s", a.ID, a.ExpiresAt.Format(time.RFC3339))
+	}
+	if a.Status.Decided() {
+		return NewDomainError("approval %s is already %s", a.ID, a.Status)
+	}
+	return nil
+}
+
+func (a *Approval) ApplyEvent(event events.Event) error {
+	base := event.GetBase()
+
+	if base.Version != a.Version+1 {
+		return fmt.Errorf("apply failed: event version mismatch for approval %s: expected %d, got %d for event %T (%s)",
+			a.ID, a.Version+1, base.Version, event, base.EventID)
+	}
+
+	switch e := event.(type) {
+	case events.ApprovalRequestedEvent:
+		a.ID = e.ApprovalID
+		a.Kind = ApprovalKind(e.Kind)
+		a.Target = e.Target
+		a.AccountIDs = slices.Clone(e.AccountIDs)
+		a.Summary = e.Summary
+		a.Command = e.Command
+		a.RequestedBy = e.RequestedBy
+		a.RequestedAt = e.Timestamp
+		a.ExpiresAt = e.ExpiresAt
+		a.RequiredApprovals = e.RequiredApprovals
+		a.Status = ApprovalPending
+	case events.ApprovalGrantedEvent:
+		a.Approvals = append(a.Approvals, ApprovalDecision{Actor: e.Approver, At: e.Timestamp})
+	case events.ApprovalExecutedEvent:
+		a.Status = ApprovalExecuted
+		if e.Error != "" {
+			a.Status = ApprovalFailed
+			a.Reason = e.Error
+		}
+		if n := len(a.Approvals); n > 0 {
+			a.DecidedBy = a.Approvals[n-1].Actor
+			a.DecidedAt = a.Approvals[n-1].At
+		}
+	case events.ApprovalRejectedEvent:
+		a.Status = ApprovalRejected
+		a.DecidedBy = e.RejectedBy
+		a.DecidedAt = e.Timestamp
+		a.Reason = e.Reason
+	case events.ApprovalExpiredEvent:
+		a.Status = ApprovalExpired
+		a.DecidedAt = e.Timestamp
+		a.Reason = e.Reason
+	default:
+		return fmt.Errorf("apply failed: unknown event type %T for approval %s", event, a.ID)
+	}
+
+	a.Version = base.Version
+	return nil
+}
+
+func (a *Approval) ApplyEvents(history []events.Event) error {
+	for _, event := range history {
+		if err := a.ApplyEvent(event); err != nil {
+			base := event.GetBase()
+			return fmt.Errorf("failed to apply event %s (%T) at version %d during reconstruction of approval %s: %w", base.EventID, event, base.Version, a.ID, err)
+		}
+	}
+	return nil
+}
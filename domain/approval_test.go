@@ -0,0 +1,130 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"financial-ledger/domain"
+)
+
+func TestApproval(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	open := func(t *testing.T, required int) *domain.Approval {
+		t.Helper()
+		a := domain.NewApproval("ap-1")
+		if err := a.HandleRequest(domain.ApprovalKindTransfer, "", []string{"acc-1", "acc-2"}, "transfer", []byte(`{}`), "alice", required, now, time.Hour); err != nil {
+			t.Fatalf("HandleRequest failed: %v", err)
+		}
+		return a
+	}
+	isDomainError := func(err error) bool {
+		var domainErr *domain.DomainError
+		return errors.As(err, &domainErr)
+	}
+
+	t.Run("RequesterMustBeNamed", func(t *testing.T) {
+		err := domain.NewApproval("ap-1").HandleRequest(domain.ApprovalKindTransfer, "", nil, "transfer", nil, "", 1, now, time.Hour)
+		if !isDomainError(err) {
+			t.Errorf("expected DomainError, got %v", err)
+		}
+	})
+
+	t.Run("RequesterCannotApprove", func(t *testing.T) {
+		a := open(t, 1)
+		if _, err := a.HandleApprove("alice", now); !isDomainError(err) {
+			t.Fatalf("expected DomainError, got %v", err)
+		}
+		if a.Status != domain.ApprovalPending || len(a.Approvals) != 0 {
+			t.Errorf("self-approval changed the approval: %+v", a)
+		}
+	})
+
+	t.Run("SecondUserApproves", func(t *testing.T) {
+		a := open(t, 1)
+		complete, err := a.HandleApprove("bob", now)
+		if err != nil || !complete {
+			t.Fatalf("HandleApprove = %v, %v; want complete", complete, err)
+		}
+		if err := a.HandleOutcome(nil, now); err != nil {
+			t.Fatalf("HandleOutcome failed: %v", err)
+		}
+		if a.Status != domain.ApprovalExecuted || a.DecidedBy != "bob" {
+			t.Errorf("unexpected approval %+v", a)
+		}
+		if _, err := a.HandleApprove("carol", now); !isDomainError(err) {
+			t.Errorf("expected a decided approval to refuse approvals, got %v", err)
+		}
+	})
+
+	t.Run("SeveralApprovers", func(t *testing.T) {
+		a := open(t, 2)
+		if complete, err := a.HandleApprove("bob", now); err != nil || complete {
+			t.Fatalf("first HandleApprove = %v, %v; want incomplete", complete, err)
+		}
+		if err := a.HandleOutcome(nil, now); !isDomainError(err) {
+			t.Errorf("expected an outcome before every approval to be refused, got %v", err)
+		}
+		if _, err := a.HandleApprove("bob", now); !isDomainError(err) {
+			t.Errorf("expected a second approval by bob to fail, got %v", err)
+		}
+		if complete, err := a.HandleApprove("carol", now); err != nil || !complete {
+			t.Fatalf("second HandleApprove = %v, %v; want complete", complete, err)
+		}
+	})
+
+	t.Run("FailedExecution", func(t *testing.T) {
+		a := open(t, 1)
+		_, _ = a.HandleApprove("bob", now)
+		_ = a.HandleOutcome(errors.New("insufficient funds"), now)
+		if a.Status != domain.ApprovalFailed || a.Reason != "insufficient funds" {
+			t.Errorf("unexpected approval %+v", a)
+		}
+	})
+
+	t.Run("Reject", func(t *testing.T) {
+		a := open(t, 1)
+		if err := a.HandleReject("bob", "", now); !isDomainError(err) {
+			t.Errorf("expected a rejection without reason to fail, got %v", err)
+		}
+		if err := a.HandleReject("bob", "wrong account", now); err != nil {
+			t.Fatalf("Reject failed: %v", err)
+		}
+		if a.Status != domain.ApprovalRejected || a.DecidedBy != "bob" || a.Reason != "wrong account" {
+			t.Errorf("unexpected approval %+v", a)
+		}
+	})
+
+	t.Run("Expire", func(t *testing.T) {
+		a := open(t, 1)
+		if expired, _ := a.HandleExpire(now.Add(59 * time.Minute)); expired {
+			t.Error("expired too early")
+		}
+		if _, err := a.HandleApprove("bob", now.Add(time.Hour)); !isDomainError(err) {
+			t.Fatalf("expected approving an expired approval to fail, got %v", err)
+		}
+		if a.Status != domain.ApprovalExpired || a.Reason == "" || len(a.Approvals) != 0 {
+			t.Errorf("unexpected approval %+v", a)
+		}
+	})
+
+	t.Run("ReplayedFromEvents", func(t *testing.T) {
+		a := open(t, 2)
+		_, _ = a.HandleApprove("bob", now)
+		_, _ = a.HandleApprove("carol", now.Add(time.Minute))
+		_ = a.HandleOutcome(nil, now.Add(time.Minute))
+		history := a.GetUncommitedChanges()
+		if len(history) != 4 || history[0].GetBase().AggregateID != domain.ApprovalStreamID("ap-1") {
+			t.Fatalf("expected request, two approvals and outcome in the approval's stream, got %+v", history)
+		}
+
+		replayed := domain.NewApproval("ap-1")
+		if err := replayed.ApplyEvents(history); err != nil {
+			t.Fatalf("ApplyEvents failed: %v", err)
+		}
+		if replayed.Status != domain.ApprovalExecuted || replayed.Version != 4 || len(replayed.Approvals) != 2 ||
+			replayed.DecidedBy != "carol" || !replayed.RequestedAt.Equal(now) || !replayed.ExpiresAt.Equal(now.Add(time.Hour)) {
+			t.Errorf("unexpected replayed approval %+v", replayed)
+		}
+	})
+}
@@ -0,0 +1,190 @@
+package domain
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"financial-ledger/events"
+)
+
+// KYCTier is how thoroughly a customer's identity has been verified.
+type KYCTier string
+
+const (
+	// KYCTierNone has not been verified.
+	KYCTierNone KYCTier = "none"
+	// KYCTierSimplified was verified with simplified due diligence.
+	KYCTierSimplified KYCTier = "simplified"
+	// KYCTierStandard was verified with standard customer due diligence.
+	KYCTierStandard KYCTier = "standard"
+	// KYCTierEnhanced was verified with enhanced due diligence, as required
+	// for higher-risk customers.
+	KYCTierEnhanced KYCTier = "enhanced"
+)
+
+// ParseKYCTier accepts a tier name in any case.
+func ParseKYCTier(s string) (KYCTier, error) {
+	tier := KYCTier(strings.ToLower(strings.TrimSpace(s)))
+	switch tier {
+	case KYCTierNone, KYCTierSimplified, KYCTierStandard, KYCTierEnhanced:
+		return tier, nil
+	}
+	return "", NewDomainError("unknown KYC tier %q: use none, simplified, standard or enhanced", s)
+}
+
+// CustomerStreamPrefix begins the ID of every customer's event stream, which
+// keeps customers apart from accounts of the same ID in one event store.
+const CustomerStreamPrefix = "customer:"
+
+// CustomerStreamID is the ID of the event stream of the customer customerID.
+func CustomerStreamID(customerID string) string {
+	return CustomerStreamPrefix + customerID
+}
+
+// IsCustomerStream reports whether aggregateID is a customer's stream rather
+// than an account's.
+func IsCustomerStream(aggregateID string) bool {
+	return strings.HasPrefix(aggregateID, CustomerStreamPrefix)
+}
+
+// Customer is an account holder, an aggregate with its own event stream. An
+// account may be held by several customers (joint ownership) and a customer
+// may hold several accounts; the accounts record their holders when they are
+// opened. Details holds the customer's name, identifiers and contact details,
+// sealed under a key belonging to the customer as data subject, so forgetting
+// the customer erases them.
+type Customer struct {
+	ID        string                    `json:"id"`
+	Details   events.SealedPersonalData `json:"details"`
+	KYCTier   KYCTier                   `json:"kycTier"`
+	CreatedAt time.Time                 `json:"createdAt"`
+	UpdatedAt time.Time                 `json:"updatedAt"`
+	Version   int                       `json:"version"`
+
+	changes []events.Event
+}
+
+func NewCustomer(id string) *Customer {
+	return &Customer{ID: id, changes: make([]events.Event, 0)}
+}
+
+func (c *Customer) GetUncommitedChanges() []events.Event {
+	unCommittedChanges := c.changes
+	c.changes = make([]events.Event, 0)
+	return unCommittedChanges
+}
+
+func (c *Customer) handleChange(event events.Event) error {
+	if err := c.ApplyEvent(event); err != nil {
+		log.Printf("ERROR: Internal Apply failed for event %T on customer %s: %v", event, c.ID, err)
+		return fmt.Errorf("internal error applying event %T: %w", event, err)
+	}
+	c.changes = append(c.changes, event)
+	return nil
+}
+
+// HandleRegister registers the customer with details sealed under the
+// customer's own subject key. The tier defaults to KYCTierNone.
+func (c *Customer) HandleRegister(details events.SealedPersonalData, tier KYCTier) error {
+	if c.Version > 0 {
+		return fmt.Errorf("%w: customer %s (current version %d)", ErrCustomerExists, c.ID, c.Version)
+	}
+	if c.ID == "" {
+		return NewDomainError("customer ID cannot be empty")
+	}
+	if tier == "" {
+		tier = KYCTierNone
+	}
+	tier, err := c.checkUpdate(&details, tier)
+	if err != nil {
+		return err
+	}
+
+	event := events.CustomerRegisteredEvent{
+		BaseEvent:  events.NewBaseEvent(CustomerStreamID(c.ID), c.Version+1, events.CustomerRegisteredType),
+		CustomerID: c.ID,
+		Details:    details,
+		KYCTier:    string(tier),
+	}
+	return c.handleChange(event)
+}
+
+// HandleUpdate replaces the customer's details, if given, and KYC tier, if set.
+func (c *Customer) HandleUpdate(details *events.SealedPersonalData, tier KYCTier) error {
+	if c.Version == 0 {
+		return fmt.Errorf("%w: %s", ErrCustomerNotFound, c.ID)
+	}
+	if details == nil && tier == "" {
+		return NewDomainError("nothing to update for customer %s", c.ID)
+	}
+	tier, err := c.checkUpdate(details, tier)
+	if err != nil {
+		return err
+	}
+
+	event := events.CustomerDetailsUpdatedEvent{
+		BaseEvent:  events.NewBaseEvent(CustomerStreamID(c.ID), c.Version+1, events.CustomerDetailsUpdatedType),
+		CustomerID: c.ID,
+		Details:    details,
+		KYCTier:    string(tier),
+	}
+	return c.handleChange(event)
+}
+
+// checkUpdate checks that details belong to the customer and returns tier
+// parsed, or empty if it is.
+func (c *Customer) checkUpdate(details *events.SealedPersonalData, tier KYCTier) (KYCTier, error) {
+	if details != nil && details.SubjectID != c.ID {
+		return "", NewDomainError("customer %s: details must belong to the customer, not data subject %q", c.ID, details.SubjectID)
+	}
+	if tier == "" {
+		return "", nil
+	}
+	parsed, err := ParseKYCTier(string(tier))
+	if err != nil {
+		return "", fmt.Errorf("customer %s: %w", c.ID, err)
+	}
+	return parsed, nil
+}
+
+func (c *Customer) ApplyEvent(event events.Event) error {
+	base := event.GetBase()
+
+	if base.Version != c.Version+1 {
+		return fmt.Errorf("apply failed: event version mismatch for customer %s: expected %d, got %d for event %T (%s)",
+			c.ID, c.Version+1, base.Version, event, base.EventID)
+	}
+
+	switch e := event.(type) {
+	case events.CustomerRegisteredEvent:
+		c.ID = e.CustomerID
+		c.Details = e.Details
+		c.KYCTier = KYCTier(e.KYCTier)
+		c.CreatedAt = e.Timestamp
+	case events.CustomerDetailsUpdatedEvent:
+		if e.Details != nil {
+			c.Details = *e.Details
+		}
+		if e.KYCTier != "" {
+			c.KYCTier = KYCTier(e.KYCTier)
+		}
+	default:
+		return fmt.Errorf("apply failed: unknown event type %T for customer %s", event, c.ID)
+	}
+
+	c.UpdatedAt = base.Timestamp
+	c.Version = base.Version
+	return nil
+}
+
+func (c *Customer) ApplyEvents(history []events.Event) error {
+	for _, event := range history {
+		if err := c.ApplyEvent(event); err != nil {
+			base := event.GetBase()
+			return fmt.Errorf("failed to apply event %s (%T) at version %d during reconstruction of customer %s: %w", base.EventID, event, base.Version, c.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+
+	"financial-ledger/domain"
+	"financial-ledger/events"
+)
+
+func TestCustomer(t *testing.T) {
+	details := events.SealedPersonalData{SubjectID: "cust-1", Data: []byte("sealed")}
+	isDomainError := func(err error) bool {
+		var domainErr *domain.DomainError
+		return errors.As(err, &domainErr)
+	}
+
+	t.Run("Register", func(t *testing.T) {
+		c := domain.NewCustomer("cust-1")
+		if err := c.HandleRegister(details, ""); err != nil {
+			t.Fatalf("HandleRegister failed: %v", err)
+		}
+		if c.Version != 1 || c.KYCTier != domain.KYCTierNone || c.CreatedAt.IsZero() || !c.UpdatedAt.Equal(c.CreatedAt) {
+			t.Errorf("unexpected customer %+v", c)
+		}
+		changes := c.GetUncommitedChanges()
+		if len(changes) != 1 || changes[0].GetBase().AggregateID != domain.CustomerStreamID("cust-1") {
+			t.Fatalf("expected one event in the customer's stream, got %+v", changes)
+		}
+		if err := c.HandleRegister(details, ""); !errors.Is(err, domain.ErrCustomerExists) {
+			t.Errorf("expected ErrCustomerExists, got %v", err)
+		}
+		if err := domain.NewCustomer("").HandleRegister(details, ""); !isDomainError(err) {
+			t.Errorf("expected DomainError for an empty ID, got %v", err)
+		}
+		if err := domain.NewCustomer("cust-2").HandleRegister(details, ""); !isDomainError(err) {
+			t.Errorf("expected DomainError for another subject's details, got %v", err)
+		}
+
+		replayed := domain.NewCustomer("cust-1")
+		if err := replayed.ApplyEvents(changes); err != nil || replayed.Version != 1 || string(replayed.Details.Data) != "sealed" {
+			t.Errorf("expected replay to rebuild the customer, got %+v, %v", replayed, err)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		c := domain.NewCustomer("cust-1")
+		if err := c.HandleUpdate(nil, domain.KYCTierStandard); !errors.Is(err, domain.ErrCustomerNotFound) {
+			t.Errorf("expected ErrCustomerNotFound before registration, got %v", err)
+		}
+		_ = c.HandleRegister(details, domain.KYCTierSimplified)
+		if err := c.HandleUpdate(nil, "Enhanced"); err != nil {
+			t.Fatalf("HandleUpdate failed: %v", err)
+		}
+		if c.Version != 2 || c.KYCTier != domain.KYCTierEnhanced || string(c.Details.Data) != "sealed" {
+			t.Errorf("expected only the tier to change, got %+v", c)
+		}
+		if err := c.HandleUpdate(nil, "gold"); !isDomainError(err) || c.KYCTier != domain.KYCTierEnhanced {
+			t.Errorf("expected an unknown tier to be refused, got %v, tier %s", err, c.KYCTier)
+		}
+		if err := c.HandleUpdate(nil, ""); !isDomainError(err) {
+			t.Errorf("expected an empty update to be refused, got %v", err)
+		}
+	})
+
+	t.Run("StreamIDs", func(t *testing.T) {
+		if !domain.IsCustomerStream(domain.CustomerStreamID("cust-1")) || domain.IsCustomerStream("cust-1") {
+			t.Error("expected only customer stream IDs to be recognised")
+		}
+		if err := domain.NewAccount("x").HandleCreateAccount(domain.CustomerStreamID("cust-1"), nil); !isDomainError(err) {
+			t.Errorf("expected an account ID naming a customer stream to be refused, got %v", err)
+		}
+	})
+}
@@ -18,4 +18,10 @@ var (
 	ErrInsufficientFunds = NewDomainError("insufficient funds")
 	ErrAccountExists     = NewDomainError("account already exists")
 	ErrAccountNotFound   = NewDomainError("account not found")
+	ErrAccountClosed     = NewDomainError("account is closed")
+	ErrCustomerExists    = NewDomainError("customer already exists")
+	ErrCustomerNotFound  = NewDomainError("customer not found")
+	ErrApprovalExists    = NewDomainError("approval already exists")
+	ErrApprovalNotFound  = NewDomainError("approval not found")
+	ErrApprovalPending   = NewDomainError("an approval is already pending")
 )
@@ -0,0 +1,273 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/events"
+	"financial-ledger/shared"
+)
+
+// ErrLimitExceeded is matched by every *LimitExceededError.
+var ErrLimitExceeded = NewDomainError("limit exceeded")
+
+// OutflowKind is a kind of money movement out of an account that limits apply to.
+type OutflowKind string
+
+const (
+	OutflowWithdrawal OutflowKind = "withdrawal"
+	OutflowTransfer   OutflowKind = "transfer"
+)
+
+// LimitKind names a single limit.
+type LimitKind string
+
+const (
+	LimitPerTransaction    LimitKind = "per-transaction"
+	LimitDaily             LimitKind = "daily"
+	LimitMonthly           LimitKind = "monthly"
+	LimitOperationsPerHour LimitKind = "operations-per-hour"
+)
+
+// operationsWindow is the rolling window MaxOperationsPerHour is counted over.
+const operationsWindow = time.Hour
+
+// AmountLimits caps one kind of outflow in one currency. A zero value means
+// no limit. Days and months are calendar days and months in UTC.
+type AmountLimits struct {
+	PerTransaction decimal.Decimal `json:"perTransaction"`
+	Daily          decimal.Decimal `json:"daily"`
+	Monthly        decimal.Decimal `json:"monthly"`
+}
+
+// Limits are the transaction limits and velocity controls for an account.
+// MaxOperationsPerHour counts withdrawals and outgoing transfers together
+// over a rolling hour; zero means no limit.
+type Limits struct {
+	Withdrawal           map[shared.Currency]AmountLimits `json:"withdrawal,omitempty"`
+	Transfer             map[shared.Currency]AmountLimits `json:"transfer,omitempty"`
+	MaxOperationsPerHour int                              `json:"maxOperationsPerHour,omitempty"`
+}
+
+// IsZero reports whether no limit is set.
+func (l Limits) IsZero() bool {
+	return len(l.Withdrawal) == 0 && len(l.Transfer) == 0 && l.MaxOperationsPerHour == 0
+}
+
+func (l Limits) amounts(kind OutflowKind, currency shared.Currency) AmountLimits {
+	if kind == OutflowTransfer {
+		return l.Transfer[currency]
+	}
+	return l.Withdrawal[currency]
+}
+
+// Validate rejects negative limits.
+func (l Limits) Validate() error {
+	if l.MaxOperationsPerHour < 0 {
+		return NewDomainError("maximum operations per hour cannot be negative: %d", l.MaxOperationsPerHour)
+	}
+	for kind, byCurrency := range map[OutflowKind]map[shared.Currency]AmountLimits{OutflowWithdrawal: l.Withdrawal, OutflowTransfer: l.Transfer} {
+		for currency, a := range byCurrency {
+			if a.PerTransaction.IsNegative() || a.Daily.IsNegative() || a.Monthly.IsNegative() {
+				return NewDomainError("%s limits for %s cannot be negative", kind, currency)
+			}
+		}
+	}
+	return nil
+}
+
+// LimitExceededError says which limit a withdrawal or transfer would break.
+// For amount limits, Limit, Used and Remaining are in Currency; for
+// LimitOperationsPerHour they count operations and Currency is empty.
+// Remaining is the headroom left before the limit, never negative.
+type LimitExceededError struct {
+	AccountID string
+	Operation OutflowKind
+	Kind      LimitKind
+	Currency  shared.Currency
+	Limit     decimal.Decimal
+	Used      decimal.Decimal
+	Requested decimal.Decimal
+	Remaining decimal.Decimal
+}
+
+func (e *LimitExceededError) Error() string {
+	if e.Kind == LimitOperationsPerHour {
+		return fmt.Sprintf("%v: account %s is limited to %s withdrawals and transfers per hour and has made %s",
+			ErrLimitExceeded, e.AccountID, e.Limit, e.Used)
+	}
+	if e.Kind == LimitPerTransaction {
+		return fmt.Sprintf("%v: %s of %s %s from account %s is above the per-transaction limit of %s %s",
+			ErrLimitExceeded, e.Operation, e.Requested, e.Currency, e.AccountID, e.Limit, e.Currency)
+	}
+	return fmt.Sprintf("%v: %s of %s %s from account %s is above the %s %s limit of %s %s (%s used, %s remaining)",
+		ErrLimitExceeded, e.Operation, e.Requested, e.Currency, e.AccountID, e.Kind, e.Operation, e.Limit, e.Currency, e.Used, e.Remaining)
+}
+
+func (e *LimitExceededError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// OutflowUsage is what an account has sent out, kept up to date by
+// ApplyEvent so limits can be checked without reading the account's
+// history: totals per currency for the UTC day and month of its latest
+// outflow, and the times of its outflows within the hour before it.
+type OutflowUsage struct {
+	Day     time.Time                                           `json:"day"`
+	Month   time.Time                                           `json:"month"`
+	Daily   map[OutflowKind]map[shared.Currency]decimal.Decimal `json:"daily,omitempty"`
+	Monthly map[OutflowKind]map[shared.Currency]decimal.Decimal `json:"monthly,omitempty"`
+	Recent  []time.Time                                         `json:"recent,omitempty"`
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func startOfMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func (u *OutflowUsage) record(kind OutflowKind, amount decimal.Decimal, currency shared.Currency, at time.Time) {
+	if day := startOfDay(at); !u.Day.Equal(day) {
+		u.Day, u.Daily = day, nil
+	}
+	if month := startOfMonth(at); !u.Month.Equal(month) {
+		u.Month, u.Monthly = month, nil
+	}
+	u.Daily = addUsage(u.Daily, kind, currency, amount)
+	u.Monthly = addUsage(u.Monthly, kind, currency, amount)
+
+	recent := make([]time.Time, 0, len(u.Recent)+1)
+	for _, t := range u.Recent {
+		if at.Sub(t) < operationsWindow {
+			recent = append(recent, t)
+		}
+	}
+	u.Recent = append(recent, at)
+}
+
+// release takes back a reversed outflow made at at from the day, month and
+// hour it was counted in, if they are still the current ones.
+func (u *OutflowUsage) release(kind OutflowKind, amount decimal.Decimal, currency shared.Currency, at time.Time) {
+	if u == nil {
+		return
+	}
+	if u.Day.Equal(startOfDay(at)) {
+		subtractUsage(u.Daily, kind, currency, amount)
+	}
+	if u.Month.Equal(startOfMonth(at)) {
+		subtractUsage(u.Monthly, kind, currency, amount)
+	}
+	for i, t := range u.Recent {
+		if t.Equal(at) {
+			u.Recent = append(u.Recent[:i:i], u.Recent[i+1:]...)
+			break
+		}
+	}
+}
+
+func subtractUsage(totals map[OutflowKind]map[shared.Currency]decimal.Decimal, kind OutflowKind, currency shared.Currency, amount decimal.Decimal) {
+	used, ok := totals[kind][currency]
+	if !ok {
+		return
+	}
+	if left := used.Sub(amount); left.IsPositive() {
+		totals[kind][currency] = left
+	} else {
+		delete(totals[kind], currency)
+	}
+}
+
+// reversedOutflow returns the kind of outflow a reversal of an event of type t
+// gives back, if it is one. Only deposits and withdrawals can be reversed.
+func reversedOutflow(t events.EventType) (OutflowKind, bool) {
+	if t == events.WithdrawalMadeType {
+		return OutflowWithdrawal, true
+	}
+	return "", false
+}
+
+func addUsage(totals map[OutflowKind]map[shared.Currency]decimal.Decimal, kind OutflowKind, currency shared.Currency, amount decimal.Decimal) map[OutflowKind]map[shared.Currency]decimal.Decimal {
+	if totals == nil {
+		totals = make(map[OutflowKind]map[shared.Currency]decimal.Decimal)
+	}
+	if totals[kind] == nil {
+		totals[kind] = make(map[shared.Currency]decimal.Decimal)
+	}
+	totals[kind][currency] = totals[kind][currency].Add(amount)
+	return totals
+}
+
+// used returns the totals for kind and currency in the day and month
+// containing now, and the number of outflows in the hour before now.
+func (u *OutflowUsage) used(kind OutflowKind, currency shared.Currency, now time.Time) (daily, monthly decimal.Decimal, operations int) {
+	if u == nil {
+		return decimal.Zero, decimal.Zero, 0
+	}
+	if u.Day.Equal(startOfDay(now)) {
+		daily = u.Daily[kind][currency]
+	}
+	if u.Month.Equal(startOfMonth(now)) {
+		monthly = u.Monthly[kind][currency]
+	}
+	for _, t := range u.Recent {
+		if now.Sub(t) < operationsWindow {
+			operations++
+		}
+	}
+	return daily, monthly, operations
+}
+
+func (a *Account) recordOutflow(kind OutflowKind, amount decimal.Decimal, currency shared.Currency, at time.Time) {
+	if a.Outflows == nil {
+		a.Outflows = &OutflowUsage{}
+	}
+	a.Outflows.record(kind, amount, currency, at)
+}
+
+// CheckLimits returns a *LimitExceededError if an outflow of amount at now
+// would break one of limits, given what the account has already sent out.
+// It is called before HandleWithdraw and HandleInitiateTransfer.
+func (a *Account) CheckLimits(limits Limits, kind OutflowKind, amount decimal.Decimal, currency shared.Currency, now time.Time) error {
+	exceeded := func(limitKind LimitKind, limit, used decimal.Decimal) error {
+		return &LimitExceededError{
+			AccountID: a.ID,
+			Operation: kind,
+			Kind:      limitKind,
+			Currency:  currency,
+			Limit:     limit,
+			Used:      used,
+			Requested: amount,
+			Remaining: decimal.Max(limit.Sub(used), decimal.Zero),
+		}
+	}
+
+	daily, monthly, operations := a.Outflows.used(kind, currency, now)
+	amounts := limits.amounts(kind, currency)
+	if amounts.PerTransaction.IsPositive() && amount.GreaterThan(amounts.PerTransaction) {
+		return exceeded(LimitPerTransaction, amounts.PerTransaction, decimal.Zero)
+	}
+	if limits.MaxOperationsPerHour > 0 && operations >= limits.MaxOperationsPerHour {
+		return &LimitExceededError{
+			AccountID: a.ID,
+			Operation: kind,
+			Kind:      LimitOperationsPerHour,
+			Limit:     decimal.NewFromInt(int64(limits.MaxOperationsPerHour)),
+			Used:      decimal.NewFromInt(int64(operations)),
+			Requested: decimal.NewFromInt(1),
+			Remaining: decimal.Zero,
+		}
+	}
+	if amounts.Daily.IsPositive() && daily.Add(amount).GreaterThan(amounts.Daily) {
+		return exceeded(LimitDaily, amounts.Daily, daily)
+	}
+	if amounts.Monthly.IsPositive() && monthly.Add(amount).GreaterThan(amounts.Monthly) {
+		return exceeded(LimitMonthly, amounts.Monthly, monthly)
+	}
+	return nil
+}
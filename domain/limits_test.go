@@ -0,0 +1,168 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/domain"
+	"financial-ledger/events"
+	"financial-ledger/shared"
+)
+
+func TestAccount_CheckLimits(t *testing.T) {
+	now := time.Date(2025, 3, 31, 23, 30, 0, 0, time.UTC)
+	limits := domain.Limits{
+		Withdrawal: map[shared.Currency]domain.AmountLimits{
+			shared.USD: {PerTransaction: dec("500"), Daily: dec("1000"), Monthly: dec("3000")},
+		},
+		MaxOperationsPerHour: 3,
+	}
+
+	// account replays a withdrawal of 100 USD at each of the given times.
+	account := func(t *testing.T, withdrawals ...time.Time) *domain.Account {
+		t.Helper()
+		a := domain.NewAccount("acc-1")
+		history := []events.Event{events.AccountCreatedEvent{
+			BaseEvent:       events.NewBaseEvent("acc-1", 1, events.AccountCreatedType),
+			InitialBalances: []shared.Balance{{Currency: shared.USD, Amount: dec("10000")}},
+		}}
+		for i, at := range withdrawals {
+			base := events.NewBaseEvent("acc-1", i+2, events.WithdrawalMadeType)
+			base.Timestamp = at
+			history = append(history, events.WithdrawalMadeEvent{BaseEvent: base, Amount: dec("100"), Currency: shared.USD})
+		}
+		if err := a.ApplyEvents(history); err != nil {
+			t.Fatalf("ApplyEvents failed: %v", err)
+		}
+		return a
+	}
+	check := func(t *testing.T, a *domain.Account, amount string) *domain.LimitExceededError {
+		t.Helper()
+		err := a.CheckLimits(limits, domain.OutflowWithdrawal, dec(amount), shared.USD, now)
+		if err == nil {
+			return nil
+		}
+		var limitErr *domain.LimitExceededError
+		if !errors.As(err, &limitErr) || !errors.Is(err, domain.ErrLimitExceeded) {
+			t.Fatalf("expected a LimitExceededError, got %v", err)
+		}
+		return limitErr
+	}
+
+	t.Run("WithinLimits", func(t *testing.T) {
+		if err := check(t, account(t, now.Add(-2*time.Hour)), "500"); err != nil {
+			t.Errorf("unexpected %v", err)
+		}
+	})
+
+	t.Run("PerTransaction", func(t *testing.T) {
+		err := check(t, account(t), "500.01")
+		if err == nil || err.Kind != domain.LimitPerTransaction || !err.Remaining.Equal(dec("500")) {
+			t.Errorf("expected the per-transaction limit with 500 headroom, got %+v", err)
+		}
+	})
+
+	t.Run("Daily", func(t *testing.T) {
+		day := []time.Time{}
+		for i := 1; i <= 8; i++ {
+			day = append(day, now.Add(-time.Duration(i)*2*time.Hour))
+		}
+		err := check(t, account(t, day...), "300")
+		if err == nil || err.Kind != domain.LimitDaily || !err.Used.Equal(dec("800")) || !err.Remaining.Equal(dec("200")) {
+			t.Fatalf("expected the daily limit with 200 headroom, got %+v", err)
+		}
+		if err := check(t, account(t, day...), "200"); err != nil {
+			t.Errorf("expected the remaining headroom to be usable, got %v", err)
+		}
+	})
+
+	t.Run("DayRollsOver", func(t *testing.T) {
+		yesterday := now.Add(-24 * time.Hour)
+		a := account(t, yesterday, yesterday, yesterday, yesterday, yesterday, yesterday, yesterday, yesterday, yesterday)
+		if err := check(t, a, "500"); err != nil {
+			t.Errorf("yesterday's withdrawals count against today: %v", err)
+		}
+	})
+
+	t.Run("Monthly", func(t *testing.T) {
+		var month []time.Time
+		for i := 1; i <= 29; i++ {
+			month = append(month, now.Add(-time.Duration(i)*24*time.Hour))
+		}
+		err := check(t, account(t, month...), "200")
+		if err == nil || err.Kind != domain.LimitMonthly || !err.Used.Equal(dec("2900")) || !err.Remaining.Equal(dec("100")) {
+			t.Errorf("expected the monthly limit with 100 headroom, got %+v", err)
+		}
+	})
+
+	t.Run("OperationsPerHour", func(t *testing.T) {
+		a := account(t, now.Add(-50*time.Minute), now.Add(-20*time.Minute), now.Add(-time.Minute))
+		err := check(t, a, "1")
+		if err == nil || err.Kind != domain.LimitOperationsPerHour || !err.Used.Equal(decimal.NewFromInt(3)) {
+			t.Fatalf("expected the operations limit, got %+v", err)
+		}
+		if err := a.CheckLimits(limits, domain.OutflowWithdrawal, dec("1"), shared.USD, now.Add(11*time.Minute)); err != nil {
+			t.Errorf("expected the oldest operation to have left the window, got %v", err)
+		}
+	})
+
+	t.Run("SurvivesSnapshot", func(t *testing.T) {
+		a := account(t, now.Add(-time.Hour), now.Add(-time.Hour))
+		snapshot, err := domain.CreateSnapshot(a)
+		if err != nil {
+			t.Fatalf("CreateSnapshot failed: %v", err)
+		}
+		restored, err := domain.ApplySnapshot(snapshot)
+		if err != nil {
+			t.Fatalf("ApplySnapshot failed: %v", err)
+		}
+		if err := check(t, restored, "900"); err == nil || err.Kind != domain.LimitPerTransaction {
+			t.Fatalf("expected the per-transaction limit, got %v", err)
+		}
+		if err := check(t, restored, "500"); err != nil {
+			t.Errorf("unexpected %v", err)
+		}
+		limitErr := restored.CheckLimits(domain.Limits{Withdrawal: map[shared.Currency]domain.AmountLimits{shared.USD: {Daily: dec("500")}}}, domain.OutflowWithdrawal, dec("301"), shared.USD, now)
+		if !errors.Is(limitErr, domain.ErrLimitExceeded) {
+			t.Errorf("expected usage to survive the snapshot, got %v", limitErr)
+		}
+	})
+
+	t.Run("ReversalReleasesUsage", func(t *testing.T) {
+		a := account(t, now.Add(-50*time.Minute), now.Add(-20*time.Minute))
+		base := events.NewBaseEvent("acc-1", 4, events.WithdrawalMadeType)
+		base.Timestamp = now.Add(-time.Minute)
+		withdrawal := events.WithdrawalMadeEvent{BaseEvent: base, Amount: dec("400"), Currency: shared.USD}
+		if err := a.ApplyEvent(withdrawal); err != nil {
+			t.Fatalf("ApplyEvent failed: %v", err)
+		}
+		if err := check(t, a, "500"); err == nil || err.Kind != domain.LimitOperationsPerHour {
+			t.Fatalf("expected the operations limit before the reversal, got %+v", err)
+		}
+
+		if err := a.HandleReverse(withdrawal); err != nil {
+			t.Fatalf("HandleReverse failed: %v", err)
+		}
+		if err := check(t, a, "500"); err != nil {
+			t.Fatalf("expected the reversed withdrawal to be released, got %+v", err)
+		}
+		if err := check(t, account(t, now.Add(-50*time.Minute), now.Add(-20*time.Minute), now.Add(-time.Minute)), "1"); err == nil {
+			t.Fatal("expected the unreversed account to stay limited")
+		}
+		daily := domain.Limits{Withdrawal: map[shared.Currency]domain.AmountLimits{shared.USD: {Daily: dec("300")}}}
+		if err := a.CheckLimits(daily, domain.OutflowWithdrawal, dec("100"), shared.USD, now); err != nil {
+			t.Errorf("expected only the two withdrawals left in the daily total, got %v", err)
+		}
+	})
+
+	t.Run("OutdatedSnapshot", func(t *testing.T) {
+		snapshot, _ := domain.CreateSnapshot(account(t, now))
+		snapshot.Schema = 0
+		if _, err := domain.ApplySnapshot(snapshot); !errors.Is(err, domain.ErrOutdatedSnapshot) {
+			t.Errorf("expected ErrOutdatedSnapshot for a snapshot without a schema, got %v", err)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"encoding/json"
+	"errors"
 	"financial-ledger/events"
 	"financial-ledger/shared"
 	"fmt"
@@ -11,9 +12,21 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+// SnapshotSchema is the version of the account state written to snapshots. It
+// is raised whenever ApplyEvent starts to derive state that older snapshots
+// lack or got wrong, so that those are ignored and the account is rebuilt
+// from its events. Version 2 releases reversed outflows from OutflowUsage;
+// snapshots without a schema predate it.
+const SnapshotSchema = 2
+
+// ErrOutdatedSnapshot is returned by ApplySnapshot for a snapshot written
+// under an older SnapshotSchema.
+var ErrOutdatedSnapshot = errors.New("snapshot predates the current state schema")
+
 type Snapshot struct {
 	AggregateID string    `json:"aggregateId"`
 	Version     int       `json:"version"`
+	Schema      int       `json:"schema,omitempty"`
 	State       []byte    `json:"state"`
 	Timestamp   time.Time `json:"timestamp"`
 }
@@ -27,12 +40,16 @@ func CreateSnapshot(account *Account) (*Snapshot, error) {
 	return &Snapshot{
 		AggregateID: account.ID,
 		Version:     account.Version,
+		Schema:      SnapshotSchema,
 		State:       stateJSON,
 		Timestamp:   time.Now().UTC(),
 	}, nil
 }
 
 func ApplySnapshot(snap *Snapshot) (*Account, error) {
+	if snap.Schema < SnapshotSchema {
+		return nil, fmt.Errorf("%w: snapshot of %s at version %d has schema %d, current is %d", ErrOutdatedSnapshot, snap.AggregateID, snap.Version, snap.Schema, SnapshotSchema)
+	}
 	var account Account
 	err := json.Unmarshal(snap.State, &account)
 	if err != nil {
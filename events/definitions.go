@@ -1,14 +1,102 @@
 package events
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/shopspring/decimal"
 
 	"financial-ledger/shared"
 )
 
+// AccountCreatedEvent opens an account. Holders are the IDs of the customers
+// who own it, more than one for a joint account.
 type AccountCreatedEvent struct {
 	BaseEvent
-	InitialBalances []shared.Balance `json:"initialBalances"`
+	InitialBalances []shared.Balance    `json:"initialBalances"`
+	PersonalData    *SealedPersonalData `json:"personalData,omitempty"`
+	Holders         []string            `json:"holders,omitempty"`
+}
+
+// AccountDetailsUpdatedEvent replaces the personal data held for an account.
+type AccountDetailsUpdatedEvent struct {
+	BaseEvent
+	PersonalData SealedPersonalData `json:"personalData"`
+}
+
+// SealedPersonalData is personal data about an account holder (owner name,
+// address, notes), encrypted under a key belonging to the data subject alone.
+// Destroying that key erases the data, while the event carrying it and the
+// hash chain over it stay intact.
+type SealedPersonalData struct {
+	SubjectID string `json:"subjectId"`
+	KeyID     string `json:"keyId"`
+	Nonce     []byte `json:"nonce"`
+	Data      []byte `json:"data"`
+}
+
+// CustomerRegisteredEvent registers a customer in the customer's own stream,
+// apart from any account. Details are sealed under the customer's subject key,
+// and KYCTier is the name of a domain.KYCTier.
+type CustomerRegisteredEvent struct {
+	BaseEvent
+	CustomerID string             `json:"customerId"`
+	Details    SealedPersonalData `json:"details"`
+	KYCTier    string             `json:"kycTier"`
+}
+
+// CustomerDetailsUpdatedEvent replaces a customer's details if Details is set,
+// and its KYC tier if KYCTier is set.
+type CustomerDetailsUpdatedEvent struct {
+	BaseEvent
+	CustomerID string              `json:"customerId"`
+	Details    *SealedPersonalData `json:"details,omitempty"`
+	KYCTier    string              `json:"kycTier,omitempty"`
+}
+
+// ApprovalRequestedEvent opens an approval in its own stream. Kind is the name
+// of a domain.ApprovalKind, and Command the held command as encoded by the
+// application layer.
+type ApprovalRequestedEvent struct {
+	BaseEvent
+	ApprovalID        string          `json:"approvalId"`
+	Kind              string          `json:"kind"`
+	Target            string          `json:"target,omitempty"`
+	AccountIDs        []string        `json:"accountIds,omitempty"`
+	Summary           string          `json:"summary"`
+	Command           json.RawMessage `json:"command"`
+	RequestedBy       string          `json:"requestedBy"`
+	RequiredApprovals int             `json:"requiredApprovals"`
+	ExpiresAt         time.Time       `json:"expiresAt"`
+}
+
+// ApprovalGrantedEvent records one approver's sign-off.
+type ApprovalGrantedEvent struct {
+	BaseEvent
+	ApprovalID string `json:"approvalId"`
+	Approver   string `json:"approver"`
+}
+
+// ApprovalExecutedEvent records the outcome of executing an approved command.
+// It is saved together with the last sign-off. Error is set if the command
+// was refused.
+type ApprovalExecutedEvent struct {
+	BaseEvent
+	ApprovalID string `json:"approvalId"`
+	Error      string `json:"error,omitempty"`
+}
+
+type ApprovalRejectedEvent struct {
+	BaseEvent
+	ApprovalID string `json:"approvalId"`
+	RejectedBy string `json:"rejectedBy"`
+	Reason     string `json:"reason"`
+}
+
+type ApprovalExpiredEvent struct {
+	BaseEvent
+	ApprovalID string `json:"approvalId"`
+	Reason     string `json:"reason"`
 }
 
 type DepositMadeEvent struct {
@@ -44,6 +132,35 @@ type CurrencyConvertedEvent struct {
 	ExchangeRate decimal.Decimal `json:"exchangeRate"`
 }
 
+// AccountClosedEvent closes an account. A closed account holds no funds and
+// accepts no further commands.
+type AccountClosedEvent struct {
+	BaseEvent
+}
+
+// TransactionReversedEvent undoes an earlier deposit or withdrawal on the same
+// account with a compensating entry: a reversed deposit takes Amount back out,
+// a reversed withdrawal pays it back in. The original event is left untouched.
+// OriginalTimestamp is when the reversed event happened, so a reversed outflow
+// can be released from the limit window it was counted in; reversals recorded
+// before it was added lack it.
+type TransactionReversedEvent struct {
+	BaseEvent
+	ReversedEventID   string          `json:"reversedEventId"`
+	ReversedType      EventType       `json:"reversedType"`
+	Amount            decimal.Decimal `json:"amount"`
+	Currency          shared.Currency `json:"currency"`
+	OriginalTimestamp *time.Time      `json:"originalTimestamp,omitempty"`
+}
+
+// Delta is the signed change the reversal makes to the balance in Currency.
+func (e TransactionReversedEvent) Delta() decimal.Decimal {
+	if e.ReversedType == DepositMadeType {
+		return e.Amount.Neg()
+	}
+	return e.Amount
+}
+
 type ExchangeRateUpdatedEvent struct {
 	BaseEvent
 	CurrencyPair         string          `json:"currencyPair"`
@@ -1,6 +1,7 @@
 package events
 
 import (
+	"reflect"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,6 +15,40 @@ type BaseEvent struct {
 	Version     int       `json:"version"` // Version of the aggregate *after* this event is applied.
 	Timestamp   time.Time `json:"timestamp"`
 	Type        EventType `json:"type"`
+
+	// IdempotencyKey and CommandFingerprint identify the client request that
+	// produced this event, so a retried command can be recognised on replay.
+	IdempotencyKey     string `json:"idempotencyKey,omitempty"`
+	CommandFingerprint string `json:"commandFingerprint,omitempty"`
+
+	Metadata Metadata `json:"metadata"`
+
+	// Position, PrevHash, Hash, GlobalPrevHash and GlobalHash are assigned by the
+	// EventStore at commit time. Hash chains the event to its predecessor in the
+	// aggregate's stream; GlobalHash chains it to its predecessor in the global log.
+	Position       int64  `json:"position,omitempty"`
+	PrevHash       string `json:"prevHash,omitempty"`
+	Hash           string `json:"hash,omitempty"`
+	GlobalPrevHash string `json:"globalPrevHash,omitempty"`
+	GlobalHash     string `json:"globalHash,omitempty"`
+
+	// SignatureKeyID and Signature are set when the EventStore signs commits.
+	// The signature covers Hash and GlobalHash, and so the event's content and
+	// everything before it in both chains.
+	SignatureKeyID string `json:"signatureKeyId,omitempty"`
+	Signature      string `json:"signature,omitempty"`
+}
+
+// Metadata is the audit envelope attached to every event: who initiated the
+// change, through which channel, why, and as part of which request.
+// CorrelationID is shared by every event produced for one command; CausationID
+// is the ID of the event that triggered this one, if any.
+type Metadata struct {
+	Actor         string `json:"actor,omitempty"`
+	CorrelationID string `json:"correlationId,omitempty"`
+	CausationID   string `json:"causationId,omitempty"`
+	Channel       string `json:"channel,omitempty"`
+	Reason        string `json:"reason,omitempty"`
 }
 
 type Event interface {
@@ -25,11 +60,23 @@ func (e BaseEvent) GetBase() BaseEvent {
 }
 
 const (
-	AccountCreatedType    EventType = "AccountCreated"
-	DepositMadeType       EventType = "DepositMade"
-	WithdrawalMadeType    EventType = "WithdrawalMade"
-	MoneyTransferredType  EventType = "MoneyTransferred"
-	CurrencyConvertedType EventType = "CurrencyConverted"
+	AccountCreatedType        EventType = "AccountCreated"
+	DepositMadeType           EventType = "DepositMade"
+	WithdrawalMadeType        EventType = "WithdrawalMade"
+	MoneyTransferredType      EventType = "MoneyTransferred"
+	CurrencyConvertedType     EventType = "CurrencyConverted"
+	AccountDetailsUpdatedType EventType = "AccountDetailsUpdated"
+	AccountClosedType         EventType = "AccountClosed"
+	TransactionReversedType   EventType = "TransactionReversed"
+
+	CustomerRegisteredType     EventType = "CustomerRegistered"
+	CustomerDetailsUpdatedType EventType = "CustomerDetailsUpdated"
+
+	ApprovalRequestedType EventType = "ApprovalRequested"
+	ApprovalGrantedType   EventType = "ApprovalGranted"
+	ApprovalExecutedType  EventType = "ApprovalExecuted"
+	ApprovalRejectedType  EventType = "ApprovalRejected"
+	ApprovalExpiredType   EventType = "ApprovalExpired"
 )
 
 func NewBaseEvent(aggregateID string, version int, eventType EventType) BaseEvent {
@@ -41,3 +88,77 @@ func NewBaseEvent(aggregateID string, version int, eventType EventType) BaseEven
 		Type:        eventType,
 	}
 }
+
+// WithBase returns a copy of event with mutate applied to its embedded BaseEvent.
+// Events are stored as values, so the service uses this to stamp request-level
+// data onto events produced by the aggregate. Other event types are handled by
+// reflection if they embed BaseEvent, and returned unchanged otherwise.
+func WithBase(event Event, mutate func(base *BaseEvent)) Event {
+	switch e := event.(type) {
+	case AccountCreatedEvent:
+		mutate(&e.BaseEvent)
+		return e
+	case DepositMadeEvent:
+		mutate(&e.BaseEvent)
+		return e
+	case WithdrawalMadeEvent:
+		mutate(&e.BaseEvent)
+		return e
+	case MoneyTransferredEvent:
+		mutate(&e.BaseEvent)
+		return e
+	case CurrencyConvertedEvent:
+		mutate(&e.BaseEvent)
+		return e
+	case ExchangeRateUpdatedEvent:
+		mutate(&e.BaseEvent)
+		return e
+	case AccountDetailsUpdatedEvent:
+		mutate(&e.BaseEvent)
+		return e
+	case AccountClosedEvent:
+		mutate(&e.BaseEvent)
+		return e
+	case TransactionReversedEvent:
+		mutate(&e.BaseEvent)
+		return e
+	case CustomerRegisteredEvent:
+		mutate(&e.BaseEvent)
+		return e
+	case CustomerDetailsUpdatedEvent:
+		mutate(&e.BaseEvent)
+		return e
+	case ApprovalRequestedEvent:
+		mutate(&e.BaseEvent)
+		return e
+	case ApprovalGrantedEvent:
+		mutate(&e.BaseEvent)
+		return e
+	case ApprovalExecutedEvent:
+		mutate(&e.BaseEvent)
+		return e
+	case ApprovalRejectedEvent:
+		mutate(&e.BaseEvent)
+		return e
+	case ApprovalExpiredEvent:
+		mutate(&e.BaseEvent)
+		return e
+	default:
+		return withBaseReflect(event, mutate)
+	}
+}
+
+func withBaseReflect(event Event, mutate func(base *BaseEvent)) Event {
+	v := reflect.ValueOf(event)
+	if v.Kind() != reflect.Struct {
+		return event
+	}
+	cp := reflect.New(v.Type()).Elem()
+	cp.Set(v)
+	field := cp.FieldByName("BaseEvent")
+	if !field.IsValid() || field.Type() != reflect.TypeOf(BaseEvent{}) {
+		return event
+	}
+	mutate(field.Addr().Interface().(*BaseEvent))
+	return cp.Interface().(Event)
+}
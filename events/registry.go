@@ -0,0 +1,63 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var ErrUnknownEventType = errors.New("unknown event type")
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[EventType]reflect.Type{
+		AccountCreatedType:        reflect.TypeOf(AccountCreatedEvent{}),
+		DepositMadeType:           reflect.TypeOf(DepositMadeEvent{}),
+		WithdrawalMadeType:        reflect.TypeOf(WithdrawalMadeEvent{}),
+		MoneyTransferredType:      reflect.TypeOf(MoneyTransferredEvent{}),
+		CurrencyConvertedType:     reflect.TypeOf(CurrencyConvertedEvent{}),
+		AccountDetailsUpdatedType: reflect.TypeOf(AccountDetailsUpdatedEvent{}),
+		AccountClosedType:         reflect.TypeOf(AccountClosedEvent{}),
+		TransactionReversedType:   reflect.TypeOf(TransactionReversedEvent{}),
+
+		CustomerRegisteredType:     reflect.TypeOf(CustomerRegisteredEvent{}),
+		CustomerDetailsUpdatedType: reflect.TypeOf(CustomerDetailsUpdatedEvent{}),
+
+		ApprovalRequestedType: reflect.TypeOf(ApprovalRequestedEvent{}),
+		ApprovalGrantedType:   reflect.TypeOf(ApprovalGrantedEvent{}),
+		ApprovalExecutedType:  reflect.TypeOf(ApprovalExecutedEvent{}),
+		ApprovalRejectedType:  reflect.TypeOf(ApprovalRejectedEvent{}),
+		ApprovalExpiredType:   reflect.TypeOf(ApprovalExpiredEvent{}),
+	}
+)
+
+// RegisterEventType makes events of eventType decodable by Unmarshal. prototype
+// is a zero value of the concrete (non-pointer) event struct.
+func RegisterEventType(eventType EventType, prototype Event) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[eventType] = reflect.TypeOf(prototype)
+}
+
+// Unmarshal decodes the JSON form of an event into its concrete type, chosen by
+// eventType. It is the inverse of json.Marshal for registered event types.
+func Unmarshal(eventType EventType, data []byte) (Event, error) {
+	registryMu.RLock()
+	t, ok := registry[eventType]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownEventType, eventType)
+	}
+
+	ptr := reflect.New(t)
+	if err := json.Unmarshal(data, ptr.Interface()); err != nil {
+		return nil, fmt.Errorf("failed to decode %s event: %w", eventType, err)
+	}
+	event, ok := ptr.Elem().Interface().(Event)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q is registered as %s, which is not an Event", ErrUnknownEventType, eventType, t)
+	}
+	return event, nil
+}
@@ -0,0 +1,90 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+
+	"financial-ledger/app"
+	"financial-ledger/domain"
+	"financial-ledger/grpcapi/internal/wire"
+)
+
+// statusCodes gives the gRPC code for each reason with a sentinel.
+var statusCodes = map[string]codes.Code{
+	wire.ReasonVersionMismatch:     codes.FailedPrecondition,
+	wire.ReasonConcurrentUpdate:    codes.Aborted,
+	wire.ReasonAccountNotFound:     codes.NotFound,
+	wire.ReasonAccountExists:       codes.AlreadyExists,
+	wire.ReasonInsufficientFunds:   codes.FailedPrecondition,
+	wire.ReasonIdempotencyConflict: codes.FailedPrecondition,
+	wire.ReasonInvalidCursor:       codes.InvalidArgument,
+	wire.ReasonAsOfOutOfRange:      codes.OutOfRange,
+	wire.ReasonUnauthenticated:     codes.Unauthenticated,
+	wire.ReasonPermissionDenied:    codes.PermissionDenied,
+	wire.ReasonApprovalRequired:    codes.FailedPrecondition,
+	wire.ReasonLimitExceeded:       codes.ResourceExhausted,
+	wire.ReasonSanctionsHit:        codes.FailedPrecondition,
+}
+
+// toStatus converts an error from the service into a gRPC status carrying a
+// google.rpc.ErrorInfo, plus a BadRequest, PreconditionFailure or
+// QuotaFailure where they say more.
+func toStatus(method string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var fieldErr *wire.FieldError
+	if errors.As(err, &fieldErr) {
+		return withDetails(codes.InvalidArgument, err.Error(), wire.ReasonInvalidArgument,
+			&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: fieldErr.Field, Description: fieldErr.Description}}})
+	}
+	if errors.Is(err, app.ErrVersionMismatch) {
+		return withDetails(codes.FailedPrecondition, err.Error(), wire.ReasonVersionMismatch,
+			&errdetails.PreconditionFailure{Violations: []*errdetails.PreconditionFailure_Violation{{Type: "VERSION", Subject: "expected_version", Description: err.Error()}}})
+	}
+	var limitErr *domain.LimitExceededError
+	if errors.As(err, &limitErr) {
+		return withDetails(codes.ResourceExhausted, err.Error(), wire.ReasonLimitExceeded,
+			&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{{Subject: "account:" + limitErr.AccountID, Description: err.Error()}}})
+	}
+	for _, s := range wire.Sentinels {
+		if errors.Is(err, s.Err) {
+			return withDetails(statusCodes[s.Reason], err.Error(), s.Reason)
+		}
+	}
+	var domainErr *domain.DomainError
+	if errors.As(err, &domainErr) {
+		return withDetails(codes.FailedPrecondition, err.Error(), wire.ReasonRuleViolation)
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	// Internal errors can carry storage details; keep them in the log.
+	log.Printf("ERROR: gRPC %s: %v", method, err)
+	return withDetails(codes.Internal, "internal server error", wire.ReasonInternal)
+}
+
+func withDetails(code codes.Code, message, reason string, extra ...protoadapt.MessageV1) error {
+	st := status.New(code, message)
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: reason, Domain: wire.ErrorDomain}}
+	details = append(details, extra...)
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
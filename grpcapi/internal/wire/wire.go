@@ -0,0 +1,331 @@
+// Package wire converts between the ledger's Go types and their protobuf
+// form, and names the error reasons carried in gRPC status details. It is
+// shared by the gRPC server and the client so both sides agree on encoding.
+package wire
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"financial-ledger/app"
+	"financial-ledger/auth"
+	"financial-ledger/domain"
+	"financial-ledger/events"
+	"financial-ledger/grpcapi/ledgerpb"
+	"financial-ledger/shared"
+	"financial-ledger/store"
+)
+
+// ErrorDomain is the domain of every google.rpc.ErrorInfo the server sends.
+const ErrorDomain = "ledger.v1"
+
+// Reasons carried in google.rpc.ErrorInfo.
+const (
+	ReasonInvalidArgument     = "INVALID_ARGUMENT"
+	ReasonVersionMismatch     = "VERSION_MISMATCH"
+	ReasonConcurrentUpdate    = "CONCURRENT_UPDATE"
+	ReasonAccountNotFound     = "ACCOUNT_NOT_FOUND"
+	ReasonAccountExists       = "ACCOUNT_EXISTS"
+	ReasonInsufficientFunds   = "INSUFFICIENT_FUNDS"
+	ReasonIdempotencyConflict = "IDEMPOTENCY_CONFLICT"
+	ReasonInvalidCursor       = "INVALID_CURSOR"
+	ReasonAsOfOutOfRange      = "AS_OF_OUT_OF_RANGE"
+	ReasonRuleViolation       = "RULE_VIOLATION"
+	ReasonUnauthenticated     = "UNAUTHENTICATED"
+	ReasonPermissionDenied    = "PERMISSION_DENIED"
+	ReasonApprovalRequired    = "APPROVAL_REQUIRED"
+	ReasonLimitExceeded       = "LIMIT_EXCEEDED"
+	ReasonSanctionsHit        = "SANCTIONS_HIT"
+	ReasonInternal            = "INTERNAL"
+)
+
+// Sentinels maps reasons to the errors they stand for, in the order the
+// server checks them. Several sentinels are DomainErrors, so they come before
+// the generic ReasonRuleViolation, which has no sentinel.
+var Sentinels = []struct {
+	Reason string
+	Err    error
+}{
+	{ReasonVersionMismatch, app.ErrVersionMismatch},
+	{ReasonConcurrentUpdate, store.ErrOptimisticLock},
+	{ReasonAccountNotFound, domain.ErrAccountNotFound},
+	{ReasonAccountExists, domain.ErrAccountExists},
+	{ReasonInsufficientFunds, domain.ErrInsufficientFunds},
+	{ReasonIdempotencyConflict, app.ErrIdempotencyConflict},
+	{ReasonInvalidCursor, app.ErrInvalidCursor},
+	{ReasonAsOfOutOfRange, app.ErrAsOfOutOfRange},
+	{ReasonUnauthenticated, auth.ErrUnauthenticated},
+	{ReasonPermissionDenied, auth.ErrPermissionDenied},
+	{ReasonApprovalRequired, app.ErrApprovalRequired},
+	{ReasonLimitExceeded, domain.ErrLimitExceeded},
+	{ReasonSanctionsHit, app.ErrScreeningHit},
+}
+
+// SentinelFor returns the error a reason stands for, or nil.
+func SentinelFor(reason string) error {
+	for _, s := range Sentinels {
+		if s.Reason == reason {
+			return s.Err
+		}
+	}
+	return nil
+}
+
+// FieldError reports a malformed request field. The server returns it as
+// INVALID_ARGUMENT with a google.rpc.BadRequest detail.
+type FieldError struct {
+	Field       string
+	Description string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Description)
+}
+
+// --- Money ---
+
+func Money(amount decimal.Decimal, currency shared.Currency) *ledgerpb.Money {
+	return &ledgerpb.Money{Amount: amount.String(), Currency: string(currency)}
+}
+
+// ParseMoney decodes m, naming field in any error.
+func ParseMoney(field string, m *ledgerpb.Money) (decimal.Decimal, shared.Currency, error) {
+	if m == nil {
+		return decimal.Zero, "", &FieldError{Field: field, Description: "is required"}
+	}
+	amount, err := ParseDecimal(field+".amount", m.GetAmount())
+	if err != nil {
+		return decimal.Zero, "", err
+	}
+	return amount, Currency(m.GetCurrency()), nil
+}
+
+func ParseDecimal(field, s string) (decimal.Decimal, error) {
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		return decimal.Zero, &FieldError{Field: field, Description: fmt.Sprintf("%q is not a decimal amount", s)}
+	}
+	return d, nil
+}
+
+// Currency normalises a currency code to upper case.
+func Currency(code string) shared.Currency {
+	return shared.Currency(strings.ToUpper(code))
+}
+
+// Balances encodes a balance map, sorted by currency.
+func Balances(balances map[shared.Currency]decimal.Decimal) []*ledgerpb.Money {
+	currencies := make([]shared.Currency, 0, len(balances))
+	for c := range balances {
+		currencies = append(currencies, c)
+	}
+	slices.Sort(currencies)
+	out := make([]*ledgerpb.Money, 0, len(currencies))
+	for _, c := range currencies {
+		out = append(out, Money(balances[c], c))
+	}
+	return out
+}
+
+func ParseBalances(field string, ms []*ledgerpb.Money) (map[shared.Currency]decimal.Decimal, error) {
+	balances := make(map[shared.Currency]decimal.Decimal, len(ms))
+	for i, m := range ms {
+		amount, currency, err := ParseMoney(fmt.Sprintf("%s[%d]", field, i), m)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := balances[currency]; dup {
+			return nil, &FieldError{Field: field, Description: fmt.Sprintf("currency %s appears more than once", currency)}
+		}
+		balances[currency] = amount
+	}
+	return balances, nil
+}
+
+func AccountBalances(b *app.AccountBalances) *ledgerpb.AccountBalances {
+	return &ledgerpb.AccountBalances{AccountId: b.AccountID, Version: int64(b.Version), Balances: Balances(b.Balances)}
+}
+
+func ParseAccountBalances(b *ledgerpb.AccountBalances) (*app.AccountBalances, error) {
+	balances, err := ParseBalances("balances", b.GetBalances())
+	if err != nil {
+		return nil, err
+	}
+	return &app.AccountBalances{AccountID: b.GetAccountId(), Version: int(b.GetVersion()), Balances: balances}, nil
+}
+
+// --- Events ---
+
+// Event encodes an event. Personal data is left out: an AccountDetailsUpdated
+// event only names its data subject.
+func Event(event events.Event) (*ledgerpb.Event, error) {
+	base := event.GetBase()
+	out := &ledgerpb.Event{
+		EventId:   base.EventID.String(),
+		AccountId: base.AggregateID,
+		Version:   int64(base.Version),
+		Type:      string(base.Type),
+		Timestamp: timestamppb.New(base.Timestamp),
+		Position:  base.Position,
+		Metadata: &ledgerpb.EventMetadata{
+			Actor:         base.Metadata.Actor,
+			CorrelationId: base.Metadata.CorrelationID,
+			CausationId:   base.Metadata.CausationID,
+			Channel:       base.Metadata.Channel,
+			Reason:        base.Metadata.Reason,
+		},
+	}
+	switch e := event.(type) {
+	case events.AccountCreatedEvent:
+		created := &ledgerpb.AccountCreated{}
+		for _, b := range e.InitialBalances {
+			created.InitialBalances = append(created.InitialBalances, Money(b.Amount, b.Currency))
+		}
+		out.Payload = &ledgerpb.Event_AccountCreated{AccountCreated: created}
+	case events.DepositMadeEvent:
+		out.Payload = &ledgerpb.Event_DepositMade{DepositMade: &ledgerpb.DepositMade{Amount: Money(e.Amount, e.Currency)}}
+	case events.WithdrawalMadeEvent:
+		out.Payload = &ledgerpb.Event_WithdrawalMade{WithdrawalMade: &ledgerpb.WithdrawalMade{Amount: Money(e.Amount, e.Currency)}}
+	case events.MoneyTransferredEvent:
+		out.Payload = &ledgerpb.Event_MoneyTransferred{MoneyTransferred: &ledgerpb.MoneyTransferred{
+			TransferId:      e.TransferID,
+			SourceAccountId: e.SourceAccountID,
+			TargetAccountId: e.TargetAccountID,
+			Debited:         Money(e.DebitedAmount, e.DebitedCurrency),
+			Credited:        Money(e.CreditedAmount, e.CreditedCurrency),
+			ExchangeRate:    e.ExchangeRate.String(),
+		}}
+	case events.CurrencyConvertedEvent:
+		out.Payload = &ledgerpb.Event_CurrencyConverted{CurrencyConverted: &ledgerpb.CurrencyConverted{
+			From:         Money(e.FromAmount, e.FromCurrency),
+			To:           Money(e.ToAmount, e.ToCurrency),
+			ExchangeRate: e.ExchangeRate.String(),
+		}}
+	case events.AccountDetailsUpdatedEvent:
+		out.Payload = &ledgerpb.Event_AccountDetailsUpdated{AccountDetailsUpdated: &ledgerpb.AccountDetailsUpdated{SubjectId: e.PersonalData.SubjectID}}
+	case events.AccountClosedEvent:
+		out.Payload = &ledgerpb.Event_AccountClosed{AccountClosed: &ledgerpb.AccountClosed{}}
+	case events.TransactionReversedEvent:
+		out.Payload = &ledgerpb.Event_TransactionReversed{TransactionReversed: &ledgerpb.TransactionReversed{
+			ReversedEventId: e.ReversedEventID,
+			ReversedType:    string(e.ReversedType),
+			Amount:          Money(e.Amount, e.Currency),
+		}}
+	default:
+		return nil, fmt.Errorf("event %s has type %s, which the gRPC API cannot encode", base.EventID, base.Type)
+	}
+	return out, nil
+}
+
+// ParseEvent decodes an event into its concrete events type. Hashes and
+// signatures are not carried over the API, so they are left empty.
+func ParseEvent(e *ledgerpb.Event) (events.Event, error) {
+	eventID, err := uuid.Parse(e.GetEventId())
+	if err != nil {
+		return nil, &FieldError{Field: "event_id", Description: err.Error()}
+	}
+	md := e.GetMetadata()
+	base := events.BaseEvent{
+		EventID:     eventID,
+		AggregateID: e.GetAccountId(),
+		Version:     int(e.GetVersion()),
+		Timestamp:   e.GetTimestamp().AsTime(),
+		Type:        events.EventType(e.GetType()),
+		Position:    e.GetPosition(),
+		Metadata: events.Metadata{
+			Actor:         md.GetActor(),
+			CorrelationID: md.GetCorrelationId(),
+			CausationID:   md.GetCausationId(),
+			Channel:       md.GetChannel(),
+			Reason:        md.GetReason(),
+		},
+	}
+
+	switch p := e.GetPayload().(type) {
+	case *ledgerpb.Event_AccountCreated:
+		created := events.AccountCreatedEvent{BaseEvent: base}
+		for i, m := range p.AccountCreated.GetInitialBalances() {
+			amount, currency, err := ParseMoney(fmt.Sprintf("initial_balances[%d]", i), m)
+			if err != nil {
+				return nil, err
+			}
+			created.InitialBalances = append(created.InitialBalances, shared.Balance{Currency: currency, Amount: amount})
+		}
+		return created, nil
+	case *ledgerpb.Event_DepositMade:
+		amount, currency, err := ParseMoney("amount", p.DepositMade.GetAmount())
+		if err != nil {
+			return nil, err
+		}
+		return events.DepositMadeEvent{BaseEvent: base, Amount: amount, Currency: currency}, nil
+	case *ledgerpb.Event_WithdrawalMade:
+		amount, currency, err := ParseMoney("amount", p.WithdrawalMade.GetAmount())
+		if err != nil {
+			return nil, err
+		}
+		return events.WithdrawalMadeEvent{BaseEvent: base, Amount: amount, Currency: currency}, nil
+	case *ledgerpb.Event_MoneyTransferred:
+		t := p.MoneyTransferred
+		debited, debitedCurrency, err := ParseMoney("debited", t.GetDebited())
+		if err != nil {
+			return nil, err
+		}
+		credited, creditedCurrency, err := ParseMoney("credited", t.GetCredited())
+		if err != nil {
+			return nil, err
+		}
+		rate, err := ParseDecimal("exchange_rate", t.GetExchangeRate())
+		if err != nil {
+			return nil, err
+		}
+		return events.MoneyTransferredEvent{
+			BaseEvent:        base,
+			TransferID:       t.GetTransferId(),
+			SourceAccountID:  t.GetSourceAccountId(),
+			TargetAccountID:  t.GetTargetAccountId(),
+			DebitedAmount:    debited,
+			DebitedCurrency:  debitedCurrency,
+			CreditedAmount:   credited,
+			CreditedCurrency: creditedCurrency,
+			ExchangeRate:     rate,
+		}, nil
+	case *ledgerpb.Event_CurrencyConverted:
+		c := p.CurrencyConverted
+		from, fromCurrency, err := ParseMoney("from", c.GetFrom())
+		if err != nil {
+			return nil, err
+		}
+		to, toCurrency, err := ParseMoney("to", c.GetTo())
+		if err != nil {
+			return nil, err
+		}
+		rate, err := ParseDecimal("exchange_rate", c.GetExchangeRate())
+		if err != nil {
+			return nil, err
+		}
+		return events.CurrencyConvertedEvent{BaseEvent: base, FromAmount: from, FromCurrency: fromCurrency, ToAmount: to, ToCurrency: toCurrency, ExchangeRate: rate}, nil
+	case *ledgerpb.Event_AccountDetailsUpdated:
+		return events.AccountDetailsUpdatedEvent{BaseEvent: base, PersonalData: events.SealedPersonalData{SubjectID: p.AccountDetailsUpdated.GetSubjectId()}}, nil
+	case *ledgerpb.Event_AccountClosed:
+		return events.AccountClosedEvent{BaseEvent: base}, nil
+	case *ledgerpb.Event_TransactionReversed:
+		r := p.TransactionReversed
+		amount, currency, err := ParseMoney("amount", r.GetAmount())
+		if err != nil {
+			return nil, err
+		}
+		return events.TransactionReversedEvent{
+			BaseEvent:       base,
+			ReversedEventID: r.GetReversedEventId(),
+			ReversedType:    events.EventType(r.GetReversedType()),
+			Amount:          amount,
+			Currency:        currency,
+		}, nil
+	}
+	return nil, &FieldError{Field: "payload", Description: fmt.Sprintf("event %s of type %s has no known payload", e.GetEventId(), e.GetType())}
+}
@@ -0,0 +1,99 @@
+package wire_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/events"
+	"financial-ledger/grpcapi/internal/wire"
+	"financial-ledger/grpcapi/ledgerpb"
+	"financial-ledger/shared"
+)
+
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func TestEventRoundTrip(t *testing.T) {
+	base := func(eventType events.EventType, version int) events.BaseEvent {
+		b := events.NewBaseEvent("acc-1", version, eventType)
+		b.Timestamp = time.Date(2025, 3, 1, 12, 0, 0, 123, time.UTC)
+		b.Position = int64(version + 10)
+		b.Metadata = events.Metadata{Actor: "ops", CorrelationID: "corr", CausationID: "cause", Channel: "cli", Reason: "test"}
+		return b
+	}
+	tests := []events.Event{
+		events.AccountCreatedEvent{BaseEvent: base(events.AccountCreatedType, 1), InitialBalances: []shared.Balance{{Currency: shared.USD, Amount: dec("10.50")}}},
+		events.DepositMadeEvent{BaseEvent: base(events.DepositMadeType, 2), Amount: dec("1.25"), Currency: shared.EUR},
+		events.WithdrawalMadeEvent{BaseEvent: base(events.WithdrawalMadeType, 3), Amount: dec("0.01"), Currency: shared.USD},
+		events.MoneyTransferredEvent{
+			BaseEvent: base(events.MoneyTransferredType, 4), TransferID: "t-1", SourceAccountID: "acc-1", TargetAccountID: "acc-2",
+			DebitedAmount: dec("5"), DebitedCurrency: shared.USD, CreditedAmount: dec("4.6"), CreditedCurrency: shared.EUR, ExchangeRate: dec("0.92"),
+		},
+		events.CurrencyConvertedEvent{BaseEvent: base(events.CurrencyConvertedType, 5), FromAmount: dec("10"), FromCurrency: shared.USD, ToAmount: dec("9.2"), ToCurrency: shared.EUR, ExchangeRate: dec("0.92")},
+		events.TransactionReversedEvent{BaseEvent: base(events.TransactionReversedType, 6), ReversedEventID: "ev-2", ReversedType: events.DepositMadeType, Amount: dec("1.25"), Currency: shared.EUR},
+		events.AccountClosedEvent{BaseEvent: base(events.AccountClosedType, 7)},
+	}
+	for _, want := range tests {
+		t.Run(string(want.GetBase().Type), func(t *testing.T) {
+			encoded, err := wire.Event(want)
+			if err != nil {
+				t.Fatalf("Event failed: %v", err)
+			}
+			got, err := wire.ParseEvent(encoded)
+			if err != nil {
+				t.Fatalf("ParseEvent failed: %v", err)
+			}
+			if normalise(got) != normalise(want) {
+				t.Errorf("round trip changed the event:\n got %#v\nwant %#v", got, want)
+			}
+		})
+	}
+
+	t.Run("PersonalDataIsNotSent", func(t *testing.T) {
+		updated := events.AccountDetailsUpdatedEvent{
+			BaseEvent:    base(events.AccountDetailsUpdatedType, 6),
+			PersonalData: events.SealedPersonalData{SubjectID: "subj-1", KeyID: "sk:subj-1:1", Nonce: []byte{1}, Data: []byte("secret")},
+		}
+		encoded, err := wire.Event(updated)
+		if err != nil {
+			t.Fatalf("Event failed: %v", err)
+		}
+		got, _ := wire.ParseEvent(encoded)
+		sealed := got.(events.AccountDetailsUpdatedEvent).PersonalData
+		if sealed.SubjectID != "subj-1" || sealed.KeyID != "" || sealed.Data != nil {
+			t.Errorf("expected only the subject to be carried, got %+v", sealed)
+		}
+	})
+}
+
+// normalise renders an event in a form where equal decimals compare equal.
+func normalise(e events.Event) string {
+	encoded, _ := wire.Event(e)
+	return encoded.String()
+}
+
+func TestParseMoney(t *testing.T) {
+	amount, currency, err := wire.ParseMoney("amount", &ledgerpb.Money{Amount: "12.30", Currency: "usd"})
+	if err != nil || !amount.Equal(dec("12.3")) || currency != shared.USD {
+		t.Errorf("unexpected result: %s %s %v", amount, currency, err)
+	}
+
+	_, _, err = wire.ParseMoney("amount", &ledgerpb.Money{Amount: "1,000", Currency: "USD"})
+	var fieldErr *wire.FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "amount.amount" {
+		t.Errorf("expected a FieldError on amount.amount, got %v", err)
+	}
+
+	if _, _, err := wire.ParseMoney("amount", nil); !errors.As(err, &fieldErr) {
+		t.Errorf("expected a FieldError for missing money, got %v", err)
+	}
+
+	_, err = wire.ParseBalances("initial_balances", []*ledgerpb.Money{{Amount: "1", Currency: "USD"}, {Amount: "2", Currency: "usd"}})
+	if !errors.As(err, &fieldErr) {
+		t.Errorf("expected duplicate currencies to be rejected, got %v", err)
+	}
+}
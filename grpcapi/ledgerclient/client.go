@@ -0,0 +1,261 @@
+// Package ledgerclient is a Go client for the ledger's gRPC API. It takes and
+// returns the same command, query and result types as app.AccountService, so
+// code can move between an in-process service and a remote ledger with few
+// changes.
+package ledgerclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"financial-ledger/app"
+	"financial-ledger/events"
+	"financial-ledger/grpcapi/internal/wire"
+	"financial-ledger/grpcapi/ledgerpb"
+	"financial-ledger/store"
+)
+
+type Client struct {
+	rpc  ledgerpb.LedgerServiceClient
+	conn *grpc.ClientConn
+}
+
+// New returns a client using an existing connection, which the caller closes.
+func New(conn grpc.ClientConnInterface) *Client {
+	return &Client{rpc: ledgerpb.NewLedgerServiceClient(conn)}
+}
+
+// Dial connects to the ledger at target. opts must include transport
+// credentials, for example grpc.WithTransportCredentials(insecure.NewCredentials()).
+func Dial(target string, opts ...grpc.DialOption) (*Client, error) {
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to ledger at %s: %w", target, err)
+	}
+	c := New(conn)
+	c.conn = conn
+	return c, nil
+}
+
+// Close closes the connection opened by Dial. It does nothing for clients
+// created with New.
+func (c *Client) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// --- Commands ---
+
+func (c *Client) CreateAccount(ctx context.Context, cmd app.CreateAccountCommand) (*app.AccountBalances, error) {
+	req := &ledgerpb.CreateAccountRequest{
+		AccountId:       cmd.AccountID,
+		InitialBalances: wire.Balances(cmd.InitialBalances),
+		SubjectId:       cmd.SubjectID,
+		IdempotencyKey:  cmd.IdempotencyKey,
+		Metadata:        requestMetadata(cmd.Metadata),
+	}
+	if cmd.Details != nil {
+		req.Details = personalDetails(*cmd.Details)
+	}
+	return parseBalances(c.rpc.CreateAccount(ctx, req))
+}
+
+func (c *Client) UpdateAccountDetails(ctx context.Context, cmd app.UpdateAccountDetailsCommand) (*app.AccountBalances, error) {
+	return parseBalances(c.rpc.UpdateAccountDetails(ctx, &ledgerpb.UpdateAccountDetailsRequest{
+		AccountId:      cmd.AccountID,
+		SubjectId:      cmd.SubjectID,
+		Details:        personalDetails(cmd.Details),
+		IdempotencyKey: cmd.IdempotencyKey,
+		Metadata:       requestMetadata(cmd.Metadata),
+	}))
+}
+
+func (c *Client) ForgetSubject(ctx context.Context, cmd app.ForgetSubjectCommand) (store.ShredRecord, error) {
+	resp, err := c.rpc.ForgetSubject(ctx, &ledgerpb.ForgetSubjectRequest{SubjectId: cmd.SubjectID, Metadata: requestMetadata(cmd.Metadata)})
+	if err != nil {
+		return store.ShredRecord{}, fromStatus(err)
+	}
+	return store.ShredRecord{
+		SubjectID:     resp.GetSubjectId(),
+		KeysDestroyed: int(resp.GetKeysDestroyed()),
+		Timestamp:     resp.GetTimestamp().AsTime(),
+	}, nil
+}
+
+func (c *Client) Deposit(ctx context.Context, cmd app.DepositMoneyCommand) (*app.AccountBalances, error) {
+	return parseBalances(c.rpc.Deposit(ctx, &ledgerpb.DepositRequest{
+		AccountId:       cmd.AccountID,
+		Amount:          wire.Money(cmd.Amount, cmd.Currency),
+		ExpectedVersion: int64(cmd.ExpectedVersion),
+		IdempotencyKey:  cmd.IdempotencyKey,
+		Metadata:        requestMetadata(cmd.Metadata),
+	}))
+}
+
+func (c *Client) Withdraw(ctx context.Context, cmd app.WithdrawMoneyCommand) (*app.AccountBalances, error) {
+	return parseBalances(c.rpc.Withdraw(ctx, &ledgerpb.WithdrawRequest{
+		AccountId:       cmd.AccountID,
+		Amount:          wire.Money(cmd.Amount, cmd.Currency),
+		ExpectedVersion: int64(cmd.ExpectedVersion),
+		IdempotencyKey:  cmd.IdempotencyKey,
+		Metadata:        requestMetadata(cmd.Metadata),
+	}))
+}
+
+func (c *Client) ConvertCurrency(ctx context.Context, cmd app.ConvertCurrencyCommand) (*app.AccountBalances, error) {
+	return parseBalances(c.rpc.ConvertCurrency(ctx, &ledgerpb.ConvertCurrencyRequest{
+		AccountId:       cmd.AccountID,
+		From:            wire.Money(cmd.FromAmount, cmd.FromCurrency),
+		ToCurrency:      string(cmd.ToCurrency),
+		ExpectedVersion: int64(cmd.ExpectedVersion),
+		IdempotencyKey:  cmd.IdempotencyKey,
+		Metadata:        requestMetadata(cmd.Metadata),
+	}))
+}
+
+// TransferMoney returns the balances of the source and target accounts after
+// the transfer. target is nil if the caller may not read the target account.
+func (c *Client) TransferMoney(ctx context.Context, cmd app.TransferMoneyCommand) (source, target *app.AccountBalances, err error) {
+	resp, err := c.rpc.TransferMoney(ctx, &ledgerpb.TransferMoneyRequest{
+		SourceAccountId: cmd.SourceAccountID,
+		TargetAccountId: cmd.TargetAccountID,
+		Amount:          wire.Money(cmd.Amount, cmd.Currency),
+		ExpectedVersion: int64(cmd.ExpectedVersion),
+		IdempotencyKey:  cmd.IdempotencyKey,
+		Metadata:        requestMetadata(cmd.Metadata),
+	})
+	if err != nil {
+		return nil, nil, fromStatus(err)
+	}
+	if source, err = wire.ParseAccountBalances(resp.GetSource()); err != nil {
+		return nil, nil, err
+	}
+	if resp.GetTarget() == nil {
+		return source, nil, nil
+	}
+	if target, err = wire.ParseAccountBalances(resp.GetTarget()); err != nil {
+		return nil, nil, err
+	}
+	return source, target, nil
+}
+
+// --- Queries ---
+
+func (c *Client) GetBalance(ctx context.Context, query app.GetBalanceQuery) (*app.AccountBalances, error) {
+	req := &ledgerpb.GetBalanceRequest{AccountId: query.AccountID}
+	if query.Currency != nil {
+		req.Currency = string(*query.Currency)
+	}
+	switch {
+	case query.AsOfVersion != 0 && query.AsOf != nil:
+		return nil, errors.New("cannot get balance: specify an as-of version or an as-of time, not both")
+	case query.AsOfVersion != 0:
+		req.AsOf = &ledgerpb.GetBalanceRequest_AsOfVersion{AsOfVersion: int64(query.AsOfVersion)}
+	case query.AsOf != nil:
+		req.AsOf = &ledgerpb.GetBalanceRequest_AsOfTime{AsOfTime: timestamppb.New(*query.AsOf)}
+	}
+	return parseBalances(c.rpc.GetBalance(ctx, req))
+}
+
+func (c *Client) SearchHistory(ctx context.Context, query app.SearchHistoryQuery) (*app.HistoryPage, error) {
+	req := &ledgerpb.SearchHistoryRequest{
+		AccountId:    query.AccountID,
+		Counterparty: query.Counterparty,
+		Descending:   query.Descending,
+		Limit:        int32(query.Limit),
+		Cursor:       query.Cursor,
+	}
+	for _, t := range query.Types {
+		req.Types = append(req.Types, string(t))
+	}
+	if query.Currency != nil {
+		req.Currency = string(*query.Currency)
+	}
+	if query.From != nil {
+		req.From = timestamppb.New(*query.From)
+	}
+	if query.To != nil {
+		req.To = timestamppb.New(*query.To)
+	}
+	if query.MinAmount != nil {
+		req.MinAmount = query.MinAmount.String()
+	}
+	if query.MaxAmount != nil {
+		req.MaxAmount = query.MaxAmount.String()
+	}
+
+	resp, err := c.rpc.SearchHistory(ctx, req)
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+	page := &app.HistoryPage{NextCursor: resp.GetNextCursor()}
+	for _, item := range resp.GetItems() {
+		event, err := wire.ParseEvent(item.GetEvent())
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode history of account %s: %w", query.AccountID, err)
+		}
+		balances, err := wire.ParseBalances("balances_after", item.GetBalancesAfter())
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode history of account %s: %w", query.AccountID, err)
+		}
+		page.Items = append(page.Items, app.HistoryItem{Event: event, BalancesAfter: balances})
+	}
+	return page, nil
+}
+
+// TailEvents calls fn with each event of an account after afterVersion and
+// then with every new event as the server commits it. It returns when ctx is
+// done, the stream fails, or fn returns an error.
+func (c *Client) TailEvents(ctx context.Context, accountID string, afterVersion int, fn func(events.Event) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.rpc.TailEvents(ctx, &ledgerpb.TailEventsRequest{AccountId: accountID, AfterVersion: int64(afterVersion)})
+	if err != nil {
+		return fromStatus(err)
+	}
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fromStatus(err)
+		}
+		event, err := wire.ParseEvent(msg)
+		if err != nil {
+			return fmt.Errorf("failed to decode event of account %s: %w", accountID, err)
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+}
+
+// --- Helpers ---
+
+func parseBalances(resp *ledgerpb.AccountBalances, err error) (*app.AccountBalances, error) {
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+	return wire.ParseAccountBalances(resp)
+}
+
+func personalDetails(d app.PersonalDetails) *ledgerpb.PersonalDetails {
+	return &ledgerpb.PersonalDetails{OwnerName: d.OwnerName, Address: d.Address, Notes: d.Notes}
+}
+
+// requestMetadata carries the caller's audit metadata. The server records the
+// channel as "grpc" whatever md.Channel says.
+func requestMetadata(md events.Metadata) *ledgerpb.RequestMetadata {
+	return &ledgerpb.RequestMetadata{Actor: md.Actor, CorrelationId: md.CorrelationID, Reason: md.Reason}
+}
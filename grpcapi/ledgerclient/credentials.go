@@ -0,0 +1,27 @@
+package ledgerclient
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// bearer attaches a credential to every call as "authorization: Bearer ...".
+type bearer struct {
+	credential string
+}
+
+func (b bearer) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + b.credential}, nil
+}
+
+func (b bearer) RequireTransportSecurity() bool {
+	return false
+}
+
+// WithCredential authenticates every call with an API key or JWT. Pass it to
+// Dial. The credential is sent over plaintext connections too, so use it with
+// insecure transport credentials only on trusted networks or in tests.
+func WithCredential(credential string) grpc.DialOption {
+	return grpc.WithPerRPCCredentials(bearer{credential: credential})
+}
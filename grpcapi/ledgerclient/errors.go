@@ -0,0 +1,76 @@
+package ledgerclient
+
+import (
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"financial-ledger/domain"
+	"financial-ledger/grpcapi/internal/wire"
+)
+
+// Error is a failed call. It unwraps to the same sentinel the service would
+// have returned in-process, so errors.Is(err, domain.ErrInsufficientFunds)
+// and similar checks work unchanged against a remote ledger.
+type Error struct {
+	Code    codes.Code
+	Reason  string // google.rpc.ErrorInfo reason, such as INSUFFICIENT_FUNDS
+	Message string
+
+	// FieldViolations lists malformed request fields for INVALID_ARGUMENT.
+	FieldViolations []*errdetails.BadRequest_FieldViolation
+
+	status *status.Status
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	if sentinel := wire.SentinelFor(e.Reason); sentinel != nil {
+		return sentinel
+	}
+	if e.Reason == wire.ReasonRuleViolation {
+		return domain.NewDomainError("%s", e.Message)
+	}
+	return nil
+}
+
+// GRPCStatus returns the status the server sent.
+func (e *Error) GRPCStatus() *status.Status {
+	return e.status
+}
+
+// fromStatus converts an error returned by a stub into an *Error. Errors that
+// carry no gRPC status, such as local context cancellation, pass through.
+func fromStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	e := &Error{Code: st.Code(), Message: st.Message(), status: st}
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if d.GetDomain() == wire.ErrorDomain {
+				e.Reason = d.GetReason()
+			}
+		case *errdetails.BadRequest:
+			e.FieldViolations = append(e.FieldViolations, d.GetFieldViolations()...)
+		}
+	}
+	return e
+}
+
+// IsRetryable reports whether a call failed only because of a concurrent
+// update and may succeed if repeated.
+func IsRetryable(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Code == codes.Aborted
+}
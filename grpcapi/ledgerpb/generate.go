@@ -0,0 +1,5 @@
+// Package ledgerpb holds the protobuf messages and gRPC stubs generated from
+// ledger.proto. Do not edit the generated files by hand.
+package ledgerpb
+
+//go:generate buf generate --template buf.gen.yaml --path ledger.proto
@@ -0,0 +1,2361 @@
+// The gRPC API of the financial ledger. It mirrors the commands and queries
+// of the application service (app/commands.go).
+//
+// Amounts are decimal strings such as "100.25", so no precision is lost on the
+// wire. Versions are account versions: the version after the last applied
+// event. Failures carry a google.rpc.ErrorInfo detail whose reason names the
+// domain error (for example INSUFFICIENT_FUNDS); a version mismatch also
+// carries a google.rpc.PreconditionFailure.
+//
+// Regenerate with `go generate ./grpcapi/...` (requires buf, protoc-gen-go and
+// protoc-gen-go-grpc on PATH).
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: ledger.proto
+
+package ledgerpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Money struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Amount        string                 `protobuf:"bytes,1,opt,name=amount,proto3" json:"amount,omitempty"`
+	Currency      string                 `protobuf:"bytes,2,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Money) Reset() {
+	*x = Money{}
+	mi := &file_ledger_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Money) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Money) ProtoMessage() {}
+
+func (x *Money) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Money.ProtoReflect.Descriptor instead.
+func (*Money) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Money) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *Money) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+// RequestMetadata is recorded on the events a command produces. The channel
+// is always recorded as "grpc".
+type RequestMetadata struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Actor         string                 `protobuf:"bytes,1,opt,name=actor,proto3" json:"actor,omitempty"`
+	CorrelationId string                 `protobuf:"bytes,2,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestMetadata) Reset() {
+	*x = RequestMetadata{}
+	mi := &file_ledger_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestMetadata) ProtoMessage() {}
+
+func (x *RequestMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestMetadata.ProtoReflect.Descriptor instead.
+func (*RequestMetadata) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RequestMetadata) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *RequestMetadata) GetCorrelationId() string {
+	if x != nil {
+		return x.CorrelationId
+	}
+	return ""
+}
+
+func (x *RequestMetadata) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type PersonalDetails struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OwnerName     string                 `protobuf:"bytes,1,opt,name=owner_name,json=ownerName,proto3" json:"owner_name,omitempty"`
+	Address       string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Notes         string                 `protobuf:"bytes,3,opt,name=notes,proto3" json:"notes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PersonalDetails) Reset() {
+	*x = PersonalDetails{}
+	mi := &file_ledger_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PersonalDetails) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PersonalDetails) ProtoMessage() {}
+
+func (x *PersonalDetails) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PersonalDetails.ProtoReflect.Descriptor instead.
+func (*PersonalDetails) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PersonalDetails) GetOwnerName() string {
+	if x != nil {
+		return x.OwnerName
+	}
+	return ""
+}
+
+func (x *PersonalDetails) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *PersonalDetails) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+type AccountBalances struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Version       int64                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	Balances      []*Money               `protobuf:"bytes,3,rep,name=balances,proto3" json:"balances,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AccountBalances) Reset() {
+	*x = AccountBalances{}
+	mi := &file_ledger_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccountBalances) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountBalances) ProtoMessage() {}
+
+func (x *AccountBalances) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountBalances.ProtoReflect.Descriptor instead.
+func (*AccountBalances) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AccountBalances) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *AccountBalances) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *AccountBalances) GetBalances() []*Money {
+	if x != nil {
+		return x.Balances
+	}
+	return nil
+}
+
+type CreateAccountRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	AccountId       string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	InitialBalances []*Money               `protobuf:"bytes,2,rep,name=initial_balances,json=initialBalances,proto3" json:"initial_balances,omitempty"`
+	SubjectId       string                 `protobuf:"bytes,3,opt,name=subject_id,json=subjectId,proto3" json:"subject_id,omitempty"`
+	Details         *PersonalDetails       `protobuf:"bytes,4,opt,name=details,proto3" json:"details,omitempty"`
+	IdempotencyKey  string                 `protobuf:"bytes,5,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	Metadata        *RequestMetadata       `protobuf:"bytes,6,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CreateAccountRequest) Reset() {
+	*x = CreateAccountRequest{}
+	mi := &file_ledger_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAccountRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAccountRequest) ProtoMessage() {}
+
+func (x *CreateAccountRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAccountRequest.ProtoReflect.Descriptor instead.
+func (*CreateAccountRequest) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateAccountRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *CreateAccountRequest) GetInitialBalances() []*Money {
+	if x != nil {
+		return x.InitialBalances
+	}
+	return nil
+}
+
+func (x *CreateAccountRequest) GetSubjectId() string {
+	if x != nil {
+		return x.SubjectId
+	}
+	return ""
+}
+
+func (x *CreateAccountRequest) GetDetails() *PersonalDetails {
+	if x != nil {
+		return x.Details
+	}
+	return nil
+}
+
+func (x *CreateAccountRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *CreateAccountRequest) GetMetadata() *RequestMetadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type UpdateAccountDetailsRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	AccountId      string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	SubjectId      string                 `protobuf:"bytes,2,opt,name=subject_id,json=subjectId,proto3" json:"subject_id,omitempty"`
+	Details        *PersonalDetails       `protobuf:"bytes,3,opt,name=details,proto3" json:"details,omitempty"`
+	IdempotencyKey string                 `protobuf:"bytes,4,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	Metadata       *RequestMetadata       `protobuf:"bytes,5,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UpdateAccountDetailsRequest) Reset() {
+	*x = UpdateAccountDetailsRequest{}
+	mi := &file_ledger_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateAccountDetailsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateAccountDetailsRequest) ProtoMessage() {}
+
+func (x *UpdateAccountDetailsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateAccountDetailsRequest.ProtoReflect.Descriptor instead.
+func (*UpdateAccountDetailsRequest) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UpdateAccountDetailsRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *UpdateAccountDetailsRequest) GetSubjectId() string {
+	if x != nil {
+		return x.SubjectId
+	}
+	return ""
+}
+
+func (x *UpdateAccountDetailsRequest) GetDetails() *PersonalDetails {
+	if x != nil {
+		return x.Details
+	}
+	return nil
+}
+
+func (x *UpdateAccountDetailsRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *UpdateAccountDetailsRequest) GetMetadata() *RequestMetadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type ForgetSubjectRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SubjectId     string                 `protobuf:"bytes,1,opt,name=subject_id,json=subjectId,proto3" json:"subject_id,omitempty"`
+	Metadata      *RequestMetadata       `protobuf:"bytes,2,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ForgetSubjectRequest) Reset() {
+	*x = ForgetSubjectRequest{}
+	mi := &file_ledger_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForgetSubjectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForgetSubjectRequest) ProtoMessage() {}
+
+func (x *ForgetSubjectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForgetSubjectRequest.ProtoReflect.Descriptor instead.
+func (*ForgetSubjectRequest) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ForgetSubjectRequest) GetSubjectId() string {
+	if x != nil {
+		return x.SubjectId
+	}
+	return ""
+}
+
+func (x *ForgetSubjectRequest) GetMetadata() *RequestMetadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type ForgetSubjectResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SubjectId     string                 `protobuf:"bytes,1,opt,name=subject_id,json=subjectId,proto3" json:"subject_id,omitempty"`
+	KeysDestroyed int32                  `protobuf:"varint,2,opt,name=keys_destroyed,json=keysDestroyed,proto3" json:"keys_destroyed,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ForgetSubjectResponse) Reset() {
+	*x = ForgetSubjectResponse{}
+	mi := &file_ledger_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForgetSubjectResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForgetSubjectResponse) ProtoMessage() {}
+
+func (x *ForgetSubjectResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForgetSubjectResponse.ProtoReflect.Descriptor instead.
+func (*ForgetSubjectResponse) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ForgetSubjectResponse) GetSubjectId() string {
+	if x != nil {
+		return x.SubjectId
+	}
+	return ""
+}
+
+func (x *ForgetSubjectResponse) GetKeysDestroyed() int32 {
+	if x != nil {
+		return x.KeysDestroyed
+	}
+	return 0
+}
+
+func (x *ForgetSubjectResponse) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+type DepositRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	AccountId       string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Amount          *Money                 `protobuf:"bytes,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	ExpectedVersion int64                  `protobuf:"varint,3,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	IdempotencyKey  string                 `protobuf:"bytes,4,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	Metadata        *RequestMetadata       `protobuf:"bytes,5,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *DepositRequest) Reset() {
+	*x = DepositRequest{}
+	mi := &file_ledger_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DepositRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DepositRequest) ProtoMessage() {}
+
+func (x *DepositRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DepositRequest.ProtoReflect.Descriptor instead.
+func (*DepositRequest) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DepositRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *DepositRequest) GetAmount() *Money {
+	if x != nil {
+		return x.Amount
+	}
+	return nil
+}
+
+func (x *DepositRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+func (x *DepositRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *DepositRequest) GetMetadata() *RequestMetadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type WithdrawRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	AccountId       string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Amount          *Money                 `protobuf:"bytes,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	ExpectedVersion int64                  `protobuf:"varint,3,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	IdempotencyKey  string                 `protobuf:"bytes,4,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	Metadata        *RequestMetadata       `protobuf:"bytes,5,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *WithdrawRequest) Reset() {
+	*x = WithdrawRequest{}
+	mi := &file_ledger_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WithdrawRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WithdrawRequest) ProtoMessage() {}
+
+func (x *WithdrawRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WithdrawRequest.ProtoReflect.Descriptor instead.
+func (*WithdrawRequest) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *WithdrawRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *WithdrawRequest) GetAmount() *Money {
+	if x != nil {
+		return x.Amount
+	}
+	return nil
+}
+
+func (x *WithdrawRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+func (x *WithdrawRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *WithdrawRequest) GetMetadata() *RequestMetadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type ConvertCurrencyRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	AccountId       string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	From            *Money                 `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	ToCurrency      string                 `protobuf:"bytes,3,opt,name=to_currency,json=toCurrency,proto3" json:"to_currency,omitempty"`
+	ExpectedVersion int64                  `protobuf:"varint,4,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	IdempotencyKey  string                 `protobuf:"bytes,5,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	Metadata        *RequestMetadata       `protobuf:"bytes,6,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ConvertCurrencyRequest) Reset() {
+	*x = ConvertCurrencyRequest{}
+	mi := &file_ledger_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConvertCurrencyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertCurrencyRequest) ProtoMessage() {}
+
+func (x *ConvertCurrencyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertCurrencyRequest.ProtoReflect.Descriptor instead.
+func (*ConvertCurrencyRequest) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ConvertCurrencyRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *ConvertCurrencyRequest) GetFrom() *Money {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *ConvertCurrencyRequest) GetToCurrency() string {
+	if x != nil {
+		return x.ToCurrency
+	}
+	return ""
+}
+
+func (x *ConvertCurrencyRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+func (x *ConvertCurrencyRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *ConvertCurrencyRequest) GetMetadata() *RequestMetadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type TransferMoneyRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	SourceAccountId string                 `protobuf:"bytes,1,opt,name=source_account_id,json=sourceAccountId,proto3" json:"source_account_id,omitempty"`
+	TargetAccountId string                 `protobuf:"bytes,2,opt,name=target_account_id,json=targetAccountId,proto3" json:"target_account_id,omitempty"`
+	Amount          *Money                 `protobuf:"bytes,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	// Of the source account.
+	ExpectedVersion int64            `protobuf:"varint,4,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	IdempotencyKey  string           `protobuf:"bytes,5,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	Metadata        *RequestMetadata `protobuf:"bytes,6,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *TransferMoneyRequest) Reset() {
+	*x = TransferMoneyRequest{}
+	mi := &file_ledger_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransferMoneyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransferMoneyRequest) ProtoMessage() {}
+
+func (x *TransferMoneyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransferMoneyRequest.ProtoReflect.Descriptor instead.
+func (*TransferMoneyRequest) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *TransferMoneyRequest) GetSourceAccountId() string {
+	if x != nil {
+		return x.SourceAccountId
+	}
+	return ""
+}
+
+func (x *TransferMoneyRequest) GetTargetAccountId() string {
+	if x != nil {
+		return x.TargetAccountId
+	}
+	return ""
+}
+
+func (x *TransferMoneyRequest) GetAmount() *Money {
+	if x != nil {
+		return x.Amount
+	}
+	return nil
+}
+
+func (x *TransferMoneyRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+func (x *TransferMoneyRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *TransferMoneyRequest) GetMetadata() *RequestMetadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type TransferMoneyResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Source *AccountBalances       `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	// Unset when the caller may not read the target account.
+	Target        *AccountBalances `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransferMoneyResponse) Reset() {
+	*x = TransferMoneyResponse{}
+	mi := &file_ledger_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransferMoneyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransferMoneyResponse) ProtoMessage() {}
+
+func (x *TransferMoneyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransferMoneyResponse.ProtoReflect.Descriptor instead.
+func (*TransferMoneyResponse) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *TransferMoneyResponse) GetSource() *AccountBalances {
+	if x != nil {
+		return x.Source
+	}
+	return nil
+}
+
+func (x *TransferMoneyResponse) GetTarget() *AccountBalances {
+	if x != nil {
+		return x.Target
+	}
+	return nil
+}
+
+type GetBalanceRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// Empty for every currency.
+	Currency string `protobuf:"bytes,2,opt,name=currency,proto3" json:"currency,omitempty"`
+	// Types that are valid to be assigned to AsOf:
+	//
+	//	*GetBalanceRequest_AsOfVersion
+	//	*GetBalanceRequest_AsOfTime
+	AsOf          isGetBalanceRequest_AsOf `protobuf_oneof:"as_of"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBalanceRequest) Reset() {
+	*x = GetBalanceRequest{}
+	mi := &file_ledger_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBalanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBalanceRequest) ProtoMessage() {}
+
+func (x *GetBalanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBalanceRequest.ProtoReflect.Descriptor instead.
+func (*GetBalanceRequest) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetBalanceRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *GetBalanceRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *GetBalanceRequest) GetAsOf() isGetBalanceRequest_AsOf {
+	if x != nil {
+		return x.AsOf
+	}
+	return nil
+}
+
+func (x *GetBalanceRequest) GetAsOfVersion() int64 {
+	if x != nil {
+		if x, ok := x.AsOf.(*GetBalanceRequest_AsOfVersion); ok {
+			return x.AsOfVersion
+		}
+	}
+	return 0
+}
+
+func (x *GetBalanceRequest) GetAsOfTime() *timestamppb.Timestamp {
+	if x != nil {
+		if x, ok := x.AsOf.(*GetBalanceRequest_AsOfTime); ok {
+			return x.AsOfTime
+		}
+	}
+	return nil
+}
+
+type isGetBalanceRequest_AsOf interface {
+	isGetBalanceRequest_AsOf()
+}
+
+type GetBalanceRequest_AsOfVersion struct {
+	AsOfVersion int64 `protobuf:"varint,3,opt,name=as_of_version,json=asOfVersion,proto3,oneof"`
+}
+
+type GetBalanceRequest_AsOfTime struct {
+	AsOfTime *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=as_of_time,json=asOfTime,proto3,oneof"`
+}
+
+func (*GetBalanceRequest_AsOfVersion) isGetBalanceRequest_AsOf() {}
+
+func (*GetBalanceRequest_AsOfTime) isGetBalanceRequest_AsOf() {}
+
+// SearchHistoryRequest has the semantics of app.SearchHistoryQuery: unset
+// filters match everything, from is inclusive and to exclusive.
+type SearchHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Types         []string               `protobuf:"bytes,2,rep,name=types,proto3" json:"types,omitempty"`
+	Currency      string                 `protobuf:"bytes,3,opt,name=currency,proto3" json:"currency,omitempty"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=to,proto3" json:"to,omitempty"`
+	MinAmount     string                 `protobuf:"bytes,6,opt,name=min_amount,json=minAmount,proto3" json:"min_amount,omitempty"`
+	MaxAmount     string                 `protobuf:"bytes,7,opt,name=max_amount,json=maxAmount,proto3" json:"max_amount,omitempty"`
+	Counterparty  string                 `protobuf:"bytes,8,opt,name=counterparty,proto3" json:"counterparty,omitempty"`
+	Descending    bool                   `protobuf:"varint,9,opt,name=descending,proto3" json:"descending,omitempty"`
+	Limit         int32                  `protobuf:"varint,10,opt,name=limit,proto3" json:"limit,omitempty"`
+	Cursor        string                 `protobuf:"bytes,11,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchHistoryRequest) Reset() {
+	*x = SearchHistoryRequest{}
+	mi := &file_ledger_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchHistoryRequest) ProtoMessage() {}
+
+func (x *SearchHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchHistoryRequest.ProtoReflect.Descriptor instead.
+func (*SearchHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SearchHistoryRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *SearchHistoryRequest) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+func (x *SearchHistoryRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *SearchHistoryRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *SearchHistoryRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *SearchHistoryRequest) GetMinAmount() string {
+	if x != nil {
+		return x.MinAmount
+	}
+	return ""
+}
+
+func (x *SearchHistoryRequest) GetMaxAmount() string {
+	if x != nil {
+		return x.MaxAmount
+	}
+	return ""
+}
+
+func (x *SearchHistoryRequest) GetCounterparty() string {
+	if x != nil {
+		return x.Counterparty
+	}
+	return ""
+}
+
+func (x *SearchHistoryRequest) GetDescending() bool {
+	if x != nil {
+		return x.Descending
+	}
+	return false
+}
+
+func (x *SearchHistoryRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SearchHistoryRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+type HistoryItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         *Event                 `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	BalancesAfter []*Money               `protobuf:"bytes,2,rep,name=balances_after,json=balancesAfter,proto3" json:"balances_after,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HistoryItem) Reset() {
+	*x = HistoryItem{}
+	mi := &file_ledger_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HistoryItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistoryItem) ProtoMessage() {}
+
+func (x *HistoryItem) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistoryItem.ProtoReflect.Descriptor instead.
+func (*HistoryItem) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *HistoryItem) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *HistoryItem) GetBalancesAfter() []*Money {
+	if x != nil {
+		return x.BalancesAfter
+	}
+	return nil
+}
+
+type SearchHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*HistoryItem         `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchHistoryResponse) Reset() {
+	*x = SearchHistoryResponse{}
+	mi := &file_ledger_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchHistoryResponse) ProtoMessage() {}
+
+func (x *SearchHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchHistoryResponse.ProtoReflect.Descriptor instead.
+func (*SearchHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SearchHistoryResponse) GetItems() []*HistoryItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *SearchHistoryResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type TailEventsRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// 0 streams the whole history first.
+	AfterVersion  int64 `protobuf:"varint,2,opt,name=after_version,json=afterVersion,proto3" json:"after_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TailEventsRequest) Reset() {
+	*x = TailEventsRequest{}
+	mi := &file_ledger_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TailEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TailEventsRequest) ProtoMessage() {}
+
+func (x *TailEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TailEventsRequest.ProtoReflect.Descriptor instead.
+func (*TailEventsRequest) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *TailEventsRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *TailEventsRequest) GetAfterVersion() int64 {
+	if x != nil {
+		return x.AfterVersion
+	}
+	return 0
+}
+
+type EventMetadata struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Actor         string                 `protobuf:"bytes,1,opt,name=actor,proto3" json:"actor,omitempty"`
+	CorrelationId string                 `protobuf:"bytes,2,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+	CausationId   string                 `protobuf:"bytes,3,opt,name=causation_id,json=causationId,proto3" json:"causation_id,omitempty"`
+	Channel       string                 `protobuf:"bytes,4,opt,name=channel,proto3" json:"channel,omitempty"`
+	Reason        string                 `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EventMetadata) Reset() {
+	*x = EventMetadata{}
+	mi := &file_ledger_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EventMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EventMetadata) ProtoMessage() {}
+
+func (x *EventMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EventMetadata.ProtoReflect.Descriptor instead.
+func (*EventMetadata) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *EventMetadata) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *EventMetadata) GetCorrelationId() string {
+	if x != nil {
+		return x.CorrelationId
+	}
+	return ""
+}
+
+func (x *EventMetadata) GetCausationId() string {
+	if x != nil {
+		return x.CausationId
+	}
+	return ""
+}
+
+func (x *EventMetadata) GetChannel() string {
+	if x != nil {
+		return x.Channel
+	}
+	return ""
+}
+
+func (x *EventMetadata) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type Event struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	EventId   string                 `protobuf:"bytes,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	AccountId string                 `protobuf:"bytes,2,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Version   int64                  `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	Type      string                 `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Position  int64                  `protobuf:"varint,6,opt,name=position,proto3" json:"position,omitempty"`
+	Metadata  *EventMetadata         `protobuf:"bytes,7,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*Event_AccountCreated
+	//	*Event_DepositMade
+	//	*Event_WithdrawalMade
+	//	*Event_MoneyTransferred
+	//	*Event_CurrencyConverted
+	//	*Event_AccountDetailsUpdated
+	//	*Event_AccountClosed
+	//	*Event_TransactionReversed
+	Payload       isEvent_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_ledger_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Event) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *Event) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *Event) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Event) GetPosition() int64 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *Event) GetMetadata() *EventMetadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *Event) GetPayload() isEvent_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Event) GetAccountCreated() *AccountCreated {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_AccountCreated); ok {
+			return x.AccountCreated
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetDepositMade() *DepositMade {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_DepositMade); ok {
+			return x.DepositMade
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetWithdrawalMade() *WithdrawalMade {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_WithdrawalMade); ok {
+			return x.WithdrawalMade
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetMoneyTransferred() *MoneyTransferred {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_MoneyTransferred); ok {
+			return x.MoneyTransferred
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetCurrencyConverted() *CurrencyConverted {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_CurrencyConverted); ok {
+			return x.CurrencyConverted
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetAccountDetailsUpdated() *AccountDetailsUpdated {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_AccountDetailsUpdated); ok {
+			return x.AccountDetailsUpdated
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetAccountClosed() *AccountClosed {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_AccountClosed); ok {
+			return x.AccountClosed
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetTransactionReversed() *TransactionReversed {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_TransactionReversed); ok {
+			return x.TransactionReversed
+		}
+	}
+	return nil
+}
+
+type isEvent_Payload interface {
+	isEvent_Payload()
+}
+
+type Event_AccountCreated struct {
+	AccountCreated *AccountCreated `protobuf:"bytes,10,opt,name=account_created,json=accountCreated,proto3,oneof"`
+}
+
+type Event_DepositMade struct {
+	DepositMade *DepositMade `protobuf:"bytes,11,opt,name=deposit_made,json=depositMade,proto3,oneof"`
+}
+
+type Event_WithdrawalMade struct {
+	WithdrawalMade *WithdrawalMade `protobuf:"bytes,12,opt,name=withdrawal_made,json=withdrawalMade,proto3,oneof"`
+}
+
+type Event_MoneyTransferred struct {
+	MoneyTransferred *MoneyTransferred `protobuf:"bytes,13,opt,name=money_transferred,json=moneyTransferred,proto3,oneof"`
+}
+
+type Event_CurrencyConverted struct {
+	CurrencyConverted *CurrencyConverted `protobuf:"bytes,14,opt,name=currency_converted,json=currencyConverted,proto3,oneof"`
+}
+
+type Event_AccountDetailsUpdated struct {
+	AccountDetailsUpdated *AccountDetailsUpdated `protobuf:"bytes,15,opt,name=account_details_updated,json=accountDetailsUpdated,proto3,oneof"`
+}
+
+type Event_AccountClosed struct {
+	AccountClosed *AccountClosed `protobuf:"bytes,16,opt,name=account_closed,json=accountClosed,proto3,oneof"`
+}
+
+type Event_TransactionReversed struct {
+	TransactionReversed *TransactionReversed `protobuf:"bytes,17,opt,name=transaction_reversed,json=transactionReversed,proto3,oneof"`
+}
+
+func (*Event_AccountCreated) isEvent_Payload() {}
+
+func (*Event_DepositMade) isEvent_Payload() {}
+
+func (*Event_WithdrawalMade) isEvent_Payload() {}
+
+func (*Event_MoneyTransferred) isEvent_Payload() {}
+
+func (*Event_CurrencyConverted) isEvent_Payload() {}
+
+func (*Event_AccountDetailsUpdated) isEvent_Payload() {}
+
+func (*Event_AccountClosed) isEvent_Payload() {}
+
+func (*Event_TransactionReversed) isEvent_Payload() {}
+
+type AccountCreated struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	InitialBalances []*Money               `protobuf:"bytes,1,rep,name=initial_balances,json=initialBalances,proto3" json:"initial_balances,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *AccountCreated) Reset() {
+	*x = AccountCreated{}
+	mi := &file_ledger_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccountCreated) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountCreated) ProtoMessage() {}
+
+func (x *AccountCreated) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountCreated.ProtoReflect.Descriptor instead.
+func (*AccountCreated) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *AccountCreated) GetInitialBalances() []*Money {
+	if x != nil {
+		return x.InitialBalances
+	}
+	return nil
+}
+
+type DepositMade struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Amount        *Money                 `protobuf:"bytes,1,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DepositMade) Reset() {
+	*x = DepositMade{}
+	mi := &file_ledger_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DepositMade) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DepositMade) ProtoMessage() {}
+
+func (x *DepositMade) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DepositMade.ProtoReflect.Descriptor instead.
+func (*DepositMade) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *DepositMade) GetAmount() *Money {
+	if x != nil {
+		return x.Amount
+	}
+	return nil
+}
+
+type WithdrawalMade struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Amount        *Money                 `protobuf:"bytes,1,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WithdrawalMade) Reset() {
+	*x = WithdrawalMade{}
+	mi := &file_ledger_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WithdrawalMade) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WithdrawalMade) ProtoMessage() {}
+
+func (x *WithdrawalMade) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WithdrawalMade.ProtoReflect.Descriptor instead.
+func (*WithdrawalMade) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *WithdrawalMade) GetAmount() *Money {
+	if x != nil {
+		return x.Amount
+	}
+	return nil
+}
+
+type MoneyTransferred struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	TransferId      string                 `protobuf:"bytes,1,opt,name=transfer_id,json=transferId,proto3" json:"transfer_id,omitempty"`
+	SourceAccountId string                 `protobuf:"bytes,2,opt,name=source_account_id,json=sourceAccountId,proto3" json:"source_account_id,omitempty"`
+	TargetAccountId string                 `protobuf:"bytes,3,opt,name=target_account_id,json=targetAccountId,proto3" json:"target_account_id,omitempty"`
+	Debited         *Money                 `protobuf:"bytes,4,opt,name=debited,proto3" json:"debited,omitempty"`
+	Credited        *Money                 `protobuf:"bytes,5,opt,name=credited,proto3" json:"credited,omitempty"`
+	ExchangeRate    string                 `protobuf:"bytes,6,opt,name=exchange_rate,json=exchangeRate,proto3" json:"exchange_rate,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *MoneyTransferred) Reset() {
+	*x = MoneyTransferred{}
+	mi := &file_ledger_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MoneyTransferred) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoneyTransferred) ProtoMessage() {}
+
+func (x *MoneyTransferred) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoneyTransferred.ProtoReflect.Descriptor instead.
+func (*MoneyTransferred) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *MoneyTransferred) GetTransferId() string {
+	if x != nil {
+		return x.TransferId
+	}
+	return ""
+}
+
+func (x *MoneyTransferred) GetSourceAccountId() string {
+	if x != nil {
+		return x.SourceAccountId
+	}
+	return ""
+}
+
+func (x *MoneyTransferred) GetTargetAccountId() string {
+	if x != nil {
+		return x.TargetAccountId
+	}
+	return ""
+}
+
+func (x *MoneyTransferred) GetDebited() *Money {
+	if x != nil {
+		return x.Debited
+	}
+	return nil
+}
+
+func (x *MoneyTransferred) GetCredited() *Money {
+	if x != nil {
+		return x.Credited
+	}
+	return nil
+}
+
+func (x *MoneyTransferred) GetExchangeRate() string {
+	if x != nil {
+		return x.ExchangeRate
+	}
+	return ""
+}
+
+type CurrencyConverted struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          *Money                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            *Money                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	ExchangeRate  string                 `protobuf:"bytes,3,opt,name=exchange_rate,json=exchangeRate,proto3" json:"exchange_rate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CurrencyConverted) Reset() {
+	*x = CurrencyConverted{}
+	mi := &file_ledger_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CurrencyConverted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CurrencyConverted) ProtoMessage() {}
+
+func (x *CurrencyConverted) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CurrencyConverted.ProtoReflect.Descriptor instead.
+func (*CurrencyConverted) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *CurrencyConverted) GetFrom() *Money {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *CurrencyConverted) GetTo() *Money {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *CurrencyConverted) GetExchangeRate() string {
+	if x != nil {
+		return x.ExchangeRate
+	}
+	return ""
+}
+
+// Personal data never leaves the server over this API; only the subject is named.
+type AccountDetailsUpdated struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SubjectId     string                 `protobuf:"bytes,1,opt,name=subject_id,json=subjectId,proto3" json:"subject_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AccountDetailsUpdated) Reset() {
+	*x = AccountDetailsUpdated{}
+	mi := &file_ledger_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccountDetailsUpdated) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountDetailsUpdated) ProtoMessage() {}
+
+func (x *AccountDetailsUpdated) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountDetailsUpdated.ProtoReflect.Descriptor instead.
+func (*AccountDetailsUpdated) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *AccountDetailsUpdated) GetSubjectId() string {
+	if x != nil {
+		return x.SubjectId
+	}
+	return ""
+}
+
+type AccountClosed struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AccountClosed) Reset() {
+	*x = AccountClosed{}
+	mi := &file_ledger_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccountClosed) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountClosed) ProtoMessage() {}
+
+func (x *AccountClosed) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountClosed.ProtoReflect.Descriptor instead.
+func (*AccountClosed) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{26}
+}
+
+// Reverses a deposit or withdrawal on the same account. A reversed deposit
+// takes amount back out; a reversed withdrawal pays it back in.
+type TransactionReversed struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ReversedEventId string                 `protobuf:"bytes,1,opt,name=reversed_event_id,json=reversedEventId,proto3" json:"reversed_event_id,omitempty"`
+	ReversedType    string                 `protobuf:"bytes,2,opt,name=reversed_type,json=reversedType,proto3" json:"reversed_type,omitempty"`
+	Amount          *Money                 `protobuf:"bytes,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *TransactionReversed) Reset() {
+	*x = TransactionReversed{}
+	mi := &file_ledger_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionReversed) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionReversed) ProtoMessage() {}
+
+func (x *TransactionReversed) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionReversed.ProtoReflect.Descriptor instead.
+func (*TransactionReversed) Descriptor() ([]byte, []int) {
+	return file_ledger_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *TransactionReversed) GetReversedEventId() string {
+	if x != nil {
+		return x.ReversedEventId
+	}
+	return ""
+}
+
+func (x *TransactionReversed) GetReversedType() string {
+	if x != nil {
+		return x.ReversedType
+	}
+	return ""
+}
+
+func (x *TransactionReversed) GetAmount() *Money {
+	if x != nil {
+		return x.Amount
+	}
+	return nil
+}
+
+var File_ledger_proto protoreflect.FileDescriptor
+
+const file_ledger_proto_rawDesc = "" +
+	"\n" +
+	"\fledger.proto\x12\tledger.v1\x1a\x1fgoogle/protobuf/timestamp.proto\";\n" +
+	"\x05Money\x12\x16\n" +
+	"\x06amount\x18\x01 \x01(\tR\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x02 \x01(\tR\bcurrency\"f\n" +
+	"\x0fRequestMetadata\x12\x14\n" +
+	"\x05actor\x18\x01 \x01(\tR\x05actor\x12%\n" +
+	"\x0ecorrelation_id\x18\x02 \x01(\tR\rcorrelationId\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"`\n" +
+	"\x0fPersonalDetails\x12\x1d\n" +
+	"\n" +
+	"owner_name\x18\x01 \x01(\tR\townerName\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12\x14\n" +
+	"\x05notes\x18\x03 \x01(\tR\x05notes\"x\n" +
+	"\x0fAccountBalances\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x03R\aversion\x12,\n" +
+	"\bbalances\x18\x03 \x03(\v2\x10.ledger.v1.MoneyR\bbalances\"\xa8\x02\n" +
+	"\x14CreateAccountRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12;\n" +
+	"\x10initial_balances\x18\x02 \x03(\v2\x10.ledger.v1.MoneyR\x0finitialBalances\x12\x1d\n" +
+	"\n" +
+	"subject_id\x18\x03 \x01(\tR\tsubjectId\x124\n" +
+	"\adetails\x18\x04 \x01(\v2\x1a.ledger.v1.PersonalDetailsR\adetails\x12'\n" +
+	"\x0fidempotency_key\x18\x05 \x01(\tR\x0eidempotencyKey\x126\n" +
+	"\bmetadata\x18\x06 \x01(\v2\x1a.ledger.v1.RequestMetadataR\bmetadata\"\xf2\x01\n" +
+	"\x1bUpdateAccountDetailsRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x1d\n" +
+	"\n" +
+	"subject_id\x18\x02 \x01(\tR\tsubjectId\x124\n" +
+	"\adetails\x18\x03 \x01(\v2\x1a.ledger.v1.PersonalDetailsR\adetails\x12'\n" +
+	"\x0fidempotency_key\x18\x04 \x01(\tR\x0eidempotencyKey\x126\n" +
+	"\bmetadata\x18\x05 \x01(\v2\x1a.ledger.v1.RequestMetadataR\bmetadata\"m\n" +
+	"\x14ForgetSubjectRequest\x12\x1d\n" +
+	"\n" +
+	"subject_id\x18\x01 \x01(\tR\tsubjectId\x126\n" +
+	"\bmetadata\x18\x02 \x01(\v2\x1a.ledger.v1.RequestMetadataR\bmetadata\"\x97\x01\n" +
+	"\x15ForgetSubjectResponse\x12\x1d\n" +
+	"\n" +
+	"subject_id\x18\x01 \x01(\tR\tsubjectId\x12%\n" +
+	"\x0ekeys_destroyed\x18\x02 \x01(\x05R\rkeysDestroyed\x128\n" +
+	"\ttimestamp\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"\xe5\x01\n" +
+	"\x0eDepositRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12(\n" +
+	"\x06amount\x18\x02 \x01(\v2\x10.ledger.v1.MoneyR\x06amount\x12)\n" +
+	"\x10expected_version\x18\x03 \x01(\x03R\x0fexpectedVersion\x12'\n" +
+	"\x0fidempotency_key\x18\x04 \x01(\tR\x0eidempotencyKey\x126\n" +
+	"\bmetadata\x18\x05 \x01(\v2\x1a.ledger.v1.RequestMetadataR\bmetadata\"\xe6\x01\n" +
+	"\x0fWithdrawRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12(\n" +
+	"\x06amount\x18\x02 \x01(\v2\x10.ledger.v1.MoneyR\x06amount\x12)\n" +
+	"\x10expected_version\x18\x03 \x01(\x03R\x0fexpectedVersion\x12'\n" +
+	"\x0fidempotency_key\x18\x04 \x01(\tR\x0eidempotencyKey\x126\n" +
+	"\bmetadata\x18\x05 \x01(\v2\x1a.ledger.v1.RequestMetadataR\bmetadata\"\x8a\x02\n" +
+	"\x16ConvertCurrencyRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12$\n" +
+	"\x04from\x18\x02 \x01(\v2\x10.ledger.v1.MoneyR\x04from\x12\x1f\n" +
+	"\vto_currency\x18\x03 \x01(\tR\n" +
+	"toCurrency\x12)\n" +
+	"\x10expected_version\x18\x04 \x01(\x03R\x0fexpectedVersion\x12'\n" +
+	"\x0fidempotency_key\x18\x05 \x01(\tR\x0eidempotencyKey\x126\n" +
+	"\bmetadata\x18\x06 \x01(\v2\x1a.ledger.v1.RequestMetadataR\bmetadata\"\xa4\x02\n" +
+	"\x14TransferMoneyRequest\x12*\n" +
+	"\x11source_account_id\x18\x01 \x01(\tR\x0fsourceAccountId\x12*\n" +
+	"\x11target_account_id\x18\x02 \x01(\tR\x0ftargetAccountId\x12(\n" +
+	"\x06amount\x18\x03 \x01(\v2\x10.ledger.v1.MoneyR\x06amount\x12)\n" +
+	"\x10expected_version\x18\x04 \x01(\x03R\x0fexpectedVersion\x12'\n" +
+	"\x0fidempotency_key\x18\x05 \x01(\tR\x0eidempotencyKey\x126\n" +
+	"\bmetadata\x18\x06 \x01(\v2\x1a.ledger.v1.RequestMetadataR\bmetadata\"\x7f\n" +
+	"\x15TransferMoneyResponse\x122\n" +
+	"\x06source\x18\x01 \x01(\v2\x1a.ledger.v1.AccountBalancesR\x06source\x122\n" +
+	"\x06target\x18\x02 \x01(\v2\x1a.ledger.v1.AccountBalancesR\x06target\"\xb9\x01\n" +
+	"\x11GetBalanceRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x1a\n" +
+	"\bcurrency\x18\x02 \x01(\tR\bcurrency\x12$\n" +
+	"\ras_of_version\x18\x03 \x01(\x03H\x00R\vasOfVersion\x12:\n" +
+	"\n" +
+	"as_of_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampH\x00R\basOfTimeB\a\n" +
+	"\x05as_of\"\xf3\x02\n" +
+	"\x14SearchHistoryRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x14\n" +
+	"\x05types\x18\x02 \x03(\tR\x05types\x12\x1a\n" +
+	"\bcurrency\x18\x03 \x01(\tR\bcurrency\x12.\n" +
+	"\x04from\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12\x1d\n" +
+	"\n" +
+	"min_amount\x18\x06 \x01(\tR\tminAmount\x12\x1d\n" +
+	"\n" +
+	"max_amount\x18\a \x01(\tR\tmaxAmount\x12\"\n" +
+	"\fcounterparty\x18\b \x01(\tR\fcounterparty\x12\x1e\n" +
+	"\n" +
+	"descending\x18\t \x01(\bR\n" +
+	"descending\x12\x14\n" +
+	"\x05limit\x18\n" +
+	" \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06cursor\x18\v \x01(\tR\x06cursor\"n\n" +
+	"\vHistoryItem\x12&\n" +
+	"\x05event\x18\x01 \x01(\v2\x10.ledger.v1.EventR\x05event\x127\n" +
+	"\x0ebalances_after\x18\x02 \x03(\v2\x10.ledger.v1.MoneyR\rbalancesAfter\"f\n" +
+	"\x15SearchHistoryResponse\x12,\n" +
+	"\x05items\x18\x01 \x03(\v2\x16.ledger.v1.HistoryItemR\x05items\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"W\n" +
+	"\x11TailEventsRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12#\n" +
+	"\rafter_version\x18\x02 \x01(\x03R\fafterVersion\"\xa1\x01\n" +
+	"\rEventMetadata\x12\x14\n" +
+	"\x05actor\x18\x01 \x01(\tR\x05actor\x12%\n" +
+	"\x0ecorrelation_id\x18\x02 \x01(\tR\rcorrelationId\x12!\n" +
+	"\fcausation_id\x18\x03 \x01(\tR\vcausationId\x12\x18\n" +
+	"\achannel\x18\x04 \x01(\tR\achannel\x12\x16\n" +
+	"\x06reason\x18\x05 \x01(\tR\x06reason\"\xde\x06\n" +
+	"\x05Event\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\tR\aeventId\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x02 \x01(\tR\taccountId\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\x03R\aversion\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\x128\n" +
+	"\ttimestamp\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x1a\n" +
+	"\bposition\x18\x06 \x01(\x03R\bposition\x124\n" +
+	"\bmetadata\x18\a \x01(\v2\x18.ledger.v1.EventMetadataR\bmetadata\x12D\n" +
+	"\x0faccount_created\x18\n" +
+	" \x01(\v2\x19.ledger.v1.AccountCreatedH\x00R\x0eaccountCreated\x12;\n" +
+	"\fdeposit_made\x18\v \x01(\v2\x16.ledger.v1.DepositMadeH\x00R\vdepositMade\x12D\n" +
+	"\x0fwithdrawal_made\x18\f \x01(\v2\x19.ledger.v1.WithdrawalMadeH\x00R\x0ewithdrawalMade\x12J\n" +
+	"\x11money_transferred\x18\r \x01(\v2\x1b.ledger.v1.MoneyTransferredH\x00R\x10moneyTransferred\x12M\n" +
+	"\x12currency_converted\x18\x0e \x01(\v2\x1c.ledger.v1.CurrencyConvertedH\x00R\x11currencyConverted\x12Z\n" +
+	"\x17account_details_updated\x18\x0f \x01(\v2 .ledger.v1.AccountDetailsUpdatedH\x00R\x15accountDetailsUpdated\x12A\n" +
+	"\x0eaccount_closed\x18\x10 \x01(\v2\x18.ledger.v1.AccountClosedH\x00R\raccountClosed\x12S\n" +
+	"\x14transaction_reversed\x18\x11 \x01(\v2\x1e.ledger.v1.TransactionReversedH\x00R\x13transactionReversedB\t\n" +
+	"\apayload\"M\n" +
+	"\x0eAccountCreated\x12;\n" +
+	"\x10initial_balances\x18\x01 \x03(\v2\x10.ledger.v1.MoneyR\x0finitialBalances\"7\n" +
+	"\vDepositMade\x12(\n" +
+	"\x06amount\x18\x01 \x01(\v2\x10.ledger.v1.MoneyR\x06amount\":\n" +
+	"\x0eWithdrawalMade\x12(\n" +
+	"\x06amount\x18\x01 \x01(\v2\x10.ledger.v1.MoneyR\x06amount\"\x8a\x02\n" +
+	"\x10MoneyTransferred\x12\x1f\n" +
+	"\vtransfer_id\x18\x01 \x01(\tR\n" +
+	"transferId\x12*\n" +
+	"\x11source_account_id\x18\x02 \x01(\tR\x0fsourceAccountId\x12*\n" +
+	"\x11target_account_id\x18\x03 \x01(\tR\x0ftargetAccountId\x12*\n" +
+	"\adebited\x18\x04 \x01(\v2\x10.ledger.v1.MoneyR\adebited\x12,\n" +
+	"\bcredited\x18\x05 \x01(\v2\x10.ledger.v1.MoneyR\bcredited\x12#\n" +
+	"\rexchange_rate\x18\x06 \x01(\tR\fexchangeRate\"\x80\x01\n" +
+	"\x11CurrencyConverted\x12$\n" +
+	"\x04from\x18\x01 \x01(\v2\x10.ledger.v1.MoneyR\x04from\x12 \n" +
+	"\x02to\x18\x02 \x01(\v2\x10.ledger.v1.MoneyR\x02to\x12#\n" +
+	"\rexchange_rate\x18\x03 \x01(\tR\fexchangeRate\"6\n" +
+	"\x15AccountDetailsUpdated\x12\x1d\n" +
+	"\n" +
+	"subject_id\x18\x01 \x01(\tR\tsubjectId\"\x0f\n" +
+	"\rAccountClosed\"\x90\x01\n" +
+	"\x13TransactionReversed\x12*\n" +
+	"\x11reversed_event_id\x18\x01 \x01(\tR\x0freversedEventId\x12#\n" +
+	"\rreversed_type\x18\x02 \x01(\tR\freversedType\x12(\n" +
+	"\x06amount\x18\x03 \x01(\v2\x10.ledger.v1.MoneyR\x06amount2\x95\x06\n" +
+	"\rLedgerService\x12L\n" +
+	"\rCreateAccount\x12\x1f.ledger.v1.CreateAccountRequest\x1a\x1a.ledger.v1.AccountBalances\x12Z\n" +
+	"\x14UpdateAccountDetails\x12&.ledger.v1.UpdateAccountDetailsRequest\x1a\x1a.ledger.v1.AccountBalances\x12R\n" +
+	"\rForgetSubject\x12\x1f.ledger.v1.ForgetSubjectRequest\x1a .ledger.v1.ForgetSubjectResponse\x12@\n" +
+	"\aDeposit\x12\x19.ledger.v1.DepositRequest\x1a\x1a.ledger.v1.AccountBalances\x12B\n" +
+	"\bWithdraw\x12\x1a.ledger.v1.WithdrawRequest\x1a\x1a.ledger.v1.AccountBalances\x12P\n" +
+	"\x0fConvertCurrency\x12!.ledger.v1.ConvertCurrencyRequest\x1a\x1a.ledger.v1.AccountBalances\x12R\n" +
+	"\rTransferMoney\x12\x1f.ledger.v1.TransferMoneyRequest\x1a .ledger.v1.TransferMoneyResponse\x12F\n" +
+	"\n" +
+	"GetBalance\x12\x1c.ledger.v1.GetBalanceRequest\x1a\x1a.ledger.v1.AccountBalances\x12R\n" +
+	"\rSearchHistory\x12\x1f.ledger.v1.SearchHistoryRequest\x1a .ledger.v1.SearchHistoryResponse\x12>\n" +
+	"\n" +
+	"TailEvents\x12\x1c.ledger.v1.TailEventsRequest\x1a\x10.ledger.v1.Event0\x01B#Z!financial-ledger/grpcapi/ledgerpbb\x06proto3"
+
+var (
+	file_ledger_proto_rawDescOnce sync.Once
+	file_ledger_proto_rawDescData []byte
+)
+
+func file_ledger_proto_rawDescGZIP() []byte {
+	file_ledger_proto_rawDescOnce.Do(func() {
+		file_ledger_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_ledger_proto_rawDesc), len(file_ledger_proto_rawDesc)))
+	})
+	return file_ledger_proto_rawDescData
+}
+
+var file_ledger_proto_msgTypes = make([]protoimpl.MessageInfo, 28)
+var file_ledger_proto_goTypes = []any{
+	(*Money)(nil),                       // 0: ledger.v1.Money
+	(*RequestMetadata)(nil),             // 1: ledger.v1.RequestMetadata
+	(*PersonalDetails)(nil),             // 2: ledger.v1.PersonalDetails
+	(*AccountBalances)(nil),             // 3: ledger.v1.AccountBalances
+	(*CreateAccountRequest)(nil),        // 4: ledger.v1.CreateAccountRequest
+	(*UpdateAccountDetailsRequest)(nil), // 5: ledger.v1.UpdateAccountDetailsRequest
+	(*ForgetSubjectRequest)(nil),        // 6: ledger.v1.ForgetSubjectRequest
+	(*ForgetSubjectResponse)(nil),       // 7: ledger.v1.ForgetSubjectResponse
+	(*DepositRequest)(nil),              // 8: ledger.v1.DepositRequest
+	(*WithdrawRequest)(nil),             // 9: ledger.v1.WithdrawRequest
+	(*ConvertCurrencyRequest)(nil),      // 10: ledger.v1.ConvertCurrencyRequest
+	(*TransferMoneyRequest)(nil),        // 11: ledger.v1.TransferMoneyRequest
+	(*TransferMoneyResponse)(nil),       // 12: ledger.v1.TransferMoneyResponse
+	(*GetBalanceRequest)(nil),           // 13: ledger.v1.GetBalanceRequest
+	(*SearchHistoryRequest)(nil),        // 14: ledger.v1.SearchHistoryRequest
+	(*HistoryItem)(nil),                 // 15: ledger.v1.HistoryItem
+	(*SearchHistoryResponse)(nil),       // 16: ledger.v1.SearchHistoryResponse
+	(*TailEventsRequest)(nil),           // 17: ledger.v1.TailEventsRequest
+	(*EventMetadata)(nil),               // 18: ledger.v1.EventMetadata
+	(*Event)(nil),                       // 19: ledger.v1.Event
+	(*AccountCreated)(nil),              // 20: ledger.v1.AccountCreated
+	(*DepositMade)(nil),                 // 21: ledger.v1.DepositMade
+	(*WithdrawalMade)(nil),              // 22: ledger.v1.WithdrawalMade
+	(*MoneyTransferred)(nil),            // 23: ledger.v1.MoneyTransferred
+	(*CurrencyConverted)(nil),           // 24: ledger.v1.CurrencyConverted
+	(*AccountDetailsUpdated)(nil),       // 25: ledger.v1.AccountDetailsUpdated
+	(*AccountClosed)(nil),               // 26: ledger.v1.AccountClosed
+	(*TransactionReversed)(nil),         // 27: ledger.v1.TransactionReversed
+	(*timestamppb.Timestamp)(nil),       // 28: google.protobuf.Timestamp
+}
+var file_ledger_proto_depIdxs = []int32{
+	0,  // 0: ledger.v1.AccountBalances.balances:type_name -> ledger.v1.Money
+	0,  // 1: ledger.v1.CreateAccountRequest.initial_balances:type_name -> ledger.v1.Money
+	2,  // 2: ledger.v1.CreateAccountRequest.details:type_name -> ledger.v1.PersonalDetails
+	1,  // 3: ledger.v1.CreateAccountRequest.metadata:type_name -> ledger.v1.RequestMetadata
+	2,  // 4: ledger.v1.UpdateAccountDetailsRequest.details:type_name -> ledger.v1.PersonalDetails
+	1,  // 5: ledger.v1.UpdateAccountDetailsRequest.metadata:type_name -> ledger.v1.RequestMetadata
+	1,  // 6: ledger.v1.ForgetSubjectRequest.metadata:type_name -> ledger.v1.RequestMetadata
+	28, // 7: ledger.v1.ForgetSubjectResponse.timestamp:type_name -> google.protobuf.Timestamp
+	0,  // 8: ledger.v1.DepositRequest.amount:type_name -> ledger.v1.Money
+	1,  // 9: ledger.v1.DepositRequest.metadata:type_name -> ledger.v1.RequestMetadata
+	0,  // 10: ledger.v1.WithdrawRequest.amount:type_name -> ledger.v1.Money
+	1,  // 11: ledger.v1.WithdrawRequest.metadata:type_name -> ledger.v1.RequestMetadata
+	0,  // 12: ledger.v1.ConvertCurrencyRequest.from:type_name -> ledger.v1.Money
+	1,  // 13: ledger.v1.ConvertCurrencyRequest.metadata:type_name -> ledger.v1.RequestMetadata
+	0,  // 14: ledger.v1.TransferMoneyRequest.amount:type_name -> ledger.v1.Money
+	1,  // 15: ledger.v1.TransferMoneyRequest.metadata:type_name -> ledger.v1.RequestMetadata
+	3,  // 16: ledger.v1.TransferMoneyResponse.source:type_name -> ledger.v1.AccountBalances
+	3,  // 17: ledger.v1.TransferMoneyResponse.target:type_name -> ledger.v1.AccountBalances
+	28, // 18: ledger.v1.GetBalanceRequest.as_of_time:type_name -> google.protobuf.Timestamp
+	28, // 19: ledger.v1.SearchHistoryRequest.from:type_name -> google.protobuf.Timestamp
+	28, // 20: ledger.v1.SearchHistoryRequest.to:type_name -> google.protobuf.Timestamp
+	19, // 21: ledger.v1.HistoryItem.event:type_name -> ledger.v1.Event
+	0,  // 22: ledger.v1.HistoryItem.balances_after:type_name -> ledger.v1.Money
+	15, // 23: ledger.v1.SearchHistoryResponse.items:type_name -> ledger.v1.HistoryItem
+	28, // 24: ledger.v1.Event.timestamp:type_name -> google.protobuf.Timestamp
+	18, // 25: ledger.v1.Event.metadata:type_name -> ledger.v1.EventMetadata
+	20, // 26: ledger.v1.Event.account_created:type_name -> ledger.v1.AccountCreated
+	21, // 27: ledger.v1.Event.deposit_made:type_name -> ledger.v1.DepositMade
+	22, // 28: ledger.v1.Event.withdrawal_made:type_name -> ledger.v1.WithdrawalMade
+	23, // 29: ledger.v1.Event.money_transferred:type_name -> ledger.v1.MoneyTransferred
+	24, // 30: ledger.v1.Event.currency_converted:type_name -> ledger.v1.CurrencyConverted
+	25, // 31: ledger.v1.Event.account_details_updated:type_name -> ledger.v1.AccountDetailsUpdated
+	26, // 32: ledger.v1.Event.account_closed:type_name -> ledger.v1.AccountClosed
+	27, // 33: ledger.v1.Event.transaction_reversed:type_name -> ledger.v1.TransactionReversed
+	0,  // 34: ledger.v1.AccountCreated.initial_balances:type_name -> ledger.v1.Money
+	0,  // 35: ledger.v1.DepositMade.amount:type_name -> ledger.v1.Money
+	0,  // 36: ledger.v1.WithdrawalMade.amount:type_name -> ledger.v1.Money
+	0,  // 37: ledger.v1.MoneyTransferred.debited:type_name -> ledger.v1.Money
+	0,  // 38: ledger.v1.MoneyTransferred.credited:type_name -> ledger.v1.Money
+	0,  // 39: ledger.v1.CurrencyConverted.from:type_name -> ledger.v1.Money
+	0,  // 40: ledger.v1.CurrencyConverted.to:type_name -> ledger.v1.Money
+	0,  // 41: ledger.v1.TransactionReversed.amount:type_name -> ledger.v1.Money
+	4,  // 42: ledger.v1.LedgerService.CreateAccount:input_type -> ledger.v1.CreateAccountRequest
+	5,  // 43: ledger.v1.LedgerService.UpdateAccountDetails:input_type -> ledger.v1.UpdateAccountDetailsRequest
+	6,  // 44: ledger.v1.LedgerService.ForgetSubject:input_type -> ledger.v1.ForgetSubjectRequest
+	8,  // 45: ledger.v1.LedgerService.Deposit:input_type -> ledger.v1.DepositRequest
+	9,  // 46: ledger.v1.LedgerService.Withdraw:input_type -> ledger.v1.WithdrawRequest
+	10, // 47: ledger.v1.LedgerService.ConvertCurrency:input_type -> ledger.v1.ConvertCurrencyRequest
+	11, // 48: ledger.v1.LedgerService.TransferMoney:input_type -> ledger.v1.TransferMoneyRequest
+	13, // 49: ledger.v1.LedgerService.GetBalance:input_type -> ledger.v1.GetBalanceRequest
+	14, // 50: ledger.v1.LedgerService.SearchHistory:input_type -> ledger.v1.SearchHistoryRequest
+	17, // 51: ledger.v1.LedgerService.TailEvents:input_type -> ledger.v1.TailEventsRequest
+	3,  // 52: ledger.v1.LedgerService.CreateAccount:output_type -> ledger.v1.AccountBalances
+	3,  // 53: ledger.v1.LedgerService.UpdateAccountDetails:output_type -> ledger.v1.AccountBalances
+	7,  // 54: ledger.v1.LedgerService.ForgetSubject:output_type -> ledger.v1.ForgetSubjectResponse
+	3,  // 55: ledger.v1.LedgerService.Deposit:output_type -> ledger.v1.AccountBalances
+	3,  // 56: ledger.v1.LedgerService.Withdraw:output_type -> ledger.v1.AccountBalances
+	3,  // 57: ledger.v1.LedgerService.ConvertCurrency:output_type -> ledger.v1.AccountBalances
+	12, // 58: ledger.v1.LedgerService.TransferMoney:output_type -> ledger.v1.TransferMoneyResponse
+	3,  // 59: ledger.v1.LedgerService.GetBalance:output_type -> ledger.v1.AccountBalances
+	16, // 60: ledger.v1.LedgerService.SearchHistory:output_type -> ledger.v1.SearchHistoryResponse
+	19, // 61: ledger.v1.LedgerService.TailEvents:output_type -> ledger.v1.Event
+	52, // [52:62] is the sub-list for method output_type
+	42, // [42:52] is the sub-list for method input_type
+	42, // [42:42] is the sub-list for extension type_name
+	42, // [42:42] is the sub-list for extension extendee
+	0,  // [0:42] is the sub-list for field type_name
+}
+
+func init() { file_ledger_proto_init() }
+func file_ledger_proto_init() {
+	if File_ledger_proto != nil {
+		return
+	}
+	file_ledger_proto_msgTypes[13].OneofWrappers = []any{
+		(*GetBalanceRequest_AsOfVersion)(nil),
+		(*GetBalanceRequest_AsOfTime)(nil),
+	}
+	file_ledger_proto_msgTypes[19].OneofWrappers = []any{
+		(*Event_AccountCreated)(nil),
+		(*Event_DepositMade)(nil),
+		(*Event_WithdrawalMade)(nil),
+		(*Event_MoneyTransferred)(nil),
+		(*Event_CurrencyConverted)(nil),
+		(*Event_AccountDetailsUpdated)(nil),
+		(*Event_AccountClosed)(nil),
+		(*Event_TransactionReversed)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ledger_proto_rawDesc), len(file_ledger_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   28,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_ledger_proto_goTypes,
+		DependencyIndexes: file_ledger_proto_depIdxs,
+		MessageInfos:      file_ledger_proto_msgTypes,
+	}.Build()
+	File_ledger_proto = out.File
+	file_ledger_proto_goTypes = nil
+	file_ledger_proto_depIdxs = nil
+}
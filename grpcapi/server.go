@@ -0,0 +1,286 @@
+// Package grpcapi serves the account service over gRPC, using the API defined
+// in ledgerpb/ledger.proto. Clients should use the ledgerclient package.
+//
+// Callers authenticate with an API key or a JWT in the "authorization"
+// metadata, as "Bearer <credential>". The interceptors from ServerOptions
+// verify it; a server built without them rejects every call.
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"financial-ledger/app"
+	"financial-ledger/auth"
+	"financial-ledger/events"
+	"financial-ledger/grpcapi/internal/wire"
+	"financial-ledger/grpcapi/ledgerpb"
+)
+
+// Server implements ledgerpb.LedgerServiceServer on top of an AccountService,
+// reached through an auth.Guard.
+type Server struct {
+	ledgerpb.UnimplementedLedgerServiceServer
+	svc *auth.Guard
+}
+
+func NewServer(svc *auth.Guard) *Server {
+	return &Server{svc: svc}
+}
+
+// Register adds the ledger service to a gRPC server.
+func (s *Server) Register(gs grpc.ServiceRegistrar) {
+	ledgerpb.RegisterLedgerServiceServer(gs, s)
+}
+
+// --- Commands ---
+
+func (s *Server) CreateAccount(ctx context.Context, req *ledgerpb.CreateAccountRequest) (*ledgerpb.AccountBalances, error) {
+	balances, err := wire.ParseBalances("initial_balances", req.GetInitialBalances())
+	if err != nil {
+		return nil, toStatus("CreateAccount", err)
+	}
+	cmd := app.CreateAccountCommand{
+		AccountID:       req.GetAccountId(),
+		InitialBalances: balances,
+		SubjectID:       req.GetSubjectId(),
+		IdempotencyKey:  req.GetIdempotencyKey(),
+		Metadata:        requestMetadata(req.GetMetadata()),
+	}
+	if d := req.GetDetails(); d != nil {
+		cmd.Details = &app.PersonalDetails{OwnerName: d.GetOwnerName(), Address: d.GetAddress(), Notes: d.GetNotes()}
+	}
+	accountID, err := s.svc.CreateAccount(ctx, cmd)
+	if err != nil {
+		return nil, toStatus("CreateAccount", err)
+	}
+	return s.balancesOf(ctx, "CreateAccount", accountID)
+}
+
+func (s *Server) UpdateAccountDetails(ctx context.Context, req *ledgerpb.UpdateAccountDetailsRequest) (*ledgerpb.AccountBalances, error) {
+	d := req.GetDetails()
+	err := s.svc.UpdateAccountDetails(ctx, app.UpdateAccountDetailsCommand{
+		AccountID:      req.GetAccountId(),
+		SubjectID:      req.GetSubjectId(),
+		Details:        app.PersonalDetails{OwnerName: d.GetOwnerName(), Address: d.GetAddress(), Notes: d.GetNotes()},
+		IdempotencyKey: req.GetIdempotencyKey(),
+		Metadata:       requestMetadata(req.GetMetadata()),
+	})
+	if err != nil {
+		return nil, toStatus("UpdateAccountDetails", err)
+	}
+	return s.balancesOf(ctx, "UpdateAccountDetails", req.GetAccountId())
+}
+
+func (s *Server) ForgetSubject(ctx context.Context, req *ledgerpb.ForgetSubjectRequest) (*ledgerpb.ForgetSubjectResponse, error) {
+	record, err := s.svc.ForgetSubject(ctx, app.ForgetSubjectCommand{SubjectID: req.GetSubjectId(), Metadata: requestMetadata(req.GetMetadata())})
+	if err != nil {
+		return nil, toStatus("ForgetSubject", err)
+	}
+	return &ledgerpb.ForgetSubjectResponse{
+		SubjectId:     record.SubjectID,
+		KeysDestroyed: int32(record.KeysDestroyed),
+		Timestamp:     timestamppb.New(record.Timestamp),
+	}, nil
+}
+
+func (s *Server) Deposit(ctx context.Context, req *ledgerpb.DepositRequest) (*ledgerpb.AccountBalances, error) {
+	amount, currency, err := wire.ParseMoney("amount", req.GetAmount())
+	if err != nil {
+		return nil, toStatus("Deposit", err)
+	}
+	err = s.svc.Deposit(ctx, app.DepositMoneyCommand{
+		AccountID:       req.GetAccountId(),
+		Amount:          amount,
+		Currency:        currency,
+		ExpectedVersion: int(req.GetExpectedVersion()),
+		IdempotencyKey:  req.GetIdempotencyKey(),
+		Metadata:        requestMetadata(req.GetMetadata()),
+	})
+	if err != nil {
+		return nil, toStatus("Deposit", err)
+	}
+	return s.balancesOf(ctx, "Deposit", req.GetAccountId())
+}
+
+func (s *Server) Withdraw(ctx context.Context, req *ledgerpb.WithdrawRequest) (*ledgerpb.AccountBalances, error) {
+	amount, currency, err := wire.ParseMoney("amount", req.GetAmount())
+	if err != nil {
+		return nil, toStatus("Withdraw", err)
+	}
+	err = s.svc.Withdraw(ctx, app.WithdrawMoneyCommand{
+		AccountID:       req.GetAccountId(),
+		Amount:          amount,
+		Currency:        currency,
+		ExpectedVersion: int(req.GetExpectedVersion()),
+		IdempotencyKey:  req.GetIdempotencyKey(),
+		Metadata:        requestMetadata(req.GetMetadata()),
+	})
+	if err != nil {
+		return nil, toStatus("Withdraw", err)
+	}
+	return s.balancesOf(ctx, "Withdraw", req.GetAccountId())
+}
+
+func (s *Server) ConvertCurrency(ctx context.Context, req *ledgerpb.ConvertCurrencyRequest) (*ledgerpb.AccountBalances, error) {
+	amount, currency, err := wire.ParseMoney("from", req.GetFrom())
+	if err != nil {
+		return nil, toStatus("ConvertCurrency", err)
+	}
+	err = s.svc.ConvertCurrency(ctx, app.ConvertCurrencyCommand{
+		AccountID:       req.GetAccountId(),
+		FromAmount:      amount,
+		FromCurrency:    currency,
+		ToCurrency:      wire.Currency(req.GetToCurrency()),
+		ExpectedVersion: int(req.GetExpectedVersion()),
+		IdempotencyKey:  req.GetIdempotencyKey(),
+		Metadata:        requestMetadata(req.GetMetadata()),
+	})
+	if err != nil {
+		return nil, toStatus("ConvertCurrency", err)
+	}
+	return s.balancesOf(ctx, "ConvertCurrency", req.GetAccountId())
+}
+
+func (s *Server) TransferMoney(ctx context.Context, req *ledgerpb.TransferMoneyRequest) (*ledgerpb.TransferMoneyResponse, error) {
+	amount, currency, err := wire.ParseMoney("amount", req.GetAmount())
+	if err != nil {
+		return nil, toStatus("TransferMoney", err)
+	}
+	err = s.svc.TransferMoney(ctx, app.TransferMoneyCommand{
+		SourceAccountID: req.GetSourceAccountId(),
+		TargetAccountID: req.GetTargetAccountId(),
+		Amount:          amount,
+		Currency:        currency,
+		ExpectedVersion: int(req.GetExpectedVersion()),
+		IdempotencyKey:  req.GetIdempotencyKey(),
+		Metadata:        requestMetadata(req.GetMetadata()),
+	})
+	if err != nil {
+		return nil, toStatus("TransferMoney", err)
+	}
+	source, err := s.balancesOf(ctx, "TransferMoney", req.GetSourceAccountId())
+	if err != nil {
+		return nil, err
+	}
+	resp := &ledgerpb.TransferMoneyResponse{Source: source}
+	// Paying into an account does not entitle the caller to read it.
+	if p, _ := auth.PrincipalFrom(ctx); p.Owns(req.GetTargetAccountId()) {
+		if resp.Target, err = s.balancesOf(ctx, "TransferMoney", req.GetTargetAccountId()); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// --- Queries ---
+
+func (s *Server) GetBalance(ctx context.Context, req *ledgerpb.GetBalanceRequest) (*ledgerpb.AccountBalances, error) {
+	query := app.GetBalanceQuery{AccountID: req.GetAccountId(), AsOfVersion: int(req.GetAsOfVersion())}
+	if req.GetCurrency() != "" {
+		currency := wire.Currency(req.GetCurrency())
+		query.Currency = &currency
+	}
+	if t := req.GetAsOfTime(); t != nil {
+		at := t.AsTime()
+		query.AsOf = &at
+	}
+	result, err := s.svc.GetBalances(ctx, query)
+	if err != nil {
+		return nil, toStatus("GetBalance", err)
+	}
+	return wire.AccountBalances(result), nil
+}
+
+func (s *Server) SearchHistory(ctx context.Context, req *ledgerpb.SearchHistoryRequest) (*ledgerpb.SearchHistoryResponse, error) {
+	query := app.SearchHistoryQuery{
+		AccountID:    req.GetAccountId(),
+		Counterparty: req.GetCounterparty(),
+		Descending:   req.GetDescending(),
+		Limit:        int(req.GetLimit()),
+		Cursor:       req.GetCursor(),
+	}
+	for _, t := range req.GetTypes() {
+		query.Types = append(query.Types, events.EventType(t))
+	}
+	if req.GetCurrency() != "" {
+		currency := wire.Currency(req.GetCurrency())
+		query.Currency = &currency
+	}
+	if req.GetFrom() != nil {
+		from := req.GetFrom().AsTime()
+		query.From = &from
+	}
+	if req.GetTo() != nil {
+		to := req.GetTo().AsTime()
+		query.To = &to
+	}
+	var err error
+	if query.MinAmount, err = optionalDecimal("min_amount", req.GetMinAmount()); err != nil {
+		return nil, toStatus("SearchHistory", err)
+	}
+	if query.MaxAmount, err = optionalDecimal("max_amount", req.GetMaxAmount()); err != nil {
+		return nil, toStatus("SearchHistory", err)
+	}
+
+	page, err := s.svc.SearchHistory(ctx, query)
+	if err != nil {
+		return nil, toStatus("SearchHistory", err)
+	}
+	resp := &ledgerpb.SearchHistoryResponse{NextCursor: page.NextCursor}
+	for _, item := range page.Items {
+		event, err := wire.Event(item.Event)
+		if err != nil {
+			return nil, toStatus("SearchHistory", err)
+		}
+		resp.Items = append(resp.Items, &ledgerpb.HistoryItem{Event: event, BalancesAfter: wire.Balances(item.BalancesAfter)})
+	}
+	return resp, nil
+}
+
+func (s *Server) TailEvents(req *ledgerpb.TailEventsRequest, stream grpc.ServerStreamingServer[ledgerpb.Event]) error {
+	err := s.svc.TailEvents(stream.Context(), req.GetAccountId(), int(req.GetAfterVersion()), func(e events.Event) error {
+		event, err := wire.Event(e)
+		if err != nil {
+			return err
+		}
+		return stream.Send(event)
+	})
+	return toStatus("TailEvents", err)
+}
+
+// --- Helpers ---
+
+// balancesOf reads an account's balances after a command.
+func (s *Server) balancesOf(ctx context.Context, method, accountID string) (*ledgerpb.AccountBalances, error) {
+	result, err := s.svc.GetBalances(ctx, app.GetBalanceQuery{AccountID: accountID})
+	if err != nil {
+		return nil, toStatus(method, err)
+	}
+	return wire.AccountBalances(result), nil
+}
+
+func optionalDecimal(field, raw string) (*decimal.Decimal, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	d, err := wire.ParseDecimal(field, raw)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// requestMetadata builds the audit metadata recorded on events produced by a
+// gRPC call. The Guard replaces the actor with the authenticated principal's ID.
+func requestMetadata(md *ledgerpb.RequestMetadata) events.Metadata {
+	return events.Metadata{
+		Actor:         md.GetActor(),
+		CorrelationID: md.GetCorrelationId(),
+		Reason:        md.GetReason(),
+		Channel:       "grpc",
+	}
+}
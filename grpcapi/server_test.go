@@ -0,0 +1,389 @@
+package grpcapi_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"financial-ledger/app"
+	"financial-ledger/auth"
+	"financial-ledger/domain"
+	"financial-ledger/events"
+	"financial-ledger/grpcapi"
+	"financial-ledger/grpcapi/ledgerclient"
+	"financial-ledger/grpcapi/ledgerpb"
+	"financial-ledger/shared"
+	"financial-ledger/store"
+)
+
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+// startServer serves a fresh ledger over an in-memory listener and returns a
+// connection to it.
+func startServer(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+	svc := app.NewAccountService(store.NewInMemoryEventStore(), store.NewInMemorySnapshotStore(), app.WithTailPollInterval(time.Millisecond))
+	return serveGuard(t, auth.NewGuard(svc, auth.AllowAll(auth.Principal{Roles: []auth.Role{auth.RoleAdmin}})))
+}
+
+// serveGuard serves guard over an in-memory listener and returns a connection
+// to it made with opts.
+func serveGuard(t *testing.T, guard *auth.Guard, opts ...grpc.DialOption) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	server := grpcapi.NewServer(guard)
+	gs := grpc.NewServer(server.ServerOptions()...)
+	server.Register(gs)
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+
+	opts = append(opts,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.NewClient("passthrough:///bufnet", opts...)
+	if err != nil {
+		t.Fatalf("failed to connect to bufconn server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestServer_CommandsAndQueries(t *testing.T) {
+	ctx := context.Background()
+	client := ledgerclient.New(startServer(t))
+
+	created, err := client.CreateAccount(ctx, app.CreateAccountCommand{
+		AccountID:       "acc-1",
+		InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: dec("100")},
+		Details:         &app.PersonalDetails{OwnerName: "Ada"},
+		Metadata:        events.Metadata{Actor: "teller-7"},
+	})
+	if err != nil {
+		t.Fatalf("CreateAccount failed: %v", err)
+	}
+	if created.Version != 1 || !created.Balances[shared.USD].Equal(dec("100")) {
+		t.Fatalf("unexpected balances after creation: %+v", created)
+	}
+	_, _ = client.CreateAccount(ctx, app.CreateAccountCommand{AccountID: "acc-2"})
+
+	t.Run("Deposit", func(t *testing.T) {
+		got, err := client.Deposit(ctx, app.DepositMoneyCommand{AccountID: "acc-1", Amount: dec("10.5"), Currency: shared.USD, ExpectedVersion: 1})
+		if err != nil {
+			t.Fatalf("Deposit failed: %v", err)
+		}
+		if got.Version != 2 || !got.Balances[shared.USD].Equal(dec("110.5")) {
+			t.Errorf("unexpected balances after deposit: %+v", got)
+		}
+	})
+
+	t.Run("WithdrawAndConvert", func(t *testing.T) {
+		if _, err := client.Withdraw(ctx, app.WithdrawMoneyCommand{AccountID: "acc-1", Amount: dec("0.5"), Currency: shared.USD}); err != nil {
+			t.Fatalf("Withdraw failed: %v", err)
+		}
+		got, err := client.ConvertCurrency(ctx, app.ConvertCurrencyCommand{AccountID: "acc-1", FromAmount: dec("50"), FromCurrency: shared.USD, ToCurrency: shared.EUR})
+		if err != nil {
+			t.Fatalf("ConvertCurrency failed: %v", err)
+		}
+		if !got.Balances[shared.USD].Equal(dec("60")) || !got.Balances[shared.EUR].Equal(dec("46")) {
+			t.Errorf("unexpected balances after conversion: %+v", got)
+		}
+	})
+
+	t.Run("Transfer", func(t *testing.T) {
+		source, target, err := client.TransferMoney(ctx, app.TransferMoneyCommand{SourceAccountID: "acc-1", TargetAccountID: "acc-2", Amount: dec("20"), Currency: shared.USD})
+		if err != nil {
+			t.Fatalf("TransferMoney failed: %v", err)
+		}
+		if !source.Balances[shared.USD].Equal(dec("40")) || !target.Balances[shared.USD].Equal(dec("20")) {
+			t.Errorf("unexpected balances after transfer: source %+v, target %+v", source, target)
+		}
+	})
+
+	t.Run("BalanceAsOf", func(t *testing.T) {
+		usd := shared.USD
+		got, err := client.GetBalance(ctx, app.GetBalanceQuery{AccountID: "acc-1", Currency: &usd, AsOfVersion: 2})
+		if err != nil {
+			t.Fatalf("GetBalance failed: %v", err)
+		}
+		if got.Version != 2 || len(got.Balances) != 1 || !got.Balances[shared.USD].Equal(dec("110.5")) {
+			t.Errorf("unexpected balances as of version 2: %+v", got)
+		}
+	})
+
+	t.Run("History", func(t *testing.T) {
+		page, err := client.SearchHistory(ctx, app.SearchHistoryQuery{AccountID: "acc-1", Descending: true, Limit: 2})
+		if err != nil {
+			t.Fatalf("SearchHistory failed: %v", err)
+		}
+		if len(page.Items) != 2 || page.NextCursor == "" {
+			t.Fatalf("expected a first page of 2 with a cursor, got %d items", len(page.Items))
+		}
+		transfer, ok := page.Items[0].Event.(events.MoneyTransferredEvent)
+		if !ok || transfer.TargetAccountID != "acc-2" || !transfer.DebitedAmount.Equal(dec("20")) {
+			t.Errorf("expected the transfer first, got %#v", page.Items[0].Event)
+		}
+		if !page.Items[0].BalancesAfter[shared.USD].Equal(dec("40")) {
+			t.Errorf("unexpected running balance: %v", page.Items[0].BalancesAfter)
+		}
+
+		rest, err := client.SearchHistory(ctx, app.SearchHistoryQuery{AccountID: "acc-1", Descending: true, Cursor: page.NextCursor})
+		if err != nil {
+			t.Fatalf("SearchHistory with cursor failed: %v", err)
+		}
+		if len(rest.Items) != 3 {
+			t.Fatalf("expected 3 remaining events, got %d", len(rest.Items))
+		}
+		created, ok := rest.Items[2].Event.(events.AccountCreatedEvent)
+		if !ok || created.GetBase().Metadata.Actor != "teller-7" || created.GetBase().Metadata.Channel != "grpc" {
+			t.Errorf("expected the creation event with gRPC metadata, got %#v", rest.Items[2].Event)
+		}
+	})
+}
+
+func TestServer_TailEvents(t *testing.T) {
+	client := ledgerclient.New(startServer(t))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, _ = client.CreateAccount(ctx, app.CreateAccountCommand{AccountID: "acc-tail"})
+	_, _ = client.Deposit(ctx, app.DepositMoneyCommand{AccountID: "acc-tail", Amount: dec("5"), Currency: shared.USD})
+
+	received := make(chan events.Event, 10)
+	tailCtx, stopTail := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- client.TailEvents(tailCtx, "acc-tail", 1, func(e events.Event) error {
+			received <- e
+			return nil
+		})
+	}()
+
+	if e := <-received; e.GetBase().Version != 2 || e.GetBase().Type != events.DepositMadeType {
+		t.Fatalf("expected the backlog deposit at version 2, got %s at %d", e.GetBase().Type, e.GetBase().Version)
+	}
+	_, _ = client.Withdraw(ctx, app.WithdrawMoneyCommand{AccountID: "acc-tail", Amount: dec("2"), Currency: shared.USD})
+	select {
+	case e := <-received:
+		withdrawal, ok := e.(events.WithdrawalMadeEvent)
+		if !ok || withdrawal.Version != 3 || !withdrawal.Amount.Equal(dec("2")) {
+			t.Errorf("expected the live withdrawal, got %#v", e)
+		}
+	case <-ctx.Done():
+		t.Fatal("live event was not streamed")
+	}
+
+	stopTail()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled after cancelling the tail, got %v", err)
+	}
+
+	t.Run("UnknownAccount", func(t *testing.T) {
+		err := client.TailEvents(ctx, "missing", 0, func(events.Event) error { return nil })
+		if !errors.Is(err, domain.ErrAccountNotFound) {
+			t.Errorf("expected ErrAccountNotFound, got %v", err)
+		}
+	})
+}
+
+func TestServer_ErrorDetails(t *testing.T) {
+	ctx := context.Background()
+	conn := startServer(t)
+	client := ledgerclient.New(conn)
+	_, _ = client.CreateAccount(ctx, app.CreateAccountCommand{AccountID: "acc-1", InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: dec("10")}})
+
+	tests := []struct {
+		name     string
+		call     func() error
+		code     codes.Code
+		reason   string
+		sentinel error
+	}{
+		{"InsufficientFunds", func() error {
+			_, err := client.Withdraw(ctx, app.WithdrawMoneyCommand{AccountID: "acc-1", Amount: dec("11"), Currency: shared.USD})
+			return err
+		}, codes.FailedPrecondition, "INSUFFICIENT_FUNDS", domain.ErrInsufficientFunds},
+		{"AccountNotFound", func() error {
+			_, err := client.GetBalance(ctx, app.GetBalanceQuery{AccountID: "missing"})
+			return err
+		}, codes.NotFound, "ACCOUNT_NOT_FOUND", domain.ErrAccountNotFound},
+		{"AccountExists", func() error {
+			_, err := client.CreateAccount(ctx, app.CreateAccountCommand{AccountID: "acc-1"})
+			return err
+		}, codes.AlreadyExists, "ACCOUNT_EXISTS", domain.ErrAccountExists},
+		{"VersionMismatch", func() error {
+			_, err := client.Deposit(ctx, app.DepositMoneyCommand{AccountID: "acc-1", Amount: dec("1"), Currency: shared.USD, ExpectedVersion: 9})
+			return err
+		}, codes.FailedPrecondition, "VERSION_MISMATCH", app.ErrVersionMismatch},
+		{"InvalidCursor", func() error {
+			_, err := client.SearchHistory(ctx, app.SearchHistoryQuery{AccountID: "acc-1", Cursor: "bogus"})
+			return err
+		}, codes.InvalidArgument, "INVALID_CURSOR", app.ErrInvalidCursor},
+		{"AsOfOutOfRange", func() error {
+			_, err := client.GetBalance(ctx, app.GetBalanceQuery{AccountID: "acc-1", AsOfVersion: 50})
+			return err
+		}, codes.OutOfRange, "AS_OF_OUT_OF_RANGE", app.ErrAsOfOutOfRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			var e *ledgerclient.Error
+			if !errors.As(err, &e) {
+				t.Fatalf("expected a *ledgerclient.Error, got %T: %v", err, err)
+			}
+			if e.Code != tt.code || e.Reason != tt.reason {
+				t.Errorf("expected %s/%s, got %s/%s", tt.code, tt.reason, e.Code, e.Reason)
+			}
+			if !errors.Is(err, tt.sentinel) {
+				t.Errorf("expected the error to unwrap to %v", tt.sentinel)
+			}
+		})
+	}
+
+	t.Run("RuleViolation", func(t *testing.T) {
+		_, err := client.Deposit(ctx, app.DepositMoneyCommand{AccountID: "acc-1", Amount: dec("-1"), Currency: shared.USD})
+		var domainErr *domain.DomainError
+		if !errors.As(err, &domainErr) {
+			t.Fatalf("expected a DomainError for a negative deposit, got %v", err)
+		}
+	})
+
+	t.Run("BadRequestFieldViolation", func(t *testing.T) {
+		rpc := ledgerpb.NewLedgerServiceClient(conn)
+		_, err := rpc.Deposit(ctx, &ledgerpb.DepositRequest{AccountId: "acc-1", Amount: &ledgerpb.Money{Amount: "ten", Currency: "USD"}})
+		st := status.Convert(err)
+		if st.Code() != codes.InvalidArgument {
+			t.Fatalf("expected InvalidArgument, got %s", st.Code())
+		}
+		var violation *errdetails.BadRequest_FieldViolation
+		for _, d := range st.Details() {
+			if br, ok := d.(*errdetails.BadRequest); ok && len(br.GetFieldViolations()) > 0 {
+				violation = br.GetFieldViolations()[0]
+			}
+		}
+		if violation == nil || violation.GetField() != "amount.amount" {
+			t.Errorf("expected a field violation on amount.amount, got %v", st.Details())
+		}
+	})
+
+	t.Run("VersionMismatchPreconditionFailure", func(t *testing.T) {
+		rpc := ledgerpb.NewLedgerServiceClient(conn)
+		_, err := rpc.Deposit(ctx, &ledgerpb.DepositRequest{AccountId: "acc-1", Amount: &ledgerpb.Money{Amount: "1", Currency: "USD"}, ExpectedVersion: 9})
+		found := false
+		for _, d := range status.Convert(err).Details() {
+			if pf, ok := d.(*errdetails.PreconditionFailure); ok && len(pf.GetViolations()) == 1 && pf.GetViolations()[0].GetType() == "VERSION" {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected a PreconditionFailure detail, got %v", status.Convert(err).Details())
+		}
+	})
+}
+
+func TestServer_LimitExceeded(t *testing.T) {
+	ctx := context.Background()
+	limits := app.LimitPolicy{Accounts: map[string]domain.Limits{
+		"acc-1": {Withdrawal: map[shared.Currency]domain.AmountLimits{shared.USD: {PerTransaction: dec("5")}}},
+	}}
+	svc := app.NewAccountService(store.NewInMemoryEventStore(), store.NewInMemorySnapshotStore(), app.WithLimitPolicy(limits))
+	conn := serveGuard(t, auth.NewGuard(svc, auth.AllowAll(auth.Principal{Roles: []auth.Role{auth.RoleAdmin}})))
+	client := ledgerclient.New(conn)
+	_, _ = client.CreateAccount(ctx, app.CreateAccountCommand{AccountID: "acc-1", InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: dec("10")}})
+
+	_, err := client.Withdraw(ctx, app.WithdrawMoneyCommand{AccountID: "acc-1", Amount: dec("6"), Currency: shared.USD})
+	var e *ledgerclient.Error
+	if !errors.As(err, &e) || e.Code != codes.ResourceExhausted || e.Reason != "LIMIT_EXCEEDED" {
+		t.Fatalf("expected ResourceExhausted/LIMIT_EXCEEDED, got %v", err)
+	}
+	if !errors.Is(err, domain.ErrLimitExceeded) {
+		t.Errorf("expected the error to unwrap to %v", domain.ErrLimitExceeded)
+	}
+
+	rpc := ledgerpb.NewLedgerServiceClient(conn)
+	_, err = rpc.Withdraw(ctx, &ledgerpb.WithdrawRequest{AccountId: "acc-1", Amount: &ledgerpb.Money{Amount: "6", Currency: "USD"}})
+	found := false
+	for _, d := range status.Convert(err).Details() {
+		if qf, ok := d.(*errdetails.QuotaFailure); ok && len(qf.GetViolations()) == 1 && qf.GetViolations()[0].GetSubject() == "account:acc-1" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a QuotaFailure detail, got %v", status.Convert(err).Details())
+	}
+}
+
+func TestServer_Authentication(t *testing.T) {
+	ctx := context.Background()
+	svc := app.NewAccountService(store.NewInMemoryEventStore(), store.NewInMemorySnapshotStore(), app.WithTailPollInterval(time.Millisecond))
+	secret := []byte("test-secret")
+	authn, err := auth.NewAuthenticator(nil, &auth.JWTVerifier{HMACSecret: secret})
+	if err != nil {
+		t.Fatalf("failed to build authenticator: %v", err)
+	}
+	guard := auth.NewGuard(svc, authn)
+	token := func(sub string, roles []string, accounts ...string) string {
+		tok, err := auth.SignHS256(secret, auth.Claims{Subject: sub, Roles: roles, Accounts: accounts, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		return tok
+	}
+	admin := ledgerclient.New(serveGuard(t, guard, ledgerclient.WithCredential(token("ops", []string{"admin"}))))
+	for _, id := range []string{"acc-1", "acc-2"} {
+		if _, err := admin.CreateAccount(ctx, app.CreateAccountCommand{AccountID: id, InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: dec("10")}}); err != nil {
+			t.Fatalf("CreateAccount %s failed: %v", id, err)
+		}
+	}
+
+	t.Run("NoCredential", func(t *testing.T) {
+		client := ledgerclient.New(serveGuard(t, guard))
+		_, err := client.GetBalance(ctx, app.GetBalanceQuery{AccountID: "acc-1"})
+		if status.Code(err) != codes.Unauthenticated || !errors.Is(err, auth.ErrUnauthenticated) {
+			t.Errorf("expected Unauthenticated, got %v", err)
+		}
+	})
+
+	t.Run("ExpiredToken", func(t *testing.T) {
+		expired, _ := auth.SignHS256(secret, auth.Claims{Subject: "ops", Roles: []string{"admin"}, ExpiresAt: time.Now().Add(-time.Hour).Unix()})
+		client := ledgerclient.New(serveGuard(t, guard, ledgerclient.WithCredential(expired)))
+		if _, err := client.GetBalance(ctx, app.GetBalanceQuery{AccountID: "acc-1"}); !errors.Is(err, auth.ErrUnauthenticated) {
+			t.Errorf("expected ErrUnauthenticated, got %v", err)
+		}
+	})
+
+	t.Run("ScopedTeller", func(t *testing.T) {
+		client := ledgerclient.New(serveGuard(t, guard, ledgerclient.WithCredential(token("teller-1", []string{"teller"}, "acc-1"))))
+		source, target, err := client.TransferMoney(ctx, app.TransferMoneyCommand{SourceAccountID: "acc-1", TargetAccountID: "acc-2", Amount: dec("3"), Currency: shared.USD})
+		if err != nil {
+			t.Fatalf("TransferMoney from an owned account failed: %v", err)
+		}
+		if !source.Balances[shared.USD].Equal(dec("7")) || target != nil {
+			t.Errorf("expected source balances only, got source %+v, target %+v", source, target)
+		}
+
+		_, err = client.Withdraw(ctx, app.WithdrawMoneyCommand{AccountID: "acc-2", Amount: dec("1"), Currency: shared.USD})
+		if status.Code(err) != codes.PermissionDenied || !errors.Is(err, auth.ErrPermissionDenied) {
+			t.Errorf("expected PermissionDenied withdrawing from another account, got %v", err)
+		}
+	})
+
+	t.Run("StreamsAreAuthorized", func(t *testing.T) {
+		client := ledgerclient.New(serveGuard(t, guard, ledgerclient.WithCredential(token("viewer", []string{"readonly"}, "acc-1"))))
+		err := client.TailEvents(ctx, "acc-2", 0, func(events.Event) error { return nil })
+		if !errors.Is(err, auth.ErrPermissionDenied) {
+			t.Errorf("expected ErrPermissionDenied tailing another account, got %v", err)
+		}
+	})
+}
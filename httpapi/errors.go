@@ -0,0 +1,96 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"financial-ledger/app"
+	"financial-ledger/auth"
+	"financial-ledger/domain"
+	"financial-ledger/store"
+)
+
+// errorBody is the JSON body of every error response.
+type errorBody struct {
+	Error errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// requestError is a problem with the request itself, reported as 400.
+type requestError struct {
+	msg string
+}
+
+func (e *requestError) Error() string {
+	return e.msg
+}
+
+func badRequest(format string, args ...any) error {
+	return &requestError{msg: fmt.Sprintf(format, args...)}
+}
+
+// errorStatus maps an error from the service to an HTTP status and a stable
+// machine-readable code. Sentinels are checked before the generic DomainError
+// because several of them are DomainErrors themselves.
+func errorStatus(err error) (int, string) {
+	var reqErr *requestError
+	var domainErr *domain.DomainError
+	switch {
+	case errors.As(err, &reqErr):
+		return http.StatusBadRequest, "bad_request"
+	case errors.Is(err, app.ErrApprovalRequired):
+		return http.StatusAccepted, "approval_required"
+	case errors.Is(err, auth.ErrUnauthenticated):
+		return http.StatusUnauthorized, "unauthenticated"
+	case errors.Is(err, auth.ErrPermissionDenied):
+		return http.StatusForbidden, "forbidden"
+	case errors.Is(err, app.ErrVersionMismatch):
+		return http.StatusPreconditionFailed, "version_mismatch"
+	case errors.Is(err, store.ErrOptimisticLock):
+		return http.StatusConflict, "concurrent_update"
+	case errors.Is(err, domain.ErrAccountNotFound):
+		return http.StatusNotFound, "account_not_found"
+	case errors.Is(err, domain.ErrCustomerNotFound):
+		return http.StatusNotFound, "customer_not_found"
+	case errors.Is(err, domain.ErrAccountExists):
+		return http.StatusConflict, "account_exists"
+	case errors.Is(err, domain.ErrInsufficientFunds):
+		return http.StatusUnprocessableEntity, "insufficient_funds"
+	case errors.Is(err, domain.ErrLimitExceeded):
+		return http.StatusUnprocessableEntity, "limit_exceeded"
+	case errors.Is(err, app.ErrScreeningHit):
+		return http.StatusUnavailableForLegalReasons, "sanctions_hit"
+	case errors.Is(err, app.ErrIdempotencyConflict):
+		return http.StatusUnprocessableEntity, "idempotency_conflict"
+	case errors.Is(err, app.ErrEventNotFound):
+		return http.StatusNotFound, "event_not_found"
+	case errors.Is(err, app.ErrInvalidCursor), errors.Is(err, app.ErrAsOfOutOfRange):
+		return http.StatusBadRequest, "bad_request"
+	case errors.As(err, &domainErr):
+		return http.StatusUnprocessableEntity, "rule_violation"
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable, "unavailable"
+	}
+	return http.StatusInternalServerError, "internal"
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	status, code := errorStatus(err)
+	message := err.Error()
+	if status == http.StatusInternalServerError {
+		// Internal errors can carry storage details; keep them in the log.
+		log.Printf("ERROR: %s %s: %v", r.Method, r.URL.Path, err)
+		message = "internal server error"
+	}
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="ledger"`)
+	}
+	writeJSON(w, status, errorBody{Error: errorDetail{Code: code, Message: message}})
+}
@@ -0,0 +1,437 @@
+// Package httpapi exposes the account service over HTTP with JSON bodies, and
+// streams committed events as Server-Sent Events.
+//
+// The account version doubles as an ETag: every response about one account
+// carries ETag: "<version>", and commands honour If-Match, failing with 412
+// when the account has moved on. The routes are described by the OpenAPI
+// document served at /openapi.json.
+//
+// Every route but /openapi.json needs a credential: an API key or a JWT, sent
+// as "Authorization: Bearer <credential>" or in X-API-Key. Requests are
+// authorized by an auth.Guard, and missing or rejected credentials get 401
+// while operations the caller's roles do not allow get 403.
+package httpapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/app"
+	"financial-ledger/auth"
+	"financial-ledger/events"
+	"financial-ledger/shared"
+)
+
+// OpenAPISpec is the OpenAPI 3 description of the API.
+//
+//go:embed openapi.json
+var OpenAPISpec []byte
+
+// maxBodyBytes bounds the size of request bodies.
+const maxBodyBytes = 1 << 20
+
+// Server is an http.Handler serving the ledger API.
+type Server struct {
+	svc *auth.Guard
+	mux *http.ServeMux
+
+	streamBufferSize  int
+	streamMaxAccounts int
+	streamHeartbeat   time.Duration
+}
+
+func NewServer(svc *auth.Guard, opts ...Option) *Server {
+	s := &Server{
+		svc:               svc,
+		mux:               http.NewServeMux(),
+		streamBufferSize:  DefaultStreamBufferSize,
+		streamMaxAccounts: DefaultStreamMaxAccounts,
+		streamHeartbeat:   DefaultStreamHeartbeat,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.mux.HandleFunc("POST /v1/accounts", s.createAccount)
+	s.mux.HandleFunc("GET /v1/accounts/{id}/balance", s.getBalance)
+	s.mux.HandleFunc("GET /v1/accounts/{id}/history", s.getHistory)
+	s.mux.HandleFunc("POST /v1/accounts/{id}/deposits", s.deposit)
+	s.mux.HandleFunc("POST /v1/accounts/{id}/withdrawals", s.withdraw)
+	s.mux.HandleFunc("POST /v1/accounts/{id}/conversions", s.convert)
+	s.mux.HandleFunc("POST /v1/transfers", s.transfer)
+	s.mux.HandleFunc("GET /v1/accounts/{id}/stream", s.streamAccountEvents)
+	s.mux.HandleFunc("GET /v1/stream", s.streamEvents)
+	s.mux.HandleFunc("GET /openapi.json", s.openAPI)
+	return s
+}
+
+// ServeHTTP authenticates the request before routing it. The OpenAPI document
+// is public.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/openapi.json" {
+		s.mux.ServeHTTP(w, r)
+		return
+	}
+	ctx, err := s.svc.Authenticate(r.Context(), credential(r), "http")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	s.mux.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// credential reads the caller's API key or JWT from the Authorization header,
+// or failing that from X-API-Key.
+func credential(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		scheme, value, _ := strings.Cut(header, " ")
+		if strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(value)
+		}
+		return ""
+	}
+	return r.Header.Get("X-API-Key")
+}
+
+// --- Request and response bodies ---
+
+type createAccountRequest struct {
+	AccountID       string                              `json:"accountId"`
+	InitialBalances map[shared.Currency]decimal.Decimal `json:"initialBalances"`
+	Holders         []string                            `json:"holders"`
+}
+
+type amountRequest struct {
+	Amount   decimal.Decimal `json:"amount"`
+	Currency shared.Currency `json:"currency"`
+}
+
+type conversionRequest struct {
+	FromAmount   decimal.Decimal `json:"fromAmount"`
+	FromCurrency shared.Currency `json:"fromCurrency"`
+	ToCurrency   shared.Currency `json:"toCurrency"`
+}
+
+type transferRequest struct {
+	SourceAccountID string          `json:"sourceAccountId"`
+	TargetAccountID string          `json:"targetAccountId"`
+	Amount          decimal.Decimal `json:"amount"`
+	Currency        shared.Currency `json:"currency"`
+}
+
+type balanceResponse struct {
+	AccountID string                              `json:"accountId"`
+	Version   int                                 `json:"version"`
+	Balances  map[shared.Currency]decimal.Decimal `json:"balances"`
+}
+
+type historyItemResponse struct {
+	Event         events.Event                        `json:"event"`
+	BalancesAfter map[shared.Currency]decimal.Decimal `json:"balancesAfter"`
+}
+
+type historyResponse struct {
+	AccountID  string                `json:"accountId"`
+	Items      []historyItemResponse `json:"items"`
+	NextCursor string                `json:"nextCursor,omitempty"`
+}
+
+// --- Handlers ---
+
+func (s *Server) createAccount(w http.ResponseWriter, r *http.Request) {
+	var req createAccountRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	accountID, err := s.svc.CreateAccount(r.Context(), app.CreateAccountCommand{
+		AccountID:       req.AccountID,
+		InitialBalances: req.InitialBalances,
+		Holders:         req.Holders,
+		IdempotencyKey:  r.Header.Get("Idempotency-Key"),
+		Metadata:        requestMetadata(r),
+	})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Location", "/v1/accounts/"+accountID+"/balance")
+	s.writeBalances(w, r, accountID, http.StatusCreated)
+}
+
+func (s *Server) getBalance(w http.ResponseWriter, r *http.Request) {
+	query := app.GetBalanceQuery{AccountID: r.PathValue("id")}
+	params := r.URL.Query()
+	if c := params.Get("currency"); c != "" {
+		currency := shared.Currency(strings.ToUpper(c))
+		query.Currency = &currency
+	}
+	if asOf := params.Get("asOf"); asOf != "" {
+		if version, err := strconv.Atoi(asOf); err == nil {
+			query.AsOfVersion = version
+		} else if at, err := time.Parse(time.RFC3339, asOf); err == nil {
+			query.AsOf = &at
+		} else {
+			writeError(w, r, badRequest("asOf must be a version number or an RFC 3339 time, got %q", asOf))
+			return
+		}
+	}
+
+	result, err := s.svc.GetBalances(r.Context(), query)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	etag := versionETag(result.Version)
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeJSON(w, http.StatusOK, balanceResponse{AccountID: result.AccountID, Version: result.Version, Balances: result.Balances})
+}
+
+func (s *Server) getHistory(w http.ResponseWriter, r *http.Request) {
+	query, err := historyQuery(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	page, err := s.svc.SearchHistory(r.Context(), query)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	resp := historyResponse{AccountID: query.AccountID, Items: make([]historyItemResponse, 0, len(page.Items)), NextCursor: page.NextCursor}
+	for _, item := range page.Items {
+		resp.Items = append(resp.Items, historyItemResponse{Event: item.Event, BalancesAfter: item.BalancesAfter})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) deposit(w http.ResponseWriter, r *http.Request) {
+	accountID := r.PathValue("id")
+	var req amountRequest
+	expected, err := decodeCommand(r, &req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	err = s.svc.Deposit(r.Context(), app.DepositMoneyCommand{
+		AccountID:       accountID,
+		Amount:          req.Amount,
+		Currency:        req.Currency,
+		ExpectedVersion: expected,
+		IdempotencyKey:  r.Header.Get("Idempotency-Key"),
+		Metadata:        requestMetadata(r),
+	})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	s.writeBalances(w, r, accountID, http.StatusOK)
+}
+
+func (s *Server) withdraw(w http.ResponseWriter, r *http.Request) {
+	accountID := r.PathValue("id")
+	var req amountRequest
+	expected, err := decodeCommand(r, &req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	err = s.svc.Withdraw(r.Context(), app.WithdrawMoneyCommand{
+		AccountID:       accountID,
+		Amount:          req.Amount,
+		Currency:        req.Currency,
+		ExpectedVersion: expected,
+		IdempotencyKey:  r.Header.Get("Idempotency-Key"),
+		Metadata:        requestMetadata(r),
+	})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	s.writeBalances(w, r, accountID, http.StatusOK)
+}
+
+func (s *Server) convert(w http.ResponseWriter, r *http.Request) {
+	accountID := r.PathValue("id")
+	var req conversionRequest
+	expected, err := decodeCommand(r, &req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	err = s.svc.ConvertCurrency(r.Context(), app.ConvertCurrencyCommand{
+		AccountID:       accountID,
+		FromAmount:      req.FromAmount,
+		FromCurrency:    req.FromCurrency,
+		ToCurrency:      req.ToCurrency,
+		ExpectedVersion: expected,
+		IdempotencyKey:  r.Header.Get("Idempotency-Key"),
+		Metadata:        requestMetadata(r),
+	})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	s.writeBalances(w, r, accountID, http.StatusOK)
+}
+
+// transfer applies If-Match to the source account, whose balances it returns.
+func (s *Server) transfer(w http.ResponseWriter, r *http.Request) {
+	var req transferRequest
+	expected, err := decodeCommand(r, &req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	err = s.svc.TransferMoney(r.Context(), app.TransferMoneyCommand{
+		SourceAccountID: req.SourceAccountID,
+		TargetAccountID: req.TargetAccountID,
+		Amount:          req.Amount,
+		Currency:        req.Currency,
+		ExpectedVersion: expected,
+		IdempotencyKey:  r.Header.Get("Idempotency-Key"),
+		Metadata:        requestMetadata(r),
+	})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	s.writeBalances(w, r, req.SourceAccountID, http.StatusOK)
+}
+
+func (s *Server) openAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(OpenAPISpec)
+}
+
+// writeBalances responds with the account's balances after a command, tagged
+// with its new version.
+func (s *Server) writeBalances(w http.ResponseWriter, r *http.Request, accountID string, status int) {
+	result, err := s.svc.GetBalances(r.Context(), app.GetBalanceQuery{AccountID: accountID})
+	if err != nil {
+		writeError(w, r, fmt.Errorf("command succeeded but balances could not be read: %w", err))
+		return
+	}
+	w.Header().Set("ETag", versionETag(result.Version))
+	writeJSON(w, status, balanceResponse{AccountID: result.AccountID, Version: result.Version, Balances: result.Balances})
+}
+
+// --- Helpers ---
+
+func versionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// expectedVersion reads If-Match. A missing header or "*" skips the check.
+func expectedVersion(r *http.Request) (int, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	tag := strings.TrimPrefix(header, "W/")
+	version, err := strconv.Atoi(strings.Trim(tag, `"`))
+	if err != nil || version < 1 || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+		return 0, badRequest("If-Match must be a single account version ETag such as \"3\", got %s", header)
+	}
+	return version, nil
+}
+
+func decodeCommand(r *http.Request, dst any) (int, error) {
+	expected, err := expectedVersion(r)
+	if err != nil {
+		return 0, err
+	}
+	return expected, decodeBody(r, dst)
+}
+
+func decodeBody(r *http.Request, dst any) error {
+	dec := json.NewDecoder(io.LimitReader(r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		if errors.Is(err, io.EOF) {
+			return badRequest("request body is empty")
+		}
+		return badRequest("invalid request body: %v", err)
+	}
+	return nil
+}
+
+func historyQuery(r *http.Request) (app.SearchHistoryQuery, error) {
+	params := r.URL.Query()
+	query := app.SearchHistoryQuery{
+		AccountID:    r.PathValue("id"),
+		Counterparty: params.Get("counterparty"),
+		Cursor:       params.Get("cursor"),
+	}
+	for _, t := range params["type"] {
+		query.Types = append(query.Types, events.EventType(t))
+	}
+	if c := params.Get("currency"); c != "" {
+		currency := shared.Currency(strings.ToUpper(c))
+		query.Currency = &currency
+	}
+	for name, dst := range map[string]**time.Time{"from": &query.From, "to": &query.To} {
+		if v := params.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return query, badRequest("%s must be an RFC 3339 time, got %q", name, v)
+			}
+			*dst = &t
+		}
+	}
+	for name, dst := range map[string]**decimal.Decimal{"minAmount": &query.MinAmount, "maxAmount": &query.MaxAmount} {
+		if v := params.Get(name); v != "" {
+			d, err := decimal.NewFromString(v)
+			if err != nil {
+				return query, badRequest("%s must be a decimal amount, got %q", name, v)
+			}
+			*dst = &d
+		}
+	}
+	switch order := params.Get("order"); order {
+	case "", "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		return query, badRequest("order must be asc or desc, got %q", order)
+	}
+	if v := params.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			return query, badRequest("limit must be a non-negative integer, got %q", v)
+		}
+		query.Limit = limit
+	}
+	return query, nil
+}
+
+// requestMetadata builds the audit metadata recorded on events produced by a
+// request. X-Actor and X-Correlation-ID are optional; the Guard replaces the
+// actor with the authenticated principal's ID.
+func requestMetadata(r *http.Request) events.Metadata {
+	return events.Metadata{
+		Actor:         r.Header.Get("X-Actor"),
+		CorrelationID: r.Header.Get("X-Correlation-ID"),
+		Channel:       "http",
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(body)
+}
@@ -0,0 +1,376 @@
+package httpapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"financial-ledger/app"
+	"financial-ledger/auth"
+	"financial-ledger/httpapi"
+	"financial-ledger/store"
+)
+
+type testClient struct {
+	t       *testing.T
+	handler http.Handler
+}
+
+func newTestClient(t *testing.T) *testClient {
+	t.Helper()
+	svc := app.NewAccountService(store.NewInMemoryEventStore(), store.NewInMemorySnapshotStore())
+	return &testClient{t: t, handler: httpapi.NewServer(unauthenticated(svc))}
+}
+
+// unauthenticated guards svc without requiring credentials, acting as an admin.
+func unauthenticated(svc *app.AccountService) *auth.Guard {
+	return auth.NewGuard(svc, auth.AllowAll(auth.Principal{Roles: []auth.Role{auth.RoleAdmin}}))
+}
+
+func (c *testClient) do(method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	c.t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+		t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+	}
+	return v
+}
+
+type balances struct {
+	AccountID string            `json:"accountId"`
+	Version   int               `json:"version"`
+	Balances  map[string]string `json:"balances"`
+}
+
+type apiError struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func expectStatus(t *testing.T, rec *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("expected status %d, got %d: %s", status, rec.Code, rec.Body.String())
+	}
+}
+
+func expectError(t *testing.T, rec *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+	expectStatus(t, rec, status)
+	if got := decode[apiError](t, rec).Error.Code; got != code {
+		t.Errorf("expected error code %q, got %q", code, got)
+	}
+}
+
+func TestServer_AccountLifecycle(t *testing.T) {
+	c := newTestClient(t)
+
+	rec := c.do("POST", "/v1/accounts", `{"accountId":"acc-1","initialBalances":{"USD":"100"}}`, nil)
+	expectStatus(t, rec, http.StatusCreated)
+	if rec.Header().Get("ETag") != `"1"` {
+		t.Errorf("expected ETag \"1\", got %q", rec.Header().Get("ETag"))
+	}
+	if rec.Header().Get("Location") != "/v1/accounts/acc-1/balance" {
+		t.Errorf("unexpected Location %q", rec.Header().Get("Location"))
+	}
+	c.do("POST", "/v1/accounts", `{"accountId":"acc-2"}`, nil)
+
+	t.Run("Deposit", func(t *testing.T) {
+		rec := c.do("POST", "/v1/accounts/acc-1/deposits", `{"amount":"50.25","currency":"USD"}`, map[string]string{"If-Match": `"1"`})
+		expectStatus(t, rec, http.StatusOK)
+		got := decode[balances](t, rec)
+		if got.Version != 2 || got.Balances["USD"] != "150.25" {
+			t.Errorf("unexpected balances after deposit: %+v", got)
+		}
+		if rec.Header().Get("ETag") != `"2"` {
+			t.Errorf("expected ETag \"2\", got %q", rec.Header().Get("ETag"))
+		}
+	})
+
+	t.Run("Withdraw", func(t *testing.T) {
+		rec := c.do("POST", "/v1/accounts/acc-1/withdrawals", `{"amount":"0.25","currency":"USD"}`, nil)
+		expectStatus(t, rec, http.StatusOK)
+		if got := decode[balances](t, rec); got.Balances["USD"] != "150" {
+			t.Errorf("unexpected balances after withdrawal: %+v", got)
+		}
+	})
+
+	t.Run("Convert", func(t *testing.T) {
+		rec := c.do("POST", "/v1/accounts/acc-1/conversions", `{"fromAmount":"50","fromCurrency":"USD","toCurrency":"EUR"}`, nil)
+		expectStatus(t, rec, http.StatusOK)
+		if got := decode[balances](t, rec); got.Balances["USD"] != "100" || got.Balances["EUR"] != "46" {
+			t.Errorf("unexpected balances after conversion: %+v", got)
+		}
+	})
+
+	t.Run("Transfer", func(t *testing.T) {
+		rec := c.do("POST", "/v1/transfers", `{"sourceAccountId":"acc-1","targetAccountId":"acc-2","amount":"30","currency":"USD"}`, map[string]string{"If-Match": `"4"`})
+		expectStatus(t, rec, http.StatusOK)
+		if got := decode[balances](t, rec); got.AccountID != "acc-1" || got.Balances["USD"] != "70" {
+			t.Errorf("unexpected source balances after transfer: %+v", got)
+		}
+		rec = c.do("GET", "/v1/accounts/acc-2/balance?currency=usd", "", nil)
+		expectStatus(t, rec, http.StatusOK)
+		if got := decode[balances](t, rec); got.Balances["USD"] != "30" {
+			t.Errorf("unexpected target balance: %+v", got)
+		}
+	})
+
+	t.Run("BalanceETag", func(t *testing.T) {
+		rec := c.do("GET", "/v1/accounts/acc-1/balance", "", nil)
+		expectStatus(t, rec, http.StatusOK)
+		etag := rec.Header().Get("ETag")
+		if etag != `"5"` {
+			t.Errorf("expected ETag \"5\", got %q", etag)
+		}
+		rec = c.do("GET", "/v1/accounts/acc-1/balance", "", map[string]string{"If-None-Match": etag})
+		expectStatus(t, rec, http.StatusNotModified)
+	})
+
+	t.Run("BalanceAsOf", func(t *testing.T) {
+		rec := c.do("GET", "/v1/accounts/acc-1/balance?asOf=2", "", nil)
+		expectStatus(t, rec, http.StatusOK)
+		if got := decode[balances](t, rec); got.Version != 2 || got.Balances["USD"] != "150.25" {
+			t.Errorf("unexpected balances as of version 2: %+v", got)
+		}
+		expectError(t, c.do("GET", "/v1/accounts/acc-1/balance?asOf=99", "", nil), http.StatusBadRequest, "bad_request")
+		expectError(t, c.do("GET", "/v1/accounts/acc-1/balance?asOf=yesterday", "", nil), http.StatusBadRequest, "bad_request")
+	})
+
+	t.Run("History", func(t *testing.T) {
+		type page struct {
+			Items []struct {
+				Event struct {
+					Type    string `json:"type"`
+					Version int    `json:"version"`
+				} `json:"event"`
+				BalancesAfter map[string]string `json:"balancesAfter"`
+			} `json:"items"`
+			NextCursor string `json:"nextCursor"`
+		}
+		rec := c.do("GET", "/v1/accounts/acc-1/history?order=desc&limit=2", "", nil)
+		expectStatus(t, rec, http.StatusOK)
+		first := decode[page](t, rec)
+		if len(first.Items) != 2 || first.Items[0].Event.Version != 5 || first.Items[0].Event.Type != "MoneyTransferred" || first.NextCursor == "" {
+			t.Fatalf("unexpected first page: %+v", first)
+		}
+		if first.Items[0].BalancesAfter["USD"] != "70" {
+			t.Errorf("unexpected running balance: %v", first.Items[0].BalancesAfter)
+		}
+
+		rec = c.do("GET", "/v1/accounts/acc-1/history?order=desc&limit=10&cursor="+first.NextCursor, "", nil)
+		expectStatus(t, rec, http.StatusOK)
+		if second := decode[page](t, rec); len(second.Items) != 3 || second.Items[2].Event.Version != 1 {
+			t.Errorf("unexpected second page: %+v", second)
+		}
+
+		rec = c.do("GET", "/v1/accounts/acc-1/history?type=DepositMade&type=WithdrawalMade", "", nil)
+		expectStatus(t, rec, http.StatusOK)
+		if filtered := decode[page](t, rec); len(filtered.Items) != 2 {
+			t.Errorf("expected 2 deposit/withdrawal events, got %+v", filtered)
+		}
+
+		expectError(t, c.do("GET", "/v1/accounts/acc-1/history?cursor=bogus", "", nil), http.StatusBadRequest, "bad_request")
+		expectError(t, c.do("GET", "/v1/accounts/acc-1/history?order=sideways", "", nil), http.StatusBadRequest, "bad_request")
+	})
+}
+
+func TestServer_Errors(t *testing.T) {
+	c := newTestClient(t)
+	c.do("POST", "/v1/accounts", `{"accountId":"acc-1","initialBalances":{"USD":"10"}}`, nil)
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		headers map[string]string
+		status  int
+		code    string
+	}{
+		{"InsufficientFunds", "POST", "/v1/accounts/acc-1/withdrawals", `{"amount":"11","currency":"USD"}`, nil, http.StatusUnprocessableEntity, "insufficient_funds"},
+		{"AccountNotFound", "POST", "/v1/accounts/missing/deposits", `{"amount":"1","currency":"USD"}`, nil, http.StatusNotFound, "account_not_found"},
+		{"BalanceOfMissingAccount", "GET", "/v1/accounts/missing/balance", "", nil, http.StatusNotFound, "account_not_found"},
+		{"AccountExists", "POST", "/v1/accounts", `{"accountId":"acc-1"}`, nil, http.StatusConflict, "account_exists"},
+		{"UnknownHolder", "POST", "/v1/accounts", `{"accountId":"acc-2","holders":["cust-9"]}`, nil, http.StatusNotFound, "customer_not_found"},
+		{"StaleIfMatch", "POST", "/v1/accounts/acc-1/deposits", `{"amount":"1","currency":"USD"}`, map[string]string{"If-Match": `"7"`}, http.StatusPreconditionFailed, "version_mismatch"},
+		{"MalformedIfMatch", "POST", "/v1/accounts/acc-1/deposits", `{"amount":"1","currency":"USD"}`, map[string]string{"If-Match": "1"}, http.StatusBadRequest, "bad_request"},
+		{"NegativeAmount", "POST", "/v1/accounts/acc-1/deposits", `{"amount":"-1","currency":"USD"}`, nil, http.StatusUnprocessableEntity, "rule_violation"},
+		{"MalformedAmount", "POST", "/v1/accounts/acc-1/deposits", `{"amount":"ten","currency":"USD"}`, nil, http.StatusBadRequest, "bad_request"},
+		{"UnknownField", "POST", "/v1/accounts/acc-1/deposits", `{"amount":"1","currency":"USD","memo":"x"}`, nil, http.StatusBadRequest, "bad_request"},
+		{"EmptyBody", "POST", "/v1/accounts/acc-1/deposits", "", nil, http.StatusBadRequest, "bad_request"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectError(t, c.do(tt.method, tt.path, tt.body, tt.headers), tt.status, tt.code)
+		})
+	}
+
+	t.Run("IdempotencyKeyReuse", func(t *testing.T) {
+		headers := map[string]string{"Idempotency-Key": "dep-1"}
+		expectStatus(t, c.do("POST", "/v1/accounts/acc-1/deposits", `{"amount":"1","currency":"USD"}`, headers), http.StatusOK)
+		rec := c.do("POST", "/v1/accounts/acc-1/deposits", `{"amount":"1","currency":"USD"}`, headers)
+		expectStatus(t, rec, http.StatusOK)
+		if got := decode[balances](t, rec); got.Balances["USD"] != "11" {
+			t.Errorf("retried deposit should not apply twice, got %+v", got)
+		}
+		expectError(t, c.do("POST", "/v1/accounts/acc-1/deposits", `{"amount":"2","currency":"USD"}`, headers), http.StatusUnprocessableEntity, "idempotency_conflict")
+	})
+}
+
+func TestServer_Authentication(t *testing.T) {
+	svc := app.NewAccountService(store.NewInMemoryEventStore(), store.NewInMemorySnapshotStore())
+	keys := map[string]string{}
+	var configured []auth.APIKey
+	for _, k := range []auth.APIKey{
+		{ID: "teller-1", Roles: []string{"teller"}, Accounts: []string{"acc-1"}},
+		{ID: "viewer", Roles: []string{"read-only"}},
+		{ID: "admin", Roles: []string{"admin"}},
+	} {
+		key, hash, err := auth.GenerateAPIKey()
+		if err != nil {
+			t.Fatalf("failed to generate API key: %v", err)
+		}
+		k.Hash = hash
+		keys[k.ID] = key
+		configured = append(configured, k)
+	}
+	authn, err := auth.NewAuthenticator(configured, nil)
+	if err != nil {
+		t.Fatalf("failed to build authenticator: %v", err)
+	}
+	guard := auth.NewGuard(svc, authn)
+	c := &testClient{t: t, handler: httpapi.NewServer(guard)}
+	bearer := func(id string) map[string]string {
+		return map[string]string{"Authorization": "Bearer " + keys[id]}
+	}
+
+	expectStatus(t, c.do("POST", "/v1/accounts", `{"accountId":"acc-1"}`, bearer("admin")), http.StatusCreated)
+	expectStatus(t, c.do("POST", "/v1/accounts", `{"accountId":"acc-2"}`, bearer("admin")), http.StatusCreated)
+
+	t.Run("MissingCredentials", func(t *testing.T) {
+		rec := c.do("GET", "/v1/accounts/acc-1/balance", "", nil)
+		expectError(t, rec, http.StatusUnauthorized, "unauthenticated")
+		if rec.Header().Get("WWW-Authenticate") == "" {
+			t.Error("401 response should carry WWW-Authenticate")
+		}
+	})
+
+	t.Run("UnknownKey", func(t *testing.T) {
+		expectError(t, c.do("GET", "/v1/accounts/acc-1/balance", "", map[string]string{"X-API-Key": "lk_bogus"}), http.StatusUnauthorized, "unauthenticated")
+	})
+
+	t.Run("OpenAPISpecIsPublic", func(t *testing.T) {
+		expectStatus(t, c.do("GET", "/openapi.json", "", nil), http.StatusOK)
+	})
+
+	t.Run("TellerMovesMoneyOnOwnAccount", func(t *testing.T) {
+		headers := bearer("teller-1")
+		headers["X-Actor"] = "someone-else"
+		expectStatus(t, c.do("POST", "/v1/accounts/acc-1/deposits", `{"amount":"5","currency":"USD"}`, headers), http.StatusOK)
+
+		history := decode[struct {
+			Items []struct {
+				Event struct {
+					Metadata struct {
+						Actor string `json:"actor"`
+					} `json:"metadata"`
+				} `json:"event"`
+			} `json:"items"`
+		}](t, c.do("GET", "/v1/accounts/acc-1/history?order=desc&limit=1", "", bearer("teller-1")))
+		if len(history.Items) != 1 || history.Items[0].Event.Metadata.Actor != "teller-1" {
+			t.Errorf("expected the deposit to be recorded as made by teller-1, got %+v", history.Items)
+		}
+	})
+
+	t.Run("TellerCannotTouchOtherAccounts", func(t *testing.T) {
+		expectError(t, c.do("POST", "/v1/accounts/acc-2/deposits", `{"amount":"5","currency":"USD"}`, bearer("teller-1")), http.StatusForbidden, "forbidden")
+		expectError(t, c.do("GET", "/v1/accounts/acc-2/balance", "", bearer("teller-1")), http.StatusForbidden, "forbidden")
+		expectError(t, c.do("POST", "/v1/transfers", `{"sourceAccountId":"acc-2","targetAccountId":"acc-1","amount":"1","currency":"USD"}`, bearer("teller-1")), http.StatusForbidden, "forbidden")
+	})
+
+	t.Run("ReadOnlyCannotMoveMoney", func(t *testing.T) {
+		expectStatus(t, c.do("GET", "/v1/accounts/acc-2/balance", "", map[string]string{"X-API-Key": keys["viewer"]}), http.StatusOK)
+		expectError(t, c.do("POST", "/v1/accounts/acc-2/withdrawals", `{"amount":"1","currency":"USD"}`, bearer("viewer")), http.StatusForbidden, "forbidden")
+	})
+
+	t.Run("DenialsAreAudited", func(t *testing.T) {
+		ctx, err := guard.Authenticate(context.Background(), keys["admin"], "test")
+		if err != nil {
+			t.Fatalf("admin failed to authenticate: %v", err)
+		}
+		denials, err := guard.ListDenials(ctx, time.Time{})
+		if err != nil {
+			t.Fatalf("ListDenials failed: %v", err)
+		}
+		var found bool
+		for _, d := range denials {
+			if d.Actor == "viewer" && d.Operation == "Withdraw" && d.Channel == "http" {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected the viewer's withdrawal to be recorded as denied, got %+v", denials)
+		}
+	})
+}
+
+func TestServer_OpenAPISpec(t *testing.T) {
+	c := newTestClient(t)
+	rec := c.do("GET", "/openapi.json", "", nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	var spec struct {
+		OpenAPI string                    `json:"openapi"`
+		Paths   map[string]map[string]any `json:"paths"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("spec is not valid JSON: %v", err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		t.Errorf("expected an OpenAPI 3 document, got version %q", spec.OpenAPI)
+	}
+
+	routes := map[string]string{
+		"/v1/accounts":                  "post",
+		"/v1/accounts/{id}/balance":     "get",
+		"/v1/accounts/{id}/history":     "get",
+		"/v1/accounts/{id}/deposits":    "post",
+		"/v1/accounts/{id}/withdrawals": "post",
+		"/v1/accounts/{id}/conversions": "post",
+		"/v1/transfers":                 "post",
+		"/openapi.json":                 "get",
+		"/v1/accounts/{id}/stream":      "get",
+		"/v1/stream":                    "get",
+	}
+	for path, method := range routes {
+		if _, ok := spec.Paths[path][method]; !ok {
+			t.Errorf("spec does not describe %s %s", strings.ToUpper(method), path)
+		}
+	}
+	if len(spec.Paths) != len(routes) {
+		t.Errorf("spec describes %d paths, server has %d", len(spec.Paths), len(routes))
+	}
+}
@@ -0,0 +1,220 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/app"
+	"financial-ledger/events"
+	"financial-ledger/shared"
+)
+
+// Defaults for the event stream. Each connection buffers at most
+// DefaultStreamBufferSize events; a client that falls further behind is
+// disconnected and should reconnect with Last-Event-ID.
+const (
+	DefaultStreamBufferSize  = 256
+	DefaultStreamMaxAccounts = 100
+	DefaultStreamHeartbeat   = 15 * time.Second
+)
+
+// errSlowConsumer ends a stream whose client cannot keep up.
+var errSlowConsumer = errors.New("client is not reading events fast enough")
+
+// Option customises a Server.
+type Option func(*Server)
+
+// WithStreamBufferSize sets how many events a stream connection may hold for
+// a slow client before it is disconnected.
+func WithStreamBufferSize(n int) Option {
+	return func(s *Server) {
+		if n > 0 {
+			s.streamBufferSize = n
+		}
+	}
+}
+
+// WithStreamMaxAccounts limits how many accounts one stream may follow.
+func WithStreamMaxAccounts(n int) Option {
+	return func(s *Server) {
+		if n > 0 {
+			s.streamMaxAccounts = n
+		}
+	}
+}
+
+// WithStreamHeartbeat sets how often an idle stream sends a keep-alive comment.
+func WithStreamHeartbeat(d time.Duration) Option {
+	return func(s *Server) {
+		if d > 0 {
+			s.streamHeartbeat = d
+		}
+	}
+}
+
+type snapshotMessage struct {
+	Position int64             `json:"position"`
+	Accounts []balanceResponse `json:"accounts"`
+}
+
+type eventMessage struct {
+	AccountID     string                              `json:"accountId"`
+	Version       int                                 `json:"version"`
+	Event         events.Event                        `json:"event"`
+	BalancesAfter map[shared.Currency]decimal.Decimal `json:"balancesAfter"`
+}
+
+// streamAccountEvents streams one account; see streamEvents.
+func (s *Server) streamAccountEvents(w http.ResponseWriter, r *http.Request) {
+	s.stream(w, r, []string{r.PathValue("id")})
+}
+
+// streamEvents streams the accounts named by repeated account parameters.
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request) {
+	s.stream(w, r, r.URL.Query()["account"])
+}
+
+// stream sends Server-Sent Events for committed events of accountIDs. Each
+// event's SSE id is its global log position. A fresh connection first gets a
+// snapshot of current balances; a reconnect carrying Last-Event-ID (or the
+// after / afterEvent parameters) instead receives every event it missed.
+func (s *Server) stream(w http.ResponseWriter, r *http.Request, accountIDs []string) {
+	query, err := s.feedQuery(r, accountIDs)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, r, errors.New("response writer does not support streaming"))
+		return
+	}
+	feed, err := s.svc.OpenFeed(r.Context(), query)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if query.FromHead {
+		snapshot := snapshotMessage{Position: feed.Position()}
+		for _, b := range feed.Balances() {
+			snapshot.Accounts = append(snapshot.Accounts, balanceResponse{AccountID: b.AccountID, Version: b.Version, Balances: b.Balances})
+		}
+		writeSSE(w, "snapshot", feed.Position(), snapshot)
+	}
+	flusher.Flush()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	buffer := make(chan app.FeedItem, s.streamBufferSize)
+	feedErr := make(chan error, 1)
+	go func() {
+		defer close(buffer)
+		feedErr <- feed.Run(ctx, func(item app.FeedItem) error {
+			select {
+			case buffer <- item:
+				return nil
+			default:
+				return errSlowConsumer
+			}
+		})
+	}()
+
+	heartbeat := time.NewTicker(s.streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case item, open := <-buffer:
+			if !open {
+				s.endStream(w, r, <-feedErr)
+				flusher.Flush()
+				return
+			}
+			base := item.Event.GetBase()
+			writeSSE(w, "event", base.Position, eventMessage{AccountID: base.AggregateID, Version: base.Version, Event: item.Event, BalancesAfter: item.BalancesAfter})
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// endStream tells the client why the server closed the stream. Nothing is sent
+// once the client itself has gone.
+func (s *Server) endStream(w http.ResponseWriter, r *http.Request, err error) {
+	if r.Context().Err() != nil {
+		return
+	}
+	code, message := "internal", "internal server error"
+	if errors.Is(err, errSlowConsumer) {
+		code, message = "slow_consumer", "stream buffer overflowed; reconnect with Last-Event-ID to resume"
+	} else if status, c := errorStatus(err); status == http.StatusInternalServerError {
+		log.Printf("ERROR: %s %s: stream failed: %v", r.Method, r.URL.Path, err)
+	} else {
+		code, message = c, err.Error()
+	}
+	writeSSE(w, "error", -1, errorBody{Error: errorDetail{Code: code, Message: message}})
+}
+
+// feedQuery reads the accounts to follow and where to resume. Last-Event-ID
+// takes precedence over the after parameter.
+func (s *Server) feedQuery(r *http.Request, accountIDs []string) (app.FeedQuery, error) {
+	query := app.FeedQuery{AccountIDs: accountIDs}
+	if len(accountIDs) == 0 {
+		return query, badRequest("name at least one account to follow")
+	}
+	if len(accountIDs) > s.streamMaxAccounts {
+		return query, badRequest("a stream may follow at most %d accounts, got %d", s.streamMaxAccounts, len(accountIDs))
+	}
+
+	params := r.URL.Query()
+	after := r.Header.Get("Last-Event-ID")
+	if after == "" {
+		after = params.Get("after")
+	}
+	switch {
+	case after != "":
+		position, err := strconv.ParseInt(after, 10, 64)
+		if err != nil || position < 0 {
+			return query, badRequest("resume position must be a non-negative integer, got %q", after)
+		}
+		query.AfterPosition = position
+	case params.Get("afterEvent") != "":
+		eventID, err := uuid.Parse(params.Get("afterEvent"))
+		if err != nil {
+			return query, badRequest("afterEvent must be an event ID: %v", err)
+		}
+		query.AfterEventID = eventID
+	default:
+		query.FromHead = true
+	}
+	return query, nil
+}
+
+// writeSSE writes one Server-Sent Event. A negative id is omitted.
+func writeSSE(w http.ResponseWriter, event string, id int64, data any) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		payload = []byte(`{"error":{"code":"internal","message":"failed to encode event"}}`)
+	}
+	if id >= 0 {
+		fmt.Fprintf(w, "id: %d\n", id)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+}
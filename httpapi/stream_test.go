@@ -0,0 +1,284 @@
+package httpapi_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/app"
+	"financial-ledger/httpapi"
+	"financial-ledger/shared"
+	"financial-ledger/store"
+)
+
+type sseMessage struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+// sseReader parses a Server-Sent Events stream. Comments are reported as
+// messages with Event ":".
+type sseReader struct {
+	scanner *bufio.Scanner
+}
+
+func (r *sseReader) next(t *testing.T) sseMessage {
+	t.Helper()
+	var msg sseMessage
+	for r.scanner.Scan() {
+		line := r.scanner.Text()
+		switch {
+		case line == "":
+			if msg.Event != "" {
+				return msg
+			}
+		case strings.HasPrefix(line, ":"):
+			return sseMessage{Event: ":"}
+		case strings.HasPrefix(line, "id: "):
+			msg.ID = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			msg.Event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			msg.Data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+	t.Fatalf("stream ended: %v", r.scanner.Err())
+	return msg
+}
+
+func (r *sseReader) nextEvent(t *testing.T) sseMessage {
+	t.Helper()
+	for {
+		if msg := r.next(t); msg.Event != ":" {
+			return msg
+		}
+	}
+}
+
+type streamFixture struct {
+	svc    *app.AccountService
+	server *httptest.Server
+}
+
+func newStreamFixture(t *testing.T, opts ...httpapi.Option) *streamFixture {
+	t.Helper()
+	svc := app.NewAccountService(store.NewInMemoryEventStore(), store.NewInMemorySnapshotStore(), app.WithTailPollInterval(time.Millisecond))
+	server := httptest.NewServer(httpapi.NewServer(unauthenticated(svc), opts...))
+	t.Cleanup(server.Close)
+	return &streamFixture{svc: svc, server: server}
+}
+
+func (f *streamFixture) open(t *testing.T, path string, headers map[string]string) (*http.Response, *sseReader) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	req, _ := http.NewRequestWithContext(ctx, "GET", f.server.URL+path, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", path, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp, &sseReader{scanner: bufio.NewScanner(resp.Body)}
+}
+
+type streamEvent struct {
+	AccountID string `json:"accountId"`
+	Version   int    `json:"version"`
+	Event     struct {
+		Type    string `json:"type"`
+		EventID string `json:"eventId"`
+	} `json:"event"`
+	BalancesAfter map[string]string `json:"balancesAfter"`
+}
+
+func decodeData[T any](t *testing.T, msg sseMessage) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal([]byte(msg.Data), &v); err != nil {
+		t.Fatalf("failed to decode %s message %q: %v", msg.Event, msg.Data, err)
+	}
+	return v
+}
+
+func TestServer_Stream(t *testing.T) {
+	f := newStreamFixture(t)
+	_, _ = f.svc.CreateAccount(app.CreateAccountCommand{AccountID: "acc-1", InitialBalances: map[shared.Currency]decimal.Decimal{shared.USD: decimal.NewFromInt(100)}})
+	_, _ = f.svc.CreateAccount(app.CreateAccountCommand{AccountID: "acc-2"})
+	_, _ = f.svc.CreateAccount(app.CreateAccountCommand{AccountID: "acc-3"})
+
+	var lastID string
+	t.Run("SnapshotThenLiveEvents", func(t *testing.T) {
+		resp, stream := f.open(t, "/v1/stream?account=acc-1&account=acc-2", nil)
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+			t.Fatalf("unexpected response: %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+		snapshot := stream.nextEvent(t)
+		if snapshot.Event != "snapshot" || snapshot.ID != "3" {
+			t.Fatalf("expected a snapshot at position 3, got %+v", snapshot)
+		}
+		accounts := decodeData[struct {
+			Accounts []struct {
+				AccountID string            `json:"accountId"`
+				Balances  map[string]string `json:"balances"`
+			} `json:"accounts"`
+		}](t, snapshot).Accounts
+		if len(accounts) != 2 || accounts[0].Balances["USD"] != "100" {
+			t.Errorf("unexpected snapshot accounts: %+v", accounts)
+		}
+
+		_ = f.svc.Deposit(app.DepositMoneyCommand{AccountID: "acc-3", Amount: decimal.NewFromInt(1), Currency: shared.USD})
+		_ = f.svc.TransferMoney(app.TransferMoneyCommand{SourceAccountID: "acc-1", TargetAccountID: "acc-2", Amount: decimal.NewFromInt(40), Currency: shared.USD})
+
+		first := stream.nextEvent(t)
+		second := stream.nextEvent(t)
+		debit, credit := decodeData[streamEvent](t, first), decodeData[streamEvent](t, second)
+		if debit.AccountID != "acc-1" || debit.BalancesAfter["USD"] != "60" || debit.Event.Type != "MoneyTransferred" {
+			t.Errorf("unexpected first event: %+v", debit)
+		}
+		if credit.AccountID != "acc-2" || credit.BalancesAfter["USD"] != "40" {
+			t.Errorf("unexpected second event: %+v", credit)
+		}
+		if first.ID != "5" || second.ID != "6" {
+			t.Errorf("expected ids to be global positions 5 and 6, got %s and %s", first.ID, second.ID)
+		}
+		lastID = first.ID
+	})
+
+	t.Run("ResumeWithLastEventID", func(t *testing.T) {
+		_, stream := f.open(t, "/v1/stream?account=acc-1&account=acc-2", map[string]string{"Last-Event-ID": lastID})
+		msg := stream.nextEvent(t)
+		if msg.Event != "event" || msg.ID != "6" {
+			t.Fatalf("expected to resume with the event at position 6, got %+v", msg)
+		}
+		if got := decodeData[streamEvent](t, msg); got.AccountID != "acc-2" || got.Version != 2 {
+			t.Errorf("unexpected resumed event: %+v", got)
+		}
+	})
+
+	t.Run("ResumeAfterEventID", func(t *testing.T) {
+		_, stream := f.open(t, "/v1/accounts/acc-1/stream?after=0", nil)
+		created := decodeData[streamEvent](t, stream.nextEvent(t))
+
+		_, stream = f.open(t, "/v1/accounts/acc-1/stream?afterEvent="+created.Event.EventID, nil)
+		if got := decodeData[streamEvent](t, stream.nextEvent(t)); got.Version != 2 || got.BalancesAfter["USD"] != "60" {
+			t.Errorf("expected the transfer after the creation event, got %+v", got)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		c := &testClient{t: t, handler: f.server.Config.Handler}
+		expectError(t, c.do("GET", "/v1/stream", "", nil), http.StatusBadRequest, "bad_request")
+		expectError(t, c.do("GET", "/v1/accounts/missing/stream", "", nil), http.StatusNotFound, "account_not_found")
+		expectError(t, c.do("GET", "/v1/accounts/acc-1/stream?afterEvent=9f0c2c2e-5d0e-4a8e-9d38-0d6f0f0f0f0f", "", nil), http.StatusNotFound, "event_not_found")
+		expectError(t, c.do("GET", "/v1/accounts/acc-1/stream", "", map[string]string{"Last-Event-ID": "abc"}), http.StatusBadRequest, "bad_request")
+	})
+}
+
+func TestServer_StreamLimits(t *testing.T) {
+	t.Run("TooManyAccounts", func(t *testing.T) {
+		f := newStreamFixture(t, httpapi.WithStreamMaxAccounts(2))
+		c := &testClient{t: t, handler: f.server.Config.Handler}
+		expectError(t, c.do("GET", "/v1/stream?account=a&account=b&account=c", "", nil), http.StatusBadRequest, "bad_request")
+	})
+
+	t.Run("Heartbeat", func(t *testing.T) {
+		f := newStreamFixture(t, httpapi.WithStreamHeartbeat(5*time.Millisecond))
+		_, _ = f.svc.CreateAccount(app.CreateAccountCommand{AccountID: "acc-1"})
+		_, stream := f.open(t, "/v1/accounts/acc-1/stream", nil)
+		stream.nextEvent(t) // snapshot
+		if msg := stream.next(t); msg.Event != ":" {
+			t.Errorf("expected a keep-alive comment on an idle stream, got %+v", msg)
+		}
+	})
+
+	t.Run("SlowConsumerIsDisconnected", func(t *testing.T) {
+		svc := app.NewAccountService(store.NewInMemoryEventStore(), store.NewInMemorySnapshotStore(), app.WithTailPollInterval(time.Millisecond))
+		_, _ = svc.CreateAccount(app.CreateAccountCommand{AccountID: "acc-1"})
+		for i := 0; i < 10; i++ {
+			_ = svc.Deposit(app.DepositMoneyCommand{AccountID: "acc-1", Amount: decimal.NewFromInt(1), Currency: shared.USD})
+		}
+		handler := httpapi.NewServer(unauthenticated(svc), httpapi.WithStreamBufferSize(2))
+
+		w := newGatedWriter()
+		req := httptest.NewRequest("GET", "/v1/accounts/acc-1/stream?after=0", nil)
+		done := make(chan struct{})
+		go func() {
+			handler.ServeHTTP(w, req)
+			close(done)
+		}()
+		time.Sleep(50 * time.Millisecond) // let the feed overrun the buffer
+		w.open()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("stream was not closed for a slow consumer")
+		}
+
+		stream := &sseReader{scanner: bufio.NewScanner(strings.NewReader(w.body()))}
+		var delivered []int
+		for {
+			msg := stream.nextEvent(t)
+			if msg.Event == "error" {
+				if code := decodeData[apiError](t, msg).Error.Code; code != "slow_consumer" {
+					t.Errorf("expected slow_consumer, got %s", code)
+				}
+				break
+			}
+			id, _ := strconv.Atoi(msg.ID)
+			delivered = append(delivered, id)
+		}
+		if len(delivered) == 0 || len(delivered) >= 11 {
+			t.Errorf("expected some but not all events before the disconnect, got %v", delivered)
+		}
+		for i, id := range delivered {
+			if id != i+1 {
+				t.Errorf("events before the disconnect must be contiguous for Last-Event-ID to resume, got %v", delivered)
+				break
+			}
+		}
+	})
+}
+
+// gatedWriter is a streaming ResponseWriter whose writes block until open is
+// called, like a client that has stopped reading.
+type gatedWriter struct {
+	header http.Header
+	gate   chan struct{}
+	mu     sync.Mutex
+	buf    strings.Builder
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{header: http.Header{}, gate: make(chan struct{})}
+}
+
+func (w *gatedWriter) Header() http.Header { return w.header }
+func (w *gatedWriter) WriteHeader(int)     {}
+func (w *gatedWriter) Flush()              {}
+func (w *gatedWriter) open()               { close(w.gate) }
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	<-w.gate
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gatedWriter) body() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
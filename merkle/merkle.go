@@ -0,0 +1,143 @@
+// Package merkle implements the RFC 6962 Merkle tree used for ledger checkpoints:
+// computing a root over the global event log, building an inclusion proof for
+// one event, and verifying such a proof offline against a published root.
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidProof = errors.New("invalid inclusion proof")
+
+// LeafHash hashes leaf data with the 0x00 domain-separation prefix.
+func LeafHash(data []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x00})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// nodeHash hashes two child hashes with the 0x01 domain-separation prefix.
+func nodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x01})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// Root returns the Merkle tree hash over the given leaf hashes. The root of an
+// empty tree is the hash of the empty string.
+func Root(leaves [][]byte) []byte {
+	if len(leaves) == 0 {
+		sum := sha256.Sum256(nil)
+		return sum[:]
+	}
+	return subtreeRoot(leaves)
+}
+
+func subtreeRoot(leaves [][]byte) []byte {
+	if len(leaves) == 1 {
+		return leaves[0]
+	}
+	k := splitPoint(len(leaves))
+	return nodeHash(subtreeRoot(leaves[:k]), subtreeRoot(leaves[k:]))
+}
+
+// AuditPath returns the sibling hashes needed to recompute the root from the
+// leaf at index, ordered from the leaf upwards.
+func AuditPath(leaves [][]byte, index int) ([][]byte, error) {
+	if index < 0 || index >= len(leaves) {
+		return nil, fmt.Errorf("leaf index %d out of range for tree of size %d", index, len(leaves))
+	}
+	return auditPath(leaves, index), nil
+}
+
+func auditPath(leaves [][]byte, index int) [][]byte {
+	if len(leaves) <= 1 {
+		return nil
+	}
+	k := splitPoint(len(leaves))
+	if index < k {
+		return append(auditPath(leaves[:k], index), subtreeRoot(leaves[k:]))
+	}
+	return append(auditPath(leaves[k:], index-k), subtreeRoot(leaves[:k]))
+}
+
+// VerifyInclusion checks that leafHash sits at index in a tree of treeSize
+// leaves whose root is root, using the audit path produced by AuditPath.
+func VerifyInclusion(leafHash []byte, index, treeSize int64, path [][]byte, root []byte) error {
+	if index < 0 || index >= treeSize {
+		return fmt.Errorf("%w: leaf index %d out of range for tree of size %d", ErrInvalidProof, index, treeSize)
+	}
+
+	fn, sn := index, treeSize-1
+	r := leafHash
+	for _, p := range path {
+		if sn == 0 {
+			return fmt.Errorf("%w: audit path is longer than expected", ErrInvalidProof)
+		}
+		if fn&1 == 1 || fn == sn {
+			r = nodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = nodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 {
+		return fmt.Errorf("%w: audit path is shorter than expected", ErrInvalidProof)
+	}
+	if !bytes.Equal(r, root) {
+		return fmt.Errorf("%w: computed root %x does not match %x", ErrInvalidProof, r, root)
+	}
+	return nil
+}
+
+// splitPoint returns the largest power of two strictly less than n (n > 1).
+func splitPoint(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+// InclusionProof is the self-contained, JSON-serialisable proof that one ledger
+// event is included in the tree summarised by a checkpoint root.
+type InclusionProof struct {
+	EventID   string   `json:"eventId"`
+	EventHash string   `json:"eventHash"` // the event's stream-chain hash; the leaf data
+	LeafIndex int64    `json:"leafIndex"` // global position - 1
+	TreeSize  int64    `json:"treeSize"`
+	RootHash  string   `json:"rootHash"`
+	AuditPath []string `json:"auditPath"`
+}
+
+// Verify checks the proof against publishedRoot (hex). It needs nothing but the
+// proof itself, so counterparties can run it without access to the ledger.
+func (p InclusionProof) Verify(publishedRoot string) error {
+	if publishedRoot != p.RootHash {
+		return fmt.Errorf("%w: proof is for root %s, not the published root %s", ErrInvalidProof, p.RootHash, publishedRoot)
+	}
+	root, err := hex.DecodeString(publishedRoot)
+	if err != nil {
+		return fmt.Errorf("%w: malformed root hash: %v", ErrInvalidProof, err)
+	}
+	path := make([][]byte, len(p.AuditPath))
+	for i, h := range p.AuditPath {
+		path[i], err = hex.DecodeString(h)
+		if err != nil {
+			return fmt.Errorf("%w: malformed audit path entry %d: %v", ErrInvalidProof, i, err)
+		}
+	}
+	return VerifyInclusion(LeafHash([]byte(p.EventHash)), p.LeafIndex, p.TreeSize, path, root)
+}
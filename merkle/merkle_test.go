@@ -0,0 +1,86 @@
+package merkle_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+
+	"financial-ledger/merkle"
+)
+
+func leaves(n int) [][]byte {
+	out := make([][]byte, n)
+	for i := range out {
+		out[i] = merkle.LeafHash([]byte(fmt.Sprintf("event-%d", i)))
+	}
+	return out
+}
+
+func TestAuditPathVerifiesForEveryLeaf(t *testing.T) {
+	for size := 1; size <= 17; size++ {
+		ls := leaves(size)
+		root := merkle.Root(ls)
+		for i := 0; i < size; i++ {
+			path, err := merkle.AuditPath(ls, i)
+			if err != nil {
+				t.Fatalf("AuditPath(size=%d, index=%d) failed: %v", size, i, err)
+			}
+			if err := merkle.VerifyInclusion(ls[i], int64(i), int64(size), path, root); err != nil {
+				t.Errorf("VerifyInclusion(size=%d, index=%d) failed: %v", size, i, err)
+			}
+		}
+	}
+}
+
+func TestVerifyInclusionRejectsTampering(t *testing.T) {
+	ls := leaves(7)
+	root := merkle.Root(ls)
+	path, _ := merkle.AuditPath(ls, 3)
+
+	t.Run("WrongLeaf", func(t *testing.T) {
+		err := merkle.VerifyInclusion(ls[4], 3, 7, path, root)
+		if !errors.Is(err, merkle.ErrInvalidProof) {
+			t.Errorf("expected ErrInvalidProof, got %v", err)
+		}
+	})
+
+	t.Run("WrongIndex", func(t *testing.T) {
+		err := merkle.VerifyInclusion(ls[3], 2, 7, path, root)
+		if !errors.Is(err, merkle.ErrInvalidProof) {
+			t.Errorf("expected ErrInvalidProof, got %v", err)
+		}
+	})
+
+	t.Run("TruncatedPath", func(t *testing.T) {
+		err := merkle.VerifyInclusion(ls[3], 3, 7, path[:len(path)-1], root)
+		if !errors.Is(err, merkle.ErrInvalidProof) {
+			t.Errorf("expected ErrInvalidProof, got %v", err)
+		}
+	})
+}
+
+func TestInclusionProof_Verify(t *testing.T) {
+	data := []string{"h0", "h1", "h2", "h3", "h4"}
+	ls := make([][]byte, len(data))
+	for i, d := range data {
+		ls[i] = merkle.LeafHash([]byte(d))
+	}
+	root := hex.EncodeToString(merkle.Root(ls))
+	path, _ := merkle.AuditPath(ls, 2)
+	proof := merkle.InclusionProof{EventHash: "h2", LeafIndex: 2, TreeSize: 5, RootHash: root}
+	for _, p := range path {
+		proof.AuditPath = append(proof.AuditPath, hex.EncodeToString(p))
+	}
+
+	if err := proof.Verify(root); err != nil {
+		t.Errorf("expected proof to verify, got %v", err)
+	}
+	if err := proof.Verify(hex.EncodeToString(merkle.Root(ls[:4]))); !errors.Is(err, merkle.ErrInvalidProof) {
+		t.Errorf("expected ErrInvalidProof against a different root, got %v", err)
+	}
+	proof.EventHash = "forged"
+	if err := proof.Verify(root); !errors.Is(err, merkle.ErrInvalidProof) {
+		t.Errorf("expected ErrInvalidProof for forged event hash, got %v", err)
+	}
+}
@@ -0,0 +1,308 @@
+// Package monitoring evaluates anti-money-laundering rules against committed
+// events and raises alerts for compliance to review.
+//
+// The Monitor is a projection: as it reads the global log it keeps, per
+// account, just enough recent activity to evaluate its Rules, and adds an
+// alert to a store.AlertStore whenever one matches. Alert IDs are derived
+// from the rule and the event that completed the match, so rebuilding the
+// projection raises no duplicates and leaves existing dispositions alone.
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/events"
+	"financial-ledger/shared"
+	"financial-ledger/store"
+)
+
+// ProjectionName is the name the Monitor registers under with the runner.
+const ProjectionName = "monitoring"
+
+var ErrAlertClosed = errors.New("alert already has a final disposition")
+
+type Option func(*Monitor)
+
+// WithClock replaces time.Now, for tests.
+func WithClock(now func() time.Time) Option {
+	return func(m *Monitor) {
+		if now != nil {
+			m.now = now
+		}
+	}
+}
+
+// movement is money entering or leaving an account.
+type movement struct {
+	eventID  string
+	at       time.Time
+	amount   decimal.Decimal
+	currency shared.Currency
+}
+
+type accountState struct {
+	version      int
+	lastActivity time.Time
+
+	// candidates holds, per structuring or rapid-movement rule, the deposits
+	// or inflows that may still complete a match.
+	candidates map[string][]movement
+}
+
+type Monitor struct {
+	sync.Mutex
+	store    store.AlertStore
+	rules    []Rule
+	accounts map[string]*accountState
+	pending  []store.Alert // raised but not yet stored
+	now      func() time.Time
+
+	reviews sync.Mutex
+}
+
+// NewMonitor returns a Monitor evaluating rules, which must be valid.
+func NewMonitor(as store.AlertStore, rules Rules, opts ...Option) *Monitor {
+	m := &Monitor{
+		store:    as,
+		rules:    slices.Clone(rules.Rules),
+		accounts: make(map[string]*accountState),
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Rules returns the rules being evaluated.
+func (m *Monitor) Rules() []Rule {
+	return slices.Clone(m.rules)
+}
+
+// --- Projection ---
+
+func (m *Monitor) Name() string {
+	return ProjectionName
+}
+
+// Apply evaluates every rule against event. Events at or below the account's
+// version have been evaluated already and are skipped. Alerts that could not
+// be stored are kept and stored before the next event is evaluated.
+func (m *Monitor) Apply(ctx context.Context, event events.Event) error {
+	m.Lock()
+	defer m.Unlock()
+	if err := m.flush(ctx); err != nil {
+		return err
+	}
+
+	base := event.GetBase()
+	st, ok := m.accounts[base.AggregateID]
+	if !ok {
+		st = &accountState{candidates: make(map[string][]movement)}
+		m.accounts[base.AggregateID] = st
+	}
+	if base.Version <= st.version {
+		return nil
+	}
+
+	in, out := movementsOf(event)
+	for _, rule := range m.rules {
+		if alert, ok := m.evaluate(rule, st, base, in, out); ok {
+			m.pending = append(m.pending, alert)
+		}
+	}
+	st.version = base.Version
+	st.lastActivity = base.Timestamp
+	return m.flush(ctx)
+}
+
+// Reset forgets the activity seen so far. Alerts already raised are kept.
+func (m *Monitor) Reset(ctx context.Context) error {
+	m.Lock()
+	defer m.Unlock()
+	m.accounts = make(map[string]*accountState)
+	return nil
+}
+
+func (m *Monitor) flush(ctx context.Context) error {
+	for len(m.pending) > 0 {
+		alert := m.pending[0]
+		added, err := m.store.AddAlert(ctx, alert)
+		if err != nil {
+			return fmt.Errorf("monitoring: failed to store alert %s: %w", alert.ID, err)
+		}
+		if added {
+			log.Printf("Warning: Monitoring rule %s raised alert %s on account %s: %s", alert.RuleID, alert.ID, alert.AccountID, alert.Description)
+		}
+		m.pending = m.pending[1:]
+	}
+	return nil
+}
+
+// movementsOf returns the money event brings into or takes out of the
+// account whose stream it belongs to. Conversions and reversals move neither.
+func movementsOf(event events.Event) (in, out *movement) {
+	base := event.GetBase()
+	move := func(amount decimal.Decimal, currency shared.Currency) *movement {
+		return &movement{eventID: base.EventID.String(), at: base.Timestamp, amount: amount, currency: currency}
+	}
+	switch e := event.(type) {
+	case events.DepositMadeEvent:
+		return move(e.Amount, e.Currency), nil
+	case events.WithdrawalMadeEvent:
+		return nil, move(e.Amount, e.Currency)
+	case events.MoneyTransferredEvent:
+		if base.AggregateID == e.SourceAccountID {
+			return nil, move(e.DebitedAmount, e.DebitedCurrency)
+		}
+		return move(e.CreditedAmount, e.CreditedCurrency), nil
+	}
+	return nil, nil
+}
+
+func (m *Monitor) evaluate(rule Rule, st *accountState, base events.BaseEvent, in, out *movement) (store.Alert, bool) {
+	moved := in
+	if moved == nil {
+		moved = out
+	}
+	if moved == nil || !rule.matchesCurrency(moved.currency) {
+		return store.Alert{}, false
+	}
+	raise := func(description string, triggers ...movement) (store.Alert, bool) {
+		ids := make([]string, len(triggers))
+		for i, t := range triggers {
+			ids[i] = t.eventID
+		}
+		return store.Alert{
+			ID:          rule.ID + ":" + base.EventID.String(),
+			RuleID:      rule.ID,
+			RuleType:    string(rule.Type),
+			AccountID:   base.AggregateID,
+			Description: description,
+			EventIDs:    ids,
+			DetectedAt:  base.Timestamp,
+			Status:      store.AlertOpen,
+		}, true
+	}
+
+	switch rule.Type {
+	case RuleStructuring:
+		floor := rule.Threshold.Sub(rule.Margin)
+		if base.Type != events.DepositMadeType || in.amount.LessThan(floor) || in.amount.GreaterThanOrEqual(rule.Threshold) {
+			return store.Alert{}, false
+		}
+		near := append(st.within(rule.ID, time.Duration(rule.Window), in.at), *in)
+		if len(near) < rule.Count {
+			st.candidates[rule.ID] = near
+			return store.Alert{}, false
+		}
+		delete(st.candidates, rule.ID)
+		return raise(fmt.Sprintf("%d deposits within %s, each between %s and %s %s",
+			len(near), time.Duration(rule.Window), floor, rule.Threshold, in.currency), near...)
+
+	case RuleRapidMovement:
+		if in != nil {
+			if in.amount.GreaterThanOrEqual(rule.MinAmount) {
+				st.candidates[rule.ID] = append(st.within(rule.ID, time.Duration(rule.Window), in.at), *in)
+			}
+			return store.Alert{}, false
+		}
+		inflows := st.within(rule.ID, time.Duration(rule.Window), out.at)
+		for i, inflow := range inflows {
+			if inflow.currency == out.currency && out.amount.GreaterThanOrEqual(inflow.amount.Mul(rule.ratio())) {
+				st.candidates[rule.ID] = slices.Delete(inflows, i, i+1)
+				return raise(fmt.Sprintf("%s %s received and %s %s sent out %s later",
+					inflow.amount, inflow.currency, out.amount, out.currency, out.at.Sub(inflow.at).Round(time.Second)), inflow, *out)
+			}
+		}
+		st.candidates[rule.ID] = inflows
+
+	case RuleRoundAmount:
+		if base.Type != events.MoneyTransferredType || out == nil || out.amount.LessThan(rule.MinAmount) || !out.amount.Mod(rule.Multiple).IsZero() {
+			return store.Alert{}, false
+		}
+		return raise(fmt.Sprintf("transfer of %s %s, a round multiple of %s", out.amount, out.currency, rule.Multiple), *out)
+
+	case RuleDormantReactivation:
+		idle := moved.at.Sub(st.lastActivity)
+		if st.lastActivity.IsZero() || idle < time.Duration(rule.DormantFor) || moved.amount.LessThan(rule.MinAmount) {
+			return store.Alert{}, false
+		}
+		direction := "in"
+		if out != nil {
+			direction = "out"
+		}
+		return raise(fmt.Sprintf("%s %s moved %s after %d days without activity", moved.amount, moved.currency, direction, int(idle.Hours()/24)), *moved)
+	}
+	return store.Alert{}, false
+}
+
+// within returns the rule's candidates no older than window at now.
+func (st *accountState) within(ruleID string, window time.Duration, now time.Time) []movement {
+	var kept []movement
+	for _, c := range st.candidates[ruleID] {
+		if now.Sub(c.at) <= window {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// --- Review ---
+
+// Alerts returns alerts with the given status, or every alert if status is
+// empty, oldest first.
+func (m *Monitor) Alerts(ctx context.Context, status store.AlertStatus) ([]store.Alert, error) {
+	return m.store.ListAlerts(ctx, status)
+}
+
+func (m *Monitor) Alert(ctx context.Context, id string) (store.Alert, error) {
+	return m.store.GetAlert(ctx, id)
+}
+
+// Disposition records actor's review of an alert. status is
+// store.AlertEscalated, store.AlertCleared or store.AlertReported, and a note
+// explaining it is required. Cleared and reported alerts are closed and take
+// no further dispositions.
+func (m *Monitor) Disposition(ctx context.Context, id string, status store.AlertStatus, actor, note string) (store.Alert, error) {
+	switch status {
+	case store.AlertEscalated, store.AlertCleared, store.AlertReported:
+	default:
+		return store.Alert{}, fmt.Errorf("unknown disposition %q: use escalated, cleared or reported", status)
+	}
+	if strings.TrimSpace(note) == "" {
+		return store.Alert{}, fmt.Errorf("a disposition of alert %s needs a note", id)
+	}
+	if actor == "" {
+		return store.Alert{}, fmt.Errorf("a disposition of alert %s must name who made it", id)
+	}
+
+	m.reviews.Lock()
+	defer m.reviews.Unlock()
+	alert, err := m.store.GetAlert(ctx, id)
+	if err != nil {
+		return store.Alert{}, err
+	}
+	if alert.Status.Closed() {
+		return alert, fmt.Errorf("%w: %s is %s", ErrAlertClosed, id, alert.Status)
+	}
+	if alert.Status == status {
+		return alert, fmt.Errorf("alert %s is already %s", id, status)
+	}
+	alert.Status = status
+	alert.Dispositions = append(alert.Dispositions, store.AlertDisposition{Status: status, Actor: actor, Note: note, At: m.now().UTC()})
+	if err := m.store.UpdateAlert(ctx, alert); err != nil {
+		return store.Alert{}, fmt.Errorf("failed to record disposition of alert %s: %w", id, err)
+	}
+	log.Printf("Alert %s %s by %s: %s", id, status, actor, note)
+	return alert, nil
+}
@@ -0,0 +1,272 @@
+package monitoring_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/events"
+	"financial-ledger/monitoring"
+	"financial-ledger/shared"
+	"financial-ledger/store"
+)
+
+var start = time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func base(accountID string, version int, eventType events.EventType, at time.Time) events.BaseEvent {
+	b := events.NewBaseEvent(accountID, version, eventType)
+	b.Timestamp = at
+	return b
+}
+
+func deposit(accountID string, version int, amount string, at time.Time) events.Event {
+	return events.DepositMadeEvent{BaseEvent: base(accountID, version, events.DepositMadeType, at), Amount: dec(amount), Currency: shared.USD}
+}
+
+func withdrawal(accountID string, version int, amount string, at time.Time) events.Event {
+	return events.WithdrawalMadeEvent{BaseEvent: base(accountID, version, events.WithdrawalMadeType, at), Amount: dec(amount), Currency: shared.USD}
+}
+
+func transferOut(accountID string, version int, amount string, at time.Time) events.Event {
+	return events.MoneyTransferredEvent{
+		BaseEvent:        base(accountID, version, events.MoneyTransferredType, at),
+		SourceAccountID:  accountID,
+		TargetAccountID:  "elsewhere",
+		DebitedAmount:    dec(amount),
+		DebitedCurrency:  shared.USD,
+		CreditedAmount:   dec(amount),
+		CreditedCurrency: shared.USD,
+		ExchangeRate:     decimal.NewFromInt(1),
+	}
+}
+
+func apply(t *testing.T, m *monitoring.Monitor, evs ...events.Event) {
+	t.Helper()
+	for _, e := range evs {
+		if err := m.Apply(context.Background(), e); err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+	}
+}
+
+func alerts(t *testing.T, m *monitoring.Monitor) []store.Alert {
+	t.Helper()
+	all, err := m.Alerts(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Alerts failed: %v", err)
+	}
+	return all
+}
+
+func TestMonitor_Rules(t *testing.T) {
+	newMonitor := func(rule monitoring.Rule) *monitoring.Monitor {
+		return monitoring.NewMonitor(store.NewInMemoryAlertStore(), monitoring.Rules{Rules: []monitoring.Rule{rule}})
+	}
+
+	t.Run("Structuring", func(t *testing.T) {
+		m := newMonitor(monitoring.Rule{ID: "st", Type: monitoring.RuleStructuring, Threshold: dec("10000"), Margin: dec("1000"), Count: 3, Window: monitoring.Duration(24 * time.Hour)})
+		apply(t, m,
+			deposit("acc-1", 1, "9500", start),
+			deposit("acc-1", 2, "12000", start.Add(time.Hour)), // above the threshold
+			deposit("acc-1", 3, "9900", start.Add(2*time.Hour)),
+		)
+		if got := alerts(t, m); len(got) != 0 {
+			t.Fatalf("expected no alert after two deposits, got %+v", got)
+		}
+		third := deposit("acc-1", 4, "9000", start.Add(3*time.Hour))
+		apply(t, m, third)
+		got := alerts(t, m)
+		if len(got) != 1 || len(got[0].EventIDs) != 3 || got[0].EventIDs[2] != third.GetBase().EventID.String() {
+			t.Fatalf("expected one alert naming the three deposits, got %+v", got)
+		}
+		if got[0].AccountID != "acc-1" || got[0].Status != store.AlertOpen || !got[0].DetectedAt.Equal(third.GetBase().Timestamp) {
+			t.Errorf("unexpected alert %+v", got[0])
+		}
+
+		// Deposits spread over more than the window do not match.
+		apply(t, m,
+			deposit("acc-2", 1, "9500", start),
+			deposit("acc-2", 2, "9500", start.Add(20*time.Hour)),
+			deposit("acc-2", 3, "9500", start.Add(30*time.Hour)),
+		)
+		if got := alerts(t, m); len(got) != 1 {
+			t.Errorf("expected deposits outside the window not to match, got %+v", got)
+		}
+	})
+
+	t.Run("RapidMovement", func(t *testing.T) {
+		m := newMonitor(monitoring.Rule{ID: "rm", Type: monitoring.RuleRapidMovement, MinAmount: dec("5000"), Window: monitoring.Duration(time.Hour)})
+		apply(t, m,
+			deposit("acc-1", 1, "8000", start),
+			withdrawal("acc-1", 2, "7000", start.Add(10*time.Minute)), // under 90%
+			deposit("acc-2", 1, "8000", start),
+			withdrawal("acc-2", 2, "8000", start.Add(2*time.Hour)), // too late
+		)
+		if got := alerts(t, m); len(got) != 0 {
+			t.Fatalf("expected no alert, got %+v", got)
+		}
+		apply(t, m, transferOut("acc-1", 3, "7500", start.Add(20*time.Minute)))
+		got := alerts(t, m)
+		if len(got) != 1 || got[0].AccountID != "acc-1" || len(got[0].EventIDs) != 2 {
+			t.Fatalf("expected one alert naming the inflow and outflow, got %+v", got)
+		}
+		// The inflow has been matched and does not match again.
+		apply(t, m, withdrawal("acc-1", 4, "7500", start.Add(30*time.Minute)))
+		if got := alerts(t, m); len(got) != 1 {
+			t.Errorf("expected the inflow to be consumed, got %+v", got)
+		}
+	})
+
+	t.Run("RoundAmount", func(t *testing.T) {
+		m := newMonitor(monitoring.Rule{ID: "ra", Type: monitoring.RuleRoundAmount, Currency: shared.USD, MinAmount: dec("5000"), Multiple: dec("1000")})
+		apply(t, m,
+			transferOut("acc-1", 1, "4000", start),
+			transferOut("acc-1", 2, "5500", start),
+			withdrawal("acc-1", 3, "6000", start),
+			deposit("acc-1", 4, "6000", start),
+		)
+		if got := alerts(t, m); len(got) != 0 {
+			t.Fatalf("expected no alert, got %+v", got)
+		}
+		apply(t, m, transferOut("acc-1", 5, "12000.00", start))
+		if got := alerts(t, m); len(got) != 1 || got[0].RuleType != string(monitoring.RuleRoundAmount) {
+			t.Errorf("expected one round-amount alert, got %+v", got)
+		}
+	})
+
+	t.Run("DormantReactivation", func(t *testing.T) {
+		m := newMonitor(monitoring.Rule{ID: "dr", Type: monitoring.RuleDormantReactivation, MinAmount: dec("1000"), DormantFor: monitoring.Duration(90 * 24 * time.Hour)})
+		apply(t, m,
+			deposit("acc-1", 1, "5000", start),
+			withdrawal("acc-1", 2, "100", start.Add(100*24*time.Hour)), // below minAmount
+			withdrawal("acc-1", 3, "2000", start.Add(120*24*time.Hour)),
+		)
+		if got := alerts(t, m); len(got) != 0 {
+			t.Fatalf("expected the small withdrawal to reset dormancy, got %+v", got)
+		}
+		apply(t, m, withdrawal("acc-1", 4, "2000", start.Add(300*24*time.Hour)))
+		if got := alerts(t, m); len(got) != 1 {
+			t.Errorf("expected one alert, got %+v", got)
+		}
+	})
+}
+
+func TestMonitor_Redelivery(t *testing.T) {
+	ctx := context.Background()
+	m := monitoring.NewMonitor(store.NewInMemoryAlertStore(), monitoring.Rules{Rules: []monitoring.Rule{
+		{ID: "ra", Type: monitoring.RuleRoundAmount, Multiple: dec("1000")},
+	}})
+	log := []events.Event{deposit("acc-1", 1, "5000", start), transferOut("acc-1", 2, "3000", start)}
+	apply(t, m, log...)
+	apply(t, m, log...)
+	got := alerts(t, m)
+	if len(got) != 1 {
+		t.Fatalf("expected redelivered events to raise no new alert, got %+v", got)
+	}
+	if _, err := m.Disposition(ctx, got[0].ID, store.AlertCleared, "carol", "payroll"); err != nil {
+		t.Fatalf("Disposition failed: %v", err)
+	}
+
+	if err := m.Reset(ctx); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	apply(t, m, log...)
+	got = alerts(t, m)
+	if len(got) != 1 || got[0].Status != store.AlertCleared {
+		t.Errorf("expected a rebuild to keep the cleared alert, got %+v", got)
+	}
+}
+
+func TestMonitor_Disposition(t *testing.T) {
+	ctx := context.Background()
+	now := start.Add(48 * time.Hour)
+	m := monitoring.NewMonitor(store.NewInMemoryAlertStore(), monitoring.Rules{Rules: []monitoring.Rule{
+		{ID: "ra", Type: monitoring.RuleRoundAmount, Multiple: dec("1000")},
+	}}, monitoring.WithClock(func() time.Time { return now }))
+	apply(t, m, transferOut("acc-1", 1, "1000", start), transferOut("acc-1", 2, "2000", start))
+	open := alerts(t, m)
+	id := open[0].ID
+
+	for name, tc := range map[string]struct {
+		status      store.AlertStatus
+		actor, note string
+	}{
+		"UnknownStatus": {store.AlertOpen, "carol", "why"},
+		"NoNote":        {store.AlertCleared, "carol", " "},
+		"NoActor":       {store.AlertCleared, "", "why"},
+	} {
+		if _, err := m.Disposition(ctx, id, tc.status, tc.actor, tc.note); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := m.Disposition(ctx, "missing", store.AlertCleared, "carol", "why"); !errors.Is(err, store.ErrAlertNotFound) {
+		t.Errorf("expected ErrAlertNotFound, got %v", err)
+	}
+
+	if _, err := m.Disposition(ctx, id, store.AlertEscalated, "carol", "ask the branch"); err != nil {
+		t.Fatalf("escalate failed: %v", err)
+	}
+	if _, err := m.Disposition(ctx, id, store.AlertEscalated, "carol", "again"); err == nil {
+		t.Error("expected escalating an escalated alert to fail")
+	}
+	alert, err := m.Disposition(ctx, id, store.AlertReported, "dave", "SAR filed")
+	if err != nil {
+		t.Fatalf("report failed: %v", err)
+	}
+	if len(alert.Dispositions) != 2 || alert.Dispositions[1].Actor != "dave" || !alert.Dispositions[1].At.Equal(now) {
+		t.Errorf("unexpected dispositions %+v", alert.Dispositions)
+	}
+	if _, err := m.Disposition(ctx, id, store.AlertCleared, "carol", "mistake"); !errors.Is(err, monitoring.ErrAlertClosed) {
+		t.Errorf("expected ErrAlertClosed, got %v", err)
+	}
+
+	reported, _ := m.Alerts(ctx, store.AlertReported)
+	stillOpen, _ := m.Alerts(ctx, store.AlertOpen)
+	if len(reported) != 1 || len(stillOpen) != 1 || stillOpen[0].ID == id {
+		t.Errorf("expected one reported and one open alert, got %+v and %+v", reported, stillOpen)
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	write := func(t *testing.T, contents string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "rules.json")
+		if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	rules, err := monitoring.LoadRules(write(t, `{"rules": [
+		{"id": "st", "type": "structuring", "currency": "USD", "threshold": "10000", "margin": "1000", "count": 3, "window": "24h"},
+		{"id": "dr", "type": "dormant-reactivation", "dormantFor": "2160h"}
+	]}`))
+	if err != nil {
+		t.Fatalf("LoadRules failed: %v", err)
+	}
+	if len(rules.Rules) != 2 || time.Duration(rules.Rules[0].Window) != 24*time.Hour || !rules.Rules[0].Threshold.Equal(dec("10000")) {
+		t.Errorf("unexpected rules %+v", rules)
+	}
+
+	for name, contents := range map[string]string{
+		"DuplicateID":   `{"rules": [{"id": "a", "type": "round-amount", "multiple": 100}, {"id": "a", "type": "round-amount", "multiple": 100}]}`,
+		"UnknownType":   `{"rules": [{"id": "a", "type": "smurfing"}]}`,
+		"UnknownField":  `{"rules": [{"id": "a", "type": "round-amount", "multiple": 100, "limit": 5}]}`,
+		"MissingWindow": `{"rules": [{"id": "a", "type": "rapid-movement"}]}`,
+		"BadDuration":   `{"rules": [{"id": "a", "type": "dormant-reactivation", "dormantFor": "90 days"}]}`,
+		"MarginTooWide": `{"rules": [{"id": "a", "type": "structuring", "threshold": 100, "margin": 100, "count": 2, "window": "1h"}]}`,
+	} {
+		if _, err := monitoring.LoadRules(write(t, contents)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package monitoring
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/shared"
+)
+
+// RuleType selects what a rule looks for.
+type RuleType string
+
+const (
+	// RuleStructuring matches Count deposits within Window, each at least
+	// Threshold - Margin but below Threshold: amounts kept just under a
+	// reporting threshold.
+	RuleStructuring RuleType = "structuring"
+
+	// RuleRapidMovement matches money leaving an account within Window of
+	// arriving: an inflow of at least MinAmount followed by an outflow of at
+	// least Ratio times it.
+	RuleRapidMovement RuleType = "rapid-movement"
+
+	// RuleRoundAmount matches outgoing transfers of at least MinAmount that
+	// are an exact multiple of Multiple.
+	RuleRoundAmount RuleType = "round-amount"
+
+	// RuleDormantReactivation matches a deposit, withdrawal or transfer of at
+	// least MinAmount on an account with no activity for DormantFor.
+	RuleDormantReactivation RuleType = "dormant-reactivation"
+)
+
+// defaultRatio is the share of an inflow that must leave again for
+// RuleRapidMovement when the rule does not set Ratio.
+var defaultRatio = decimal.RequireFromString("0.9")
+
+// Duration is a time.Duration written in rule files as a string such as "24h".
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"24h\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Rule is one entry of a rule file. Which fields apply depends on Type; see
+// the RuleType constants. Currency limits the rule to one currency, and
+// amounts are compared in the currency of the money moved.
+type Rule struct {
+	ID       string          `json:"id"`
+	Type     RuleType        `json:"type"`
+	Currency shared.Currency `json:"currency,omitempty"`
+
+	MinAmount  decimal.Decimal `json:"minAmount"`
+	Threshold  decimal.Decimal `json:"threshold"`
+	Margin     decimal.Decimal `json:"margin"`
+	Count      int             `json:"count,omitempty"`
+	Window     Duration        `json:"window,omitempty"`
+	Ratio      decimal.Decimal `json:"ratio"`
+	Multiple   decimal.Decimal `json:"multiple"`
+	DormantFor Duration        `json:"dormantFor,omitempty"`
+}
+
+// Rules is the content of a rule file.
+type Rules struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Validate checks that every rule has a unique ID and the parameters its
+// type needs.
+func (r Rules) Validate() error {
+	seen := make(map[string]bool)
+	for i, rule := range r.Rules {
+		if rule.ID == "" {
+			return fmt.Errorf("rule %d has no ID", i+1)
+		}
+		if seen[rule.ID] {
+			return fmt.Errorf("rule ID %q is used twice", rule.ID)
+		}
+		seen[rule.ID] = true
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("rule %q: %w", rule.ID, err)
+		}
+	}
+	return nil
+}
+
+func (r Rule) validate() error {
+	if r.MinAmount.IsNegative() {
+		return fmt.Errorf("minAmount cannot be negative")
+	}
+	switch r.Type {
+	case RuleStructuring:
+		if !r.Threshold.IsPositive() || !r.Margin.IsPositive() || r.Margin.GreaterThanOrEqual(r.Threshold) {
+			return fmt.Errorf("structuring needs a positive threshold and a positive margin below it")
+		}
+		if r.Count < 2 {
+			return fmt.Errorf("structuring needs a count of at least 2, got %d", r.Count)
+		}
+		if r.Window <= 0 {
+			return fmt.Errorf("structuring needs a window")
+		}
+	case RuleRapidMovement:
+		if r.Window <= 0 {
+			return fmt.Errorf("rapid-movement needs a window")
+		}
+		if r.Ratio.IsNegative() || r.Ratio.GreaterThan(decimal.NewFromInt(1)) {
+			return fmt.Errorf("ratio must be between 0 and 1, got %s", r.Ratio)
+		}
+	case RuleRoundAmount:
+		if !r.Multiple.IsPositive() {
+			return fmt.Errorf("round-amount needs a positive multiple")
+		}
+	case RuleDormantReactivation:
+		if r.DormantFor <= 0 {
+			return fmt.Errorf("dormant-reactivation needs dormantFor")
+		}
+	default:
+		return fmt.Errorf("unknown rule type %q: use structuring, rapid-movement, round-amount or dormant-reactivation", r.Type)
+	}
+	return nil
+}
+
+func (r Rule) ratio() decimal.Decimal {
+	if r.Ratio.IsZero() {
+		return defaultRatio
+	}
+	return r.Ratio
+}
+
+func (r Rule) matchesCurrency(c shared.Currency) bool {
+	return r.Currency == "" || r.Currency == c
+}
+
+// LoadRules reads and validates a rule file.
+func LoadRules(path string) (Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Rules{}, fmt.Errorf("failed to read monitoring rules: %w", err)
+	}
+	var rules Rules
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&rules); err != nil {
+		return Rules{}, fmt.Errorf("invalid monitoring rules %s: %w", path, err)
+	}
+	if err := rules.Validate(); err != nil {
+		return Rules{}, fmt.Errorf("invalid monitoring rules %s: %w", path, err)
+	}
+	return rules, nil
+}
@@ -0,0 +1,107 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"financial-ledger/events"
+)
+
+// Message is an event as handed to a Publisher. ID is the event ID and is the
+// same every time the event is published, so consumers deduplicate on it.
+type Message struct {
+	ID          string           `json:"id"`
+	Position    int64            `json:"position"`
+	AggregateID string           `json:"aggregateId"`
+	Version     int              `json:"version"`
+	Type        events.EventType `json:"type"`
+	Timestamp   time.Time        `json:"timestamp"`
+	Event       json.RawMessage  `json:"event"`
+}
+
+// NewMessage wraps a committed event, serialising it exactly as stored.
+func NewMessage(event events.Event) (Message, error) {
+	base := event.GetBase()
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return Message{}, fmt.Errorf("failed to serialise event %s: %w", base.EventID, err)
+	}
+	return Message{
+		ID:          base.EventID.String(),
+		Position:    base.Position,
+		AggregateID: base.AggregateID,
+		Version:     base.Version,
+		Type:        base.Type,
+		Timestamp:   base.Timestamp,
+		Event:       payload,
+	}, nil
+}
+
+// Publisher sends messages to another system. Publish must not return nil
+// until the message is durably accepted; the relay then removes it from the
+// outbox. Any error leaves the message to be published again later.
+type Publisher interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+// WriterPublisher writes each message as one line of JSON.
+type WriterPublisher struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterPublisher publishes to w, for example os.Stdout.
+func NewWriterPublisher(w io.Writer) *WriterPublisher {
+	return &WriterPublisher{w: w}
+}
+
+func (p *WriterPublisher) Publish(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode message %s: %w", msg.ID, err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, err := p.w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write message %s: %w", msg.ID, err)
+	}
+	return nil
+}
+
+// FilePublisher appends messages as JSON lines to a file and syncs after each
+// one, so a message reported as published survives a crash.
+type FilePublisher struct {
+	*WriterPublisher
+	file *os.File
+}
+
+// OpenFilePublisher opens path for appending, creating it if needed.
+func OpenFilePublisher(path string) (*FilePublisher, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open outbox file: %w", err)
+	}
+	return &FilePublisher{WriterPublisher: NewWriterPublisher(f), file: f}, nil
+}
+
+func (p *FilePublisher) Publish(ctx context.Context, msg Message) error {
+	if err := p.WriterPublisher.Publish(ctx, msg); err != nil {
+		return err
+	}
+	if err := p.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync outbox file after message %s: %w", msg.ID, err)
+	}
+	return nil
+}
+
+func (p *FilePublisher) Close() error {
+	return p.file.Close()
+}
@@ -0,0 +1,92 @@
+// Package outbox publishes committed ledger events to other systems.
+//
+// Event stores that implement store.Outbox record each event as unpublished in
+// the same commit that stores it. A Relay drains that outbox to a Publisher and
+// marks events published only after the publisher accepts them. A crash at any
+// point therefore causes, at worst, an event to be published twice; consumers
+// discard duplicates by Message.ID, which is the event ID.
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"financial-ledger/store"
+)
+
+const DefaultBatchSize = 100
+
+type RelayOption func(*Relay)
+
+// WithBatchSize sets how many events the relay reads from the outbox at a time.
+func WithBatchSize(n int) RelayOption {
+	return func(r *Relay) {
+		if n > 0 {
+			r.batchSize = n
+		}
+	}
+}
+
+type Relay struct {
+	outbox    store.Outbox
+	publisher Publisher
+	batchSize int
+}
+
+func NewRelay(ob store.Outbox, pub Publisher, opts ...RelayOption) *Relay {
+	r := &Relay{outbox: ob, publisher: pub, batchSize: DefaultBatchSize}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Drain publishes every pending event in Position order and returns how many
+// were published. It stops at the first event the publisher rejects, so events
+// are never published out of order; the rest stay pending.
+func (r *Relay) Drain(ctx context.Context) (int, error) {
+	published := 0
+	for {
+		pending, err := r.outbox.PendingOutbox(ctx, r.batchSize)
+		if err != nil {
+			return published, fmt.Errorf("failed to read outbox: %w", err)
+		}
+		if len(pending) == 0 {
+			return published, nil
+		}
+		for _, event := range pending {
+			msg, err := NewMessage(event)
+			if err != nil {
+				return published, err
+			}
+			if err := r.publisher.Publish(ctx, msg); err != nil {
+				return published, fmt.Errorf("failed to publish event %s (position %d): %w", msg.ID, msg.Position, err)
+			}
+			if err := r.outbox.MarkPublished(ctx, event.GetBase().EventID); err != nil {
+				// The event was published; it will be published again, which
+				// consumers tolerate.
+				return published, fmt.Errorf("failed to mark event %s published: %w", msg.ID, err)
+			}
+			published++
+		}
+	}
+}
+
+// Run drains the outbox every interval until ctx is cancelled. Failures are
+// logged and retried on the next tick.
+func (r *Relay) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := r.Drain(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("ERROR: Outbox relay failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package outbox_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/events"
+	"financial-ledger/outbox"
+	"financial-ledger/store"
+)
+
+// flakyPublisher records messages and fails the publish numbered failAt.
+type flakyPublisher struct {
+	calls    int
+	failAt   int
+	received []outbox.Message
+}
+
+func (p *flakyPublisher) Publish(ctx context.Context, msg outbox.Message) error {
+	p.calls++
+	if p.calls == p.failAt {
+		return errors.New("broker unavailable")
+	}
+	p.received = append(p.received, msg)
+	return nil
+}
+
+func commitDeposits(t *testing.T, es *store.InMemoryEventStore, accountID string, n int) {
+	t.Helper()
+	batch := make([]events.Event, n)
+	for i := range batch {
+		batch[i] = events.DepositMadeEvent{
+			BaseEvent: events.NewBaseEvent(accountID, i+1, events.DepositMadeType),
+			Amount:    decimal.NewFromInt(int64(i + 1)),
+			Currency:  "USD",
+		}
+	}
+	if err := es.SaveEvents(accountID, 0, batch); err != nil {
+		t.Fatalf("SaveEvents failed: %v", err)
+	}
+}
+
+func TestRelay(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("DrainsInOrder", func(t *testing.T) {
+		es := store.NewInMemoryEventStore(store.WithOutbox())
+		commitDeposits(t, es, "acc-1", 5)
+		pub := &flakyPublisher{}
+		n, err := outbox.NewRelay(es, pub, outbox.WithBatchSize(2)).Drain(ctx)
+		if err != nil || n != 5 {
+			t.Fatalf("expected 5 published, got %d (err: %v)", n, err)
+		}
+		for i, msg := range pub.received {
+			if msg.Position != int64(i+1) || msg.Version != i+1 || msg.Type != events.DepositMadeType {
+				t.Errorf("message %d out of order: %+v", i, msg)
+			}
+		}
+		if size, _ := es.OutboxSize(ctx); size != 0 {
+			t.Errorf("expected empty outbox, got %d", size)
+		}
+	})
+
+	t.Run("FailureKeepsRestPending", func(t *testing.T) {
+		es := store.NewInMemoryEventStore(store.WithOutbox())
+		commitDeposits(t, es, "acc-1", 4)
+		pub := &flakyPublisher{failAt: 3}
+		relay := outbox.NewRelay(es, pub)
+
+		n, err := relay.Drain(ctx)
+		if err == nil || n != 2 {
+			t.Fatalf("expected failure after 2 published, got %d (err: %v)", n, err)
+		}
+		if size, _ := es.OutboxSize(ctx); size != 2 {
+			t.Errorf("expected 2 events still pending, got %d", size)
+		}
+		n, err = relay.Drain(ctx)
+		if err != nil || n != 2 {
+			t.Fatalf("expected the remaining 2 published, got %d (err: %v)", n, err)
+		}
+		if got := pub.received[2].Version; got != 3 {
+			t.Errorf("expected version 3 to be published next, got %d", got)
+		}
+	})
+
+	t.Run("DedupIDIsEventID", func(t *testing.T) {
+		es := store.NewInMemoryEventStore(store.WithOutbox())
+		commitDeposits(t, es, "acc-1", 1)
+		pending, _ := es.PendingOutbox(ctx, 0)
+		pub := &flakyPublisher{}
+		outbox.NewRelay(es, pub).Drain(ctx)
+		msg := pub.received[0]
+		if msg.ID != pending[0].GetBase().EventID.String() {
+			t.Errorf("expected message ID %s, got %s", pending[0].GetBase().EventID, msg.ID)
+		}
+		var event events.DepositMadeEvent
+		if err := json.Unmarshal(msg.Event, &event); err != nil || !event.Amount.Equal(decimal.NewFromInt(1)) || event.Hash == "" {
+			t.Errorf("expected the committed event in the payload, got %s (err: %v)", msg.Event, err)
+		}
+	})
+}
+
+func TestPublishers(t *testing.T) {
+	ctx := context.Background()
+	msg := outbox.Message{ID: "e-1", Position: 1, AggregateID: "acc-1", Version: 1, Type: events.DepositMadeType, Event: json.RawMessage(`{}`)}
+
+	t.Run("Writer", func(t *testing.T) {
+		var buf bytes.Buffer
+		pub := outbox.NewWriterPublisher(&buf)
+		_ = pub.Publish(ctx, msg)
+		_ = pub.Publish(ctx, msg)
+		lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+		if len(lines) != 2 {
+			t.Fatalf("expected 2 JSON lines, got %q", buf.String())
+		}
+		var got outbox.Message
+		if err := json.Unmarshal(lines[0], &got); err != nil || got.ID != "e-1" {
+			t.Errorf("unexpected line %s (err: %v)", lines[0], err)
+		}
+	})
+
+	t.Run("FileAppends", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events.jsonl")
+		for range 2 {
+			pub, err := outbox.OpenFilePublisher(path)
+			if err != nil {
+				t.Fatalf("OpenFilePublisher failed: %v", err)
+			}
+			if err := pub.Publish(ctx, msg); err != nil {
+				t.Fatalf("Publish failed: %v", err)
+			}
+			pub.Close()
+		}
+		f, _ := os.Open(path)
+		defer f.Close()
+		lines := 0
+		for scanner := bufio.NewScanner(f); scanner.Scan(); {
+			lines++
+		}
+		if lines != 2 {
+			t.Errorf("expected reopening to append, got %d lines", lines)
+		}
+	})
+}
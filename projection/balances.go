@@ -0,0 +1,79 @@
+package projection
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/domain"
+	"financial-ledger/events"
+	"financial-ledger/shared"
+)
+
+// BalanceProjectionName is the name the balance projection registers under.
+const BalanceProjectionName = "balances"
+
+// BalanceProjection keeps the current balances of every account. It folds
+// events through domain.Account, so the balances follow the same rules as the
+// write side, and skips events at or below an account's version, which makes
+// redelivered events harmless. Customer and approval streams hold no balances
+// and are skipped.
+type BalanceProjection struct {
+	sync.RWMutex
+	accounts map[string]*domain.Account
+}
+
+func NewBalanceProjection() *BalanceProjection {
+	return &BalanceProjection{accounts: make(map[string]*domain.Account)}
+}
+
+func (p *BalanceProjection) Name() string {
+	return BalanceProjectionName
+}
+
+func (p *BalanceProjection) Apply(ctx context.Context, event events.Event) error {
+	base := event.GetBase()
+	if !domain.IsAccountStream(base.AggregateID) {
+		return nil
+	}
+	p.Lock()
+	defer p.Unlock()
+
+	account, ok := p.accounts[base.AggregateID]
+	if !ok {
+		account = domain.NewAccount(base.AggregateID)
+		p.accounts[base.AggregateID] = account
+	}
+	if base.Version <= account.Version {
+		return nil
+	}
+	if err := account.ApplyEvent(event); err != nil {
+		return fmt.Errorf("balance projection: %w", err)
+	}
+	return nil
+}
+
+func (p *BalanceProjection) Reset(ctx context.Context) error {
+	p.Lock()
+	defer p.Unlock()
+	p.accounts = make(map[string]*domain.Account)
+	return nil
+}
+
+// Balances returns a copy of the account's balances and the version they
+// reflect. found is false for accounts the projection has not seen.
+func (p *BalanceProjection) Balances(accountID string) (balances map[shared.Currency]decimal.Decimal, version int, found bool) {
+	p.RLock()
+	defer p.RUnlock()
+	account, ok := p.accounts[accountID]
+	if !ok || account.Version == 0 {
+		return nil, 0, false
+	}
+	balances = make(map[shared.Currency]decimal.Decimal, len(account.Balances))
+	for cur, amount := range account.Balances {
+		balances[cur] = amount
+	}
+	return balances, account.Version, true
+}
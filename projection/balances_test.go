@@ -0,0 +1,55 @@
+package projection_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/events"
+	"financial-ledger/projection"
+	"financial-ledger/shared"
+)
+
+func TestBalanceProjection(t *testing.T) {
+	ctx := context.Background()
+	p := projection.NewBalanceProjection()
+
+	created := events.AccountCreatedEvent{
+		BaseEvent:       events.NewBaseEvent("acc-1", 1, events.AccountCreatedType),
+		InitialBalances: []shared.Balance{{Currency: shared.USD, Amount: decimal.NewFromInt(100)}},
+	}
+	deposit := events.DepositMadeEvent{
+		BaseEvent: events.NewBaseEvent("acc-1", 2, events.DepositMadeType),
+		Amount:    decimal.NewFromInt(25),
+		Currency:  shared.EUR,
+	}
+	for _, e := range []events.Event{created, deposit, deposit} { // the repeat must be ignored
+		if err := p.Apply(ctx, e); err != nil {
+			t.Fatalf("Apply(%s) failed: %v", e.GetBase().Type, err)
+		}
+	}
+
+	balances, version, found := p.Balances("acc-1")
+	if !found || version != 2 {
+		t.Fatalf("expected acc-1 at version 2, got found=%v version=%d", found, version)
+	}
+	if !balances[shared.USD].Equal(decimal.NewFromInt(100)) || !balances[shared.EUR].Equal(decimal.NewFromInt(25)) {
+		t.Errorf("unexpected balances %v", balances)
+	}
+
+	balances[shared.USD] = decimal.Zero
+	if again, _, _ := p.Balances("acc-1"); !again[shared.USD].Equal(decimal.NewFromInt(100)) {
+		t.Errorf("Balances did not return a copy")
+	}
+	if _, _, found := p.Balances("acc-2"); found {
+		t.Errorf("expected unknown account to be missing")
+	}
+
+	if err := p.Reset(ctx); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	if _, _, found := p.Balances("acc-1"); found {
+		t.Errorf("expected no balances after reset")
+	}
+}
@@ -0,0 +1,257 @@
+// Package projection maintains read models from the global event log. A Runner
+// feeds committed events, in Position order, to each registered Projection and
+// records a checkpoint per projection so that it resumes where it stopped.
+package projection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"financial-ledger/events"
+	"financial-ledger/store"
+)
+
+var (
+	ErrUnknownProjection   = errors.New("unknown projection")
+	ErrDuplicateProjection = errors.New("projection already registered")
+)
+
+// DefaultBatchSize is how many events the Runner reads from the log at a time.
+const DefaultBatchSize = 500
+
+// Projection is a read model built by folding events. Delivery is at least
+// once: after a crash between Apply and the checkpoint being saved, the same
+// events are applied again, so Apply must tolerate repeats.
+type Projection interface {
+	Name() string
+
+	// Apply folds one event into the read model.
+	Apply(ctx context.Context, event events.Event) error
+
+	// Reset discards all state so the projection can be rebuilt from position zero.
+	Reset(ctx context.Context) error
+}
+
+// Status describes how far a projection has got. Lag is the number of
+// committed events it has not applied yet.
+type Status struct {
+	Name      string
+	Position  int64
+	Head      int64
+	Lag       int64
+	UpdatedAt time.Time
+	LastError string
+}
+
+type registered struct {
+	projection Projection
+	checkpoint store.ProjectionCheckpoint
+	lastErr    error
+}
+
+// Runner drives projections from a global log.
+type Runner struct {
+	mu          sync.Mutex
+	log         store.GlobalLog
+	checkpoints store.ProjectionCheckpointStore
+	batchSize   int
+	projections []*registered
+}
+
+// RunnerOption customises a Runner.
+type RunnerOption func(*Runner)
+
+// WithBatchSize overrides DefaultBatchSize.
+func WithBatchSize(n int) RunnerOption {
+	return func(r *Runner) {
+		if n > 0 {
+			r.batchSize = n
+		}
+	}
+}
+
+func NewRunner(gl store.GlobalLog, checkpoints store.ProjectionCheckpointStore, opts ...RunnerOption) *Runner {
+	r := &Runner{log: gl, checkpoints: checkpoints, batchSize: DefaultBatchSize}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Register adds p to the runner, resuming from its saved checkpoint if there
+// is one. A projection whose state does not survive restarts should be rebuilt
+// after registering if the checkpoint store does.
+func (r *Runner) Register(ctx context.Context, p Projection) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name := p.Name()
+	if _, err := r.find(name); err == nil {
+		return fmt.Errorf("%w: %s", ErrDuplicateProjection, name)
+	}
+	checkpoint, found, err := r.checkpoints.GetProjectionCheckpoint(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to load checkpoint for projection %s: %w", name, err)
+	}
+	if !found {
+		checkpoint = store.ProjectionCheckpoint{Name: name}
+	}
+	r.projections = append(r.projections, &registered{projection: p, checkpoint: checkpoint})
+	return nil
+}
+
+// CatchUp applies every event committed since each projection's checkpoint. With
+// no names it catches up all projections. A failing projection does not hold
+// back the others; their errors are joined.
+func (r *Runner) CatchUp(ctx context.Context, names ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	targets, err := r.selectProjections(names)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, reg := range targets {
+		if err := r.catchUp(ctx, reg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Rebuild resets the named projection and replays the whole log into it.
+func (r *Runner) Rebuild(ctx context.Context, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reg, err := r.find(name)
+	if err != nil {
+		return err
+	}
+	if err := reg.projection.Reset(ctx); err != nil {
+		return fmt.Errorf("failed to reset projection %s: %w", name, err)
+	}
+	reg.checkpoint = store.ProjectionCheckpoint{Name: name, UpdatedAt: time.Now().UTC()}
+	if err := r.checkpoints.SaveProjectionCheckpoint(ctx, reg.checkpoint); err != nil {
+		return fmt.Errorf("failed to reset checkpoint for projection %s: %w", name, err)
+	}
+	log.Printf("Rebuilding projection %s from position 0", name)
+	return r.catchUp(ctx, reg)
+}
+
+// Status reports each projection's position and lag behind the head of the log.
+func (r *Runner) Status(ctx context.Context) ([]Status, error) {
+	head, err := r.log.Head(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read global log head: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Status, 0, len(r.projections))
+	for _, reg := range r.projections {
+		st := Status{
+			Name:      reg.projection.Name(),
+			Position:  reg.checkpoint.Position,
+			Head:      head,
+			Lag:       max(head-reg.checkpoint.Position, 0),
+			UpdatedAt: reg.checkpoint.UpdatedAt,
+		}
+		if reg.lastErr != nil {
+			st.LastError = reg.lastErr.Error()
+		}
+		out = append(out, st)
+	}
+	return out, nil
+}
+
+// Run catches up every projection each interval until ctx is cancelled.
+func (r *Runner) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.CatchUp(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("Warning: Projection catch-up failed: %v", err)
+			}
+		}
+	}
+}
+
+// catchUp feeds reg every event after its checkpoint, saving the checkpoint
+// after each batch. The caller holds r.mu.
+func (r *Runner) catchUp(ctx context.Context, reg *registered) error {
+	name := reg.projection.Name()
+	err := func() error {
+		for {
+			batch, err := r.log.ReadAll(ctx, reg.checkpoint.Position, r.batchSize)
+			if err != nil {
+				return fmt.Errorf("failed to read global log for projection %s: %w", name, err)
+			}
+			if len(batch) == 0 {
+				return nil
+			}
+			position := reg.checkpoint.Position
+			for _, event := range batch {
+				if err := reg.projection.Apply(ctx, event); err != nil {
+					// Keep the progress made so far in this batch.
+					r.saveCheckpoint(ctx, reg, position)
+					return fmt.Errorf("projection %s failed at position %d: %w", name, event.GetBase().Position, err)
+				}
+				position = event.GetBase().Position
+			}
+			if err := r.saveCheckpoint(ctx, reg, position); err != nil {
+				return err
+			}
+			if len(batch) < r.batchSize {
+				return nil
+			}
+		}
+	}()
+	reg.lastErr = err
+	return err
+}
+
+func (r *Runner) saveCheckpoint(ctx context.Context, reg *registered, position int64) error {
+	if position == reg.checkpoint.Position {
+		return nil
+	}
+	checkpoint := store.ProjectionCheckpoint{Name: reg.checkpoint.Name, Position: position, UpdatedAt: time.Now().UTC()}
+	if err := r.checkpoints.SaveProjectionCheckpoint(ctx, checkpoint); err != nil {
+		return fmt.Errorf("failed to save checkpoint for projection %s at position %d: %w", checkpoint.Name, position, err)
+	}
+	reg.checkpoint = checkpoint
+	return nil
+}
+
+func (r *Runner) find(name string) (*registered, error) {
+	for _, reg := range r.projections {
+		if reg.projection.Name() == name {
+			return reg, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownProjection, name)
+}
+
+func (r *Runner) selectProjections(names []string) ([]*registered, error) {
+	if len(names) == 0 {
+		return r.projections, nil
+	}
+	out := make([]*registered, 0, len(names))
+	for _, name := range names {
+		reg, err := r.find(name)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, reg)
+	}
+	return out, nil
+}
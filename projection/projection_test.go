@@ -0,0 +1,165 @@
+package projection_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"financial-ledger/events"
+	"financial-ledger/projection"
+	"financial-ledger/store"
+)
+
+type TestEvent struct {
+	events.BaseEvent
+	Data string
+}
+
+// recorder remembers the positions it was given and can be told to fail.
+type recorder struct {
+	name      string
+	positions []int64
+	failAt    int64
+}
+
+func (r *recorder) Name() string { return r.name }
+
+func (r *recorder) Apply(ctx context.Context, event events.Event) error {
+	pos := event.GetBase().Position
+	if pos == r.failAt {
+		return fmt.Errorf("refusing position %d", pos)
+	}
+	r.positions = append(r.positions, pos)
+	return nil
+}
+
+func (r *recorder) Reset(ctx context.Context) error {
+	r.positions = nil
+	return nil
+}
+
+func appendEvents(t *testing.T, es *store.InMemoryEventStore, aggID string, from, n int) {
+	t.Helper()
+	batch := make([]events.Event, 0, n)
+	for v := from; v < from+n; v++ {
+		batch = append(batch, TestEvent{BaseEvent: events.NewBaseEvent(aggID, v, "TestEvent"), Data: fmt.Sprint(v)})
+	}
+	if err := es.SaveEvents(aggID, from-1, batch); err != nil {
+		t.Fatalf("SaveEvents failed: %v", err)
+	}
+}
+
+func TestRunner(t *testing.T) {
+	ctx := context.Background()
+	es := store.NewInMemoryEventStore()
+	checkpoints := store.NewInMemoryProjectionCheckpointStore()
+	runner := projection.NewRunner(es, checkpoints, projection.WithBatchSize(2))
+
+	a, b := &recorder{name: "a"}, &recorder{name: "b"}
+	for _, p := range []projection.Projection{a, b} {
+		if err := runner.Register(ctx, p); err != nil {
+			t.Fatalf("Register(%s) failed: %v", p.Name(), err)
+		}
+	}
+	appendEvents(t, es, "agg-1", 1, 3)
+	appendEvents(t, es, "agg-2", 1, 2)
+
+	t.Run("DuplicateName", func(t *testing.T) {
+		if err := runner.Register(ctx, &recorder{name: "a"}); !errors.Is(err, projection.ErrDuplicateProjection) {
+			t.Errorf("expected ErrDuplicateProjection, got %v", err)
+		}
+	})
+
+	t.Run("LagBeforeCatchUp", func(t *testing.T) {
+		statuses, err := runner.Status(ctx)
+		if err != nil {
+			t.Fatalf("Status failed: %v", err)
+		}
+		for _, st := range statuses {
+			if st.Head != 5 || st.Position != 0 || st.Lag != 5 {
+				t.Errorf("unexpected status %+v", st)
+			}
+		}
+	})
+
+	t.Run("CatchUpOne", func(t *testing.T) {
+		if err := runner.CatchUp(ctx, "a"); err != nil {
+			t.Fatalf("CatchUp failed: %v", err)
+		}
+		if fmt.Sprint(a.positions) != "[1 2 3 4 5]" || len(b.positions) != 0 {
+			t.Errorf("unexpected positions a=%v b=%v", a.positions, b.positions)
+		}
+		cp, _, _ := checkpoints.GetProjectionCheckpoint(ctx, "a")
+		if cp.Position != 5 {
+			t.Errorf("expected checkpoint at 5, got %d", cp.Position)
+		}
+	})
+
+	t.Run("IncrementalCatchUp", func(t *testing.T) {
+		appendEvents(t, es, "agg-1", 4, 1)
+		if err := runner.CatchUp(ctx); err != nil {
+			t.Fatalf("CatchUp failed: %v", err)
+		}
+		if fmt.Sprint(a.positions) != "[1 2 3 4 5 6]" || fmt.Sprint(b.positions) != "[1 2 3 4 5 6]" {
+			t.Errorf("unexpected positions a=%v b=%v", a.positions, b.positions)
+		}
+	})
+
+	t.Run("FailureIsIsolatedAndReported", func(t *testing.T) {
+		appendEvents(t, es, "agg-2", 3, 2) // positions 7 and 8
+		b.failAt = 8
+		err := runner.CatchUp(ctx)
+		if err == nil {
+			t.Fatalf("expected an error from projection b")
+		}
+		statuses, _ := runner.Status(ctx)
+		for _, st := range statuses {
+			switch st.Name {
+			case "a":
+				if st.Lag != 0 || st.LastError != "" {
+					t.Errorf("a should be unaffected, got %+v", st)
+				}
+			case "b":
+				if st.Position != 7 || st.Lag != 1 || st.LastError == "" {
+					t.Errorf("b should stop before position 8, got %+v", st)
+				}
+			}
+		}
+
+		b.failAt = 0
+		if err := runner.CatchUp(ctx, "b"); err != nil {
+			t.Fatalf("CatchUp after fix failed: %v", err)
+		}
+		if fmt.Sprint(b.positions) != "[1 2 3 4 5 6 7 8]" {
+			t.Errorf("unexpected positions b=%v", b.positions)
+		}
+	})
+
+	t.Run("Rebuild", func(t *testing.T) {
+		if err := runner.Rebuild(ctx, "a"); err != nil {
+			t.Fatalf("Rebuild failed: %v", err)
+		}
+		if fmt.Sprint(a.positions) != "[1 2 3 4 5 6 7 8]" {
+			t.Errorf("unexpected positions after rebuild a=%v", a.positions)
+		}
+		if err := runner.Rebuild(ctx, "missing"); !errors.Is(err, projection.ErrUnknownProjection) {
+			t.Errorf("expected ErrUnknownProjection, got %v", err)
+		}
+	})
+
+	t.Run("ResumeFromCheckpoint", func(t *testing.T) {
+		resumed := projection.NewRunner(es, checkpoints)
+		c := &recorder{name: "a"}
+		if err := resumed.Register(ctx, c); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+		appendEvents(t, es, "agg-1", 5, 1)
+		if err := resumed.CatchUp(ctx); err != nil {
+			t.Fatalf("CatchUp failed: %v", err)
+		}
+		if fmt.Sprint(c.positions) != "[9]" {
+			t.Errorf("expected only the new event, got %v", c.positions)
+		}
+	})
+}
@@ -0,0 +1,177 @@
+package screening
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Entry is one sanctioned person, organisation, vessel or aircraft.
+type Entry struct {
+	UID         string
+	Name        string
+	Type        string
+	Programs    []string
+	Aliases     []string
+	Identifiers []Identifier
+}
+
+// Identifier is a document or registration number, such as a passport.
+type Identifier struct {
+	Type   string
+	Number string
+}
+
+// List is the content of a sanctions list file. Published is the publication
+// date the file states, if any.
+type List struct {
+	Published string
+	Entries   []Entry
+}
+
+// ofacEmpty is how OFAC's delimited files write an empty field.
+const ofacEmpty = "-0-"
+
+// ParseCSV reads a list in the layout of OFAC's sdn.csv: no header, and the
+// columns ent_num, SDN_Name, SDN_Type, Program, Title, Call_Sign, Vess_type,
+// Tonnage, GRT, Vess_flag, Vess_owner and Remarks. Aliases ("a.k.a. 'X'")
+// and identifiers such as "Passport X" are taken from the remarks.
+func ParseCSV(r io.Reader) (*List, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	list := &List{}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid sanctions list at line %d: %w", line, err)
+		}
+		if len(record) == 1 && strings.TrimSpace(strings.Trim(record[0], "\x1a")) == "" {
+			continue // blank line or the end-of-file marker OFAC appends
+		}
+		if len(record) < 4 {
+			return nil, fmt.Errorf("invalid sanctions list at line %d: expected at least 4 columns, got %d", line, len(record))
+		}
+		field := func(i int) string {
+			if i >= len(record) {
+				return ""
+			}
+			v := strings.TrimSpace(record[i])
+			if v == ofacEmpty {
+				return ""
+			}
+			return v
+		}
+		entry := Entry{UID: field(0), Name: field(1), Type: field(2), Programs: splitPrograms(field(3))}
+		if entry.UID == "" || entry.Name == "" {
+			return nil, fmt.Errorf("invalid sanctions list at line %d: entry needs a number and a name", line)
+		}
+		entry.Aliases, entry.Identifiers = parseRemarks(field(11))
+		list.Entries = append(list.Entries, entry)
+	}
+	return list, nil
+}
+
+// splitPrograms splits OFAC's "SDGT] [IRGC" into its programs.
+func splitPrograms(s string) []string {
+	var programs []string
+	for _, p := range strings.Split(s, "] [") {
+		if p = strings.Trim(p, "[] "); p != "" {
+			programs = append(programs, p)
+		}
+	}
+	return programs
+}
+
+var (
+	aliasRemark = regexp.MustCompile(`^(?:a\.k\.a\.|f\.k\.a\.|n\.k\.a\.)\s*'(.+)'$`)
+	idRemark    = regexp.MustCompile(`^(Passport|National ID No\.|Tax ID No\.|SSN|Cedula No\.|D\.N\.I\.|NIT #|RFC|C\.U\.R\.P\.|SWIFT/BIC|IMO|Registration ID|Registration Number|Company Number|Identification Number)\s+([A-Za-z0-9][A-Za-z0-9./-]*)`)
+)
+
+func parseRemarks(remarks string) ([]string, []Identifier) {
+	var aliases []string
+	var ids []Identifier
+	for _, part := range strings.Split(remarks, ";") {
+		part = strings.TrimSuffix(strings.TrimSpace(part), ".")
+		if m := aliasRemark.FindStringSubmatch(part); m != nil {
+			aliases = append(aliases, m[1])
+		} else if m := idRemark.FindStringSubmatch(part); m != nil {
+			ids = append(ids, Identifier{Type: m[1], Number: strings.TrimSuffix(m[2], ".")})
+		}
+	}
+	return aliases, ids
+}
+
+// xmlList follows the schema of OFAC's sdn.xml; element names are matched in
+// any namespace.
+type xmlList struct {
+	Published string `xml:"publshInformation>Publish_Date"`
+	Entries   []struct {
+		UID       string   `xml:"uid"`
+		FirstName string   `xml:"firstName"`
+		LastName  string   `xml:"lastName"`
+		Type      string   `xml:"sdnType"`
+		Programs  []string `xml:"programList>program"`
+		Aliases   []struct {
+			FirstName string `xml:"firstName"`
+			LastName  string `xml:"lastName"`
+		} `xml:"akaList>aka"`
+		IDs []struct {
+			Type   string `xml:"idType"`
+			Number string `xml:"idNumber"`
+		} `xml:"idList>id"`
+	} `xml:"sdnEntry"`
+}
+
+// ParseXML reads a list in the schema of OFAC's sdn.xml.
+func ParseXML(r io.Reader) (*List, error) {
+	var doc xmlList
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid sanctions list: %w", err)
+	}
+	list := &List{Published: strings.TrimSpace(doc.Published)}
+	for i, e := range doc.Entries {
+		entry := Entry{UID: strings.TrimSpace(e.UID), Name: ofacName(e.FirstName, e.LastName), Type: strings.TrimSpace(e.Type)}
+		if entry.UID == "" || entry.Name == "" {
+			return nil, fmt.Errorf("invalid sanctions list: entry %d needs a uid and a name", i+1)
+		}
+		for _, p := range e.Programs {
+			if p = strings.TrimSpace(p); p != "" {
+				entry.Programs = append(entry.Programs, p)
+			}
+		}
+		for _, a := range e.Aliases {
+			if name := ofacName(a.FirstName, a.LastName); name != "" {
+				entry.Aliases = append(entry.Aliases, name)
+			}
+		}
+		for _, id := range e.IDs {
+			if number := strings.TrimSpace(id.Number); number != "" {
+				entry.Identifiers = append(entry.Identifiers, Identifier{Type: strings.TrimSpace(id.Type), Number: number})
+			}
+		}
+		list.Entries = append(list.Entries, entry)
+	}
+	return list, nil
+}
+
+// ofacName writes a name the way sdn.csv does: "LAST, First".
+func ofacName(first, last string) string {
+	first, last = strings.TrimSpace(first), strings.TrimSpace(last)
+	if first == "" {
+		return last
+	}
+	if last == "" {
+		return first
+	}
+	return last + ", " + first
+}
@@ -0,0 +1,96 @@
+package screening
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// tokens upper-cases a name, strips accents and splits it into words, so
+// that "Pérez-Ruiz, José" and "JOSE PEREZ RUIZ" have the same words.
+func tokens(name string) []string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// accent stripped from the preceding letter
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToUpper(r))
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Fields(b.String())
+}
+
+// normalizeIdentifier keeps only the letters and digits of a document
+// number, upper-cased.
+func normalizeIdentifier(id string) string {
+	return strings.Join(tokens(id), "")
+}
+
+// nameScore is the similarity of two names from 0 to 1. Names are compared
+// both as written and with their words sorted, so that word order does not
+// matter ("LAST, First" against "First Last"), and the better score counts.
+func nameScore(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	score := jaroWinkler(strings.Join(a, " "), strings.Join(b, " "))
+	sortedA, sortedB := slices.Sorted(slices.Values(a)), slices.Sorted(slices.Values(b))
+	return max(score, jaroWinkler(strings.Join(sortedA, " "), strings.Join(sortedB, " ")))
+}
+
+// jaroWinkler returns the Jaro-Winkler similarity of s and t, which favours
+// strings sharing a prefix of up to four characters.
+func jaroWinkler(s, t string) float64 {
+	a, b := []rune(s), []rune(t)
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	window := max(0, max(len(a), len(b))/2-1)
+	matchedA := make([]bool, len(a))
+	matchedB := make([]bool, len(b))
+	matches := 0
+	for i := range a {
+		lo, hi := max(0, i-window), min(len(b), i+window+1)
+		for j := lo; j < hi; j++ {
+			if !matchedB[j] && a[i] == b[j] {
+				matchedA[i], matchedB[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions, j := 0, 0
+	for i := range a {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if a[i] != b[j] {
+			transpositions++
+		}
+		j++
+	}
+	m := float64(matches)
+	jaro := (m/float64(len(a)) + m/float64(len(b)) + (m-float64(transpositions/2))/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(a), len(b)) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
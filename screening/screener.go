@@ -0,0 +1,234 @@
+// Package screening screens names and identifiers against a sanctions list
+// loaded from a local file in the CSV or XML format OFAC publishes.
+//
+// Names match fuzzily: accents, punctuation and word order are ignored, and
+// a list name or alias whose Jaro-Winkler similarity reaches the threshold is
+// a match. Identifiers such as passport numbers match only exactly. Each load
+// of the list that changes its content gets the next version number, and
+// every decision records the version it was made against.
+package screening
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"financial-ledger/store"
+)
+
+// DefaultThreshold is the similarity at or above which a name matches.
+const DefaultThreshold = 0.9
+
+type Option func(*Screener)
+
+// WithThreshold sets the similarity, above 0 and at most 1, at or above which
+// a name matches. 1 matches only names that are equal once normalised.
+func WithThreshold(threshold float64) Option {
+	return func(s *Screener) {
+		s.threshold = threshold
+	}
+}
+
+// WithClock replaces time.Now, for tests.
+func WithClock(now func() time.Time) Option {
+	return func(s *Screener) {
+		if now != nil {
+			s.now = now
+		}
+	}
+}
+
+// Version identifies a loaded list. Digest is the SHA-256 of the file.
+type Version struct {
+	Number    int
+	Digest    string
+	Source    string
+	Published string
+	Entries   int
+	LoadedAt  time.Time
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d (sha256:%.12s, %d entries)", v.Number, v.Digest, v.Entries)
+}
+
+// Subject is who is screened: a name and any document numbers.
+type Subject struct {
+	Name        string
+	Identifiers []string
+}
+
+type indexedEntry struct {
+	entry Entry
+	names [][]string // tokens of the name and each alias
+	ids   map[string]string
+}
+
+type Screener struct {
+	sync.RWMutex
+	path      string
+	threshold float64
+	entries   []indexedEntry
+	version   Version
+	now       func() time.Time
+}
+
+// NewScreener loads the list at path as version 1.
+func NewScreener(path string, opts ...Option) (*Screener, error) {
+	s := &Screener{path: path, threshold: DefaultThreshold, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.threshold <= 0 || s.threshold > 1 {
+		return nil, fmt.Errorf("screening threshold must be above 0 and at most 1, got %g", s.threshold)
+	}
+	if _, _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload reads the list file again. If its content changed, it replaces the
+// list and takes the next version number; otherwise the version is kept and
+// changed is false. A file that cannot be read or parsed leaves the current
+// list in place.
+func (s *Screener) Reload() (v Version, changed bool, err error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return s.Version(), false, fmt.Errorf("failed to read sanctions list: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+
+	s.Lock()
+	defer s.Unlock()
+	if digest == s.version.Digest {
+		return s.version, false, nil
+	}
+	var list *List
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+		list, err = ParseXML(bytes.NewReader(data))
+	} else {
+		list, err = ParseCSV(bytes.NewReader(data))
+	}
+	if err != nil {
+		return s.version, false, fmt.Errorf("%s: %w", s.path, err)
+	}
+
+	entries := make([]indexedEntry, len(list.Entries))
+	for i, e := range list.Entries {
+		ie := indexedEntry{entry: e, names: [][]string{tokens(e.Name)}, ids: make(map[string]string)}
+		for _, alias := range e.Aliases {
+			ie.names = append(ie.names, tokens(alias))
+		}
+		for _, id := range e.Identifiers {
+			ie.ids[normalizeIdentifier(id.Number)] = id.Type + " " + id.Number
+		}
+		entries[i] = ie
+	}
+	s.entries = entries
+	s.version = Version{
+		Number:    s.version.Number + 1,
+		Digest:    digest,
+		Source:    s.path,
+		Published: list.Published,
+		Entries:   len(list.Entries),
+		LoadedAt:  s.now().UTC(),
+	}
+	log.Printf("Sanctions list %s loaded as version %s", s.path, s.version)
+	return s.version, true, nil
+}
+
+// Watch reloads the list every interval until ctx is cancelled, so that a
+// replaced file takes effect without a restart.
+func (s *Screener) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, _, err := s.Reload(); err != nil {
+				log.Printf("ERROR: Failed to reload sanctions list, keeping version %d: %v", s.Version().Number, err)
+			}
+		}
+	}
+}
+
+func (s *Screener) Version() Version {
+	s.RLock()
+	defer s.RUnlock()
+	return s.version
+}
+
+func (s *Screener) Threshold() float64 {
+	return s.threshold
+}
+
+// Screen checks subject against the current list. The decision does not keep
+// the subject, whose details are personal data, but names the list version
+// and holds every matching entry, best first, with its best matching name,
+// alias or identifier; its Outcome is ScreeningClear if there are none and
+// is otherwise left for the caller to set.
+func (s *Screener) Screen(subject Subject) store.ScreeningDecision {
+	s.RLock()
+	defer s.RUnlock()
+	decision := store.ScreeningDecision{
+		ListVersion: s.version.Number,
+		ListDigest:  s.version.Digest,
+		Threshold:   s.threshold,
+		ScreenedAt:  s.now().UTC(),
+	}
+
+	name := tokens(subject.Name)
+	ids := make([]string, 0, len(subject.Identifiers))
+	for _, id := range subject.Identifiers {
+		if n := normalizeIdentifier(id); n != "" {
+			ids = append(ids, n)
+		}
+	}
+	for _, ie := range s.entries {
+		best := store.ScreeningMatch{EntryUID: ie.entry.UID, EntryName: ie.entry.Name, Programs: ie.entry.Programs}
+		for _, id := range ids {
+			if listed, ok := ie.ids[id]; ok {
+				best.Matched, best.Score = listed, 1
+			}
+		}
+		for i, listed := range ie.names {
+			if best.Score == 1 {
+				break
+			}
+			if score := nameScore(name, listed); score > best.Score {
+				best.Score = score
+				best.Matched = ie.entry.Name
+				if i > 0 {
+					best.Matched = ie.entry.Aliases[i-1]
+				}
+			}
+		}
+		if best.Score >= s.threshold {
+			decision.Matches = append(decision.Matches, best)
+		}
+	}
+	slices.SortStableFunc(decision.Matches, func(a, b store.ScreeningMatch) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+		return 0
+	})
+	if len(decision.Matches) == 0 {
+		decision.Outcome = store.ScreeningClear
+	}
+	return decision
+}
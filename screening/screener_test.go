@@ -0,0 +1,187 @@
+package screening_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"financial-ledger/screening"
+	"financial-ledger/store"
+)
+
+const sdnCSV = `36,"AEROCARIBBEAN AIRLINES",-0- ,"CUBA",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,"Havana, Cuba."
+2674,"HERNANDEZ PEREZ, Jose Antonio","individual","SDNTK] [ILLICIT-DRUGS",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,"DOB 10 Jun 1960; a.k.a. 'EL CHEPE'; Passport A1234567 (Mexico); National ID No. HEPJ600610 (Mexico)."
+` + "\x1a\n"
+
+const sdnXML = `<?xml version="1.0" standalone="yes"?>
+<sdnList xmlns="http://tempuri.org/sdnList.xsd">
+  <publshInformation><Publish_Date>03/02/2026</Publish_Date><Record_Count>1</Record_Count></publshInformation>
+  <sdnEntry>
+    <uid>7140</uid>
+    <firstName>Ivan</firstName>
+    <lastName>PETROV</lastName>
+    <sdnType>Individual</sdnType>
+    <programList><program>UKRAINE-EO13661</program></programList>
+    <idList><id><uid>1</uid><idType>Passport</idType><idNumber>71 0345678</idNumber></id></idList>
+    <akaList><aka><uid>2</uid><type>a.k.a.</type><category>strong</category><lastName>PETROFF</lastName><firstName>Ivan</firstName></aka></akaList>
+  </sdnEntry>
+</sdnList>`
+
+func writeList(t *testing.T, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseCSV(t *testing.T) {
+	list, err := screening.ParseCSV(strings.NewReader(sdnCSV))
+	if err != nil {
+		t.Fatalf("ParseCSV failed: %v", err)
+	}
+	if len(list.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", list.Entries)
+	}
+	e := list.Entries[1]
+	if e.UID != "2674" || e.Type != "individual" || len(e.Programs) != 2 || e.Programs[1] != "ILLICIT-DRUGS" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	if len(e.Aliases) != 1 || e.Aliases[0] != "EL CHEPE" {
+		t.Errorf("expected the a.k.a. from the remarks, got %v", e.Aliases)
+	}
+	if len(e.Identifiers) != 2 || e.Identifiers[0] != (screening.Identifier{Type: "Passport", Number: "A1234567"}) {
+		t.Errorf("expected the passport and national ID from the remarks, got %+v", e.Identifiers)
+	}
+	if list.Entries[0].Type != "" {
+		t.Errorf("expected -0- to read as empty, got %q", list.Entries[0].Type)
+	}
+
+	if _, err := screening.ParseCSV(strings.NewReader(`1,"NAME"`)); err == nil {
+		t.Error("expected a short record to be refused")
+	}
+}
+
+func TestParseXML(t *testing.T) {
+	list, err := screening.ParseXML(strings.NewReader(sdnXML))
+	if err != nil {
+		t.Fatalf("ParseXML failed: %v", err)
+	}
+	if list.Published != "03/02/2026" || len(list.Entries) != 1 {
+		t.Fatalf("unexpected list %+v", list)
+	}
+	e := list.Entries[0]
+	if e.Name != "PETROV, Ivan" || len(e.Aliases) != 1 || e.Aliases[0] != "PETROFF, Ivan" || e.Identifiers[0].Number != "71 0345678" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+}
+
+func TestScreener(t *testing.T) {
+	path := writeList(t, "sdn.csv", sdnCSV)
+	sc, err := screening.NewScreener(path)
+	if err != nil {
+		t.Fatalf("NewScreener failed: %v", err)
+	}
+
+	t.Run("FuzzyNames", func(t *testing.T) {
+		for _, tc := range []struct {
+			name  string
+			entry string
+		}{
+			{"Jose Antonio Hernandez Perez", "2674"},
+			{"HERNÁNDEZ-PÉREZ, José Antonio", "2674"},
+			{"Hernandez Peres, Jose Antonio", "2674"},
+			{"el chepe", "2674"},
+			{"Aero Caribbean Airlines", "36"},
+			{"Jane Smith", ""},
+			{"Perez", ""},
+		} {
+			d := sc.Screen(screening.Subject{Name: tc.name})
+			switch {
+			case tc.entry == "" && len(d.Matches) != 0:
+				t.Errorf("%q: expected no match, got %+v", tc.name, d.Matches)
+			case tc.entry == "" && d.Outcome != store.ScreeningClear:
+				t.Errorf("%q: expected a clear outcome, got %q", tc.name, d.Outcome)
+			case tc.entry != "" && (len(d.Matches) == 0 || d.Matches[0].EntryUID != tc.entry):
+				t.Errorf("%q: expected a match on entry %s, got %+v", tc.name, tc.entry, d.Matches)
+			}
+		}
+	})
+
+	t.Run("Identifiers", func(t *testing.T) {
+		d := sc.Screen(screening.Subject{Name: "Somebody Else", Identifiers: []string{"a-123 4567"}})
+		if len(d.Matches) != 1 || d.Matches[0].Score != 1 || d.Matches[0].Matched != "Passport A1234567" {
+			t.Errorf("expected an exact identifier match, got %+v", d.Matches)
+		}
+	})
+
+	t.Run("OneRuneNames", func(t *testing.T) {
+		short, err := screening.NewScreener(writeList(t, "sdn.csv", `99,"Q","individual","SDGT",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- `+"\n"))
+		if err != nil {
+			t.Fatalf("NewScreener failed: %v", err)
+		}
+		if d := short.Screen(screening.Subject{Name: "q"}); len(d.Matches) != 1 || d.Matches[0].Score != 1 {
+			t.Errorf("expected an identical one-letter name to score 1, got %+v", d.Matches)
+		}
+		if d := short.Screen(screening.Subject{Name: "Z"}); len(d.Matches) != 0 {
+			t.Errorf("expected another letter not to match, got %+v", d.Matches)
+		}
+	})
+
+	t.Run("Threshold", func(t *testing.T) {
+		strict, err := screening.NewScreener(path, screening.WithThreshold(1))
+		if err != nil {
+			t.Fatalf("NewScreener failed: %v", err)
+		}
+		if d := strict.Screen(screening.Subject{Name: "Hernandez Peres, Jose Antonio"}); len(d.Matches) != 0 {
+			t.Errorf("expected no fuzzy match at threshold 1, got %+v", d.Matches)
+		}
+		if d := strict.Screen(screening.Subject{Name: "jose antonio hernandez perez"}); len(d.Matches) != 1 {
+			t.Errorf("expected a match on the normalised name, got %+v", d.Matches)
+		}
+		if _, err := screening.NewScreener(path, screening.WithThreshold(1.5)); err == nil {
+			t.Error("expected a threshold above 1 to be refused")
+		}
+	})
+
+	t.Run("ReloadIsVersioned", func(t *testing.T) {
+		now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+		sc, err := screening.NewScreener(path, screening.WithClock(func() time.Time { return now }))
+		if err != nil {
+			t.Fatalf("NewScreener failed: %v", err)
+		}
+		first := sc.Version()
+		if first.Number != 1 || first.Entries != 2 || len(first.Digest) != 64 || !first.LoadedAt.Equal(now) {
+			t.Fatalf("unexpected first version %+v", first)
+		}
+		if v, changed, err := sc.Reload(); err != nil || changed || v.Number != 1 {
+			t.Errorf("expected an unchanged file to keep version 1, got %+v, %v, %v", v, changed, err)
+		}
+
+		xmlPath := writeList(t, "sdn.xml", sdnXML)
+		if err := os.Rename(xmlPath, path); err != nil {
+			t.Fatal(err)
+		}
+		v, changed, err := sc.Reload()
+		if err != nil || !changed || v.Number != 2 || v.Published != "03/02/2026" {
+			t.Fatalf("expected version 2 from the XML list, got %+v, %v, %v", v, changed, err)
+		}
+		d := sc.Screen(screening.Subject{Name: "Ivan Petroff"})
+		if d.ListVersion != 2 || d.ListDigest != v.Digest || len(d.Matches) != 1 {
+			t.Errorf("expected a match recorded against version 2, got %+v", d)
+		}
+
+		if err := os.WriteFile(path, []byte("<sdnList><sdnEntry>"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := sc.Reload(); err == nil {
+			t.Error("expected a broken file to fail to load")
+		}
+		if sc.Version().Number != 2 {
+			t.Errorf("expected a failed reload to keep version 2, got %+v", sc.Version())
+		}
+	})
+}
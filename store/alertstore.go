@@ -0,0 +1,141 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrAlertNotFound = errors.New("alert not found")
+
+type AlertStatus string
+
+const (
+	// AlertOpen has not been reviewed yet.
+	AlertOpen AlertStatus = "open"
+	// AlertEscalated needs further investigation before it can be closed.
+	AlertEscalated AlertStatus = "escalated"
+	// AlertCleared was reviewed and found not to be suspicious.
+	AlertCleared AlertStatus = "cleared"
+	// AlertReported was reported to the authorities.
+	AlertReported AlertStatus = "reported"
+)
+
+// Closed reports whether the alert has a final disposition.
+func (s AlertStatus) Closed() bool {
+	return s == AlertCleared || s == AlertReported
+}
+
+// AlertDisposition is one review of an alert.
+type AlertDisposition struct {
+	Status AlertStatus `json:"status"`
+	Actor  string      `json:"actor"`
+	Note   string      `json:"note"`
+	At     time.Time   `json:"at"`
+}
+
+// Alert is raised when a monitoring rule matches an account's activity.
+// EventIDs are the events that triggered it, oldest first; DetectedAt is
+// the time of the last of them. Dispositions record every review, in order.
+type Alert struct {
+	ID           string             `json:"id"`
+	RuleID       string             `json:"ruleId"`
+	RuleType     string             `json:"ruleType"`
+	AccountID    string             `json:"accountId"`
+	Description  string             `json:"description"`
+	EventIDs     []string           `json:"eventIds"`
+	DetectedAt   time.Time          `json:"detectedAt"`
+	Status       AlertStatus        `json:"status"`
+	Dispositions []AlertDisposition `json:"dispositions,omitempty"`
+}
+
+type AlertStore interface {
+	// AddAlert adds an alert unless one with the same ID exists, in which
+	// case it reports added == false and changes nothing, so that an alert
+	// raised again when events are redelivered keeps its dispositions.
+	AddAlert(ctx context.Context, a Alert) (added bool, err error)
+
+	UpdateAlert(ctx context.Context, a Alert) error
+	GetAlert(ctx context.Context, id string) (Alert, error)
+
+	// ListAlerts returns alerts with the given status, or every alert if
+	// status is empty, oldest first.
+	ListAlerts(ctx context.Context, status AlertStatus) ([]Alert, error)
+}
+
+type InMemoryAlertStore struct {
+	sync.RWMutex
+	alerts map[string]Alert
+	order  []string // alert IDs in the order they were added
+}
+
+func NewInMemoryAlertStore() *InMemoryAlertStore {
+	return &InMemoryAlertStore{alerts: make(map[string]Alert)}
+}
+
+func copyAlert(a Alert) Alert {
+	a.EventIDs = append([]string(nil), a.EventIDs...)
+	a.Dispositions = append([]AlertDisposition(nil), a.Dispositions...)
+	return a
+}
+
+func (s *InMemoryAlertStore) AddAlert(ctx context.Context, a Alert) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, fmt.Errorf("add alert %s: %w", a.ID, err)
+	}
+	if a.ID == "" {
+		return false, errors.New("alert must have an ID")
+	}
+	s.Lock()
+	defer s.Unlock()
+	if _, exists := s.alerts[a.ID]; exists {
+		return false, nil
+	}
+	s.alerts[a.ID] = copyAlert(a)
+	s.order = append(s.order, a.ID)
+	return true, nil
+}
+
+func (s *InMemoryAlertStore) UpdateAlert(ctx context.Context, a Alert) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("update alert %s: %w", a.ID, err)
+	}
+	s.Lock()
+	defer s.Unlock()
+	if _, ok := s.alerts[a.ID]; !ok {
+		return fmt.Errorf("%w: %s", ErrAlertNotFound, a.ID)
+	}
+	s.alerts[a.ID] = copyAlert(a)
+	return nil
+}
+
+func (s *InMemoryAlertStore) GetAlert(ctx context.Context, id string) (Alert, error) {
+	if err := ctx.Err(); err != nil {
+		return Alert{}, fmt.Errorf("get alert %s: %w", id, err)
+	}
+	s.RLock()
+	defer s.RUnlock()
+	a, ok := s.alerts[id]
+	if !ok {
+		return Alert{}, fmt.Errorf("%w: %s", ErrAlertNotFound, id)
+	}
+	return copyAlert(a), nil
+}
+
+func (s *InMemoryAlertStore) ListAlerts(ctx context.Context, status AlertStatus) ([]Alert, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("list alerts: %w", err)
+	}
+	s.RLock()
+	defer s.RUnlock()
+	var out []Alert
+	for _, id := range s.order {
+		a := s.alerts[id]
+		if status == "" || a.Status == status {
+			out = append(out, copyAlert(a))
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,52 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"financial-ledger/store"
+)
+
+func TestInMemoryAlertStore(t *testing.T) {
+	ctx := context.Background()
+	as := store.NewInMemoryAlertStore()
+
+	eventIDs := []string{"ev-1"}
+	first := store.Alert{ID: "al-1", AccountID: "acc-1", EventIDs: eventIDs, Status: store.AlertOpen}
+	if added, err := as.AddAlert(ctx, first); err != nil || !added {
+		t.Fatalf("AddAlert = %v, %v; want added", added, err)
+	}
+	eventIDs[0] = "ev-9"
+	first.Status = store.AlertCleared
+	if added, err := as.AddAlert(ctx, first); err != nil || added {
+		t.Errorf("AddAlert of an existing alert = %v, %v; want not added", added, err)
+	}
+	_, _ = as.AddAlert(ctx, store.Alert{ID: "al-2", Status: store.AlertOpen})
+
+	got, err := as.GetAlert(ctx, "al-1")
+	if err != nil || got.EventIDs[0] != "ev-1" || got.Status != store.AlertOpen {
+		t.Fatalf("GetAlert = %+v, %v; want the first version, unaliased", got, err)
+	}
+
+	got.Status = store.AlertEscalated
+	got.Dispositions = append(got.Dispositions, store.AlertDisposition{Status: store.AlertEscalated, Actor: "carol", Note: "ask branch"})
+	if err := as.UpdateAlert(ctx, got); err != nil {
+		t.Fatalf("UpdateAlert failed: %v", err)
+	}
+	if err := as.UpdateAlert(ctx, store.Alert{ID: "missing"}); !errors.Is(err, store.ErrAlertNotFound) {
+		t.Errorf("expected ErrAlertNotFound, got %v", err)
+	}
+	if _, err := as.GetAlert(ctx, "missing"); !errors.Is(err, store.ErrAlertNotFound) {
+		t.Errorf("expected ErrAlertNotFound, got %v", err)
+	}
+
+	open, _ := as.ListAlerts(ctx, store.AlertOpen)
+	if len(open) != 1 || open[0].ID != "al-2" {
+		t.Errorf("expected only al-2 open, got %+v", open)
+	}
+	all, _ := as.ListAlerts(ctx, "")
+	if len(all) != 2 || all[0].ID != "al-1" || len(all[0].Dispositions) != 1 {
+		t.Errorf("expected both alerts in the order added, got %+v", all)
+	}
+}
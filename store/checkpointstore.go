@@ -0,0 +1,81 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Checkpoint records the Merkle root over the first TreeSize events of the
+// global log. KeyID and Signature are set when a signer is configured.
+type Checkpoint struct {
+	TreeSize  int64     `json:"treeSize"`
+	RootHash  string    `json:"rootHash"`
+	Timestamp time.Time `json:"timestamp"`
+	KeyID     string    `json:"keyId,omitempty"`
+	Signature string    `json:"signature,omitempty"`
+}
+
+// SigningPayload returns the bytes covered by the checkpoint signature.
+func (c Checkpoint) SigningPayload() []byte {
+	return []byte(fmt.Sprintf("ledger-checkpoint\n%d\n%s\n%s", c.TreeSize, c.RootHash, c.Timestamp.UTC().Format(time.RFC3339Nano)))
+}
+
+type CheckpointStore interface {
+	SaveCheckpoint(ctx context.Context, checkpoint Checkpoint) error
+
+	GetLatestCheckpoint(ctx context.Context) (checkpoint Checkpoint, found bool, err error)
+
+	ListCheckpoints(ctx context.Context) ([]Checkpoint, error)
+}
+
+type InMemoryCheckpointStore struct {
+	sync.RWMutex
+	checkpoints []Checkpoint
+}
+
+func NewInMemoryCheckpointStore() *InMemoryCheckpointStore {
+	return &InMemoryCheckpointStore{}
+}
+
+func (s *InMemoryCheckpointStore) SaveCheckpoint(ctx context.Context, checkpoint Checkpoint) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+	s.Lock()
+	defer s.Unlock()
+
+	if n := len(s.checkpoints); n > 0 && checkpoint.TreeSize <= s.checkpoints[n-1].TreeSize {
+		return fmt.Errorf("checkpoint tree size %d does not extend latest checkpoint (size %d)", checkpoint.TreeSize, s.checkpoints[n-1].TreeSize)
+	}
+	s.checkpoints = append(s.checkpoints, checkpoint)
+	return nil
+}
+
+func (s *InMemoryCheckpointStore) GetLatestCheckpoint(ctx context.Context) (Checkpoint, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return Checkpoint{}, false, fmt.Errorf("get latest checkpoint: %w", err)
+	}
+	s.RLock()
+	defer s.RUnlock()
+
+	if len(s.checkpoints) == 0 {
+		return Checkpoint{}, false, nil
+	}
+	return s.checkpoints[len(s.checkpoints)-1], true, nil
+}
+
+func (s *InMemoryCheckpointStore) ListCheckpoints(ctx context.Context) ([]Checkpoint, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("list checkpoints: %w", err)
+	}
+	s.RLock()
+	defer s.RUnlock()
+
+	result := make([]Checkpoint, len(s.checkpoints))
+	copy(result, s.checkpoints)
+	sort.Slice(result, func(i, j int) bool { return result[i].TreeSize < result[j].TreeSize })
+	return result, nil
+}
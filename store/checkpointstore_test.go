@@ -0,0 +1,52 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+
+	"financial-ledger/store"
+)
+
+func TestInMemoryCheckpointStore(t *testing.T) {
+	ctx := context.Background()
+	cs := store.NewInMemoryCheckpointStore()
+
+	t.Run("EmptyStore", func(t *testing.T) {
+		_, found, err := cs.GetLatestCheckpoint(ctx)
+		if err != nil || found {
+			t.Errorf("Expected no checkpoint, found=%v err=%v", found, err)
+		}
+	})
+
+	t.Run("SaveAndGetLatest", func(t *testing.T) {
+		if err := cs.SaveCheckpoint(ctx, store.Checkpoint{TreeSize: 3, RootHash: "r3"}); err != nil {
+			t.Fatalf("SaveCheckpoint failed: %v", err)
+		}
+		if err := cs.SaveCheckpoint(ctx, store.Checkpoint{TreeSize: 7, RootHash: "r7"}); err != nil {
+			t.Fatalf("SaveCheckpoint failed: %v", err)
+		}
+		latest, found, err := cs.GetLatestCheckpoint(ctx)
+		if err != nil || !found {
+			t.Fatalf("Expected latest checkpoint, err: %v", err)
+		}
+		if latest.TreeSize != 7 || latest.RootHash != "r7" {
+			t.Errorf("Unexpected latest checkpoint: %+v", latest)
+		}
+	})
+
+	t.Run("RejectNonIncreasingTreeSize", func(t *testing.T) {
+		if err := cs.SaveCheckpoint(ctx, store.Checkpoint{TreeSize: 7, RootHash: "other"}); err == nil {
+			t.Errorf("Expected error for checkpoint that does not extend the latest")
+		}
+	})
+
+	t.Run("ListInOrder", func(t *testing.T) {
+		list, err := cs.ListCheckpoints(ctx)
+		if err != nil {
+			t.Fatalf("ListCheckpoints failed: %v", err)
+		}
+		if len(list) != 2 || list[0].TreeSize != 3 || list[1].TreeSize != 7 {
+			t.Errorf("Unexpected checkpoint list: %+v", list)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// DataKeyStore keeps an Encryptor's data keys, wrapped under the master key,
+// so that ciphertext written by one process can be opened by the next. It must
+// be as durable as the stores whose payloads the keys seal.
+type DataKeyStore interface {
+	// SaveDataKey stores key, replacing any key with the same ID.
+	SaveDataKey(ctx context.Context, key WrappedDataKey) error
+
+	// DeleteDataKey removes a retired key. Deleting a missing key is not an error.
+	DeleteDataKey(ctx context.Context, id string) error
+
+	// ListDataKeys returns every stored key, oldest first.
+	ListDataKeys(ctx context.Context) ([]WrappedDataKey, error)
+}
+
+type InMemoryDataKeyStore struct {
+	sync.RWMutex
+	keys map[string]WrappedDataKey
+}
+
+func NewInMemoryDataKeyStore() *InMemoryDataKeyStore {
+	return &InMemoryDataKeyStore{keys: make(map[string]WrappedDataKey)}
+}
+
+func (s *InMemoryDataKeyStore) SaveDataKey(ctx context.Context, key WrappedDataKey) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("save data key %s: %w", key.ID, err)
+	}
+	if key.ID == "" {
+		return fmt.Errorf("cannot save data key with empty ID")
+	}
+	s.Lock()
+	defer s.Unlock()
+	s.keys[key.ID] = copyWrappedDataKey(key)
+	return nil
+}
+
+func (s *InMemoryDataKeyStore) DeleteDataKey(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("delete data key %s: %w", id, err)
+	}
+	s.Lock()
+	defer s.Unlock()
+	delete(s.keys, id)
+	return nil
+}
+
+func (s *InMemoryDataKeyStore) ListDataKeys(ctx context.Context) ([]WrappedDataKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("list data keys: %w", err)
+	}
+	s.RLock()
+	defer s.RUnlock()
+	keys := make([]WrappedDataKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, copyWrappedDataKey(key))
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].Created.Equal(keys[j].Created) {
+			return keys[i].Created.Before(keys[j].Created)
+		}
+		return keys[i].ID < keys[j].ID
+	})
+	return keys, nil
+}
+
+func copyWrappedDataKey(key WrappedDataKey) WrappedDataKey {
+	key.Wrapped.Nonce = append([]byte(nil), key.Wrapped.Nonce...)
+	key.Wrapped.Data = append([]byte(nil), key.Wrapped.Data...)
+	return key
+}
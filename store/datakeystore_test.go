@@ -0,0 +1,55 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"financial-ledger/store"
+)
+
+func TestInMemoryDataKeyStore(t *testing.T) {
+	ctx := context.Background()
+	ks := store.NewInMemoryDataKeyStore()
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, key := range []store.WrappedDataKey{
+		{ID: "dk:03", Created: created.Add(time.Hour), Wrapped: store.Ciphertext{Data: []byte{1}}},
+		{ID: "dk:02", Created: created},
+		{ID: "dk:01", Created: created},
+	} {
+		if err := ks.SaveDataKey(ctx, key); err != nil {
+			t.Fatalf("SaveDataKey failed: %v", err)
+		}
+	}
+
+	t.Run("ListOrdered", func(t *testing.T) {
+		keys, err := ks.ListDataKeys(ctx)
+		if err != nil || len(keys) != 3 || keys[0].ID != "dk:01" || keys[1].ID != "dk:02" || keys[2].ID != "dk:03" {
+			t.Fatalf("expected keys ordered oldest first, got %+v, %v", keys, err)
+		}
+		keys[2].Wrapped.Data[0] = 9
+		again, _ := ks.ListDataKeys(ctx)
+		if again[2].Wrapped.Data[0] != 1 {
+			t.Errorf("ListDataKeys must return copies")
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if err := ks.DeleteDataKey(ctx, "dk:01"); err != nil {
+			t.Fatalf("DeleteDataKey failed: %v", err)
+		}
+		if err := ks.DeleteDataKey(ctx, "dk:missing"); err != nil {
+			t.Errorf("deleting a missing key should succeed, got %v", err)
+		}
+		if keys, _ := ks.ListDataKeys(ctx); len(keys) != 2 {
+			t.Errorf("expected 2 keys left, got %+v", keys)
+		}
+	})
+
+	t.Run("EmptyID", func(t *testing.T) {
+		if err := ks.SaveDataKey(ctx, store.WrappedDataKey{}); err == nil {
+			t.Error("expected an error for an empty key ID")
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// AccessDenial records a request that was refused because its caller could
+// not be authenticated or lacked permission. Actor is empty when
+// authentication failed. Credentials are never recorded.
+type AccessDenial struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Actor      string    `json:"actor"`
+	Roles      []string  `json:"roles,omitempty"`
+	Channel    string    `json:"channel,omitempty"`
+	Operation  string    `json:"operation"`
+	Detail     string    `json:"detail,omitempty"`
+	AccountIDs []string  `json:"accountIds,omitempty"`
+	Reason     string    `json:"reason"`
+}
+
+// DenialStore is an append-only log of access denials.
+type DenialStore interface {
+	RecordDenial(ctx context.Context, d AccessDenial) error
+
+	// ListDenials returns denials recorded at or after since, oldest first.
+	ListDenials(ctx context.Context, since time.Time) ([]AccessDenial, error)
+}
+
+type InMemoryDenialStore struct {
+	sync.RWMutex
+	denials []AccessDenial
+}
+
+func NewInMemoryDenialStore() *InMemoryDenialStore {
+	return &InMemoryDenialStore{}
+}
+
+func (s *InMemoryDenialStore) RecordDenial(ctx context.Context, d AccessDenial) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("record access denial: %w", err)
+	}
+	s.Lock()
+	defer s.Unlock()
+	d.Roles = append([]string(nil), d.Roles...)
+	d.AccountIDs = append([]string(nil), d.AccountIDs...)
+	s.denials = append(s.denials, d)
+	return nil
+}
+
+func (s *InMemoryDenialStore) ListDenials(ctx context.Context, since time.Time) ([]AccessDenial, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("list access denials: %w", err)
+	}
+	s.RLock()
+	defer s.RUnlock()
+	var out []AccessDenial
+	for _, d := range s.denials {
+		if !d.Timestamp.Before(since) {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
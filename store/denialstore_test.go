@@ -0,0 +1,32 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"financial-ledger/store"
+)
+
+func TestInMemoryDenialStore(t *testing.T) {
+	ctx := context.Background()
+	ds := store.NewInMemoryDenialStore()
+	start := time.Now().UTC()
+
+	roles := []string{"readonly"}
+	_ = ds.RecordDenial(ctx, store.AccessDenial{Timestamp: start, Actor: "bob", Roles: roles, Operation: "Withdraw", Reason: "missing permission"})
+	_ = ds.RecordDenial(ctx, store.AccessDenial{Timestamp: start.Add(time.Minute), Operation: "authenticate", Reason: "unknown API key"})
+	roles[0] = "admin"
+
+	all, err := ds.ListDenials(ctx, time.Time{})
+	if err != nil || len(all) != 2 {
+		t.Fatalf("expected 2 denials, got %d (err: %v)", len(all), err)
+	}
+	if all[0].Roles[0] != "readonly" {
+		t.Errorf("stored denial must not alias the caller's slice, got roles %v", all[0].Roles)
+	}
+	recent, _ := ds.ListDenials(ctx, start.Add(time.Second))
+	if len(recent) != 1 || recent[0].Operation != "authenticate" {
+		t.Errorf("expected only the later denial, got %+v", recent)
+	}
+}
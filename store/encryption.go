@@ -0,0 +1,451 @@
+package store
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"financial-ledger/events"
+)
+
+var (
+	ErrUnknownDataKey   = errors.New("unknown data key")
+	ErrDecryptFailed    = errors.New("decryption failed")
+	ErrUnknownMasterKey = errors.New("unknown master key")
+)
+
+// Ciphertext is an AES-GCM sealed payload together with the ID of the data key
+// that sealed it. It is what the stores keep at rest instead of plaintext.
+type Ciphertext struct {
+	KeyID string `json:"keyId"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+// MasterKey is a 256-bit key-encryption key. It never encrypts payloads
+// directly; it only wraps the data keys that do.
+type MasterKey struct {
+	id  string
+	key []byte
+}
+
+// NewMasterKey wraps raw 32-byte key material.
+func NewMasterKey(key []byte) (*MasterKey, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("master key must be 32 bytes, got %d", len(key))
+	}
+	sum := sha256.Sum256(append([]byte("ledger-master-key\n"), key...))
+	return &MasterKey{id: "mk:" + hex.EncodeToString(sum[:8]), key: append([]byte(nil), key...)}, nil
+}
+
+// LoadMasterKey reads a master key file holding 64 hex characters.
+func LoadMasterKey(path string) (*MasterKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read master key: %w", err)
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("master key %s is not hex encoded: %w", path, err)
+	}
+	return NewMasterKey(key)
+}
+
+// GenerateMasterKeyFile writes a new random master key to path (mode 0600) and
+// returns its key ID.
+func GenerateMasterKeyFile(path string) (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate master key: %w", err)
+	}
+	mk, _ := NewMasterKey(key)
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(key)+"\n"), 0o600); err != nil {
+		return "", fmt.Errorf("failed to write master key: %w", err)
+	}
+	return mk.id, nil
+}
+
+func (k *MasterKey) ID() string {
+	return k.id
+}
+
+// WrappedDataKey is a data key as held at rest: encrypted under a master key.
+// The ID is random, and the scope the key belongs to is sealed with the key
+// itself, so a key store does not list the aggregates or data subjects whose
+// payloads its keys seal.
+type WrappedDataKey struct {
+	ID          string     `json:"id"`
+	MasterKeyID string     `json:"masterKeyId"`
+	Wrapped     Ciphertext `json:"wrapped"`
+	Created     time.Time  `json:"created"`
+}
+
+// dataKeyMaterial is what a WrappedDataKey seals. Seq numbers the keys of a
+// scope; the highest is the one in use.
+type dataKeyMaterial struct {
+	Scope string `json:"scope"`
+	Seq   int    `json:"seq"`
+	Key   []byte `json:"key"`
+}
+
+// Encryptor implements envelope encryption. Each scope (an aggregate, or a
+// tenant when WithTenantScope is used) gets its own AES-256 data key; data keys
+// are stored only in wrapped form, in a DataKeyStore, and unwrapped on use.
+type Encryptor struct {
+	sync.RWMutex
+	master  *MasterKey
+	scopeOf func(aggregateID string) string
+	keys    DataKeyStore
+
+	dataKeys map[string]*WrappedDataKey // by data key ID, as in keys
+	scopes   map[string]string          // data key ID -> scope
+	current  map[string]string          // scope -> ID of the data key used for new ciphertext
+	counter  map[string]int             // scope -> number of data keys created
+}
+
+// EncryptorOption customises an Encryptor.
+type EncryptorOption func(*Encryptor)
+
+// WithTenantScope shares one data key between all aggregates for which tenantOf
+// returns the same tenant. By default every aggregate has its own data key.
+func WithTenantScope(tenantOf func(aggregateID string) string) EncryptorOption {
+	return func(e *Encryptor) {
+		e.scopeOf = tenantOf
+	}
+}
+
+// NewEncryptor returns an Encryptor whose data keys are kept in memory only.
+// Use LoadEncryptor to keep them where a later process can find them.
+func NewEncryptor(master *MasterKey, opts ...EncryptorOption) *Encryptor {
+	e := &Encryptor{
+		master:   master,
+		scopeOf:  func(aggregateID string) string { return aggregateID },
+		keys:     NewInMemoryDataKeyStore(),
+		dataKeys: make(map[string]*WrappedDataKey),
+		scopes:   make(map[string]string),
+		current:  make(map[string]string),
+		counter:  make(map[string]int),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// LoadEncryptor returns an Encryptor that saves every data key it creates or
+// re-wraps to keys, starting from the keys already there. The keys wrapped
+// under master are unwrapped once to learn their scopes.
+func LoadEncryptor(ctx context.Context, master *MasterKey, keys DataKeyStore, opts ...EncryptorOption) (*Encryptor, error) {
+	e := NewEncryptor(master, opts...)
+	e.keys = keys
+	stored, err := keys.ListDataKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load data keys: %w", err)
+	}
+	for _, wk := range stored {
+		wk := wk
+		if wk.MasterKeyID != master.id {
+			// Wrapped under another master key: kept so that Open reports
+			// ErrUnknownMasterKey, but it cannot be current for any scope.
+			e.dataKeys[wk.ID] = &wk
+			continue
+		}
+		material, err := e.unwrapLocked(&wk)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load data keys: %w", err)
+		}
+		e.dataKeys[wk.ID] = &wk
+		e.scopes[wk.ID] = material.Scope
+		if material.Seq > e.counter[material.Scope] {
+			e.counter[material.Scope] = material.Seq
+			e.current[material.Scope] = wk.ID
+		}
+	}
+	return e, nil
+}
+
+// MasterKeyID returns the ID of the master key currently wrapping data keys.
+func (e *Encryptor) MasterKeyID() string {
+	e.RLock()
+	defer e.RUnlock()
+	return e.master.id
+}
+
+// Seal encrypts plaintext under the current data key for aggregateID's scope,
+// creating that key on first use. aad is authenticated but not encrypted; pass
+// the same value to Open.
+func (e *Encryptor) Seal(aggregateID string, plaintext, aad []byte) (Ciphertext, error) {
+	keyID, key, err := e.currentDataKey(e.scopeOf(aggregateID))
+	if err != nil {
+		return Ciphertext{}, err
+	}
+	sealed, err := seal(key, plaintext, aad)
+	if err != nil {
+		return Ciphertext{}, err
+	}
+	sealed.KeyID = keyID
+	return sealed, nil
+}
+
+// Open decrypts c with the data key named by its KeyID.
+func (e *Encryptor) Open(c Ciphertext, aad []byte) ([]byte, error) {
+	e.RLock()
+	wk, ok := e.dataKeys[c.KeyID]
+	if !ok {
+		e.RUnlock()
+		return nil, fmt.Errorf("%w: %q", ErrUnknownDataKey, c.KeyID)
+	}
+	material, err := e.unwrapLocked(wk)
+	e.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	return open(material.Key, c, aad)
+}
+
+// IsCurrent reports whether c was sealed with the data key that Seal would use
+// now for aggregateID. Stores use it to skip payloads that need no re-encryption.
+func (e *Encryptor) IsCurrent(aggregateID string, c Ciphertext) bool {
+	e.RLock()
+	defer e.RUnlock()
+	return e.current[e.scopeOf(aggregateID)] == c.KeyID
+}
+
+// RotateMasterKey re-wraps every data key under newMaster and saves it. Payloads
+// are not touched, and once this returns the old master key file can be
+// destroyed. If it fails, the old master key stays in use; run it again.
+func (e *Encryptor) RotateMasterKey(newMaster *MasterKey) (int, error) {
+	e.Lock()
+	defer e.Unlock()
+
+	rewrapped := make(map[string]*WrappedDataKey, len(e.dataKeys))
+	for id, wk := range e.dataKeys {
+		material, err := e.unwrapLocked(wk)
+		if err != nil {
+			return 0, err
+		}
+		wrapped, err := wrapDataKey(newMaster, wk.ID, material)
+		if err != nil {
+			return 0, fmt.Errorf("failed to re-wrap data key %s: %w", id, err)
+		}
+		next := *wk
+		next.MasterKeyID = newMaster.id
+		next.Wrapped = wrapped
+		rewrapped[id] = &next
+	}
+	for id, wk := range rewrapped {
+		if err := e.keys.SaveDataKey(context.Background(), *wk); err != nil {
+			return 0, fmt.Errorf("failed to save re-wrapped data key %s: %w", id, err)
+		}
+	}
+	e.dataKeys = rewrapped
+	e.master = newMaster
+	return len(rewrapped), nil
+}
+
+// RotateDataKeys starts a new data key for every scope. Existing ciphertext
+// stays readable; run the stores' Reencrypt to move it to the new keys, then
+// RetireDataKeys to drop the old ones.
+func (e *Encryptor) RotateDataKeys() (int, error) {
+	e.RLock()
+	scopes := make([]string, 0, len(e.current))
+	for scope := range e.current {
+		scopes = append(scopes, scope)
+	}
+	e.RUnlock()
+	sort.Strings(scopes)
+
+	for _, scope := range scopes {
+		if _, _, err := e.newDataKey(scope); err != nil {
+			return 0, err
+		}
+	}
+	return len(scopes), nil
+}
+
+// RetireDataKeys deletes every data key that is no longer current and whose ID
+// is not in inUse, the union of the stores' DataKeysInUse. Keys are never
+// retired by rotation alone, so old ciphertext stays readable until every
+// store holding it has been re-encrypted. It returns the number of keys deleted.
+func (e *Encryptor) RetireDataKeys(ctx context.Context, inUse map[string]bool) (int, error) {
+	e.Lock()
+	defer e.Unlock()
+
+	current := make(map[string]bool, len(e.current))
+	for _, id := range e.current {
+		current[id] = true
+	}
+	ids := make([]string, 0, len(e.dataKeys))
+	for id := range e.dataKeys {
+		if !current[id] && !inUse[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for i, id := range ids {
+		if err := e.keys.DeleteDataKey(ctx, id); err != nil {
+			return i, fmt.Errorf("failed to retire data key %s: %w", id, err)
+		}
+		delete(e.dataKeys, id)
+		delete(e.scopes, id)
+	}
+	return len(ids), nil
+}
+
+func (e *Encryptor) currentDataKey(scope string) (string, []byte, error) {
+	e.RLock()
+	wk, ok := e.dataKeys[e.current[scope]]
+	if !ok {
+		e.RUnlock()
+		return e.newDataKey(scope)
+	}
+	material, err := e.unwrapLocked(wk)
+	e.RUnlock()
+	if err != nil {
+		return "", nil, err
+	}
+	return wk.ID, material.Key, nil
+}
+
+func (e *Encryptor) newDataKey(scope string) (string, []byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	rawID := make([]byte, 16)
+	if _, err := rand.Read(rawID); err != nil {
+		return "", nil, fmt.Errorf("failed to generate data key ID: %w", err)
+	}
+	id := "dk:" + hex.EncodeToString(rawID)
+
+	e.Lock()
+	defer e.Unlock()
+	seq := e.counter[scope] + 1
+	wrapped, err := wrapDataKey(e.master, id, dataKeyMaterial{Scope: scope, Seq: seq, Key: key})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to wrap data key %s: %w", id, err)
+	}
+	wk := &WrappedDataKey{ID: id, MasterKeyID: e.master.id, Wrapped: wrapped, Created: time.Now().UTC()}
+	// Nothing may be sealed under a key that a later process could not find.
+	if err := e.keys.SaveDataKey(context.Background(), *wk); err != nil {
+		return "", nil, fmt.Errorf("failed to save data key %s: %w", id, err)
+	}
+	e.counter[scope] = seq
+	e.dataKeys[id] = wk
+	e.scopes[id] = scope
+	e.current[scope] = id
+	return id, key, nil
+}
+
+// wrapDataKey seals material under master, bound to the key's ID.
+func wrapDataKey(master *MasterKey, id string, material dataKeyMaterial) (Ciphertext, error) {
+	plaintext, err := json.Marshal(material)
+	if err != nil {
+		return Ciphertext{}, fmt.Errorf("failed to encode data key %s: %w", id, err)
+	}
+	return seal(master.key, plaintext, []byte(id))
+}
+
+func (e *Encryptor) unwrapLocked(wk *WrappedDataKey) (dataKeyMaterial, error) {
+	if wk.MasterKeyID != e.master.id {
+		return dataKeyMaterial{}, fmt.Errorf("%w: data key %s is wrapped by %s", ErrUnknownMasterKey, wk.ID, wk.MasterKeyID)
+	}
+	plaintext, err := open(e.master.key, wk.Wrapped, []byte(wk.ID))
+	if err != nil {
+		return dataKeyMaterial{}, fmt.Errorf("failed to unwrap data key %s: %w", wk.ID, err)
+	}
+	var material dataKeyMaterial
+	if err := json.Unmarshal(plaintext, &material); err != nil {
+		return dataKeyMaterial{}, fmt.Errorf("failed to decode data key %s: %w", wk.ID, err)
+	}
+	return material, nil
+}
+
+func seal(key, plaintext, aad []byte) (Ciphertext, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return Ciphertext{}, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return Ciphertext{}, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return Ciphertext{Nonce: nonce, Data: gcm.Seal(nil, nonce, plaintext, aad)}, nil
+}
+
+func open(key []byte, c Ciphertext, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, c.Nonce, c.Data, aad)
+	if err != nil {
+		return nil, fmt.Errorf("%w: key %s: %v", ErrDecryptFailed, c.KeyID, err)
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialise AES: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealedEvent is how an event is held at rest when payload encryption is on.
+// The BaseEvent stays readable for concurrency checks, chaining and indexing;
+// the full event is kept only as ciphertext.
+type sealedEvent struct {
+	events.BaseEvent
+	Payload Ciphertext
+}
+
+func eventAAD(base events.BaseEvent) []byte {
+	return []byte("ledger-event\n" + base.EventID.String())
+}
+
+func sealEvent(enc *Encryptor, event events.Event) (events.Event, error) {
+	base := event.GetBase()
+	plaintext, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialise event %s for encryption: %w", base.EventID, err)
+	}
+	payload, err := enc.Seal(base.AggregateID, plaintext, eventAAD(base))
+	if err != nil {
+		return nil, err
+	}
+	return sealedEvent{BaseEvent: base, Payload: payload}, nil
+}
+
+// openEvent decrypts a sealed event; events stored in plaintext pass through.
+func openEvent(enc *Encryptor, stored events.Event) (events.Event, error) {
+	sealed, ok := stored.(sealedEvent)
+	if !ok {
+		return stored, nil
+	}
+	if enc == nil {
+		return nil, fmt.Errorf("event %s is encrypted but no encryptor is configured", sealed.EventID)
+	}
+	plaintext, err := enc.Open(sealed.Payload, eventAAD(sealed.BaseEvent))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt event %s: %w", sealed.EventID, err)
+	}
+	event, err := events.Unmarshal(sealed.Type, plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode decrypted event %s: %w", sealed.EventID, err)
+	}
+	return event, nil
+}
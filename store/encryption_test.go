@@ -0,0 +1,276 @@
+package store_test
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"financial-ledger/domain"
+	"financial-ledger/events"
+	"financial-ledger/store"
+)
+
+func init() {
+	events.RegisterEventType("TestEvent", TestEvent{})
+}
+
+func newTestMasterKey(t *testing.T) *store.MasterKey {
+	t.Helper()
+	raw := make([]byte, 32)
+	_, _ = rand.Read(raw)
+	mk, err := store.NewMasterKey(raw)
+	if err != nil {
+		t.Fatalf("NewMasterKey failed: %v", err)
+	}
+	return mk
+}
+
+func TestEncryptor(t *testing.T) {
+	enc := store.NewEncryptor(newTestMasterKey(t))
+
+	t.Run("SealAndOpen", func(t *testing.T) {
+		c, err := enc.Seal("acc-1", []byte("balance 100"), []byte("aad"))
+		if err != nil {
+			t.Fatalf("Seal failed: %v", err)
+		}
+		if !strings.HasPrefix(c.KeyID, "dk:") || strings.Contains(c.KeyID, "acc-1") || strings.Contains(string(c.Data), "balance") {
+			t.Errorf("unexpected ciphertext: %+v", c)
+		}
+		plain, err := enc.Open(c, []byte("aad"))
+		if err != nil || string(plain) != "balance 100" {
+			t.Errorf("Open returned %q, %v", plain, err)
+		}
+		if _, err := enc.Open(c, []byte("other")); !errors.Is(err, store.ErrDecryptFailed) {
+			t.Errorf("expected ErrDecryptFailed for wrong AAD, got %v", err)
+		}
+	})
+
+	t.Run("PerAggregateKeys", func(t *testing.T) {
+		a, _ := enc.Seal("acc-1", []byte("x"), nil)
+		b, _ := enc.Seal("acc-2", []byte("x"), nil)
+		if a.KeyID == b.KeyID {
+			t.Errorf("expected distinct data keys per aggregate, both used %s", a.KeyID)
+		}
+	})
+
+	t.Run("TenantScope", func(t *testing.T) {
+		tenantEnc := store.NewEncryptor(newTestMasterKey(t), store.WithTenantScope(func(id string) string {
+			return strings.SplitN(id, "/", 2)[0]
+		}))
+		a, _ := tenantEnc.Seal("bank-a/acc-1", []byte("x"), nil)
+		b, _ := tenantEnc.Seal("bank-a/acc-2", []byte("x"), nil)
+		c, _ := tenantEnc.Seal("bank-b/acc-1", []byte("x"), nil)
+		if a.KeyID != b.KeyID || a.KeyID == c.KeyID {
+			t.Errorf("expected one data key per tenant, got %s, %s, %s", a.KeyID, b.KeyID, c.KeyID)
+		}
+	})
+
+	t.Run("RotateMasterKey", func(t *testing.T) {
+		c, _ := enc.Seal("acc-1", []byte("before rotation"), nil)
+		newMaster := newTestMasterKey(t)
+		if _, err := enc.RotateMasterKey(newMaster); err != nil {
+			t.Fatalf("RotateMasterKey failed: %v", err)
+		}
+		if enc.MasterKeyID() != newMaster.ID() {
+			t.Errorf("expected master key %s, got %s", newMaster.ID(), enc.MasterKeyID())
+		}
+		if plain, err := enc.Open(c, nil); err != nil || string(plain) != "before rotation" {
+			t.Errorf("expected old ciphertext to stay readable, got %q, %v", plain, err)
+		}
+	})
+
+	t.Run("RotateDataKeys", func(t *testing.T) {
+		before, _ := enc.Seal("acc-1", []byte("x"), nil)
+		if _, err := enc.RotateDataKeys(); err != nil {
+			t.Fatalf("RotateDataKeys failed: %v", err)
+		}
+		after, _ := enc.Seal("acc-1", []byte("x"), nil)
+		if before.KeyID == after.KeyID || enc.IsCurrent("acc-1", before) || !enc.IsCurrent("acc-1", after) {
+			t.Errorf("expected a new current data key, before %s after %s", before.KeyID, after.KeyID)
+		}
+	})
+
+	t.Run("UnknownDataKey", func(t *testing.T) {
+		_, err := enc.Open(store.Ciphertext{KeyID: "dk:missing:1"}, nil)
+		if !errors.Is(err, store.ErrUnknownDataKey) {
+			t.Errorf("expected ErrUnknownDataKey, got %v", err)
+		}
+	})
+}
+
+func TestLoadEncryptor(t *testing.T) {
+	ctx := context.Background()
+	master := newTestMasterKey(t)
+	keys := store.NewInMemoryDataKeyStore()
+	enc, err := store.LoadEncryptor(ctx, master, keys)
+	if err != nil {
+		t.Fatalf("LoadEncryptor failed: %v", err)
+	}
+	es := store.NewInMemoryEventStore(store.WithPayloadEncryption(enc))
+	_ = es.SaveEvents("agg-a", 0, []events.Event{newTestEvent("agg-a", 1, "secret-1")})
+	c, _ := enc.Seal("agg-a", []byte("x"), nil)
+
+	t.Run("RestartedProcessOpens", func(t *testing.T) {
+		restarted, err := store.LoadEncryptor(ctx, master, keys)
+		if err != nil {
+			t.Fatalf("LoadEncryptor failed: %v", err)
+		}
+		if plain, err := restarted.Open(c, nil); err != nil || string(plain) != "x" {
+			t.Errorf("expected the restarted encryptor to open old ciphertext, got %q, %v", plain, err)
+		}
+		if !restarted.IsCurrent("agg-a", c) {
+			t.Errorf("expected the stored key to stay current")
+		}
+		stored, _ := keys.ListDataKeys(ctx)
+		for _, k := range stored {
+			if raw, _ := json.Marshal(k); strings.Contains(string(raw), "agg-a") {
+				t.Errorf("stored data key names its aggregate: %s", raw)
+			}
+		}
+		next, _ := restarted.Seal("agg-b", []byte("y"), nil)
+		if plain, err := enc.Open(next, nil); err == nil {
+			t.Errorf("a key created after the restart must not be known to the old process, opened %q", plain)
+		}
+	})
+
+	t.Run("RotatedMasterKeySaved", func(t *testing.T) {
+		newMaster := newTestMasterKey(t)
+		if _, err := enc.RotateMasterKey(newMaster); err != nil {
+			t.Fatalf("RotateMasterKey failed: %v", err)
+		}
+		restarted, _ := store.LoadEncryptor(ctx, newMaster, keys)
+		if _, err := restarted.Open(c, nil); err != nil {
+			t.Errorf("expected keys re-wrapped under the new master key, got %v", err)
+		}
+	})
+
+	t.Run("RetireDataKeys", func(t *testing.T) {
+		old := c.KeyID
+		_, _ = enc.RotateDataKeys()
+		inUse, _ := es.DataKeysInUse(ctx)
+		if n, err := enc.RetireDataKeys(ctx, inUse); err != nil || n != 0 {
+			t.Fatalf("expected no key retired while events use it, got %d, %v", n, err)
+		}
+		if _, err := es.Reencrypt(ctx); err != nil {
+			t.Fatalf("Reencrypt failed: %v", err)
+		}
+		inUse, _ = es.DataKeysInUse(ctx)
+		if inUse[old] {
+			t.Fatalf("expected re-encryption to release %s", old)
+		}
+		if n, err := enc.RetireDataKeys(ctx, inUse); err != nil || n < 1 {
+			t.Fatalf("expected the old key to be retired, got %d, %v", n, err)
+		}
+		if _, err := enc.Open(c, nil); !errors.Is(err, store.ErrUnknownDataKey) {
+			t.Errorf("expected ErrUnknownDataKey for a retired key, got %v", err)
+		}
+		stored, _ := keys.ListDataKeys(ctx)
+		for _, k := range stored {
+			if k.ID == old {
+				t.Errorf("retired key %s is still stored", old)
+			}
+		}
+		if stream, err := es.GetEvents("agg-a"); err != nil || stream[0].(TestEvent).Data != "secret-1" {
+			t.Errorf("expected events to stay readable, got %v", err)
+		}
+	})
+}
+
+func TestMasterKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "master.key")
+	keyID, err := store.GenerateMasterKeyFile(path)
+	if err != nil {
+		t.Fatalf("GenerateMasterKeyFile failed: %v", err)
+	}
+	mk, err := store.LoadMasterKey(path)
+	if err != nil {
+		t.Fatalf("LoadMasterKey failed: %v", err)
+	}
+	if mk.ID() != keyID {
+		t.Errorf("expected key ID %s, got %s", keyID, mk.ID())
+	}
+}
+
+func TestInMemoryEventStore_PayloadEncryption(t *testing.T) {
+	ctx := context.Background()
+	enc := store.NewEncryptor(newTestMasterKey(t))
+	es := store.NewInMemoryEventStore(store.WithPayloadEncryption(enc))
+	_ = es.SaveEvents("agg-a", 0, []events.Event{newTestEvent("agg-a", 1, "secret-1"), newTestEvent("agg-a", 2, "secret-2")})
+
+	t.Run("CiphertextAtRest", func(t *testing.T) {
+		raw := es.GetStreamCopy("agg-a")
+		if _, plain := raw[0].(TestEvent); plain {
+			t.Fatalf("expected event to be sealed at rest")
+		}
+		if raw[0].GetBase().Hash == "" || raw[0].GetBase().Version != 1 {
+			t.Errorf("expected base event to stay readable at rest: %+v", raw[0].GetBase())
+		}
+	})
+
+	t.Run("ReadsArePlaintext", func(t *testing.T) {
+		stream, err := es.GetEvents("agg-a")
+		if err != nil {
+			t.Fatalf("GetEvents failed: %v", err)
+		}
+		if stream[1].(TestEvent).Data != "secret-2" {
+			t.Errorf("expected decrypted payload, got %+v", stream[1])
+		}
+		all, _ := es.ReadAll(ctx, 0, 0)
+		if all[0].(TestEvent).Data != "secret-1" {
+			t.Errorf("expected decrypted payload from global log, got %+v", all[0])
+		}
+	})
+
+	t.Run("ReencryptKeepsChainIntact", func(t *testing.T) {
+		before := es.GetStreamCopy("agg-a")
+		_, _ = enc.RotateDataKeys()
+		n, err := es.Reencrypt(ctx)
+		if err != nil || n != 2 {
+			t.Fatalf("expected 2 events re-encrypted, got %d (err: %v)", n, err)
+		}
+		after := es.GetStreamCopy("agg-a")
+		if after[0].GetBase().Hash != before[0].GetBase().Hash {
+			t.Errorf("re-encryption must not change the event hash")
+		}
+		if _, brk, err := store.VerifyChain(ctx, es); err != nil || brk != nil {
+			t.Errorf("expected intact chain after re-encryption, got %v, %v", brk, err)
+		}
+		if n, _ := es.Reencrypt(ctx); n != 0 {
+			t.Errorf("expected nothing left to re-encrypt, got %d", n)
+		}
+	})
+}
+
+func TestInMemorySnapshotStore_Encryption(t *testing.T) {
+	ctx := context.Background()
+	enc := store.NewEncryptor(newTestMasterKey(t))
+	ss := store.NewInMemorySnapshotStore(store.WithSnapshotEncryption(enc))
+	state := []byte(`{"id":"acc-1","balances":{"USD":"100"},"version":5}`)
+	_ = ss.SaveSnapshot(&domain.Snapshot{AggregateID: "acc-1", Version: 5, State: state})
+
+	snap, found, err := ss.GetLatestSnapshot("acc-1")
+	if err != nil || !found {
+		t.Fatalf("GetLatestSnapshot failed: found=%v err=%v", found, err)
+	}
+	if string(snap.State) != string(state) {
+		t.Errorf("expected decrypted state %s, got %s", state, snap.State)
+	}
+
+	inUse, err := ss.DataKeysInUse(ctx)
+	if err != nil || len(inUse) != 1 {
+		t.Errorf("expected one data key in use, got %v, %v", inUse, err)
+	}
+
+	_, _ = enc.RotateDataKeys()
+	if n, err := ss.Reencrypt(ctx); err != nil || n != 1 {
+		t.Errorf("expected 1 snapshot re-encrypted, got %d (err: %v)", n, err)
+	}
+	snap, _, _ = ss.GetLatestSnapshot("acc-1")
+	if string(snap.State) != string(state) {
+		t.Errorf("expected state to survive re-encryption, got %s", snap.State)
+	}
+}
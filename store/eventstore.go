@@ -1,9 +1,11 @@
 package store
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
+	"sort"
 	"sync"
 
 	"financial-ledger/events"
@@ -14,26 +16,72 @@ var (
 	ErrNotFound       = errors.New("aggregate not found")
 )
 
+// EventStore persists aggregate event streams. The *Context methods honour
+// cancellation and deadlines; the plain methods are kept for compatibility and
+// behave like their *Context counterpart called with context.Background().
 type EventStore interface {
 	SaveEvents(aggregateID string, expectedVersion int, eventsToSave []events.Event) error
 
 	GetEvents(aggregateID string) ([]events.Event, error)
 
 	GetEventsAfterVersion(aggregateID string, version int) ([]events.Event, error)
+
+	SaveEventsContext(ctx context.Context, aggregateID string, expectedVersion int, eventsToSave []events.Event) error
+
+	GetEventsContext(ctx context.Context, aggregateID string) ([]events.Event, error)
+
+	GetEventsAfterVersionContext(ctx context.Context, aggregateID string, version int) ([]events.Event, error)
 }
 
 type InMemoryEventStore struct {
 	sync.RWMutex
-	streams map[string][]events.Event
+	streams   map[string][]events.Event
+	globalLog []events.Event
+	signer    Signer
+	encryptor *Encryptor
+
+	outboxEnabled bool
+	outbox        []int64 // positions of committed events not yet published
+}
+
+// InMemoryEventStoreOption customises an InMemoryEventStore.
+type InMemoryEventStoreOption func(*InMemoryEventStore)
+
+// WithEventSigner signs every event as it is committed. A signing failure
+// aborts the commit, so no unsigned event is stored while a signer is set.
+func WithEventSigner(signer Signer) InMemoryEventStoreOption {
+	return func(s *InMemoryEventStore) {
+		s.signer = signer
+	}
+}
+
+// WithPayloadEncryption keeps every committed event encrypted at rest. Only the
+// BaseEvent (IDs, versions, chain hashes, metadata) stays readable; reads
+// decrypt transparently, so callers always see plaintext events.
+func WithPayloadEncryption(enc *Encryptor) InMemoryEventStoreOption {
+	return func(s *InMemoryEventStore) {
+		s.encryptor = enc
+	}
 }
 
-func NewInMemoryEventStore() *InMemoryEventStore {
-	return &InMemoryEventStore{
+func NewInMemoryEventStore(opts ...InMemoryEventStoreOption) *InMemoryEventStore {
+	s := &InMemoryEventStore{
 		streams: make(map[string][]events.Event),
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *InMemoryEventStore) SaveEvents(aggregateID string, expectedVersion int, newEvents []events.Event) error {
+	return s.SaveEventsContext(context.Background(), aggregateID, expectedVersion, newEvents)
+}
+
+func (s *InMemoryEventStore) SaveEventsContext(ctx context.Context, aggregateID string, expectedVersion int, newEvents []events.Event) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("save events for aggregate %s: %w", aggregateID, err)
+	}
 	s.Lock()
 	defer s.Unlock()
 
@@ -67,15 +115,105 @@ func (s *InMemoryEventStore) SaveEvents(aggregateID string, expectedVersion int,
 		}
 	}
 
+	prevHash := ""
+	if streamExists && len(stream) > 0 {
+		prevHash = stream[len(stream)-1].GetBase().Hash
+	}
+	globalPrevHash := ""
+	if len(s.globalLog) > 0 {
+		globalPrevHash = s.globalLog[len(s.globalLog)-1].GetBase().GlobalHash
+	}
+
+	chained := make([]events.Event, 0, len(newEvents))
+	for i, event := range newEvents {
+		position := int64(len(s.globalLog) + i + 1)
+		linked, err := chainEvent(event, prevHash, position, globalPrevHash)
+		if err != nil {
+			return fmt.Errorf("failed to hash-chain events for aggregate %s: %w", aggregateID, err)
+		}
+		prevHash = linked.GetBase().Hash
+		globalPrevHash = linked.GetBase().GlobalHash
+		if s.signer != nil {
+			if linked, err = signEvent(linked, s.signer); err != nil {
+				return fmt.Errorf("failed to sign events for aggregate %s: %w", aggregateID, err)
+			}
+		}
+		if s.encryptor != nil {
+			if linked, err = sealEvent(s.encryptor, linked); err != nil {
+				return fmt.Errorf("failed to encrypt events for aggregate %s: %w", aggregateID, err)
+			}
+		}
+		chained = append(chained, linked)
+	}
+
 	if !streamExists {
-		s.streams[aggregateID] = make([]events.Event, 0, len(newEvents))
+		s.streams[aggregateID] = make([]events.Event, 0, len(chained))
+	}
+	s.streams[aggregateID] = append(s.streams[aggregateID], chained...)
+	if s.outboxEnabled {
+		for _, event := range chained {
+			s.outbox = append(s.outbox, event.GetBase().Position)
+		}
 	}
-	s.streams[aggregateID] = append(s.streams[aggregateID], newEvents...)
+	s.globalLog = append(s.globalLog, chained...)
 
 	return nil
 }
 
+func (s *InMemoryEventStore) ReadAll(ctx context.Context, afterPosition int64, limit int) ([]events.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("read global log after position %d: %w", afterPosition, err)
+	}
+	s.RLock()
+	defer s.RUnlock()
+
+	if afterPosition < 0 {
+		afterPosition = 0
+	}
+	if afterPosition >= int64(len(s.globalLog)) {
+		return []events.Event{}, nil
+	}
+	remaining := s.globalLog[afterPosition:]
+	if limit > 0 && limit < len(remaining) {
+		remaining = remaining[:limit]
+	}
+	return s.openEvents(remaining)
+}
+
+func (s *InMemoryEventStore) Head(ctx context.Context) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("read global log head: %w", err)
+	}
+	s.RLock()
+	defer s.RUnlock()
+	return int64(len(s.globalLog)), nil
+}
+
+func (s *InMemoryEventStore) StreamIDs(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("list streams: %w", err)
+	}
+	s.RLock()
+	defer s.RUnlock()
+
+	ids := make([]string, 0, len(s.streams))
+	for id, stream := range s.streams {
+		if len(stream) > 0 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
 func (s *InMemoryEventStore) GetEvents(aggregateID string) ([]events.Event, error) {
+	return s.GetEventsContext(context.Background(), aggregateID)
+}
+
+func (s *InMemoryEventStore) GetEventsContext(ctx context.Context, aggregateID string) ([]events.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("get events for aggregate %s: %w", aggregateID, err)
+	}
 	s.RLock()
 	defer s.RUnlock()
 
@@ -84,12 +222,17 @@ func (s *InMemoryEventStore) GetEvents(aggregateID string) ([]events.Event, erro
 		return []events.Event{}, nil
 	}
 
-	copiedStream := make([]events.Event, len(streamData))
-	copy(copiedStream, streamData)
-	return copiedStream, nil
+	return s.openEvents(streamData)
 }
 
 func (s *InMemoryEventStore) GetEventsAfterVersion(aggregateID string, version int) ([]events.Event, error) {
+	return s.GetEventsAfterVersionContext(context.Background(), aggregateID, version)
+}
+
+func (s *InMemoryEventStore) GetEventsAfterVersionContext(ctx context.Context, aggregateID string, version int) ([]events.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("get events after version %d for aggregate %s: %w", version, aggregateID, err)
+	}
 	s.RLock()
 	defer s.RUnlock()
 
@@ -110,15 +253,84 @@ func (s *InMemoryEventStore) GetEventsAfterVersion(aggregateID string, version i
 		return []events.Event{}, nil
 	}
 
-	result := make([]events.Event, len(streamData)-startIndex)
-	copy(result, streamData[startIndex:])
+	return s.openEvents(streamData[startIndex:])
+}
+
+// openEvents returns a copy of stored, decrypting sealed events. The caller
+// must hold at least a read lock.
+func (s *InMemoryEventStore) openEvents(stored []events.Event) ([]events.Event, error) {
+	result := make([]events.Event, len(stored))
+	for i, event := range stored {
+		opened, err := openEvent(s.encryptor, event)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = opened
+	}
 	return result, nil
 }
 
+// Reencrypt re-seals every stored event whose payload is not under the current
+// data key of its scope, and seals any event stored in plaintext. Run it after
+// Encryptor.RotateDataKeys. Hashes and signatures cover the plaintext, so they
+// are unaffected. It returns the number of events rewritten.
+func (s *InMemoryEventStore) Reencrypt(ctx context.Context) (int, error) {
+	if s.encryptor == nil {
+		return 0, errors.New("event store is not configured for encryption")
+	}
+	s.Lock()
+	defer s.Unlock()
+
+	rewritten := 0
+	for i, stored := range s.globalLog {
+		if err := ctx.Err(); err != nil {
+			return rewritten, fmt.Errorf("re-encrypt events: %w", err)
+		}
+		base := stored.GetBase()
+		if sealed, ok := stored.(sealedEvent); ok && s.encryptor.IsCurrent(base.AggregateID, sealed.Payload) {
+			continue
+		}
+		plain, err := openEvent(s.encryptor, stored)
+		if err != nil {
+			return rewritten, err
+		}
+		resealed, err := sealEvent(s.encryptor, plain)
+		if err != nil {
+			return rewritten, fmt.Errorf("failed to re-encrypt event %s: %w", base.EventID, err)
+		}
+		s.globalLog[i] = resealed
+		if stream := s.streams[base.AggregateID]; base.Version >= 1 && base.Version <= len(stream) &&
+			stream[base.Version-1].GetBase().EventID == base.EventID {
+			stream[base.Version-1] = resealed
+		}
+		rewritten++
+	}
+	return rewritten, nil
+}
+
+// DataKeysInUse returns the IDs of the data keys that sealed the stored events,
+// for Encryptor.RetireDataKeys.
+func (s *InMemoryEventStore) DataKeysInUse(ctx context.Context) (map[string]bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("list data keys in use: %w", err)
+	}
+	s.RLock()
+	defer s.RUnlock()
+
+	inUse := make(map[string]bool)
+	for _, stored := range s.globalLog {
+		if sealed, ok := stored.(sealedEvent); ok {
+			inUse[sealed.Payload.KeyID] = true
+		}
+	}
+	return inUse, nil
+}
+
 // --- Test Helpers ---
 // These methods are primarily for testing purposes, allowing manipulation of the store's state.
 
-// GetStreamCopy returns a copy of the raw event stream for a given aggregate ID.
+// GetStreamCopy returns a copy of the raw event stream for a given aggregate ID,
+// as held at rest (still sealed if encryption is on).
 // Useful for inspecting the store's state in tests.
 func (s *InMemoryEventStore) GetStreamCopy(aggregateID string) []events.Event {
 	s.RLock()
@@ -134,6 +346,7 @@ func (s *InMemoryEventStore) GetStreamCopy(aggregateID string) []events.Event {
 
 // SetStream forcefully replaces the event stream for a given aggregate ID.
 // WARNING: Use ONLY in tests to simulate specific scenarios (like event pruning for snapshot testing).
+// It bypasses hash chaining and leaves the global log untouched, so VerifyChain reports the rewrite.
 func (s *InMemoryEventStore) SetStream(aggregateID string, stream []events.Event) {
 	s.Lock()
 	defer s.Unlock()
@@ -1,6 +1,7 @@
 package store_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -224,3 +225,21 @@ func TestInMemoryEventStore_GetEventsAfterVersion(t *testing.T) {
 		}
 	})
 }
+
+func TestInMemoryEventStore_ContextCancelled(t *testing.T) {
+	es := store.NewInMemoryEventStore()
+	aggID := "agg-ctx-1"
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := es.SaveEventsContext(ctx, aggID, 0, []events.Event{newTestEvent(aggID, 1, "one")})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from SaveEventsContext, got %v", err)
+	}
+	if stream, _ := es.GetEvents(aggID); len(stream) != 0 {
+		t.Errorf("Expected no events saved with cancelled context, got %d", len(stream))
+	}
+	if _, err := es.GetEventsAfterVersionContext(ctx, aggID, 0); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from GetEventsAfterVersionContext, got %v", err)
+	}
+}
@@ -0,0 +1,229 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"financial-ledger/events"
+)
+
+// GlobalLog is implemented by event stores that keep, next to the per-aggregate
+// streams, a single log of every committed event ordered by Position (1-based).
+type GlobalLog interface {
+	// ReadAll returns up to limit events with a Position greater than afterPosition.
+	// A limit of 0 or less returns every remaining event.
+	ReadAll(ctx context.Context, afterPosition int64, limit int) ([]events.Event, error)
+
+	// StreamIDs returns the IDs of all aggregates with at least one event, sorted.
+	StreamIDs(ctx context.Context) ([]string, error)
+
+	// Head returns the Position of the last committed event, or 0 if there is none.
+	Head(ctx context.Context) (int64, error)
+}
+
+// AuditableEventStore is an EventStore whose global log can be walked for audit.
+type AuditableEventStore interface {
+	EventStore
+	GlobalLog
+}
+
+// ComputeEventHash returns the stream-chain hash of event, linking it to prevHash,
+// the hash of the previous event in the same stream ("" for the first event).
+// Chain fields already set on event are ignored, so stored events can be rehashed.
+func ComputeEventHash(event events.Event, prevHash string) (string, error) {
+	content := events.WithBase(event, func(base *events.BaseEvent) {
+		base.Position = 0
+		base.PrevHash = ""
+		base.Hash = ""
+		base.GlobalPrevHash = ""
+		base.GlobalHash = ""
+		base.SignatureKeyID = ""
+		base.Signature = ""
+	})
+	payload, err := json.Marshal(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialise event %s for hashing: %w", event.GetBase().EventID, err)
+	}
+
+	h := sha256.New()
+	h.Write([]byte(prevHash))
+	h.Write([]byte{'\n'})
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ComputeGlobalHash links an event's stream hash into the global log at position.
+func ComputeGlobalHash(position int64, eventHash, globalPrevHash string) string {
+	h := sha256.New()
+	h.Write([]byte(globalPrevHash))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(strconv.FormatInt(position, 10)))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(eventHash))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// chainEvent stamps an event with its global position and both chain hashes.
+func chainEvent(event events.Event, prevHash string, position int64, globalPrevHash string) (events.Event, error) {
+	hash, err := ComputeEventHash(event, prevHash)
+	if err != nil {
+		return nil, err
+	}
+	return events.WithBase(event, func(base *events.BaseEvent) {
+		base.Position = position
+		base.PrevHash = prevHash
+		base.Hash = hash
+		base.GlobalPrevHash = globalPrevHash
+		base.GlobalHash = ComputeGlobalHash(position, hash, globalPrevHash)
+	}), nil
+}
+
+// ChainBreak describes the first link that failed verification.
+type ChainBreak struct {
+	Scope       string `json:"scope"` // "stream" or "global"
+	AggregateID string `json:"aggregateId,omitempty"`
+	Version     int    `json:"version,omitempty"`
+	Position    int64  `json:"position,omitempty"`
+	Reason      string `json:"reason"`
+}
+
+func (b ChainBreak) String() string {
+	if b.Scope == "global" {
+		return fmt.Sprintf("global log broken at position %d (aggregate %s, version %d): %s", b.Position, b.AggregateID, b.Version, b.Reason)
+	}
+	return fmt.Sprintf("stream %s broken at version %d: %s", b.AggregateID, b.Version, b.Reason)
+}
+
+// VerifyReport summarises a successful walk of the store.
+type VerifyReport struct {
+	Streams int   `json:"streams"`
+	Events  int   `json:"events"`
+	Head    int64 `json:"head"`
+	Signed  int   `json:"signed"`
+}
+
+type verifyConfig struct {
+	keyring *Keyring
+}
+
+// VerifyOption customises VerifyChain.
+type VerifyOption func(*verifyConfig)
+
+// WithSignatureKeys makes VerifyChain require a valid signature on every event,
+// made by one of the keys in keyring. Without it signatures are not checked.
+func WithSignatureKeys(keyring *Keyring) VerifyOption {
+	return func(c *verifyConfig) {
+		c.keyring = keyring
+	}
+}
+
+// VerifyChain walks every stream and then the global log, recomputing each hash.
+// It returns the first broken link found, or nil if history is intact.
+func VerifyChain(ctx context.Context, es AuditableEventStore, opts ...VerifyOption) (*VerifyReport, *ChainBreak, error) {
+	var cfg verifyConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	streamIDs, err := es.StreamIDs(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list streams: %w", err)
+	}
+
+	report := &VerifyReport{Streams: len(streamIDs)}
+	streamHashes := make(map[string][]string, len(streamIDs))
+
+	for _, id := range streamIDs {
+		stream, err := es.GetEventsContext(ctx, id)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read stream %s: %w", id, err)
+		}
+		prevHash := ""
+		hashes := make([]string, 0, len(stream))
+		for i, event := range stream {
+			base := event.GetBase()
+			brk := &ChainBreak{Scope: "stream", AggregateID: id, Version: base.Version, Position: base.Position}
+			if base.Version != i+1 {
+				brk.Reason = fmt.Sprintf("expected version %d", i+1)
+				return nil, brk, nil
+			}
+			if base.PrevHash != prevHash {
+				brk.Reason = "previous-hash link does not match the preceding event"
+				return nil, brk, nil
+			}
+			hash, err := ComputeEventHash(event, base.PrevHash)
+			if err != nil {
+				return nil, nil, err
+			}
+			if hash != base.Hash {
+				brk.Reason = "event content does not match its stored hash"
+				return nil, brk, nil
+			}
+			if cfg.keyring != nil {
+				if base.Signature == "" {
+					brk.Reason = "event is not signed"
+					return nil, brk, nil
+				}
+				if err := cfg.keyring.Verify(base.SignatureKeyID, EventSigningPayload(base), base.Signature); err != nil {
+					brk.Reason = err.Error()
+					return nil, brk, nil
+				}
+				report.Signed++
+			}
+			prevHash = base.Hash
+			hashes = append(hashes, base.Hash)
+		}
+		streamHashes[id] = hashes
+		report.Events += len(stream)
+	}
+
+	globalLog, err := es.ReadAll(ctx, 0, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read global log: %w", err)
+	}
+	globalPrev := ""
+	for i, event := range globalLog {
+		base := event.GetBase()
+		position := int64(i + 1)
+		brk := &ChainBreak{Scope: "global", AggregateID: base.AggregateID, Version: base.Version, Position: position}
+		if base.Position != position {
+			brk.Reason = fmt.Sprintf("event records position %d", base.Position)
+			return nil, brk, nil
+		}
+		if base.GlobalPrevHash != globalPrev {
+			brk.Reason = "previous-hash link does not match the preceding entry"
+			return nil, brk, nil
+		}
+		hash, err := ComputeEventHash(event, base.PrevHash)
+		if err != nil {
+			return nil, nil, err
+		}
+		if hash != base.Hash || ComputeGlobalHash(position, base.Hash, base.GlobalPrevHash) != base.GlobalHash {
+			brk.Reason = "entry does not match its stored hash"
+			return nil, brk, nil
+		}
+		hashes := streamHashes[base.AggregateID]
+		if base.Version < 1 || base.Version > len(hashes) || hashes[base.Version-1] != base.Hash {
+			brk.Reason = "entry does not match the event held in its aggregate stream"
+			return nil, brk, nil
+		}
+		globalPrev = base.GlobalHash
+		report.Head = position
+	}
+
+	if len(globalLog) != report.Events {
+		return nil, &ChainBreak{
+			Scope:    "global",
+			Position: int64(len(globalLog)),
+			Reason:   fmt.Sprintf("global log holds %d events but streams hold %d", len(globalLog), report.Events),
+		}, nil
+	}
+
+	return report, nil, nil
+}
+
+var _ AuditableEventStore = (*InMemoryEventStore)(nil)
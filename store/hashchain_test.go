@@ -0,0 +1,81 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+
+	"financial-ledger/events"
+	"financial-ledger/store"
+)
+
+func TestVerifyChain(t *testing.T) {
+	ctx := context.Background()
+	newStore := func() *store.InMemoryEventStore {
+		es := store.NewInMemoryEventStore()
+		_ = es.SaveEvents("agg-a", 0, []events.Event{newTestEvent("agg-a", 1, "a1"), newTestEvent("agg-a", 2, "a2")})
+		_ = es.SaveEvents("agg-b", 0, []events.Event{newTestEvent("agg-b", 1, "b1")})
+		_ = es.SaveEvents("agg-a", 2, []events.Event{newTestEvent("agg-a", 3, "a3")})
+		return es
+	}
+
+	t.Run("EventsAreChained", func(t *testing.T) {
+		es := newStore()
+		stream, _ := es.GetEvents("agg-a")
+		if stream[0].GetBase().PrevHash != "" || stream[0].GetBase().Hash == "" {
+			t.Fatalf("first event should have a hash and no predecessor: %+v", stream[0].GetBase())
+		}
+		if stream[2].GetBase().PrevHash != stream[1].GetBase().Hash {
+			t.Errorf("stream link broken between versions 2 and 3")
+		}
+		if stream[2].GetBase().Position != 4 {
+			t.Errorf("expected global position 4, got %d", stream[2].GetBase().Position)
+		}
+	})
+
+	t.Run("IntactHistory", func(t *testing.T) {
+		report, brk, err := store.VerifyChain(ctx, newStore())
+		if err != nil {
+			t.Fatalf("VerifyChain failed: %v", err)
+		}
+		if brk != nil {
+			t.Fatalf("expected intact chain, got break: %s", brk)
+		}
+		if report.Streams != 2 || report.Events != 4 || report.Head != 4 {
+			t.Errorf("unexpected report: %+v", report)
+		}
+	})
+
+	t.Run("DetectsRewrittenStream", func(t *testing.T) {
+		es := newStore()
+		stream, _ := es.GetEvents("agg-a")
+		tampered := stream[1].(TestEvent)
+		tampered.Data = "edited"
+		stream[1] = tampered
+		es.SetStream("agg-a", stream)
+
+		_, brk, err := store.VerifyChain(ctx, es)
+		if err != nil {
+			t.Fatalf("VerifyChain failed: %v", err)
+		}
+		if brk == nil {
+			t.Fatalf("expected tampering to be detected")
+		}
+		if brk.Scope != "stream" || brk.AggregateID != "agg-a" || brk.Version != 2 {
+			t.Errorf("expected break at agg-a version 2, got %s", brk)
+		}
+	})
+
+	t.Run("DetectsDroppedEvent", func(t *testing.T) {
+		es := newStore()
+		stream, _ := es.GetEvents("agg-a")
+		es.SetStream("agg-a", stream[:2])
+
+		_, brk, err := store.VerifyChain(ctx, es)
+		if err != nil {
+			t.Fatalf("VerifyChain failed: %v", err)
+		}
+		if brk == nil || brk.Scope != "global" || brk.Position != 4 {
+			t.Errorf("expected global break at position 4, got %v", brk)
+		}
+	})
+}
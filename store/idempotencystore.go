@@ -0,0 +1,65 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// IdempotencyRecord remembers the outcome of a command submitted with an
+// idempotency key, so a retry of the same request can return the original result.
+type IdempotencyRecord struct {
+	Key         string    `json:"key"`
+	Fingerprint string    `json:"fingerprint"`
+	AggregateID string    `json:"aggregateId"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+type IdempotencyStore interface {
+	SaveRecord(ctx context.Context, record IdempotencyRecord) error
+
+	GetRecord(ctx context.Context, key string) (record IdempotencyRecord, found bool, err error)
+}
+
+type InMemoryIdempotencyStore struct {
+	sync.RWMutex
+	records map[string]IdempotencyRecord
+}
+
+func NewInMemoryIdempotencyStore() *InMemoryIdempotencyStore {
+	return &InMemoryIdempotencyStore{
+		records: make(map[string]IdempotencyRecord),
+	}
+}
+
+func (s *InMemoryIdempotencyStore) SaveRecord(ctx context.Context, record IdempotencyRecord) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("save idempotency record: %w", err)
+	}
+	if record.Key == "" {
+		return fmt.Errorf("cannot save idempotency record with empty key")
+	}
+	s.Lock()
+	defer s.Unlock()
+
+	if existing, found := s.records[record.Key]; found && existing.Fingerprint != record.Fingerprint {
+		return fmt.Errorf("idempotency key %s already recorded with a different fingerprint", record.Key)
+	}
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now().UTC()
+	}
+	s.records[record.Key] = record
+	return nil
+}
+
+func (s *InMemoryIdempotencyStore) GetRecord(ctx context.Context, key string) (IdempotencyRecord, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return IdempotencyRecord{}, false, fmt.Errorf("get idempotency record: %w", err)
+	}
+	s.RLock()
+	defer s.RUnlock()
+
+	record, found := s.records[key]
+	return record, found, nil
+}
@@ -0,0 +1,52 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+
+	"financial-ledger/store"
+)
+
+func TestInMemoryIdempotencyStore_SaveAndGetRecord(t *testing.T) {
+	is := store.NewInMemoryIdempotencyStore()
+
+	t.Run("GetNotFound", func(t *testing.T) {
+		_, found, err := is.GetRecord(context.Background(), "missing")
+		if err != nil {
+			t.Fatalf("GetRecord failed: %v", err)
+		}
+		if found {
+			t.Errorf("Expected record not found")
+		}
+	})
+
+	t.Run("SaveAndGet", func(t *testing.T) {
+		err := is.SaveRecord(context.Background(), store.IdempotencyRecord{Key: "k1", Fingerprint: "f1", AggregateID: "acc-1"})
+		if err != nil {
+			t.Fatalf("SaveRecord failed: %v", err)
+		}
+		record, found, err := is.GetRecord(context.Background(), "k1")
+		if err != nil || !found {
+			t.Fatalf("Expected record to be found, err: %v", err)
+		}
+		if record.Fingerprint != "f1" || record.AggregateID != "acc-1" {
+			t.Errorf("Record mismatch: %+v", record)
+		}
+		if record.Timestamp.IsZero() {
+			t.Errorf("Expected timestamp to be set on save")
+		}
+	})
+
+	t.Run("FailOnDifferentFingerprint", func(t *testing.T) {
+		err := is.SaveRecord(context.Background(), store.IdempotencyRecord{Key: "k1", Fingerprint: "f2", AggregateID: "acc-1"})
+		if err == nil {
+			t.Errorf("Expected error when overwriting key with a different fingerprint")
+		}
+	})
+
+	t.Run("FailOnEmptyKey", func(t *testing.T) {
+		if err := is.SaveRecord(context.Background(), store.IdempotencyRecord{}); err == nil {
+			t.Errorf("Expected error for empty key")
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"financial-ledger/events"
+)
+
+var ErrOutboxDisabled = errors.New("outbox is not enabled on this event store")
+
+// Outbox is implemented by event stores that record, in the same commit as the
+// events themselves, which events still have to be published. An event stays
+// in the outbox from the moment it is committed until it is marked published,
+// so a crash between committing and publishing loses nothing.
+type Outbox interface {
+	// PendingOutbox returns up to limit unpublished events in Position order.
+	// A limit of 0 or less returns all of them.
+	PendingOutbox(ctx context.Context, limit int) ([]events.Event, error)
+
+	// MarkPublished removes events from the outbox. IDs that are not pending
+	// are ignored, so marking is safe to repeat.
+	MarkPublished(ctx context.Context, eventIDs ...uuid.UUID) error
+
+	// OutboxSize returns the number of unpublished events.
+	OutboxSize(ctx context.Context) (int, error)
+}
+
+// WithOutbox records every committed event in an outbox under the same lock
+// that commits it. Without a relay draining it the outbox grows without bound,
+// so it is off by default.
+func WithOutbox() InMemoryEventStoreOption {
+	return func(s *InMemoryEventStore) {
+		s.outboxEnabled = true
+	}
+}
+
+func (s *InMemoryEventStore) PendingOutbox(ctx context.Context, limit int) ([]events.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("read outbox: %w", err)
+	}
+	s.RLock()
+	defer s.RUnlock()
+	if !s.outboxEnabled {
+		return nil, ErrOutboxDisabled
+	}
+
+	positions := s.outbox
+	if limit > 0 && limit < len(positions) {
+		positions = positions[:limit]
+	}
+	pending := make([]events.Event, len(positions))
+	for i, position := range positions {
+		pending[i] = s.globalLog[position-1]
+	}
+	return s.openEvents(pending)
+}
+
+func (s *InMemoryEventStore) MarkPublished(ctx context.Context, eventIDs ...uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("mark events published: %w", err)
+	}
+	s.Lock()
+	defer s.Unlock()
+	if !s.outboxEnabled {
+		return ErrOutboxDisabled
+	}
+
+	published := make(map[uuid.UUID]bool, len(eventIDs))
+	for _, id := range eventIDs {
+		published[id] = true
+	}
+	remaining := s.outbox[:0]
+	for _, position := range s.outbox {
+		if !published[s.globalLog[position-1].GetBase().EventID] {
+			remaining = append(remaining, position)
+		}
+	}
+	s.outbox = remaining
+	return nil
+}
+
+func (s *InMemoryEventStore) OutboxSize(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("read outbox size: %w", err)
+	}
+	s.RLock()
+	defer s.RUnlock()
+	if !s.outboxEnabled {
+		return 0, ErrOutboxDisabled
+	}
+	return len(s.outbox), nil
+}
@@ -0,0 +1,71 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"financial-ledger/events"
+	"financial-ledger/store"
+)
+
+func TestInMemoryEventStore_Outbox(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Disabled", func(t *testing.T) {
+		es := store.NewInMemoryEventStore()
+		if _, err := es.PendingOutbox(ctx, 0); !errors.Is(err, store.ErrOutboxDisabled) {
+			t.Errorf("expected ErrOutboxDisabled, got %v", err)
+		}
+	})
+
+	t.Run("CommittedWithEvents", func(t *testing.T) {
+		es := store.NewInMemoryEventStore(store.WithOutbox())
+		first := []events.Event{newTestEvent("acc-1", 1, "10"), newTestEvent("acc-1", 2, "20")}
+		if err := es.SaveEvents("acc-1", 0, first); err != nil {
+			t.Fatalf("SaveEvents failed: %v", err)
+		}
+		// A rejected commit must leave nothing in the outbox.
+		if err := es.SaveEvents("acc-1", 0, []events.Event{newTestEvent("acc-1", 1, "30")}); !errors.Is(err, store.ErrOptimisticLock) {
+			t.Fatalf("expected ErrOptimisticLock, got %v", err)
+		}
+		_ = es.SaveEvents("acc-2", 0, []events.Event{newTestEvent("acc-2", 1, "5")})
+
+		pending, err := es.PendingOutbox(ctx, 0)
+		if err != nil || len(pending) != 3 {
+			t.Fatalf("expected 3 pending events, got %d (err: %v)", len(pending), err)
+		}
+		for i, e := range pending {
+			if e.GetBase().Position != int64(i+1) {
+				t.Errorf("expected pending events in position order, got %d at %d", e.GetBase().Position, i)
+			}
+		}
+		if limited, _ := es.PendingOutbox(ctx, 2); len(limited) != 2 {
+			t.Errorf("expected limit to apply, got %d", len(limited))
+		}
+
+		if err := es.MarkPublished(ctx, pending[1].GetBase().EventID); err != nil {
+			t.Fatalf("MarkPublished failed: %v", err)
+		}
+		_ = es.MarkPublished(ctx, pending[1].GetBase().EventID)
+		pending, _ = es.PendingOutbox(ctx, 0)
+		if len(pending) != 2 || pending[0].GetBase().Position != 1 || pending[1].GetBase().Position != 3 {
+			t.Errorf("expected positions 1 and 3 left, got %v", pending)
+		}
+		if n, _ := es.OutboxSize(ctx); n != 2 {
+			t.Errorf("expected outbox size 2, got %d", n)
+		}
+	})
+
+	t.Run("DecryptsPending", func(t *testing.T) {
+		es := store.NewInMemoryEventStore(store.WithOutbox(), store.WithPayloadEncryption(store.NewEncryptor(newTestMasterKey(t))))
+		_ = es.SaveEvents("acc-1", 0, []events.Event{newTestEvent("acc-1", 1, "10")})
+		pending, err := es.PendingOutbox(ctx, 0)
+		if err != nil || len(pending) != 1 {
+			t.Fatalf("expected 1 pending event, got %d (err: %v)", len(pending), err)
+		}
+		if e, ok := pending[0].(TestEvent); !ok || e.Data != "10" {
+			t.Errorf("expected the decrypted TestEvent, got %#v", pending[0])
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ProjectionCheckpoint records how far a projection has consumed the global
+// log: every event up to and including Position has been applied.
+type ProjectionCheckpoint struct {
+	Name      string    `json:"name"`
+	Position  int64     `json:"position"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type ProjectionCheckpointStore interface {
+	SaveProjectionCheckpoint(ctx context.Context, checkpoint ProjectionCheckpoint) error
+
+	// GetProjectionCheckpoint returns found == false for a projection that has
+	// never saved a checkpoint, which means it starts from position zero.
+	GetProjectionCheckpoint(ctx context.Context, name string) (checkpoint ProjectionCheckpoint, found bool, err error)
+
+	ListProjectionCheckpoints(ctx context.Context) ([]ProjectionCheckpoint, error)
+}
+
+type InMemoryProjectionCheckpointStore struct {
+	sync.RWMutex
+	checkpoints map[string]ProjectionCheckpoint
+}
+
+func NewInMemoryProjectionCheckpointStore() *InMemoryProjectionCheckpointStore {
+	return &InMemoryProjectionCheckpointStore{checkpoints: make(map[string]ProjectionCheckpoint)}
+}
+
+func (s *InMemoryProjectionCheckpointStore) SaveProjectionCheckpoint(ctx context.Context, checkpoint ProjectionCheckpoint) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("save projection checkpoint %s: %w", checkpoint.Name, err)
+	}
+	if checkpoint.Name == "" {
+		return fmt.Errorf("projection checkpoint must have a name")
+	}
+	if checkpoint.Position < 0 {
+		return fmt.Errorf("projection checkpoint %s has negative position %d", checkpoint.Name, checkpoint.Position)
+	}
+	s.Lock()
+	defer s.Unlock()
+	s.checkpoints[checkpoint.Name] = checkpoint
+	return nil
+}
+
+func (s *InMemoryProjectionCheckpointStore) GetProjectionCheckpoint(ctx context.Context, name string) (ProjectionCheckpoint, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return ProjectionCheckpoint{}, false, fmt.Errorf("get projection checkpoint %s: %w", name, err)
+	}
+	s.RLock()
+	defer s.RUnlock()
+	checkpoint, ok := s.checkpoints[name]
+	return checkpoint, ok, nil
+}
+
+func (s *InMemoryProjectionCheckpointStore) ListProjectionCheckpoints(ctx context.Context) ([]ProjectionCheckpoint, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("list projection checkpoints: %w", err)
+	}
+	s.RLock()
+	defer s.RUnlock()
+	out := make([]ProjectionCheckpoint, 0, len(s.checkpoints))
+	for _, checkpoint := range s.checkpoints {
+		out = append(out, checkpoint)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
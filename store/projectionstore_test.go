@@ -0,0 +1,58 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+
+	"financial-ledger/store"
+)
+
+func TestInMemoryProjectionCheckpointStore(t *testing.T) {
+	ctx := context.Background()
+	cs := store.NewInMemoryProjectionCheckpointStore()
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, found, err := cs.GetProjectionCheckpoint(ctx, "balances")
+		if err != nil || found {
+			t.Errorf("Expected no checkpoint, found=%v err=%v", found, err)
+		}
+	})
+
+	t.Run("SaveAndGet", func(t *testing.T) {
+		for _, cp := range []store.ProjectionCheckpoint{{Name: "balances", Position: 7}, {Name: "directory", Position: 3}, {Name: "balances", Position: 9}} {
+			if err := cs.SaveProjectionCheckpoint(ctx, cp); err != nil {
+				t.Fatalf("SaveProjectionCheckpoint(%+v) failed: %v", cp, err)
+			}
+		}
+		cp, found, err := cs.GetProjectionCheckpoint(ctx, "balances")
+		if err != nil || !found || cp.Position != 9 {
+			t.Errorf("Expected balances at position 9, got %+v found=%v err=%v", cp, found, err)
+		}
+		all, err := cs.ListProjectionCheckpoints(ctx)
+		if err != nil {
+			t.Fatalf("ListProjectionCheckpoints failed: %v", err)
+		}
+		if len(all) != 2 || all[0].Name != "balances" || all[1].Name != "directory" {
+			t.Errorf("Unexpected checkpoints: %+v", all)
+		}
+	})
+
+	t.Run("RewindForRebuild", func(t *testing.T) {
+		if err := cs.SaveProjectionCheckpoint(ctx, store.ProjectionCheckpoint{Name: "balances"}); err != nil {
+			t.Fatalf("SaveProjectionCheckpoint failed: %v", err)
+		}
+		cp, _, _ := cs.GetProjectionCheckpoint(ctx, "balances")
+		if cp.Position != 0 {
+			t.Errorf("Expected position 0 after rewind, got %d", cp.Position)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		if err := cs.SaveProjectionCheckpoint(ctx, store.ProjectionCheckpoint{Position: 1}); err == nil {
+			t.Errorf("Expected error for unnamed checkpoint")
+		}
+		if err := cs.SaveProjectionCheckpoint(ctx, store.ProjectionCheckpoint{Name: "x", Position: -1}); err == nil {
+			t.Errorf("Expected error for negative position")
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"financial-ledger/events"
+)
+
+// ErrScreeningExists is returned when a decision with the same ID was already
+// recorded, as when a retried command is screened again.
+var ErrScreeningExists = errors.New("screening decision already recorded")
+
+// ScreeningOutcome is what was done with an operation after screening.
+type ScreeningOutcome string
+
+const (
+	// ScreeningClear had no match at or above the threshold.
+	ScreeningClear ScreeningOutcome = "clear"
+	// ScreeningBlocked matched and the operation was refused.
+	ScreeningBlocked ScreeningOutcome = "blocked"
+	// ScreeningHeld matched and the operation was held for approval.
+	ScreeningHeld ScreeningOutcome = "held"
+	// ScreeningFlagged matched on a manual check, with no operation to stop.
+	ScreeningFlagged ScreeningOutcome = "flagged"
+)
+
+// ScreeningMatch is a sanctions list entry that matched the screened name or
+// an identifier. Matched is the list's own name, alias or identifier that
+// matched, and Score its similarity, from 0 to 1; identifiers only match
+// exactly.
+type ScreeningMatch struct {
+	EntryUID  string   `json:"entryUid"`
+	EntryName string   `json:"entryName"`
+	Programs  []string `json:"programs,omitempty"`
+	Matched   string   `json:"matched"`
+	Score     float64  `json:"score"`
+}
+
+// ScreeningDecision records one screening: who or what was screened, against
+// which version of the list, and the outcome. Operation is the screened
+// command, such as "account creation" or "transfer", and AccountID or
+// CustomerID what it was about. Subject holds the screened name and
+// identifiers sealed under the data subject's key, so that forgetting the
+// subject erases them; manual checks, which have no data subject, keep none.
+type ScreeningDecision struct {
+	ID          string                     `json:"id"`
+	Operation   string                     `json:"operation"`
+	AccountID   string                     `json:"accountId,omitempty"`
+	CustomerID  string                     `json:"customerId,omitempty"`
+	Subject     *events.SealedPersonalData `json:"subject,omitempty"`
+	ListVersion int                        `json:"listVersion"`
+	ListDigest  string                     `json:"listDigest"`
+	Threshold   float64                    `json:"threshold"`
+	Matches     []ScreeningMatch           `json:"matches,omitempty"`
+	Outcome     ScreeningOutcome           `json:"outcome"`
+	ApprovalID  string                     `json:"approvalId,omitempty"`
+	Actor       string                     `json:"actor,omitempty"`
+	ScreenedAt  time.Time                  `json:"screenedAt"`
+}
+
+type ScreeningStore interface {
+	AddScreening(ctx context.Context, d ScreeningDecision) error
+
+	// ListScreenings returns the decisions about accountID, or every decision
+	// if accountID is empty, oldest first.
+	ListScreenings(ctx context.Context, accountID string) ([]ScreeningDecision, error)
+}
+
+type InMemoryScreeningStore struct {
+	sync.RWMutex
+	decisions []ScreeningDecision
+}
+
+func NewInMemoryScreeningStore() *InMemoryScreeningStore {
+	return &InMemoryScreeningStore{}
+}
+
+func copyScreening(d ScreeningDecision) ScreeningDecision {
+	if d.Subject != nil {
+		subject := *d.Subject
+		subject.Nonce = append([]byte(nil), subject.Nonce...)
+		subject.Data = append([]byte(nil), subject.Data...)
+		d.Subject = &subject
+	}
+	matches := make([]ScreeningMatch, len(d.Matches))
+	for i, m := range d.Matches {
+		m.Programs = append([]string(nil), m.Programs...)
+		matches[i] = m
+	}
+	d.Matches = matches
+	return d
+}
+
+func (s *InMemoryScreeningStore) AddScreening(ctx context.Context, d ScreeningDecision) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("add screening %s: %w", d.ID, err)
+	}
+	if d.ID == "" {
+		return errors.New("screening decision must have an ID")
+	}
+	s.Lock()
+	defer s.Unlock()
+	for _, existing := range s.decisions {
+		if existing.ID == d.ID {
+			return fmt.Errorf("%w: %s", ErrScreeningExists, d.ID)
+		}
+	}
+	s.decisions = append(s.decisions, copyScreening(d))
+	return nil
+}
+
+func (s *InMemoryScreeningStore) ListScreenings(ctx context.Context, accountID string) ([]ScreeningDecision, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("list screenings: %w", err)
+	}
+	s.RLock()
+	defer s.RUnlock()
+	var out []ScreeningDecision
+	for _, d := range s.decisions {
+		if accountID == "" || d.AccountID == accountID {
+			out = append(out, copyScreening(d))
+		}
+	}
+	return out, nil
+}
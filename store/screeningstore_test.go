@@ -0,0 +1,36 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+
+	"financial-ledger/store"
+)
+
+func TestInMemoryScreeningStore(t *testing.T) {
+	ctx := context.Background()
+	ss := store.NewInMemoryScreeningStore()
+
+	programs := []string{"SDGT"}
+	if err := ss.AddScreening(ctx, store.ScreeningDecision{ID: "sc-1", AccountID: "acc-1", ListVersion: 1, Outcome: store.ScreeningClear}); err != nil {
+		t.Fatalf("AddScreening failed: %v", err)
+	}
+	_ = ss.AddScreening(ctx, store.ScreeningDecision{ID: "sc-2", AccountID: "acc-2", ListVersion: 2, Outcome: store.ScreeningHeld,
+		Matches: []store.ScreeningMatch{{EntryUID: "36", Programs: programs, Score: 0.95}}})
+	programs[0] = "CUBA"
+	if err := ss.AddScreening(ctx, store.ScreeningDecision{}); err == nil {
+		t.Error("expected a decision without an ID to be refused")
+	}
+
+	all, err := ss.ListScreenings(ctx, "")
+	if err != nil || len(all) != 2 || all[0].ID != "sc-1" {
+		t.Fatalf("expected both decisions in order, got %+v (err: %v)", all, err)
+	}
+	if all[1].Matches[0].Programs[0] != "SDGT" {
+		t.Errorf("stored decision must not alias the caller's slices, got %v", all[1].Matches[0].Programs)
+	}
+	one, _ := ss.ListScreenings(ctx, "acc-2")
+	if len(one) != 1 || one[0].ListVersion != 2 {
+		t.Errorf("expected only the acc-2 decision, got %+v", one)
+	}
+}
@@ -0,0 +1,202 @@
+package store
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"financial-ledger/events"
+)
+
+var (
+	ErrUnknownSigningKey = errors.New("signature made with an unknown key")
+	ErrInvalidSignature  = errors.New("invalid signature")
+)
+
+// Signer signs ledger records. KeyID is stored next to every signature so that
+// verifiers can pick the matching public key after keys are rotated.
+type Signer interface {
+	KeyID() string
+	Sign(message []byte) ([]byte, error)
+}
+
+// Ed25519Signer signs with an Ed25519 private key. Its key ID is derived from
+// the public key, so the same key always yields the same ID.
+type Ed25519Signer struct {
+	keyID string
+	key   ed25519.PrivateKey
+}
+
+func NewEd25519Signer(key ed25519.PrivateKey) *Ed25519Signer {
+	return &Ed25519Signer{
+		keyID: KeyIDFor(key.Public().(ed25519.PublicKey)),
+		key:   key,
+	}
+}
+
+// LoadEd25519Signer reads a PEM-encoded PKCS #8 Ed25519 private key, as written
+// by GenerateEd25519KeyFiles or `openssl genpkey -algorithm ed25519`.
+func LoadEd25519Signer(path string) (*Ed25519Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("signing key %s is not a PEM private key", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %s: %w", path, err)
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key %s is a %T, not an Ed25519 key", path, parsed)
+	}
+	return NewEd25519Signer(key), nil
+}
+
+func (s *Ed25519Signer) KeyID() string {
+	return s.keyID
+}
+
+func (s *Ed25519Signer) Sign(message []byte) ([]byte, error) {
+	return ed25519.Sign(s.key, message), nil
+}
+
+func (s *Ed25519Signer) PublicKey() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+// KeyIDFor returns the key ID recorded on signatures made with pub's private key.
+func KeyIDFor(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return "ed25519:" + hex.EncodeToString(sum[:8])
+}
+
+// GenerateEd25519KeyFiles creates a new key pair, writing the private key to
+// privatePath (mode 0600) and the public key to publicPath. It returns the key ID.
+func GenerateEd25519KeyFiles(privatePath, publicPath string) (string, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate key: %w", err)
+	}
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode private key: %w", err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode public key: %w", err)
+	}
+	if err := os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}), 0o600); err != nil {
+		return "", fmt.Errorf("failed to write private key: %w", err)
+	}
+	if err := os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write public key: %w", err)
+	}
+	return KeyIDFor(pub), nil
+}
+
+// Keyring holds the public keys trusted to have signed ledger records, indexed
+// by key ID. Keep retired keys in the keyring so older signatures still verify.
+type Keyring struct {
+	keys map[string]ed25519.PublicKey
+}
+
+func NewKeyring(keys ...ed25519.PublicKey) *Keyring {
+	k := &Keyring{keys: make(map[string]ed25519.PublicKey, len(keys))}
+	for _, pub := range keys {
+		k.keys[KeyIDFor(pub)] = pub
+	}
+	return k
+}
+
+// LoadKeyring reads PEM-encoded Ed25519 keys from the given files. Public keys
+// are used as-is; for private keys only the public half is kept.
+func LoadKeyring(paths ...string) (*Keyring, error) {
+	k := NewKeyring()
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read verification key: %w", err)
+		}
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("verification key %s is not PEM encoded", path)
+		}
+
+		var parsed any
+		switch block.Type {
+		case "PUBLIC KEY":
+			parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+		case "PRIVATE KEY":
+			var priv any
+			priv, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+			if edPriv, ok := priv.(ed25519.PrivateKey); ok {
+				parsed = edPriv.Public()
+			}
+		default:
+			return nil, fmt.Errorf("verification key %s has unsupported PEM type %q", path, block.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse verification key %s: %w", path, err)
+		}
+		pub, ok := parsed.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("verification key %s is not an Ed25519 key", path)
+		}
+		k.keys[KeyIDFor(pub)] = pub
+	}
+	return k, nil
+}
+
+// KeyIDs returns the IDs of every trusted key, sorted.
+func (k *Keyring) KeyIDs() []string {
+	ids := make([]string, 0, len(k.keys))
+	for id := range k.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Verify checks a hex-encoded signature over message made by the key keyID.
+func (k *Keyring) Verify(keyID string, message []byte, signature string) error {
+	pub, ok := k.keys[keyID]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownSigningKey, keyID)
+	}
+	sig, err := hex.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature: %v", ErrInvalidSignature, err)
+	}
+	if !ed25519.Verify(pub, message, sig) {
+		return fmt.Errorf("%w: does not match key %s", ErrInvalidSignature, keyID)
+	}
+	return nil
+}
+
+// EventSigningPayload returns the bytes covered by an event's signature.
+func EventSigningPayload(base events.BaseEvent) []byte {
+	return []byte(fmt.Sprintf("ledger-event\n%s\n%s", base.Hash, base.GlobalHash))
+}
+
+// signEvent stamps a chained event with signer's signature over its hashes.
+func signEvent(event events.Event, signer Signer) (events.Event, error) {
+	sig, err := signer.Sign(EventSigningPayload(event.GetBase()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign event %s: %w", event.GetBase().EventID, err)
+	}
+	return events.WithBase(event, func(base *events.BaseEvent) {
+		base.SignatureKeyID = signer.KeyID()
+		base.Signature = hex.EncodeToString(sig)
+	}), nil
+}
@@ -0,0 +1,149 @@
+package store_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"financial-ledger/events"
+	"financial-ledger/store"
+)
+
+func newTestSigner(t *testing.T) *store.Ed25519Signer {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	return store.NewEd25519Signer(priv)
+}
+
+func TestEd25519KeyFiles(t *testing.T) {
+	dir := t.TempDir()
+	privPath := filepath.Join(dir, "ledger.key")
+	pubPath := privPath + ".pub"
+
+	keyID, err := store.GenerateEd25519KeyFiles(privPath, pubPath)
+	if err != nil {
+		t.Fatalf("GenerateEd25519KeyFiles failed: %v", err)
+	}
+
+	signer, err := store.LoadEd25519Signer(privPath)
+	if err != nil {
+		t.Fatalf("LoadEd25519Signer failed: %v", err)
+	}
+	if signer.KeyID() != keyID {
+		t.Errorf("expected key ID %s, got %s", keyID, signer.KeyID())
+	}
+
+	keyring, err := store.LoadKeyring(pubPath)
+	if err != nil {
+		t.Fatalf("LoadKeyring failed: %v", err)
+	}
+	sig, _ := signer.Sign([]byte("message"))
+	if err := keyring.Verify(keyID, []byte("message"), hex.EncodeToString(sig)); err != nil {
+		t.Errorf("expected signature to verify, got %v", err)
+	}
+	if err := keyring.Verify(keyID, []byte("other"), hex.EncodeToString(sig)); !errors.Is(err, store.ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+	if err := keyring.Verify("ed25519:unknown", []byte("message"), hex.EncodeToString(sig)); !errors.Is(err, store.ErrUnknownSigningKey) {
+		t.Errorf("expected ErrUnknownSigningKey, got %v", err)
+	}
+
+	if _, err := store.LoadEd25519Signer(pubPath); err == nil {
+		t.Errorf("expected error loading a public key as a signing key")
+	}
+}
+
+func TestVerifyChain_Signatures(t *testing.T) {
+	ctx := context.Background()
+	oldSigner := newTestSigner(t)
+	newSigner := newTestSigner(t)
+	keyring := store.NewKeyring(oldSigner.PublicKey(), newSigner.PublicKey())
+
+	// Signed with the old key, then the key is rotated for later commits.
+	es := store.NewInMemoryEventStore(store.WithEventSigner(oldSigner))
+	_ = es.SaveEvents("agg-a", 0, []events.Event{newTestEvent("agg-a", 1, "a1")})
+	rotated := store.NewInMemoryEventStore(store.WithEventSigner(newSigner))
+	stream, _ := es.GetEvents("agg-a")
+	rotated.SetStream("agg-a", stream)
+	_ = rotated.SaveEvents("agg-a", 1, []events.Event{newTestEvent("agg-a", 2, "a2")})
+
+	t.Run("EventsCarryKeyID", func(t *testing.T) {
+		stream, _ := es.GetEvents("agg-a")
+		if stream[0].GetBase().SignatureKeyID != oldSigner.KeyID() || stream[0].GetBase().Signature == "" {
+			t.Errorf("expected event signed by %s, got %+v", oldSigner.KeyID(), stream[0].GetBase())
+		}
+	})
+
+	t.Run("ValidSignatures", func(t *testing.T) {
+		report, brk, err := store.VerifyChain(ctx, es, store.WithSignatureKeys(keyring))
+		if err != nil || brk != nil {
+			t.Fatalf("expected valid chain, got break %v, err %v", brk, err)
+		}
+		if report.Signed != 1 {
+			t.Errorf("expected 1 signed event, got %d", report.Signed)
+		}
+	})
+
+	t.Run("RotatedKeysVerifyWithFullKeyring", func(t *testing.T) {
+		stream, _ := rotated.GetEvents("agg-a")
+		if stream[1].GetBase().SignatureKeyID != newSigner.KeyID() {
+			t.Errorf("expected second event signed by the new key")
+		}
+		for _, event := range stream {
+			base := event.GetBase()
+			if err := keyring.Verify(base.SignatureKeyID, store.EventSigningPayload(base), base.Signature); err != nil {
+				t.Errorf("version %d failed to verify: %v", base.Version, err)
+			}
+		}
+	})
+
+	t.Run("UnknownKey", func(t *testing.T) {
+		_, brk, err := store.VerifyChain(ctx, es, store.WithSignatureKeys(store.NewKeyring(newSigner.PublicKey())))
+		if err != nil {
+			t.Fatalf("VerifyChain failed: %v", err)
+		}
+		if brk == nil || !strings.Contains(brk.Reason, "unknown key") {
+			t.Errorf("expected unknown-key break, got %v", brk)
+		}
+	})
+
+	t.Run("FabricatedHistoryWithoutKey", func(t *testing.T) {
+		// A replica rewrites an event and recomputes the hash chain, but cannot
+		// produce a signature from a trusted key.
+		forged := store.NewInMemoryEventStore(store.WithEventSigner(newTestSigner(t)))
+		_ = forged.SaveEvents("agg-a", 0, []events.Event{newTestEvent("agg-a", 1, "forged")})
+
+		_, brk, err := store.VerifyChain(ctx, forged)
+		if err != nil || brk != nil {
+			t.Fatalf("expected hash chain alone to pass, got break %v, err %v", brk, err)
+		}
+		_, brk, err = store.VerifyChain(ctx, forged, store.WithSignatureKeys(keyring))
+		if err != nil {
+			t.Fatalf("VerifyChain failed: %v", err)
+		}
+		if brk == nil || brk.AggregateID != "agg-a" || brk.Version != 1 {
+			t.Errorf("expected signature break at agg-a version 1, got %v", brk)
+		}
+	})
+
+	t.Run("UnsignedEvent", func(t *testing.T) {
+		unsigned := store.NewInMemoryEventStore()
+		_ = unsigned.SaveEvents("agg-a", 0, []events.Event{newTestEvent("agg-a", 1, "a1")})
+
+		_, brk, err := store.VerifyChain(ctx, unsigned, store.WithSignatureKeys(keyring))
+		if err != nil {
+			t.Fatalf("VerifyChain failed: %v", err)
+		}
+		if brk == nil || brk.Reason != "event is not signed" {
+			t.Errorf("expected unsigned-event break, got %v", brk)
+		}
+	})
+}
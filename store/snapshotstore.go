@@ -1,60 +1,246 @@
 package store
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"financial-ledger/domain"
 )
 
+// SnapshotStore persists the latest snapshot per aggregate. As with EventStore,
+// the plain methods are compatibility wrappers around the *Context methods.
 type SnapshotStore interface {
 	SaveSnapshot(snapshot *domain.Snapshot) error
 
 	GetLatestSnapshot(aggregateID string) (snapshot *domain.Snapshot, found bool, err error)
+
+	SaveSnapshotContext(ctx context.Context, snapshot *domain.Snapshot) error
+
+	GetLatestSnapshotContext(ctx context.Context, aggregateID string) (snapshot *domain.Snapshot, found bool, err error)
+}
+
+// SnapshotHistory is implemented by snapshot stores that keep older snapshots,
+// so point-in-time queries can start close to the requested version.
+type SnapshotHistory interface {
+	// GetSnapshotAtOrBefore returns the snapshot with the highest version not
+	// greater than maxVersion.
+	GetSnapshotAtOrBefore(ctx context.Context, aggregateID string, maxVersion int) (snapshot *domain.Snapshot, found bool, err error)
 }
 
+// InMemorySnapshotStore keeps every snapshot of each aggregate, ordered by version.
 type InMemorySnapshotStore struct {
 	sync.RWMutex
-	snapshots map[string]*domain.Snapshot
+	snapshots map[string][]*domain.Snapshot
+	encryptor *Encryptor
 }
 
-func NewInMemorySnapshotStore() *InMemorySnapshotStore {
-	return &InMemorySnapshotStore{
-		snapshots: make(map[string]*domain.Snapshot),
+// InMemorySnapshotStoreOption customises an InMemorySnapshotStore.
+type InMemorySnapshotStoreOption func(*InMemorySnapshotStore)
+
+// WithSnapshotEncryption keeps snapshot state encrypted at rest. The stored
+// State holds a JSON-encoded Ciphertext; reads return the decrypted state.
+func WithSnapshotEncryption(enc *Encryptor) InMemorySnapshotStoreOption {
+	return func(s *InMemorySnapshotStore) {
+		s.encryptor = enc
 	}
 }
 
+func NewInMemorySnapshotStore(opts ...InMemorySnapshotStoreOption) *InMemorySnapshotStore {
+	s := &InMemorySnapshotStore{
+		snapshots: make(map[string][]*domain.Snapshot),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
 func (s *InMemorySnapshotStore) SaveSnapshot(snapshot *domain.Snapshot) error {
+	return s.SaveSnapshotContext(context.Background(), snapshot)
+}
+
+func (s *InMemorySnapshotStore) SaveSnapshotContext(ctx context.Context, snapshot *domain.Snapshot) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("save snapshot: %w", err)
+	}
 	if snapshot == nil {
 		return fmt.Errorf("cannot save nil snapshot")
 	}
 	s.Lock()
 	defer s.Unlock()
 
-	s.snapshots[snapshot.AggregateID] = snapshot
 	snapshot.Timestamp = time.Now().UTC()
+	stored := snapshot
+	if s.encryptor != nil {
+		sealed, err := sealSnapshot(s.encryptor, snapshot)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt snapshot for aggregate %s: %w", snapshot.AggregateID, err)
+		}
+		stored = sealed
+	}
+
+	// Keep the history ordered by version; a snapshot at an existing version replaces it.
+	history := s.snapshots[snapshot.AggregateID]
+	i := sort.Search(len(history), func(i int) bool { return history[i].Version >= snapshot.Version })
+	if i < len(history) && history[i].Version == snapshot.Version {
+		history[i] = stored
+	} else {
+		history = append(history, nil)
+		copy(history[i+1:], history[i:])
+		history[i] = stored
+	}
+	s.snapshots[snapshot.AggregateID] = history
 	return nil
 }
 
 func (s *InMemorySnapshotStore) GetLatestSnapshot(aggregateID string) (*domain.Snapshot, bool, error) {
+	return s.GetLatestSnapshotContext(context.Background(), aggregateID)
+}
+
+func (s *InMemorySnapshotStore) GetLatestSnapshotContext(ctx context.Context, aggregateID string) (*domain.Snapshot, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, fmt.Errorf("get snapshot for aggregate %s: %w", aggregateID, err)
+	}
+	s.RLock()
+	defer s.RUnlock()
+
+	history := s.snapshots[aggregateID]
+	if len(history) == 0 {
+		return nil, false, nil
+	}
+	return s.copySnapshot(history[len(history)-1])
+}
+
+func (s *InMemorySnapshotStore) GetSnapshotAtOrBefore(ctx context.Context, aggregateID string, maxVersion int) (*domain.Snapshot, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, fmt.Errorf("get snapshot at version %d for aggregate %s: %w", maxVersion, aggregateID, err)
+	}
 	s.RLock()
 	defer s.RUnlock()
 
-	snapshot, found := s.snapshots[aggregateID]
-	if !found {
+	history := s.snapshots[aggregateID]
+	i := sort.Search(len(history), func(i int) bool { return history[i].Version > maxVersion })
+	if i == 0 {
 		return nil, false, nil
 	}
+	return s.copySnapshot(history[i-1])
+}
 
+// copySnapshot returns a decrypted copy of a stored snapshot. The caller must
+// hold at least a read lock.
+func (s *InMemorySnapshotStore) copySnapshot(snapshot *domain.Snapshot) (*domain.Snapshot, bool, error) {
 	stateCopy := make([]byte, len(snapshot.State))
 	copy(stateCopy, snapshot.State)
+	if s.encryptor != nil {
+		state, err := openSnapshotState(s.encryptor, snapshot)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to decrypt snapshot for aggregate %s: %w", snapshot.AggregateID, err)
+		}
+		stateCopy = state
+	}
 
 	snapCopy := &domain.Snapshot{
 		AggregateID: snapshot.AggregateID,
 		Version:     snapshot.Version,
+		Schema:      snapshot.Schema,
 		State:       stateCopy,
 		Timestamp:   snapshot.Timestamp,
 	}
 
 	return snapCopy, true, nil
 }
+
+var _ SnapshotHistory = (*InMemorySnapshotStore)(nil)
+
+// Reencrypt re-seals every snapshot whose state is not under the current data
+// key of its scope. It returns the number of snapshots rewritten.
+func (s *InMemorySnapshotStore) Reencrypt(ctx context.Context) (int, error) {
+	if s.encryptor == nil {
+		return 0, errors.New("snapshot store is not configured for encryption")
+	}
+	s.Lock()
+	defer s.Unlock()
+
+	rewritten := 0
+	for id, history := range s.snapshots {
+		for i, stored := range history {
+			if err := ctx.Err(); err != nil {
+				return rewritten, fmt.Errorf("re-encrypt snapshots: %w", err)
+			}
+			var envelope Ciphertext
+			if err := json.Unmarshal(stored.State, &envelope); err == nil && envelope.KeyID != "" && s.encryptor.IsCurrent(id, envelope) {
+				continue
+			}
+			state, err := openSnapshotState(s.encryptor, stored)
+			if err != nil {
+				return rewritten, fmt.Errorf("failed to decrypt snapshot for aggregate %s at version %d: %w", id, stored.Version, err)
+			}
+			plain := *stored
+			plain.State = state
+			resealed, err := sealSnapshot(s.encryptor, &plain)
+			if err != nil {
+				return rewritten, fmt.Errorf("failed to re-encrypt snapshot for aggregate %s at version %d: %w", id, stored.Version, err)
+			}
+			history[i] = resealed
+			rewritten++
+		}
+	}
+	return rewritten, nil
+}
+
+// DataKeysInUse returns the IDs of the data keys that sealed the stored
+// snapshots, for Encryptor.RetireDataKeys.
+func (s *InMemorySnapshotStore) DataKeysInUse(ctx context.Context) (map[string]bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("list data keys in use: %w", err)
+	}
+	s.RLock()
+	defer s.RUnlock()
+
+	inUse := make(map[string]bool)
+	for _, history := range s.snapshots {
+		for _, stored := range history {
+			var envelope Ciphertext
+			if err := json.Unmarshal(stored.State, &envelope); err == nil && envelope.KeyID != "" {
+				inUse[envelope.KeyID] = true
+			}
+		}
+	}
+	return inUse, nil
+}
+
+func snapshotAAD(snapshot *domain.Snapshot) []byte {
+	return []byte(fmt.Sprintf("ledger-snapshot\n%s\n%d", snapshot.AggregateID, snapshot.Version))
+}
+
+// sealSnapshot returns a copy of snapshot whose State is the JSON-encoded
+// Ciphertext of the original state.
+func sealSnapshot(enc *Encryptor, snapshot *domain.Snapshot) (*domain.Snapshot, error) {
+	payload, err := enc.Seal(snapshot.AggregateID, snapshot.State, snapshotAAD(snapshot))
+	if err != nil {
+		return nil, err
+	}
+	envelope, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	sealed := *snapshot
+	sealed.State = envelope
+	return &sealed, nil
+}
+
+func openSnapshotState(enc *Encryptor, stored *domain.Snapshot) ([]byte, error) {
+	var envelope Ciphertext
+	if err := json.Unmarshal(stored.State, &envelope); err != nil || envelope.KeyID == "" {
+		// Saved before encryption was enabled.
+		state := make([]byte, len(stored.State))
+		copy(state, stored.State)
+		return state, nil
+	}
+	return enc.Open(envelope, snapshotAAD(stored))
+}
@@ -1,6 +1,7 @@
 package store_test
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 	"time"
@@ -110,3 +111,44 @@ func TestInMemorySnapshotStore_SaveAndGetSnapshot(t *testing.T) {
 		}
 	})
 }
+
+func TestInMemorySnapshotStore_GetSnapshotAtOrBefore(t *testing.T) {
+	ctx := context.Background()
+	ss := store.NewInMemorySnapshotStore()
+	aggID := "snap-hist-1"
+	for _, v := range []int{200, 100, 300} {
+		if err := ss.SaveSnapshot(newSnapshot(aggID, v, map[string]interface{}{"v": v})); err != nil {
+			t.Fatalf("SaveSnapshot(%d) failed: %v", v, err)
+		}
+	}
+
+	tests := []struct {
+		maxVersion int
+		want       int
+		found      bool
+	}{
+		{50, 0, false},
+		{100, 100, true},
+		{199, 100, true},
+		{250, 200, true},
+		{1000, 300, true},
+	}
+	for _, tt := range tests {
+		snap, found, err := ss.GetSnapshotAtOrBefore(ctx, aggID, tt.maxVersion)
+		if err != nil {
+			t.Fatalf("GetSnapshotAtOrBefore(%d) failed: %v", tt.maxVersion, err)
+		}
+		if found != tt.found {
+			t.Errorf("GetSnapshotAtOrBefore(%d): expected found=%v, got %v", tt.maxVersion, tt.found, found)
+			continue
+		}
+		if found && snap.Version != tt.want {
+			t.Errorf("GetSnapshotAtOrBefore(%d): expected version %d, got %d", tt.maxVersion, tt.want, snap.Version)
+		}
+	}
+
+	latest, _, _ := ss.GetLatestSnapshot(aggID)
+	if latest.Version != 300 {
+		t.Errorf("expected latest snapshot at version 300, got %d", latest.Version)
+	}
+}
@@ -0,0 +1,147 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"financial-ledger/events"
+)
+
+var ErrSubjectForgotten = errors.New("data subject has been forgotten")
+
+// ShredRecord is the lasting trace of a "forget subject" request: when it
+// happened and how many keys were destroyed. It holds no personal data.
+type ShredRecord struct {
+	SubjectID     string    `json:"subjectId"`
+	KeysDestroyed int       `json:"keysDestroyed"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// SubjectKeyStore keeps one encryption key per data subject and uses it to seal
+// that subject's personal data. Shredding a subject destroys its keys, which
+// makes every copy of the data unreadable, including those inside immutable
+// events, snapshots and backups.
+type SubjectKeyStore interface {
+	// Seal encrypts plaintext under subjectID's key, creating the key on first use.
+	Seal(ctx context.Context, subjectID string, plaintext []byte) (events.SealedPersonalData, error)
+
+	// Open decrypts sealed. It returns ErrSubjectForgotten if the key was shredded.
+	Open(ctx context.Context, sealed events.SealedPersonalData) ([]byte, error)
+
+	// Shred destroys every key held for subjectID.
+	Shred(ctx context.Context, subjectID string) (ShredRecord, error)
+}
+
+type InMemorySubjectKeyStore struct {
+	sync.RWMutex
+	keys     map[string][]byte // by key ID
+	current  map[string]string // subject ID -> key ID
+	counter  map[string]int    // subject ID -> number of keys created
+	shredded map[string]ShredRecord
+}
+
+func NewInMemorySubjectKeyStore() *InMemorySubjectKeyStore {
+	return &InMemorySubjectKeyStore{
+		keys:     make(map[string][]byte),
+		current:  make(map[string]string),
+		counter:  make(map[string]int),
+		shredded: make(map[string]ShredRecord),
+	}
+}
+
+func personalDataAAD(subjectID string) []byte {
+	return []byte("ledger-personal-data\n" + subjectID)
+}
+
+func (s *InMemorySubjectKeyStore) Seal(ctx context.Context, subjectID string, plaintext []byte) (events.SealedPersonalData, error) {
+	if err := ctx.Err(); err != nil {
+		return events.SealedPersonalData{}, fmt.Errorf("seal personal data: %w", err)
+	}
+	if subjectID == "" {
+		return events.SealedPersonalData{}, errors.New("subject ID cannot be empty")
+	}
+
+	keyID, key, err := s.currentKey(subjectID)
+	if err != nil {
+		return events.SealedPersonalData{}, err
+	}
+	sealed, err := seal(key, plaintext, personalDataAAD(subjectID))
+	if err != nil {
+		return events.SealedPersonalData{}, fmt.Errorf("failed to seal personal data for subject %s: %w", subjectID, err)
+	}
+	return events.SealedPersonalData{SubjectID: subjectID, KeyID: keyID, Nonce: sealed.Nonce, Data: sealed.Data}, nil
+}
+
+func (s *InMemorySubjectKeyStore) Open(ctx context.Context, sealed events.SealedPersonalData) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("open personal data: %w", err)
+	}
+	s.RLock()
+	key, ok := s.keys[sealed.KeyID]
+	key = append([]byte(nil), key...)
+	s.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: key %s for subject %s no longer exists", ErrSubjectForgotten, sealed.KeyID, sealed.SubjectID)
+	}
+	return open(key, Ciphertext{KeyID: sealed.KeyID, Nonce: sealed.Nonce, Data: sealed.Data}, personalDataAAD(sealed.SubjectID))
+}
+
+func (s *InMemorySubjectKeyStore) Shred(ctx context.Context, subjectID string) (ShredRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return ShredRecord{}, fmt.Errorf("shred subject %s: %w", subjectID, err)
+	}
+	s.Lock()
+	defer s.Unlock()
+
+	destroyed := 0
+	for n := 1; n <= s.counter[subjectID]; n++ {
+		keyID := subjectKeyID(subjectID, n)
+		if key, ok := s.keys[keyID]; ok {
+			clear(key)
+			delete(s.keys, keyID)
+			destroyed++
+		}
+	}
+	delete(s.current, subjectID)
+
+	record := ShredRecord{SubjectID: subjectID, KeysDestroyed: destroyed, Timestamp: time.Now().UTC()}
+	s.shredded[subjectID] = record
+	return record, nil
+}
+
+// GetShredRecord reports whether subjectID was forgotten, and when.
+func (s *InMemorySubjectKeyStore) GetShredRecord(subjectID string) (ShredRecord, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	record, ok := s.shredded[subjectID]
+	return record, ok
+}
+
+// currentKey returns the subject's active key, creating one if none exists. A
+// subject who was forgotten and later returns gets a fresh key, so data sealed
+// before the erasure stays unreadable.
+func (s *InMemorySubjectKeyStore) currentKey(subjectID string) (string, []byte, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if keyID, ok := s.current[subjectID]; ok {
+		return keyID, append([]byte(nil), s.keys[keyID]...), nil
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", nil, fmt.Errorf("failed to generate key for subject %s: %w", subjectID, err)
+	}
+	s.counter[subjectID]++
+	keyID := subjectKeyID(subjectID, s.counter[subjectID])
+	s.keys[keyID] = key
+	s.current[subjectID] = keyID
+	return keyID, append([]byte(nil), key...), nil
+}
+
+func subjectKeyID(subjectID string, n int) string {
+	return fmt.Sprintf("sk:%s:%d", subjectID, n)
+}
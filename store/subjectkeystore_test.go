@@ -0,0 +1,64 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"financial-ledger/store"
+)
+
+func TestInMemorySubjectKeyStore(t *testing.T) {
+	ctx := context.Background()
+	ks := store.NewInMemorySubjectKeyStore()
+
+	sealed, err := ks.Seal(ctx, "subj-1", []byte("Jane Doe"))
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	other, _ := ks.Seal(ctx, "subj-2", []byte("John Roe"))
+
+	t.Run("OpenBeforeShred", func(t *testing.T) {
+		plain, err := ks.Open(ctx, sealed)
+		if err != nil || string(plain) != "Jane Doe" {
+			t.Errorf("Open returned %q, %v", plain, err)
+		}
+	})
+
+	t.Run("BoundToSubject", func(t *testing.T) {
+		moved := sealed
+		moved.SubjectID = "subj-2"
+		if _, err := ks.Open(ctx, moved); err == nil {
+			t.Errorf("expected ciphertext moved to another subject to fail")
+		}
+	})
+
+	t.Run("Shred", func(t *testing.T) {
+		record, err := ks.Shred(ctx, "subj-1")
+		if err != nil || record.KeysDestroyed != 1 {
+			t.Fatalf("expected 1 key destroyed, got %+v (err: %v)", record, err)
+		}
+		if _, err := ks.Open(ctx, sealed); !errors.Is(err, store.ErrSubjectForgotten) {
+			t.Errorf("expected ErrSubjectForgotten, got %v", err)
+		}
+		if plain, err := ks.Open(ctx, other); err != nil || string(plain) != "John Roe" {
+			t.Errorf("other subjects must stay readable, got %q, %v", plain, err)
+		}
+		if _, found := ks.GetShredRecord("subj-1"); !found {
+			t.Errorf("expected a shred record for subj-1")
+		}
+	})
+
+	t.Run("ReturningSubjectGetsNewKey", func(t *testing.T) {
+		fresh, err := ks.Seal(ctx, "subj-1", []byte("new data"))
+		if err != nil {
+			t.Fatalf("Seal failed: %v", err)
+		}
+		if fresh.KeyID == sealed.KeyID {
+			t.Errorf("expected a new key after shredding, got %s again", fresh.KeyID)
+		}
+		if _, err := ks.Open(ctx, sealed); !errors.Is(err, store.ErrSubjectForgotten) {
+			t.Errorf("data sealed before the erasure must stay unreadable, got %v", err)
+		}
+	})
+}
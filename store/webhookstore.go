@@ -0,0 +1,224 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook subscription not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// WebhookSubscription asks for notifications of the given types to be posted
+// to URL. An empty EventTypes matches every type. Secret signs the payloads.
+type WebhookSubscription struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"eventTypes,omitempty"`
+	Secret     string    `json:"secret"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	DeliveryPending   WebhookDeliveryStatus = "pending"
+	DeliveryDelivered WebhookDeliveryStatus = "delivered"
+	DeliveryDead      WebhookDeliveryStatus = "dead"
+)
+
+// WebhookDelivery is one notification on its way to one subscription. Pending
+// deliveries are attempted once NextAttemptAt has passed; deliveries that used
+// up their attempts are dead-lettered until replayed.
+type WebhookDelivery struct {
+	ID             string                `json:"id"`
+	SubscriptionID string                `json:"subscriptionId"`
+	Type           string                `json:"type"`
+	Payload        []byte                `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	NextAttemptAt  time.Time             `json:"nextAttemptAt"`
+	LastError      string                `json:"lastError,omitempty"`
+	LastStatusCode int                   `json:"lastStatusCode,omitempty"`
+	CreatedAt      time.Time             `json:"createdAt"`
+	UpdatedAt      time.Time             `json:"updatedAt"`
+}
+
+type WebhookStore interface {
+	SaveSubscription(ctx context.Context, sub WebhookSubscription) error
+	GetSubscription(ctx context.Context, id string) (WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+
+	// EnqueueDelivery adds a delivery unless one with the same ID exists, in
+	// which case it reports added == false and changes nothing. This makes
+	// enqueueing safe to repeat when events are redelivered.
+	EnqueueDelivery(ctx context.Context, d WebhookDelivery) (added bool, err error)
+
+	// DueDeliveries returns up to limit pending deliveries whose NextAttemptAt
+	// is not after now, oldest first.
+	DueDeliveries(ctx context.Context, now time.Time, limit int) ([]WebhookDelivery, error)
+
+	UpdateDelivery(ctx context.Context, d WebhookDelivery) error
+	GetDelivery(ctx context.Context, id string) (WebhookDelivery, error)
+
+	// ListDeliveries returns deliveries with the given status, or every
+	// delivery if status is empty, oldest first.
+	ListDeliveries(ctx context.Context, status WebhookDeliveryStatus) ([]WebhookDelivery, error)
+}
+
+type InMemoryWebhookStore struct {
+	sync.RWMutex
+	subscriptions map[string]WebhookSubscription
+	deliveries    map[string]WebhookDelivery
+	order         []string // delivery IDs in enqueue order
+}
+
+func NewInMemoryWebhookStore() *InMemoryWebhookStore {
+	return &InMemoryWebhookStore{
+		subscriptions: make(map[string]WebhookSubscription),
+		deliveries:    make(map[string]WebhookDelivery),
+	}
+}
+
+func (s *InMemoryWebhookStore) SaveSubscription(ctx context.Context, sub WebhookSubscription) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("save webhook subscription %s: %w", sub.ID, err)
+	}
+	if sub.ID == "" {
+		return errors.New("webhook subscription must have an ID")
+	}
+	s.Lock()
+	defer s.Unlock()
+	sub.EventTypes = append([]string(nil), sub.EventTypes...)
+	s.subscriptions[sub.ID] = sub
+	return nil
+}
+
+func (s *InMemoryWebhookStore) GetSubscription(ctx context.Context, id string) (WebhookSubscription, error) {
+	if err := ctx.Err(); err != nil {
+		return WebhookSubscription{}, fmt.Errorf("get webhook subscription %s: %w", id, err)
+	}
+	s.RLock()
+	defer s.RUnlock()
+	sub, ok := s.subscriptions[id]
+	if !ok {
+		return WebhookSubscription{}, fmt.Errorf("%w: %s", ErrWebhookNotFound, id)
+	}
+	return sub, nil
+}
+
+func (s *InMemoryWebhookStore) ListSubscriptions(ctx context.Context) ([]WebhookSubscription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("list webhook subscriptions: %w", err)
+	}
+	s.RLock()
+	defer s.RUnlock()
+	out := make([]WebhookSubscription, 0, len(s.subscriptions))
+	for _, sub := range s.subscriptions {
+		out = append(out, sub)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.Before(out[j].CreatedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+func (s *InMemoryWebhookStore) DeleteSubscription(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("delete webhook subscription %s: %w", id, err)
+	}
+	s.Lock()
+	defer s.Unlock()
+	if _, ok := s.subscriptions[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrWebhookNotFound, id)
+	}
+	delete(s.subscriptions, id)
+	return nil
+}
+
+func (s *InMemoryWebhookStore) EnqueueDelivery(ctx context.Context, d WebhookDelivery) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, fmt.Errorf("enqueue webhook delivery %s: %w", d.ID, err)
+	}
+	if d.ID == "" {
+		return false, errors.New("webhook delivery must have an ID")
+	}
+	s.Lock()
+	defer s.Unlock()
+	if _, exists := s.deliveries[d.ID]; exists {
+		return false, nil
+	}
+	s.deliveries[d.ID] = d
+	s.order = append(s.order, d.ID)
+	return true, nil
+}
+
+func (s *InMemoryWebhookStore) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]WebhookDelivery, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("read due webhook deliveries: %w", err)
+	}
+	s.RLock()
+	defer s.RUnlock()
+	var due []WebhookDelivery
+	for _, id := range s.order {
+		d := s.deliveries[id]
+		if d.Status != DeliveryPending || d.NextAttemptAt.After(now) {
+			continue
+		}
+		due = append(due, d)
+		if limit > 0 && len(due) == limit {
+			break
+		}
+	}
+	return due, nil
+}
+
+func (s *InMemoryWebhookStore) UpdateDelivery(ctx context.Context, d WebhookDelivery) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("update webhook delivery %s: %w", d.ID, err)
+	}
+	s.Lock()
+	defer s.Unlock()
+	if _, ok := s.deliveries[d.ID]; !ok {
+		return fmt.Errorf("%w: %s", ErrDeliveryNotFound, d.ID)
+	}
+	s.deliveries[d.ID] = d
+	return nil
+}
+
+func (s *InMemoryWebhookStore) GetDelivery(ctx context.Context, id string) (WebhookDelivery, error) {
+	if err := ctx.Err(); err != nil {
+		return WebhookDelivery{}, fmt.Errorf("get webhook delivery %s: %w", id, err)
+	}
+	s.RLock()
+	defer s.RUnlock()
+	d, ok := s.deliveries[id]
+	if !ok {
+		return WebhookDelivery{}, fmt.Errorf("%w: %s", ErrDeliveryNotFound, id)
+	}
+	return d, nil
+}
+
+func (s *InMemoryWebhookStore) ListDeliveries(ctx context.Context, status WebhookDeliveryStatus) ([]WebhookDelivery, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("list webhook deliveries: %w", err)
+	}
+	s.RLock()
+	defer s.RUnlock()
+	var out []WebhookDelivery
+	for _, id := range s.order {
+		if d := s.deliveries[id]; status == "" || d.Status == status {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
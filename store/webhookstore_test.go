@@ -0,0 +1,90 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"financial-ledger/store"
+)
+
+func TestInMemoryWebhookStore(t *testing.T) {
+	ctx := context.Background()
+	ws := store.NewInMemoryWebhookStore()
+	now := time.Now().UTC()
+
+	t.Run("Subscriptions", func(t *testing.T) {
+		sub := store.WebhookSubscription{ID: "wh-1", URL: "http://example.test/hook", EventTypes: []string{"DepositMade"}, CreatedAt: now}
+		if err := ws.SaveSubscription(ctx, sub); err != nil {
+			t.Fatalf("SaveSubscription failed: %v", err)
+		}
+		got, err := ws.GetSubscription(ctx, "wh-1")
+		if err != nil || got.URL != sub.URL || len(got.EventTypes) != 1 {
+			t.Errorf("GetSubscription returned %+v, %v", got, err)
+		}
+		if err := ws.DeleteSubscription(ctx, "wh-1"); err != nil {
+			t.Fatalf("DeleteSubscription failed: %v", err)
+		}
+		if _, err := ws.GetSubscription(ctx, "wh-1"); !errors.Is(err, store.ErrWebhookNotFound) {
+			t.Errorf("expected ErrWebhookNotFound after delete, got %v", err)
+		}
+		if err := ws.DeleteSubscription(ctx, "wh-1"); !errors.Is(err, store.ErrWebhookNotFound) {
+			t.Errorf("expected ErrWebhookNotFound deleting twice, got %v", err)
+		}
+	})
+
+	t.Run("EnqueueIsIdempotent", func(t *testing.T) {
+		d := store.WebhookDelivery{ID: "d-1", Status: store.DeliveryPending, NextAttemptAt: now}
+		added, err := ws.EnqueueDelivery(ctx, d)
+		if err != nil || !added {
+			t.Fatalf("first enqueue: added=%v err=%v", added, err)
+		}
+		d.Type = "changed"
+		added, err = ws.EnqueueDelivery(ctx, d)
+		if err != nil || added {
+			t.Errorf("second enqueue: added=%v err=%v", added, err)
+		}
+		got, _ := ws.GetDelivery(ctx, "d-1")
+		if got.Type != "" {
+			t.Errorf("duplicate enqueue must not overwrite, got type %q", got.Type)
+		}
+	})
+
+	t.Run("DueDeliveries", func(t *testing.T) {
+		ws.EnqueueDelivery(ctx, store.WebhookDelivery{ID: "d-2", Status: store.DeliveryPending, NextAttemptAt: now.Add(time.Minute)})
+		ws.EnqueueDelivery(ctx, store.WebhookDelivery{ID: "d-3", Status: store.DeliveryDead, NextAttemptAt: now})
+
+		due, err := ws.DueDeliveries(ctx, now, 0)
+		if err != nil || len(due) != 1 || due[0].ID != "d-1" {
+			t.Fatalf("expected only d-1 due now, got %+v (err: %v)", due, err)
+		}
+		due, _ = ws.DueDeliveries(ctx, now.Add(time.Hour), 0)
+		if len(due) != 2 || due[0].ID != "d-1" || due[1].ID != "d-2" {
+			t.Errorf("expected d-1, d-2 in enqueue order, got %+v", due)
+		}
+		due, _ = ws.DueDeliveries(ctx, now.Add(time.Hour), 1)
+		if len(due) != 1 {
+			t.Errorf("expected limit to apply, got %d", len(due))
+		}
+	})
+
+	t.Run("UpdateAndList", func(t *testing.T) {
+		d, _ := ws.GetDelivery(ctx, "d-1")
+		d.Status = store.DeliveryDelivered
+		if err := ws.UpdateDelivery(ctx, d); err != nil {
+			t.Fatalf("UpdateDelivery failed: %v", err)
+		}
+		if err := ws.UpdateDelivery(ctx, store.WebhookDelivery{ID: "missing"}); !errors.Is(err, store.ErrDeliveryNotFound) {
+			t.Errorf("expected ErrDeliveryNotFound, got %v", err)
+		}
+		dead, _ := ws.ListDeliveries(ctx, store.DeliveryDead)
+		if len(dead) != 1 || dead[0].ID != "d-3" {
+			t.Errorf("expected d-3 dead-lettered, got %+v", dead)
+		}
+		all, _ := ws.ListDeliveries(ctx, "")
+		if len(all) != 3 {
+			t.Errorf("expected 3 deliveries in total, got %d", len(all))
+		}
+	})
+}
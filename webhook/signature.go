@@ -0,0 +1,65 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader carries the payload signature in the form
+// "t=<unix seconds>,v1=<hex HMAC-SHA256>". The MAC covers "<t>.<body>", so a
+// captured request cannot be replayed with a different timestamp.
+const SignatureHeader = "X-Ledger-Signature"
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Sign returns the SignatureHeader value for body sent at timestamp.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + t + ",v1=" + mac(secret, t, body)
+}
+
+// Verify checks a SignatureHeader value against body. Signatures older than
+// tolerance (relative to now) are rejected; a tolerance of 0 disables the check.
+// Receivers should use it as-is rather than comparing MACs themselves.
+func Verify(secret, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var t, sig string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			t = value
+		case "v1":
+			sig = value
+		}
+	}
+	if t == "" || sig == "" {
+		return fmt.Errorf("%w: header %q lacks t or v1", ErrInvalidSignature, header)
+	}
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: bad timestamp %q", ErrInvalidSignature, t)
+	}
+	if tolerance > 0 {
+		if age := now.Sub(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+			return fmt.Errorf("%w: timestamp is %s away from now", ErrInvalidSignature, age.Round(time.Second))
+		}
+	}
+	if !hmac.Equal([]byte(sig), []byte(mac(secret, t, body))) {
+		return fmt.Errorf("%w: MAC does not match", ErrInvalidSignature)
+	}
+	return nil
+}
+
+func mac(secret, timestamp string, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(timestamp))
+	h.Write([]byte("."))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
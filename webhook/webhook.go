@@ -0,0 +1,357 @@
+// Package webhook notifies downstream systems of ledger activity by posting
+// signed JSON payloads to subscribed URLs.
+//
+// The Dispatcher is a projection: as it reads the global log it enqueues one
+// delivery per matching subscription in a store.WebhookStore. Deliveries are
+// sent separately by DeliverDue, so a slow or failing receiver never holds up
+// other projections. Failed deliveries are retried with exponential backoff
+// and dead-lettered once the RetryPolicy is used up; dead letters can be
+// inspected and replayed.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"financial-ledger/events"
+	"financial-ledger/store"
+)
+
+// ProjectionName is the name the Dispatcher registers under with the runner.
+const ProjectionName = "webhooks"
+
+// CommandRejectedType is the notification type sent when a command is
+// rejected by a business rule. It is not an event type: rejected commands
+// leave nothing in the event log.
+const CommandRejectedType = "CommandRejected"
+
+// Headers sent with every delivery besides SignatureHeader.
+const (
+	DeliveryHeader = "X-Ledger-Delivery"
+	TypeHeader     = "X-Ledger-Event-Type"
+)
+
+var ErrNotDeadLettered = errors.New("webhook delivery is not dead-lettered")
+
+// RetryPolicy controls redelivery. The delay before attempt n+1 is
+// BaseDelay * 2^(n-1), capped at MaxDelay.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryPolicy makes 8 attempts over roughly 20 minutes.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 8, BaseDelay: 10 * time.Second, MaxDelay: 10 * time.Minute}
+}
+
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.BaseDelay << (attempt - 1)
+	if d <= 0 || (p.MaxDelay > 0 && d > p.MaxDelay) {
+		d = p.MaxDelay
+	}
+	return d
+}
+
+// Payload is the JSON body of every delivery. ID is the event ID for ledger
+// events; receivers should use it to discard duplicates, since delivery is at
+// least once.
+type Payload struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// DeliveryReport summarises one DeliverDue pass.
+type DeliveryReport struct {
+	Delivered    int
+	Retrying     int
+	DeadLettered int
+}
+
+type Option func(*Dispatcher)
+
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(d *Dispatcher) {
+		if p.MaxAttempts > 0 {
+			d.retry = p
+		}
+	}
+}
+
+// WithHTTPClient replaces the default client, which times out after 10 seconds.
+func WithHTTPClient(c *http.Client) Option {
+	return func(d *Dispatcher) {
+		if c != nil {
+			d.client = c
+		}
+	}
+}
+
+// WithClock replaces time.Now, for tests.
+func WithClock(now func() time.Time) Option {
+	return func(d *Dispatcher) {
+		if now != nil {
+			d.now = now
+		}
+	}
+}
+
+type Dispatcher struct {
+	store  store.WebhookStore
+	client *http.Client
+	retry  RetryPolicy
+	now    func() time.Time
+}
+
+func NewDispatcher(ws store.WebhookStore, opts ...Option) *Dispatcher {
+	d := &Dispatcher{
+		store:  ws,
+		client: &http.Client{Timeout: 10 * time.Second},
+		retry:  DefaultRetryPolicy(),
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// --- Subscriptions ---
+
+// Subscribe registers target for notifications of eventTypes (all types if
+// empty) and returns the subscription, including the secret its payloads are
+// signed with.
+func (d *Dispatcher) Subscribe(ctx context.Context, target string, eventTypes []string) (store.WebhookSubscription, error) {
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return store.WebhookSubscription{}, fmt.Errorf("webhook URL must be an absolute http or https URL, got %q", target)
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return store.WebhookSubscription{}, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	sub := store.WebhookSubscription{
+		ID:         "wh_" + uuid.NewString(),
+		URL:        target,
+		EventTypes: eventTypes,
+		Secret:     "whsec_" + hex.EncodeToString(secret),
+		CreatedAt:  d.now().UTC(),
+	}
+	if err := d.store.SaveSubscription(ctx, sub); err != nil {
+		return store.WebhookSubscription{}, fmt.Errorf("failed to save webhook subscription: %w", err)
+	}
+	return sub, nil
+}
+
+func (d *Dispatcher) Unsubscribe(ctx context.Context, id string) error {
+	return d.store.DeleteSubscription(ctx, id)
+}
+
+func (d *Dispatcher) Subscriptions(ctx context.Context) ([]store.WebhookSubscription, error) {
+	return d.store.ListSubscriptions(ctx)
+}
+
+// --- Projection ---
+
+func (d *Dispatcher) Name() string {
+	return ProjectionName
+}
+
+// Apply enqueues a delivery of event for every matching subscription.
+// Delivery IDs are derived from the subscription and event IDs, so applying an
+// event twice enqueues it once.
+func (d *Dispatcher) Apply(ctx context.Context, event events.Event) error {
+	base := event.GetBase()
+	return d.enqueue(ctx, base.EventID.String(), string(base.Type), base.Timestamp, event)
+}
+
+// Reset does nothing. Deliveries already enqueued are kept, and a rebuild does
+// not enqueue them again, nor send events older than a subscription to it.
+func (d *Dispatcher) Reset(ctx context.Context) error {
+	return nil
+}
+
+// Notify enqueues a notification that is not a ledger event, such as
+// CommandRejectedType. id must be unique to the notification.
+func (d *Dispatcher) Notify(ctx context.Context, notificationType, id string, data any) error {
+	return d.enqueue(ctx, id, notificationType, d.now().UTC(), data)
+}
+
+func (d *Dispatcher) enqueue(ctx context.Context, id, notificationType string, createdAt time.Time, data any) error {
+	subs, err := d.store.ListSubscriptions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	var body []byte
+	for _, sub := range subs {
+		if len(sub.EventTypes) > 0 && !slices.Contains(sub.EventTypes, notificationType) {
+			continue
+		}
+		if createdAt.Before(sub.CreatedAt) {
+			continue // subscriptions are not sent history
+		}
+		if body == nil {
+			raw, err := json.Marshal(data)
+			if err != nil {
+				return fmt.Errorf("failed to encode %s notification %s: %w", notificationType, id, err)
+			}
+			if body, err = json.Marshal(Payload{ID: id, Type: notificationType, CreatedAt: createdAt, Data: raw}); err != nil {
+				return fmt.Errorf("failed to encode %s notification %s: %w", notificationType, id, err)
+			}
+		}
+		now := d.now().UTC()
+		_, err := d.store.EnqueueDelivery(ctx, store.WebhookDelivery{
+			ID:             sub.ID + ":" + id,
+			SubscriptionID: sub.ID,
+			Type:           notificationType,
+			Payload:        body,
+			Status:         store.DeliveryPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to enqueue %s notification %s for webhook %s: %w", notificationType, id, sub.ID, err)
+		}
+	}
+	return nil
+}
+
+// --- Delivery ---
+
+// DeliverDue attempts every pending delivery whose retry time has come.
+func (d *Dispatcher) DeliverDue(ctx context.Context) (DeliveryReport, error) {
+	var report DeliveryReport
+	due, err := d.store.DueDeliveries(ctx, d.now(), 0)
+	if err != nil {
+		return report, fmt.Errorf("failed to read due webhook deliveries: %w", err)
+	}
+	for _, delivery := range due {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		delivery = d.attempt(ctx, delivery)
+		if err := d.store.UpdateDelivery(ctx, delivery); err != nil {
+			return report, fmt.Errorf("failed to record webhook delivery %s: %w", delivery.ID, err)
+		}
+		switch delivery.Status {
+		case store.DeliveryDelivered:
+			report.Delivered++
+		case store.DeliveryDead:
+			report.DeadLettered++
+			log.Printf("Warning: Webhook delivery %s dead-lettered after %d attempts: %s", delivery.ID, delivery.Attempts, delivery.LastError)
+		default:
+			report.Retrying++
+		}
+	}
+	return report, nil
+}
+
+// attempt posts delivery once and returns it with its new state.
+func (d *Dispatcher) attempt(ctx context.Context, delivery store.WebhookDelivery) store.WebhookDelivery {
+	now := d.now().UTC()
+	delivery.Attempts++
+	delivery.UpdatedAt = now
+	delivery.LastStatusCode = 0
+
+	sub, err := d.store.GetSubscription(ctx, delivery.SubscriptionID)
+	if err != nil {
+		delivery.Status = store.DeliveryDead
+		delivery.LastError = err.Error()
+		return delivery
+	}
+
+	if err := d.post(ctx, sub, &delivery, now); err != nil {
+		delivery.LastError = err.Error()
+		if delivery.Attempts >= d.retry.MaxAttempts {
+			delivery.Status = store.DeliveryDead
+		} else {
+			delivery.NextAttemptAt = now.Add(d.retry.delay(delivery.Attempts))
+		}
+		return delivery
+	}
+	delivery.Status = store.DeliveryDelivered
+	delivery.LastError = ""
+	return delivery
+}
+
+func (d *Dispatcher) post(ctx context.Context, sub store.WebhookSubscription, delivery *store.WebhookDelivery, now time.Time) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "financial-ledger-webhooks")
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(TypeHeader, delivery.Type)
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, now, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	delivery.LastStatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("receiver answered %s", resp.Status)
+	}
+	return nil
+}
+
+// Run delivers due notifications every interval until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := d.DeliverDue(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("ERROR: Webhook delivery failed: %v", err)
+			}
+		}
+	}
+}
+
+// --- Dead letters ---
+
+// Deliveries lists deliveries with the given status, or all if status is empty.
+func (d *Dispatcher) Deliveries(ctx context.Context, status store.WebhookDeliveryStatus) ([]store.WebhookDelivery, error) {
+	return d.store.ListDeliveries(ctx, status)
+}
+
+// Replay moves a dead-lettered delivery back to the queue with a fresh set of
+// attempts. It is sent on the next DeliverDue.
+func (d *Dispatcher) Replay(ctx context.Context, deliveryID string) error {
+	delivery, err := d.store.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return err
+	}
+	if delivery.Status != store.DeliveryDead {
+		return fmt.Errorf("%w: %s is %s", ErrNotDeadLettered, deliveryID, delivery.Status)
+	}
+	now := d.now().UTC()
+	delivery.Status = store.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = now
+	delivery.UpdatedAt = now
+	return d.store.UpdateDelivery(ctx, delivery)
+}
@@ -0,0 +1,228 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"financial-ledger/events"
+	"financial-ledger/store"
+	"financial-ledger/webhook"
+)
+
+// receiver is an httptest server that records verified payloads and answers
+// with whatever status is set.
+type receiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	secret   string
+	status   int
+	payloads []webhook.Payload
+	badSigs  int
+}
+
+func newReceiver(t *testing.T) *receiver {
+	r := &receiver{status: http.StatusOK}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if err := webhook.Verify(r.secret, req.Header.Get(webhook.SignatureHeader), body, time.Now(), 0); err != nil {
+			r.badSigs++
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.status == http.StatusOK {
+			var p webhook.Payload
+			json.Unmarshal(body, &p)
+			r.payloads = append(r.payloads, p)
+		}
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) setStatus(status int) {
+	r.mu.Lock()
+	r.status = status
+	r.mu.Unlock()
+}
+
+func (r *receiver) received() []webhook.Payload {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]webhook.Payload(nil), r.payloads...)
+}
+
+type clock struct{ now time.Time }
+
+func (c *clock) Now() time.Time          { return c.now }
+func (c *clock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func deposit(accountID string, version int) events.Event {
+	return events.DepositMadeEvent{
+		BaseEvent: events.NewBaseEvent(accountID, version, events.DepositMadeType),
+		Amount:    decimal.NewFromInt(100),
+		Currency:  "USD",
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	ctx := context.Background()
+	clk := &clock{now: time.Now()}
+	ws := store.NewInMemoryWebhookStore()
+	policy := webhook.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: time.Minute}
+	d := webhook.NewDispatcher(ws, webhook.WithRetryPolicy(policy), webhook.WithClock(clk.Now))
+
+	rcv := newReceiver(t)
+	sub, err := d.Subscribe(ctx, rcv.URL, []string{string(events.DepositMadeType), webhook.CommandRejectedType})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	rcv.secret = sub.Secret
+
+	t.Run("RejectsBadURL", func(t *testing.T) {
+		if _, err := d.Subscribe(ctx, "ftp://example.test", nil); err == nil {
+			t.Errorf("expected non-http URL to be rejected")
+		}
+	})
+
+	t.Run("DeliversSignedPayload", func(t *testing.T) {
+		event := deposit("acc-1", 2)
+		if err := d.Apply(ctx, event); err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+		// An event of a type the subscription did not ask for.
+		d.Apply(ctx, events.AccountCreatedEvent{BaseEvent: events.NewBaseEvent("acc-1", 1, events.AccountCreatedType)})
+
+		report, err := d.DeliverDue(ctx)
+		if err != nil || report.Delivered != 1 {
+			t.Fatalf("expected 1 delivery, got %+v (err: %v)", report, err)
+		}
+		got := rcv.received()
+		if len(got) != 1 || got[0].ID != event.GetBase().EventID.String() || got[0].Type != "DepositMade" {
+			t.Fatalf("unexpected payloads %+v", got)
+		}
+		if !strings.Contains(string(got[0].Data), `"amount":"100"`) {
+			t.Errorf("payload data lacks the amount: %s", got[0].Data)
+		}
+	})
+
+	t.Run("ApplyTwiceDeliversOnce", func(t *testing.T) {
+		event := deposit("acc-2", 2)
+		d.Apply(ctx, event)
+		d.Apply(ctx, event)
+		d.DeliverDue(ctx)
+		d.Apply(ctx, event)
+		if report, _ := d.DeliverDue(ctx); report.Delivered != 0 {
+			t.Errorf("expected re-applied event not to be delivered again, got %+v", report)
+		}
+	})
+
+	t.Run("Notify", func(t *testing.T) {
+		if err := d.Notify(ctx, webhook.CommandRejectedType, "rej-1", map[string]string{"error": "insufficient funds"}); err != nil {
+			t.Fatalf("Notify failed: %v", err)
+		}
+		d.DeliverDue(ctx)
+		got := rcv.received()
+		if last := got[len(got)-1]; last.ID != "rej-1" || last.Type != webhook.CommandRejectedType {
+			t.Errorf("unexpected notification %+v", last)
+		}
+	})
+
+	t.Run("BackoffAndDeadLetter", func(t *testing.T) {
+		rcv.setStatus(http.StatusServiceUnavailable)
+		d.Apply(ctx, deposit("acc-3", 2))
+
+		if report, _ := d.DeliverDue(ctx); report.Retrying != 1 {
+			t.Fatalf("expected a retry to be scheduled, got %+v", report)
+		}
+		if report, _ := d.DeliverDue(ctx); report.Retrying+report.DeadLettered != 0 {
+			t.Errorf("expected nothing due before the backoff elapses, got %+v", report)
+		}
+		clk.Advance(time.Second)
+		if report, _ := d.DeliverDue(ctx); report.Retrying != 1 {
+			t.Fatalf("expected second attempt after 1s, got %+v", report)
+		}
+		clk.Advance(time.Second)
+		if report, _ := d.DeliverDue(ctx); report.Retrying != 0 {
+			t.Errorf("expected the third attempt to wait 2s, got %+v", report)
+		}
+		clk.Advance(time.Second)
+		if report, _ := d.DeliverDue(ctx); report.DeadLettered != 1 {
+			t.Fatalf("expected dead letter after 3 attempts, got %+v", report)
+		}
+
+		dead, _ := d.Deliveries(ctx, store.DeliveryDead)
+		if len(dead) != 1 || dead[0].Attempts != 3 || dead[0].LastStatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("unexpected dead letters %+v", dead)
+		}
+
+		rcv.setStatus(http.StatusOK)
+		if err := d.Replay(ctx, dead[0].ID); err != nil {
+			t.Fatalf("Replay failed: %v", err)
+		}
+		if report, _ := d.DeliverDue(ctx); report.Delivered != 1 {
+			t.Errorf("expected replayed delivery to succeed, got %+v", report)
+		}
+		if err := d.Replay(ctx, dead[0].ID); !errors.Is(err, webhook.ErrNotDeadLettered) {
+			t.Errorf("expected ErrNotDeadLettered replaying a delivered message, got %v", err)
+		}
+	})
+
+	t.Run("RemovedSubscription", func(t *testing.T) {
+		d.Apply(ctx, deposit("acc-4", 2))
+		if err := d.Unsubscribe(ctx, sub.ID); err != nil {
+			t.Fatalf("Unsubscribe failed: %v", err)
+		}
+		if report, _ := d.DeliverDue(ctx); report.DeadLettered != 1 {
+			t.Errorf("expected delivery to a removed subscription to be dead-lettered, got %+v", report)
+		}
+	})
+
+	if rcv.badSigs != 0 {
+		t.Errorf("receiver rejected %d signatures", rcv.badSigs)
+	}
+}
+
+func TestSignature(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	now := time.Now()
+	header := webhook.Sign("secret", now, body)
+
+	t.Run("Valid", func(t *testing.T) {
+		if err := webhook.Verify("secret", header, body, now, time.Minute); err != nil {
+			t.Errorf("expected valid signature, got %v", err)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		body   []byte
+		now    time.Time
+	}{
+		{"WrongSecret", "other", header, body, now},
+		{"TamperedBody", "secret", header, []byte(`{"id":"2"}`), now},
+		{"Stale", "secret", header, body, now.Add(time.Hour)},
+		{"Malformed", "secret", "v1=abc", body, now},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := webhook.Verify(tc.secret, tc.header, tc.body, tc.now, time.Minute); !errors.Is(err, webhook.ErrInvalidSignature) {
+				t.Errorf("expected ErrInvalidSignature, got %v", err)
+			}
+		})
+	}
+}